@@ -0,0 +1,101 @@
+/*
+Copyright Gen Digital Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package dataintegrity
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/piprate/json-gold/ld"
+	"github.com/trustbloc/did-go/doc/ld/processor"
+	"github.com/trustbloc/did-go/pkg/canonicalizer"
+
+	"github.com/trustbloc/vc-go/dataintegrity/models"
+	"github.com/trustbloc/vc-go/dataintegrity/suite"
+)
+
+type canonicalizeOpts struct {
+	loader    ld.DocumentLoader
+	mda       ld.MessageDigestAlgorithm
+	algorithm models.CanonicalizationAlgorithm
+}
+
+// CanonicalizeOpt is an option of CanonicalizeForProof.
+type CanonicalizeOpt func(opts *canonicalizeOpts)
+
+// WithCanonicalizeDocumentLoader sets the JSON-LD document loader used for RDF canonicalization.
+// By default, JSON-LD contexts are loaded from the network.
+func WithCanonicalizeDocumentLoader(loader ld.DocumentLoader) CanonicalizeOpt {
+	return func(opts *canonicalizeOpts) {
+		opts.loader = loader
+	}
+}
+
+// WithCanonicalizeDigest sets the hash algorithm used by RDF canonicalization to label blank nodes.
+// It defaults to SHA-256, the ecdsa suites use SHA-384 with P-384 keys.
+func WithCanonicalizeDigest(mda ld.MessageDigestAlgorithm) CanonicalizeOpt {
+	return func(opts *canonicalizeOpts) {
+		opts.mda = mda
+	}
+}
+
+// WithCanonicalizeAlgorithm sets the RDF canonicalization algorithm, URDNA2015 by default,
+// as with Options.WithCanonicalizationAlgorithm.
+func WithCanonicalizeAlgorithm(algorithm models.CanonicalizationAlgorithm) CanonicalizeOpt {
+	return func(opts *canonicalizeOpts) {
+		opts.algorithm = algorithm
+	}
+}
+
+// CanonicalizeForProof returns the canonical form of doc that the given cryptographic suite hashes
+// when creating or verifying a proof: URDNA2015 (or RDFC-1.0) N-Quads for the RDF canonicalization suites
+// and JCS (RFC 8785) bytes for the JCS suites. As the suites do, the proof of doc is ignored.
+//
+// It is a diagnostic helper for signature mismatches between implementations, it is not used
+// by Signer and Verifier. The selective disclosure suites ecdsa-sd-2023 and bbs-2023 are not supported.
+func CanonicalizeForProof(doc []byte, suiteType string, opts ...CanonicalizeOpt) ([]byte, error) {
+	options := &canonicalizeOpts{mda: ld.MessageDigestAlgorithmSHA256}
+
+	for _, opt := range opts {
+		opt(options)
+	}
+
+	docData := map[string]interface{}{}
+
+	if err := json.Unmarshal(doc, &docData); err != nil {
+		return nil, fmt.Errorf("canonicalize for proof: expects JSON-LD payload: %w", err)
+	}
+
+	delete(docData, proofPath)
+
+	var (
+		out []byte
+		err error
+	)
+
+	// The suite types of the ecdsa2019 and eddsa2022 packages.
+	switch suiteType {
+	case "ecdsa-2019", "ecdsa-rdfc-2019", "eddsa-2022", "eddsa-rdfc-2022":
+		procOpts := []processor.Opts{processor.WithMessageDigestAlgorithm(options.mda)}
+
+		if options.loader != nil {
+			procOpts = append(procOpts, processor.WithDocumentLoader(options.loader))
+		}
+
+		out, err = suite.CanonicalizeRDF(docData, options.algorithm, procOpts...)
+	case "ecdsa-jcs-2019", "eddsa-jcs-2022":
+		out, err = canonicalizer.MarshalCanonical(docData)
+	default:
+		return nil, ErrUnsupportedSuite
+	}
+
+	if err != nil {
+		return nil, fmt.Errorf("canonicalize for proof with %s suite: %w", suiteType, err)
+	}
+
+	return out, nil
+}
@@ -0,0 +1,207 @@
+/*
+Copyright Gen Digital Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package dataintegrity
+
+import (
+	"crypto/ecdsa"
+	"crypto/sha256"
+	"encoding/json"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/multiformats/go-multibase"
+	"github.com/stretchr/testify/require"
+	"github.com/tidwall/sjson"
+	"github.com/trustbloc/did-go/doc/did"
+	"github.com/trustbloc/did-go/doc/ld/documentloader"
+	kmsapi "github.com/trustbloc/kms-go/spi/kms"
+
+	"github.com/trustbloc/vc-go/dataintegrity/models"
+	"github.com/trustbloc/vc-go/dataintegrity/suite"
+	"github.com/trustbloc/vc-go/dataintegrity/suite/ecdsa2019"
+	"github.com/trustbloc/vc-go/internal/testutil/kmscryptoutil"
+)
+
+func TestCanonicalizeForProof(t *testing.T) {
+	docLoader, err := documentloader.NewDocumentLoader(createMockProvider())
+	require.NoError(t, err)
+
+	kmsCrypto := kmscryptoutil.LocalKMSCrypto(t)
+
+	p256JWK, err := kmsCrypto.Create(kmsapi.ECDSAP256IEEEP1363)
+	require.NoError(t, err)
+
+	p256VM, err := did.NewVerificationMethodFromJWK(mockKID, "JsonWebKey2020", mockDID, p256JWK)
+	require.NoError(t, err)
+
+	signer, err := NewSigner(&Options{
+		DIDResolver: resolveFunc(func(id string) (*did.DocResolution, error) {
+			return makeMockDIDResolution(id, p256VM, did.AssertionMethod), nil
+		}),
+	}, ecdsa2019.NewSignerInitializer(&ecdsa2019.SignerInitializerOptions{
+		LDDocumentLoader: docLoader,
+		SignerGetter:     ecdsa2019.WithKMSCryptoWrapper(kmsCrypto),
+	}))
+	require.NoError(t, err)
+
+	pubKey, ok := p256JWK.Key.(*ecdsa.PublicKey)
+	require.True(t, ok)
+
+	t.Run("canonical form is the signed data", func(t *testing.T) {
+		for _, suiteType := range []string{ecdsa2019.SuiteType, ecdsa2019.SuiteTypeJCS} {
+			t.Run(suiteType, func(t *testing.T) {
+				signedCred, err := signer.AddProof(validCredential, &models.ProofOptions{
+					VerificationMethod:   p256VM,
+					VerificationMethodID: p256VM.ID,
+					SuiteType:            suiteType,
+					Purpose:              AssertionMethod,
+					ProofType:            models.DataIntegrityProof,
+					Created:              time.Now(),
+				})
+				require.NoError(t, err)
+
+				canonDoc, err := CanonicalizeForProof(signedCred, suiteType, WithCanonicalizeDocumentLoader(docLoader))
+				require.NoError(t, err)
+
+				unsignedCanonDoc, err := CanonicalizeForProof(validCredential, suiteType,
+					WithCanonicalizeDocumentLoader(docLoader))
+				require.NoError(t, err)
+				require.Equal(t, unsignedCanonDoc, canonDoc)
+
+				// The proof configuration is the proof without proofValue, in the context of the document.
+				var signed struct {
+					Context interface{}            `json:"@context"`
+					Proof   map[string]interface{} `json:"proof"`
+				}
+
+				require.NoError(t, json.Unmarshal(signedCred, &signed))
+
+				proofValue, ok := signed.Proof["proofValue"].(string)
+				require.True(t, ok)
+
+				delete(signed.Proof, "proofValue")
+				signed.Proof["@context"] = signed.Context
+
+				confBytes, err := json.Marshal(signed.Proof)
+				require.NoError(t, err)
+
+				canonConf, err := CanonicalizeForProof(confBytes, suiteType, WithCanonicalizeDocumentLoader(docLoader))
+				require.NoError(t, err)
+
+				confHash := sha256.Sum256(canonConf)
+				docHash := sha256.Sum256(canonDoc)
+				digest := sha256.Sum256(append(confHash[:], docHash[:]...))
+
+				_, sig, err := multibase.Decode(proofValue)
+				require.NoError(t, err)
+				require.Len(t, sig, 64)
+
+				require.True(t, ecdsa.Verify(pubKey, digest[:],
+					new(big.Int).SetBytes(sig[:32]), new(big.Int).SetBytes(sig[32:])))
+			})
+		}
+	})
+
+	t.Run("output format", func(t *testing.T) {
+		nQuads, err := CanonicalizeForProof(validCredential, ecdsa2019.SuiteTypeNew,
+			WithCanonicalizeDocumentLoader(docLoader))
+		require.NoError(t, err)
+		require.Contains(t, string(nQuads),
+			"<https://www.w3.org/2018/credentials#issuer> <did:example:76e12ec712ebc6f1c221ebfeb1f> .\n")
+
+		jcs, err := CanonicalizeForProof([]byte(`{"b": 2, "a": [1.0, "x"], "proof": {"type": "y"}}`),
+			"eddsa-jcs-2022")
+		require.NoError(t, err)
+		require.Equal(t, `{"a":[1,"x"],"b":2}`, string(jcs))
+	})
+
+	t.Run("canonicalization algorithm", func(t *testing.T) {
+		for _, algorithm := range []models.CanonicalizationAlgorithm{models.URDNA2015, models.RDFC10} {
+			nQuads, err := CanonicalizeForProof(validCredential, ecdsa2019.SuiteTypeNew,
+				WithCanonicalizeDocumentLoader(docLoader), WithCanonicalizeAlgorithm(algorithm))
+			require.NoError(t, err)
+
+			defaultNQuads, err := CanonicalizeForProof(validCredential, ecdsa2019.SuiteTypeNew,
+				WithCanonicalizeDocumentLoader(docLoader))
+			require.NoError(t, err)
+			require.Equal(t, defaultNQuads, nQuads)
+		}
+
+		// The algorithms only differ on the control characters of literals.
+		controlCredential, err := sjson.SetBytes(validCredential, `credentialSubject.https://schema\.org/name`,
+			"Example\u0001University")
+		require.NoError(t, err)
+
+		nQuads, err := CanonicalizeForProof(controlCredential, ecdsa2019.SuiteTypeNew,
+			WithCanonicalizeDocumentLoader(docLoader), WithCanonicalizeAlgorithm(models.URDNA2015))
+		require.NoError(t, err)
+		require.Contains(t, string(nQuads), "\"Example\x01University\"")
+
+		nQuads, err = CanonicalizeForProof(controlCredential, ecdsa2019.SuiteTypeNew,
+			WithCanonicalizeDocumentLoader(docLoader), WithCanonicalizeAlgorithm(models.RDFC10))
+		require.NoError(t, err)
+		require.Contains(t, string(nQuads), `"Example\u0001University"`)
+
+		// The -rdfc- suites default to URDNA2015 too, RDFC-1.0 must be pinned.
+		nQuads, err = CanonicalizeForProof(controlCredential, ecdsa2019.SuiteTypeNew,
+			WithCanonicalizeDocumentLoader(docLoader))
+		require.NoError(t, err)
+		require.Contains(t, string(nQuads), "\"Example\x01University\"")
+
+		_, err = CanonicalizeForProof(validCredential, ecdsa2019.SuiteTypeNew,
+			WithCanonicalizeDocumentLoader(docLoader), WithCanonicalizeAlgorithm("URGNA2012"))
+		require.ErrorContains(t, err, `unsupported RDF canonicalization algorithm "URGNA2012"`)
+
+		// Signer and Verifier must agree on the algorithm.
+		resolver := resolveFunc(func(id string) (*did.DocResolution, error) {
+			return makeMockDIDResolution(id, p256VM, did.AssertionMethod), nil
+		})
+
+		rdfcSigner, err := NewSigner((&Options{DIDResolver: resolver}).WithCanonicalizationAlgorithm(models.RDFC10),
+			ecdsa2019.NewSignerInitializer(&ecdsa2019.SignerInitializerOptions{
+				LDDocumentLoader: docLoader,
+				SignerGetter:     ecdsa2019.WithKMSCryptoWrapper(kmsCrypto),
+			}))
+		require.NoError(t, err)
+
+		newVerifier := func(algorithm models.CanonicalizationAlgorithm) *Verifier {
+			v, e := NewVerifier((&Options{DIDResolver: resolver}).WithCanonicalizationAlgorithm(algorithm),
+				ecdsa2019.NewVerifierInitializer(&ecdsa2019.VerifierInitializerOptions{
+					LDDocumentLoader: docLoader,
+				}))
+			require.NoError(t, e)
+
+			return v
+		}
+
+		proofOpts := func() *models.ProofOptions {
+			return &models.ProofOptions{
+				VerificationMethodID: p256VM.ID,
+				SuiteType:            ecdsa2019.SuiteTypeNew,
+				Purpose:              AssertionMethod,
+				ProofType:            models.DataIntegrityProof,
+			}
+		}
+
+		signedCred, err := rdfcSigner.AddProof(controlCredential, proofOpts())
+		require.NoError(t, err)
+
+		require.NoError(t, newVerifier(models.RDFC10).VerifyProof(signedCred, proofOpts()))
+
+		err = newVerifier("").VerifyProof(signedCred, proofOpts())
+		require.ErrorIs(t, err, suite.ErrInvalidProof)
+	})
+
+	t.Run("failure", func(t *testing.T) {
+		_, err := CanonicalizeForProof(validCredential, "ecdsa-sd-2023")
+		require.ErrorIs(t, err, ErrUnsupportedSuite)
+
+		_, err = CanonicalizeForProof([]byte("not JSON"), ecdsa2019.SuiteType)
+		require.ErrorContains(t, err, "expects JSON-LD payload")
+	})
+}
@@ -7,10 +7,17 @@ SPDX-License-Identifier: Apache-2.0
 package dataintegrity
 
 import (
+	"context"
 	"errors"
+	"fmt"
+	"time"
 
+	"github.com/tidwall/gjson"
+	"github.com/tidwall/sjson"
 	"github.com/trustbloc/did-go/doc/did"
 	vdrapi "github.com/trustbloc/did-go/vdr/api"
+
+	"github.com/trustbloc/vc-go/dataintegrity/models"
 )
 
 var (
@@ -24,13 +31,238 @@ var (
 	// ErrVMResolution is returned when a Signer or Verifier needs to resolve a
 	// verification method but this fails.
 	ErrVMResolution = errors.New("failed to resolve verification method")
+	// ErrMissingVerificationRelationship is returned, together with ErrVMResolution, when the
+	// verification method is not listed under the verification relationship of the proof
+	// purpose in the DID document of its controller, e.g. a key authorized only for
+	// authentication used for an assertionMethod proof.
+	ErrMissingVerificationRelationship = errors.New("verification method is not authorized for the proof purpose")
+	// ErrUnsupportedPurpose is returned when a Signer is required to create a
+	// proof with a proof purpose that the cryptographic suite doesn't support.
+	ErrUnsupportedPurpose = errors.New("data integrity proof requires unsupported proof purpose")
+	// ErrInvalidProofChain is returned when a Signer or Verifier is given a proof
+	// chained to a previous proof that is not in the proof set, or that doesn't
+	// precede it in the proof set, and when a Verifier is given proofs which are not
+	// of the expected models.ProofStructure. It reports a broken chain, while a proof
+	// of the chain which doesn't verify fails with suite.ErrInvalidProof.
+	ErrInvalidProofChain = errors.New("data integrity proof chain is invalid")
 )
 
-type didResolver interface {
+// SupportedPurposes returns the proof purposes a Signer can create proofs for:
+// assertionMethod, authentication, capabilityDelegation and capabilityInvocation.
+// A cryptographic suite may support only some of them, see suite.PurposeRestrictor.
+// Purposes that are not used for signing, like keyAgreement, are not supported.
+func SupportedPurposes() []string {
+	return []string{AssertionMethod, Authentication, CapabilityDelegation, CapabilityInvocation}
+}
+
+// previousProofDoc returns the unsecured doc with its proof set to the previous proof,
+// which is the one of the proofs with the previousProof id, as required by the Add Proof
+// and Verify Proof algorithms for proof chains.
+func previousProofDoc(unsecuredDoc []byte, proofs []gjson.Result, previousProof string) ([]byte, error) {
+	for _, proof := range proofs {
+		if proof.Get("id").String() != previousProof {
+			continue
+		}
+
+		doc, err := sjson.SetRawBytes(unsecuredDoc, proofPath, []byte("["+proof.Raw+"]"))
+		if err != nil {
+			// TODO update linter to use go 1.20: https://github.com/hyperledger/aries-framework-go/issues/3613
+			return nil, errors.Join(ErrInvalidProofChain, err) // nolint:typecheck
+		}
+
+		return doc, nil
+	}
+
+	// TODO update linter to use go 1.20: https://github.com/hyperledger/aries-framework-go/issues/3613
+	return nil, errors.Join(ErrInvalidProofChain, // nolint:typecheck
+		fmt.Errorf("previous proof %q is not found before the chained proof", previousProof))
+}
+
+// checkProofStructure returns ErrInvalidProofChain if the proofs are not of the given structure.
+// The proofs of a ProofSet must not be chained, and each proof of a ProofChain but the first one
+// must be chained to the proof preceding it. Any structure is accepted if none is given.
+func checkProofStructure(proofs []gjson.Result, structure models.ProofStructure) error {
+	switch structure {
+	case models.ProofSet:
+		for i, proof := range proofs {
+			if previousProof := proof.Get("previousProof"); previousProof.Exists() {
+				return fmt.Errorf("%w: proof [%d] of proof set is chained to previous proof %q",
+					ErrInvalidProofChain, i, previousProof.String())
+			}
+		}
+	case models.ProofChain:
+		for i, proof := range proofs {
+			previousProof := proof.Get("previousProof")
+
+			if i == 0 {
+				if previousProof.Exists() {
+					return fmt.Errorf("%w: first proof of proof chain is chained to previous proof %q",
+						ErrInvalidProofChain, previousProof.String())
+				}
+
+				continue
+			}
+
+			precedingID := proofs[i-1].Get("id").String()
+			if precedingID == "" || previousProof.String() != precedingID {
+				return fmt.Errorf("%w: proof [%d] of proof chain is not chained to the proof preceding it",
+					ErrInvalidProofChain, i)
+			}
+		}
+	}
+
+	return nil
+}
+
+// DIDResolver resolves the DID documents of the verification methods, e.g. a VDR registry,
+// a universal resolver HTTP client or a CachingResolver.
+type DIDResolver interface {
 	Resolve(did string, opts ...vdrapi.DIDMethodOption) (*did.DocResolution, error)
 }
 
+// ContextDIDResolver is implemented by the DIDResolvers that can bind a resolution, eg its HTTP
+// request, to a context, as WebResolver and CachingResolver. The Verifier context-aware methods,
+// eg VerifyProofContext, resolve with it.
+type ContextDIDResolver interface {
+	ResolveContext(ctx context.Context, did string, opts ...vdrapi.DIDMethodOption) (*did.DocResolution, error)
+}
+
+type resolveResult struct {
+	resolution *did.DocResolution
+	err        error
+}
+
+// resolveDIDContext resolves didID with the resolver until ctx is done. The resolution of a resolver
+// that is not a ContextDIDResolver is abandoned when ctx is done, it goes on until the resolver returns.
+func resolveDIDContext(
+	ctx context.Context,
+	resolver DIDResolver,
+	didID string,
+	opts ...vdrapi.DIDMethodOption,
+) (*did.DocResolution, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	if ctxResolver, ok := resolver.(ContextDIDResolver); ok {
+		return ctxResolver.ResolveContext(ctx, didID, opts...)
+	}
+
+	if ctx.Done() == nil {
+		return resolver.Resolve(didID, opts...)
+	}
+
+	result := make(chan resolveResult, 1)
+
+	go func() {
+		resolution, err := resolver.Resolve(didID, opts...)
+		result <- resolveResult{resolution: resolution, err: err}
+	}()
+
+	select {
+	case r := <-result:
+		return r.resolution, r.err
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
+
 // Options contains initialization parameters for Data Integrity Signer and Verifier.
 type Options struct {
-	DIDResolver didResolver
+	DIDResolver DIDResolver
+
+	contextCacheSize          int
+	verificationCacheSize     int
+	clock                     func() time.Time
+	givenProofTimes           bool
+	canonicalizationAlgorithm models.CanonicalizationAlgorithm
+	relationshipCheckPurposes []string
+	algorithmSelector         SignatureAlgorithmSelector
+}
+
+// WithDIDResolver sets the DIDResolver used by the Signer and Verifier to resolve the verification
+// methods of the proofs, e.g. a CachingResolver wrapping a universal resolver client.
+func (o *Options) WithDIDResolver(resolver DIDResolver) *Options {
+	o.DIDResolver = resolver
+
+	return o
+}
+
+// WithProofRelationshipCheck makes the Verifier enforce, for the proofs with one of the given
+// purposes, that the verification method is listed under the verification relationship of the
+// purpose in the DID document of its controller, e.g. assertionMethod. The DID document is then
+// always resolved, even when the proof options give the verification method, and an assertionMethod
+// proof can't use a verification method that is listed without relationship. Else the Verifier
+// fails with ErrMissingVerificationRelationship.
+func (o *Options) WithProofRelationshipCheck(purposes ...string) *Options {
+	o.relationshipCheckPurposes = append(o.relationshipCheckPurposes, purposes...)
+
+	return o
+}
+
+// WithSignatureAlgorithmSelector sets how the Verifier selects the signature algorithm of a proof from
+// its cryptographic suite and the key of its verification method. The selected algorithm is given to the
+// suite as models.ProofOptions.SignatureAlgorithm, which the ecdsa-2019 and eddsa-2022 suites verify the
+// proof with, and a proof whose key the selector rejects is not verified. By default, SignatureAlgorithm
+// is used.
+func (o *Options) WithSignatureAlgorithmSelector(selector SignatureAlgorithmSelector) *Options {
+	o.algorithmSelector = selector
+
+	return o
+}
+
+// WithContextCache makes the Signer or Verifier cache up to size JSON-LD contexts loaded by
+// its cryptographic suites in memory, keyed on the context URL, e.g. so that the credentials
+// of a batch, which share their contexts, don't load them again.
+func (o *Options) WithContextCache(size int) *Options {
+	o.contextCacheSize = size
+
+	return o
+}
+
+// WithVerificationCache makes the Verifier remember up to size proofs that it verified,
+// so that verifying again the same proof of the same document with the same options
+// doesn't canonicalize the document again. The proof created and expires times are
+// checked at each verification, and a remembered proof is forgotten when it expires.
+func (o *Options) WithVerificationCache(size int) *Options {
+	o.verificationCacheSize = size
+
+	return o
+}
+
+// WithClock makes the Signer take the created time of the proofs from now instead
+// of time.Now, when the proof options have no created time set. A fixed clock makes
+// the output of deterministic suites reproducible, e.g. for golden files in tests.
+func (o *Options) WithClock(now func() time.Time) *Options {
+	o.clock = now
+
+	return o
+}
+
+// WithGivenProofTimes makes the Signer keep the created and expires times of the proof options
+// as given, with their time zone and precision, instead of converting them to UTC truncated to
+// seconds (eg "2023-02-24T23:36:38Z", as in the specification examples). The suites format the
+// times with models.DateTimeFormat, which drops the sub-second digits.
+func (o *Options) WithGivenProofTimes() *Options {
+	o.givenProofTimes = true
+
+	return o
+}
+
+// WithCanonicalizationAlgorithm pins the RDF canonicalization algorithm, models.URDNA2015 (the
+// default) or models.RDFC10, used by the Signer and Verifier suites with RDF canonicalization,
+// for the proof options that don't set one. Signer and verifier must use the same algorithm.
+//
+// By spec, ecdsa-2019 and eddsa-2022 use URDNA2015, while ecdsa-rdfc-2019 and eddsa-rdfc-2022
+// use RDFC-1.0. All of them default to URDNA2015 nevertheless, the algorithm of the proofs created
+// before this option, so that their canonical form doesn't change: callers following the spec of
+// the -rdfc- suites must pin models.RDFC10. The JCS suites don't use RDF canonicalization, and the
+// canonicalization of ecdsa-sd-2023 is not affected.
+//
+// RDFC-1.0 is emulated on top of the URDNA2015 of the JSON-LD processor, see suite.CanonicalizeRDF
+// for its limitation.
+func (o *Options) WithCanonicalizationAlgorithm(algorithm models.CanonicalizationAlgorithm) *Options {
+	o.canonicalizationAlgorithm = algorithm
+
+	return o
 }
@@ -0,0 +1,156 @@
+/*
+Copyright Gen Digital Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package dataintegrity_test
+
+import (
+	"crypto/ed25519"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/multiformats/go-multibase"
+	"github.com/trustbloc/did-go/doc/did"
+	"github.com/trustbloc/did-go/pkg/canonicalizer"
+	"golang.org/x/crypto/sha3"
+
+	"github.com/trustbloc/vc-go/dataintegrity"
+	"github.com/trustbloc/vc-go/dataintegrity/models"
+	"github.com/trustbloc/vc-go/dataintegrity/suite"
+)
+
+const sha3SuiteType = "example-sha3-jcs-2024"
+
+// sha3Suite is a custom cryptographic suite, that signs the SHA3-256 hashes of the JCS
+// canonical forms of the proof configuration and of the document with an Ed25519 key.
+type sha3Suite struct {
+	privateKey ed25519.PrivateKey
+}
+
+func (s *sha3Suite) Signer() (suite.Signer, error)     { return s, nil }
+func (s *sha3Suite) Verifier() (suite.Verifier, error) { return s, nil }
+func (s *sha3Suite) Type() []string                    { return []string{sha3SuiteType} }
+func (s *sha3Suite) RequiresCreated() bool             { return false }
+
+func (s *sha3Suite) CreateProof(doc []byte, opts *models.ProofOptions) (*models.Proof, error) {
+	proof := &models.Proof{
+		Type:               models.DataIntegrityProof,
+		CryptoSuite:        sha3SuiteType,
+		ProofPurpose:       opts.Purpose,
+		VerificationMethod: opts.VerificationMethod.ID,
+		Created:            opts.Created.Format(models.DateTimeFormat),
+	}
+
+	hash, err := s.hash(doc, proof)
+	if err != nil {
+		return nil, err
+	}
+
+	proof.ProofValue, err = multibase.Encode(multibase.Base58BTC, ed25519.Sign(s.privateKey, hash))
+	if err != nil {
+		return nil, err
+	}
+
+	return proof, nil
+}
+
+func (s *sha3Suite) VerifyProof(doc []byte, proof *models.Proof, opts *models.ProofOptions) error {
+	hash, err := s.hash(doc, &models.Proof{
+		Type:               proof.Type,
+		CryptoSuite:        proof.CryptoSuite,
+		ProofPurpose:       proof.ProofPurpose,
+		VerificationMethod: proof.VerificationMethod,
+		Created:            proof.Created,
+	})
+	if err != nil {
+		return err
+	}
+
+	_, signature, err := multibase.Decode(proof.ProofValue)
+	if err != nil {
+		return err
+	}
+
+	if !ed25519.Verify(opts.VerificationMethod.Value, hash, signature) {
+		return errors.New("invalid signature")
+	}
+
+	return nil
+}
+
+func (s *sha3Suite) hash(doc []byte, proofConfig *models.Proof) ([]byte, error) {
+	var docData map[string]interface{}
+
+	if err := json.Unmarshal(doc, &docData); err != nil {
+		return nil, err
+	}
+
+	canonicalDoc, err := canonicalizer.MarshalCanonical(docData)
+	if err != nil {
+		return nil, err
+	}
+
+	canonicalConfig, err := canonicalizer.MarshalCanonical(proofConfig)
+	if err != nil {
+		return nil, err
+	}
+
+	configHash := sha3.Sum256(canonicalConfig)
+	docHash := sha3.Sum256(canonicalDoc)
+
+	return append(configHash[:], docHash[:]...), nil
+}
+
+func ExampleNewSigner_registeredSuite() {
+	publicKey, privateKey, err := ed25519.GenerateKey(nil)
+	if err != nil {
+		panic(err)
+	}
+
+	// Register the custom suite once, eg in an init function of the package implementing it.
+	suite.RegisterSigner(&sha3Suite{privateKey: privateKey})
+	suite.RegisterVerifier(&sha3Suite{})
+
+	defer suite.UnregisterSigner(sha3SuiteType)
+	defer suite.UnregisterVerifier(sha3SuiteType)
+
+	// The Signer and the Verifier use the registered suite, without it being passed to them.
+	signer, err := dataintegrity.NewSigner(nil)
+	if err != nil {
+		panic(err)
+	}
+
+	verifier, err := dataintegrity.NewVerifier(nil)
+	if err != nil {
+		panic(err)
+	}
+
+	vm := did.NewVerificationMethodFromBytes("did:example:holder#key-1", "Ed25519VerificationKey2020",
+		"did:example:holder", publicKey)
+
+	proofOpts := func() *models.ProofOptions {
+		return &models.ProofOptions{
+			VerificationMethod: vm,
+			SuiteType:          sha3SuiteType,
+			Purpose:            dataintegrity.AssertionMethod,
+			ProofType:          models.DataIntegrityProof,
+			Created:            time.Now(),
+		}
+	}
+
+	signed, err := signer.AddProof([]byte(`{"id":"urn:example:doc","name":"Jayden Doe"}`), proofOpts())
+	if err != nil {
+		panic(err)
+	}
+
+	fmt.Println(signer.SupportsSuite(sha3SuiteType))
+	fmt.Println(verifier.VerifyProof(signed, proofOpts()))
+
+	// Output:
+	// true
+	// <nil>
+}
@@ -7,27 +7,44 @@ SPDX-License-Identifier: Apache-2.0
 package dataintegrity
 
 import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/sha256"
 	_ "embed"
+	"encoding/json"
 	"fmt"
+	"sync"
 	"testing"
 	"time"
 
+	"github.com/multiformats/go-multibase"
+	"github.com/piprate/json-gold/ld"
 	"github.com/stretchr/testify/require"
+	"github.com/tidwall/gjson"
 	"github.com/tidwall/sjson"
 	"github.com/trustbloc/did-go/doc/did"
 	"github.com/trustbloc/did-go/doc/ld/documentloader"
 	mockldstore "github.com/trustbloc/did-go/doc/ld/mock"
 	"github.com/trustbloc/did-go/doc/ld/store"
+	"github.com/trustbloc/kms-go/doc/jose/jwk/jwksupport"
 	kmsapi "github.com/trustbloc/kms-go/spi/kms"
 
 	"github.com/trustbloc/vc-go/dataintegrity/models"
+	"github.com/trustbloc/vc-go/dataintegrity/suite"
 	"github.com/trustbloc/vc-go/dataintegrity/suite/ecdsa2019"
+	"github.com/trustbloc/vc-go/dataintegrity/suite/eddsa2022"
 	"github.com/trustbloc/vc-go/internal/testutil/kmscryptoutil"
 )
 
 var (
 	//go:embed suite/ecdsa2019/testdata/valid_credential.jsonld
 	validCredential []byte
+
+	//go:embed suite/ecdsa2019/testdata/valid_graph_credential.jsonld
+	validGraphCredential []byte
 )
 
 const (
@@ -109,6 +126,258 @@ func TestIntegration(t *testing.T) {
 			require.NoError(t, err)
 		})
 
+		t.Run("detailed verification", func(t *testing.T) {
+			created := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+
+			signedCred, err := signer.AddProof(validCredential, &models.ProofOptions{
+				VerificationMethod:   p256VM,
+				VerificationMethodID: p256VM.ID,
+				SuiteType:            ecdsa2019.SuiteType,
+				Purpose:              AssertionMethod,
+				ProofType:            models.DataIntegrityProof,
+				Created:              created,
+			})
+			require.NoError(t, err)
+
+			verifyOpts := &models.ProofOptions{
+				SuiteType: ecdsa2019.SuiteType,
+				Purpose:   AssertionMethod,
+				ProofType: models.DataIntegrityProof,
+			}
+
+			detail, err := verifier.VerifyProofDetailed(signedCred, verifyOpts)
+			require.NoError(t, err)
+			require.Equal(t, mockDID, detail.SignerDID)
+			require.Equal(t, mockKID, detail.VerificationMethodID)
+			require.Equal(t, ecdsa2019.SuiteType, detail.CryptoSuite)
+			require.True(t, created.Equal(detail.Created))
+			require.Len(t, detail.ProofSet, 1)
+
+			// A proof set, with a proof by another signer.
+			otherProof, err := signer.CreateProof(validCredential, &models.ProofOptions{
+				VerificationMethod:   p384VM,
+				VerificationMethodID: mockKID2,
+				SuiteType:            ecdsa2019.SuiteTypeNew,
+				Purpose:              AssertionMethod,
+				ProofType:            models.DataIntegrityProof,
+			})
+			require.NoError(t, err)
+
+			otherProofRaw, err := json.Marshal(otherProof)
+			require.NoError(t, err)
+
+			signedCred, err = sjson.SetRawBytes(signedCred, "proof",
+				[]byte("["+gjson.GetBytes(signedCred, "proof").Raw+","+string(otherProofRaw)+"]"))
+			require.NoError(t, err)
+
+			verifyOpts.SuiteType = ""
+
+			detail, err = verifier.VerifyProofDetailed(signedCred, verifyOpts)
+			require.NoError(t, err)
+			require.Equal(t, mockKID, detail.VerificationMethodID)
+			require.Len(t, detail.ProofSet, 2)
+			require.Equal(t, mockKID, detail.ProofSet[0].VerificationMethodID)
+			require.Equal(t, mockDID2, detail.ProofSet[1].SignerDID)
+			require.Equal(t, mockKID2, detail.ProofSet[1].VerificationMethodID)
+			require.Equal(t, ecdsa2019.SuiteTypeNew, detail.ProofSet[1].CryptoSuite)
+			require.False(t, detail.ProofSet[1].Created.IsZero())
+
+			tampered, err := sjson.SetBytes(signedCred, "issuanceDate", "2011-01-01T19:23:24Z")
+			require.NoError(t, err)
+
+			detail, err = verifier.VerifyProofDetailed(tampered, verifyOpts)
+			require.ErrorIs(t, err, suite.ErrInvalidProof)
+			require.Nil(t, detail)
+		})
+
+		t.Run("proof times in UTC at second precision", func(t *testing.T) {
+			zone := time.FixedZone("UTC-5", -5*60*60)
+			created := time.Now().In(zone)
+
+			signedCred, err := signer.AddProof(validCredential, &models.ProofOptions{
+				VerificationMethod:   p256VM,
+				VerificationMethodID: p256VM.ID,
+				SuiteType:            ecdsa2019.SuiteType,
+				Purpose:              AssertionMethod,
+				ProofType:            models.DataIntegrityProof,
+				Created:              created,
+				Expires:              created.Add(time.Hour + time.Millisecond),
+			})
+			require.NoError(t, err)
+
+			// Same format as "2023-02-24T23:36:38Z" in the specification examples.
+			require.Equal(t, created.UTC().Format("2006-01-02T15:04:05Z"),
+				gjson.GetBytes(signedCred, "proof.created").String())
+			require.Equal(t, created.Add(time.Hour).UTC().Format("2006-01-02T15:04:05Z"),
+				gjson.GetBytes(signedCred, "proof.expires").String())
+
+			err = verifier.VerifyProof(signedCred, &models.ProofOptions{
+				SuiteType: ecdsa2019.SuiteType,
+				Purpose:   AssertionMethod,
+				ProofType: models.DataIntegrityProof,
+			})
+			require.NoError(t, err)
+		})
+
+		t.Run("custom proof fields", func(t *testing.T) {
+			// A full IRI, as the canonicalization drops the terms the context doesn't define.
+			const nonceField = "https://example.com/vocab/nonce"
+
+			signedCred, err := signer.AddProof(validCredential, &models.ProofOptions{
+				VerificationMethod:   p256VM,
+				VerificationMethodID: p256VM.ID,
+				SuiteType:            ecdsa2019.SuiteType,
+				Purpose:              AssertionMethod,
+				ProofType:            models.DataIntegrityProof,
+				Created:              time.Now(),
+				CustomFields:         map[string]interface{}{nonceField: "mock-nonce"},
+			})
+			require.NoError(t, err)
+
+			require.Equal(t, "mock-nonce",
+				gjson.GetBytes(signedCred, "proof.https://example\\.com/vocab/nonce").String())
+
+			verifyOpts := &models.ProofOptions{
+				SuiteType: ecdsa2019.SuiteType,
+				Purpose:   AssertionMethod,
+				ProofType: models.DataIntegrityProof,
+			}
+
+			err = verifier.VerifyProof(signedCred, verifyOpts)
+			require.NoError(t, err)
+
+			tampered, err := sjson.SetBytes(signedCred, "proof.https://example\\.com/vocab/nonce", "other-nonce")
+			require.NoError(t, err)
+
+			err = verifier.VerifyProof(tampered, verifyOpts)
+			require.Error(t, err)
+
+			withAddedField, err := sjson.SetBytes(signedCred, "proof.note", "added")
+			require.NoError(t, err)
+
+			verifyOpts.UnsignedProofFields = []string{"note"}
+
+			err = verifier.VerifyProof(withAddedField, verifyOpts)
+			require.NoError(t, err)
+
+			_, err = signer.AddProof(validCredential, &models.ProofOptions{
+				VerificationMethod:   p256VM,
+				VerificationMethodID: p256VM.ID,
+				SuiteType:            ecdsa2019.SuiteType,
+				Purpose:              AssertionMethod,
+				ProofType:            models.DataIntegrityProof,
+				CustomFields:         map[string]interface{}{"challenge": "mock-challenge"},
+			})
+			require.ErrorIs(t, err, ErrProofGeneration)
+			require.ErrorContains(t, err, `custom proof field "challenge" is reserved`)
+		})
+
+		t.Run("nonce", func(t *testing.T) {
+			// The context must define the nonce term, else the canonicalization drops it.
+			credential, err := sjson.SetBytes(validCredential, "@context.-1",
+				map[string]interface{}{"nonce": "https://w3id.org/security#nonce"})
+			require.NoError(t, err)
+
+			signOpts := &models.ProofOptions{
+				VerificationMethod:   p256VM,
+				VerificationMethodID: p256VM.ID,
+				SuiteType:            ecdsa2019.SuiteType,
+				Purpose:              AssertionMethod,
+				ProofType:            models.DataIntegrityProof,
+				Created:              time.Now(),
+				Nonce:                "mock-nonce",
+			}
+
+			signedCred, err := signer.AddProof(credential, signOpts)
+			require.NoError(t, err)
+			require.Equal(t, "mock-nonce", gjson.GetBytes(signedCred, "proof.nonce").String())
+
+			verifyOpts := &models.ProofOptions{
+				SuiteType: ecdsa2019.SuiteType,
+				Purpose:   AssertionMethod,
+				ProofType: models.DataIntegrityProof,
+				Nonce:     "mock-nonce",
+			}
+
+			err = verifier.VerifyProof(signedCred, verifyOpts)
+			require.NoError(t, err)
+
+			// An empty nonce is not checked.
+			verifyOpts.Nonce = ""
+
+			err = verifier.VerifyProof(signedCred, verifyOpts)
+			require.NoError(t, err)
+
+			verifyOpts.Nonce = "other-nonce"
+
+			err = verifier.VerifyProof(signedCred, verifyOpts)
+			require.ErrorIs(t, err, ErrInvalidNonce)
+			require.EqualError(t, err, `data integrity proof has invalid nonce: expected "other-nonce", got "mock-nonce"`)
+
+			// The nonce is signed over.
+			tampered, err := sjson.SetBytes(signedCred, "proof.nonce", "other-nonce")
+			require.NoError(t, err)
+
+			err = verifier.VerifyProof(tampered, verifyOpts)
+			require.ErrorIs(t, err, suite.ErrInvalidProof)
+
+			signOpts.Nonce = ""
+
+			withoutNonce, err := signer.AddProof(credential, signOpts)
+			require.NoError(t, err)
+			require.False(t, gjson.GetBytes(withoutNonce, "proof.nonce").Exists())
+
+			verifyOpts.Nonce = ""
+
+			err = verifier.VerifyProof(withoutNonce, verifyOpts)
+			require.NoError(t, err)
+
+			verifyOpts.Nonce = "mock-nonce"
+
+			err = verifier.VerifyProof(withoutNonce, verifyOpts)
+			require.ErrorIs(t, err, ErrInvalidNonce)
+		})
+
+		t.Run("graph-framed credential", func(t *testing.T) {
+			verifyOpts := &models.ProofOptions{
+				SuiteType: ecdsa2019.SuiteType,
+				Purpose:   AssertionMethod,
+				ProofType: models.DataIntegrityProof,
+			}
+
+			for _, suiteType := range []string{ecdsa2019.SuiteType, ecdsa2019.SuiteTypeJCS} {
+				signedCred, err := signer.AddProof(validGraphCredential, &models.ProofOptions{
+					VerificationMethod:   p256VM,
+					VerificationMethodID: p256VM.ID,
+					SuiteType:            suiteType,
+					Purpose:              AssertionMethod,
+					ProofType:            models.DataIntegrityProof,
+					Created:              time.Now(),
+				})
+				require.NoError(t, err, suiteType)
+
+				verifyOpts.SuiteType = suiteType
+
+				err = verifier.VerifyProof(signedCred, verifyOpts)
+				require.NoError(t, err, suiteType)
+
+				// Each node of the graph is signed.
+				for _, path := range []string{"@graph.0.credentialSubject.name", "@graph.1.name"} {
+					tampered, err := sjson.SetBytes(signedCred, path, "Other")
+					require.NoError(t, err)
+
+					err = verifier.VerifyProof(tampered, verifyOpts)
+					require.Error(t, err, suiteType+" "+path)
+				}
+			}
+
+			canonical, err := CanonicalizeForProof(validGraphCredential, ecdsa2019.SuiteType,
+				WithCanonicalizeDocumentLoader(docLoader))
+			require.NoError(t, err)
+			require.Contains(t, string(canonical),
+				`<did:example:76e12ec712ebc6f1c221ebfeb1f> <https://schema.org/name> "Example University" .`)
+		})
+
 		t.Run("P-384 key", func(t *testing.T) {
 			signOpts := &models.ProofOptions{
 				VerificationMethod:   p384VM,
@@ -133,6 +402,206 @@ func TestIntegration(t *testing.T) {
 			err = verifier.VerifyProof(signedCred, verifyOpts)
 			require.NoError(t, err)
 		})
+
+		t.Run("concurrent signing", func(t *testing.T) {
+			const signers = 100
+
+			// The signer and its options are shared by all goroutines.
+			signOpts := &models.ProofOptions{
+				VerificationMethodID: mockKID,
+				SuiteType:            ecdsa2019.SuiteTypeNew,
+				Purpose:              AssertionMethod,
+				ProofType:            models.DataIntegrityProof,
+			}
+
+			signedCreds := make([][]byte, signers)
+			signErrs := make([]error, signers)
+
+			var wg sync.WaitGroup
+
+			for i := range signers {
+				wg.Add(1)
+
+				go func() {
+					defer wg.Done()
+
+					cred, err := sjson.SetBytes(validCredential, "id", fmt.Sprintf("urn:uuid:credential-%d", i))
+					if err != nil {
+						signErrs[i] = err
+
+						return
+					}
+
+					signedCreds[i], signErrs[i] = signer.AddProof(cred, signOpts)
+				}()
+			}
+
+			wg.Wait()
+
+			require.Equal(t, &models.ProofOptions{
+				VerificationMethodID: mockKID,
+				SuiteType:            ecdsa2019.SuiteTypeNew,
+				Purpose:              AssertionMethod,
+				ProofType:            models.DataIntegrityProof,
+			}, signOpts)
+
+			for i, signedCred := range signedCreds {
+				require.NoError(t, signErrs[i])
+				require.Equal(t, fmt.Sprintf("urn:uuid:credential-%d", i), gjson.GetBytes(signedCred, "id").String())
+
+				require.NoError(t, verifier.VerifyProof(signedCred, &models.ProofOptions{
+					VerificationMethodID: mockKID,
+					SuiteType:            ecdsa2019.SuiteTypeNew,
+					Purpose:              AssertionMethod,
+					ProofType:            models.DataIntegrityProof,
+				}))
+			}
+		})
+	})
+
+	t.Run("proof chain", func(t *testing.T) {
+		// The VC 2.0 context defines the data integrity proof terms, so that a previous proof is signed over.
+		credential, err := sjson.SetBytes(validCredential, "@context", []string{"https://www.w3.org/ns/credentials/v2"})
+		require.NoError(t, err)
+
+		signedCred, err := signer.AddProof(credential, &models.ProofOptions{
+			VerificationMethod:   p256VM,
+			VerificationMethodID: p256VM.ID,
+			SuiteType:            ecdsa2019.SuiteType,
+			Purpose:              AssertionMethod,
+			ProofType:            models.DataIntegrityProof,
+			Created:              time.Now(),
+			ProofID:              "urn:uuid:proof-1",
+		})
+		require.NoError(t, err)
+
+		chainedCred, err := signer.AddProof(signedCred, &models.ProofOptions{
+			VerificationMethod:   p384VM,
+			VerificationMethodID: p384VM.ID,
+			SuiteType:            ecdsa2019.SuiteType,
+			Purpose:              AssertionMethod,
+			ProofType:            models.DataIntegrityProof,
+			Created:              time.Now(),
+			ProofID:              "urn:uuid:proof-2",
+			PreviousProof:        "urn:uuid:proof-1",
+		})
+		require.NoError(t, err)
+
+		proofs := gjson.GetBytes(chainedCred, "proof").Array()
+		require.Len(t, proofs, 2)
+		require.Equal(t, "urn:uuid:proof-1", proofs[0].Get("id").String())
+		require.Equal(t, "urn:uuid:proof-1", proofs[1].Get("previousProof").String())
+
+		verifyOpts := func() *models.ProofOptions {
+			return &models.ProofOptions{
+				SuiteType: ecdsa2019.SuiteType,
+				Purpose:   AssertionMethod,
+				ProofType: models.DataIntegrityProof,
+			}
+		}
+
+		require.NoError(t, verifier.VerifyProof(chainedCred, verifyOpts()))
+
+		t.Run("previous proof replaced", func(t *testing.T) {
+			otherSignedCred, err := signer.AddProof(credential, &models.ProofOptions{
+				VerificationMethod:   p256VM,
+				VerificationMethodID: p256VM.ID,
+				SuiteType:            ecdsa2019.SuiteType,
+				Purpose:              AssertionMethod,
+				ProofType:            models.DataIntegrityProof,
+				Created:              time.Now().Add(-time.Hour),
+				ProofID:              "urn:uuid:proof-1",
+			})
+			require.NoError(t, err)
+
+			// The replacing proof is valid, but the chained proof signs over the replaced one.
+			replaced, err := sjson.SetRawBytes(chainedCred, "proof.0",
+				[]byte(gjson.GetBytes(otherSignedCred, "proof").Raw))
+			require.NoError(t, err)
+
+			err = verifier.VerifyProof(replaced, verifyOpts())
+			require.ErrorIs(t, err, suite.ErrInvalidProof)
+		})
+
+		t.Run("previous proof missing", func(t *testing.T) {
+			withoutPrevious, err := sjson.DeleteBytes(chainedCred, "proof.0")
+			require.NoError(t, err)
+
+			err = verifier.VerifyProof(withoutPrevious, verifyOpts())
+			require.ErrorIs(t, err, ErrInvalidProofChain)
+		})
+
+		t.Run("previous proof after chained proof", func(t *testing.T) {
+			reordered, err := sjson.SetRawBytes(chainedCred, "proof",
+				[]byte("["+proofs[1].Raw+","+proofs[0].Raw+"]"))
+			require.NoError(t, err)
+
+			err = verifier.VerifyProof(reordered, verifyOpts())
+			require.ErrorIs(t, err, ErrInvalidProofChain)
+		})
+
+		t.Run("proof structure", func(t *testing.T) {
+			otherSignedCred, err := signer.AddProof(credential, &models.ProofOptions{
+				VerificationMethod:   p384VM,
+				VerificationMethodID: p384VM.ID,
+				SuiteType:            ecdsa2019.SuiteType,
+				Purpose:              AssertionMethod,
+				ProofType:            models.DataIntegrityProof,
+				Created:              time.Now(),
+				ProofID:              "urn:uuid:proof-2",
+			})
+			require.NoError(t, err)
+
+			proofSetCred, err := sjson.SetRawBytes(signedCred, "proof", []byte("["+proofs[0].Raw+","+
+				gjson.GetBytes(otherSignedCred, "proof").Raw+"]"))
+			require.NoError(t, err)
+
+			structureOpts := func(structure models.ProofStructure) *models.ProofOptions {
+				opts := verifyOpts()
+				opts.ProofStructure = structure
+
+				return opts
+			}
+
+			require.NoError(t, verifier.VerifyProof(proofSetCred, structureOpts(models.ProofSet)))
+			require.NoError(t, verifier.VerifyProof(chainedCred, structureOpts(models.ProofChain)))
+			require.NoError(t, verifier.VerifyProof(signedCred, structureOpts(models.ProofChain)))
+
+			err = verifier.VerifyProof(chainedCred, structureOpts(models.ProofSet))
+			require.ErrorIs(t, err, ErrInvalidProofChain)
+			require.ErrorContains(t, err, "proof [1] of proof set is chained to previous proof \"urn:uuid:proof-1\"")
+
+			err = verifier.VerifyProof(proofSetCred, structureOpts(models.ProofChain))
+			require.ErrorIs(t, err, ErrInvalidProofChain)
+			require.ErrorContains(t, err, "proof [1] of proof chain is not chained to the proof preceding it")
+
+			withoutPrevious, err := sjson.DeleteBytes(chainedCred, "proof.0")
+			require.NoError(t, err)
+
+			err = verifier.VerifyProof(withoutPrevious, structureOpts(models.ProofChain))
+			require.ErrorIs(t, err, ErrInvalidProofChain)
+			require.ErrorContains(t, err, "first proof of proof chain is chained to previous proof")
+
+			// A broken signature of a well-formed chain is an invalid proof, not a broken chain.
+			tampered, err := sjson.SetBytes(chainedCred, "proof.1.created", time.Now().Add(-time.Hour).Format(time.RFC3339))
+			require.NoError(t, err)
+
+			err = verifier.VerifyProof(tampered, structureOpts(models.ProofChain))
+			require.ErrorIs(t, err, suite.ErrInvalidProof)
+			require.NotErrorIs(t, err, ErrInvalidProofChain)
+		})
+
+		t.Run("sign with unknown previous proof", func(t *testing.T) {
+			_, err := signer.AddProof(signedCred, &models.ProofOptions{
+				VerificationMethod:   p384VM,
+				VerificationMethodID: p384VM.ID,
+				SuiteType:            ecdsa2019.SuiteType,
+				Purpose:              AssertionMethod,
+				ProofType:            models.DataIntegrityProof,
+				PreviousProof:        "urn:uuid:proof-3",
+			})
+			require.ErrorIs(t, err, ErrInvalidProofChain)
+		})
 	})
 
 	t.Run("failure", func(t *testing.T) {
@@ -193,6 +662,724 @@ func TestIntegration(t *testing.T) {
 	})
 }
 
+func TestIntegration_ContextCache(t *testing.T) {
+	docLoader, err := documentloader.NewDocumentLoader(createMockProvider())
+	require.NoError(t, err)
+
+	kmsCrypto := kmscryptoutil.LocalKMSCrypto(t)
+
+	p256JWK, err := kmsCrypto.Create(kmsapi.ECDSAP256IEEEP1363)
+	require.NoError(t, err)
+
+	p256VM, err := did.NewVerificationMethodFromJWK(mockKID, "JsonWebKey2020", mockDID, p256JWK)
+	require.NoError(t, err)
+
+	resolver := resolveFunc(func(id string) (*did.DocResolution, error) {
+		return makeMockDIDResolution(id, p256VM, did.AssertionMethod), nil
+	})
+
+	signer, err := NewSigner(&Options{DIDResolver: resolver},
+		ecdsa2019.NewSignerInitializer(&ecdsa2019.SignerInitializerOptions{
+			LDDocumentLoader: docLoader,
+			SignerGetter:     ecdsa2019.WithKMSCryptoWrapper(kmsCrypto),
+		}))
+	require.NoError(t, err)
+
+	signedCred, err := signer.AddProof(validCredential, &models.ProofOptions{
+		VerificationMethodID: mockKID,
+		SuiteType:            ecdsa2019.SuiteType,
+		Purpose:              AssertionMethod,
+		ProofType:            models.DataIntegrityProof,
+		Created:              time.Now(),
+	})
+	require.NoError(t, err)
+
+	loader := &countingLoader{loader: docLoader, loads: map[string]int{}}
+
+	verifier, err := NewVerifier((&Options{DIDResolver: resolver}).WithContextCache(10),
+		ecdsa2019.NewVerifierInitializer(&ecdsa2019.VerifierInitializerOptions{
+			LDDocumentLoader: loader,
+		}))
+	require.NoError(t, err)
+
+	verifyOpts := func() *models.ProofOptions {
+		return &models.ProofOptions{
+			Purpose:   AssertionMethod,
+			ProofType: models.DataIntegrityProof,
+		}
+	}
+
+	require.NoError(t, verifier.VerifyProof(signedCred, verifyOpts()))
+
+	loads := loader.total()
+	require.NotZero(t, loads)
+
+	var wg sync.WaitGroup
+
+	errs := make(chan error, 20)
+
+	for i := 0; i < 20; i++ {
+		wg.Add(1)
+
+		go func() {
+			defer wg.Done()
+
+			errs <- verifier.VerifyProof(signedCred, verifyOpts())
+		}()
+	}
+
+	wg.Wait()
+	close(errs)
+
+	for e := range errs {
+		require.NoError(t, e)
+	}
+
+	require.Equal(t, loads, loader.total())
+
+	t.Run("signer", func(t *testing.T) {
+		signLoader := &countingLoader{loader: docLoader, loads: map[string]int{}}
+
+		cachingSigner, e := NewSigner((&Options{DIDResolver: resolver}).WithContextCache(10),
+			ecdsa2019.NewSignerInitializer(&ecdsa2019.SignerInitializerOptions{
+				LDDocumentLoader: signLoader,
+				SignerGetter:     ecdsa2019.WithKMSCryptoWrapper(kmsCrypto),
+			}))
+		require.NoError(t, e)
+
+		signOpts := &models.ProofOptions{
+			VerificationMethodID: mockKID,
+			SuiteType:            ecdsa2019.SuiteType,
+			Purpose:              AssertionMethod,
+			ProofType:            models.DataIntegrityProof,
+		}
+
+		_, e = cachingSigner.AddProof(validCredential, signOpts)
+		require.NoError(t, e)
+
+		signLoads := signLoader.total()
+		require.NotZero(t, signLoads)
+
+		signed, e := cachingSigner.AddProof(validCredential, signOpts)
+		require.NoError(t, e)
+		require.Equal(t, signLoads, signLoader.total())
+
+		require.NoError(t, verifier.VerifyProof(signed, verifyOpts()))
+	})
+}
+
+func TestIntegration_Multikey(t *testing.T) {
+	docLoader, err := documentloader.NewDocumentLoader(createMockProvider())
+	require.NoError(t, err)
+
+	kmsCrypto := kmscryptoutil.LocalKMSCrypto(t)
+
+	tests := []struct {
+		name      string
+		keyType   kmsapi.KeyType
+		suiteType string
+		codec     []byte
+	}{
+		{
+			name:      "P-256",
+			keyType:   kmsapi.ECDSAP256IEEEP1363,
+			suiteType: ecdsa2019.SuiteTypeNew,
+			codec:     []byte{0x80, 0x24},
+		},
+		{
+			name:      "P-384",
+			keyType:   kmsapi.ECDSAP384IEEEP1363,
+			suiteType: ecdsa2019.SuiteTypeNew,
+			codec:     []byte{0x81, 0x24},
+		},
+		{
+			name:      "P-521",
+			keyType:   kmsapi.ECDSAP521IEEEP1363,
+			suiteType: ecdsa2019.SuiteTypeNew,
+			codec:     []byte{0x82, 0x24},
+		},
+		{
+			name:      "Ed25519",
+			keyType:   kmsapi.ED25519Type,
+			suiteType: eddsa2022.SuiteType,
+			codec:     []byte{0xed, 0x01},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			pubJWK, err := kmsCrypto.Create(tc.keyType)
+			require.NoError(t, err)
+
+			var rawKey []byte
+
+			switch key := pubJWK.Key.(type) {
+			case *ecdsa.PublicKey:
+				rawKey = elliptic.MarshalCompressed(key.Curve, key.X, key.Y)
+			case ed25519.PublicKey:
+				rawKey = key
+			default:
+				require.Failf(t, "unexpected key type", "%T", key)
+			}
+
+			publicKeyMultibase, err := multibase.Encode(multibase.Base58BTC, append(tc.codec, rawKey...))
+			require.NoError(t, err)
+
+			didKey := "did:key:" + publicKeyMultibase
+			vmID := didKey + "#" + publicKeyMultibase
+
+			didDoc, err := did.ParseDocument([]byte(fmt.Sprintf(`{
+				"@context": ["https://www.w3.org/ns/did/v1", "https://w3id.org/security/multikey/v1"],
+				"id": %[1]q,
+				"verificationMethod": [{
+					"id": %[2]q,
+					"type": "Multikey",
+					"controller": %[1]q,
+					"publicKeyMultibase": %[3]q
+				}],
+				"assertionMethod": [%[2]q]
+			}`, didKey, vmID, publicKeyMultibase)))
+			require.NoError(t, err)
+
+			resolver := resolveFunc(func(id string) (*did.DocResolution, error) {
+				return &did.DocResolution{DIDDocument: didDoc}, nil
+			})
+
+			// The proof is created with the JWK of the key, and verified with its Multikey.
+			signingVM, err := did.NewVerificationMethodFromJWK(vmID, "JsonWebKey2020", didKey, pubJWK)
+			require.NoError(t, err)
+
+			var (
+				signerInit   suite.SignerInitializer
+				verifierInit suite.VerifierInitializer
+			)
+
+			if tc.suiteType == eddsa2022.SuiteType {
+				signerInit = eddsa2022.NewSignerInitializer(&eddsa2022.SignerInitializerOptions{
+					LDDocumentLoader: docLoader,
+					SignerGetter:     eddsa2022.WithKMSCryptoWrapper(kmsCrypto),
+				})
+				verifierInit = eddsa2022.NewVerifierInitializer(&eddsa2022.VerifierInitializerOptions{
+					LDDocumentLoader: docLoader,
+				})
+			} else {
+				signerInit = ecdsa2019.NewSignerInitializer(&ecdsa2019.SignerInitializerOptions{
+					LDDocumentLoader: docLoader,
+					SignerGetter:     ecdsa2019.WithKMSCryptoWrapper(kmsCrypto),
+				})
+				verifierInit = ecdsa2019.NewVerifierInitializer(&ecdsa2019.VerifierInitializerOptions{
+					LDDocumentLoader: docLoader,
+				})
+			}
+
+			signer, err := NewSigner(&Options{DIDResolver: resolver}, signerInit)
+			require.NoError(t, err)
+
+			verifier, err := NewVerifier(&Options{DIDResolver: resolver}, verifierInit)
+			require.NoError(t, err)
+
+			signedCred, err := signer.AddProof(validCredential, &models.ProofOptions{
+				VerificationMethod:   signingVM,
+				VerificationMethodID: vmID,
+				SuiteType:            tc.suiteType,
+				Purpose:              AssertionMethod,
+				ProofType:            models.DataIntegrityProof,
+				Created:              time.Now(),
+			})
+			require.NoError(t, err)
+			require.Equal(t, vmID, gjson.GetBytes(signedCred, "proof.verificationMethod").String())
+
+			require.NoError(t, verifier.VerifyProof(signedCred, &models.ProofOptions{
+				Purpose:   AssertionMethod,
+				ProofType: models.DataIntegrityProof,
+			}))
+
+			tamperedCred, err := sjson.SetBytes(signedCred, "issuer", "did:example:other")
+			require.NoError(t, err)
+
+			require.Error(t, verifier.VerifyProof(tamperedCred, &models.ProofOptions{
+				Purpose:   AssertionMethod,
+				ProofType: models.DataIntegrityProof,
+			}))
+		})
+	}
+}
+
+func TestIntegration_VerifyProofWithKey(t *testing.T) {
+	docLoader, err := documentloader.NewDocumentLoader(createMockProvider())
+	require.NoError(t, err)
+
+	kmsCrypto := kmscryptoutil.LocalKMSCrypto(t)
+
+	tests := []struct {
+		name         string
+		keyType      kmsapi.KeyType
+		suiteType    string
+		signerInit   suite.SignerInitializer
+		verifierInit suite.VerifierInitializer
+	}{
+		{
+			name:      "P-256",
+			keyType:   kmsapi.ECDSAP256IEEEP1363,
+			suiteType: ecdsa2019.SuiteType,
+			signerInit: ecdsa2019.NewSignerInitializer(&ecdsa2019.SignerInitializerOptions{
+				LDDocumentLoader: docLoader,
+				SignerGetter:     ecdsa2019.WithKMSCryptoWrapper(kmsCrypto),
+			}),
+			verifierInit: ecdsa2019.NewVerifierInitializer(&ecdsa2019.VerifierInitializerOptions{
+				LDDocumentLoader: docLoader,
+			}),
+		},
+		{
+			name:      "Ed25519",
+			keyType:   kmsapi.ED25519Type,
+			suiteType: eddsa2022.SuiteType,
+			signerInit: eddsa2022.NewSignerInitializer(&eddsa2022.SignerInitializerOptions{
+				LDDocumentLoader: docLoader,
+				SignerGetter:     eddsa2022.WithKMSCryptoWrapper(kmsCrypto),
+			}),
+			verifierInit: eddsa2022.NewVerifierInitializer(&eddsa2022.VerifierInitializerOptions{
+				LDDocumentLoader: docLoader,
+			}),
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			pubJWK, err := kmsCrypto.Create(tc.keyType)
+			require.NoError(t, err)
+
+			otherJWK, err := kmsCrypto.Create(tc.keyType)
+			require.NoError(t, err)
+
+			signingVM, err := did.NewVerificationMethodFromJWK(mockKID, "JsonWebKey2020", mockDID, pubJWK)
+			require.NoError(t, err)
+
+			signer, err := NewSigner(&Options{}, tc.signerInit)
+			require.NoError(t, err)
+
+			// No DID resolver: the verification method of the proof is never resolved.
+			verifier, err := NewVerifier(&Options{}, tc.verifierInit)
+			require.NoError(t, err)
+
+			signedCred, err := signer.AddProof(validCredential, &models.ProofOptions{
+				VerificationMethod:   signingVM,
+				VerificationMethodID: mockKID,
+				SuiteType:            tc.suiteType,
+				Purpose:              Authentication,
+				ProofType:            models.DataIntegrityProof,
+				Created:              time.Now(),
+				Domain:               "https://example.com",
+				Challenge:            "nonce",
+			})
+			require.NoError(t, err)
+
+			require.NoError(t, verifier.VerifyProofWithKey(signedCred, pubJWK.Key, tc.suiteType))
+
+			err = verifier.VerifyProofWithKey(signedCred, otherJWK.Key, tc.suiteType)
+			require.ErrorIs(t, err, suite.ErrInvalidProof)
+
+			tamperedCred, err := sjson.SetBytes(signedCred, "issuer", "did:example:other")
+			require.NoError(t, err)
+
+			err = verifier.VerifyProofWithKey(tamperedCred, pubJWK.Key, tc.suiteType)
+			require.ErrorIs(t, err, suite.ErrInvalidProof)
+
+			err = verifier.VerifyProofWithKey(signedCred, pubJWK.Key, "other-suite-2023")
+			require.ErrorIs(t, err, ErrUnsupportedSuite)
+
+			err = verifier.VerifyProofWithKey(validCredential, pubJWK.Key, tc.suiteType)
+			require.ErrorIs(t, err, ErrMissingProof)
+		})
+	}
+
+	t.Run("unsupported key", func(t *testing.T) {
+		verifier, err := NewVerifier(&Options{})
+		require.NoError(t, err)
+
+		err = verifier.VerifyProofWithKey(validCredential, "not a key", ecdsa2019.SuiteType)
+		require.ErrorContains(t, err, "convert public key to JWK")
+	})
+}
+
+func TestIntegration_VerifyProofSignature(t *testing.T) {
+	docLoader, err := documentloader.NewDocumentLoader(createMockProvider())
+	require.NoError(t, err)
+
+	kmsCrypto := kmscryptoutil.LocalKMSCrypto(t)
+
+	pubJWK, err := kmsCrypto.Create(kmsapi.ECDSAP256IEEEP1363)
+	require.NoError(t, err)
+
+	otherJWK, err := kmsCrypto.Create(kmsapi.ECDSAP256IEEEP1363)
+	require.NoError(t, err)
+
+	signingVM, err := did.NewVerificationMethodFromJWK(mockKID, "JsonWebKey2020", mockDID, pubJWK)
+	require.NoError(t, err)
+
+	signer, err := NewSigner(&Options{}, ecdsa2019.NewSignerInitializer(&ecdsa2019.SignerInitializerOptions{
+		LDDocumentLoader: docLoader,
+		SignerGetter:     ecdsa2019.WithKMSCryptoWrapper(kmsCrypto),
+	}))
+	require.NoError(t, err)
+
+	resolver := resolveFunc(func(string) (*did.DocResolution, error) {
+		require.FailNow(t, "the verification method is resolved")
+
+		return nil, ErrVMResolution
+	})
+
+	// The verification method of the proof, and its verification relationship, are never resolved.
+	verifier, err := NewVerifier(&Options{DIDResolver: resolver},
+		ecdsa2019.NewVerifierInitializer(&ecdsa2019.VerifierInitializerOptions{
+			LDDocumentLoader: docLoader,
+		}))
+	require.NoError(t, err)
+
+	signedCred, err := signer.AddProof(validCredential, &models.ProofOptions{
+		VerificationMethod:   signingVM,
+		VerificationMethodID: mockKID,
+		SuiteType:            ecdsa2019.SuiteType,
+		Purpose:              Authentication,
+		ProofType:            models.DataIntegrityProof,
+		Created:              time.Now().Add(-2 * time.Hour),
+		Expires:              time.Now().Add(-time.Hour),
+		Challenge:            "nonce",
+	})
+	require.NoError(t, err)
+
+	trustedKeys := func(keys map[string]interface{}) KeyLookup {
+		return func(vmID string) (crypto.PublicKey, bool) {
+			key, ok := keys[vmID]
+
+			return key, ok
+		}
+	}
+
+	t.Run("success", func(t *testing.T) {
+		// The expired proof verifies, as only its signature is checked.
+		require.NoError(t, verifier.VerifyProofSignature(signedCred, trustedKeys(map[string]interface{}{
+			mockKID: pubJWK.Key,
+		})))
+	})
+
+	t.Run("wrong key", func(t *testing.T) {
+		err = verifier.VerifyProofSignature(signedCred, trustedKeys(map[string]interface{}{
+			mockKID: otherJWK.Key,
+		}))
+		require.ErrorIs(t, err, suite.ErrInvalidProof)
+	})
+
+	t.Run("tampered document", func(t *testing.T) {
+		tamperedCred, e := sjson.SetBytes(signedCred, "issuer", "did:example:other")
+		require.NoError(t, e)
+
+		err = verifier.VerifyProofSignature(tamperedCred, trustedKeys(map[string]interface{}{
+			mockKID: pubJWK.Key,
+		}))
+		require.ErrorIs(t, err, suite.ErrInvalidProof)
+	})
+
+	t.Run("unknown key", func(t *testing.T) {
+		err = verifier.VerifyProofSignature(signedCred, trustedKeys(map[string]interface{}{
+			mockKID2: pubJWK.Key,
+		}))
+		require.ErrorIs(t, err, ErrVMResolution)
+		require.ErrorContains(t, err, "key of "+mockKID+" is not known")
+	})
+
+	t.Run("unsupported key", func(t *testing.T) {
+		err = verifier.VerifyProofSignature(signedCred, trustedKeys(map[string]interface{}{
+			mockKID: "not a key",
+		}))
+		require.ErrorContains(t, err, "convert public key to JWK")
+	})
+
+	t.Run("full verification", func(t *testing.T) {
+		err = verifier.VerifyProof(signedCred, &models.ProofOptions{
+			VerificationMethod: signingVM,
+			Purpose:            Authentication,
+			ProofType:          models.DataIntegrityProof,
+		})
+		require.ErrorIs(t, err, ErrExpired)
+	})
+}
+
+func TestIntegration_TryAllAssertionKeys(t *testing.T) {
+	docLoader, err := documentloader.NewDocumentLoader(createMockProvider())
+	require.NoError(t, err)
+
+	kmsCrypto := kmscryptoutil.LocalKMSCrypto(t)
+
+	const rotatedDID = "did:test:rotated"
+
+	newVM := func(id string) *did.VerificationMethod {
+		key, e := kmsCrypto.Create(kmsapi.ECDSAP256IEEEP1363)
+		require.NoError(t, e)
+
+		vm, e := did.NewVerificationMethodFromJWK(id, "JsonWebKey2020", rotatedDID, key)
+		require.NoError(t, e)
+
+		return vm
+	}
+
+	// The key of #key-1 is rotated: the previous key is now listed as #key-2.
+	newKey := newVM(rotatedDID + "#key-1")
+	previousKey := newVM(rotatedDID + "#key-2")
+
+	resolver := resolveFunc(func(id string) (*did.DocResolution, error) {
+		if id != rotatedDID {
+			return nil, ErrVMResolution
+		}
+
+		return &did.DocResolution{DIDDocument: &did.Doc{
+			ID: rotatedDID,
+			AssertionMethod: []did.Verification{
+				{VerificationMethod: *newKey, Relationship: did.AssertionMethod},
+				{VerificationMethod: *previousKey, Relationship: did.AssertionMethod},
+			},
+		}}, nil
+	})
+
+	signer, err := NewSigner(&Options{DIDResolver: resolver},
+		ecdsa2019.NewSignerInitializer(&ecdsa2019.SignerInitializerOptions{
+			LDDocumentLoader: docLoader,
+			SignerGetter:     ecdsa2019.WithKMSCryptoWrapper(kmsCrypto),
+		}))
+	require.NoError(t, err)
+
+	verifier, err := NewVerifier(&Options{DIDResolver: resolver},
+		ecdsa2019.NewVerifierInitializer(&ecdsa2019.VerifierInitializerOptions{
+			LDDocumentLoader: docLoader,
+		}))
+	require.NoError(t, err)
+
+	// sign signs with the key of signingKey under the verification method ID vmID.
+	sign := func(t *testing.T, vmID string, signingKey *did.VerificationMethod) []byte {
+		t.Helper()
+
+		vm := *signingKey
+		vm.ID = vmID
+
+		signedCred, e := signer.AddProof(validCredential, &models.ProofOptions{
+			VerificationMethod:   &vm,
+			VerificationMethodID: vmID,
+			SuiteType:            ecdsa2019.SuiteType,
+			Purpose:              AssertionMethod,
+			ProofType:            models.DataIntegrityProof,
+			Created:              time.Now(),
+		})
+		require.NoError(t, e)
+
+		return signedCred
+	}
+
+	verifyOpts := func(tryAll bool) *models.ProofOptions {
+		return &models.ProofOptions{
+			SuiteType:           ecdsa2019.SuiteType,
+			Purpose:             AssertionMethod,
+			ProofType:           models.DataIntegrityProof,
+			TryAllAssertionKeys: tryAll,
+		}
+	}
+
+	t.Run("referenced key verifies", func(t *testing.T) {
+		detail, e := verifier.VerifyProofDetailed(sign(t, newKey.ID, newKey), verifyOpts(true))
+		require.NoError(t, e)
+		require.Equal(t, newKey.ID, detail.VerificationMethodID)
+		require.Empty(t, detail.MatchedVerificationMethodID)
+	})
+
+	t.Run("re-keyed method ID", func(t *testing.T) {
+		signedCred := sign(t, newKey.ID, previousKey)
+
+		require.ErrorIs(t, verifier.VerifyProof(signedCred, verifyOpts(false)), suite.ErrInvalidProof)
+
+		detail, e := verifier.VerifyProofDetailed(signedCred, verifyOpts(true))
+		require.NoError(t, e)
+		require.Equal(t, newKey.ID, detail.VerificationMethodID)
+		require.Equal(t, previousKey.ID, detail.MatchedVerificationMethodID)
+		require.Equal(t, rotatedDID, detail.SignerDID)
+	})
+
+	t.Run("removed method ID", func(t *testing.T) {
+		signedCred := sign(t, rotatedDID+"#key-0", previousKey)
+
+		require.ErrorIs(t, verifier.VerifyProof(signedCred, verifyOpts(false)), ErrVMResolution)
+
+		detail, e := verifier.VerifyProofDetailed(signedCred, verifyOpts(true))
+		require.NoError(t, e)
+		require.Equal(t, rotatedDID+"#key-0", detail.VerificationMethodID)
+		require.Equal(t, previousKey.ID, detail.MatchedVerificationMethodID)
+	})
+
+	t.Run("no key verifies", func(t *testing.T) {
+		otherKey := newVM(rotatedDID + "#other")
+
+		err := verifier.VerifyProof(sign(t, newKey.ID, otherKey), verifyOpts(true))
+		require.ErrorIs(t, err, suite.ErrInvalidProof)
+
+		err = verifier.VerifyProof(sign(t, rotatedDID+"#key-0", otherKey), verifyOpts(true))
+		require.ErrorIs(t, err, ErrVMResolution)
+
+		signedCred := sign(t, newKey.ID, previousKey)
+		signedCred, err = sjson.SetBytes(signedCred, "proof.verificationMethod", "did:test:unknown#key-1")
+		require.NoError(t, err)
+
+		err = verifier.VerifyProof(signedCred, verifyOpts(true))
+		require.ErrorIs(t, err, ErrVMResolution)
+	})
+}
+
+func TestIntegration_PrepareProof(t *testing.T) {
+	docLoader, err := documentloader.NewDocumentLoader(createMockProvider())
+	require.NoError(t, err)
+
+	// The keys are used as by a device that only signs digests.
+	p256Key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+
+	ed25519Pub, ed25519Priv, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+
+	tests := []struct {
+		name      string
+		pub       interface{}
+		suiteType string
+		sign      func(sigBase []byte) []byte
+	}{
+		{
+			name:      "P-256",
+			pub:       &p256Key.PublicKey,
+			suiteType: ecdsa2019.SuiteTypeNew,
+			sign: func(sigBase []byte) []byte {
+				digest := sha256.Sum256(sigBase)
+
+				r, s, e := ecdsa.Sign(rand.Reader, p256Key, digest[:])
+				require.NoError(t, e)
+
+				return append(r.FillBytes(make([]byte, 32)), s.FillBytes(make([]byte, 32))...)
+			},
+		},
+		{
+			name:      "Ed25519",
+			pub:       ed25519Pub,
+			suiteType: eddsa2022.SuiteType,
+			sign: func(sigBase []byte) []byte {
+				return ed25519.Sign(ed25519Priv, sigBase)
+			},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			pubJWK, err := jwksupport.JWKFromKey(tc.pub)
+			require.NoError(t, err)
+
+			vm, err := did.NewVerificationMethodFromJWK(mockKID, "JsonWebKey2020", mockDID, pubJWK)
+			require.NoError(t, err)
+
+			resolver := resolveFunc(func(id string) (*did.DocResolution, error) {
+				return makeMockDIDResolution(id, vm, did.AssertionMethod), nil
+			})
+
+			// No key signer is needed to prepare proofs.
+			signer, err := NewSigner(&Options{DIDResolver: resolver},
+				ecdsa2019.NewSignerInitializer(&ecdsa2019.SignerInitializerOptions{LDDocumentLoader: docLoader}),
+				eddsa2022.NewSignerInitializer(&eddsa2022.SignerInitializerOptions{LDDocumentLoader: docLoader}))
+			require.NoError(t, err)
+
+			verifier, err := NewVerifier(&Options{DIDResolver: resolver},
+				ecdsa2019.NewVerifierInitializer(&ecdsa2019.VerifierInitializerOptions{LDDocumentLoader: docLoader}),
+				eddsa2022.NewVerifierInitializer(&eddsa2022.VerifierInitializerOptions{LDDocumentLoader: docLoader}))
+			require.NoError(t, err)
+
+			sigBase, finalize, err := signer.PrepareProof(validCredential, &models.ProofOptions{
+				VerificationMethodID: mockKID,
+				SuiteType:            tc.suiteType,
+				Purpose:              AssertionMethod,
+				ProofType:            models.DataIntegrityProof,
+			})
+			require.NoError(t, err)
+
+			proof, err := finalize(tc.sign(sigBase))
+			require.NoError(t, err)
+			require.Equal(t, tc.suiteType, proof.CryptoSuite)
+			require.Equal(t, mockKID, proof.VerificationMethod)
+			require.NotEmpty(t, proof.Created)
+
+			proofBytes, err := json.Marshal(proof)
+			require.NoError(t, err)
+
+			signedCred, err := sjson.SetRawBytes(validCredential, "proof", proofBytes)
+			require.NoError(t, err)
+
+			require.NoError(t, verifier.VerifyProof(signedCred, &models.ProofOptions{
+				Purpose:   AssertionMethod,
+				ProofType: models.DataIntegrityProof,
+			}))
+
+			// The signature of other data doesn't verify.
+			proof, err = finalize(tc.sign([]byte("other data")))
+			require.NoError(t, err)
+
+			proofBytes, err = json.Marshal(proof)
+			require.NoError(t, err)
+
+			signedCred, err = sjson.SetRawBytes(validCredential, "proof", proofBytes)
+			require.NoError(t, err)
+
+			require.ErrorIs(t, verifier.VerifyProof(signedCred, &models.ProofOptions{
+				Purpose:   AssertionMethod,
+				ProofType: models.DataIntegrityProof,
+			}), suite.ErrInvalidProof)
+		})
+	}
+
+	t.Run("unsupported suite", func(t *testing.T) {
+		signer, err := NewSigner(&Options{}, &mockSuiteInitializer{
+			mockSuite: &mockSuite{},
+			typeStr:   mockSuiteType,
+		})
+		require.NoError(t, err)
+
+		_, _, err = signer.PrepareProof(validCredential, &models.ProofOptions{
+			VerificationMethod: &did.VerificationMethod{ID: mockKID},
+			SuiteType:          mockSuiteType,
+			Purpose:            AssertionMethod,
+		})
+		require.ErrorIs(t, err, ErrUnsupportedSuite)
+		require.ErrorContains(t, err, "mock-suite-2023 suite doesn't support signing prepared proofs")
+	})
+}
+
+type countingLoader struct {
+	mu     sync.Mutex
+	loader ld.DocumentLoader
+	loads  map[string]int
+}
+
+func (l *countingLoader) LoadDocument(u string) (*ld.RemoteDocument, error) {
+	l.mu.Lock()
+	l.loads[u]++
+	l.mu.Unlock()
+
+	return l.loader.LoadDocument(u)
+}
+
+func (l *countingLoader) total() int {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	total := 0
+
+	for _, n := range l.loads {
+		total += n
+	}
+
+	return total
+}
+
 type provider struct {
 	ContextStore        store.ContextStore
 	RemoteProviderStore store.RemoteProviderStore
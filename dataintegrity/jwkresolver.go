@@ -0,0 +1,73 @@
+/*
+Copyright Gen Digital Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package dataintegrity
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/trustbloc/did-go/doc/did"
+	didjwk "github.com/trustbloc/did-go/method/jwk"
+	vdrapi "github.com/trustbloc/did-go/vdr/api"
+)
+
+const jwkDIDPrefix = "did:jwk:"
+
+// JWKResolver is a DIDResolver of did:jwk DIDs, whose DID document is derived from the JWK encoded
+// into the DID, as specified by the did:jwk method: https://github.com/quartzjer/did-jwk. It doesn't
+// make any network call. The JWK must be an EC or OKP public key, its verification method is the
+// DID with the fragment #0.
+type JWKResolver struct{}
+
+// NewJWKResolver creates a JWKResolver.
+func NewJWKResolver() *JWKResolver {
+	return &JWKResolver{}
+}
+
+// Resolve returns the DID document of a did:jwk DID. The DID method options are ignored.
+func (r *JWKResolver) Resolve(didID string, _ ...vdrapi.DIDMethodOption) (*did.DocResolution, error) {
+	methodID, found := strings.CutPrefix(didID, jwkDIDPrefix)
+	if !found {
+		return nil, fmt.Errorf("%q is not a did:jwk DID", didID)
+	}
+
+	if strings.ContainsAny(methodID, ":/?#") {
+		return nil, fmt.Errorf("did:jwk DID %q should not have a path, query or fragment", didID)
+	}
+
+	keyBytes, err := base64.RawURLEncoding.DecodeString(methodID)
+	if err != nil {
+		return nil, fmt.Errorf("resolve %s: decode JWK: %w", didID, err)
+	}
+
+	var key struct {
+		Kty string `json:"kty"`
+		D   string `json:"d"`
+	}
+
+	err = json.Unmarshal(keyBytes, &key)
+	if err != nil {
+		return nil, fmt.Errorf("resolve %s: unmarshal JWK: %w", didID, err)
+	}
+
+	if key.Kty != "EC" && key.Kty != "OKP" {
+		return nil, fmt.Errorf("resolve %s: unsupported JWK key type %q", didID, key.Kty)
+	}
+
+	if key.D != "" {
+		return nil, fmt.Errorf("resolve %s: JWK is a private key", didID)
+	}
+
+	resolution, err := didjwk.New().Read(didID)
+	if err != nil {
+		return nil, fmt.Errorf("resolve %s: %w", didID, err)
+	}
+
+	return resolution, nil
+}
@@ -0,0 +1,134 @@
+/*
+Copyright Gen Digital Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package dataintegrity
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"github.com/tidwall/sjson"
+	"github.com/trustbloc/did-go/doc/ld/documentloader"
+	kmsapi "github.com/trustbloc/kms-go/spi/kms"
+
+	"github.com/trustbloc/vc-go/dataintegrity/models"
+	"github.com/trustbloc/vc-go/dataintegrity/suite"
+	"github.com/trustbloc/vc-go/dataintegrity/suite/ecdsa2019"
+	"github.com/trustbloc/vc-go/internal/testutil/kmscryptoutil"
+)
+
+func jwkDID(t *testing.T, key interface{}) string {
+	t.Helper()
+
+	keyBytes, err := json.Marshal(key)
+	require.NoError(t, err)
+
+	return jwkDIDPrefix + base64.RawURLEncoding.EncodeToString(keyBytes)
+}
+
+func TestJWKResolver(t *testing.T) {
+	kmsCrypto := kmscryptoutil.LocalKMSCrypto(t)
+
+	p256Key, err := kmsCrypto.Create(kmsapi.ECDSAP256IEEEP1363)
+	require.NoError(t, err)
+
+	ed25519Key, err := kmsCrypto.Create(kmsapi.ED25519)
+	require.NoError(t, err)
+
+	resolver := NewJWKResolver()
+
+	t.Run("success", func(t *testing.T) {
+		for name, key := range map[string]interface{}{"EC key": p256Key, "OKP key": ed25519Key} {
+			didID := jwkDID(t, key)
+
+			res, err := resolver.Resolve(didID)
+			require.NoError(t, err, name)
+			require.Equal(t, didID, res.DIDDocument.ID, name)
+			require.Len(t, res.DIDDocument.VerificationMethod, 1, name)
+			require.Equal(t, didID+"#0", res.DIDDocument.VerificationMethod[0].ID, name)
+			require.Len(t, res.DIDDocument.AssertionMethod, 1, name)
+		}
+	})
+
+	t.Run("verify ecdsa-rdfc-2019 proof of a did:jwk issuer", func(t *testing.T) {
+		res, err := resolver.Resolve(jwkDID(t, p256Key))
+		require.NoError(t, err)
+
+		vm := &res.DIDDocument.VerificationMethod[0]
+
+		docLoader, err := documentloader.NewDocumentLoader(createMockProvider())
+		require.NoError(t, err)
+
+		signer, err := NewSigner(&Options{}, ecdsa2019.NewSignerInitializer(&ecdsa2019.SignerInitializerOptions{
+			LDDocumentLoader: docLoader,
+			SignerGetter:     ecdsa2019.WithKMSCryptoWrapper(kmsCrypto),
+		}))
+		require.NoError(t, err)
+
+		verifier, err := NewVerifier((&Options{}).WithDIDResolver(resolver),
+			ecdsa2019.NewVerifierInitializer(&ecdsa2019.VerifierInitializerOptions{
+				LDDocumentLoader: docLoader,
+			}))
+		require.NoError(t, err)
+
+		signedCred, err := signer.AddProof(validCredential, &models.ProofOptions{
+			VerificationMethod:   vm,
+			VerificationMethodID: vm.ID,
+			SuiteType:            ecdsa2019.SuiteTypeNew,
+			Purpose:              AssertionMethod,
+			ProofType:            models.DataIntegrityProof,
+			Created:              time.Now(),
+		})
+		require.NoError(t, err)
+
+		err = verifier.VerifyProof(signedCred, &models.ProofOptions{
+			SuiteType: ecdsa2019.SuiteTypeNew,
+			Purpose:   AssertionMethod,
+			ProofType: models.DataIntegrityProof,
+		})
+		require.NoError(t, err)
+
+		// The key of another did:jwk DID doesn't verify the proof.
+		otherKey, err := kmsCrypto.Create(kmsapi.ECDSAP256IEEEP1363)
+		require.NoError(t, err)
+
+		tamperedCred, err := sjson.SetBytes(signedCred, "proof.verificationMethod", jwkDID(t, otherKey)+"#0")
+		require.NoError(t, err)
+
+		err = verifier.VerifyProof(tamperedCred, &models.ProofOptions{
+			SuiteType: ecdsa2019.SuiteTypeNew,
+			Purpose:   AssertionMethod,
+			ProofType: models.DataIntegrityProof,
+		})
+		require.ErrorIs(t, err, suite.ErrInvalidProof)
+	})
+
+	t.Run("failure", func(t *testing.T) {
+		_, err := resolver.Resolve("did:web:example.com")
+		require.ErrorContains(t, err, "is not a did:jwk DID")
+
+		_, err = resolver.Resolve(jwkDID(t, p256Key) + "#0")
+		require.ErrorContains(t, err, "should not have a path, query or fragment")
+
+		_, err = resolver.Resolve(jwkDIDPrefix + "not*base64")
+		require.ErrorContains(t, err, "decode JWK")
+
+		_, err = resolver.Resolve(jwkDIDPrefix + base64.RawURLEncoding.EncodeToString([]byte("[]")))
+		require.ErrorContains(t, err, "unmarshal JWK")
+
+		_, err = resolver.Resolve(jwkDID(t, map[string]string{"kty": "RSA", "n": "AQAB", "e": "AQAB"}))
+		require.ErrorContains(t, err, `unsupported JWK key type "RSA"`)
+
+		_, err = resolver.Resolve(jwkDID(t, map[string]string{"kty": "OKP", "crv": "Ed25519", "x": "AA", "d": "AA"}))
+		require.ErrorContains(t, err, "JWK is a private key")
+
+		_, err = resolver.Resolve(jwkDID(t, map[string]string{"kty": "EC", "crv": "P-256"}))
+		require.ErrorContains(t, err, "resolve did:jwk:")
+	})
+}
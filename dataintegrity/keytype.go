@@ -0,0 +1,173 @@
+/*
+Copyright Gen Digital Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package dataintegrity
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"slices"
+
+	"github.com/trustbloc/vc-go/dataintegrity/models"
+)
+
+// ErrIncompatibleKeyType is returned by a Verifier when the key of the verification method of a
+// proof is not of a type its cryptographic suite signs with, eg an Ed25519 key for an ecdsa-rdfc-2019
+// proof. The proof is rejected before its signature is verified.
+var ErrIncompatibleKeyType = errors.New("verification method key type incompatible with cryptosuite")
+
+// ErrAlgorithmMismatch is returned by a Verifier when the JWK of the verification method of a proof
+// declares an alg other than the signature algorithm its cryptographic suite requires with the key,
+// eg ES384 for an ecdsa-rdfc-2019 proof with a P-256 key. The proof is rejected before its signature
+// is verified.
+var ErrAlgorithmMismatch = errors.New("verification method key algorithm incompatible with cryptosuite")
+
+const (
+	keyEd25519   = "Ed25519"
+	keyX25519    = "X25519"
+	keyP256      = "P-256"
+	keyP384      = "P-384"
+	keyP521      = "P-521"
+	keySecp256k1 = "secp256k1"
+	keyBLS12381  = "BLS12381_G2"
+)
+
+// suiteKeyTypes are the key types of the cryptographic suites of this module. The proofs of the
+// other suites, eg registered custom suites, are verified whatever the key type.
+var suiteKeyTypes = map[string][]string{ //nolint:gochecknoglobals
+	"ecdsa-2019":      {keyP256, keyP384, keyP521, keySecp256k1},
+	"ecdsa-rdfc-2019": {keyP256, keyP384, keyP521, keySecp256k1},
+	"ecdsa-jcs-2019":  {keyP256, keyP384, keyP521, keySecp256k1},
+	"ecdsa-sd-2023":   {keyP256},
+	"eddsa-2022":      {keyEd25519},
+	"eddsa-rdfc-2022": {keyEd25519},
+	"eddsa-jcs-2022":  {keyEd25519},
+	"bbs-2023":        {keyBLS12381},
+}
+
+// keyAlgorithms are the JWS algorithms of the signatures that the cryptographic suites of this module
+// verify with a key of each key type. The curve of the key alone selects the algorithm, as each suite
+// has a single signature algorithm per curve.
+var keyAlgorithms = map[string]string{ //nolint:gochecknoglobals
+	keyEd25519:   "EdDSA",
+	keyP256:      "ES256",
+	keyP384:      "ES384",
+	keyP521:      "ES512",
+	keySecp256k1: "ES256K",
+}
+
+// vmTypeKeyTypes are the key types of the verification method types specific to a key type.
+var vmTypeKeyTypes = map[string]string{ //nolint:gochecknoglobals
+	"Ed25519VerificationKey2018":        keyEd25519,
+	"Ed25519VerificationKey2020":        keyEd25519,
+	"X25519KeyAgreementKey2019":         keyX25519,
+	"X25519KeyAgreementKey2020":         keyX25519,
+	"EcdsaSecp256k1VerificationKey2019": keySecp256k1,
+	"Bls12381G2Key2020":                 keyBLS12381,
+}
+
+// multikeyHeaders are the multicodec headers of the Multikey public keys.
+// ref https://www.w3.org/TR/controller-document/#Multikey
+var multikeyHeaders = []struct { //nolint:gochecknoglobals
+	header  []byte
+	keyType string
+}{
+	{[]byte{0xed, 0x01}, keyEd25519},
+	{[]byte{0xec, 0x01}, keyX25519},
+	{[]byte{0x80, 0x24}, keyP256},
+	{[]byte{0x12, 0x00}, keyP256},
+	{[]byte{0x81, 0x24}, keyP384},
+	{[]byte{0x12, 0x01}, keyP384},
+	{[]byte{0x82, 0x24}, keyP521},
+	{[]byte{0x12, 0x02}, keyP521},
+	{[]byte{0xe7, 0x01}, keySecp256k1},
+	{[]byte{0xeb, 0x01}, keyBLS12381},
+}
+
+// SignatureAlgorithmSelector returns the signature algorithm, as a JWS alg, that a proof of cryptoSuite
+// is verified with using the key of vm, or "" to let the suite derive it from the key. It returns an
+// error when the key can't verify a proof of cryptoSuite, and the proof is then rejected.
+type SignatureAlgorithmSelector func(cryptoSuite string, vm *models.VerificationMethod) (string, error)
+
+// SignatureAlgorithm is the default SignatureAlgorithmSelector of a Verifier. For the cryptographic
+// suites of this module, it derives the signature algorithm from the key type of vm, eg ES384 for an
+// ecdsa-rdfc-2019 proof with a P-384 key, and returns ErrIncompatibleKeyType when the key is not of a
+// key type of the suite, or ErrAlgorithmMismatch when the JWK of vm declares another alg. It returns
+// "" for the other suites, and for bbs-2023, which has no JWS algorithm.
+func SignatureAlgorithm(cryptoSuite string, vm *models.VerificationMethod) (string, error) {
+	if err := checkKeyType(cryptoSuite, vm); err != nil {
+		return "", err
+	}
+
+	if _, ok := suiteKeyTypes[cryptoSuite]; !ok || vm == nil {
+		return "", nil
+	}
+
+	keyType := verificationMethodKeyType(vm)
+
+	alg, ok := keyAlgorithms[keyType]
+	if !ok {
+		return "", nil
+	}
+
+	if key := vm.JSONWebKey(); key != nil && key.Algorithm != "" && key.Algorithm != alg {
+		return "", fmt.Errorf("%w: cryptosuite %s with key type %s requires alg %s, JWK declares alg %s",
+			ErrAlgorithmMismatch, cryptoSuite, keyType, alg, key.Algorithm)
+	}
+
+	return alg, nil
+}
+
+// checkKeyType returns ErrIncompatibleKeyType when the key of vm is known not to be of a key type
+// of the cryptosuite.
+func checkKeyType(cryptoSuite string, vm *models.VerificationMethod) error {
+	keyTypes, ok := suiteKeyTypes[cryptoSuite]
+	if !ok || vm == nil {
+		return nil
+	}
+
+	keyType := verificationMethodKeyType(vm)
+	if keyType == "" || slices.Contains(keyTypes, keyType) {
+		return nil
+	}
+
+	description := vm.Type
+	if _, typed := vmTypeKeyTypes[vm.Type]; !typed {
+		description = fmt.Sprintf("%s (%s)", vm.Type, keyType)
+	}
+
+	return fmt.Errorf("%w: cryptosuite %s incompatible with key type %s", ErrIncompatibleKeyType,
+		cryptoSuite, description)
+}
+
+// verificationMethodKeyType returns the key type of vm, from its JWK, its type or, for a Multikey,
+// its multicodec header, or "" when it is not known.
+func verificationMethodKeyType(vm *models.VerificationMethod) string {
+	if key := vm.JSONWebKey(); key != nil {
+		if key.Crv == "" {
+			return key.Kty
+		}
+
+		return key.Crv
+	}
+
+	if keyType, ok := vmTypeKeyTypes[vm.Type]; ok {
+		return keyType
+	}
+
+	if vm.Type != "Multikey" {
+		return ""
+	}
+
+	for _, h := range multikeyHeaders {
+		if bytes.HasPrefix(vm.Value, h.header) {
+			return h.keyType
+		}
+	}
+
+	return ""
+}
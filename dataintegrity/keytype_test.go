@@ -0,0 +1,271 @@
+/*
+Copyright Gen Digital Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package dataintegrity
+
+import (
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"slices"
+	"testing"
+
+	"github.com/btcsuite/btcd/btcec/v2"
+	"github.com/stretchr/testify/require"
+	"github.com/trustbloc/did-go/doc/did"
+	"github.com/trustbloc/kms-go/doc/jose/jwk/jwksupport"
+
+	"github.com/trustbloc/vc-go/dataintegrity/models"
+)
+
+func TestCheckKeyType(t *testing.T) {
+	jwkVM := func(t *testing.T, key interface{}) *models.VerificationMethod {
+		t.Helper()
+
+		j, err := jwksupport.JWKFromKey(key)
+		require.NoError(t, err)
+
+		vm, err := did.NewVerificationMethodFromJWK(mockKID, "JsonWebKey2020", mockDID, j)
+		require.NoError(t, err)
+
+		return vm
+	}
+
+	ecKey := func(t *testing.T, curve elliptic.Curve) *ecdsa.PublicKey {
+		t.Helper()
+
+		priv, err := ecdsa.GenerateKey(curve, rand.Reader)
+		require.NoError(t, err)
+
+		return &priv.PublicKey
+	}
+
+	edPub, _, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+
+	multikey := func(header ...byte) *models.VerificationMethod {
+		return did.NewVerificationMethodFromBytes(mockKID, "Multikey", mockDID, append(header, make([]byte, 33)...))
+	}
+
+	keys := map[string]*models.VerificationMethod{
+		keyEd25519:   did.NewVerificationMethodFromBytes(mockKID, "Ed25519VerificationKey2020", mockDID, edPub),
+		keyX25519:    multikey(0xec, 0x01),
+		keyP256:      jwkVM(t, ecKey(t, elliptic.P256())),
+		keyP384:      multikey(0x81, 0x24),
+		keyP521:      jwkVM(t, ecKey(t, elliptic.P521())),
+		keySecp256k1: jwkVM(t, ecKey(t, btcec.S256())),
+		keyBLS12381:  did.NewVerificationMethodFromBytes(mockKID, "Bls12381G2Key2020", mockDID, make([]byte, 96)),
+		"RSA":        jwkVM(t, &rsaKey.PublicKey),
+	}
+
+	for cryptoSuite, keyTypes := range suiteKeyTypes {
+		for keyType, vm := range keys {
+			err = checkKeyType(cryptoSuite, vm)
+
+			if slices.Contains(keyTypes, keyType) {
+				require.NoError(t, err, "%s with %s", cryptoSuite, keyType)
+
+				continue
+			}
+
+			require.ErrorIs(t, err, ErrIncompatibleKeyType, "%s with %s", cryptoSuite, keyType)
+			require.ErrorContains(t, err, "cryptosuite "+cryptoSuite+" incompatible with key type")
+		}
+	}
+
+	t.Run("messages", func(t *testing.T) {
+		require.EqualError(t, checkKeyType("ecdsa-rdfc-2019", keys[keyEd25519]),
+			"verification method key type incompatible with cryptosuite: "+
+				"cryptosuite ecdsa-rdfc-2019 incompatible with key type Ed25519VerificationKey2020")
+		require.EqualError(t, checkKeyType("eddsa-rdfc-2022", keys[keyP256]),
+			"verification method key type incompatible with cryptosuite: "+
+				"cryptosuite eddsa-rdfc-2022 incompatible with key type JsonWebKey2020 (P-256)")
+		require.EqualError(t, checkKeyType("bbs-2023", keys[keyP384]),
+			"verification method key type incompatible with cryptosuite: "+
+				"cryptosuite bbs-2023 incompatible with key type Multikey (P-384)")
+	})
+
+	t.Run("unknown key type or suite", func(t *testing.T) {
+		require.NoError(t, checkKeyType("ecdsa-rdfc-2019",
+			did.NewVerificationMethodFromBytes(mockKID, "CustomKey2024", mockDID, []byte{0xed, 0x01})))
+		require.NoError(t, checkKeyType("ecdsa-rdfc-2019", multikey(0x00, 0x01)))
+		require.NoError(t, checkKeyType(mockSuiteType, keys[keyEd25519]))
+		require.NoError(t, checkKeyType("ecdsa-rdfc-2019", nil))
+	})
+}
+
+func TestVerifier_IncompatibleKeyType(t *testing.T) {
+	edPub, _, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+
+	mockVerifySuite := &mockSuite{}
+
+	v, err := NewVerifier(
+		&Options{
+			DIDResolver: &mockResolver{
+				vm: did.NewVerificationMethodFromBytes(mockKID, "Ed25519VerificationKey2020", mockDID, edPub),
+				vr: did.AssertionMethod,
+			},
+		},
+		&mockSuiteInitializer{
+			mockSuite: mockVerifySuite,
+			typeStr:   "ecdsa-rdfc-2019",
+		})
+	require.NoError(t, err)
+
+	signedDoc, err := mockAddProof([]byte(`{"id":"foo"}`), &models.Proof{
+		Type:               models.DataIntegrityProof,
+		CryptoSuite:        "ecdsa-rdfc-2019",
+		VerificationMethod: mockKID,
+		ProofPurpose:       AssertionMethod,
+	})
+	require.NoError(t, err)
+
+	err = v.VerifyProof(signedDoc, &models.ProofOptions{Purpose: AssertionMethod})
+	require.ErrorIs(t, err, ErrIncompatibleKeyType)
+	require.ErrorContains(t, err,
+		"cryptosuite ecdsa-rdfc-2019 incompatible with key type Ed25519VerificationKey2020")
+	require.Zero(t, mockVerifySuite.verifyProofCalls)
+}
+
+func TestSignatureAlgorithm(t *testing.T) {
+	jwkVM := func(t *testing.T, key interface{}, alg string) *models.VerificationMethod {
+		t.Helper()
+
+		j, err := jwksupport.JWKFromKey(key)
+		require.NoError(t, err)
+
+		j.Algorithm = alg
+
+		vm, err := did.NewVerificationMethodFromJWK(mockKID, "JsonWebKey2020", mockDID, j)
+		require.NoError(t, err)
+
+		return vm
+	}
+
+	ecKey := func(t *testing.T, curve elliptic.Curve) *ecdsa.PublicKey {
+		t.Helper()
+
+		priv, err := ecdsa.GenerateKey(curve, rand.Reader)
+		require.NoError(t, err)
+
+		return &priv.PublicKey
+	}
+
+	edPub, _, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+
+	t.Run("derived from cryptosuite and key curve", func(t *testing.T) {
+		tests := []struct {
+			cryptoSuite string
+			vm          *models.VerificationMethod
+			alg         string
+		}{
+			{"ecdsa-rdfc-2019", jwkVM(t, ecKey(t, elliptic.P256()), ""), "ES256"},
+			{"ecdsa-jcs-2019", jwkVM(t, ecKey(t, elliptic.P384()), ""), "ES384"},
+			{"ecdsa-2019", jwkVM(t, ecKey(t, elliptic.P521()), ""), "ES512"},
+			{"ecdsa-rdfc-2019", jwkVM(t, ecKey(t, btcec.S256()), ""), "ES256K"},
+			{"ecdsa-sd-2023", jwkVM(t, ecKey(t, elliptic.P256()), "ES256"), "ES256"},
+			{"ecdsa-rdfc-2019", did.NewVerificationMethodFromBytes(mockKID, "Multikey", mockDID,
+				append([]byte{0x81, 0x24}, make([]byte, 49)...)), "ES384"},
+			{"eddsa-rdfc-2022", did.NewVerificationMethodFromBytes(mockKID, "Ed25519VerificationKey2020",
+				mockDID, edPub), "EdDSA"},
+			{"eddsa-jcs-2022", jwkVM(t, edPub, "EdDSA"), "EdDSA"},
+			{"bbs-2023", did.NewVerificationMethodFromBytes(mockKID, "Bls12381G2Key2020", mockDID,
+				make([]byte, 96)), ""},
+			{mockSuiteType, jwkVM(t, ecKey(t, elliptic.P256()), "ES384"), ""},
+		}
+
+		for _, tc := range tests {
+			alg, e := SignatureAlgorithm(tc.cryptoSuite, tc.vm)
+			require.NoError(t, e, tc.cryptoSuite)
+			require.Equal(t, tc.alg, alg, tc.cryptoSuite)
+		}
+	})
+
+	t.Run("JWK with mismatched alg", func(t *testing.T) {
+		_, err = SignatureAlgorithm("ecdsa-rdfc-2019", jwkVM(t, ecKey(t, elliptic.P256()), "ES384"))
+		require.ErrorIs(t, err, ErrAlgorithmMismatch)
+		require.EqualError(t, err, "verification method key algorithm incompatible with cryptosuite: "+
+			"cryptosuite ecdsa-rdfc-2019 with key type P-256 requires alg ES256, JWK declares alg ES384")
+
+		_, err = SignatureAlgorithm("eddsa-rdfc-2022", jwkVM(t, edPub, "ES256"))
+		require.ErrorIs(t, err, ErrAlgorithmMismatch)
+	})
+
+	t.Run("incompatible key type", func(t *testing.T) {
+		_, err = SignatureAlgorithm("eddsa-rdfc-2022", jwkVM(t, ecKey(t, elliptic.P256()), ""))
+		require.ErrorIs(t, err, ErrIncompatibleKeyType)
+	})
+}
+
+func TestVerifier_AlgorithmMismatch(t *testing.T) {
+	priv, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+
+	j, err := jwksupport.JWKFromKey(&priv.PublicKey)
+	require.NoError(t, err)
+
+	j.Algorithm = "ES384"
+
+	vm, err := did.NewVerificationMethodFromJWK(mockKID, "JsonWebKey2020", mockDID, j)
+	require.NoError(t, err)
+
+	signedDoc, err := mockAddProof([]byte(`{"id":"foo"}`), &models.Proof{
+		Type:               models.DataIntegrityProof,
+		CryptoSuite:        "ecdsa-rdfc-2019",
+		VerificationMethod: mockKID,
+		ProofPurpose:       AssertionMethod,
+	})
+	require.NoError(t, err)
+
+	newVerifier := func(t *testing.T, opts *Options) (*Verifier, *mockSuite) {
+		t.Helper()
+
+		mockVerifySuite := &mockSuite{}
+
+		v, e := NewVerifier(
+			opts.WithDIDResolver(&mockResolver{vm: vm, vr: did.AssertionMethod}),
+			&mockSuiteInitializer{
+				mockSuite: mockVerifySuite,
+				typeStr:   "ecdsa-rdfc-2019",
+			})
+		require.NoError(t, e)
+
+		return v, mockVerifySuite
+	}
+
+	t.Run("mismatched alg", func(t *testing.T) {
+		v, mockVerifySuite := newVerifier(t, &Options{})
+
+		err = v.VerifyProof(signedDoc, &models.ProofOptions{Purpose: AssertionMethod})
+		require.ErrorIs(t, err, ErrAlgorithmMismatch)
+		require.ErrorContains(t, err, "requires alg ES256, JWK declares alg ES384")
+		require.Zero(t, mockVerifySuite.verifyProofCalls)
+	})
+
+	t.Run("custom selector", func(t *testing.T) {
+		var selected []string
+
+		v, mockVerifySuite := newVerifier(t, (&Options{}).WithSignatureAlgorithmSelector(
+			func(cryptoSuite string, vm *models.VerificationMethod) (string, error) {
+				selected = append(selected, cryptoSuite+" "+vm.JSONWebKey().Algorithm)
+
+				return vm.JSONWebKey().Algorithm, nil
+			}))
+
+		err = v.VerifyProof(signedDoc, &models.ProofOptions{Purpose: AssertionMethod})
+		require.NoError(t, err)
+		require.Equal(t, []string{"ecdsa-rdfc-2019 ES384"}, selected)
+		require.Equal(t, 1, mockVerifySuite.verifyProofCalls)
+		require.Equal(t, "ES384", mockVerifySuite.verifyProofOpts.SignatureAlgorithm)
+	})
+}
@@ -7,8 +7,14 @@ SPDX-License-Identifier: Apache-2.0
 package models
 
 import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
 	"time"
 
+	"github.com/multiformats/go-multibase"
 	"github.com/trustbloc/did-go/doc/did"
 )
 
@@ -43,8 +49,90 @@ type Proof struct {
 	Expires            string `json:"expires,omitempty"`
 	Domain             string `json:"domain,omitempty"`
 	Challenge          string `json:"challenge,omitempty"`
+	Nonce              string `json:"nonce,omitempty"`
 	ProofValue         string `json:"proofValue"`
 	PreviousProof      string `json:"previousProof,omitempty"`
+	// CustomFields are the other members of the proof, eg a member required by a profile.
+	// They are signed over with the proof configuration.
+	CustomFields map[string]interface{} `json:"-"`
+}
+
+// proofJSON has the fields of Proof, without its methods.
+type proofJSON Proof
+
+// reservedProofFields are the members of the proof and of the proof configuration that
+// custom fields can't set.
+var reservedProofFields = map[string]bool{ //nolint:gochecknoglobals
+	"@context":           true,
+	"id":                 true,
+	"type":               true,
+	"cryptosuite":        true,
+	"proofPurpose":       true,
+	"verificationMethod": true,
+	"created":            true,
+	"expires":            true,
+	"domain":             true,
+	"challenge":          true,
+	"nonce":              true,
+	"proofValue":         true,
+	"previousProof":      true,
+}
+
+// IsReservedProofField returns true if name is a member of the proof that a custom field
+// can't set, eg "proofValue" or "created".
+func IsReservedProofField(name string) bool {
+	return reservedProofFields[name]
+}
+
+// MarshalJSON marshals the proof together with its custom fields.
+func (p Proof) MarshalJSON() ([]byte, error) {
+	proofBytes, err := json.Marshal(proofJSON(p))
+	if err != nil || len(p.CustomFields) == 0 {
+		return proofBytes, err
+	}
+
+	var proof map[string]interface{}
+
+	if err = json.Unmarshal(proofBytes, &proof); err != nil {
+		return nil, err
+	}
+
+	for name, value := range p.CustomFields {
+		if !reservedProofFields[name] {
+			proof[name] = value
+		}
+	}
+
+	return json.Marshal(proof)
+}
+
+// UnmarshalJSON unmarshals the proof, keeping the members without a Proof field in CustomFields.
+func (p *Proof) UnmarshalJSON(data []byte) error {
+	var proof proofJSON
+
+	if err := json.Unmarshal(data, &proof); err != nil {
+		return err
+	}
+
+	var members map[string]interface{}
+
+	if err := json.Unmarshal(data, &members); err != nil {
+		return err
+	}
+
+	for name := range members {
+		if reservedProofFields[name] {
+			delete(members, name)
+		}
+	}
+
+	if len(members) > 0 {
+		proof.CustomFields = members
+	}
+
+	*p = Proof(proof)
+
+	return nil
 }
 
 // ProofOptions provides options for signing or verifying a data integrity proof.
@@ -56,9 +144,161 @@ type ProofOptions struct {
 	SuiteType            string
 	Domain               string
 	Challenge            string
+	Nonce                string // During verification, the proof nonce must match it unless it is empty.
 	Created              time.Time
 	Expires              time.Time // During verification process the value must be taken from Proof.Expires.
-	CustomFields         map[string]interface{}
+	// CustomFields are added to the created proof and signed over with the proof configuration,
+	// they can't set reserved members (see IsReservedProofField). During verification, they are
+	// taken from Proof.CustomFields.
+	CustomFields map[string]interface{}
+	// UnsignedProofFields is used during verification: these members of the proof are not
+	// custom fields signed over, eg because they are added to the proof once it is created.
+	UnsignedProofFields []string
+	// AcceptedDomains is used during verification: if set, the proof domain must be one
+	// of them, and Domain is ignored.
+	AcceptedDomains []string
+	// CreatedTolerance is used during verification: proof.Created may be up to
+	// CreatedTolerance in the future relative to the verifier's clock.
+	CreatedTolerance time.Duration
+	// AllowFutureCreated is used during verification: proof.Created may be at any time
+	// in the future, and CreatedTolerance is ignored.
+	AllowFutureCreated bool
+	// ExpiresTolerance is used during verification: proof.Expires may be up to
+	// ExpiresTolerance in the past relative to the verifier's clock.
+	ExpiresTolerance time.Duration
+	// MandatoryPointers are the JSON pointers to the statements that are always
+	// disclosed by selective disclosure suites.
+	MandatoryPointers []string
+	// ProofID is the id of the proof, so that a later proof of a proof chain
+	// can reference it.
+	ProofID string
+	// PreviousProof is the id of the proof that the proof is chained to.
+	PreviousProof string
+	// ProofValueEncoding is the multibase encoding of the created proof value,
+	// for the suites that allow choosing it. Defaults to base58-btc.
+	ProofValueEncoding ProofValueEncoding
+	// CanonicalizationAlgorithm is the RDF canonicalization algorithm of the suites
+	// with RDF canonicalization. Defaults to URDNA2015.
+	CanonicalizationAlgorithm CanonicalizationAlgorithm
+	// LenientProofContext is used during verification: a proof that doesn't verify is verified
+	// again with the Data Integrity v2 context added to the proof configuration, if the context
+	// of the document doesn't define the proof terms (see suite.ProofConfigContext). This accepts
+	// the proofs of issuers that sign as if the document had the Data Integrity context.
+	LenientProofContext bool
+	// ProofValueEncodingHint is used during verification: the proofValue may also be encoded
+	// with this encoding, without multibase prefix (see DecodeProofValue). By default, the
+	// proofValue must be multibase encoded.
+	ProofValueEncodingHint ProofValueEncoding
+	// TryAllAssertionKeys is used during verification of assertionMethod proofs: when the verification
+	// method of the proof can't be resolved or doesn't verify the proof, eg during a key rotation, the
+	// proof is verified with each key listed under assertionMethod in the DID document of its controller,
+	// and verifies if one of them does.
+	TryAllAssertionKeys bool
+	// ProofStructure is used during verification: it is the expected structure of the proofs of the
+	// document. By default, the proofs are verified independently, except for the chained ones, which
+	// are verified together with the proofs they are chained to.
+	ProofStructure ProofStructure
+	// SignatureAlgorithm is used during verification: it is the signature algorithm, as a JWS alg, eg
+	// ES384, that the suites with several algorithms verify the proof with. It is set by the Verifier
+	// from its SignatureAlgorithmSelector. By default, the suites derive it from the key.
+	SignatureAlgorithm string
+}
+
+// ProofStructure is how the proofs of a document relate to each other.
+type ProofStructure string
+
+const (
+	// ProofSet is an unordered set of independent proofs, none of them chained to another.
+	ProofSet ProofStructure = "set"
+	// ProofChain is an ordered chain of proofs, each proof after the first one being chained,
+	// through previousProof, to the proof preceding it, and signed over together with it.
+	ProofChain ProofStructure = "chain"
+)
+
+// CanonicalizationAlgorithm is an RDF canonicalization algorithm.
+type CanonicalizationAlgorithm string
+
+const (
+	// URDNA2015 is the RDF Dataset Normalization Algorithm of the W3C Credentials Community
+	// Group, used by the suites specified before RDFC-1.0, ecdsa-2019 and eddsa-2022.
+	URDNA2015 CanonicalizationAlgorithm = "URDNA2015"
+	// RDFC10 is the W3C RDF Dataset Canonicalization algorithm, RDFC-1.0, specified by the
+	// ecdsa-rdfc-2019, eddsa-rdfc-2022 and ecdsa-sd-2023 suites.
+	RDFC10 CanonicalizationAlgorithm = "RDFC-1.0"
+)
+
+// ProofValueEncoding is a multibase encoding of proof values, named as in the
+// multibase specification.
+type ProofValueEncoding string
+
+const (
+	// ProofValueBase58BTC is the multibase base58-btc encoding, prefixed with 'z'.
+	ProofValueBase58BTC ProofValueEncoding = "base58btc"
+	// ProofValueBase64URL is the multibase base64url encoding without padding, prefixed with 'u'.
+	ProofValueBase64URL ProofValueEncoding = "base64url"
+	// ProofValueBase64 is the base64 encoding with the standard alphabet. It is only a decoding
+	// hint of DecodeProofValue, proof values are not created with it.
+	ProofValueBase64 ProofValueEncoding = "base64"
+)
+
+// Encoding returns the multibase encoding of e, base58-btc if e is empty.
+func (e ProofValueEncoding) Encoding() (multibase.Encoding, error) {
+	switch e {
+	case "", ProofValueBase58BTC:
+		return multibase.Base58BTC, nil
+	case ProofValueBase64URL:
+		return multibase.Base64url, nil
+	default:
+		return 0, fmt.Errorf("unsupported proof value encoding %q", string(e))
+	}
+}
+
+// DecodeProofValue decodes the multibase encoded proofValue. If hint is set, value may also be encoded
+// with the hint encoding, without multibase prefix and with or without padding: this accepts the proof
+// values of the issuers that omit the prefix. As such a value may also be valid multibase, eg a base58-btc
+// value starting with 'z', all the decodings of value are returned, the multibase one first.
+func DecodeProofValue(value string, hint ProofValueEncoding) ([][]byte, error) {
+	var decoded [][]byte
+
+	_, data, err := multibase.Decode(value)
+	if err == nil {
+		decoded = append(decoded, data)
+	}
+
+	if hint == "" {
+		return decoded, err
+	}
+
+	decode, ok := unprefixedDecoders[hint]
+	if !ok {
+		return nil, fmt.Errorf("unsupported proof value encoding hint %q", string(hint))
+	}
+
+	data, hintErr := decode(value)
+	if hintErr == nil {
+		decoded = append(decoded, data)
+	}
+
+	if len(decoded) == 0 {
+		return nil, errors.Join(err, hintErr)
+	}
+
+	return decoded, nil
+}
+
+// unprefixedDecoders decode the proof values encoded without multibase prefix.
+var unprefixedDecoders = map[ProofValueEncoding]func(value string) ([]byte, error){ //nolint:gochecknoglobals
+	ProofValueBase58BTC: func(value string) ([]byte, error) {
+		_, data, err := multibase.Decode(string(multibase.Base58BTC) + value)
+
+		return data, err
+	},
+	ProofValueBase64URL: func(value string) ([]byte, error) {
+		return base64.RawURLEncoding.DecodeString(strings.TrimRight(value, "="))
+	},
+	ProofValueBase64: func(value string) ([]byte, error) {
+		return base64.RawStdEncoding.DecodeString(strings.TrimRight(value, "="))
+	},
 }
 
 // DateTimeFormat is the date-time format used by the data integrity
@@ -0,0 +1,99 @@
+/*
+Copyright Gen Digital Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package dataintegrity
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	lru "github.com/hashicorp/golang-lru/v2"
+	"github.com/trustbloc/did-go/doc/did"
+	vdrapi "github.com/trustbloc/did-go/vdr/api"
+)
+
+// CachingResolver is a DIDResolver caching the resolutions of another one, so that the DID of
+// an issuer is resolved once for all its credentials. It is safe for concurrent use.
+type CachingResolver struct {
+	resolver    DIDResolver
+	ttl         time.Duration
+	negativeTTL time.Duration
+	cache       *lru.Cache[string, cachedResolution]
+	now         func() time.Time
+}
+
+type cachedResolution struct {
+	resolution *did.DocResolution
+	err        error
+	expires    time.Time
+}
+
+// NewCachingResolver wraps resolver with a cache of up to size DIDs. A resolved DID document is
+// kept for ttl, and a resolution failure for negativeTTL, so that a DID failing to resolve is not
+// requested again for each verification. A zero negativeTTL disables the caching of failures.
+func NewCachingResolver(resolver DIDResolver, size int, ttl, negativeTTL time.Duration) (*CachingResolver, error) {
+	cache, err := lru.New[string, cachedResolution](size)
+	if err != nil {
+		return nil, err
+	}
+
+	return &CachingResolver{
+		resolver:    resolver,
+		ttl:         ttl,
+		negativeTTL: negativeTTL,
+		cache:       cache,
+		now:         time.Now,
+	}, nil
+}
+
+// Resolve returns the cached resolution of didID, or resolves it with the wrapped resolver. The
+// resolutions with DID method options are not cached, as the options may change their result.
+func (r *CachingResolver) Resolve(didID string, opts ...vdrapi.DIDMethodOption) (*did.DocResolution, error) {
+	return r.ResolveContext(context.Background(), didID, opts...)
+}
+
+// ResolveContext returns the cached resolution of didID as Resolve, or resolves it with the wrapped
+// resolver until ctx is done, implements ContextDIDResolver. The failures because ctx is done are not cached.
+func (r *CachingResolver) ResolveContext(
+	ctx context.Context,
+	didID string,
+	opts ...vdrapi.DIDMethodOption,
+) (*did.DocResolution, error) {
+	if len(opts) > 0 {
+		return resolveDIDContext(ctx, r.resolver, didID, opts...)
+	}
+
+	now := r.now()
+
+	if cached, ok := r.cache.Get(didID); ok {
+		if now.Before(cached.expires) {
+			return cached.resolution, cached.err
+		}
+
+		r.cache.Remove(didID)
+	}
+
+	resolution, err := resolveDIDContext(ctx, r.resolver, didID)
+	if err != nil && (errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded)) {
+		return nil, err
+	}
+
+	ttl := r.ttl
+	if err != nil {
+		ttl = r.negativeTTL
+	}
+
+	if ttl > 0 {
+		r.cache.Add(didID, cachedResolution{
+			resolution: resolution,
+			err:        err,
+			expires:    now.Add(ttl),
+		})
+	}
+
+	return resolution, err
+}
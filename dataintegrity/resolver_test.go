@@ -0,0 +1,163 @@
+/*
+Copyright Gen Digital Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package dataintegrity
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"github.com/trustbloc/did-go/doc/did"
+	vdrapi "github.com/trustbloc/did-go/vdr/api"
+)
+
+func TestCachingResolver(t *testing.T) {
+	const failingDID = "did:foo:failing"
+
+	var calls atomic.Int32
+
+	resolver := didResolverFunc(func(id string) (*did.DocResolution, error) {
+		calls.Add(1)
+
+		if id == failingDID {
+			return nil, errors.New("resolution failed")
+		}
+
+		return &did.DocResolution{DIDDocument: &did.Doc{ID: id}}, nil
+	})
+
+	newResolver := func(t *testing.T, negativeTTL time.Duration) (*CachingResolver, *time.Time) {
+		t.Helper()
+
+		calls.Store(0)
+
+		r, err := NewCachingResolver(resolver, 10, time.Minute, negativeTTL)
+		require.NoError(t, err)
+
+		now := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+		r.now = func() time.Time { return now }
+
+		return r, &now
+	}
+
+	t.Run("cached until TTL", func(t *testing.T) {
+		r, now := newResolver(t, 0)
+
+		for range 3 {
+			res, err := r.Resolve(mockDID)
+			require.NoError(t, err)
+			require.Equal(t, mockDID, res.DIDDocument.ID)
+		}
+
+		require.EqualValues(t, 1, calls.Load())
+
+		_, err := r.Resolve("did:foo:other")
+		require.NoError(t, err)
+		require.EqualValues(t, 2, calls.Load())
+
+		*now = now.Add(time.Minute)
+
+		_, err = r.Resolve(mockDID)
+		require.NoError(t, err)
+		require.EqualValues(t, 3, calls.Load())
+	})
+
+	t.Run("negative caching", func(t *testing.T) {
+		r, now := newResolver(t, 10*time.Second)
+
+		for range 3 {
+			_, err := r.Resolve(failingDID)
+			require.EqualError(t, err, "resolution failed")
+		}
+
+		require.EqualValues(t, 1, calls.Load())
+
+		*now = now.Add(10 * time.Second)
+
+		_, err := r.Resolve(failingDID)
+		require.EqualError(t, err, "resolution failed")
+		require.EqualValues(t, 2, calls.Load())
+	})
+
+	t.Run("negative caching disabled", func(t *testing.T) {
+		r, _ := newResolver(t, 0)
+
+		for range 3 {
+			_, err := r.Resolve(failingDID)
+			require.Error(t, err)
+		}
+
+		require.EqualValues(t, 3, calls.Load())
+	})
+
+	t.Run("not cached with DID method options", func(t *testing.T) {
+		r, _ := newResolver(t, 0)
+
+		for range 2 {
+			_, err := r.Resolve(mockDID, vdrapi.WithOption("key", "value"))
+			require.NoError(t, err)
+		}
+
+		require.EqualValues(t, 2, calls.Load())
+	})
+
+	t.Run("context failures not cached", func(t *testing.T) {
+		r, _ := newResolver(t, time.Minute)
+
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+
+		_, err := r.ResolveContext(ctx, mockDID)
+		require.ErrorIs(t, err, context.Canceled)
+
+		res, err := r.ResolveContext(context.Background(), mockDID)
+		require.NoError(t, err)
+		require.Equal(t, mockDID, res.DIDDocument.ID)
+		require.EqualValues(t, 1, calls.Load())
+	})
+
+	t.Run("concurrent resolution", func(t *testing.T) {
+		r, _ := newResolver(t, time.Minute)
+
+		var wg sync.WaitGroup
+
+		for i := range 50 {
+			wg.Add(1)
+
+			go func() {
+				defer wg.Done()
+
+				if i%2 == 0 {
+					_, err := r.Resolve(mockDID)
+					require.NoError(t, err)
+				} else {
+					_, err := r.Resolve(failingDID)
+					require.Error(t, err)
+				}
+			}()
+		}
+
+		wg.Wait()
+	})
+
+	t.Run("invalid size", func(t *testing.T) {
+		_, err := NewCachingResolver(resolver, 0, time.Minute, 0)
+		require.Error(t, err)
+	})
+
+	t.Run("verifier option", func(t *testing.T) {
+		r, _ := newResolver(t, 0)
+
+		v, err := NewVerifier((&Options{}).WithDIDResolver(r))
+		require.NoError(t, err)
+		require.Equal(t, r, v.resolver)
+	})
+}
@@ -7,13 +7,19 @@ SPDX-License-Identifier: Apache-2.0
 package dataintegrity
 
 import (
+	"context"
 	"encoding/json"
 	"errors"
 	"fmt"
 	"strings"
+	"time"
 
+	"github.com/piprate/json-gold/ld"
+	"github.com/tidwall/gjson"
 	"github.com/tidwall/sjson"
 	"github.com/trustbloc/did-go/doc/did"
+	"golang.org/x/exp/maps"
+	"golang.org/x/exp/slices"
 
 	"github.com/trustbloc/vc-go/dataintegrity/models"
 	"github.com/trustbloc/vc-go/dataintegrity/suite"
@@ -38,41 +44,120 @@ const (
 
 // Signer implements the Add Proof algorithm of the verifiable credential data
 // integrity specification, using a set of provided cryptographic suites.
+//
+// A Signer is safe for concurrent use, as long as the document loaders and key
+// signers given to its suites are: it doesn't change after NewSigner, and it
+// doesn't modify the models.ProofOptions it is given.
 type Signer struct {
-	suites   map[string]suite.Signer
-	resolver didResolver
+	suites     map[string]suite.Signer
+	purposes   map[string][]string
+	resolver   DIDResolver
+	now        func() time.Time
+	algo       models.CanonicalizationAlgorithm
+	givenTimes bool
 }
 
 // NewSigner initializes a Signer that supports using the provided cryptographic
-// suites to perform data integrity signing.
+// suites, and the suites registered with suite.RegisterSigner, to perform data
+// integrity signing.
+//
+// The suites sign with the signer of their SignerGetter option: to sign with the keys of a KMS or
+// HSM outside of the process, eg a cloud KMS, implement suite.KeySigner and initialize the suites
+// with their WithKeySigner SignerGetter. suite.KMSKeySigner signs with the in-process kms-go crypto.
 func NewSigner(opts *Options, suites ...suite.SignerInitializer) (*Signer, error) {
 	if opts == nil {
 		opts = &Options{}
 	}
 
 	signer := &Signer{
-		suites:   map[string]suite.Signer{},
-		resolver: opts.DIDResolver,
+		suites:     map[string]suite.Signer{},
+		purposes:   map[string][]string{},
+		resolver:   opts.DIDResolver,
+		now:        time.Now,
+		algo:       opts.canonicalizationAlgorithm,
+		givenTimes: opts.givenProofTimes,
+	}
+
+	if opts.clock != nil {
+		signer.now = opts.clock
+	}
+
+	wrapLoader, err := contextCacheWrapper(opts.contextCacheSize)
+	if err != nil {
+		return nil, err
 	}
 
 	for _, initializer := range suites {
 		for _, suiteType := range initializer.Type() {
-			if _, ok := signer.suites[suiteType]; ok {
-				continue
+			if err = signer.addSuite(suiteType, initializer, wrapLoader); err != nil {
+				return nil, err
 			}
+		}
+	}
 
-			signingSuite, err := initializer.Signer()
-			if err != nil {
+	// The suites registered with suite.RegisterSigner are looked up for the other suite types,
+	// so that the given suites take precedence.
+	for _, suiteType := range suite.RegisteredSignerTypes() {
+		if initializer, ok := suite.LookupSigner(suiteType); ok {
+			if err = signer.addSuite(suiteType, initializer, wrapLoader); err != nil {
 				return nil, err
 			}
-
-			signer.suites[suiteType] = signingSuite
 		}
 	}
 
 	return signer, nil
 }
 
+// addSuite initializes the suite of suiteType with initializer, unless the Signer has one already.
+func (s *Signer) addSuite(
+	suiteType string,
+	initializer suite.SignerInitializer,
+	wrapLoader func(loader ld.DocumentLoader) ld.DocumentLoader,
+) error {
+	if s.SupportsSuite(suiteType) {
+		return nil
+	}
+
+	signingSuite, err := initializer.Signer()
+	if err != nil {
+		return err
+	}
+
+	if loaderWrapper, ok := signingSuite.(suite.LDDocumentLoaderWrapper); ok && wrapLoader != nil {
+		loaderWrapper.WrapLDDocumentLoader(wrapLoader)
+	}
+
+	s.suites[suiteType] = signingSuite
+	s.purposes[suiteType] = SupportedPurposes()
+
+	if restrictor, ok := signingSuite.(suite.PurposeRestrictor); ok {
+		s.purposes[suiteType] = restrictor.SupportedPurposes()
+	}
+
+	return nil
+}
+
+// SupportsSuite reports whether the Signer can create proofs of the given cryptographic suite type.
+func (s *Signer) SupportsSuite(suiteType string) bool {
+	_, ok := s.suites[suiteType]
+
+	return ok
+}
+
+// Now returns the current time of the Signer clock, the created time of the proofs that don't set one
+// (see Options.WithClock).
+func (s *Signer) Now() time.Time {
+	return s.now()
+}
+
+// Suites returns the sorted cryptographic suite types the Signer can create proofs of.
+func (s *Signer) Suites() []string {
+	suiteTypes := maps.Keys(s.suites)
+	slices.Sort(suiteTypes)
+
+	return suiteTypes
+}
+
 var (
 	// ErrProofGeneration is returned when Signer.AddProof() fails to generate a
 	// proof using a supported cryptographic suite.
@@ -83,17 +168,57 @@ var (
 // signed using the provided options.
 //
 // If the provided options request a cryptographic suite that this Signer does
-// not support, AddProof returns ErrUnsupportedSuite.
+// not support, AddProof returns ErrUnsupportedSuite. If they request a proof
+// purpose that the suite does not support, AddProof returns ErrUnsupportedPurpose.
 //
 // If signing fails, or the created proof is invalid, AddProof returns
 // ErrProofGeneration.
-func (s *Signer) AddProof(doc []byte, opts *models.ProofOptions) ([]byte, error) { // nolint:gocyclo
-	signerSuite, ok := s.suites[opts.SuiteType]
-	if !ok {
-		return nil, ErrUnsupportedSuite
+//
+// If opts.PreviousProof is set, the doc must contain the proof with that id,
+// and the created proof is appended to the proof set of the doc.
+func (s *Signer) AddProof(doc []byte, opts *models.ProofOptions) ([]byte, error) {
+	proof, err := s.CreateProof(doc, opts)
+	if err != nil {
+		return nil, err
 	}
 
-	err := resolveVM(opts, s.resolver, "")
+	proofRaw, err := json.Marshal(proof)
+	if err != nil {
+		return nil, ErrProofGeneration
+	}
+
+	if opts.PreviousProof != "" {
+		proofSet := make([]string, 0)
+
+		for _, p := range gjson.GetBytes(doc, proofPath).Array() {
+			proofSet = append(proofSet, p.Raw)
+		}
+
+		proofRaw = []byte("[" + strings.Join(append(proofSet, string(proofRaw)), ",") + "]")
+	}
+
+	out, err := sjson.SetRawBytes(doc, proofPath, proofRaw)
+	if err != nil {
+		// TODO update linter to use go 1.20: https://github.com/hyperledger/aries-framework-go/issues/3613
+		return nil, errors.Join(ErrProofGeneration, err) // nolint:typecheck
+	}
+
+	return out, nil
+}
+
+// CreateProof returns a proof for the provided JSON doc, signed using the provided
+// options, without adding it to the doc. The doc is expected to have no "proof" field,
+// unless opts.PreviousProof is set: then the proof is chained to the proof of the doc
+// with the opts.PreviousProof id, which is signed over together with the doc. Other
+// proofs of the doc are not signed over. If opts.Created is not set, the proof is created
+// at the current time of the Signer clock (see Options.WithClock). The created and expires
+// times of the proof are in UTC, at second precision, unless the Signer keeps them as given
+// (see Options.WithGivenProofTimes). opts is not modified.
+//
+// CreateProof returns the same errors as AddProof, and ErrInvalidProofChain if the
+// previous proof is not found.
+func (s *Signer) CreateProof(doc []byte, opts *models.ProofOptions) (*models.Proof, error) {
+	signerSuite, doc, opts, err := s.prepare(doc, opts)
 	if err != nil {
 		return nil, err
 	}
@@ -104,6 +229,147 @@ func (s *Signer) AddProof(doc []byte, opts *models.ProofOptions) ([]byte, error)
 		return nil, errors.Join(ErrProofGeneration, err) // nolint:typecheck
 	}
 
+	return checkCreatedProof(proof, signerSuite, opts)
+}
+
+// PrepareProof splits CreateProof in two, for signing keys that can't be used through the
+// suites, eg keys of a PKCS#11 device: it returns the data to sign for a proof of the JSON
+// doc with the provided options, and a finalize function creating the proof from the raw
+// signature of this data, made with the verification method key. The data to sign is the
+// hash of the proof configuration followed by the hash of the document: the ecdsa-2019
+// suites hash it again with the hash of the key curve, and expect an IEEE P1363 signature,
+// the eddsa-2022 suites sign it as is with Ed25519.
+//
+// PrepareProof returns ErrUnsupportedSuite if the suite doesn't implement suite.ProofPreparer,
+// and otherwise the same errors as CreateProof, which finalize returns for the proof creation.
+func (s *Signer) PrepareProof(
+	doc []byte,
+	opts *models.ProofOptions,
+) ([]byte, func(sig []byte) (*models.Proof, error), error) {
+	signerSuite, doc, opts, err := s.prepare(doc, opts)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	preparer, ok := signerSuite.(suite.ProofPreparer)
+	if !ok {
+		// TODO update linter to use go 1.20: https://github.com/hyperledger/aries-framework-go/issues/3613
+		return nil, nil, errors.Join(ErrUnsupportedSuite, // nolint:typecheck
+			fmt.Errorf("%s suite doesn't support signing prepared proofs", opts.SuiteType))
+	}
+
+	sigBase, finalizeSuite, err := preparer.PrepareProof(doc, opts)
+	if err != nil {
+		// TODO update linter to use go 1.20: https://github.com/hyperledger/aries-framework-go/issues/3613
+		return nil, nil, errors.Join(ErrProofGeneration, err) // nolint:typecheck
+	}
+
+	finalize := func(sig []byte) (*models.Proof, error) {
+		proof, e := finalizeSuite(sig)
+		if e != nil {
+			// TODO update linter to use go 1.20: https://github.com/hyperledger/aries-framework-go/issues/3613
+			return nil, errors.Join(ErrProofGeneration, e) // nolint:typecheck
+		}
+
+		return checkCreatedProof(proof, signerSuite, opts)
+	}
+
+	return sigBase, finalize, nil
+}
+
+// prepare returns the suite, the doc to sign and a copy of opts with the defaults and the
+// verification method set, for the proof creation.
+func (s *Signer) prepare(doc []byte, opts *models.ProofOptions) (suite.Signer, []byte, *models.ProofOptions, error) {
+	signerSuite, ok := s.suites[opts.SuiteType]
+	if !ok {
+		return nil, nil, nil, ErrUnsupportedSuite
+	}
+
+	// The defaults below and the suites fill in the options: work on a copy, so that
+	// the same options can be used for concurrent proofs.
+	optsCopy := *opts
+	opts = &optsCopy
+
+	if opts.PreviousProof != "" {
+		unsecuredDoc, err := sjson.DeleteBytes(doc, proofPath)
+		if err != nil {
+			return nil, nil, nil, ErrProofGeneration
+		}
+
+		doc, err = previousProofDoc(unsecuredDoc, gjson.GetBytes(doc, proofPath).Array(), opts.PreviousProof)
+		if err != nil {
+			return nil, nil, nil, err
+		}
+	}
+
+	if opts.Purpose != "" && !slices.Contains(s.purposes[opts.SuiteType], opts.Purpose) {
+		// TODO update linter to use go 1.20: https://github.com/hyperledger/aries-framework-go/issues/3613
+		return nil, nil, nil, errors.Join(ErrUnsupportedPurpose, // nolint:typecheck
+			fmt.Errorf("proof purpose %q is not supported by %s suite, supported purposes: [%s]",
+				opts.Purpose, opts.SuiteType, strings.Join(s.purposes[opts.SuiteType], ", ")))
+	}
+
+	err := resolveVM(context.Background(), opts, s.resolver, "", false)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+
+	opts.CustomFields, err = proofCustomFields(opts.CustomFields)
+	if err != nil {
+		// TODO update linter to use go 1.20: https://github.com/hyperledger/aries-framework-go/issues/3613
+		return nil, nil, nil, errors.Join(ErrProofGeneration, err) // nolint:typecheck
+	}
+
+	if opts.Created.IsZero() {
+		opts.Created = s.now()
+	}
+
+	// The proof times are serialized in UTC at second precision, as in the specification
+	// examples (eg "2023-02-24T23:36:38Z"), rather than with the time zone offset of the
+	// given times, unless the Signer keeps them as given.
+	if !s.givenTimes {
+		opts.Created = opts.Created.UTC().Truncate(time.Second)
+
+		if !opts.Expires.IsZero() {
+			opts.Expires = opts.Expires.UTC().Truncate(time.Second)
+		}
+	}
+
+	if opts.CanonicalizationAlgorithm == "" {
+		opts.CanonicalizationAlgorithm = s.algo
+	}
+
+	return signerSuite, doc, opts, nil
+}
+
+// proofCustomFields checks that the custom fields don't set reserved proof members, and returns
+// them as they are unmarshalled from the proof JSON, so that they are signed over as verified.
+func proofCustomFields(customFields map[string]interface{}) (map[string]interface{}, error) {
+	if len(customFields) == 0 {
+		return nil, nil
+	}
+
+	for name := range customFields {
+		if models.IsReservedProofField(name) {
+			return nil, fmt.Errorf("custom proof field %q is reserved", name)
+		}
+	}
+
+	fieldsBytes, err := json.Marshal(customFields)
+	if err != nil {
+		return nil, fmt.Errorf("marshal custom proof fields: %w", err)
+	}
+
+	var fields map[string]interface{}
+
+	if err = json.Unmarshal(fieldsBytes, &fields); err != nil {
+		return nil, fmt.Errorf("unmarshal custom proof fields: %w", err)
+	}
+
+	return fields, nil
+}
+
+func checkCreatedProof(proof *models.Proof, signerSuite suite.Signer, opts *models.ProofOptions) (*models.Proof, error) {
 	if proof.Type == "" || proof.ProofPurpose == "" || proof.VerificationMethod == "" {
 		return nil, ErrProofGeneration
 	}
@@ -120,49 +386,68 @@ func (s *Signer) AddProof(doc []byte, opts *models.ProofOptions) ([]byte, error)
 		return nil, ErrProofGeneration
 	}
 
-	proofRaw, err := json.Marshal(proof)
-	if err != nil {
+	if opts.Nonce != "" && opts.Nonce != proof.Nonce {
 		return nil, ErrProofGeneration
 	}
 
-	out, err := sjson.SetRawBytes(doc, proofPath, proofRaw)
-	if err != nil {
-		// TODO update linter to use go 1.20: https://github.com/hyperledger/aries-framework-go/issues/3613
-		return nil, errors.Join(ErrProofGeneration, err) // nolint:typecheck
+	return proof, nil
+}
+
+// ResolveVerificationMethod returns the verification method with the given id, found for the
+// proof purpose in the DID document resolved with the Signer DID resolver, as CreateProof does
+// for the proof options without a VerificationMethod.
+func (s *Signer) ResolveVerificationMethod(vmID, purpose string) (*models.VerificationMethod, error) {
+	opts := &models.ProofOptions{VerificationMethodID: vmID, Purpose: purpose}
+
+	if err := resolveVM(context.Background(), opts, s.resolver, "", false); err != nil {
+		return nil, err
 	}
 
-	return out, nil
+	return opts.VerificationMethod, nil
 }
 
-func resolveVM(opts *models.ProofOptions, resolver didResolver, vmID string) error {
-	if opts.VerificationMethod == nil {
-		if opts.VerificationMethodID == "" {
-			opts.VerificationMethodID = vmID
-		}
+func resolveVM(
+	ctx context.Context,
+	opts *models.ProofOptions,
+	resolver DIDResolver,
+	vmID string,
+	checkRelationship bool,
+) error {
+	if opts.VerificationMethod != nil && !checkRelationship {
+		return nil
+	}
 
-		if resolver == nil {
-			return ErrNoResolver
-		}
+	if opts.VerificationMethodID == "" {
+		opts.VerificationMethodID = vmID
+	}
 
-		didDoc, err := getDIDDocFromVerificationMethod(opts.VerificationMethodID, resolver)
-		if err != nil {
-			// TODO update linter to use go 1.20: https://github.com/hyperledger/aries-framework-go/issues/3613
-			return errors.Join(ErrVMResolution, err) // nolint:typecheck
-		}
+	if resolver == nil {
+		return ErrNoResolver
+	}
 
-		vm, err := getVMByPurpose(opts.Purpose, opts.VerificationMethodID, didDoc)
-		if err != nil {
-			// TODO update linter to use go 1.20: https://github.com/hyperledger/aries-framework-go/issues/3613
-			return errors.Join(ErrVMResolution, err) // nolint:typecheck
-		}
+	didDoc, err := getDIDDocFromVerificationMethod(ctx, opts.VerificationMethodID, resolver)
+	if err != nil {
+		// TODO update linter to use go 1.20: https://github.com/hyperledger/aries-framework-go/issues/3613
+		return errors.Join(ErrVMResolution, err) // nolint:typecheck
+	}
 
-		opts.VerificationMethod = vm
+	vm, err := getVMByPurpose(opts.Purpose, opts.VerificationMethodID, didDoc, checkRelationship)
+	if err != nil {
+		// TODO update linter to use go 1.20: https://github.com/hyperledger/aries-framework-go/issues/3613
+		return errors.Join(ErrVMResolution, err) // nolint:typecheck
 	}
 
+	// With the relationship check, the verification method of the DID document is used even
+	// if one was given, as only the DID document tells that it is authorized for the purpose.
+	opts.VerificationMethod = vm
+
 	return nil
 }
 
-func getVMByPurpose(purpose, vmID string, didDoc *did.Doc) (*did.VerificationMethod, error) {
+// getVMByPurpose returns the verification method vmID of didDoc listed under the verification
+// relationship of purpose. For assertionMethod, a verification method without relationship is
+// also allowed, unless strictRelationship is set.
+func getVMByPurpose(purpose, vmID string, didDoc *did.Doc, strictRelationship bool) (*did.VerificationMethod, error) {
 	var verificationMethod *did.VerificationMethod
 
 	vmIDFragment := vmIDFragmentOnly(vmID)
@@ -172,7 +457,7 @@ func getVMByPurpose(purpose, vmID string, didDoc *did.Doc) (*did.VerificationMet
 		assertionMethods := didDoc.VerificationMethods(did.AssertionMethod)[did.AssertionMethod]
 
 		verificationMethod = getVM(vmIDFragment, assertionMethods)
-		if verificationMethod == nil {
+		if verificationMethod == nil && !strictRelationship {
 			// A VM with general relationship is allowed for assertion
 			generalMethods :=
 				didDoc.VerificationMethods(did.VerificationRelationshipGeneral)[did.VerificationRelationshipGeneral]
@@ -196,8 +481,8 @@ func getVMByPurpose(purpose, vmID string, didDoc *did.Doc) (*did.VerificationMet
 	}
 
 	if verificationMethod == nil {
-		return nil, fmt.Errorf("unable to find matching %s key IDs for given verification method ID %s",
-			purpose, vmID)
+		return nil, fmt.Errorf("%w: verification method %s is not listed under %s of %s",
+			ErrMissingVerificationRelationship, vmID, purpose, didDoc.ID)
 	}
 
 	return verificationMethod, nil
@@ -222,13 +507,17 @@ func vmIDFragmentOnly(vmID string) string {
 	return vmSplit[1]
 }
 
-func getDIDDocFromVerificationMethod(verificationMethod string, didResolver didResolver) (*did.Doc, error) {
+func getDIDDocFromVerificationMethod(
+	ctx context.Context,
+	verificationMethod string,
+	didResolver DIDResolver,
+) (*did.Doc, error) {
 	didID, err := getDIDFromVerificationMethod(verificationMethod)
 	if err != nil {
 		return nil, err
 	}
 
-	docResolution, err := didResolver.Resolve(didID)
+	docResolution, err := resolveDIDContext(ctx, didResolver, didID)
 	if err != nil {
 		return nil, err
 	}
@@ -37,6 +37,11 @@ func TestNewSigner(t *testing.T) {
 		require.NoError(t, err)
 		require.NotNil(t, s)
 		require.Len(t, s.suites, 2)
+
+		require.True(t, s.SupportsSuite(mockSuiteType))
+		require.True(t, s.SupportsSuite(mockSuiteType+"-but-different"))
+		require.False(t, s.SupportsSuite("unknown-suite"))
+		require.Equal(t, []string{mockSuiteType, mockSuiteType + "-but-different"}, s.Suites())
 	})
 
 	t.Run("initializer error", func(t *testing.T) {
@@ -112,6 +117,128 @@ func TestSigner_AddProof(t *testing.T) {
 		require.True(t, jsonEquals(unsignedDoc, mockDoc), "adding proof changed other parts of doc")
 	})
 
+	t.Run("create proof", func(t *testing.T) {
+		expectProof := &models.Proof{
+			Type:               mockSuiteType,
+			ProofPurpose:       AssertionMethod,
+			VerificationMethod: "mock-vm",
+		}
+
+		s, err := NewSigner(
+			&Options{},
+			&mockSuiteInitializer{
+				mockSuite: &mockSuite{
+					CreateProofVal: expectProof,
+				},
+				typeStr: mockSuiteType,
+			})
+
+		require.NoError(t, err)
+
+		proof, err := s.CreateProof(mockDoc, &models.ProofOptions{
+			SuiteType:          mockSuiteType,
+			VerificationMethod: &did.VerificationMethod{ID: "mock-vm"},
+			Purpose:            AssertionMethod,
+		})
+		require.NoError(t, err)
+		require.Equal(t, expectProof, proof)
+	})
+
+	t.Run("clock", func(t *testing.T) {
+		now := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+
+		m := &mockSuite{
+			CreateProofVal: &models.Proof{
+				Type:               mockSuiteType,
+				ProofPurpose:       AssertionMethod,
+				VerificationMethod: "mock-vm",
+			},
+		}
+
+		s, err := NewSigner(
+			(&Options{}).WithClock(func() time.Time { return now }),
+			&mockSuiteInitializer{
+				mockSuite: m,
+				typeStr:   mockSuiteType,
+			})
+		require.NoError(t, err)
+
+		opts := &models.ProofOptions{
+			SuiteType:          mockSuiteType,
+			VerificationMethod: &did.VerificationMethod{ID: "mock-vm"},
+			Purpose:            AssertionMethod,
+		}
+
+		_, err = s.CreateProof(mockDoc, opts)
+		require.NoError(t, err)
+		require.Equal(t, now, m.createProofOpts.Created)
+		require.True(t, opts.Created.IsZero())
+
+		created := now.Add(-time.Hour)
+		opts.Created = created
+
+		_, err = s.CreateProof(mockDoc, opts)
+		require.NoError(t, err)
+		require.Equal(t, created, m.createProofOpts.Created)
+	})
+
+	t.Run("created and expires precision", func(t *testing.T) {
+		m := &mockSuite{
+			CreateProofVal: &models.Proof{
+				Type:               mockSuiteType,
+				ProofPurpose:       AssertionMethod,
+				VerificationMethod: "mock-vm",
+			},
+		}
+
+		s, err := NewSigner(&Options{}, &mockSuiteInitializer{mockSuite: m, typeStr: mockSuiteType})
+		require.NoError(t, err)
+
+		zone := time.FixedZone("UTC+2", 2*60*60)
+
+		_, err = s.CreateProof(mockDoc, &models.ProofOptions{
+			SuiteType:          mockSuiteType,
+			VerificationMethod: &did.VerificationMethod{ID: "mock-vm"},
+			Purpose:            AssertionMethod,
+			Created:            time.Date(2023, 2, 25, 1, 36, 38, 123456789, zone),
+			Expires:            time.Date(2024, 2, 25, 1, 36, 38, 999999999, zone),
+		})
+		require.NoError(t, err)
+
+		// As in https://www.w3.org/TR/vc-di-ecdsa/#example-signed-credential.
+		require.Equal(t, "2023-02-24T23:36:38Z", m.createProofOpts.Created.Format(models.DateTimeFormat))
+		require.Equal(t, "2024-02-24T23:36:38Z", m.createProofOpts.Expires.Format(models.DateTimeFormat))
+
+		_, err = s.CreateProof(mockDoc, &models.ProofOptions{
+			SuiteType:          mockSuiteType,
+			VerificationMethod: &did.VerificationMethod{ID: "mock-vm"},
+			Purpose:            AssertionMethod,
+		})
+		require.NoError(t, err)
+		require.True(t, m.createProofOpts.Expires.IsZero())
+		require.Zero(t, m.createProofOpts.Created.Nanosecond())
+		require.Equal(t, time.UTC, m.createProofOpts.Created.Location())
+
+		s, err = NewSigner((&Options{}).WithGivenProofTimes(),
+			&mockSuiteInitializer{mockSuite: m, typeStr: mockSuiteType})
+		require.NoError(t, err)
+
+		created := time.Date(2023, 2, 25, 1, 36, 38, 123456789, zone)
+		expires := time.Date(2024, 2, 25, 1, 36, 38, 999999999, zone)
+
+		_, err = s.CreateProof(mockDoc, &models.ProofOptions{
+			SuiteType:          mockSuiteType,
+			VerificationMethod: &did.VerificationMethod{ID: "mock-vm"},
+			Purpose:            AssertionMethod,
+			Created:            created,
+			Expires:            expires,
+		})
+		require.NoError(t, err)
+		require.Equal(t, created, m.createProofOpts.Created)
+		require.Equal(t, expires, m.createProofOpts.Expires)
+		require.Equal(t, "2023-02-25T01:36:38+02:00", m.createProofOpts.Created.Format(models.DateTimeFormat))
+	})
+
 	t.Run("failure", func(t *testing.T) {
 		t.Run("unsupported suite", func(t *testing.T) {
 			s, err := NewSigner(
@@ -134,6 +261,33 @@ func TestSigner_AddProof(t *testing.T) {
 			require.Nil(t, signedDoc)
 		})
 
+		t.Run("unsupported purpose", func(t *testing.T) {
+			s, err := NewSigner(
+				&Options{},
+				&mockSuiteInitializer{
+					mockSuite: &mockSuite{
+						PurposesVal: []string{AssertionMethod},
+					},
+					typeStr: mockSuiteType,
+				})
+
+			require.NoError(t, err)
+
+			signedDoc, err := s.AddProof(mockDoc, &models.ProofOptions{
+				SuiteType: mockSuiteType,
+				Purpose:   "keyAgreement",
+			})
+			require.ErrorIs(t, err, ErrUnsupportedPurpose)
+			require.ErrorContains(t, err, "supported purposes: [assertionMethod]")
+			require.Nil(t, signedDoc)
+
+			_, err = s.AddProof(mockDoc, &models.ProofOptions{
+				SuiteType: mockSuiteType,
+				Purpose:   Authentication,
+			})
+			require.ErrorIs(t, err, ErrUnsupportedPurpose)
+		})
+
 		t.Run("no resolver", func(t *testing.T) {
 			createdTime := time.Now().Format(models.DateTimeFormat)
 
@@ -0,0 +1,757 @@
+/*
+Copyright Gen Digital Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package bbs2023
+
+import (
+	"crypto/rand"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"math/big"
+	"sync"
+
+	"github.com/consensys/gnark-crypto/ecc"
+	bls12381 "github.com/consensys/gnark-crypto/ecc/bls12-381"
+	"github.com/consensys/gnark-crypto/ecc/bls12-381/fr"
+	"github.com/consensys/gnark-crypto/field/hash"
+)
+
+// The BBS signature scheme with the BLS12-381-SHA-256 ciphersuite and the hash to scalar
+// messages mapping, as per https://datatracker.ietf.org/doc/draft-irtf-cfrg-bbs-signatures/,
+// on top of the BLS12-381 pairing implementation of github.com/consensys/gnark-crypto.
+
+const (
+	ciphersuiteID = "BBS_BLS12381G1_XMD:SHA-256_SSWU_RO_"
+	apiID         = ciphersuiteID + "H2G_HM2S_"
+
+	expandLen     = 48
+	scalarSize    = fr.Bytes
+	g1PointSize   = bls12381.SizeOfG1AffineCompressed
+	signatureSize = g1PointSize + scalarSize
+	proofMinSize  = 3*g1PointSize + 4*scalarSize
+
+	// PrivateKeySize is the size of a BBS private key.
+	PrivateKeySize = scalarSize
+	// PublicKeySize is the size of a BBS public key, a compressed BLS12-381 G2 point.
+	PublicKeySize = bls12381.SizeOfG2AffineCompressed
+
+	keyMaterialMinSize = 32
+)
+
+var errInvalidBBSSignature = errors.New("invalid BBS signature")
+
+// PrivateKey is a BBS private key, which signs with the BLS12-381-SHA-256 ciphersuite.
+type PrivateKey struct {
+	sk fr.Element
+}
+
+// GenerateKey generates a BBS private key from random key material read from rand,
+// crypto/rand.Reader if nil.
+func GenerateKey(rand io.Reader) (*PrivateKey, error) {
+	keyMaterial := make([]byte, keyMaterialMinSize)
+
+	if _, err := io.ReadFull(randReader(rand), keyMaterial); err != nil {
+		return nil, err
+	}
+
+	return DeriveKey(keyMaterial, nil)
+}
+
+// DeriveKey derives a BBS private key from at least 32 bytes of secret key material, as KeyGen
+// of the BBS signature scheme with the default key DST. keyInfo is optional context information.
+func DeriveKey(keyMaterial, keyInfo []byte) (*PrivateKey, error) {
+	if len(keyMaterial) < keyMaterialMinSize {
+		return nil, fmt.Errorf("BBS key material should be at least %d bytes", keyMaterialMinSize)
+	}
+
+	if len(keyInfo) > 0xffff {
+		return nil, errors.New("BBS key info is too long")
+	}
+
+	input := make([]byte, 0, len(keyMaterial)+2+len(keyInfo))
+	input = append(input, keyMaterial...)
+	input = binary.BigEndian.AppendUint16(input, uint16(len(keyInfo)))
+	input = append(input, keyInfo...)
+
+	sk, err := hashToScalar(input, apiID+"KEYGEN_DST_")
+	if err != nil {
+		return nil, err
+	}
+
+	if sk.IsZero() {
+		return nil, errors.New("invalid BBS key material")
+	}
+
+	return &PrivateKey{sk: sk}, nil
+}
+
+// ParsePrivateKey parses the big-endian encoding of a BBS private key.
+func ParsePrivateKey(privateKey []byte) (*PrivateKey, error) {
+	sk, err := scalarFromBytes(privateKey)
+	if err != nil {
+		return nil, fmt.Errorf("invalid BBS private key: %w", err)
+	}
+
+	return &PrivateKey{sk: sk}, nil
+}
+
+// Bytes returns the big-endian encoding of the private key.
+func (k *PrivateKey) Bytes() []byte {
+	b := k.sk.Bytes()
+
+	return b[:]
+}
+
+// PublicKey returns the public key of the private key, a compressed BLS12-381 G2 point.
+func (k *PrivateKey) PublicKey() []byte {
+	var w bls12381.G2Affine
+
+	w.ScalarMultiplicationBase(scalarBigInt(&k.sk))
+
+	b := w.Bytes()
+
+	return b[:]
+}
+
+// Sign signs the messages and the header, which can be empty, implements Signer.
+func (k *PrivateKey) Sign(header []byte, messages [][]byte) ([]byte, error) {
+	publicKey := k.PublicKey()
+
+	scalars, err := messagesToScalars(messages)
+	if err != nil {
+		return nil, err
+	}
+
+	generators, err := createGenerators(len(messages) + 1)
+	if err != nil {
+		return nil, err
+	}
+
+	domain, err := calculateDomain(publicKey, generators, header)
+	if err != nil {
+		return nil, err
+	}
+
+	eInput := serializeScalars(append(append([]fr.Element{k.sk}, scalars...), domain)...)
+
+	e, err := hashToScalar(eInput, apiID+"H2S_")
+	if err != nil {
+		return nil, err
+	}
+
+	b, err := computeB(generators, domain, scalars, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	var skPlusE fr.Element
+
+	skPlusE.Add(&k.sk, &e)
+
+	if skPlusE.IsZero() {
+		return nil, errors.New("BBS signing failed")
+	}
+
+	skPlusE.Inverse(&skPlusE)
+
+	var a bls12381.G1Affine
+
+	a.ScalarMultiplication(&b, scalarBigInt(&skPlusE))
+
+	aBytes := a.Bytes()
+	eBytes := e.Bytes()
+
+	return append(aBytes[:], eBytes[:]...), nil
+}
+
+// verifySignature verifies the BBS signature of the messages and header with the public key.
+func verifySignature(publicKey, signature, header []byte, messages [][]byte) error {
+	w, err := parsePublicKey(publicKey)
+	if err != nil {
+		return err
+	}
+
+	if len(signature) != signatureSize {
+		return errInvalidBBSSignature
+	}
+
+	a, err := g1PointFromBytes(signature[:g1PointSize])
+	if err != nil {
+		return errInvalidBBSSignature
+	}
+
+	e, err := scalarFromBytes(signature[g1PointSize:])
+	if err != nil {
+		return errInvalidBBSSignature
+	}
+
+	scalars, err := messagesToScalars(messages)
+	if err != nil {
+		return err
+	}
+
+	generators, err := createGenerators(len(messages) + 1)
+	if err != nil {
+		return err
+	}
+
+	domain, err := calculateDomain(publicKey, generators, header)
+	if err != nil {
+		return err
+	}
+
+	b, err := computeB(generators, domain, scalars, nil)
+	if err != nil {
+		return err
+	}
+
+	// e(A, W + BP2 * e) * e(B, -BP2) == 1
+	_, _, _, bp2 := bls12381.Generators()
+
+	var wPlusE, negBP2 bls12381.G2Affine
+
+	wPlusE.ScalarMultiplicationBase(scalarBigInt(&e))
+	wPlusE.Add(&wPlusE, w)
+	negBP2.Neg(&bp2)
+
+	ok, err := bls12381.PairingCheck([]bls12381.G1Affine{*a, b}, []bls12381.G2Affine{wPlusE, negBP2})
+	if err != nil || !ok {
+		return errInvalidBBSSignature
+	}
+
+	return nil
+}
+
+// proofGen creates a BBS proof of knowledge of the signature of the messages, which reveals
+// only the messages at the disclosed indexes, given in ascending order. The presentation header
+// ph, which can be empty, is bound to the proof.
+func proofGen( //nolint:funlen
+	publicKey, signature, header, ph []byte,
+	messages [][]byte,
+	disclosedIndexes []int,
+	rand io.Reader,
+) ([]byte, error) {
+	if len(signature) != signatureSize {
+		return nil, errInvalidBBSSignature
+	}
+
+	a, err := g1PointFromBytes(signature[:g1PointSize])
+	if err != nil {
+		return nil, errInvalidBBSSignature
+	}
+
+	e, err := scalarFromBytes(signature[g1PointSize:])
+	if err != nil {
+		return nil, errInvalidBBSSignature
+	}
+
+	disclosed, err := checkDisclosedIndexes(disclosedIndexes, len(messages))
+	if err != nil {
+		return nil, err
+	}
+
+	scalars, err := messagesToScalars(messages)
+	if err != nil {
+		return nil, err
+	}
+
+	generators, err := createGenerators(len(messages) + 1)
+	if err != nil {
+		return nil, err
+	}
+
+	domain, err := calculateDomain(publicKey, generators, header)
+	if err != nil {
+		return nil, err
+	}
+
+	var undisclosedIndexes []int
+
+	for i := range messages {
+		if !disclosed[i] {
+			undisclosedIndexes = append(undisclosedIndexes, i)
+		}
+	}
+
+	random, err := randomScalars(5+len(undisclosedIndexes), rand)
+	if err != nil {
+		return nil, err
+	}
+
+	r1, r2, eTilde, r1Tilde, r3Tilde, mTilde := random[0], random[1], random[2], random[3], random[4], random[5:]
+
+	b, err := computeB(generators, domain, scalars, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	var r1r2 fr.Element
+
+	r1r2.Mul(&r1, &r2)
+
+	// D = B * r2, Abar = A * (r1 * r2), Bbar = D * r1 - Abar * e
+	var d, aBar, bBar, tmp bls12381.G1Affine
+
+	d.ScalarMultiplication(&b, scalarBigInt(&r2))
+	aBar.ScalarMultiplication(a, scalarBigInt(&r1r2))
+	bBar.ScalarMultiplication(&d, scalarBigInt(&r1))
+	tmp.ScalarMultiplication(&aBar, scalarBigInt(&e))
+	bBar.Sub(&bBar, &tmp)
+
+	// T1 = Abar * e~ + D * r1~, T2 = D * r3~ + H_j1 * m~_j1 + ... + H_jU * m~_jU
+	t1, err := multiExp([]bls12381.G1Affine{aBar, d}, []fr.Element{eTilde, r1Tilde})
+	if err != nil {
+		return nil, err
+	}
+
+	t2Points := []bls12381.G1Affine{d}
+	t2Scalars := []fr.Element{r3Tilde}
+
+	for i, j := range undisclosedIndexes {
+		t2Points = append(t2Points, generators[j+1])
+		t2Scalars = append(t2Scalars, mTilde[i])
+	}
+
+	t2, err := multiExp(t2Points, t2Scalars)
+	if err != nil {
+		return nil, err
+	}
+
+	challenge, err := proofChallenge(aBar, bBar, d, t1, t2, domain, disclosedIndexes, scalars, ph)
+	if err != nil {
+		return nil, err
+	}
+
+	var r3, eHat, r1Hat, r3Hat fr.Element
+
+	r3.Inverse(&r2)
+
+	eHat.Mul(&e, &challenge)
+	eHat.Add(&eTilde, &eHat)
+
+	r1Hat.Mul(&r1, &challenge)
+	r1Hat.Sub(&r1Tilde, &r1Hat)
+
+	r3Hat.Mul(&r3, &challenge)
+	r3Hat.Sub(&r3Tilde, &r3Hat)
+
+	proof := serializePoints(aBar, bBar, d)
+	proof = append(proof, serializeScalars(eHat, r1Hat, r3Hat)...)
+
+	for i, j := range undisclosedIndexes {
+		var mHat fr.Element
+
+		mHat.Mul(&scalars[j], &challenge)
+		mHat.Add(&mTilde[i], &mHat)
+
+		proof = append(proof, serializeScalars(mHat)...)
+	}
+
+	return append(proof, serializeScalars(challenge)...), nil
+}
+
+// proofVerify verifies the BBS proof of the disclosed messages, at the disclosed indexes
+// given in ascending order, with the public key, header and presentation header ph.
+func proofVerify( //nolint:funlen
+	publicKey, proof, header, ph []byte,
+	disclosedMessages [][]byte,
+	disclosedIndexes []int,
+) error {
+	w, err := parsePublicKey(publicKey)
+	if err != nil {
+		return err
+	}
+
+	if len(proof) < proofMinSize || (len(proof)-proofMinSize)%scalarSize != 0 {
+		return errors.New("invalid BBS proof size")
+	}
+
+	if len(disclosedMessages) != len(disclosedIndexes) {
+		return fmt.Errorf("BBS proof has %d disclosed indexes for %d disclosed messages",
+			len(disclosedIndexes), len(disclosedMessages))
+	}
+
+	undisclosedCount := (len(proof) - proofMinSize) / scalarSize
+	messageCount := len(disclosedIndexes) + undisclosedCount
+
+	disclosed, err := checkDisclosedIndexes(disclosedIndexes, messageCount)
+	if err != nil {
+		return err
+	}
+
+	points := make([]bls12381.G1Affine, 3)
+
+	for i := range points {
+		p, errPoint := g1PointFromBytes(proof[i*g1PointSize : (i+1)*g1PointSize])
+		if errPoint != nil {
+			return fmt.Errorf("invalid BBS proof: %w", errPoint)
+		}
+
+		points[i] = *p
+	}
+
+	aBar, bBar, d := points[0], points[1], points[2]
+
+	scalarBytes := proof[3*g1PointSize:]
+	scalars := make([]fr.Element, len(scalarBytes)/scalarSize)
+
+	for i := range scalars {
+		scalars[i], err = scalarFromBytes(scalarBytes[i*scalarSize : (i+1)*scalarSize])
+		if err != nil {
+			return fmt.Errorf("invalid BBS proof: %w", err)
+		}
+	}
+
+	eHat, r1Hat, r3Hat, mHat, challenge := scalars[0], scalars[1], scalars[2], scalars[3:len(scalars)-1],
+		scalars[len(scalars)-1]
+
+	disclosedScalars, err := messagesToScalars(disclosedMessages)
+	if err != nil {
+		return err
+	}
+
+	generators, err := createGenerators(messageCount + 1)
+	if err != nil {
+		return err
+	}
+
+	domain, err := calculateDomain(publicKey, generators, header)
+	if err != nil {
+		return err
+	}
+
+	// T1 = Bbar * c + Abar * e^ + D * r1^
+	t1, err := multiExp([]bls12381.G1Affine{bBar, aBar, d}, []fr.Element{challenge, eHat, r1Hat})
+	if err != nil {
+		return err
+	}
+
+	// Bv = P1 + Q_1 * domain + H_i1 * msg_i1 + ... + H_iR * msg_iR
+	messageScalars := make(map[int]fr.Element, len(disclosedIndexes))
+
+	for i, index := range disclosedIndexes {
+		messageScalars[index] = disclosedScalars[i]
+	}
+
+	bv, err := computeB(generators, domain, nil, messageScalars)
+	if err != nil {
+		return err
+	}
+
+	// T2 = Bv * c + D * r3^ + H_j1 * m^_j1 + ... + H_jU * m^_jU
+	t2Points := []bls12381.G1Affine{bv, d}
+	t2Scalars := []fr.Element{challenge, r3Hat}
+
+	j := 0
+
+	for i := 0; i < messageCount; i++ {
+		if disclosed[i] {
+			continue
+		}
+
+		t2Points = append(t2Points, generators[i+1])
+		t2Scalars = append(t2Scalars, mHat[j])
+		j++
+	}
+
+	t2, err := multiExp(t2Points, t2Scalars)
+	if err != nil {
+		return err
+	}
+
+	allScalars := make([]fr.Element, messageCount)
+
+	for index, scalar := range messageScalars {
+		allScalars[index] = scalar
+	}
+
+	expected, err := proofChallenge(aBar, bBar, d, t1, t2, domain, disclosedIndexes, allScalars, ph)
+	if err != nil {
+		return err
+	}
+
+	if !expected.Equal(&challenge) {
+		return errors.New("invalid BBS proof challenge")
+	}
+
+	// e(Abar, W) * e(Bbar, -BP2) == 1
+	_, _, _, bp2 := bls12381.Generators()
+
+	var negBP2 bls12381.G2Affine
+
+	negBP2.Neg(&bp2)
+
+	ok, err := bls12381.PairingCheck([]bls12381.G1Affine{aBar, bBar}, []bls12381.G2Affine{*w, negBP2})
+	if err != nil || !ok {
+		return errors.New("invalid BBS proof")
+	}
+
+	return nil
+}
+
+// proofChallenge calculates the challenge of a BBS proof. The scalars are indexed by message index,
+// only the ones of the disclosed indexes are used.
+func proofChallenge(
+	aBar, bBar, d, t1, t2 bls12381.G1Affine,
+	domain fr.Element,
+	disclosedIndexes []int,
+	scalars []fr.Element,
+	ph []byte,
+) (fr.Element, error) {
+	input := binary.BigEndian.AppendUint64(nil, uint64(len(disclosedIndexes)))
+
+	for _, i := range disclosedIndexes {
+		input = binary.BigEndian.AppendUint64(input, uint64(i))
+		input = append(input, serializeScalars(scalars[i])...)
+	}
+
+	input = append(input, serializePoints(aBar, bBar, d, t1, t2)...)
+	input = append(input, serializeScalars(domain)...)
+	input = binary.BigEndian.AppendUint64(input, uint64(len(ph)))
+	input = append(input, ph...)
+
+	return hashToScalar(input, apiID+"H2S_")
+}
+
+// computeB returns P1 + Q_1 * domain + H_1 * msg_1 + ... + H_L * msg_L, for the message scalars
+// given in order, or by index.
+func computeB(
+	generators []bls12381.G1Affine,
+	domain fr.Element,
+	scalars []fr.Element,
+	indexedScalars map[int]fr.Element,
+) (bls12381.G1Affine, error) {
+	p1, err := basePoint()
+	if err != nil {
+		return bls12381.G1Affine{}, err
+	}
+
+	points := []bls12381.G1Affine{generators[0]}
+	pointScalars := []fr.Element{domain}
+
+	for i, scalar := range scalars {
+		points = append(points, generators[i+1])
+		pointScalars = append(pointScalars, scalar)
+	}
+
+	for i, scalar := range indexedScalars {
+		points = append(points, generators[i+1])
+		pointScalars = append(pointScalars, scalar)
+	}
+
+	b, err := multiExp(points, pointScalars)
+	if err != nil {
+		return bls12381.G1Affine{}, err
+	}
+
+	b.Add(&b, &p1)
+
+	return b, nil
+}
+
+// calculateDomain binds the public key, the generators and the header to the signature.
+func calculateDomain(publicKey []byte, generators []bls12381.G1Affine, header []byte) (fr.Element, error) {
+	input := append([]byte{}, publicKey...)
+	input = binary.BigEndian.AppendUint64(input, uint64(len(generators)-1))
+	input = append(input, serializePoints(generators...)...)
+	input = append(input, apiID...)
+	input = binary.BigEndian.AppendUint64(input, uint64(len(header)))
+	input = append(input, header...)
+
+	return hashToScalar(input, apiID+"H2S_")
+}
+
+// createGenerators returns Q_1 followed by the message generators H_1, ..., H_L for count = L + 1.
+func createGenerators(count int) ([]bls12381.G1Affine, error) {
+	return generatorsFromSeed(count, apiID+"MESSAGE_GENERATOR_SEED")
+}
+
+// basePoint is the fixed point P1 of G1 of the ciphersuite.
+var basePoint = sync.OnceValues(func() (bls12381.G1Affine, error) { //nolint:gochecknoglobals
+	p1, err := generatorsFromSeed(1, apiID+"BP_MESSAGE_GENERATOR_SEED")
+	if err != nil {
+		return bls12381.G1Affine{}, err
+	}
+
+	return p1[0], nil
+})
+
+func generatorsFromSeed(count int, seed string) ([]bls12381.G1Affine, error) {
+	seedDST := []byte(apiID + "SIG_GENERATOR_SEED_")
+	generatorDST := []byte(apiID + "SIG_GENERATOR_DST_")
+
+	v, err := hash.ExpandMsgXmd([]byte(seed), seedDST, expandLen)
+	if err != nil {
+		return nil, err
+	}
+
+	generators := make([]bls12381.G1Affine, count)
+
+	for i := range generators {
+		v, err = hash.ExpandMsgXmd(binary.BigEndian.AppendUint64(v, uint64(i+1)), seedDST, expandLen)
+		if err != nil {
+			return nil, err
+		}
+
+		generators[i], err = bls12381.HashToG1(v, generatorDST)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return generators, nil
+}
+
+func messagesToScalars(messages [][]byte) ([]fr.Element, error) {
+	scalars := make([]fr.Element, len(messages))
+
+	for i, message := range messages {
+		scalar, err := hashToScalar(message, apiID+"MAP_MSG_TO_SCALAR_AS_HASH_")
+		if err != nil {
+			return nil, err
+		}
+
+		scalars[i] = scalar
+	}
+
+	return scalars, nil
+}
+
+func hashToScalar(msg []byte, dst string) (fr.Element, error) {
+	uniform, err := hash.ExpandMsgXmd(msg, []byte(dst), expandLen)
+	if err != nil {
+		return fr.Element{}, err
+	}
+
+	var scalar fr.Element
+
+	scalar.SetBigInt(new(big.Int).SetBytes(uniform))
+
+	return scalar, nil
+}
+
+func randomScalars(count int, rand io.Reader) ([]fr.Element, error) {
+	scalars := make([]fr.Element, count)
+	buf := make([]byte, expandLen)
+
+	for i := range scalars {
+		if _, err := io.ReadFull(randReader(rand), buf); err != nil {
+			return nil, err
+		}
+
+		scalars[i].SetBigInt(new(big.Int).SetBytes(buf))
+	}
+
+	return scalars, nil
+}
+
+func randReader(r io.Reader) io.Reader {
+	if r == nil {
+		return rand.Reader
+	}
+
+	return r
+}
+
+func multiExp(points []bls12381.G1Affine, scalars []fr.Element) (bls12381.G1Affine, error) {
+	var p bls12381.G1Affine
+
+	_, err := p.MultiExp(points, scalars, ecc.MultiExpConfig{})
+	if err != nil {
+		return p, err
+	}
+
+	return p, nil
+}
+
+// checkDisclosedIndexes checks that the indexes are in ascending order and lower than count.
+func checkDisclosedIndexes(indexes []int, count int) (map[int]bool, error) {
+	set := make(map[int]bool, len(indexes))
+
+	for i, index := range indexes {
+		if index < 0 || index >= count || (i > 0 && index <= indexes[i-1]) {
+			return nil, fmt.Errorf("invalid BBS disclosed message index %d", index)
+		}
+
+		set[index] = true
+	}
+
+	return set, nil
+}
+
+func parsePublicKey(publicKey []byte) (*bls12381.G2Affine, error) {
+	var w bls12381.G2Affine
+
+	if len(publicKey) != PublicKeySize || publicKey[0]&0x80 == 0 {
+		return nil, errors.New("invalid BBS public key")
+	}
+
+	if _, err := w.SetBytes(publicKey); err != nil || w.IsInfinity() {
+		return nil, errors.New("invalid BBS public key")
+	}
+
+	return &w, nil
+}
+
+func g1PointFromBytes(b []byte) (*bls12381.G1Affine, error) {
+	var p bls12381.G1Affine
+
+	if len(b) != g1PointSize || b[0]&0x80 == 0 {
+		return nil, errors.New("invalid G1 point")
+	}
+
+	if _, err := p.SetBytes(b); err != nil || p.IsInfinity() {
+		return nil, errors.New("invalid G1 point")
+	}
+
+	return &p, nil
+}
+
+func scalarFromBytes(b []byte) (fr.Element, error) {
+	var scalar fr.Element
+
+	if len(b) != scalarSize {
+		return scalar, errors.New("invalid scalar size")
+	}
+
+	if err := scalar.SetBytesCanonical(b); err != nil {
+		return scalar, err
+	}
+
+	if scalar.IsZero() {
+		return scalar, errors.New("invalid zero scalar")
+	}
+
+	return scalar, nil
+}
+
+func scalarBigInt(scalar *fr.Element) *big.Int {
+	return scalar.BigInt(new(big.Int))
+}
+
+func serializePoints(points ...bls12381.G1Affine) []byte {
+	out := make([]byte, 0, len(points)*g1PointSize)
+
+	for i := range points {
+		b := points[i].Bytes()
+		out = append(out, b[:]...)
+	}
+
+	return out
+}
+
+func serializeScalars(scalars ...fr.Element) []byte {
+	out := make([]byte, 0, len(scalars)*scalarSize)
+
+	for i := range scalars {
+		b := scalars[i].Bytes()
+		out = append(out, b[:]...)
+	}
+
+	return out
+}
@@ -0,0 +1,739 @@
+/*
+Copyright Gen Digital Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+// Package bbs2023 implements the bbs-2023 data integrity cryptographic suite, whose derived
+// proofs are unlinkable. The BBS signatures use the BLS12-381 pairing implementation of
+// github.com/consensys/gnark-crypto.
+package bbs2023
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/fxamacker/cbor/v2"
+	"github.com/multiformats/go-multibase"
+	"github.com/piprate/json-gold/ld"
+
+	"github.com/trustbloc/vc-go/dataintegrity/models"
+	"github.com/trustbloc/vc-go/dataintegrity/suite"
+	"github.com/trustbloc/vc-go/dataintegrity/suite/internal/selective"
+)
+
+const (
+	// SuiteType "bbs-2023" is the data integrity Type identifier for the suite
+	// implementing BBS selective disclosure signatures as per this
+	// spec:https://www.w3.org/TR/vc-di-bbs/#bbs-2023
+	SuiteType = "bbs-2023"
+)
+
+const (
+	ldCtxKey            = "@context"
+	hmacKeySize         = 32
+	hmacLabelPrefix     = "u"
+	shuffledLabelPrefix = "b"
+	mandatoryGroup      = "mandatory"
+	selectiveGroup      = "selective"
+	combinedGroup       = "combined"
+)
+
+var (
+	baseProofHeader    = []byte{0xd9, 0x5d, 0x02}
+	derivedProofHeader = []byte{0xd9, 0x5d, 0x03}
+	multikeyBLSHeader  = []byte{0xeb, 0x01}
+)
+
+// SignerGetter returns a Signer, which must sign with the private key matching the
+// BBS public key of the verification method provided in models.ProofOptions.
+type SignerGetter func(publicKey []byte) (Signer, error)
+
+// WithStaticSigner sets the Suite to use a fixed Signer, with externally-chosen signing key.
+//
+// Use when a signing Suite is initialized for a single signature, then thrown away.
+func WithStaticSigner(signer Signer) SignerGetter {
+	return func([]byte) (Signer, error) {
+		return signer, nil
+	}
+}
+
+// A Signer is able to create BBS signatures, eg a PrivateKey.
+type Signer interface {
+	// Sign will sign the messages and header using a private key internal to the Signer.
+	// returns:
+	// 		BBS signature in []byte
+	//		error in case of errors
+	Sign(header []byte, messages [][]byte) ([]byte, error)
+}
+
+// Suite implements the bbs-2023 data integrity cryptographic suite with BLS12-381 G2 keys.
+//
+// The issuer creates a base proof, which signs the mandatory statements in the BBS header and
+// every other statement as a BBS message. The holder derives from the base proof a disclosure
+// proof, which reveals only the mandatory and the selected statements. Unlike ecdsa-sd-2023,
+// the derived proofs don't carry the base signature, so that they can't be linked to each other.
+// The verifier accepts both base and derived proofs.
+type Suite struct {
+	ldLoader     ld.DocumentLoader
+	signerGetter SignerGetter
+}
+
+// Options provides initialization options for Suite.
+type Options struct {
+	LDDocumentLoader ld.DocumentLoader
+	SignerGetter     SignerGetter
+}
+
+// SuiteInitializer is the initializer for Suite.
+type SuiteInitializer func() (*Suite, error)
+
+// New constructs an initializer for Suite.
+func New(options *Options) SuiteInitializer {
+	return func() (*Suite, error) {
+		return &Suite{
+			ldLoader:     options.LDDocumentLoader,
+			signerGetter: options.SignerGetter,
+		}, nil
+	}
+}
+
+type initializer SuiteInitializer
+
+// Signer private, implements suite.SignerInitializer.
+func (i initializer) Signer() (suite.Signer, error) {
+	return i()
+}
+
+// Verifier private, implements suite.VerifierInitializer.
+func (i initializer) Verifier() (suite.Verifier, error) {
+	return i()
+}
+
+// Deriver private, implements suite.DeriverInitializer.
+func (i initializer) Deriver() (suite.Deriver, error) {
+	return i()
+}
+
+// Type private, implements suite.SignerInitializer, suite.VerifierInitializer
+// and suite.DeriverInitializer.
+func (i initializer) Type() []string {
+	return []string{SuiteType}
+}
+
+// SignerInitializerOptions provides options for a SignerInitializer.
+type SignerInitializerOptions struct {
+	LDDocumentLoader ld.DocumentLoader
+	SignerGetter     SignerGetter
+}
+
+// NewSignerInitializer returns a suite.SignerInitializer that initializes a bbs-2023
+// signing Suite with the given SignerInitializerOptions.
+func NewSignerInitializer(options *SignerInitializerOptions) suite.SignerInitializer {
+	return initializer(New(&Options{
+		LDDocumentLoader: options.LDDocumentLoader,
+		SignerGetter:     options.SignerGetter,
+	}))
+}
+
+// VerifierInitializerOptions provides options for a VerifierInitializer.
+type VerifierInitializerOptions struct {
+	LDDocumentLoader ld.DocumentLoader // required
+}
+
+// NewVerifierInitializer returns a suite.VerifierInitializer that initializes a
+// bbs-2023 verification Suite with the given VerifierInitializerOptions.
+func NewVerifierInitializer(options *VerifierInitializerOptions) suite.VerifierInitializer {
+	return initializer(New(&Options{
+		LDDocumentLoader: options.LDDocumentLoader,
+	}))
+}
+
+// DeriverInitializerOptions provides options for a DeriverInitializer.
+type DeriverInitializerOptions struct {
+	LDDocumentLoader ld.DocumentLoader // required
+}
+
+// NewDeriverInitializer returns a suite.DeriverInitializer that initializes a
+// bbs-2023 Suite for deriving disclosure proofs with the given DeriverInitializerOptions.
+func NewDeriverInitializer(options *DeriverInitializerOptions) suite.DeriverInitializer {
+	return initializer(New(&Options{
+		LDDocumentLoader: options.LDDocumentLoader,
+	}))
+}
+
+type baseProofValue struct {
+	_                 struct{} `cbor:",toarray"`
+	BBSSignature      []byte
+	BBSHeader         []byte
+	PublicKey         []byte
+	HMACKey           []byte
+	MandatoryPointers []string
+}
+
+type derivedProofValue struct {
+	_                  struct{} `cbor:",toarray"`
+	BBSProof           []byte
+	LabelMap           map[int]int
+	MandatoryIndexes   []int
+	SelectiveIndexes   []int
+	PresentationHeader []byte
+}
+
+// CreateProof implements the bbs-2023 cryptographic suite for Add Base Proof:
+// https://www.w3.org/TR/vc-di-bbs/#base-proof-transformation-bbs-2023
+func (s *Suite) CreateProof(doc []byte, opts *models.ProofOptions) (*models.Proof, error) {
+	if opts.SuiteType == "" {
+		opts.SuiteType = SuiteType
+	}
+
+	if opts.PreviousProof != "" {
+		return nil, errors.New("bbs-2023 proofs can't be chained to a previous proof")
+	}
+
+	docData, publicKey, err := s.transform(doc, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	proofHash, err := s.proofHash(docData[ldCtxKey], opts)
+	if err != nil {
+		return nil, err
+	}
+
+	hmacKey := make([]byte, hmacKeySize)
+
+	if _, err = rand.Read(hmacKey); err != nil {
+		return nil, err
+	}
+
+	groups, err := s.processor().CanonicalizeAndGroup(docData, shuffledLabels(hmacKey), map[string][]string{
+		mandatoryGroup: opts.MandatoryPointers,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	mandatory := groups.Statements(groups.Groups[mandatoryGroup].Matching)
+	nonMandatory := groups.Statements(groups.Groups[mandatoryGroup].NonMatching)
+
+	signer, err := s.signerGetter(publicKey)
+	if err != nil {
+		return nil, err
+	}
+
+	bbsHeader := append(append([]byte{}, proofHash...), hashStatements(mandatory)...)
+
+	bbsSignature, err := signer.Sign(bbsHeader, statementMessages(nonMandatory))
+	if err != nil {
+		return nil, err
+	}
+
+	mandatoryPointers := opts.MandatoryPointers
+	if mandatoryPointers == nil {
+		mandatoryPointers = []string{}
+	}
+
+	proofValue, err := encodeProofValue(baseProofHeader, &baseProofValue{
+		BBSSignature:      bbsSignature,
+		BBSHeader:         bbsHeader,
+		PublicKey:         publicKey,
+		HMACKey:           hmacKey,
+		MandatoryPointers: mandatoryPointers,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	var expires string
+	if !opts.Expires.IsZero() {
+		expires = opts.Expires.Format(models.DateTimeFormat)
+	}
+
+	p := &models.Proof{
+		Type:               models.DataIntegrityProof,
+		CryptoSuite:        opts.SuiteType,
+		ProofPurpose:       opts.Purpose,
+		Domain:             opts.Domain,
+		Challenge:          opts.Challenge,
+		Nonce:              opts.Nonce,
+		VerificationMethod: opts.VerificationMethod.ID,
+		ProofValue:         proofValue,
+		Created:            opts.Created.Format(models.DateTimeFormat),
+		Expires:            expires,
+		ID:                 opts.ProofID,
+		PreviousProof:      opts.PreviousProof,
+		CustomFields:       opts.CustomFields,
+	}
+
+	return p, nil
+}
+
+// DeriveProof implements the bbs-2023 cryptographic suite for Add Derived Proof:
+// https://www.w3.org/TR/vc-di-bbs/#add-derived-proof-bbs-2023
+//
+// The returned document contains the statements selected by the mandatory pointers of
+// the base proof and by selectivePointers, the returned proof is the disclosure proof for it.
+// Each call creates a new BBS proof, unlinkable to the other proofs derived from the base proof.
+func (s *Suite) DeriveProof( // nolint:funlen
+	doc []byte,
+	proof *models.Proof,
+	selectivePointers []string,
+) ([]byte, *models.Proof, error) {
+	docData := make(map[string]interface{})
+
+	err := json.Unmarshal(doc, &docData)
+	if err != nil {
+		return nil, nil, fmt.Errorf("bbs-2023 suite expects JSON-LD payload: %w", err)
+	}
+
+	base, err := parseBaseProofValue(proof.ProofValue)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	combinedPointers := append(append([]string{}, base.MandatoryPointers...), selectivePointers...)
+
+	groups, err := s.processor().CanonicalizeAndGroup(docData, shuffledLabels(base.HMACKey), map[string][]string{
+		mandatoryGroup: base.MandatoryPointers,
+		selectiveGroup: selectivePointers,
+		combinedGroup:  combinedPointers,
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+
+	mandatory := groups.Groups[mandatoryGroup]
+	mandatoryMatching := selective.IndexSet(mandatory.Matching)
+	selectiveMatching := selective.IndexSet(groups.Groups[selectiveGroup].Matching)
+
+	mandatoryIndexes := []int{}
+
+	for relativeIndex, absoluteIndex := range groups.Groups[combinedGroup].Matching {
+		if mandatoryMatching[absoluteIndex] {
+			mandatoryIndexes = append(mandatoryIndexes, relativeIndex)
+		}
+	}
+
+	// The selective indexes are relative to the non-mandatory statements, the BBS messages.
+	selectiveIndexes := []int{}
+
+	for relativeIndex, absoluteIndex := range mandatory.NonMatching {
+		if selectiveMatching[absoluteIndex] {
+			selectiveIndexes = append(selectiveIndexes, relativeIndex)
+		}
+	}
+
+	bbsProof, err := proofGen(base.PublicKey, base.BBSSignature, base.BBSHeader, nil,
+		statementMessages(groups.Statements(mandatory.NonMatching)), selectiveIndexes, nil)
+	if err != nil {
+		return nil, nil, fmt.Errorf("creating bbs-2023 derived proof: %w", err)
+	}
+
+	labels, err := groups.VerifierLabelMap(groups.Groups[combinedGroup].DeskolemizedNQuads)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	labelMap := make(map[int]int, len(labels))
+
+	for index, label := range labels {
+		labelMap[index], err = strconv.Atoi(strings.TrimPrefix(label, shuffledLabelPrefix))
+		if err != nil {
+			return nil, nil, err
+		}
+	}
+
+	revealDoc, err := selective.SelectJSONLD(combinedPointers, groups.Skolemized)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	revealDocBytes, err := json.Marshal(selective.Unskolemize(revealDoc))
+	if err != nil {
+		return nil, nil, err
+	}
+
+	proofValue, err := encodeProofValue(derivedProofHeader, &derivedProofValue{
+		BBSProof:           bbsProof,
+		LabelMap:           labelMap,
+		MandatoryIndexes:   mandatoryIndexes,
+		SelectiveIndexes:   selectiveIndexes,
+		PresentationHeader: []byte{},
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+
+	derivedProof := *proof
+	derivedProof.ProofValue = proofValue
+
+	return revealDocBytes, &derivedProof, nil
+}
+
+// VerifyProof implements the bbs-2023 cryptographic suite for Verify Derived Proof:
+// https://www.w3.org/TR/vc-di-bbs/#verify-derived-proof-bbs-2023
+//
+// Base proofs are verified as well, so that the holder can check the credential before disclosure.
+func (s *Suite) VerifyProof(doc []byte, proof *models.Proof, opts *models.ProofOptions) error {
+	return s.VerifyProofContext(context.Background(), doc, proof, opts)
+}
+
+// VerifyProofContext verifies the proof as VerifyProof, with the JSON-LD contexts loaded until
+// ctx is done, implements suite.ContextVerifier.
+func (s *Suite) VerifyProofContext(
+	ctx context.Context,
+	doc []byte,
+	proof *models.Proof,
+	opts *models.ProofOptions,
+) error {
+	ctxSuite := *s
+	ctxSuite.ldLoader = suite.DocumentLoaderWithContext(ctx, s.ldLoader)
+
+	return ctxSuite.verifyProof(doc, proof, opts)
+}
+
+func (s *Suite) verifyProof(doc []byte, proof *models.Proof, opts *models.ProofOptions) error {
+	docData, publicKey, err := s.transform(doc, opts)
+	if err != nil {
+		return err
+	}
+
+	proofHash, err := s.proofHash(docData[ldCtxKey], opts)
+	if err != nil {
+		return err
+	}
+
+	_, proofValue, err := multibase.Decode(proof.ProofValue)
+	if err != nil {
+		return fmt.Errorf("decoding proofValue: %w", err)
+	}
+
+	switch {
+	case bytes.HasPrefix(proofValue, baseProofHeader):
+		base, errParse := parseBaseProofValue(proof.ProofValue)
+		if errParse != nil {
+			return errParse
+		}
+
+		groups, errGroup := s.processor().CanonicalizeAndGroup(docData, shuffledLabels(base.HMACKey),
+			map[string][]string{mandatoryGroup: base.MandatoryPointers})
+		if errGroup != nil {
+			return errGroup
+		}
+
+		mandatory := groups.Statements(groups.Groups[mandatoryGroup].Matching)
+		nonMandatory := groups.Statements(groups.Groups[mandatoryGroup].NonMatching)
+
+		bbsHeader := append(append([]byte{}, proofHash...), hashStatements(mandatory)...)
+
+		err = verifySignature(publicKey, base.BBSSignature, bbsHeader, statementMessages(nonMandatory))
+		if err != nil {
+			return fmt.Errorf("failed to verify bbs-2023 DI base proof: %w", err)
+		}
+	case bytes.HasPrefix(proofValue, derivedProofHeader):
+		derived, errParse := parseDerivedProofValue(proofValue)
+		if errParse != nil {
+			return errParse
+		}
+
+		mandatory, nonMandatory, errSplit := s.splitDisclosedStatements(docData, derived)
+		if errSplit != nil {
+			return errSplit
+		}
+
+		if len(derived.SelectiveIndexes) != len(nonMandatory) {
+			return fmt.Errorf("bbs-2023 proof has %d selective indexes for %d non-mandatory statements",
+				len(derived.SelectiveIndexes), len(nonMandatory))
+		}
+
+		bbsHeader := append(append([]byte{}, proofHash...), hashStatements(mandatory)...)
+
+		err = proofVerify(publicKey, derived.BBSProof, bbsHeader, derived.PresentationHeader,
+			statementMessages(nonMandatory), derived.SelectiveIndexes)
+		if err != nil {
+			return fmt.Errorf("failed to verify bbs-2023 DI derived proof: %w", err)
+		}
+	default:
+		return errors.New("bbs-2023 proofValue has unknown header")
+	}
+
+	return nil
+}
+
+// WrapLDDocumentLoader wraps the JSON-LD document loader of the Suite, implements
+// suite.LDDocumentLoaderWrapper.
+func (s *Suite) WrapLDDocumentLoader(wrap func(loader ld.DocumentLoader) ld.DocumentLoader) {
+	if s.ldLoader != nil {
+		s.ldLoader = wrap(s.ldLoader)
+	}
+}
+
+// RequiresCreated returns false, as the bbs-2023 cryptographic suite does not
+// require the use of the models.Proof.Created field.
+func (s *Suite) RequiresCreated() bool {
+	return false
+}
+
+func (s *Suite) transform(doc []byte, opts *models.ProofOptions) (map[string]interface{}, []byte, error) {
+	if opts.SuiteType == "" {
+		opts.SuiteType = SuiteType
+	}
+
+	if opts.ProofType != models.DataIntegrityProof || opts.SuiteType != SuiteType {
+		return nil, nil, suite.ErrProofTransformation
+	}
+
+	docData := make(map[string]interface{})
+
+	err := json.Unmarshal(doc, &docData)
+	if err != nil {
+		return nil, nil, fmt.Errorf("bbs-2023 suite expects JSON-LD payload: %w", err)
+	}
+
+	publicKey, err := verificationMethodKey(opts.VerificationMethod)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return docData, publicKey, nil
+}
+
+// splitDisclosedStatements canonicalizes the disclosed document with the label map of the
+// derived proof and splits the statements into mandatory and non-mandatory ones.
+func (s *Suite) splitDisclosedStatements(
+	docData map[string]interface{},
+	derived *derivedProofValue,
+) ([]string, []string, error) {
+	labelMap := make(map[int]string, len(derived.LabelMap))
+
+	for index, label := range derived.LabelMap {
+		labelMap[index] = shuffledLabelPrefix + strconv.Itoa(label)
+	}
+
+	statements, err := s.processor().CanonicalizeWithLabelMap(docData, labelMap)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	mandatoryIndexes := selective.IndexSet(derived.MandatoryIndexes)
+
+	var mandatory, nonMandatory []string
+
+	for i, statement := range statements {
+		if mandatoryIndexes[i] {
+			mandatory = append(mandatory, statement)
+		} else {
+			nonMandatory = append(nonMandatory, statement)
+		}
+	}
+
+	return mandatory, nonMandatory, nil
+}
+
+func (s *Suite) processor() *selective.Processor {
+	return selective.NewProcessor(s.ldLoader)
+}
+
+func (s *Suite) proofHash(docCtx interface{}, opts *models.ProofOptions) ([]byte, error) {
+	canonConf, err := s.processor().ToNQuads(proofConfig(docCtx, opts))
+	if err != nil {
+		return nil, err
+	}
+
+	canonical, _, err := selective.CanonicalizeNQuads(canonConf)
+	if err != nil {
+		return nil, err
+	}
+
+	return hashStatements(canonical), nil
+}
+
+func proofConfig(docCtx interface{}, opts *models.ProofOptions) map[string]interface{} {
+	proof := map[string]interface{}{
+		"type":               models.DataIntegrityProof,
+		"cryptosuite":        opts.SuiteType,
+		"verificationMethod": opts.VerificationMethodID,
+		"proofPurpose":       opts.Purpose,
+	}
+
+	if ctx := suite.ProofConfigContext(docCtx, opts.LenientProofContext); ctx != nil {
+		proof[ldCtxKey] = ctx
+	}
+
+	if !opts.Created.IsZero() {
+		proof["created"] = opts.Created.Format(models.DateTimeFormat)
+	}
+
+	if !opts.Expires.IsZero() {
+		proof["expires"] = opts.Expires.Format(models.DateTimeFormat)
+	}
+
+	if opts.Challenge != "" {
+		proof["challenge"] = opts.Challenge
+	}
+
+	if opts.Nonce != "" {
+		proof["nonce"] = opts.Nonce
+	}
+
+	if opts.Domain != "" {
+		proof["domain"] = opts.Domain
+	}
+
+	if opts.ProofID != "" {
+		proof["id"] = opts.ProofID
+	}
+
+	if opts.PreviousProof != "" {
+		proof["previousProof"] = opts.PreviousProof
+	}
+
+	for name, value := range opts.CustomFields {
+		if _, ok := proof[name]; !ok {
+			proof[name] = value
+		}
+	}
+
+	return proof
+}
+
+// shuffledLabels returns the selective.LabelFunc replacing the canonical blank node labels with
+// "b" and the index of their HMAC in the sorted HMACs, so that the labels don't leak the structure
+// of the undisclosed statements, while keeping the label map of the derived proofs small.
+func shuffledLabels(hmacKey []byte) selective.LabelFunc {
+	return func(canonicalLabels []string) (map[string]string, error) {
+		hmacLabels := make([]string, 0, len(canonicalLabels))
+		canonicalByHMAC := make(map[string]string, len(canonicalLabels))
+
+		for _, canonicalLabel := range canonicalLabels {
+			mac := hmac.New(sha256.New, hmacKey)
+			mac.Write([]byte(canonicalLabel))
+
+			hmacLabel := hmacLabelPrefix + base64.RawURLEncoding.EncodeToString(mac.Sum(nil))
+
+			hmacLabels = append(hmacLabels, hmacLabel)
+			canonicalByHMAC[hmacLabel] = canonicalLabel
+		}
+
+		sort.Strings(hmacLabels)
+
+		labels := make(map[string]string, len(canonicalLabels))
+
+		for i, hmacLabel := range hmacLabels {
+			labels[canonicalByHMAC[hmacLabel]] = shuffledLabelPrefix + strconv.Itoa(i)
+		}
+
+		return labels, nil
+	}
+}
+
+func statementMessages(statements []string) [][]byte {
+	messages := make([][]byte, 0, len(statements))
+
+	for _, statement := range statements {
+		messages = append(messages, []byte(statement))
+	}
+
+	return messages
+}
+
+func hashStatements(statements []string) []byte {
+	h := sha256.New()
+
+	for _, statement := range statements {
+		h.Write([]byte(statement))
+	}
+
+	return h.Sum(nil)
+}
+
+// blsPublicKey is implemented by the BLS12-381 G2 public keys of JWKs.
+type blsPublicKey interface {
+	Marshal() ([]byte, error)
+}
+
+func verificationMethodKey(vm *models.VerificationMethod) ([]byte, error) {
+	if vm == nil {
+		return nil, errors.New("verification method is required")
+	}
+
+	publicKey := vm.Value
+
+	if key := vm.JSONWebKey(); key != nil {
+		blsKey, ok := key.Key.(blsPublicKey)
+		if !ok || key.Crv != "BLS12381_G2" {
+			return nil, fmt.Errorf("unsupported BBS curve. %v", key.Crv)
+		}
+
+		marshaled, err := blsKey.Marshal()
+		if err != nil {
+			return nil, err
+		}
+
+		publicKey = marshaled
+	} else if bytes.HasPrefix(publicKey, multikeyBLSHeader) && len(publicKey) == len(multikeyBLSHeader)+PublicKeySize {
+		publicKey = publicKey[len(multikeyBLSHeader):]
+	}
+
+	if _, err := parsePublicKey(publicKey); err != nil {
+		return nil, errors.New("verification method needs JWK or BLS12-381 G2 Multikey")
+	}
+
+	return publicKey, nil
+}
+
+func encodeProofValue(header []byte, value interface{}) (string, error) {
+	encMode, err := cbor.CoreDetEncOptions().EncMode()
+	if err != nil {
+		return "", err
+	}
+
+	data, err := encMode.Marshal(value)
+	if err != nil {
+		return "", fmt.Errorf("encoding bbs-2023 proofValue: %w", err)
+	}
+
+	return multibase.Encode(multibase.Base64url, append(append([]byte{}, header...), data...))
+}
+
+func parseBaseProofValue(proofValue string) (*baseProofValue, error) {
+	_, data, err := multibase.Decode(proofValue)
+	if err != nil {
+		return nil, fmt.Errorf("decoding proofValue: %w", err)
+	}
+
+	if !bytes.HasPrefix(data, baseProofHeader) {
+		return nil, errors.New("bbs-2023 proofValue is not a base proof")
+	}
+
+	value := &baseProofValue{}
+
+	err = cbor.Unmarshal(data[len(baseProofHeader):], value)
+	if err != nil {
+		return nil, fmt.Errorf("decoding bbs-2023 base proofValue: %w", err)
+	}
+
+	return value, nil
+}
+
+func parseDerivedProofValue(data []byte) (*derivedProofValue, error) {
+	value := &derivedProofValue{}
+
+	err := cbor.Unmarshal(data[len(derivedProofHeader):], value)
+	if err != nil {
+		return nil, fmt.Errorf("decoding bbs-2023 derived proofValue: %w", err)
+	}
+
+	return value, nil
+}
@@ -0,0 +1,297 @@
+/*
+Copyright Gen Digital Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package bbs2023
+
+import (
+	_ "embed"
+	"encoding/base64"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"github.com/tidwall/sjson"
+	"github.com/trustbloc/did-go/doc/did"
+	"github.com/trustbloc/did-go/doc/ld/documentloader"
+	mockldstore "github.com/trustbloc/did-go/doc/ld/mock"
+	"github.com/trustbloc/did-go/doc/ld/store"
+	"github.com/trustbloc/kms-go/doc/jose/jwk"
+
+	"github.com/trustbloc/vc-go/dataintegrity/models"
+	"github.com/trustbloc/vc-go/dataintegrity/suite"
+)
+
+//go:embed testdata/valid_credential.jsonld
+var validCredential []byte
+
+func TestNew(t *testing.T) {
+	sigInit := NewSignerInitializer(&SignerInitializerOptions{})
+
+	signer, err := sigInit.Signer()
+	require.NoError(t, err)
+	require.False(t, signer.RequiresCreated())
+	require.EqualValues(t, []string{"bbs-2023"}, sigInit.Type())
+
+	verInit := NewVerifierInitializer(&VerifierInitializerOptions{})
+
+	verifier, err := verInit.Verifier()
+	require.NoError(t, err)
+	require.NotNil(t, verifier)
+
+	derInit := NewDeriverInitializer(&DeriverInitializerOptions{})
+
+	deriver, err := derInit.Deriver()
+	require.NoError(t, err)
+	require.NotNil(t, deriver)
+	require.EqualValues(t, []string{"bbs-2023"}, derInit.Type())
+}
+
+func TestIntegration(t *testing.T) {
+	docLoader, err := documentloader.NewDocumentLoader(createMockProvider())
+	require.NoError(t, err)
+
+	key, err := GenerateKey(nil)
+	require.NoError(t, err)
+
+	signer, err := NewSignerInitializer(&SignerInitializerOptions{
+		LDDocumentLoader: docLoader,
+		SignerGetter:     WithStaticSigner(key),
+	}).Signer()
+	require.NoError(t, err)
+
+	verifier, err := NewVerifierInitializer(&VerifierInitializerOptions{
+		LDDocumentLoader: docLoader,
+	}).Verifier()
+	require.NoError(t, err)
+
+	deriver, err := NewDeriverInitializer(&DeriverInitializerOptions{
+		LDDocumentLoader: docLoader,
+	}).Deriver()
+	require.NoError(t, err)
+
+	blsVM := did.NewVerificationMethodFromBytes("#key-1", "Multikey", "did:foo:bar",
+		append(append([]byte{}, multikeyBLSHeader...), key.PublicKey()...))
+
+	// The proofs are verified with the created time they are signed with, as the Verifier does.
+	created := time.Now()
+
+	proofOpts := func() *models.ProofOptions {
+		return &models.ProofOptions{
+			VerificationMethod:   blsVM,
+			VerificationMethodID: blsVM.ID,
+			SuiteType:            SuiteType,
+			Purpose:              "assertionMethod",
+			ProofType:            models.DataIntegrityProof,
+			Created:              created,
+			MandatoryPointers:    []string{"/issuer", "/validFrom"},
+		}
+	}
+
+	opts := proofOpts()
+
+	baseProof, err := signer.CreateProof(validCredential, opts)
+	require.NoError(t, err)
+	require.Equal(t, SuiteType, baseProof.CryptoSuite)
+	require.Equal(t, "u2V0C", baseProof.ProofValue[:5])
+
+	t.Run("success", func(t *testing.T) {
+		t.Run("base proof", func(t *testing.T) {
+			require.NoError(t, verifier.VerifyProof(validCredential, baseProof, opts))
+		})
+
+		t.Run("derived proof", func(t *testing.T) {
+			pointers := []string{"/credentialSubject/degree/name", "/credentialSubject/alumniOf/1"}
+
+			revealed, derivedProof, err := deriver.DeriveProof(validCredential, baseProof, pointers)
+			require.NoError(t, err)
+			require.Equal(t, "u2V0D", derivedProof.ProofValue[:5])
+			require.Equal(t, baseProof.Created, derivedProof.Created)
+
+			var revealedDoc map[string]interface{}
+
+			require.NoError(t, json.Unmarshal(revealed, &revealedDoc))
+			require.Equal(t, "did:example:76e12ec712ebc6f1c221ebfeb1f", revealedDoc["issuer"])
+
+			subject, ok := revealedDoc["credentialSubject"].(map[string]interface{})
+			require.True(t, ok)
+			require.NotContains(t, subject, "name")
+			require.NotContains(t, subject, "birthDate")
+			require.Equal(t, []interface{}{"Another University"}, subject["alumniOf"])
+			require.Equal(t, map[string]interface{}{
+				"type": "ExampleBachelorDegree",
+				"name": "Bachelor of Science and Arts",
+			}, subject["degree"])
+
+			require.NoError(t, verifier.VerifyProof(revealed, derivedProof, proofOpts()))
+
+			// The derived proofs of the same disclosure are unlinkable.
+			otherRevealed, otherProof, err := deriver.DeriveProof(validCredential, baseProof, pointers)
+			require.NoError(t, err)
+			require.Equal(t, revealed, otherRevealed)
+			require.NotEqual(t, derivedProof.ProofValue, otherProof.ProofValue)
+
+			require.NoError(t, verifier.VerifyProof(otherRevealed, otherProof, proofOpts()))
+		})
+
+		t.Run("derived proof with mandatory statements only", func(t *testing.T) {
+			revealed, derivedProof, err := deriver.DeriveProof(validCredential, baseProof, nil)
+			require.NoError(t, err)
+			require.NotContains(t, string(revealed), "credentialSubject")
+
+			require.NoError(t, verifier.VerifyProof(revealed, derivedProof, proofOpts()))
+		})
+
+		t.Run("JWK verification method", func(t *testing.T) {
+			jwkJSON, err := json.Marshal(map[string]string{
+				"kty": "EC",
+				"crv": "BLS12381_G2",
+				"x":   base64.RawURLEncoding.EncodeToString(key.PublicKey()),
+			})
+			require.NoError(t, err)
+
+			blsJWK := &jwk.JWK{}
+			require.NoError(t, blsJWK.UnmarshalJSON(jwkJSON))
+
+			jwkVM, err := did.NewVerificationMethodFromJWK("#key-1", "JsonWebKey2020", "did:foo:bar", blsJWK)
+			require.NoError(t, err)
+
+			jwkOpts := proofOpts()
+			jwkOpts.VerificationMethod = jwkVM
+
+			revealed, derivedProof, err := deriver.DeriveProof(validCredential, baseProof, nil)
+			require.NoError(t, err)
+
+			require.NoError(t, verifier.VerifyProof(revealed, derivedProof, jwkOpts))
+		})
+	})
+
+	t.Run("failure", func(t *testing.T) {
+		t.Run("modified disclosed statement", func(t *testing.T) {
+			revealed, derivedProof, err := deriver.DeriveProof(validCredential, baseProof,
+				[]string{"/credentialSubject/degree/name"})
+			require.NoError(t, err)
+
+			revealed, err = sjson.SetBytes(revealed, "credentialSubject.degree.name", "Doctor of Arts")
+			require.NoError(t, err)
+
+			err = verifier.VerifyProof(revealed, derivedProof, proofOpts())
+			require.ErrorContains(t, err, "failed to verify bbs-2023 DI derived proof")
+		})
+
+		t.Run("modified mandatory statement", func(t *testing.T) {
+			revealed, derivedProof, err := deriver.DeriveProof(validCredential, baseProof, nil)
+			require.NoError(t, err)
+
+			revealed, err = sjson.SetBytes(revealed, "validFrom", "2011-01-01T19:23:24Z")
+			require.NoError(t, err)
+
+			err = verifier.VerifyProof(revealed, derivedProof, proofOpts())
+			require.ErrorContains(t, err, "failed to verify bbs-2023 DI derived proof")
+		})
+
+		t.Run("undisclosed statement", func(t *testing.T) {
+			revealed, derivedProof, err := deriver.DeriveProof(validCredential, baseProof,
+				[]string{"/credentialSubject/degree/name"})
+			require.NoError(t, err)
+
+			revealed, err = sjson.SetBytes(revealed, "credentialSubject.birthDate", "2000-01-01")
+			require.NoError(t, err)
+
+			require.Error(t, verifier.VerifyProof(revealed, derivedProof, proofOpts()))
+		})
+
+		t.Run("modified base proof document", func(t *testing.T) {
+			modified, err := sjson.SetBytes(validCredential, "credentialSubject.name", "John Doe")
+			require.NoError(t, err)
+
+			err = verifier.VerifyProof(modified, baseProof, opts)
+			require.ErrorContains(t, err, "failed to verify bbs-2023 DI base proof")
+		})
+
+		t.Run("other issuer key", func(t *testing.T) {
+			otherKey, err := GenerateKey(nil)
+			require.NoError(t, err)
+
+			otherOpts := proofOpts()
+			otherOpts.VerificationMethod = did.NewVerificationMethodFromBytes("#key-1", "Multikey", "did:foo:bar",
+				otherKey.PublicKey())
+
+			require.Error(t, verifier.VerifyProof(validCredential, baseProof, otherOpts))
+
+			revealed, derivedProof, err := deriver.DeriveProof(validCredential, baseProof, nil)
+			require.NoError(t, err)
+
+			require.Error(t, verifier.VerifyProof(revealed, derivedProof, otherOpts))
+		})
+
+		t.Run("pointer does not match document", func(t *testing.T) {
+			_, _, err := deriver.DeriveProof(validCredential, baseProof, []string{"/credentialSubject/foo"})
+			require.ErrorContains(t, err, "does not match document")
+		})
+
+		t.Run("derive from derived proof", func(t *testing.T) {
+			revealed, derivedProof, err := deriver.DeriveProof(validCredential, baseProof, nil)
+			require.NoError(t, err)
+
+			_, _, err = deriver.DeriveProof(revealed, derivedProof, nil)
+			require.ErrorContains(t, err, "not a base proof")
+		})
+
+		t.Run("wrong suite type", func(t *testing.T) {
+			badOpts := proofOpts()
+			badOpts.SuiteType = "ecdsa-sd-2023"
+
+			_, err := signer.CreateProof(validCredential, badOpts)
+			require.ErrorIs(t, err, suite.ErrProofTransformation)
+
+			err = verifier.VerifyProof(validCredential, baseProof, badOpts)
+			require.ErrorIs(t, err, suite.ErrProofTransformation)
+		})
+
+		t.Run("invalid verification method", func(t *testing.T) {
+			badOpts := proofOpts()
+			badOpts.VerificationMethod = did.NewVerificationMethodFromBytes("#key-1", "Multikey", "did:foo:bar",
+				[]byte("invalid"))
+
+			_, err := signer.CreateProof(validCredential, badOpts)
+			require.ErrorContains(t, err, "verification method needs JWK or BLS12-381 G2 Multikey")
+
+			badOpts.VerificationMethod = nil
+
+			err = verifier.VerifyProof(validCredential, baseProof, badOpts)
+			require.ErrorContains(t, err, "verification method is required")
+		})
+
+		t.Run("unknown proofValue header", func(t *testing.T) {
+			badProof := *baseProof
+			badProof.ProofValue = "uAAAA"
+
+			err := verifier.VerifyProof(validCredential, &badProof, opts)
+			require.ErrorContains(t, err, "unknown header")
+		})
+	})
+}
+
+type provider struct {
+	ContextStore        store.ContextStore
+	RemoteProviderStore store.RemoteProviderStore
+}
+
+func (p *provider) JSONLDContextStore() store.ContextStore {
+	return p.ContextStore
+}
+
+func (p *provider) JSONLDRemoteProviderStore() store.RemoteProviderStore {
+	return p.RemoteProviderStore
+}
+
+func createMockProvider() *provider {
+	return &provider{
+		ContextStore:        mockldstore.NewMockContextStore(),
+		RemoteProviderStore: mockldstore.NewMockRemoteProviderStore(),
+	}
+}
@@ -0,0 +1,200 @@
+/*
+Copyright Gen Digital Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package bbs2023
+
+import (
+	"bytes"
+	"encoding/hex"
+	"testing"
+
+	"github.com/consensys/gnark-crypto/field/hash"
+	"github.com/stretchr/testify/require"
+)
+
+// The fixtures of the BLS12-381-SHA-256 ciphersuite of the BBS signature scheme draft.
+const (
+	fixtureKeyMaterial = "746869732d49532d6a7573742d616e2d546573742d494b4d2d746f2d67656e65726174652d2465287240742" +
+		"32d6b6579"
+	fixtureKeyInfo = "746869732d49532d736f6d652d6b65792d6d657461646174612d746f2d62652d757365642d696e2d746573742d6b" +
+		"65792d67656e"
+	fixturePrivateKey = "60e55110f76883a13d030b2f6bd11883422d5abde717569fc0731f51237169fc"
+	fixturePublicKey  = "a820f230f6ae38503b86c70dc50b61c58a77e45c39ab25c0652bbaa8fa136f2851bd4781c9dcde39fc9d1d52c9e60" +
+		"268061e7d7632171d91aa8d460acee0e96f1e7c4cfb12d3ff9ab5d5dc91c277db75c845d649ef3c4f63aebc364cd55ded0c"
+	fixtureBasePoint = "a8ce256102840821a3e94ea9025e4662b205762f9776b3a766c872b948f1fd225e7c59698588e70d11406d161b4e" +
+		"28c9"
+	fixtureHeader  = "11223344556677889900aabbccddeeff"
+	fixtureMessage = "9872ad089e452c7b6e283dfac2a80d58e8d0ff71cc4d5e310a1debdda4a45f02"
+
+	fixtureSignature = "84773160b824e194073a57493dac1a20b667af70cd2352d8af241c77658da5253aa8458317cca0eae615690d55b" +
+		"1f27164657dcafee1d5c1973947aa70e2cfbb4c892340be5969920d0916067b4565a0"
+
+	fixturePresentationHeader = "bed231d880675ed101ead304512e043ade9958dd0241ea70b4b3957fba941501"
+	fixtureRandomSeed         = "332e313431353932363533353839373933323338343632363433333833323739"
+	fixtureProof              = "94916292a7a6bade28456c601d3af33fcf39278d6594b467e128a3f83686a104ef2b2fcf72df0215eeaf" +
+		"69262ffe8194a19fab31a82ddbe06908985abc4c9825788b8a1610942d12b7f5debbea8985296361206dbace7af0cc834c80f33e0aa" +
+		"daeea5597befbb651827b5eed5a66f1a959bb46cfd5ca1a817a14475960f69b32c54db7587b5ee3ab665fbd37b506830a49f21d59" +
+		"2f5e634f47cee05a025a2f8f94e73a6c15f02301d1178a92873b6e8634bafe4983c3e15a663d64080678dbf29417519b78af042be2" +
+		"b3e1c4d08b8d520ffab008cbaaca5671a15b22c239b38e940cfeaa5e72104576a9ec4a6fad78c532381aeaa6fb56409cef56ee5c14" +
+		"0d455feeb04426193c57086c9b6d397d9418"
+)
+
+func TestDeriveKey(t *testing.T) {
+	t.Run("fixture", func(t *testing.T) {
+		key, err := DeriveKey(fromHex(t, fixtureKeyMaterial), fromHex(t, fixtureKeyInfo))
+		require.NoError(t, err)
+		require.Equal(t, fixturePrivateKey, hex.EncodeToString(key.Bytes()))
+		require.Equal(t, fixturePublicKey, hex.EncodeToString(key.PublicKey()))
+
+		parsed, err := ParsePrivateKey(key.Bytes())
+		require.NoError(t, err)
+		require.Equal(t, key.PublicKey(), parsed.PublicKey())
+	})
+
+	t.Run("generate", func(t *testing.T) {
+		key, err := GenerateKey(nil)
+		require.NoError(t, err)
+		require.Len(t, key.Bytes(), PrivateKeySize)
+		require.Len(t, key.PublicKey(), PublicKeySize)
+	})
+
+	t.Run("failure", func(t *testing.T) {
+		_, err := DeriveKey([]byte("too short"), nil)
+		require.ErrorContains(t, err, "BBS key material should be at least 32 bytes")
+
+		_, err = GenerateKey(bytes.NewReader(nil))
+		require.Error(t, err)
+
+		_, err = ParsePrivateKey([]byte("invalid"))
+		require.ErrorContains(t, err, "invalid BBS private key")
+	})
+}
+
+func TestSign(t *testing.T) {
+	key, err := DeriveKey(fromHex(t, fixtureKeyMaterial), fromHex(t, fixtureKeyInfo))
+	require.NoError(t, err)
+
+	header := fromHex(t, fixtureHeader)
+
+	t.Run("fixture", func(t *testing.T) {
+		basePoint, err := basePoint()
+		require.NoError(t, err)
+
+		p1 := basePoint.Bytes()
+		require.Equal(t, fixtureBasePoint, hex.EncodeToString(p1[:]))
+
+		messages := [][]byte{fromHex(t, fixtureMessage)}
+
+		signature, err := key.Sign(header, messages)
+		require.NoError(t, err)
+		require.Equal(t, fixtureSignature, hex.EncodeToString(signature))
+
+		require.NoError(t, verifySignature(key.PublicKey(), signature, header, messages))
+	})
+
+	t.Run("failure", func(t *testing.T) {
+		messages := [][]byte{[]byte("a"), []byte("b"), []byte("c"), {}}
+
+		signature, err := key.Sign(header, messages)
+		require.NoError(t, err)
+		require.NoError(t, verifySignature(key.PublicKey(), signature, header, messages))
+
+		otherKey, err := GenerateKey(nil)
+		require.NoError(t, err)
+
+		require.ErrorIs(t, verifySignature(otherKey.PublicKey(), signature, header, messages), errInvalidBBSSignature)
+		require.ErrorIs(t, verifySignature(key.PublicKey(), signature, nil, messages), errInvalidBBSSignature)
+		require.ErrorIs(t, verifySignature(key.PublicKey(), signature, header, messages[:3]), errInvalidBBSSignature)
+		require.ErrorIs(t, verifySignature(key.PublicKey(), signature, header,
+			[][]byte{[]byte("a"), []byte("c"), []byte("b"), {}}), errInvalidBBSSignature)
+		require.ErrorIs(t, verifySignature(key.PublicKey(), signature[1:], header, messages), errInvalidBBSSignature)
+		require.Error(t, verifySignature([]byte("invalid"), signature, header, messages))
+	})
+}
+
+func TestProof(t *testing.T) {
+	key, err := DeriveKey(fromHex(t, fixtureKeyMaterial), fromHex(t, fixtureKeyInfo))
+	require.NoError(t, err)
+
+	header := fromHex(t, fixtureHeader)
+	ph := fromHex(t, fixturePresentationHeader)
+
+	t.Run("fixture", func(t *testing.T) {
+		// The random scalars of the fixture are mocked with expand_message_xmd from a seed.
+		random, err := hash.ExpandMsgXmd(fromHex(t, fixtureRandomSeed), []byte(apiID+"MOCK_RANDOM_SCALARS_DST_"),
+			5*expandLen)
+		require.NoError(t, err)
+
+		messages := [][]byte{fromHex(t, fixtureMessage)}
+
+		proof, err := proofGen(key.PublicKey(), fromHex(t, fixtureSignature), header, ph, messages, []int{0},
+			bytes.NewReader(random))
+		require.NoError(t, err)
+		require.Equal(t, fixtureProof, hex.EncodeToString(proof))
+
+		require.NoError(t, proofVerify(key.PublicKey(), proof, header, ph, messages, []int{0}))
+	})
+
+	messages := [][]byte{[]byte("a"), []byte("b"), []byte("c"), []byte("d")}
+
+	signature, err := key.Sign(header, messages)
+	require.NoError(t, err)
+
+	t.Run("selective disclosure", func(t *testing.T) {
+		for _, disclosed := range [][]int{nil, {0}, {1, 3}, {0, 1, 2, 3}} {
+			proof, err := proofGen(key.PublicKey(), signature, header, ph, messages, disclosed, nil)
+			require.NoError(t, err)
+
+			disclosedMessages := make([][]byte, 0, len(disclosed))
+
+			for _, i := range disclosed {
+				disclosedMessages = append(disclosedMessages, messages[i])
+			}
+
+			require.NoError(t, proofVerify(key.PublicKey(), proof, header, ph, disclosedMessages, disclosed))
+
+			// The proofs are unlinkable.
+			otherProof, err := proofGen(key.PublicKey(), signature, header, ph, messages, disclosed, nil)
+			require.NoError(t, err)
+			require.NotEqual(t, proof, otherProof)
+		}
+	})
+
+	t.Run("failure", func(t *testing.T) {
+		proof, err := proofGen(key.PublicKey(), signature, header, ph, messages, []int{0, 2}, nil)
+		require.NoError(t, err)
+
+		disclosedMessages := [][]byte{[]byte("a"), []byte("c")}
+
+		require.NoError(t, proofVerify(key.PublicKey(), proof, header, ph, disclosedMessages, []int{0, 2}))
+
+		require.Error(t, proofVerify(key.PublicKey(), proof, header, []byte("other"), disclosedMessages, []int{0, 2}))
+		require.Error(t, proofVerify(key.PublicKey(), proof, nil, ph, disclosedMessages, []int{0, 2}))
+		require.Error(t, proofVerify(key.PublicKey(), proof, header, ph, [][]byte{[]byte("a"), []byte("b")},
+			[]int{0, 2}))
+		require.Error(t, proofVerify(key.PublicKey(), proof, header, ph, disclosedMessages, []int{0, 1}))
+		require.Error(t, proofVerify(key.PublicKey(), proof[1:], header, ph, disclosedMessages, []int{0, 2}))
+		require.Error(t, proofVerify(key.PublicKey(), proof, header, ph, disclosedMessages, []int{2, 0}))
+
+		_, err = proofGen(key.PublicKey(), signature, header, ph, messages, []int{0, 4}, nil)
+		require.Error(t, err)
+
+		_, err = proofGen(key.PublicKey(), signature, header, ph, messages, []int{1, 1}, nil)
+		require.Error(t, err)
+
+		_, err = proofGen(key.PublicKey(), signature[1:], header, ph, messages, []int{0}, nil)
+		require.ErrorIs(t, err, errInvalidBBSSignature)
+	})
+}
+
+func fromHex(t *testing.T, s string) []byte {
+	t.Helper()
+
+	b, err := hex.DecodeString(s)
+	require.NoError(t, err)
+
+	return b
+}
@@ -0,0 +1,201 @@
+/*
+Copyright Gen Digital Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package suite
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+
+	"github.com/piprate/json-gold/ld"
+	"github.com/trustbloc/did-go/doc/ld/processor"
+	"golang.org/x/exp/slices"
+
+	"github.com/trustbloc/vc-go/dataintegrity/models"
+)
+
+// CanonicalizeRDF returns the N-Quads of the JSON-LD document data, canonicalized with the given
+// RDF canonicalization algorithm, URDNA2015 if algorithm is empty.
+//
+// RDFC-1.0 is the W3C standard of URDNA2015: its canonical N-Quads escape the control characters
+// of literals other than \b, \t, \n, \f and \r as \uXXXX, where URDNA2015 keeps them as they are.
+// The documents without such literals have the same canonical form with both algorithms.
+//
+// RDFC-1.0 is emulated: the N-Quads canonicalized with URDNA2015 are escaped, then sorted again.
+// As the blank node labels are computed by URDNA2015 over the unescaped N-Quads, the labels, and so
+// the canonical form, may differ from those of an RDFC-1.0 implementation for the documents with
+// a control character in a literal of a statement that has a blank node.
+func CanonicalizeRDF(
+	data map[string]interface{},
+	algorithm models.CanonicalizationAlgorithm,
+	opts ...processor.Opts,
+) ([]byte, error) {
+	switch algorithm {
+	case "", models.URDNA2015, models.RDFC10:
+	default:
+		return nil, fmt.Errorf("unsupported RDF canonicalization algorithm %q", string(algorithm))
+	}
+
+	out, err := processor.Default().GetCanonicalDocument(data, opts...)
+	if err != nil {
+		return nil, err
+	}
+
+	if algorithm != models.RDFC10 || !strings.ContainsFunc(string(out), isRDFC10EscapedControl) {
+		return out, nil
+	}
+
+	quads := strings.SplitAfter(string(out), "\n")
+
+	for i := range quads {
+		quads[i] = escapeRDFC10Controls(quads[i])
+	}
+
+	// The escaping may change the code point order of the quads.
+	sort.Strings(quads)
+
+	return []byte(strings.Join(quads, "")), nil
+}
+
+// CanonicalizeRDFTo writes to w the N-Quads returned by CanonicalizeRDF for data, loading the JSON-LD
+// contexts with loader and hashing the blank nodes with mda, SHA-256 if empty.
+//
+// The canonical N-Quads are not streamed: the blank node labeling of URDNA2015 needs the whole RDF dataset,
+// and the normalization of the JSON-LD processor keeps all the serialized N-Quads as well. CanonicalizeRDFTo
+// only avoids the copy of joining them into a single document, which the JSON-LD processor grows by string
+// concatenation, reallocating it for every quad joined. Hashing the N-Quads of a document of about 5 MB
+// (BenchmarkCanonicalizeRDF) allocates about 113 MB with CanonicalizeRDFTo, against about 149 GB with
+// CanonicalizeRDF. The N-Quads are collected again when RDFC-1.0 escapes control characters of a literal,
+// as the escaping may change their order.
+func CanonicalizeRDFTo(
+	w io.Writer,
+	data map[string]interface{},
+	algorithm models.CanonicalizationAlgorithm,
+	loader ld.DocumentLoader,
+	mda ld.MessageDigestAlgorithm,
+) error {
+	switch algorithm {
+	case "", models.URDNA2015, models.RDFC10:
+	default:
+		return fmt.Errorf("unsupported RDF canonicalization algorithm %q", string(algorithm))
+	}
+
+	toRDFOpts := ld.NewJsonLdOptions("")
+	toRDFOpts.ProcessingMode = ld.JsonLd_1_1
+	toRDFOpts.DocumentLoader = loader
+
+	datasetObj, err := ld.NewJsonLdProcessor().ToRDF(data, toRDFOpts)
+	if err != nil {
+		return fmt.Errorf("failed to normalize JSON-LD document: %w", err)
+	}
+
+	dataset, ok := datasetObj.(*ld.RDFDataset)
+	if !ok {
+		return errors.New("failed to normalize JSON-LD document, invalid dataset")
+	}
+
+	if mda == "" {
+		mda = ld.MessageDigestAlgorithmSHA256
+	}
+
+	normalization := ld.NewNormalisationAlgorithm(ld.AlgorithmURDNA2015, mda)
+	normalization.Normalize(dataset)
+
+	quads := normalization.Quads()
+
+	if algorithm == models.RDFC10 && slices.ContainsFunc(quads, hasRDFC10EscapedControl) {
+		return writeEscapedNQuads(w, quads)
+	}
+
+	for _, quad := range quads {
+		if err = writeNQuad(w, quad); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func writeNQuad(w io.Writer, quad *ld.Quad) error {
+	graphName := "@default"
+	if quad.Graph != nil {
+		graphName = quad.Graph.GetValue()
+	}
+
+	serializer := &ld.NQuadRDFSerializer{}
+
+	return serializer.SerializeTo(w, &ld.RDFDataset{Graphs: map[string][]*ld.Quad{graphName: {quad}}})
+}
+
+func writeEscapedNQuads(w io.Writer, quads []*ld.Quad) error {
+	lines := make([]string, len(quads))
+
+	for i, quad := range quads {
+		var line bytes.Buffer
+
+		if err := writeNQuad(&line, quad); err != nil {
+			return err
+		}
+
+		lines[i] = escapeRDFC10Controls(line.String())
+	}
+
+	// The escaping may change the code point order of the quads.
+	sort.Strings(lines)
+
+	for _, line := range lines {
+		if _, err := io.WriteString(w, line); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func hasRDFC10EscapedControl(quad *ld.Quad) bool {
+	for _, node := range []ld.Node{quad.Subject, quad.Predicate, quad.Object, quad.Graph} {
+		if node == nil {
+			continue
+		}
+
+		if strings.ContainsFunc(node.GetValue(), isRDFC10EscapedControl) {
+			return true
+		}
+
+		if literal, ok := node.(*ld.Literal); ok &&
+			strings.ContainsFunc(literal.Datatype+literal.Language, isRDFC10EscapedControl) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// isRDFC10EscapedControl reports whether r is a control character that canonical N-Quads
+// represent with a UCHAR: https://www.w3.org/TR/rdf12-n-quads/#canonical-quads
+func isRDFC10EscapedControl(r rune) bool {
+	return r <= 0x07 || r == 0x0B || (r >= 0x0E && r <= 0x1F) || r == 0x7F
+}
+
+func escapeRDFC10Controls(quad string) string {
+	var sb strings.Builder
+
+	for _, r := range quad {
+		if isRDFC10EscapedControl(r) {
+			fmt.Fprintf(&sb, `\u%04X`, r)
+
+			continue
+		}
+
+		sb.WriteRune(r)
+	}
+
+	return sb.String()
+}
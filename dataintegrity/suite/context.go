@@ -0,0 +1,53 @@
+/*
+Copyright Gen Digital Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package suite
+
+import "slices"
+
+// DataIntegrityContextV2 is the JSON-LD context defining the terms of the Data Integrity proofs.
+const DataIntegrityContextV2 = "https://w3id.org/security/data-integrity/v2"
+
+// proofTermsContexts are the contexts defining the terms of the Data Integrity proofs.
+var proofTermsContexts = []string{ //nolint:gochecknoglobals
+	"https://w3id.org/security/data-integrity/v1",
+	DataIntegrityContextV2,
+	"https://www.w3.org/ns/credentials/v2",
+}
+
+// ProofConfigContext returns the @context of the proof configuration of a document with the context
+// docCtx. It is docCtx, unless lenient is set and docCtx doesn't define the proof terms: then the
+// Data Integrity v2 context is appended, so that the canonicalization doesn't drop the proof terms.
+// The terms are defined by the W3C contexts defining them, or by an embedded context defining
+// DataIntegrityProof.
+func ProofConfigContext(docCtx interface{}, lenient bool) interface{} {
+	if !lenient || docCtx == nil || definesProofTerms(docCtx) {
+		return docCtx
+	}
+
+	if contexts, ok := docCtx.([]interface{}); ok {
+		return append(slices.Clone(contexts), DataIntegrityContextV2)
+	}
+
+	return []interface{}{docCtx, DataIntegrityContextV2}
+}
+
+func definesProofTerms(ctx interface{}) bool {
+	switch c := ctx.(type) {
+	case string:
+		return slices.Contains(proofTermsContexts, c)
+	case map[string]interface{}:
+		_, ok := c["DataIntegrityProof"]
+
+		return ok
+	case []interface{}:
+		return slices.ContainsFunc(c, definesProofTerms)
+	case []string:
+		return slices.ContainsFunc(c, func(s string) bool { return slices.Contains(proofTermsContexts, s) })
+	}
+
+	return false
+}
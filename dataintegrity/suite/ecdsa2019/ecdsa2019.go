@@ -7,6 +7,7 @@ SPDX-License-Identifier: Apache-2.0
 package ecdsa2019
 
 import (
+	"context"
 	"crypto/elliptic"
 	"crypto/sha256"
 	"crypto/sha512"
@@ -14,11 +15,13 @@ import (
 	"errors"
 	"fmt"
 	"hash"
+	"io"
 	"reflect"
 
+	"github.com/btcsuite/btcd/btcec/v2"
 	"github.com/multiformats/go-multibase"
 	"github.com/piprate/json-gold/ld"
-	"github.com/trustbloc/did-go/doc/ld/processor"
+	"github.com/trustbloc/did-go/pkg/canonicalizer"
 	"github.com/trustbloc/kms-go/doc/jose/jwk"
 	"github.com/trustbloc/kms-go/spi/kms"
 	wrapperapi "github.com/trustbloc/kms-go/wrapper/api"
@@ -37,6 +40,11 @@ const (
 
 	// SuiteTypeNew "ecdsa-rdfc-2019" is the data integrity Type identifier for the suite.
 	SuiteTypeNew = "ecdsa-rdfc-2019"
+
+	// SuiteTypeJCS "ecdsa-jcs-2019" is the data integrity Type identifier for the suite
+	// implementing ecdsa signatures with JSON Canonicalization Scheme (RFC 8785) as per this
+	// spec:https://www.w3.org/TR/vc-di-ecdsa/#ecdsa-jcs-2019
+	SuiteTypeJCS = "ecdsa-jcs-2019"
 )
 
 // SignerGetter returns a Signer, which must sign with the private key matching
@@ -62,6 +70,15 @@ func WithKMSCryptoWrapper(kmsCrypto wrapperapi.KMSCryptoSigner) SignerGetter {
 	}
 }
 
+// WithKeySigner provides a SignerGetter signing with the keys of a suite.KeySigner, eg of a cloud
+// KMS or an HSM, see suite.NewFixedKeySigner: the key of the KeySigner is identified by the key ID
+// of the public key JWK, and must be of its type.
+func WithKeySigner(signer suite.KeySigner) SignerGetter {
+	return func(pub *jwk.JWK) (Signer, error) {
+		return suite.NewFixedKeySigner(signer, pub)
+	}
+}
+
 // A KMSSigner is able to sign messages.
 type KMSSigner interface { // TODO note: only used by deprecated function
 	// Sign will sign msg using a matching signature primitive in kh key handle of a private key
@@ -89,20 +106,30 @@ type Verifier interface {
 	Verify(signature, msg []byte, pubKey *pubkey.PublicKey) error
 }
 
-// Suite implements the ecdsa-2019 data integrity cryptographic suite.
+// Suite implements the ecdsa-2019 data integrity cryptographic suite, both in RDF
+// canonicalization (ecdsa-rdfc-2019) and JCS (ecdsa-jcs-2019) variants.
+//
+// The specification defines the P-256 and P-384 curves, hashed with SHA-256 and SHA-384. As extensions,
+// the Suite also signs and verifies with P-521 keys, hashing with SHA-512, and with secp256k1 keys,
+// hashing with SHA-256 as for P-256, for the ecosystems using secp256k1 keys. The specification has
+// no test vectors for these curves, so their proofs are only tested against this implementation.
 type Suite struct {
-	ldLoader     ld.DocumentLoader
-	p256Verifier Verifier
-	p384Verifier Verifier
-	signerGetter SignerGetter
+	ldLoader          ld.DocumentLoader
+	p256Verifier      Verifier
+	p384Verifier      Verifier
+	p521Verifier      Verifier
+	secp256k1Verifier Verifier
+	signerGetter      SignerGetter
 }
 
 // Options provides initialization options for Suite.
 type Options struct {
-	LDDocumentLoader ld.DocumentLoader
-	P256Verifier     Verifier
-	P384Verifier     Verifier
-	SignerGetter     SignerGetter
+	LDDocumentLoader  ld.DocumentLoader
+	P256Verifier      Verifier
+	P384Verifier      Verifier
+	P521Verifier      Verifier
+	Secp256k1Verifier Verifier
+	SignerGetter      SignerGetter
 }
 
 // SuiteInitializer is the initializer for Suite.
@@ -112,10 +139,12 @@ type SuiteInitializer func() (suite.Suite, error)
 func New(options *Options) SuiteInitializer {
 	return func() (suite.Suite, error) {
 		return &Suite{
-			ldLoader:     options.LDDocumentLoader,
-			p256Verifier: options.P256Verifier,
-			p384Verifier: options.P384Verifier,
-			signerGetter: options.SignerGetter,
+			ldLoader:          options.LDDocumentLoader,
+			p256Verifier:      options.P256Verifier,
+			p384Verifier:      options.P384Verifier,
+			p521Verifier:      options.P521Verifier,
+			secp256k1Verifier: options.Secp256k1Verifier,
+			signerGetter:      options.SignerGetter,
 		}, nil
 	}
 }
@@ -135,7 +164,7 @@ func (i initializer) Verifier() (suite.Verifier, error) {
 // Type private, implements suite.SignerInitializer and
 // suite.VerifierInitializer.
 func (i initializer) Type() []string {
-	return []string{SuiteType, SuiteTypeNew}
+	return []string{SuiteType, SuiteTypeNew, SuiteTypeJCS}
 }
 
 // SignerInitializerOptions provides options for a SignerInitializer.
@@ -155,15 +184,17 @@ func NewSignerInitializer(options *SignerInitializerOptions) suite.SignerInitial
 
 // VerifierInitializerOptions provides options for a VerifierInitializer.
 type VerifierInitializerOptions struct {
-	LDDocumentLoader ld.DocumentLoader // required
-	P256Verifier     Verifier          // optional
-	P384Verifier     Verifier          // optional
+	LDDocumentLoader  ld.DocumentLoader // required
+	P256Verifier      Verifier          // optional
+	P384Verifier      Verifier          // optional
+	P521Verifier      Verifier          // optional
+	Secp256k1Verifier Verifier          // optional
 }
 
 // NewVerifierInitializer returns a suite.VerifierInitializer that initializes an
 // ecdsa-2019 verification Suite with the given VerifierInitializerOptions.
 func NewVerifierInitializer(options *VerifierInitializerOptions) suite.VerifierInitializer {
-	p256Verifier, p384Verifier := options.P256Verifier, options.P384Verifier
+	p256Verifier, p384Verifier, p521Verifier := options.P256Verifier, options.P384Verifier, options.P521Verifier
 
 	if p256Verifier == nil {
 		p256Verifier = ecdsa.NewES256()
@@ -173,10 +204,21 @@ func NewVerifierInitializer(options *VerifierInitializerOptions) suite.VerifierI
 		p384Verifier = ecdsa.NewES384()
 	}
 
+	if p521Verifier == nil {
+		p521Verifier = ecdsa.NewES521()
+	}
+
+	secp256k1Verifier := options.Secp256k1Verifier
+	if secp256k1Verifier == nil {
+		secp256k1Verifier = ecdsa.NewSecp256k1()
+	}
+
 	return initializer(New(&Options{
-		LDDocumentLoader: options.LDDocumentLoader,
-		P256Verifier:     p256Verifier,
-		P384Verifier:     p384Verifier,
+		LDDocumentLoader:  options.LDDocumentLoader,
+		P256Verifier:      p256Verifier,
+		P384Verifier:      p384Verifier,
+		P521Verifier:      p521Verifier,
+		Secp256k1Verifier: secp256k1Verifier,
 	}))
 }
 
@@ -187,23 +229,50 @@ const (
 // CreateProof implements the ecdsa-2019 cryptographic suite for Add Proof:
 // https://www.w3.org/TR/vc-di-ecdsa/#add-proof-ecdsa-2019
 func (s *Suite) CreateProof(doc []byte, opts *models.ProofOptions) (*models.Proof, error) {
-	if opts.SuiteType == "" {
-		opts.SuiteType = SuiteType
+	sigBase, vmKey, finalize, err := s.prepareProof(doc, opts)
+	if err != nil {
+		return nil, err
 	}
 
-	docHash, vmKey, _, err := s.transformAndHash(doc, opts)
+	sig, err := sign(sigBase, vmKey.JWK, s.signerGetter)
 	if err != nil {
 		return nil, err
 	}
 
-	sig, err := sign(docHash, vmKey.JWK, s.signerGetter)
+	return finalize(sig)
+}
+
+// PrepareProof performs the transform and hash steps of the ecdsa-2019 Add Proof, for a signature made
+// outside of the Suite, implements suite.ProofPreparer. The returned data is signed with ECDSA, after
+// hashing it with the hash of the key curve, into an IEEE P1363 signature.
+func (s *Suite) PrepareProof(
+	doc []byte,
+	opts *models.ProofOptions,
+) ([]byte, func(sig []byte) (*models.Proof, error), error) {
+	sigBase, _, finalize, err := s.prepareProof(doc, opts)
 	if err != nil {
-		return nil, err
+		return nil, nil, err
+	}
+
+	return sigBase, finalize, nil
+}
+
+func (s *Suite) prepareProof(
+	doc []byte,
+	opts *models.ProofOptions,
+) ([]byte, *pubkey.PublicKey, func(sig []byte) (*models.Proof, error), error) {
+	if opts.SuiteType == "" {
+		opts.SuiteType = SuiteType
 	}
 
-	sigStr, err := multibase.Encode(multibase.Base58BTC, sig)
+	docHash, vmKey, _, err := s.transformAndHash(doc, opts)
 	if err != nil {
-		return nil, err
+		return nil, nil, nil, err
+	}
+
+	encoding, err := opts.ProofValueEncoding.Encoding()
+	if err != nil {
+		return nil, nil, nil, err
 	}
 
 	var expires string
@@ -211,19 +280,51 @@ func (s *Suite) CreateProof(doc []byte, opts *models.ProofOptions) (*models.Proo
 		expires = opts.Expires.Format(models.DateTimeFormat)
 	}
 
-	p := &models.Proof{
+	proof := models.Proof{
 		Type:               models.DataIntegrityProof,
 		CryptoSuite:        opts.SuiteType,
 		ProofPurpose:       opts.Purpose,
 		Domain:             opts.Domain,
 		Challenge:          opts.Challenge,
+		Nonce:              opts.Nonce,
 		VerificationMethod: opts.VerificationMethod.ID,
-		ProofValue:         sigStr,
 		Created:            opts.Created.Format(models.DateTimeFormat),
 		Expires:            expires,
+		ID:                 opts.ProofID,
+		PreviousProof:      opts.PreviousProof,
+		CustomFields:       opts.CustomFields,
+	}
+
+	finalize := func(sig []byte) (*models.Proof, error) {
+		sigStr, e := multibase.Encode(encoding, sig)
+		if e != nil {
+			return nil, e
+		}
+
+		p := proof
+		p.ProofValue = sigStr
+
+		return &p, nil
 	}
 
-	return p, nil
+	return docHash, vmKey, finalize, nil
+}
+
+// signatureAlgorithm returns the hash, verifier, message digest algorithm and key type of the JWS alg
+// selected by the data integrity Verifier, in place of those of the key curve.
+func (s *Suite) signatureAlgorithm(alg string) (hash.Hash, Verifier, ld.MessageDigestAlgorithm, kms.KeyType, error) {
+	switch alg {
+	case "ES256":
+		return sha256.New(), s.p256Verifier, ld.MessageDigestAlgorithmSHA256, kms.ECDSAP256TypeIEEEP1363, nil
+	case "ES384":
+		return sha512.New384(), s.p384Verifier, ld.MessageDigestAlgorithmSHA384, kms.ECDSAP384TypeIEEEP1363, nil
+	case "ES512":
+		return sha512.New(), s.p521Verifier, ld.MessageDigestAlgorithmSHA512, kms.ECDSAP521TypeIEEEP1363, nil
+	case "ES256K":
+		return sha256.New(), s.secp256k1Verifier, ld.MessageDigestAlgorithmSHA256, kms.ECDSASecp256k1TypeIEEEP1363, nil
+	default:
+		return nil, nil, "", "", fmt.Errorf("unsupported ECDSA signature algorithm %q", alg)
+	}
 }
 
 func (s *Suite) unmarshalECKey(ecCRV elliptic.Curve, pubKey []byte) ([]byte, error) {
@@ -242,6 +343,17 @@ func (s *Suite) unmarshalECKey(ecCRV elliptic.Curve, pubKey []byte) ([]byte, err
 	return pubKey, nil
 }
 
+// unmarshalSecp256k1Key returns the uncompressed form of a secp256k1 public key, as the elliptic
+// package only decompresses the points of the NIST curves.
+func unmarshalSecp256k1Key(_ elliptic.Curve, pubKey []byte) ([]byte, error) {
+	key, err := btcec.ParsePubKey(pubKey)
+	if err != nil {
+		return nil, err
+	}
+
+	return key.SerializeUncompressed(), nil
+}
+
 //nolint:funlen,gocyclo // Old function
 func (s *Suite) transformAndHash(doc []byte, opts *models.ProofOptions) ([]byte, *pubkey.PublicKey, Verifier, error) {
 	if opts.SuiteType == "" {
@@ -283,6 +395,19 @@ func (s *Suite) transformAndHash(doc []byte, opts *models.ProofOptions) ([]byte,
 			curve = elliptic.P384()
 			mda = ld.MessageDigestAlgorithmSHA384
 			finalKey.Type = kms.ECDSAP384TypeIEEEP1363
+		} else if reflect.DeepEqual([]byte{0x82, 0x24}, header) ||
+			reflect.DeepEqual([]byte{0x12, 0x02}, header) {
+			verifier = s.p521Verifier
+			h = sha512.New()
+			curve = elliptic.P521()
+			mda = ld.MessageDigestAlgorithmSHA512
+			finalKey.Type = kms.ECDSAP521TypeIEEEP1363
+		} else if reflect.DeepEqual([]byte{0xe7, 0x01}, header) {
+			verifier = s.secp256k1Verifier
+			h = sha256.New()
+			curve = btcec.S256()
+			mda = ld.MessageDigestAlgorithmSHA256
+			finalKey.Type = kms.ECDSASecp256k1TypeIEEEP1363
 		}
 
 		if curve == nil {
@@ -293,7 +418,12 @@ func (s *Suite) transformAndHash(doc []byte, opts *models.ProofOptions) ([]byte,
 			)
 		}
 
-		pubKey, errEc := s.unmarshalECKey(curve, opts.VerificationMethod.Value[2:])
+		unmarshalKey := s.unmarshalECKey
+		if finalKey.Type == kms.ECDSASecp256k1TypeIEEEP1363 {
+			unmarshalKey = unmarshalSecp256k1Key
+		}
+
+		pubKey, errEc := unmarshalKey(curve, opts.VerificationMethod.Value[2:])
 		if errEc != nil {
 			return nil, nil, nil, errors.Join(fmt.Errorf(
 				"failed to unmarshal EC key. Header %x. Length %v",
@@ -321,51 +451,107 @@ func (s *Suite) transformAndHash(doc []byte, opts *models.ProofOptions) ([]byte,
 			verifier = s.p384Verifier
 			mda = ld.MessageDigestAlgorithmSHA384
 			finalKey.Type = kms.ECDSAP384TypeIEEEP1363
+		case "P-521":
+			h = sha512.New()
+			verifier = s.p521Verifier
+			mda = ld.MessageDigestAlgorithmSHA512
+			finalKey.Type = kms.ECDSAP521TypeIEEEP1363
+		case "secp256k1":
+			h = sha256.New()
+			verifier = s.secp256k1Verifier
+			mda = ld.MessageDigestAlgorithmSHA256
+			finalKey.Type = kms.ECDSASecp256k1TypeIEEEP1363
 		default:
 			return nil, nil, nil, fmt.Errorf("unsupported ECDSA curve. %v", finalKey.JWK.Crv)
 		}
 	}
 
+	if opts.SignatureAlgorithm != "" {
+		h, verifier, mda, finalKey.Type, err = s.signatureAlgorithm(opts.SignatureAlgorithm)
+		if err != nil {
+			return nil, nil, nil, err
+		}
+	}
+
 	confData := proofConfig(docData[ldCtxKey], opts)
 
-	if opts.ProofType != models.DataIntegrityProof || (opts.SuiteType != SuiteType && opts.SuiteType != SuiteTypeNew) {
+	if opts.ProofType != models.DataIntegrityProof || (opts.SuiteType != SuiteType &&
+		opts.SuiteType != SuiteTypeNew && opts.SuiteType != SuiteTypeJCS) {
 		return nil, nil, nil, suite.ErrProofTransformation
 	}
 
-	canonDoc, err := canonicalize(docData, s.ldLoader, mda)
-	if err != nil {
-		return nil, nil, nil, err
+	// The canonical N-Quads are written into the hash quad by quad, without joining them into a document.
+	canonicalizeFn := func(w io.Writer, data map[string]interface{}) error {
+		return canonicalizeTo(w, data, s.ldLoader, mda, opts.CanonicalizationAlgorithm)
 	}
 
-	canonConf, err := canonicalize(confData, s.ldLoader, mda)
-	if err != nil {
+	if opts.SuiteType == SuiteTypeJCS {
+		canonicalizeFn = canonicalizeJCSTo
+	}
+
+	if err = canonicalizeFn(h, docData); err != nil {
 		return nil, nil, nil, err
 	}
 
-	docHash := hashData(canonDoc, canonConf, h)
+	docHash := h.Sum(nil)
+
+	h.Reset()
+
+	if err = canonicalizeFn(h, confData); err != nil {
+		return nil, nil, nil, err
+	}
 
-	return docHash, finalKey, verifier, nil
+	return append(h.Sum(nil), docHash...), finalKey, verifier, nil
 }
 
 // VerifyProof implements the ecdsa-2019 cryptographic suite for CheckJWTProof Proof:
 // https://www.w3.org/TR/vc-di-ecdsa/#verify-proof-ecdsa-2019
 func (s *Suite) VerifyProof(doc []byte, proof *models.Proof, opts *models.ProofOptions) error {
+	return s.VerifyProofContext(context.Background(), doc, proof, opts)
+}
+
+// VerifyProofContext verifies the proof as VerifyProof, with the JSON-LD contexts loaded until
+// ctx is done, implements suite.ContextVerifier.
+func (s *Suite) VerifyProofContext(
+	ctx context.Context,
+	doc []byte,
+	proof *models.Proof,
+	opts *models.ProofOptions,
+) error {
+	ctxSuite := *s
+	ctxSuite.ldLoader = suite.DocumentLoaderWithContext(ctx, s.ldLoader)
+
+	return ctxSuite.verifyProof(doc, proof, opts)
+}
+
+func (s *Suite) verifyProof(doc []byte, proof *models.Proof, opts *models.ProofOptions) error {
 	message, vmKey, verifier, err := s.transformAndHash(doc, opts)
 	if err != nil {
 		return err
 	}
 
-	_, signature, err := multibase.Decode(proof.ProofValue)
+	signatures, err := models.DecodeProofValue(proof.ProofValue, opts.ProofValueEncodingHint)
 	if err != nil {
 		return fmt.Errorf("decoding proofValue: %w", err)
 	}
 
-	err = verifier.Verify(signature, message, vmKey)
-	if err != nil {
-		return fmt.Errorf("failed to verify ecdsa-2019 DI proof: %w", err)
+	// With a proof value encoding hint, the proofValue may have several decodings.
+	for _, signature := range signatures {
+		err = verifier.Verify(signature, message, vmKey)
+		if err == nil {
+			return nil
+		}
 	}
 
-	return nil
+	return fmt.Errorf("failed to verify ecdsa-2019 DI proof: %w", err)
+}
+
+// WrapLDDocumentLoader wraps the JSON-LD document loader of the Suite, implements
+// suite.LDDocumentLoaderWrapper.
+func (s *Suite) WrapLDDocumentLoader(wrap func(loader ld.DocumentLoader) ld.DocumentLoader) {
+	if s.ldLoader != nil {
+		s.ldLoader = wrap(s.ldLoader)
+	}
 }
 
 // RequiresCreated returns false, as the ecdsa-2019 cryptographic suite does not
@@ -374,40 +560,39 @@ func (s *Suite) RequiresCreated() bool {
 	return false
 }
 
-func canonicalize(data map[string]interface{}, loader ld.DocumentLoader, mda ld.MessageDigestAlgorithm,
-) ([]byte, error) {
-	out, err := processor.Default().GetCanonicalDocument(
-		data,
-		processor.WithDocumentLoader(loader),
-		processor.WithMessageDigestAlgorithm(mda),
-	)
-	if err != nil {
-		return nil, fmt.Errorf("canonicalizing signature base data: %w", err)
+func canonicalizeTo(w io.Writer, data map[string]interface{}, loader ld.DocumentLoader,
+	mda ld.MessageDigestAlgorithm, algorithm models.CanonicalizationAlgorithm,
+) error {
+	if err := suite.CanonicalizeRDFTo(w, data, algorithm, loader, mda); err != nil {
+		return fmt.Errorf("canonicalizing signature base data: %w", err)
 	}
 
-	return out, nil
+	return nil
 }
 
-func hashData(docData, proofData []byte, h hash.Hash) []byte {
-	h.Write(docData)
-	docHash := h.Sum(nil)
+func canonicalizeJCSTo(w io.Writer, data map[string]interface{}) error {
+	out, err := canonicalizer.MarshalCanonical(data)
+	if err != nil {
+		return fmt.Errorf("canonicalizing signature base data: %w", err)
+	}
 
-	h.Reset()
-	h.Write(proofData)
-	proofHash := h.Sum(nil)
+	_, err = w.Write(out)
 
-	return append(proofHash, docHash...)
+	return err
 }
 
 func proofConfig(docCtx interface{}, opts *models.ProofOptions) map[string]interface{} {
 	proof := map[string]interface{}{
-		ldCtxKey:             docCtx,
 		"type":               models.DataIntegrityProof,
 		"cryptosuite":        opts.SuiteType,
 		"verificationMethod": opts.VerificationMethodID,
 		"proofPurpose":       opts.Purpose,
 	}
 
+	if ctx := suite.ProofConfigContext(docCtx, opts.LenientProofContext); ctx != nil {
+		proof[ldCtxKey] = ctx
+	}
+
 	if !opts.Created.IsZero() {
 		proof["created"] = opts.Created.Format(models.DateTimeFormat)
 	}
@@ -420,10 +605,28 @@ func proofConfig(docCtx interface{}, opts *models.ProofOptions) map[string]inter
 		proof["challenge"] = opts.Challenge
 	}
 
+	if opts.Nonce != "" {
+		proof["nonce"] = opts.Nonce
+	}
+
 	if opts.Domain != "" {
 		proof["domain"] = opts.Domain
 	}
 
+	if opts.ProofID != "" {
+		proof["id"] = opts.ProofID
+	}
+
+	if opts.PreviousProof != "" {
+		proof["previousProof"] = opts.PreviousProof
+	}
+
+	for name, value := range opts.CustomFields {
+		if _, ok := proof[name]; !ok {
+			proof[name] = value
+		}
+	}
+
 	return proof
 }
 
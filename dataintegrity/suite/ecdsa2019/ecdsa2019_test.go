@@ -60,7 +60,7 @@ func TestNew(t *testing.T) {
 		require.NotNil(t, signer)
 		require.False(t, signer.RequiresCreated())
 
-		require.EqualValues(t, []string{"ecdsa-2019", "ecdsa-rdfc-2019"}, sigInit.Type())
+		require.EqualValues(t, []string{"ecdsa-2019", "ecdsa-rdfc-2019", "ecdsa-jcs-2019"}, sigInit.Type())
 	})
 
 	t.Run("verifier success", func(t *testing.T) {
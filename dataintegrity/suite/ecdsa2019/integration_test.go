@@ -7,17 +7,23 @@ SPDX-License-Identifier: Apache-2.0
 package ecdsa2019
 
 import (
+	"encoding/base64"
+	"strings"
 	"testing"
 	"time"
 
+	"github.com/btcsuite/btcd/btcec/v2"
+	"github.com/multiformats/go-multibase"
 	"github.com/stretchr/testify/require"
 	"github.com/trustbloc/did-go/doc/did"
 	"github.com/trustbloc/did-go/doc/ld/documentloader"
 	kmsapi "github.com/trustbloc/kms-go/spi/kms"
 
+	"github.com/trustbloc/vc-go/crypto-ext/testutil"
 	"github.com/trustbloc/vc-go/internal/testutil/kmscryptoutil"
 
 	"github.com/trustbloc/vc-go/dataintegrity/models"
+	"github.com/trustbloc/vc-go/dataintegrity/suite"
 )
 
 func TestIntegration(t *testing.T) {
@@ -53,6 +59,27 @@ func TestIntegration(t *testing.T) {
 	p384VM, err := did.NewVerificationMethodFromJWK("#key-2", "JsonWebKey2020", "did:foo:bar", p384JWK)
 	require.NoError(t, err)
 
+	p521JWK, err := kmsCrypto.Create(kmsapi.ECDSAP521IEEEP1363)
+	require.NoError(t, err)
+
+	p521VM, err := did.NewVerificationMethodFromJWK("#key-3", "JsonWebKey2020", "did:foo:bar", p521JWK)
+	require.NoError(t, err)
+
+	// The localkms secp256k1 signatures have a key ID prefix, sign with the key of a static signer instead.
+	secp256k1KeySigner, secp256k1Key, err := testutil.CreateEDSASecp256k1(true)
+	require.NoError(t, err)
+
+	secp256k1JWK := secp256k1Key.JWK
+
+	secp256k1VM, err := did.NewVerificationMethodFromJWK("#key-4", "JsonWebKey2020", "did:foo:bar", secp256k1JWK)
+	require.NoError(t, err)
+
+	secp256k1Signer, err := NewSignerInitializer(&SignerInitializerOptions{
+		LDDocumentLoader: docLoader,
+		SignerGetter:     WithStaticSigner(secp256k1KeySigner),
+	}).Signer()
+	require.NoError(t, err)
+
 	t.Run("success", func(t *testing.T) {
 		t.Run("P-256 key", func(t *testing.T) {
 			proofOpts := &models.ProofOptions{
@@ -72,6 +99,116 @@ func TestIntegration(t *testing.T) {
 			require.NoError(t, err)
 		})
 
+		t.Run("P-256 key with proof value encodings", func(t *testing.T) {
+			for encoding, prefix := range map[models.ProofValueEncoding]string{
+				"":                         "z",
+				models.ProofValueBase58BTC: "z",
+				models.ProofValueBase64URL: "u",
+			} {
+				proofOpts := &models.ProofOptions{
+					VerificationMethod:   p256VM,
+					VerificationMethodID: p256VM.ID,
+					SuiteType:            SuiteType,
+					Purpose:              "assertionMethod",
+					ProofType:            models.DataIntegrityProof,
+					Created:              time.Now(),
+					ProofValueEncoding:   encoding,
+				}
+
+				proof, err := signer.CreateProof(validCredential, proofOpts)
+				require.NoError(t, err)
+				require.True(t, strings.HasPrefix(proof.ProofValue, prefix), proof.ProofValue)
+
+				// The verifier detects the encoding from the multibase prefix.
+				err = verifier.VerifyProof(validCredential, proof, &models.ProofOptions{
+					VerificationMethod:   p256VM,
+					VerificationMethodID: p256VM.ID,
+					SuiteType:            SuiteType,
+					Purpose:              "assertionMethod",
+					ProofType:            models.DataIntegrityProof,
+				})
+				require.NoError(t, err)
+			}
+		})
+
+		t.Run("P-256 key with proof value without multibase prefix", func(t *testing.T) {
+			proofOpts := &models.ProofOptions{
+				VerificationMethod:   p256VM,
+				VerificationMethodID: p256VM.ID,
+				SuiteType:            SuiteType,
+				Purpose:              "assertionMethod",
+				ProofType:            models.DataIntegrityProof,
+				Created:              time.Now(),
+			}
+
+			proof, err := signer.CreateProof(validCredential, proofOpts)
+			require.NoError(t, err)
+
+			_, signature, err := multibase.Decode(proof.ProofValue)
+			require.NoError(t, err)
+
+			for hint, value := range map[models.ProofValueEncoding]string{
+				models.ProofValueBase58BTC: strings.TrimPrefix(proof.ProofValue, "z"),
+				models.ProofValueBase64URL: base64.RawURLEncoding.EncodeToString(signature),
+				models.ProofValueBase64:    base64.StdEncoding.EncodeToString(signature),
+			} {
+				unprefixed := *proof
+				unprefixed.ProofValue = value
+
+				verifyOpts := &models.ProofOptions{
+					VerificationMethod:   p256VM,
+					VerificationMethodID: p256VM.ID,
+					SuiteType:            SuiteType,
+					Purpose:              "assertionMethod",
+					ProofType:            models.DataIntegrityProof,
+				}
+
+				err = verifier.VerifyProof(validCredential, &unprefixed, verifyOpts)
+				require.Error(t, err, hint)
+
+				verifyOpts.ProofValueEncodingHint = hint
+
+				err = verifier.VerifyProof(validCredential, &unprefixed, verifyOpts)
+				require.NoError(t, err, hint)
+
+				// A multibase proof value is decoded as such.
+				err = verifier.VerifyProof(validCredential, proof, verifyOpts)
+				require.NoError(t, err, hint)
+			}
+
+			// A base58-btc value starting with 'z' is also valid multibase. The ECDSA signatures
+			// are randomized, so the proof is created until its value without prefix does.
+			for !strings.HasPrefix(proof.ProofValue, "zz") {
+				proof, err = signer.CreateProof(validCredential, proofOpts)
+				require.NoError(t, err)
+			}
+
+			unprefixed := *proof
+			unprefixed.ProofValue = strings.TrimPrefix(proof.ProofValue, "z")
+
+			err = verifier.VerifyProof(validCredential, &unprefixed, &models.ProofOptions{
+				VerificationMethod:     p256VM,
+				VerificationMethodID:   p256VM.ID,
+				SuiteType:              SuiteType,
+				Purpose:                "assertionMethod",
+				ProofType:              models.DataIntegrityProof,
+				ProofValueEncodingHint: models.ProofValueBase58BTC,
+			})
+			require.NoError(t, err)
+
+			unprefixed.ProofValue = base64.StdEncoding.EncodeToString(signature)
+
+			err = verifier.VerifyProof(validCredential, &unprefixed, &models.ProofOptions{
+				VerificationMethod:     p256VM,
+				VerificationMethodID:   p256VM.ID,
+				SuiteType:              SuiteType,
+				Purpose:                "assertionMethod",
+				ProofType:              models.DataIntegrityProof,
+				ProofValueEncodingHint: "base32",
+			})
+			require.ErrorContains(t, err, `unsupported proof value encoding hint "base32"`)
+		})
+
 		t.Run("P-256 key with new Suite", func(t *testing.T) {
 			proofOpts := &models.ProofOptions{
 				VerificationMethod:   p256VM,
@@ -92,6 +229,50 @@ func TestIntegration(t *testing.T) {
 			require.EqualValues(t, SuiteTypeNew, proof.CryptoSuite)
 		})
 
+		t.Run("P-256 key with JCS Suite", func(t *testing.T) {
+			jcsSignerInit := NewSignerInitializer(&SignerInitializerOptions{
+				SignerGetter: WithKMSCryptoWrapper(kmsCrypto),
+			})
+
+			jcsSigner, err := jcsSignerInit.Signer()
+			require.NoError(t, err)
+
+			proofOpts := &models.ProofOptions{
+				VerificationMethod:   p256VM,
+				VerificationMethodID: p256VM.ID,
+				SuiteType:            SuiteTypeJCS,
+				Purpose:              "assertionMethod",
+				ProofType:            models.DataIntegrityProof,
+				Created:              time.Now(),
+			}
+
+			// JCS doesn't need JSON-LD contexts, so the signer has no document loader.
+			proof, err := jcsSigner.CreateProof(validCredential, proofOpts)
+			require.NoError(t, err)
+
+			require.EqualValues(t, SuiteTypeJCS, proof.CryptoSuite)
+
+			err = verifier.VerifyProof(validCredential, proof, proofOpts)
+			require.NoError(t, err)
+		})
+
+		t.Run("P-384 key with JCS Suite", func(t *testing.T) {
+			proofOpts := &models.ProofOptions{
+				VerificationMethod:   p384VM,
+				VerificationMethodID: p384VM.ID,
+				SuiteType:            SuiteTypeJCS,
+				Purpose:              "assertionMethod",
+				ProofType:            models.DataIntegrityProof,
+				Created:              time.Now(),
+			}
+
+			proof, err := signer.CreateProof(validCredential, proofOpts)
+			require.NoError(t, err)
+
+			err = verifier.VerifyProof(validCredential, proof, proofOpts)
+			require.NoError(t, err)
+		})
+
 		t.Run("P-384 key", func(t *testing.T) {
 			proofOpts := &models.ProofOptions{
 				VerificationMethod:   p384VM,
@@ -109,9 +290,142 @@ func TestIntegration(t *testing.T) {
 			err = verifier.VerifyProof(validCredential, proof, proofOpts)
 			require.NoError(t, err)
 		})
+
+		t.Run("P-384 key with selected signature algorithm", func(t *testing.T) {
+			proofOpts := &models.ProofOptions{
+				VerificationMethod:   p384VM,
+				VerificationMethodID: p384VM.ID,
+				SuiteType:            SuiteType,
+				Purpose:              "assertionMethod",
+				ProofType:            models.DataIntegrityProof,
+				Created:              time.Now(),
+			}
+
+			proof, err := signer.CreateProof(validCredential, proofOpts)
+			require.NoError(t, err)
+
+			proofOpts.SignatureAlgorithm = "ES384"
+			require.NoError(t, verifier.VerifyProof(validCredential, proof, proofOpts))
+
+			proofOpts.SignatureAlgorithm = "ES256"
+			require.Error(t, verifier.VerifyProof(validCredential, proof, proofOpts))
+
+			proofOpts.SignatureAlgorithm = "RS256"
+			require.ErrorContains(t, verifier.VerifyProof(validCredential, proof, proofOpts),
+				`unsupported ECDSA signature algorithm "RS256"`)
+		})
+
+		// P-521 is an extension of the specification, which has no test vector for it.
+		t.Run("P-521 key", func(t *testing.T) {
+			proofOpts := &models.ProofOptions{
+				VerificationMethod:   p521VM,
+				VerificationMethodID: p521VM.ID,
+				SuiteType:            SuiteTypeNew,
+				Purpose:              "assertionMethod",
+				ProofType:            models.DataIntegrityProof,
+				Created:              time.Now(),
+			}
+
+			proof, err := signer.CreateProof(validCredential, proofOpts)
+			require.NoError(t, err)
+
+			// IEEE P1363 signature: r and s of 66 bytes each.
+			_, sig, err := multibase.Decode(proof.ProofValue)
+			require.NoError(t, err)
+			require.Len(t, sig, 132)
+
+			err = verifier.VerifyProof(validCredential, proof, proofOpts)
+			require.NoError(t, err)
+		})
+
+		t.Run("secp256k1 key", func(t *testing.T) {
+			for _, suiteType := range []string{SuiteType, SuiteTypeNew, SuiteTypeJCS} {
+				proofOpts := &models.ProofOptions{
+					VerificationMethod:   secp256k1VM,
+					VerificationMethodID: secp256k1VM.ID,
+					SuiteType:            suiteType,
+					Purpose:              "assertionMethod",
+					ProofType:            models.DataIntegrityProof,
+					Created:              time.Now(),
+				}
+
+				proof, err := secp256k1Signer.CreateProof(validCredential, proofOpts)
+				require.NoError(t, err)
+
+				// IEEE P1363 signature: r and s of 32 bytes each.
+				_, sig, err := multibase.Decode(proof.ProofValue)
+				require.NoError(t, err)
+				require.Len(t, sig, 64)
+
+				err = verifier.VerifyProof(validCredential, proof, proofOpts)
+				require.NoError(t, err, suiteType)
+			}
+		})
+
+		t.Run("secp256k1 Multikey", func(t *testing.T) {
+			pubKeyBytes, err := secp256k1JWK.PublicKeyBytes()
+			require.NoError(t, err)
+
+			pubKey, err := btcec.ParsePubKey(pubKeyBytes)
+			require.NoError(t, err)
+
+			multikeyVM := did.NewVerificationMethodFromBytes("#key-4", "Multikey", "did:foo:bar",
+				append([]byte{0xe7, 0x01}, pubKey.SerializeCompressed()...))
+
+			proof, err := secp256k1Signer.CreateProof(validCredential, &models.ProofOptions{
+				VerificationMethod:   secp256k1VM,
+				VerificationMethodID: secp256k1VM.ID,
+				SuiteType:            SuiteTypeNew,
+				Purpose:              "assertionMethod",
+				ProofType:            models.DataIntegrityProof,
+			})
+			require.NoError(t, err)
+
+			err = verifier.VerifyProof(validCredential, proof, &models.ProofOptions{
+				VerificationMethod:   multikeyVM,
+				VerificationMethodID: secp256k1VM.ID,
+				SuiteType:            SuiteTypeNew,
+				Purpose:              "assertionMethod",
+				ProofType:            models.DataIntegrityProof,
+			})
+			require.NoError(t, err)
+		})
 	})
 
 	t.Run("failure", func(t *testing.T) {
+		t.Run("JCS proof verified as RDFC", func(t *testing.T) {
+			proofOpts := &models.ProofOptions{
+				VerificationMethod:   p256VM,
+				VerificationMethodID: p256VM.ID,
+				SuiteType:            SuiteTypeJCS,
+				Purpose:              "assertionMethod",
+				ProofType:            models.DataIntegrityProof,
+				Created:              time.Now(),
+			}
+
+			proof, err := signer.CreateProof(validCredential, proofOpts)
+			require.NoError(t, err)
+
+			proofOpts.SuiteType = SuiteTypeNew
+
+			err = verifier.VerifyProof(validCredential, proof, proofOpts)
+			require.Error(t, err)
+			require.Contains(t, err.Error(), "failed to verify ecdsa-2019 DI proof")
+		})
+
+		t.Run("unsupported proof value encoding", func(t *testing.T) {
+			_, err := signer.CreateProof(validCredential, &models.ProofOptions{
+				VerificationMethod:   p256VM,
+				VerificationMethodID: p256VM.ID,
+				SuiteType:            SuiteType,
+				Purpose:              "assertionMethod",
+				ProofType:            models.DataIntegrityProof,
+				Created:              time.Now(),
+				ProofValueEncoding:   "base32",
+			})
+			require.ErrorContains(t, err, `unsupported proof value encoding "base32"`)
+		})
+
 		t.Run("wrong key", func(t *testing.T) {
 			signOpts := &models.ProofOptions{
 				VerificationMethod:   p256VM,
@@ -139,3 +453,68 @@ func TestIntegration(t *testing.T) {
 		})
 	})
 }
+
+func TestIntegration_KeySigner(t *testing.T) {
+	docLoader, err := documentloader.NewDocumentLoader(createMockProvider())
+	require.NoError(t, err)
+
+	kmsSuite, err := kmscryptoutil.LocalKMSCryptoSuite()
+	require.NoError(t, err)
+
+	keyCreator, err := kmsSuite.KeyCreator()
+	require.NoError(t, err)
+
+	keySigner, err := suite.NewKMSKeySigner(kmsSuite)
+	require.NoError(t, err)
+
+	signer, err := NewSignerInitializer(&SignerInitializerOptions{
+		LDDocumentLoader: docLoader,
+		SignerGetter:     WithKeySigner(keySigner),
+	}).Signer()
+	require.NoError(t, err)
+
+	verifier, err := NewVerifierInitializer(&VerifierInitializerOptions{
+		LDDocumentLoader: docLoader,
+	}).Verifier()
+	require.NoError(t, err)
+
+	for _, keyType := range []kmsapi.KeyType{kmsapi.ECDSAP256IEEEP1363, kmsapi.ECDSAP384IEEEP1363} {
+		pubJWK, err := keyCreator.Create(keyType)
+		require.NoError(t, err)
+
+		vm, err := did.NewVerificationMethodFromJWK("#key-1", "JsonWebKey2020", "did:foo:bar", pubJWK)
+		require.NoError(t, err)
+
+		proofOpts := &models.ProofOptions{
+			VerificationMethod:   vm,
+			VerificationMethodID: vm.ID,
+			SuiteType:            SuiteType,
+			Purpose:              "assertionMethod",
+			ProofType:            models.DataIntegrityProof,
+			Created:              time.Now(),
+		}
+
+		proof, err := signer.CreateProof(validCredential, proofOpts)
+		require.NoError(t, err, keyType)
+
+		err = verifier.VerifyProof(validCredential, proof, proofOpts)
+		require.NoError(t, err, keyType)
+	}
+
+	// The DER encoded ECDSA signatures of the key are not Data Integrity signatures.
+	derJWK, err := keyCreator.Create(kmsapi.ECDSAP256DER)
+	require.NoError(t, err)
+
+	derVM, err := did.NewVerificationMethodFromJWK("#key-2", "JsonWebKey2020", "did:foo:bar", derJWK)
+	require.NoError(t, err)
+
+	_, err = signer.CreateProof(validCredential, &models.ProofOptions{
+		VerificationMethod:   derVM,
+		VerificationMethodID: derVM.ID,
+		SuiteType:            SuiteType,
+		Purpose:              "assertionMethod",
+		ProofType:            models.DataIntegrityProof,
+		Created:              time.Now(),
+	})
+	require.ErrorIs(t, err, suite.ErrKeyTypeMismatch)
+}
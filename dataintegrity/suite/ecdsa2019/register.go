@@ -0,0 +1,35 @@
+/*
+Copyright Gen Digital Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package ecdsa2019
+
+import (
+	"github.com/trustbloc/vc-go/dataintegrity/suite"
+	"github.com/trustbloc/vc-go/ldcontext"
+)
+
+func init() { // nolint:gochecknoinits
+	suite.RegisterVerifier(registeredVerifierInitializer{})
+}
+
+// registeredVerifierInitializer initializes the ecdsa-2019 verification Suite registered with
+// suite.RegisterVerifier, which loads the JSON-LD contexts from the embedded bundle of ldcontext.
+type registeredVerifierInitializer struct{}
+
+// Verifier private, implements suite.VerifierInitializer.
+func (registeredVerifierInitializer) Verifier() (suite.Verifier, error) {
+	loader, err := ldcontext.SharedDocumentLoader()
+	if err != nil {
+		return nil, err
+	}
+
+	return NewVerifierInitializer(&VerifierInitializerOptions{LDDocumentLoader: loader}).Verifier()
+}
+
+// Type private, implements suite.VerifierInitializer.
+func (registeredVerifierInitializer) Type() []string {
+	return initializer(nil).Type()
+}
@@ -0,0 +1,802 @@
+/*
+Copyright Gen Digital Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package ecdsasd2023
+
+import (
+	"bytes"
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/fxamacker/cbor/v2"
+	"github.com/multiformats/go-multibase"
+	"github.com/piprate/json-gold/ld"
+	"github.com/trustbloc/kms-go/doc/jose/jwk"
+	"github.com/trustbloc/kms-go/spi/kms"
+	wrapperapi "github.com/trustbloc/kms-go/wrapper/api"
+
+	"github.com/trustbloc/vc-go/crypto-ext/pubkey"
+	ecdsaverifier "github.com/trustbloc/vc-go/crypto-ext/verifiers/ecdsa"
+	"github.com/trustbloc/vc-go/dataintegrity/models"
+	"github.com/trustbloc/vc-go/dataintegrity/suite"
+	"github.com/trustbloc/vc-go/dataintegrity/suite/internal/selective"
+)
+
+const (
+	// SuiteType "ecdsa-sd-2023" is the data integrity Type identifier for the suite
+	// implementing ecdsa selective disclosure signatures as per this
+	// spec:https://www.w3.org/TR/vc-di-ecdsa/#ecdsa-sd-2023
+	SuiteType = "ecdsa-sd-2023"
+)
+
+const (
+	ldCtxKey        = "@context"
+	hmacKeySize     = 32
+	hmacLabelPrefix = "u"
+	p256CoordSize   = 32
+	mandatoryGroup  = "mandatory"
+	selectiveGroup  = "selective"
+	combinedGroup   = "combined"
+)
+
+var (
+	baseProofHeader    = []byte{0xd9, 0x5d, 0x00}
+	derivedProofHeader = []byte{0xd9, 0x5d, 0x01}
+	multikeyP256Header = []byte{0x80, 0x24}
+)
+
+// SignerGetter returns a Signer, which must sign with the private key matching
+// the public key provided in models.ProofOptions.VerificationMethod.
+type SignerGetter func(pub *jwk.JWK) (Signer, error)
+
+// WithStaticSigner sets the Suite to use a fixed Signer, with externally-chosen signing key.
+//
+// Use when a signing Suite is initialized for a single signature, then thrown away.
+func WithStaticSigner(signer Signer) SignerGetter {
+	return func(*jwk.JWK) (Signer, error) {
+		return signer, nil
+	}
+}
+
+// WithKMSCryptoWrapper provides a SignerGetter using the kmscrypto wrapper.
+//
+// This SignerGetter assumes that the public key JWKs provided were received
+// from the same kmscrypto.KMSCrypto implementation.
+func WithKMSCryptoWrapper(kmsCrypto wrapperapi.KMSCryptoSigner) SignerGetter {
+	return func(pub *jwk.JWK) (Signer, error) {
+		return kmsCrypto.FixedKeySigner(pub)
+	}
+}
+
+// WithKeySigner provides a SignerGetter signing with the keys of a suite.KeySigner, eg of a cloud
+// KMS or an HSM, see suite.NewFixedKeySigner: the key of the KeySigner is identified by the key ID
+// of the public key JWK, and must be of its type.
+func WithKeySigner(signer suite.KeySigner) SignerGetter {
+	return func(pub *jwk.JWK) (Signer, error) {
+		return suite.NewFixedKeySigner(signer, pub)
+	}
+}
+
+// A Signer is able to sign messages.
+type Signer interface {
+	// Sign will sign msg using a private key internal to the Signer.
+	// returns:
+	// 		signature in []byte
+	//		error in case of errors
+	Sign(msg []byte) ([]byte, error)
+}
+
+// A Verifier is able to verify messages.
+type Verifier interface {
+	// Verify will verify a signature for the given msg using a matching signature primitive in kh key handle of
+	// a public key
+	// returns:
+	// 		error in case of errors or nil if signature verification was successful
+	Verify(signature, msg []byte, pubKey *pubkey.PublicKey) error
+}
+
+// Suite implements the ecdsa-sd-2023 data integrity cryptographic suite with P-256 keys.
+//
+// The issuer creates a base proof, which signs the mandatory statements together and
+// every other statement separately with a proof-scoped key. The holder derives from the
+// base proof a disclosure proof, which reveals only the mandatory and the selected statements.
+// The verifier accepts both base and derived proofs.
+type Suite struct {
+	ldLoader     ld.DocumentLoader
+	p256Verifier Verifier
+	signerGetter SignerGetter
+}
+
+// Options provides initialization options for Suite.
+type Options struct {
+	LDDocumentLoader ld.DocumentLoader
+	P256Verifier     Verifier
+	SignerGetter     SignerGetter
+}
+
+// SuiteInitializer is the initializer for Suite.
+type SuiteInitializer func() (*Suite, error)
+
+// New constructs an initializer for Suite.
+func New(options *Options) SuiteInitializer {
+	return func() (*Suite, error) {
+		return &Suite{
+			ldLoader:     options.LDDocumentLoader,
+			p256Verifier: options.P256Verifier,
+			signerGetter: options.SignerGetter,
+		}, nil
+	}
+}
+
+type initializer SuiteInitializer
+
+// Signer private, implements suite.SignerInitializer.
+func (i initializer) Signer() (suite.Signer, error) {
+	return i()
+}
+
+// Verifier private, implements suite.VerifierInitializer.
+func (i initializer) Verifier() (suite.Verifier, error) {
+	return i()
+}
+
+// Deriver private, implements suite.DeriverInitializer.
+func (i initializer) Deriver() (suite.Deriver, error) {
+	return i()
+}
+
+// Type private, implements suite.SignerInitializer, suite.VerifierInitializer
+// and suite.DeriverInitializer.
+func (i initializer) Type() []string {
+	return []string{SuiteType}
+}
+
+// SignerInitializerOptions provides options for a SignerInitializer.
+type SignerInitializerOptions struct {
+	LDDocumentLoader ld.DocumentLoader
+	SignerGetter     SignerGetter
+}
+
+// NewSignerInitializer returns a suite.SignerInitializer that initializes an ecdsa-sd-2023
+// signing Suite with the given SignerInitializerOptions.
+func NewSignerInitializer(options *SignerInitializerOptions) suite.SignerInitializer {
+	return initializer(New(&Options{
+		LDDocumentLoader: options.LDDocumentLoader,
+		SignerGetter:     options.SignerGetter,
+	}))
+}
+
+// VerifierInitializerOptions provides options for a VerifierInitializer.
+type VerifierInitializerOptions struct {
+	LDDocumentLoader ld.DocumentLoader // required
+	P256Verifier     Verifier          // optional
+}
+
+// NewVerifierInitializer returns a suite.VerifierInitializer that initializes an
+// ecdsa-sd-2023 verification Suite with the given VerifierInitializerOptions.
+func NewVerifierInitializer(options *VerifierInitializerOptions) suite.VerifierInitializer {
+	p256Verifier := options.P256Verifier
+
+	if p256Verifier == nil {
+		p256Verifier = ecdsaverifier.NewES256()
+	}
+
+	return initializer(New(&Options{
+		LDDocumentLoader: options.LDDocumentLoader,
+		P256Verifier:     p256Verifier,
+	}))
+}
+
+// DeriverInitializerOptions provides options for a DeriverInitializer.
+type DeriverInitializerOptions struct {
+	LDDocumentLoader ld.DocumentLoader // required
+}
+
+// NewDeriverInitializer returns a suite.DeriverInitializer that initializes an
+// ecdsa-sd-2023 Suite for deriving disclosure proofs with the given DeriverInitializerOptions.
+func NewDeriverInitializer(options *DeriverInitializerOptions) suite.DeriverInitializer {
+	return initializer(New(&Options{
+		LDDocumentLoader: options.LDDocumentLoader,
+	}))
+}
+
+type baseProofValue struct {
+	_                 struct{} `cbor:",toarray"`
+	BaseSignature     []byte
+	PublicKey         []byte
+	HMACKey           []byte
+	Signatures        [][]byte
+	MandatoryPointers []string
+}
+
+type derivedProofValue struct {
+	_                struct{} `cbor:",toarray"`
+	BaseSignature    []byte
+	PublicKey        []byte
+	Signatures       [][]byte
+	LabelMap         map[int][]byte
+	MandatoryIndexes []int
+}
+
+// CreateProof implements the ecdsa-sd-2023 cryptographic suite for Add Base Proof:
+// https://www.w3.org/TR/vc-di-ecdsa/#base-proof-transformation-ecdsa-sd-2023
+func (s *Suite) CreateProof(doc []byte, opts *models.ProofOptions) (*models.Proof, error) {
+	if opts.SuiteType == "" {
+		opts.SuiteType = SuiteType
+	}
+
+	if opts.PreviousProof != "" {
+		return nil, errors.New("ecdsa-sd-2023 proofs can't be chained to a previous proof")
+	}
+
+	docData, vmKey, err := s.transform(doc, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	proofHash, err := s.proofHash(docData[ldCtxKey], opts)
+	if err != nil {
+		return nil, err
+	}
+
+	hmacKey := make([]byte, hmacKeySize)
+
+	if _, err = rand.Read(hmacKey); err != nil {
+		return nil, err
+	}
+
+	groups, err := s.processor().CanonicalizeAndGroup(docData, hmacLabels(hmacKey), map[string][]string{
+		mandatoryGroup: opts.MandatoryPointers,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	mandatory := groups.Statements(groups.Groups[mandatoryGroup].Matching)
+	nonMandatory := groups.Statements(groups.Groups[mandatoryGroup].NonMatching)
+
+	proofKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		return nil, err
+	}
+
+	publicKey := append(append([]byte{}, multikeyP256Header...),
+		elliptic.MarshalCompressed(elliptic.P256(), proofKey.X, proofKey.Y)...)
+
+	signer, err := s.signerGetter(vmKey.JWK)
+	if err != nil {
+		return nil, err
+	}
+
+	baseSignature, err := signer.Sign(signData(proofHash, publicKey, hashStatements(mandatory)))
+	if err != nil {
+		return nil, err
+	}
+
+	signatures := make([][]byte, 0, len(nonMandatory))
+
+	for _, statement := range nonMandatory {
+		sig, errSign := signWithProofKey(proofKey, []byte(statement))
+		if errSign != nil {
+			return nil, errSign
+		}
+
+		signatures = append(signatures, sig)
+	}
+
+	mandatoryPointers := opts.MandatoryPointers
+	if mandatoryPointers == nil {
+		mandatoryPointers = []string{}
+	}
+
+	proofValue, err := encodeProofValue(baseProofHeader, &baseProofValue{
+		BaseSignature:     baseSignature,
+		PublicKey:         publicKey,
+		HMACKey:           hmacKey,
+		Signatures:        signatures,
+		MandatoryPointers: mandatoryPointers,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	var expires string
+	if !opts.Expires.IsZero() {
+		expires = opts.Expires.Format(models.DateTimeFormat)
+	}
+
+	p := &models.Proof{
+		Type:               models.DataIntegrityProof,
+		CryptoSuite:        opts.SuiteType,
+		ProofPurpose:       opts.Purpose,
+		Domain:             opts.Domain,
+		Challenge:          opts.Challenge,
+		Nonce:              opts.Nonce,
+		VerificationMethod: opts.VerificationMethod.ID,
+		ProofValue:         proofValue,
+		Created:            opts.Created.Format(models.DateTimeFormat),
+		Expires:            expires,
+		ID:                 opts.ProofID,
+		PreviousProof:      opts.PreviousProof,
+		CustomFields:       opts.CustomFields,
+	}
+
+	return p, nil
+}
+
+// DeriveProof implements the ecdsa-sd-2023 cryptographic suite for Add Derived Proof:
+// https://www.w3.org/TR/vc-di-ecdsa/#add-derived-proof-ecdsa-sd-2023
+//
+// The returned document contains the statements selected by the mandatory pointers of
+// the base proof and by selectivePointers, the returned proof is the disclosure proof for it.
+func (s *Suite) DeriveProof( // nolint:funlen
+	doc []byte,
+	proof *models.Proof,
+	selectivePointers []string,
+) ([]byte, *models.Proof, error) {
+	docData := make(map[string]interface{})
+
+	err := json.Unmarshal(doc, &docData)
+	if err != nil {
+		return nil, nil, fmt.Errorf("ecdsa-sd-2023 suite expects JSON-LD payload: %w", err)
+	}
+
+	base, err := parseBaseProofValue(proof.ProofValue)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	combinedPointers := append(append([]string{}, base.MandatoryPointers...), selectivePointers...)
+
+	groups, err := s.processor().CanonicalizeAndGroup(docData, hmacLabels(base.HMACKey), map[string][]string{
+		mandatoryGroup: base.MandatoryPointers,
+		selectiveGroup: selectivePointers,
+		combinedGroup:  combinedPointers,
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+
+	mandatory := groups.Groups[mandatoryGroup]
+	if len(mandatory.NonMatching) != len(base.Signatures) {
+		return nil, nil, errors.New("base proof signatures do not match the document statements")
+	}
+
+	mandatoryMatching := selective.IndexSet(mandatory.Matching)
+	selectiveMatching := selective.IndexSet(groups.Groups[selectiveGroup].Matching)
+
+	mandatoryIndexes := []int{}
+
+	for relativeIndex, absoluteIndex := range groups.Groups[combinedGroup].Matching {
+		if mandatoryMatching[absoluteIndex] {
+			mandatoryIndexes = append(mandatoryIndexes, relativeIndex)
+		}
+	}
+
+	signatures := [][]byte{}
+
+	for i, absoluteIndex := range mandatory.NonMatching {
+		if selectiveMatching[absoluteIndex] {
+			signatures = append(signatures, base.Signatures[i])
+		}
+	}
+
+	labels, err := groups.VerifierLabelMap(groups.Groups[combinedGroup].DeskolemizedNQuads)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	labelMap := make(map[int][]byte, len(labels))
+
+	for index, label := range labels {
+		labelMap[index], err = base64.RawURLEncoding.DecodeString(strings.TrimPrefix(label, hmacLabelPrefix))
+		if err != nil {
+			return nil, nil, err
+		}
+	}
+
+	revealDoc, err := selective.SelectJSONLD(combinedPointers, groups.Skolemized)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	revealDocBytes, err := json.Marshal(selective.Unskolemize(revealDoc))
+	if err != nil {
+		return nil, nil, err
+	}
+
+	proofValue, err := encodeProofValue(derivedProofHeader, &derivedProofValue{
+		BaseSignature:    base.BaseSignature,
+		PublicKey:        base.PublicKey,
+		Signatures:       signatures,
+		LabelMap:         labelMap,
+		MandatoryIndexes: mandatoryIndexes,
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+
+	derivedProof := *proof
+	derivedProof.ProofValue = proofValue
+
+	return revealDocBytes, &derivedProof, nil
+}
+
+// VerifyProof implements the ecdsa-sd-2023 cryptographic suite for Verify Derived Proof:
+// https://www.w3.org/TR/vc-di-ecdsa/#verify-derived-proof-ecdsa-sd-2023
+//
+// Base proofs are verified as well, so that the holder can check the credential before disclosure.
+func (s *Suite) VerifyProof(doc []byte, proof *models.Proof, opts *models.ProofOptions) error {
+	return s.VerifyProofContext(context.Background(), doc, proof, opts)
+}
+
+// VerifyProofContext verifies the proof as VerifyProof, with the JSON-LD contexts loaded until
+// ctx is done, implements suite.ContextVerifier.
+func (s *Suite) VerifyProofContext(
+	ctx context.Context,
+	doc []byte,
+	proof *models.Proof,
+	opts *models.ProofOptions,
+) error {
+	ctxSuite := *s
+	ctxSuite.ldLoader = suite.DocumentLoaderWithContext(ctx, s.ldLoader)
+
+	return ctxSuite.verifyProof(doc, proof, opts)
+}
+
+func (s *Suite) verifyProof(doc []byte, proof *models.Proof, opts *models.ProofOptions) error {
+	docData, vmKey, err := s.transform(doc, opts)
+	if err != nil {
+		return err
+	}
+
+	proofHash, err := s.proofHash(docData[ldCtxKey], opts)
+	if err != nil {
+		return err
+	}
+
+	_, proofValue, err := multibase.Decode(proof.ProofValue)
+	if err != nil {
+		return fmt.Errorf("decoding proofValue: %w", err)
+	}
+
+	var (
+		baseSignature, publicKey []byte
+		mandatory, nonMandatory  []string
+		signatures               [][]byte
+	)
+
+	switch {
+	case bytes.HasPrefix(proofValue, baseProofHeader):
+		base, errParse := parseBaseProofValue(proof.ProofValue)
+		if errParse != nil {
+			return errParse
+		}
+
+		groups, errGroup := s.processor().CanonicalizeAndGroup(docData, hmacLabels(base.HMACKey), map[string][]string{
+			mandatoryGroup: base.MandatoryPointers,
+		})
+		if errGroup != nil {
+			return errGroup
+		}
+
+		baseSignature, publicKey, signatures = base.BaseSignature, base.PublicKey, base.Signatures
+		mandatory = groups.Statements(groups.Groups[mandatoryGroup].Matching)
+		nonMandatory = groups.Statements(groups.Groups[mandatoryGroup].NonMatching)
+	case bytes.HasPrefix(proofValue, derivedProofHeader):
+		derived, errParse := parseDerivedProofValue(proofValue)
+		if errParse != nil {
+			return errParse
+		}
+
+		mandatory, nonMandatory, err = s.splitDisclosedStatements(docData, derived)
+		if err != nil {
+			return err
+		}
+
+		baseSignature, publicKey, signatures = derived.BaseSignature, derived.PublicKey, derived.Signatures
+	default:
+		return errors.New("ecdsa-sd-2023 proofValue has unknown header")
+	}
+
+	if len(signatures) != len(nonMandatory) {
+		return fmt.Errorf("ecdsa-sd-2023 proof has %d signatures for %d non-mandatory statements",
+			len(signatures), len(nonMandatory))
+	}
+
+	err = s.p256Verifier.Verify(baseSignature, signData(proofHash, publicKey, hashStatements(mandatory)), vmKey)
+	if err != nil {
+		return fmt.Errorf("failed to verify ecdsa-sd-2023 DI proof base signature: %w", err)
+	}
+
+	proofKey, err := proofPublicKey(publicKey)
+	if err != nil {
+		return err
+	}
+
+	for i, statement := range nonMandatory {
+		err = s.p256Verifier.Verify(signatures[i], []byte(statement), proofKey)
+		if err != nil {
+			return fmt.Errorf("failed to verify ecdsa-sd-2023 DI proof statement signature: %w", err)
+		}
+	}
+
+	return nil
+}
+
+// WrapLDDocumentLoader wraps the JSON-LD document loader of the Suite, implements
+// suite.LDDocumentLoaderWrapper.
+func (s *Suite) WrapLDDocumentLoader(wrap func(loader ld.DocumentLoader) ld.DocumentLoader) {
+	if s.ldLoader != nil {
+		s.ldLoader = wrap(s.ldLoader)
+	}
+}
+
+// RequiresCreated returns false, as the ecdsa-sd-2023 cryptographic suite does not
+// require the use of the models.Proof.Created field.
+func (s *Suite) RequiresCreated() bool {
+	return false
+}
+
+func (s *Suite) transform(doc []byte, opts *models.ProofOptions) (map[string]interface{}, *pubkey.PublicKey, error) {
+	if opts.SuiteType == "" {
+		opts.SuiteType = SuiteType
+	}
+
+	if opts.ProofType != models.DataIntegrityProof || opts.SuiteType != SuiteType {
+		return nil, nil, suite.ErrProofTransformation
+	}
+
+	docData := make(map[string]interface{})
+
+	err := json.Unmarshal(doc, &docData)
+	if err != nil {
+		return nil, nil, fmt.Errorf("ecdsa-sd-2023 suite expects JSON-LD payload: %w", err)
+	}
+
+	vmKey, err := verificationMethodKey(opts.VerificationMethod)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return docData, vmKey, nil
+}
+
+// splitDisclosedStatements canonicalizes the disclosed document with the label map of the
+// derived proof and splits the statements into mandatory and non-mandatory ones.
+func (s *Suite) splitDisclosedStatements(
+	docData map[string]interface{},
+	derived *derivedProofValue,
+) ([]string, []string, error) {
+	labelMap := make(map[int]string, len(derived.LabelMap))
+
+	for index, label := range derived.LabelMap {
+		labelMap[index] = hmacLabelPrefix + base64.RawURLEncoding.EncodeToString(label)
+	}
+
+	statements, err := s.processor().CanonicalizeWithLabelMap(docData, labelMap)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	mandatoryIndexes := selective.IndexSet(derived.MandatoryIndexes)
+
+	var mandatory, nonMandatory []string
+
+	for i, statement := range statements {
+		if mandatoryIndexes[i] {
+			mandatory = append(mandatory, statement)
+		} else {
+			nonMandatory = append(nonMandatory, statement)
+		}
+	}
+
+	return mandatory, nonMandatory, nil
+}
+
+func (s *Suite) processor() *selective.Processor {
+	return selective.NewProcessor(s.ldLoader)
+}
+
+func (s *Suite) proofHash(docCtx interface{}, opts *models.ProofOptions) ([]byte, error) {
+	canonConf, err := s.processor().ToNQuads(proofConfig(docCtx, opts))
+	if err != nil {
+		return nil, err
+	}
+
+	canonical, _, err := selective.CanonicalizeNQuads(canonConf)
+	if err != nil {
+		return nil, err
+	}
+
+	return hashStatements(canonical), nil
+}
+
+func proofConfig(docCtx interface{}, opts *models.ProofOptions) map[string]interface{} {
+	proof := map[string]interface{}{
+		"type":               models.DataIntegrityProof,
+		"cryptosuite":        opts.SuiteType,
+		"verificationMethod": opts.VerificationMethodID,
+		"proofPurpose":       opts.Purpose,
+	}
+
+	if ctx := suite.ProofConfigContext(docCtx, opts.LenientProofContext); ctx != nil {
+		proof[ldCtxKey] = ctx
+	}
+
+	if !opts.Created.IsZero() {
+		proof["created"] = opts.Created.Format(models.DateTimeFormat)
+	}
+
+	if !opts.Expires.IsZero() {
+		proof["expires"] = opts.Expires.Format(models.DateTimeFormat)
+	}
+
+	if opts.Challenge != "" {
+		proof["challenge"] = opts.Challenge
+	}
+
+	if opts.Nonce != "" {
+		proof["nonce"] = opts.Nonce
+	}
+
+	if opts.Domain != "" {
+		proof["domain"] = opts.Domain
+	}
+
+	if opts.ProofID != "" {
+		proof["id"] = opts.ProofID
+	}
+
+	if opts.PreviousProof != "" {
+		proof["previousProof"] = opts.PreviousProof
+	}
+
+	for name, value := range opts.CustomFields {
+		if _, ok := proof[name]; !ok {
+			proof[name] = value
+		}
+	}
+
+	return proof
+}
+
+// hmacLabels returns the selective.LabelFunc replacing the canonical blank node labels
+// with their HMAC, so that the labels don't leak the structure of the undisclosed statements.
+func hmacLabels(hmacKey []byte) selective.LabelFunc {
+	return func(canonicalLabels []string) (map[string]string, error) {
+		labels := make(map[string]string, len(canonicalLabels))
+
+		for _, canonicalLabel := range canonicalLabels {
+			mac := hmac.New(sha256.New, hmacKey)
+			mac.Write([]byte(canonicalLabel))
+
+			labels[canonicalLabel] = hmacLabelPrefix + base64.RawURLEncoding.EncodeToString(mac.Sum(nil))
+		}
+
+		return labels, nil
+	}
+}
+
+func signData(proofHash, publicKey, mandatoryHash []byte) []byte {
+	data := make([]byte, 0, len(proofHash)+len(publicKey)+len(mandatoryHash))
+	data = append(data, proofHash...)
+	data = append(data, publicKey...)
+
+	return append(data, mandatoryHash...)
+}
+
+func hashStatements(statements []string) []byte {
+	h := sha256.New()
+
+	for _, statement := range statements {
+		h.Write([]byte(statement))
+	}
+
+	return h.Sum(nil)
+}
+
+func signWithProofKey(key *ecdsa.PrivateKey, msg []byte) ([]byte, error) {
+	digest := sha256.Sum256(msg)
+
+	r, s, err := ecdsa.Sign(rand.Reader, key, digest[:])
+	if err != nil {
+		return nil, err
+	}
+
+	sig := make([]byte, 2*p256CoordSize)
+	r.FillBytes(sig[:p256CoordSize])
+	s.FillBytes(sig[p256CoordSize:])
+
+	return sig, nil
+}
+
+func verificationMethodKey(vm *models.VerificationMethod) (*pubkey.PublicKey, error) {
+	if vm == nil {
+		return nil, errors.New("verification method is required")
+	}
+
+	if key := vm.JSONWebKey(); key != nil {
+		if key.Crv != "P-256" {
+			return nil, fmt.Errorf("unsupported ECDSA curve. %v", key.Crv)
+		}
+
+		return &pubkey.PublicKey{Type: kms.ECDSAP256TypeIEEEP1363, JWK: key}, nil
+	}
+
+	return proofPublicKey(vm.Value)
+}
+
+// proofPublicKey parses a P-256 public key in the compressed Multikey format.
+func proofPublicKey(multikey []byte) (*pubkey.PublicKey, error) {
+	if !bytes.HasPrefix(multikey, multikeyP256Header) {
+		return nil, errors.New("verification method needs JWK or P-256 Multikey")
+	}
+
+	x, y := elliptic.UnmarshalCompressed(elliptic.P256(), multikey[len(multikeyP256Header):])
+	if x == nil {
+		return nil, errors.New("failed to unmarshal EC key")
+	}
+
+	return &pubkey.PublicKey{
+		Type:     kms.ECDSAP256TypeIEEEP1363,
+		BytesKey: &pubkey.BytesKey{Bytes: elliptic.Marshal(elliptic.P256(), x, y)}, //nolint:staticcheck
+	}, nil
+}
+
+func encodeProofValue(header []byte, value interface{}) (string, error) {
+	encMode, err := cbor.CoreDetEncOptions().EncMode()
+	if err != nil {
+		return "", err
+	}
+
+	data, err := encMode.Marshal(value)
+	if err != nil {
+		return "", fmt.Errorf("encoding ecdsa-sd-2023 proofValue: %w", err)
+	}
+
+	return multibase.Encode(multibase.Base64url, append(append([]byte{}, header...), data...))
+}
+
+func parseBaseProofValue(proofValue string) (*baseProofValue, error) {
+	_, data, err := multibase.Decode(proofValue)
+	if err != nil {
+		return nil, fmt.Errorf("decoding proofValue: %w", err)
+	}
+
+	if !bytes.HasPrefix(data, baseProofHeader) {
+		return nil, errors.New("ecdsa-sd-2023 proofValue is not a base proof")
+	}
+
+	value := &baseProofValue{}
+
+	err = cbor.Unmarshal(data[len(baseProofHeader):], value)
+	if err != nil {
+		return nil, fmt.Errorf("decoding ecdsa-sd-2023 base proofValue: %w", err)
+	}
+
+	return value, nil
+}
+
+func parseDerivedProofValue(data []byte) (*derivedProofValue, error) {
+	value := &derivedProofValue{}
+
+	err := cbor.Unmarshal(data[len(derivedProofHeader):], value)
+	if err != nil {
+		return nil, fmt.Errorf("decoding ecdsa-sd-2023 derived proofValue: %w", err)
+	}
+
+	return value, nil
+}
@@ -0,0 +1,260 @@
+/*
+Copyright Gen Digital Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package ecdsasd2023
+
+import (
+	_ "embed"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"github.com/tidwall/gjson"
+	"github.com/tidwall/sjson"
+	"github.com/trustbloc/did-go/doc/did"
+	"github.com/trustbloc/did-go/doc/ld/documentloader"
+	mockldstore "github.com/trustbloc/did-go/doc/ld/mock"
+	"github.com/trustbloc/did-go/doc/ld/store"
+	kmsapi "github.com/trustbloc/kms-go/spi/kms"
+
+	"github.com/trustbloc/vc-go/dataintegrity/models"
+	"github.com/trustbloc/vc-go/dataintegrity/suite"
+	"github.com/trustbloc/vc-go/internal/testutil/kmscryptoutil"
+)
+
+//go:embed testdata/valid_credential.jsonld
+var validCredential []byte
+
+func TestNew(t *testing.T) {
+	sigInit := NewSignerInitializer(&SignerInitializerOptions{})
+
+	signer, err := sigInit.Signer()
+	require.NoError(t, err)
+	require.False(t, signer.RequiresCreated())
+	require.EqualValues(t, []string{"ecdsa-sd-2023"}, sigInit.Type())
+
+	verInit := NewVerifierInitializer(&VerifierInitializerOptions{})
+
+	verifier, err := verInit.Verifier()
+	require.NoError(t, err)
+	require.NotNil(t, verifier)
+
+	derInit := NewDeriverInitializer(&DeriverInitializerOptions{})
+
+	deriver, err := derInit.Deriver()
+	require.NoError(t, err)
+	require.NotNil(t, deriver)
+	require.EqualValues(t, []string{"ecdsa-sd-2023"}, derInit.Type())
+}
+
+func TestIntegration(t *testing.T) {
+	docLoader, err := documentloader.NewDocumentLoader(createMockProvider())
+	require.NoError(t, err)
+
+	kmsCrypto := kmscryptoutil.LocalKMSCrypto(t)
+
+	signer, err := NewSignerInitializer(&SignerInitializerOptions{
+		LDDocumentLoader: docLoader,
+		SignerGetter:     WithKMSCryptoWrapper(kmsCrypto),
+	}).Signer()
+	require.NoError(t, err)
+
+	verifier, err := NewVerifierInitializer(&VerifierInitializerOptions{
+		LDDocumentLoader: docLoader,
+	}).Verifier()
+	require.NoError(t, err)
+
+	deriver, err := NewDeriverInitializer(&DeriverInitializerOptions{
+		LDDocumentLoader: docLoader,
+	}).Deriver()
+	require.NoError(t, err)
+
+	p256JWK, err := kmsCrypto.Create(kmsapi.ECDSAP256IEEEP1363)
+	require.NoError(t, err)
+
+	p256VM, err := did.NewVerificationMethodFromJWK("#key-1", "JsonWebKey2020", "did:foo:bar", p256JWK)
+	require.NoError(t, err)
+
+	// The proofs are verified with the created time they are signed with, as the Verifier does.
+	created := time.Now()
+
+	proofOpts := func() *models.ProofOptions {
+		return &models.ProofOptions{
+			VerificationMethod:   p256VM,
+			VerificationMethodID: p256VM.ID,
+			SuiteType:            SuiteType,
+			Purpose:              "assertionMethod",
+			ProofType:            models.DataIntegrityProof,
+			Created:              created,
+			MandatoryPointers:    []string{"/issuer", "/validFrom"},
+		}
+	}
+
+	opts := proofOpts()
+
+	baseProof, err := signer.CreateProof(validCredential, opts)
+	require.NoError(t, err)
+	require.Equal(t, SuiteType, baseProof.CryptoSuite)
+	require.Equal(t, "u", baseProof.ProofValue[:1])
+
+	t.Run("success", func(t *testing.T) {
+		t.Run("base proof", func(t *testing.T) {
+			require.NoError(t, verifier.VerifyProof(validCredential, baseProof, opts))
+		})
+
+		t.Run("derived proof", func(t *testing.T) {
+			revealed, derivedProof, err := deriver.DeriveProof(validCredential, baseProof,
+				[]string{"/credentialSubject/degree/name", "/credentialSubject/alumniOf/1"})
+			require.NoError(t, err)
+			require.NotEqual(t, baseProof.ProofValue, derivedProof.ProofValue)
+			require.Equal(t, baseProof.Created, derivedProof.Created)
+
+			var revealedDoc map[string]interface{}
+
+			require.NoError(t, json.Unmarshal(revealed, &revealedDoc))
+			require.Equal(t, "did:example:76e12ec712ebc6f1c221ebfeb1f", revealedDoc["issuer"])
+			require.Equal(t, "http://example.edu/credentials/1872", revealedDoc["id"])
+
+			subject, ok := revealedDoc["credentialSubject"].(map[string]interface{})
+			require.True(t, ok)
+			require.NotContains(t, subject, "name")
+			require.NotContains(t, subject, "birthDate")
+			require.NotContains(t, subject, "id")
+			require.Equal(t, []interface{}{"Another University"}, subject["alumniOf"])
+			require.Equal(t, map[string]interface{}{
+				"type": "ExampleBachelorDegree",
+				"name": "Bachelor of Science and Arts",
+			}, subject["degree"])
+
+			require.NoError(t, verifier.VerifyProof(revealed, derivedProof, proofOpts()))
+		})
+
+		t.Run("derived proof with mandatory statements only", func(t *testing.T) {
+			revealed, derivedProof, err := deriver.DeriveProof(validCredential, baseProof, nil)
+			require.NoError(t, err)
+			require.NotContains(t, string(revealed), "credentialSubject")
+
+			require.NoError(t, verifier.VerifyProof(revealed, derivedProof, proofOpts()))
+		})
+	})
+
+	t.Run("graph-framed credential", func(t *testing.T) {
+		credential, err := sjson.DeleteBytes(validCredential, ldCtxKey)
+		require.NoError(t, err)
+
+		graphCredential, err := json.Marshal(map[string]interface{}{
+			ldCtxKey: json.RawMessage(gjson.GetBytes(validCredential, ldCtxKey).Raw),
+			"@graph": []json.RawMessage{credential},
+		})
+		require.NoError(t, err)
+
+		graphOpts := proofOpts()
+		graphOpts.MandatoryPointers = []string{"/@graph/0/issuer", "/@graph/0/validFrom"}
+
+		graphProof, err := signer.CreateProof(graphCredential, graphOpts)
+		require.NoError(t, err)
+		require.NoError(t, verifier.VerifyProof(graphCredential, graphProof, graphOpts))
+
+		revealed, derivedProof, err := deriver.DeriveProof(graphCredential, graphProof,
+			[]string{"/@graph/0/credentialSubject/name"})
+		require.NoError(t, err)
+		require.Equal(t, "did:example:76e12ec712ebc6f1c221ebfeb1f", gjson.GetBytes(revealed, "@graph.0.issuer").String())
+		require.Equal(t, "Jayden Doe", gjson.GetBytes(revealed, "@graph.0.credentialSubject.name").String())
+		require.False(t, gjson.GetBytes(revealed, "@graph.0.credentialSubject.birthDate").Exists())
+
+		require.NoError(t, verifier.VerifyProof(revealed, derivedProof, graphOpts))
+	})
+
+	t.Run("failure", func(t *testing.T) {
+		t.Run("modified disclosed statement", func(t *testing.T) {
+			revealed, derivedProof, err := deriver.DeriveProof(validCredential, baseProof,
+				[]string{"/credentialSubject/degree/name"})
+			require.NoError(t, err)
+
+			revealed, err = sjson.SetBytes(revealed, "credentialSubject.degree.name", "Doctor of Arts")
+			require.NoError(t, err)
+
+			err = verifier.VerifyProof(revealed, derivedProof, proofOpts())
+			require.ErrorContains(t, err, "statement signature")
+		})
+
+		t.Run("modified mandatory statement", func(t *testing.T) {
+			revealed, derivedProof, err := deriver.DeriveProof(validCredential, baseProof, nil)
+			require.NoError(t, err)
+
+			revealed, err = sjson.SetBytes(revealed, "validFrom", "2011-01-01T19:23:24Z")
+			require.NoError(t, err)
+
+			err = verifier.VerifyProof(revealed, derivedProof, proofOpts())
+			require.ErrorContains(t, err, "base signature")
+		})
+
+		t.Run("modified base proof document", func(t *testing.T) {
+			modified, err := sjson.SetBytes(validCredential, "credentialSubject.name", "John Doe")
+			require.NoError(t, err)
+
+			require.Error(t, verifier.VerifyProof(modified, baseProof, opts))
+		})
+
+		t.Run("pointer does not match document", func(t *testing.T) {
+			_, _, err := deriver.DeriveProof(validCredential, baseProof, []string{"/credentialSubject/foo"})
+			require.ErrorContains(t, err, "does not match document")
+		})
+
+		t.Run("invalid pointer", func(t *testing.T) {
+			_, _, err := deriver.DeriveProof(validCredential, baseProof, []string{"credentialSubject"})
+			require.ErrorContains(t, err, "invalid JSON pointer")
+		})
+
+		t.Run("derive from derived proof", func(t *testing.T) {
+			revealed, derivedProof, err := deriver.DeriveProof(validCredential, baseProof, nil)
+			require.NoError(t, err)
+
+			_, _, err = deriver.DeriveProof(revealed, derivedProof, nil)
+			require.ErrorContains(t, err, "not a base proof")
+		})
+
+		t.Run("wrong suite type", func(t *testing.T) {
+			badOpts := proofOpts()
+			badOpts.SuiteType = "ecdsa-2019"
+
+			_, err := signer.CreateProof(validCredential, badOpts)
+			require.ErrorIs(t, err, suite.ErrProofTransformation)
+
+			err = verifier.VerifyProof(validCredential, baseProof, badOpts)
+			require.ErrorIs(t, err, suite.ErrProofTransformation)
+		})
+
+		t.Run("unknown proofValue header", func(t *testing.T) {
+			badProof := *baseProof
+			badProof.ProofValue = "uAAAA"
+
+			err := verifier.VerifyProof(validCredential, &badProof, opts)
+			require.ErrorContains(t, err, "unknown header")
+		})
+	})
+}
+
+type provider struct {
+	ContextStore        store.ContextStore
+	RemoteProviderStore store.RemoteProviderStore
+}
+
+func (p *provider) JSONLDContextStore() store.ContextStore {
+	return p.ContextStore
+}
+
+func (p *provider) JSONLDRemoteProviderStore() store.RemoteProviderStore {
+	return p.RemoteProviderStore
+}
+
+func createMockProvider() *provider {
+	return &provider{
+		ContextStore:        mockldstore.NewMockContextStore(),
+		RemoteProviderStore: mockldstore.NewMockRemoteProviderStore(),
+	}
+}
@@ -7,6 +7,8 @@ SPDX-License-Identifier: Apache-2.0
 package eddsa2022
 
 import (
+	"bytes"
+	"context"
 	"crypto/sha256"
 	"encoding/json"
 	"errors"
@@ -16,6 +18,7 @@ import (
 	"github.com/multiformats/go-multibase"
 	"github.com/piprate/json-gold/ld"
 	"github.com/trustbloc/did-go/doc/ld/processor"
+	"github.com/trustbloc/did-go/pkg/canonicalizer"
 	"github.com/trustbloc/kms-go/doc/jose/jwk"
 	"github.com/trustbloc/kms-go/spi/kms"
 	wrapperapi "github.com/trustbloc/kms-go/wrapper/api"
@@ -34,6 +37,11 @@ const (
 
 	// SuiteType2 "eddsa-2022" is the data integrity Type identifier for the suite. Alias (vc playground).
 	SuiteType2 = "eddsa-2022"
+
+	// SuiteTypeJCS "eddsa-jcs-2022" is the data integrity Type identifier for the suite
+	// implementing eddsa signatures with JSON Canonicalization Scheme (RFC 8785) as per this
+	// spec:https://w3c.github.io/vc-di-eddsa/#eddsa-jcs-2022
+	SuiteTypeJCS = "eddsa-jcs-2022"
 )
 
 // SignerGetter returns a Signer, which must sign with the private key matching
@@ -59,6 +67,15 @@ func WithKMSCryptoWrapper(kmsCrypto wrapperapi.KMSCryptoSigner) SignerGetter {
 	}
 }
 
+// WithKeySigner provides a SignerGetter signing with the keys of a suite.KeySigner, eg of a cloud
+// KMS or an HSM, see suite.NewFixedKeySigner: the key of the KeySigner is identified by the key ID
+// of the public key JWK, and must be of its type.
+func WithKeySigner(signer suite.KeySigner) SignerGetter {
+	return func(pub *jwk.JWK) (Signer, error) {
+		return suite.NewFixedKeySigner(signer, pub)
+	}
+}
+
 // A KMSSigner is able to sign messages.
 type KMSSigner interface { // TODO note: only used by deprecated function
 	// Sign will sign msg using a matching signature primitive in kh key handle of a private key
@@ -86,7 +103,8 @@ type Verifier interface {
 	Verify(signature, msg []byte, pubKey *pubkey.PublicKey) error
 }
 
-// Suite implements the eddsa-2022 data integrity cryptographic suite.
+// Suite implements the eddsa-2022 data integrity cryptographic suite, both in RDF
+// canonicalization (eddsa-rdfc-2022) and JCS (eddsa-jcs-2022) variants.
 type Suite struct {
 	ldLoader        ld.DocumentLoader
 	signerGetter    SignerGetter
@@ -129,7 +147,7 @@ func (i initializer) Verifier() (suite.Verifier, error) {
 // Type private, implements suite.SignerInitializer and
 // suite.VerifierInitializer.
 func (i initializer) Type() []string {
-	return []string{SuiteType, SuiteType2}
+	return []string{SuiteType, SuiteType2, SuiteTypeJCS}
 }
 
 // SignerInitializerOptions provides options for a SignerInitializer.
@@ -170,23 +188,54 @@ func NewVerifierInitializer(options *VerifierInitializerOptions) suite.VerifierI
 
 const (
 	ldCtxKey = "@context"
+
+	ed25519PublicKeySize = 32
 )
 
+// ed25519MulticodecPrefix is the varint of the ed25519-pub multicodec.
+var ed25519MulticodecPrefix = []byte{0xed, 0x01} //nolint:gochecknoglobals
+
 // CreateProof implements the eddsa-2022 cryptographic suite for Add Proof.
 func (s *Suite) CreateProof(doc []byte, opts *models.ProofOptions) (*models.Proof, error) {
-	docHash, vmKey, _, err := s.transformAndHash(doc, opts)
+	sigBase, vmKey, finalize, err := s.prepareProof(doc, opts)
 	if err != nil {
 		return nil, err
 	}
 
-	sig, err := sign(docHash, vmKey.JWK, s.signerGetter)
+	sig, err := sign(sigBase, vmKey.JWK, s.signerGetter)
 	if err != nil {
 		return nil, err
 	}
 
-	sigStr, err := multibase.Encode(multibase.Base58BTC, sig)
+	return finalize(sig)
+}
+
+// PrepareProof performs the transform and hash steps of the eddsa-2022 Add Proof, for a signature made
+// outside of the Suite, implements suite.ProofPreparer. The returned data is signed as is with Ed25519.
+func (s *Suite) PrepareProof(
+	doc []byte,
+	opts *models.ProofOptions,
+) ([]byte, func(sig []byte) (*models.Proof, error), error) {
+	sigBase, _, finalize, err := s.prepareProof(doc, opts)
 	if err != nil {
-		return nil, err
+		return nil, nil, err
+	}
+
+	return sigBase, finalize, nil
+}
+
+func (s *Suite) prepareProof(
+	doc []byte,
+	opts *models.ProofOptions,
+) ([]byte, *pubkey.PublicKey, func(sig []byte) (*models.Proof, error), error) {
+	docHash, vmKey, _, err := s.transformAndHash(doc, opts)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+
+	encoding, err := opts.ProofValueEncoding.Encoding()
+	if err != nil {
+		return nil, nil, nil, err
 	}
 
 	var expires string
@@ -194,19 +243,34 @@ func (s *Suite) CreateProof(doc []byte, opts *models.ProofOptions) (*models.Proo
 		expires = opts.Expires.Format(models.DateTimeFormat)
 	}
 
-	p := &models.Proof{
+	proof := models.Proof{
 		Type:               models.DataIntegrityProof,
-		CryptoSuite:        SuiteType,
+		CryptoSuite:        suiteType(opts),
 		ProofPurpose:       opts.Purpose,
 		Domain:             opts.Domain,
 		Challenge:          opts.Challenge,
+		Nonce:              opts.Nonce,
 		VerificationMethod: opts.VerificationMethod.ID,
-		ProofValue:         sigStr,
 		Created:            opts.Created.Format(models.DateTimeFormat),
 		Expires:            expires,
+		ID:                 opts.ProofID,
+		PreviousProof:      opts.PreviousProof,
+		CustomFields:       opts.CustomFields,
+	}
+
+	finalize := func(sig []byte) (*models.Proof, error) {
+		sigStr, e := multibase.Encode(encoding, sig)
+		if e != nil {
+			return nil, e
+		}
+
+		p := proof
+		p.ProofValue = sigStr
+
+		return &p, nil
 	}
 
-	return p, nil
+	return docHash, vmKey, finalize, nil
 }
 
 // nolint:gocyclo
@@ -224,12 +288,17 @@ func (s *Suite) transformAndHash(doc []byte, opts *models.ProofOptions) ([]byte,
 		verifier Verifier
 	)
 
+	// The selected signature algorithm, if any, must be the only one of the suite.
+	if opts.SignatureAlgorithm != "" && opts.SignatureAlgorithm != "EdDSA" {
+		return nil, nil, nil, fmt.Errorf("unsupported EdDSA signature algorithm %q", opts.SignatureAlgorithm)
+	}
+
 	verifier = s.eD25519Verifier
 	keyType = kms.ED25519Type
 
 	finalKey := &pubkey.PublicKey{Type: keyType, JWK: opts.VerificationMethod.JSONWebKey()}
 	if finalKey.JWK == nil && len(opts.VerificationMethod.Value) > 0 {
-		finalKey.BytesKey = &pubkey.BytesKey{Bytes: opts.VerificationMethod.Value}
+		finalKey.BytesKey = &pubkey.BytesKey{Bytes: ed25519KeyBytes(opts.VerificationMethod.Value)}
 	}
 
 	if finalKey.JWK == nil && finalKey.BytesKey == nil {
@@ -241,16 +310,24 @@ func (s *Suite) transformAndHash(doc []byte, opts *models.ProofOptions) ([]byte,
 	confData := proofConfig(docData[ldCtxKey], opts)
 
 	if opts.ProofType != models.DataIntegrityProof || (opts.SuiteType != SuiteType &&
-		opts.SuiteType != SuiteType2) {
+		opts.SuiteType != SuiteType2 && opts.SuiteType != SuiteTypeJCS) {
 		return nil, nil, nil, suite.ErrProofTransformation
 	}
 
-	canonDoc, err := canonicalize(docData, s.ldLoader)
+	canonicalizeFn := func(data map[string]interface{}) ([]byte, error) {
+		return canonicalize(data, s.ldLoader, opts.CanonicalizationAlgorithm)
+	}
+
+	if opts.SuiteType == SuiteTypeJCS {
+		canonicalizeFn = canonicalizeJCS
+	}
+
+	canonDoc, err := canonicalizeFn(docData)
 	if err != nil {
 		return nil, nil, nil, err
 	}
 
-	canonConf, err := canonicalize(confData, s.ldLoader)
+	canonConf, err := canonicalizeFn(confData)
 	if err != nil {
 		return nil, nil, nil, err
 	}
@@ -262,22 +339,51 @@ func (s *Suite) transformAndHash(doc []byte, opts *models.ProofOptions) ([]byte,
 
 // VerifyProof implements the eddsa-2022 cryptographic suite for CheckJWTProof Proof.
 func (s *Suite) VerifyProof(doc []byte, proof *models.Proof, opts *models.ProofOptions) error {
+	return s.VerifyProofContext(context.Background(), doc, proof, opts)
+}
+
+// VerifyProofContext verifies the proof as VerifyProof, with the JSON-LD contexts loaded until
+// ctx is done, implements suite.ContextVerifier.
+func (s *Suite) VerifyProofContext(
+	ctx context.Context,
+	doc []byte,
+	proof *models.Proof,
+	opts *models.ProofOptions,
+) error {
+	ctxSuite := *s
+	ctxSuite.ldLoader = suite.DocumentLoaderWithContext(ctx, s.ldLoader)
+
+	return ctxSuite.verifyProof(doc, proof, opts)
+}
+
+func (s *Suite) verifyProof(doc []byte, proof *models.Proof, opts *models.ProofOptions) error {
 	message, vmKey, verifier, err := s.transformAndHash(doc, opts)
 	if err != nil {
 		return err
 	}
 
-	_, signature, err := multibase.Decode(proof.ProofValue)
+	signatures, err := models.DecodeProofValue(proof.ProofValue, opts.ProofValueEncodingHint)
 	if err != nil {
 		return fmt.Errorf("decoding proofValue: %w", err)
 	}
 
-	err = verifier.Verify(signature, message, vmKey)
-	if err != nil {
-		return fmt.Errorf("failed to verify eddsa-2022 DI proof: %w", err)
+	// With a proof value encoding hint, the proofValue may have several decodings.
+	for _, signature := range signatures {
+		err = verifier.Verify(signature, message, vmKey)
+		if err == nil {
+			return nil
+		}
 	}
 
-	return nil
+	return fmt.Errorf("failed to verify eddsa-2022 DI proof: %w", err)
+}
+
+// WrapLDDocumentLoader wraps the JSON-LD document loader of the Suite, implements
+// suite.LDDocumentLoaderWrapper.
+func (s *Suite) WrapLDDocumentLoader(wrap func(loader ld.DocumentLoader) ld.DocumentLoader) {
+	if s.ldLoader != nil {
+		s.ldLoader = wrap(s.ldLoader)
+	}
 }
 
 // RequiresCreated returns false, as the eddsa-2022 cryptographic suite does not
@@ -286,8 +392,33 @@ func (s *Suite) RequiresCreated() bool {
 	return false
 }
 
-func canonicalize(data map[string]interface{}, loader ld.DocumentLoader) ([]byte, error) {
-	out, err := processor.Default().GetCanonicalDocument(data, processor.WithDocumentLoader(loader))
+// ed25519KeyBytes returns the raw Ed25519 public key of a verification method value, removing the
+// ed25519-pub multicodec prefix of a publicKeyMultibase (Multikey, Ed25519VerificationKey2020).
+// ref https://www.w3.org/TR/controller-document/#Multikey
+func ed25519KeyBytes(value []byte) []byte {
+	if len(value) == ed25519PublicKeySize+len(ed25519MulticodecPrefix) &&
+		bytes.HasPrefix(value, ed25519MulticodecPrefix) {
+		return value[len(ed25519MulticodecPrefix):]
+	}
+
+	return value
+}
+
+func canonicalize(
+	data map[string]interface{},
+	loader ld.DocumentLoader,
+	algorithm models.CanonicalizationAlgorithm,
+) ([]byte, error) {
+	out, err := suite.CanonicalizeRDF(data, algorithm, processor.WithDocumentLoader(loader))
+	if err != nil {
+		return nil, fmt.Errorf("canonicalizing signature base data: %w", err)
+	}
+
+	return out, nil
+}
+
+func canonicalizeJCS(data map[string]interface{}) ([]byte, error) {
+	out, err := canonicalizer.MarshalCanonical(data)
 	if err != nil {
 		return nil, fmt.Errorf("canonicalizing signature base data: %w", err)
 	}
@@ -306,20 +437,26 @@ func hashData(docData, proofData []byte, h hash.Hash) []byte {
 	return append(proofHash, docHash...)
 }
 
-func proofConfig(docCtx interface{}, opts *models.ProofOptions) map[string]interface{} {
-	suiteType := SuiteType
+func suiteType(opts *models.ProofOptions) string {
 	if opts.SuiteType != "" {
-		suiteType = opts.SuiteType
+		return opts.SuiteType
 	}
 
+	return SuiteType
+}
+
+func proofConfig(docCtx interface{}, opts *models.ProofOptions) map[string]interface{} {
 	proof := map[string]interface{}{
-		ldCtxKey:             docCtx,
 		"type":               models.DataIntegrityProof,
-		"cryptosuite":        suiteType,
+		"cryptosuite":        suiteType(opts),
 		"verificationMethod": opts.VerificationMethodID,
 		"proofPurpose":       opts.Purpose,
 	}
 
+	if ctx := suite.ProofConfigContext(docCtx, opts.LenientProofContext); ctx != nil {
+		proof[ldCtxKey] = ctx
+	}
+
 	if !opts.Created.IsZero() {
 		proof["created"] = opts.Created.Format(models.DateTimeFormat)
 	}
@@ -332,10 +469,28 @@ func proofConfig(docCtx interface{}, opts *models.ProofOptions) map[string]inter
 		proof["challenge"] = opts.Challenge
 	}
 
+	if opts.Nonce != "" {
+		proof["nonce"] = opts.Nonce
+	}
+
 	if opts.Domain != "" {
 		proof["domain"] = opts.Domain
 	}
 
+	if opts.ProofID != "" {
+		proof["id"] = opts.ProofID
+	}
+
+	if opts.PreviousProof != "" {
+		proof["previousProof"] = opts.PreviousProof
+	}
+
+	for name, value := range opts.CustomFields {
+		if _, ok := proof[name]; !ok {
+			proof[name] = value
+		}
+	}
+
 	return proof
 }
 
@@ -51,7 +51,7 @@ func TestNew(t *testing.T) {
 		require.NotNil(t, signer)
 		require.False(t, signer.RequiresCreated())
 
-		require.EqualValues(t, []string{"eddsa-rdfc-2022", "eddsa-2022"}, sigInit.Type())
+		require.EqualValues(t, []string{"eddsa-rdfc-2022", "eddsa-2022", "eddsa-jcs-2022"}, sigInit.Type())
 	})
 
 	t.Run("verifier success", func(t *testing.T) {
@@ -1,18 +1,27 @@
 package eddsa2022
 
 import (
+	"bytes"
+	"crypto/ed25519"
+	"crypto/sha256"
 	_ "embed"
+	"encoding/json"
+	"strings"
 	"testing"
 	"time"
 
+	"github.com/multiformats/go-multibase"
 	"github.com/stretchr/testify/require"
+	"github.com/tidwall/sjson"
 	"github.com/trustbloc/did-go/doc/did"
 	"github.com/trustbloc/did-go/doc/ld/documentloader"
 	mockldstore "github.com/trustbloc/did-go/doc/ld/mock"
 	"github.com/trustbloc/did-go/doc/ld/store"
+	"github.com/trustbloc/did-go/doc/ld/testutil"
 	kmsapi "github.com/trustbloc/kms-go/spi/kms"
 
 	"github.com/trustbloc/vc-go/dataintegrity/models"
+	"github.com/trustbloc/vc-go/dataintegrity/suite"
 	"github.com/trustbloc/vc-go/internal/testutil/kmscryptoutil"
 )
 
@@ -21,6 +30,18 @@ var (
 	validCredential []byte
 	//go:embed testdata/invalid_jsonld.jsonld
 	invalidJSONLD []byte
+	//go:embed testdata/spec_example_credential.jsonld
+	specExampleCredential []byte
+	//go:embed testdata/spec_example_eddsa_rdfc_2022.jsonld
+	specExampleRDFC []byte
+	//go:embed testdata/spec_example_eddsa_jcs_2022.jsonld
+	specExampleJCS []byte
+)
+
+// The key pair of the examples of the Data Integrity EdDSA Cryptosuites v1.0 specification.
+const (
+	specExamplePublicKeyMultibase = "z6MkrJVnaZkeFzdQyMZu1cgjg7k1pZZ6pvBQ7XJPt4swbTQ2"
+	specExampleSecretKeyMultibase = "z3u2en7t5LR2WtQH5PfFqMqwVHBeXouLzo6haApm8XHqvjxq"
 )
 
 func TestIntegration(t *testing.T) {
@@ -74,6 +95,89 @@ func TestIntegration(t *testing.T) {
 			err = verifier.VerifyProof(validCredential, proof, proofOpts)
 			require.NoError(t, err)
 		})
+
+		t.Run("registered verifier", func(t *testing.T) {
+			proofOpts := &models.ProofOptions{
+				VerificationMethod:   ed25519VM,
+				VerificationMethodID: ed25519VM.ID,
+				SuiteType:            SuiteType,
+				Purpose:              "assertionMethod",
+				ProofType:            models.DataIntegrityProof,
+				Created:              time.Now(),
+			}
+
+			proof, err := signer.CreateProof(validCredential, proofOpts)
+			require.NoError(t, err)
+
+			for _, suiteType := range []string{SuiteType, SuiteType2, SuiteTypeJCS} {
+				registered, ok := suite.LookupVerifier(suiteType)
+				require.True(t, ok, suiteType)
+
+				registeredVerifier, err := registered.Verifier()
+				require.NoError(t, err)
+				require.NoError(t, registeredVerifier.VerifyProof(validCredential, proof, proofOpts))
+			}
+		})
+
+		t.Run("ED25519 key with selected signature algorithm", func(t *testing.T) {
+			proofOpts := &models.ProofOptions{
+				VerificationMethod:   ed25519VM,
+				VerificationMethodID: ed25519VM.ID,
+				SuiteType:            SuiteType,
+				Purpose:              "assertionMethod",
+				ProofType:            models.DataIntegrityProof,
+				Created:              time.Now(),
+			}
+
+			proof, err := signer.CreateProof(validCredential, proofOpts)
+			require.NoError(t, err)
+
+			proofOpts.SignatureAlgorithm = "EdDSA"
+			require.NoError(t, verifier.VerifyProof(validCredential, proof, proofOpts))
+
+			proofOpts.SignatureAlgorithm = "ES256"
+			require.ErrorContains(t, verifier.VerifyProof(validCredential, proof, proofOpts),
+				`unsupported EdDSA signature algorithm "ES256"`)
+		})
+
+		t.Run("ED25519 key with base64url proof value", func(t *testing.T) {
+			proofOpts := &models.ProofOptions{
+				VerificationMethod:   ed25519VM,
+				VerificationMethodID: ed25519VM.ID,
+				SuiteType:            SuiteType,
+				Purpose:              "assertionMethod",
+				ProofType:            models.DataIntegrityProof,
+				Created:              time.Now(),
+				ProofValueEncoding:   models.ProofValueBase64URL,
+			}
+
+			proof, err := signer.CreateProof(validCredential, proofOpts)
+			require.NoError(t, err)
+			require.True(t, strings.HasPrefix(proof.ProofValue, "u"), proof.ProofValue)
+
+			proofOpts.ProofValueEncoding = ""
+
+			err = verifier.VerifyProof(validCredential, proof, proofOpts)
+			require.NoError(t, err)
+		})
+
+		t.Run("ED25519 key with JCS Suite", func(t *testing.T) {
+			proofOpts := &models.ProofOptions{
+				VerificationMethod:   ed25519VM,
+				VerificationMethodID: ed25519VM.ID,
+				SuiteType:            SuiteTypeJCS,
+				Purpose:              "assertionMethod",
+				ProofType:            models.DataIntegrityProof,
+				Created:              time.Now(),
+			}
+
+			proof, err := signer.CreateProof(validCredential, proofOpts)
+			require.NoError(t, err)
+			require.Equal(t, SuiteTypeJCS, proof.CryptoSuite)
+
+			err = verifier.VerifyProof(validCredential, proof, proofOpts)
+			require.NoError(t, err)
+		})
 	})
 
 	t.Run("failure", func(t *testing.T) {
@@ -106,6 +210,202 @@ func TestIntegration(t *testing.T) {
 	})
 }
 
+type ed25519Signer ed25519.PrivateKey
+
+func (s ed25519Signer) Sign(msg []byte) ([]byte, error) {
+	return ed25519.Sign(ed25519.PrivateKey(s), msg), nil
+}
+
+func TestJCSKnownAnswer(t *testing.T) {
+	privKey := ed25519.NewKeyFromSeed(bytes.Repeat([]byte{0x01}, ed25519.SeedSize))
+
+	vm := &did.VerificationMethod{
+		ID:    "did:foo:bar#key-1",
+		Type:  "Ed25519VerificationKey2020",
+		Value: privKey.Public().(ed25519.PublicKey),
+	}
+
+	doc := []byte(`{"type":["VerifiableCredential"],"issuer":"did:foo:bar",` +
+		`"@context":["https://www.w3.org/ns/credentials/v2"],"credentialSubject":{"name":"\u00e9","age":1.0}}`)
+
+	// Expected RFC 8785 canonical forms of the document and of the proof configuration.
+	canonDoc := `{"@context":["https://www.w3.org/ns/credentials/v2"],"credentialSubject":{"age":1,"name":"é"},` +
+		`"issuer":"did:foo:bar","type":["VerifiableCredential"]}`
+	canonConf := `{"@context":["https://www.w3.org/ns/credentials/v2"],"created":"2023-02-24T23:36:38Z",` +
+		`"cryptosuite":"eddsa-jcs-2022","proofPurpose":"assertionMethod","type":"DataIntegrityProof",` +
+		`"verificationMethod":"did:foo:bar#key-1"}`
+
+	docHash := sha256.Sum256([]byte(canonDoc))
+	confHash := sha256.Sum256([]byte(canonConf))
+
+	expectedSig, err := multibase.Encode(multibase.Base58BTC,
+		ed25519.Sign(privKey, append(confHash[:], docHash[:]...)))
+	require.NoError(t, err)
+
+	signer, err := NewSignerInitializer(&SignerInitializerOptions{
+		SignerGetter: WithStaticSigner(ed25519Signer(privKey)),
+	}).Signer()
+	require.NoError(t, err)
+
+	proofOpts := &models.ProofOptions{
+		VerificationMethod:   vm,
+		VerificationMethodID: vm.ID,
+		SuiteType:            SuiteTypeJCS,
+		Purpose:              "assertionMethod",
+		ProofType:            models.DataIntegrityProof,
+		Created:              time.Date(2023, 2, 24, 23, 36, 38, 0, time.UTC),
+	}
+
+	proof, err := signer.CreateProof(doc, proofOpts)
+	require.NoError(t, err)
+	require.Equal(t, expectedSig, proof.ProofValue)
+
+	verifier, err := NewVerifierInitializer(&VerifierInitializerOptions{}).Verifier()
+	require.NoError(t, err)
+
+	require.NoError(t, verifier.VerifyProof(doc, proof, proofOpts))
+}
+
+// TestSpecExamples verifies the signed credentials of the examples of the Data Integrity EdDSA Cryptosuites
+// v1.0 specification, and signs their credential again with the example key: Ed25519 signatures being
+// deterministic, the proof values must be the published ones.
+func TestSpecExamples(t *testing.T) {
+	docLoader, err := testutil.DocumentLoader()
+	require.NoError(t, err)
+
+	_, publicKey, err := multibase.Decode(specExamplePublicKeyMultibase)
+	require.NoError(t, err)
+	// The multicodec prefixes of ed25519-pub and ed25519-priv.
+	require.Equal(t, []byte{0xed, 0x01}, publicKey[:2])
+
+	_, secretKey, err := multibase.Decode(specExampleSecretKeyMultibase)
+	require.NoError(t, err)
+	require.Equal(t, []byte{0x80, 0x26}, secretKey[:2])
+
+	privKey := ed25519.NewKeyFromSeed(secretKey[2:])
+	require.Equal(t, ed25519.PublicKey(publicKey[2:]), privKey.Public())
+
+	signer, err := NewSignerInitializer(&SignerInitializerOptions{
+		LDDocumentLoader: docLoader,
+		SignerGetter:     WithStaticSigner(ed25519Signer(privKey)),
+	}).Signer()
+	require.NoError(t, err)
+
+	verifier, err := NewVerifierInitializer(&VerifierInitializerOptions{
+		LDDocumentLoader: docLoader,
+	}).Verifier()
+	require.NoError(t, err)
+
+	vm := &did.VerificationMethod{
+		ID:    "did:key:" + specExamplePublicKeyMultibase + "#" + specExamplePublicKeyMultibase,
+		Type:  "Multikey",
+		Value: publicKey[2:],
+	}
+
+	for _, tc := range []struct {
+		suiteType string
+		signed    []byte
+	}{
+		{suiteType: SuiteType, signed: specExampleRDFC},
+		{suiteType: SuiteTypeJCS, signed: specExampleJCS},
+	} {
+		t.Run(tc.suiteType, func(t *testing.T) {
+			var signed struct {
+				Proof *models.Proof `json:"proof"`
+			}
+
+			require.NoError(t, json.Unmarshal(tc.signed, &signed))
+			require.NotNil(t, signed.Proof)
+			require.Equal(t, tc.suiteType, signed.Proof.CryptoSuite)
+
+			unsigned, e := sjson.DeleteBytes(tc.signed, "proof")
+			require.NoError(t, e)
+			require.JSONEq(t, string(specExampleCredential), string(unsigned))
+
+			proofOpts := &models.ProofOptions{
+				VerificationMethod:   vm,
+				VerificationMethodID: vm.ID,
+				SuiteType:            tc.suiteType,
+				Purpose:              "assertionMethod",
+				ProofType:            models.DataIntegrityProof,
+				Created:              time.Date(2023, 2, 24, 23, 36, 38, 0, time.UTC),
+			}
+
+			require.NoError(t, verifier.VerifyProof(specExampleCredential, signed.Proof, proofOpts))
+
+			proof, e := signer.CreateProof(specExampleCredential, proofOpts)
+			require.NoError(t, e)
+			require.Equal(t, signed.Proof.ProofValue, proof.ProofValue)
+		})
+	}
+}
+
+func TestJCSDeterministicSigning(t *testing.T) {
+	privKey := ed25519.NewKeyFromSeed(bytes.Repeat([]byte{0x02}, ed25519.SeedSize))
+
+	vm := &did.VerificationMethod{
+		ID:    "did:foo:bar#key-1",
+		Type:  "Ed25519VerificationKey2020",
+		Value: privKey.Public().(ed25519.PublicKey),
+	}
+
+	// The same logical credential, with the keys in different orders. The subject keys U+1F600 and
+	// U+FB33 are ordered differently by UTF-16 code units (RFC 8785) and by code points.
+	docs := [][]byte{
+		[]byte(`{"@context":["https://www.w3.org/ns/credentials/v2"],"type":["VerifiableCredential"],` +
+			`"issuer":"did:foo:bar","credentialSubject":{"id":"did:foo:subject","a":"1",` +
+			`"\ufb33":"3","\ud83d\ude00":"2","degree":{"type":"BachelorDegree","name":"Bachelor"}}}`),
+		[]byte(`{"credentialSubject":{"degree":{"name":"Bachelor","type":"BachelorDegree"},` +
+			`"\ud83d\ude00":"2","\ufb33":"3","a":"1","id":"did:foo:subject"},"issuer":"did:foo:bar",` +
+			`"type":["VerifiableCredential"],"@context":["https://www.w3.org/ns/credentials/v2"]}`),
+		[]byte(`{"issuer":"did:foo:bar","credentialSubject":{"a":"1","\ufb33":"3","id":"did:foo:subject",` +
+			`"degree":{"type":"BachelorDegree","name":"Bachelor"},"\ud83d\ude00":"2"},` +
+			`"@context":["https://www.w3.org/ns/credentials/v2"],"type":["VerifiableCredential"]}`),
+	}
+
+	canonDoc := `{"@context":["https://www.w3.org/ns/credentials/v2"],"credentialSubject":{"a":"1",` +
+		`"degree":{"name":"Bachelor","type":"BachelorDegree"},"id":"did:foo:subject",` +
+		"\"\U0001F600\":\"2\",\"\uFB33\":\"3\"}," + `"issuer":"did:foo:bar","type":["VerifiableCredential"]}`
+	canonConf := `{"@context":["https://www.w3.org/ns/credentials/v2"],"created":"2023-02-24T23:36:38Z",` +
+		`"cryptosuite":"eddsa-jcs-2022","proofPurpose":"assertionMethod","type":"DataIntegrityProof",` +
+		`"verificationMethod":"did:foo:bar#key-1"}`
+
+	docHash := sha256.Sum256([]byte(canonDoc))
+	confHash := sha256.Sum256([]byte(canonConf))
+
+	expectedSig, err := multibase.Encode(multibase.Base58BTC,
+		ed25519.Sign(privKey, append(confHash[:], docHash[:]...)))
+	require.NoError(t, err)
+
+	signer, err := NewSignerInitializer(&SignerInitializerOptions{
+		SignerGetter: WithStaticSigner(ed25519Signer(privKey)),
+	}).Signer()
+	require.NoError(t, err)
+
+	verifier, err := NewVerifierInitializer(&VerifierInitializerOptions{}).Verifier()
+	require.NoError(t, err)
+
+	proofOpts := &models.ProofOptions{
+		VerificationMethod:   vm,
+		VerificationMethodID: vm.ID,
+		SuiteType:            SuiteTypeJCS,
+		Purpose:              "assertionMethod",
+		ProofType:            models.DataIntegrityProof,
+		Created:              time.Date(2023, 2, 24, 23, 36, 38, 0, time.UTC),
+	}
+
+	for i, doc := range docs {
+		// Sign repeatedly, so that any dependency on the map iteration order shows up.
+		for range 10 {
+			proof, err := signer.CreateProof(doc, proofOpts)
+			require.NoError(t, err, i)
+			require.Equal(t, expectedSig, proof.ProofValue, i)
+
+			require.NoError(t, verifier.VerifyProof(doc, proof, proofOpts), i)
+		}
+	}
+}
+
 type provider struct {
 	ContextStore        store.ContextStore
 	RemoteProviderStore store.RemoteProviderStore
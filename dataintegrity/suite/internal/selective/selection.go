@@ -0,0 +1,701 @@
+/*
+Copyright Gen Digital Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+// Package selective implements the selection of the statements of a JSON-LD document by JSON pointers,
+// shared by the selective disclosure cryptographic suites, as per
+// https://www.w3.org/TR/vc-di-ecdsa/#selective-disclosure-functions.
+package selective
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/piprate/json-gold/ld"
+)
+
+const (
+	ldCtxKey          = "@context"
+	ldGraphKey        = "@graph"
+	skolemPrefix      = "urn:bnid:"
+	skolemLabelPrefix = "sk"
+	blankNodePrefix   = "_:"
+	canonicalPrefix   = "c14n"
+	nquadsFormat      = "application/n-quads"
+)
+
+var skolemIRI = regexp.MustCompile(`<` + skolemPrefix + `([^>]+)>`)
+
+// LabelFunc returns the map from the given canonical blank node labels to the labels
+// the statements are signed with.
+type LabelFunc func(canonicalLabels []string) (map[string]string, error)
+
+// Group holds the indexes of the canonical statements selected by a group of
+// JSON pointers, and of the statements that were not.
+type Group struct {
+	Matching           []int
+	NonMatching        []int
+	DeskolemizedNQuads []string
+}
+
+// Groups holds the canonical statements of a document grouped by JSON pointers.
+type Groups struct {
+	// NQuads are the canonical statements of the document with the blank node labels of the LabelFunc, sorted.
+	NQuads []string
+	// LabelMap maps the skolemized blank node labels to the labels of the LabelFunc.
+	LabelMap   map[string]string
+	Skolemized map[string]interface{}
+	Groups     map[string]*Group
+}
+
+// Statements returns the statements at the given indexes.
+func (c *Groups) Statements(indexes []int) []string {
+	statements := make([]string, 0, len(indexes))
+
+	for _, i := range indexes {
+		statements = append(statements, c.NQuads[i])
+	}
+
+	return statements
+}
+
+// VerifierLabelMap returns the map from the canonical blank node labels of the disclosed
+// statements, by index, to the labels the base proof signed them with.
+func (c *Groups) VerifierLabelMap(disclosed []string) (map[int]string, error) {
+	_, idMap, err := CanonicalizeNQuads(disclosed)
+	if err != nil {
+		return nil, err
+	}
+
+	labelMap := make(map[int]string, len(idMap))
+
+	for input, canonical := range idMap {
+		label, ok := c.LabelMap[input]
+		if !ok {
+			return nil, fmt.Errorf("unknown blank node label %s", input)
+		}
+
+		index, err := strconv.Atoi(strings.TrimPrefix(canonical, canonicalPrefix))
+		if err != nil {
+			return nil, fmt.Errorf("invalid canonical blank node label %s", canonical)
+		}
+
+		labelMap[index] = label
+	}
+
+	return labelMap, nil
+}
+
+// Processor canonicalizes and selects JSON-LD documents with a JSON-LD document loader.
+type Processor struct {
+	ldLoader ld.DocumentLoader
+}
+
+// NewProcessor returns a Processor using the given JSON-LD document loader.
+func NewProcessor(ldLoader ld.DocumentLoader) *Processor {
+	return &Processor{ldLoader: ldLoader}
+}
+
+// CanonicalizeAndGroup canonicalizes the document, replaces the blank node labels with
+// the labels of labelFunc and groups the statements by each of the given groups of JSON pointers.
+func (p *Processor) CanonicalizeAndGroup(
+	docData map[string]interface{},
+	labelFunc LabelFunc,
+	groupPointers map[string][]string,
+) (*Groups, error) {
+	skolemized, err := p.skolemize(docData)
+	if err != nil {
+		return nil, err
+	}
+
+	nquads, err := p.ToNQuads(skolemized)
+	if err != nil {
+		return nil, err
+	}
+
+	canonical, idMap, err := CanonicalizeNQuads(deskolemize(nquads))
+	if err != nil {
+		return nil, err
+	}
+
+	canonicalLabels := make([]string, 0, len(idMap))
+
+	for _, canonicalLabel := range idMap {
+		canonicalLabels = append(canonicalLabels, canonicalLabel)
+	}
+
+	sort.Strings(canonicalLabels)
+
+	canonicalLabelMap, err := labelFunc(canonicalLabels)
+	if err != nil {
+		return nil, err
+	}
+
+	labelMap := make(map[string]string, len(idMap))
+
+	for input, canonicalLabel := range idMap {
+		labelMap[input] = canonicalLabelMap[canonicalLabel]
+	}
+
+	statements, err := relabelNQuads(canonical, canonicalLabelMap)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &Groups{
+		NQuads:     sortedStatements(statements),
+		LabelMap:   labelMap,
+		Skolemized: skolemized,
+		Groups:     make(map[string]*Group, len(groupPointers)),
+	}
+
+	for name, pointers := range groupPointers {
+		g, errGroup := p.selectGroup(result, pointers)
+		if errGroup != nil {
+			return nil, errGroup
+		}
+
+		result.Groups[name] = g
+	}
+
+	return result, nil
+}
+
+// CanonicalizeWithLabelMap canonicalizes the disclosed document and replaces its canonical
+// blank node labels, by index, with the labels of labelMap. The statements are returned sorted.
+func (p *Processor) CanonicalizeWithLabelMap(
+	docData map[string]interface{},
+	labelMap map[int]string,
+) ([]string, error) {
+	canonicalLabelMap := make(map[string]string, len(labelMap))
+
+	for index, label := range labelMap {
+		canonicalLabelMap[canonicalPrefix+strconv.Itoa(index)] = label
+	}
+
+	nquads, err := p.ToNQuads(docData)
+	if err != nil {
+		return nil, err
+	}
+
+	canonical, _, err := CanonicalizeNQuads(nquads)
+	if err != nil {
+		return nil, err
+	}
+
+	statements, err := relabelNQuads(canonical, canonicalLabelMap)
+	if err != nil {
+		return nil, err
+	}
+
+	return sortedStatements(statements), nil
+}
+
+func (p *Processor) selectGroup(c *Groups, pointers []string) (*Group, error) {
+	g := &Group{}
+
+	selection, err := SelectJSONLD(pointers, c.Skolemized)
+	if err != nil {
+		return nil, err
+	}
+
+	selected := map[string]bool{}
+
+	if selection != nil {
+		nquads, errRDF := p.ToNQuads(selection)
+		if errRDF != nil {
+			return nil, errRDF
+		}
+
+		g.DeskolemizedNQuads = deskolemize(nquads)
+
+		statements, errRelabel := relabelNQuads(g.DeskolemizedNQuads, c.LabelMap)
+		if errRelabel != nil {
+			return nil, errRelabel
+		}
+
+		for _, statement := range statements {
+			selected[statement] = true
+		}
+	}
+
+	for i, statement := range c.NQuads {
+		if selected[statement] {
+			g.Matching = append(g.Matching, i)
+		} else {
+			g.NonMatching = append(g.NonMatching, i)
+		}
+	}
+
+	return g, nil
+}
+
+func (p *Processor) ldOptions() *ld.JsonLdOptions {
+	opts := ld.NewJsonLdOptions("")
+	opts.ProcessingMode = ld.JsonLd_1_1
+	opts.ProduceGeneralizedRdf = true
+	opts.DocumentLoader = p.ldLoader
+
+	return opts
+}
+
+// skolemize replaces the blank nodes of the document with skolem IRIs, so that
+// the same blank nodes can be found in any selection from the document.
+func (p *Processor) skolemize(doc map[string]interface{}) (map[string]interface{}, error) {
+	proc := ld.NewJsonLdProcessor()
+
+	expanded, err := proc.Expand(doc, p.ldOptions())
+	if err != nil {
+		return nil, fmt.Errorf("expanding JSON-LD document: %w", err)
+	}
+
+	counter := 0
+
+	skolemizeNode(expanded, &counter)
+
+	compacted, err := proc.Compact(expanded, map[string]interface{}{ldCtxKey: doc[ldCtxKey]}, p.ldOptions())
+	if err != nil {
+		return nil, fmt.Errorf("compacting JSON-LD document: %w", err)
+	}
+
+	return keepTopLevelGraph(doc, compacted), nil
+}
+
+// keepTopLevelGraph restores the top-level @graph of the document, that the compaction drops when
+// the graph has a single node, so that the JSON pointers into the document match the skolemized one.
+func keepTopLevelGraph(doc, compacted map[string]interface{}) map[string]interface{} {
+	graph, ok := doc[ldGraphKey]
+	if !ok {
+		return compacted
+	}
+
+	if _, ok = compacted[ldGraphKey]; ok {
+		return compacted
+	}
+
+	node := make(map[string]interface{}, len(compacted))
+
+	for key, value := range compacted {
+		if key != ldCtxKey {
+			node[key] = value
+		}
+	}
+
+	out := map[string]interface{}{ldCtxKey: compacted[ldCtxKey]}
+
+	_, graphIsNode := graph.(map[string]interface{})
+
+	switch {
+	case len(node) == 0:
+		out[ldGraphKey] = []interface{}{}
+	case graphIsNode:
+		out[ldGraphKey] = node
+	default:
+		out[ldGraphKey] = []interface{}{node}
+	}
+
+	return out
+}
+
+func skolemizeNode(value interface{}, counter *int) {
+	switch v := value.(type) {
+	case []interface{}:
+		for _, e := range v {
+			skolemizeNode(e, counter)
+		}
+	case map[string]interface{}:
+		if _, ok := v["@value"]; ok {
+			return
+		}
+
+		_, hasID := v["@id"]
+		_, isList := v["@list"]
+		_, isSet := v["@set"]
+
+		if !hasID && !isList && !isSet {
+			v["@id"] = fmt.Sprintf("%s%s%d", skolemPrefix, skolemLabelPrefix, *counter)
+			*counter++
+		}
+
+		for _, e := range v {
+			skolemizeNode(e, counter)
+		}
+	}
+}
+
+// Unskolemize removes the skolem IRIs from the selected document, turning the nodes back into blank nodes.
+func Unskolemize(value interface{}) interface{} {
+	switch v := value.(type) {
+	case []interface{}:
+		for _, e := range v {
+			Unskolemize(e)
+		}
+	case map[string]interface{}:
+		for key, e := range v {
+			if id, ok := e.(string); ok && (key == "id" || key == "@id") && strings.HasPrefix(id, skolemPrefix) {
+				delete(v, key)
+
+				continue
+			}
+
+			Unskolemize(e)
+		}
+	}
+
+	return value
+}
+
+func deskolemize(nquads []string) []string {
+	out := make([]string, 0, len(nquads))
+
+	for _, nquad := range nquads {
+		out = append(out, skolemIRI.ReplaceAllString(nquad, blankNodePrefix+"$1"))
+	}
+
+	return out
+}
+
+// ToNQuads converts the JSON-LD document to n-quads.
+func (p *Processor) ToNQuads(doc map[string]interface{}) ([]string, error) {
+	opts := p.ldOptions()
+	opts.Format = nquadsFormat
+
+	view, err := ld.NewJsonLdProcessor().ToRDF(doc, opts)
+	if err != nil {
+		return nil, fmt.Errorf("converting JSON-LD document to n-quads: %w", err)
+	}
+
+	nquads, ok := view.(string)
+	if !ok {
+		return nil, errors.New("converting JSON-LD document to n-quads: invalid view")
+	}
+
+	return splitNQuads(nquads), nil
+}
+
+// CanonicalizeNQuads canonicalizes the statements with URDNA2015 and returns them sorted,
+// together with the map from the input blank node labels to the canonical ones.
+func CanonicalizeNQuads(nquads []string) ([]string, map[string]string, error) {
+	dataset, err := ld.ParseNQuads(strings.Join(nquads, ""))
+	if err != nil {
+		return nil, nil, fmt.Errorf("parsing n-quads: %w", err)
+	}
+
+	inputLabels := map[*ld.BlankNode]string{}
+
+	for _, quads := range dataset.Graphs {
+		for _, quad := range quads {
+			for _, node := range []ld.Node{quad.Subject, quad.Object, quad.Graph} {
+				if bn, ok := node.(*ld.BlankNode); ok {
+					inputLabels[bn] = bn.Attribute
+				}
+			}
+		}
+	}
+
+	view, err := ld.NewNormalisationAlgorithm(ld.AlgorithmURDNA2015, ld.MessageDigestAlgorithmSHA256).
+		Main(dataset, &ld.JsonLdOptions{Format: nquadsFormat})
+	if err != nil {
+		return nil, nil, fmt.Errorf("canonicalizing n-quads: %w", err)
+	}
+
+	canonical, ok := view.(string)
+	if !ok {
+		return nil, nil, errors.New("canonicalizing n-quads: invalid view")
+	}
+
+	idMap := make(map[string]string, len(inputLabels))
+
+	for bn, input := range inputLabels {
+		idMap[strings.TrimPrefix(input, blankNodePrefix)] = strings.TrimPrefix(bn.Attribute, blankNodePrefix)
+	}
+
+	return splitNQuads(canonical), idMap, nil
+}
+
+// relabelNQuads replaces the blank node labels of each statement using the label map.
+func relabelNQuads(nquads []string, labelMap map[string]string) ([]string, error) {
+	out := make([]string, 0, len(nquads))
+	serializer := &ld.NQuadRDFSerializer{}
+
+	for _, nquad := range nquads {
+		dataset, err := ld.ParseNQuads(nquad)
+		if err != nil {
+			return nil, fmt.Errorf("parsing n-quads: %w", err)
+		}
+
+		for _, quads := range dataset.Graphs {
+			for _, quad := range quads {
+				for _, node := range []ld.Node{quad.Subject, quad.Object, quad.Graph} {
+					bn, ok := node.(*ld.BlankNode)
+					if !ok {
+						continue
+					}
+
+					label, ok := labelMap[strings.TrimPrefix(bn.Attribute, blankNodePrefix)]
+					if !ok {
+						return nil, fmt.Errorf("unknown blank node label %s", bn.Attribute)
+					}
+
+					bn.Attribute = blankNodePrefix + label
+				}
+			}
+		}
+
+		relabeled, err := serializer.Serialize(dataset)
+		if err != nil {
+			return nil, err
+		}
+
+		out = append(out, relabeled.(string)) //nolint:forcetypeassert
+	}
+
+	return out, nil
+}
+
+func splitNQuads(nquads string) []string {
+	var out []string
+
+	for _, nquad := range strings.SplitAfter(nquads, "\n") {
+		if nquad != "" {
+			out = append(out, nquad)
+		}
+	}
+
+	return out
+}
+
+func sortedStatements(statements []string) []string {
+	sorted := append([]string{}, statements...)
+	sort.Strings(sorted)
+
+	return sorted
+}
+
+// sparseArray is an array of the selection with only the selected elements set.
+type sparseArray map[int]interface{}
+
+// SelectJSONLD returns the part of the JSON-LD document selected by the JSON pointers.
+// Objects on the path to a selected value keep their id and type.
+func SelectJSONLD(pointers []string, doc map[string]interface{}) (map[string]interface{}, error) {
+	if len(pointers) == 0 {
+		return nil, nil
+	}
+
+	selection := initialSelection(doc)
+	selection[ldCtxKey] = doc[ldCtxKey]
+
+	for _, pointer := range pointers {
+		paths, err := parsePointer(pointer)
+		if err != nil {
+			return nil, err
+		}
+
+		err = selectPaths(doc, paths, selection)
+		if err != nil {
+			return nil, fmt.Errorf("JSON pointer %q does not match document: %w", pointer, err)
+		}
+	}
+
+	return finalizeSelection(selection).(map[string]interface{}), nil //nolint:forcetypeassert
+}
+
+func parsePointer(pointer string) ([]string, error) {
+	if !strings.HasPrefix(pointer, "/") {
+		return nil, fmt.Errorf("invalid JSON pointer %q", pointer)
+	}
+
+	paths := strings.Split(pointer[1:], "/")
+
+	for i, path := range paths {
+		paths[i] = strings.ReplaceAll(strings.ReplaceAll(path, "~1", "/"), "~0", "~")
+	}
+
+	return paths, nil
+}
+
+func selectPaths(doc map[string]interface{}, paths []string, selection map[string]interface{}) error {
+	var (
+		value          interface{} = doc
+		selectedParent interface{}
+		selectedValue  interface{} = selection
+		err            error
+	)
+
+	for _, path := range paths {
+		selectedParent = selectedValue
+
+		value, err = child(value, path)
+		if err != nil {
+			return err
+		}
+
+		selectedValue = selectedChild(selectedParent, path)
+		if selectedValue == nil {
+			switch v := value.(type) {
+			case []interface{}:
+				selectedValue = sparseArray{}
+			case map[string]interface{}:
+				selectedValue = initialSelection(v)
+			default:
+				selectedValue = v
+			}
+
+			setSelectedChild(selectedParent, path, selectedValue)
+		}
+	}
+
+	if v, ok := value.(map[string]interface{}); ok {
+		if selected, isMap := selectedValue.(map[string]interface{}); isMap {
+			for key, e := range v {
+				selected[key] = deepCopy(e)
+			}
+
+			return nil
+		}
+	}
+
+	setSelectedChild(selectedParent, paths[len(paths)-1], deepCopy(value))
+
+	return nil
+}
+
+func child(value interface{}, path string) (interface{}, error) {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		e, ok := v[path]
+		if !ok {
+			return nil, fmt.Errorf("missing field %q", path)
+		}
+
+		return e, nil
+	case []interface{}:
+		i, err := strconv.Atoi(path)
+		if err != nil || i < 0 || i >= len(v) {
+			return nil, fmt.Errorf("invalid array index %q", path)
+		}
+
+		return v[i], nil
+	default:
+		return nil, fmt.Errorf("cannot select %q from a primitive value", path)
+	}
+}
+
+func selectedChild(parent interface{}, path string) interface{} {
+	switch p := parent.(type) {
+	case map[string]interface{}:
+		return p[path]
+	case sparseArray:
+		i, _ := strconv.Atoi(path) //nolint:errcheck // validated by child()
+
+		return p[i]
+	case []interface{}:
+		i, _ := strconv.Atoi(path) //nolint:errcheck // validated by child()
+
+		return p[i]
+	}
+
+	return nil
+}
+
+func setSelectedChild(parent interface{}, path string, value interface{}) {
+	switch p := parent.(type) {
+	case map[string]interface{}:
+		p[path] = value
+	case sparseArray:
+		i, _ := strconv.Atoi(path) //nolint:errcheck // validated by child()
+		p[i] = value
+	case []interface{}:
+		i, _ := strconv.Atoi(path) //nolint:errcheck // validated by child()
+		p[i] = value
+	}
+}
+
+func initialSelection(source map[string]interface{}) map[string]interface{} {
+	selection := map[string]interface{}{}
+
+	for _, key := range []string{"id", "@id"} {
+		if id, ok := source[key].(string); ok && !strings.HasPrefix(id, blankNodePrefix) {
+			selection[key] = id
+		}
+	}
+
+	for _, key := range []string{"type", "@type"} {
+		if t, ok := source[key]; ok {
+			selection[key] = deepCopy(t)
+		}
+	}
+
+	return selection
+}
+
+func finalizeSelection(value interface{}) interface{} {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		for key, e := range v {
+			v[key] = finalizeSelection(e)
+		}
+
+		return v
+	case sparseArray:
+		indexes := make([]int, 0, len(v))
+		for i := range v {
+			indexes = append(indexes, i)
+		}
+
+		sort.Ints(indexes)
+
+		out := make([]interface{}, 0, len(v))
+		for _, i := range indexes {
+			out = append(out, finalizeSelection(v[i]))
+		}
+
+		return out
+	case []interface{}:
+		for i, e := range v {
+			v[i] = finalizeSelection(e)
+		}
+
+		return v
+	}
+
+	return value
+}
+
+func deepCopy(value interface{}) interface{} {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		out := make(map[string]interface{}, len(v))
+		for key, e := range v {
+			out[key] = deepCopy(e)
+		}
+
+		return out
+	case []interface{}:
+		out := make([]interface{}, len(v))
+		for i, e := range v {
+			out[i] = deepCopy(e)
+		}
+
+		return out
+	}
+
+	return value
+}
+
+// IndexSet returns the set of the given indexes.
+func IndexSet(indexes []int) map[int]bool {
+	set := make(map[int]bool, len(indexes))
+
+	for _, i := range indexes {
+		set[i] = true
+	}
+
+	return set
+}
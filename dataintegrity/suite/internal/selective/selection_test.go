@@ -0,0 +1,47 @@
+/*
+Copyright Gen Digital Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package selective
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestSelectJSONLD(t *testing.T) {
+	doc := map[string]interface{}{
+		"@context": "https://www.w3.org/ns/credentials/v2",
+		"id":       "urn:root",
+		"type":     "Example",
+		"a": map[string]interface{}{
+			"id":   "_:b0",
+			"type": "A",
+			"b":    "foo",
+			"c":    "bar",
+		},
+		"list": []interface{}{"x", map[string]interface{}{"type": "Y", "y": "y"}, "z"},
+		"d/e":  "slash",
+	}
+
+	selection, err := SelectJSONLD([]string{"/a/b", "/list/1/y", "/list/2", "/d~1e"}, doc)
+	require.NoError(t, err)
+	require.Equal(t, map[string]interface{}{
+		"@context": "https://www.w3.org/ns/credentials/v2",
+		"id":       "urn:root",
+		"type":     "Example",
+		"a":        map[string]interface{}{"type": "A", "b": "foo"},
+		"list":     []interface{}{map[string]interface{}{"type": "Y", "y": "y"}, "z"},
+		"d/e":      "slash",
+	}, selection)
+
+	selection, err = SelectJSONLD(nil, doc)
+	require.NoError(t, err)
+	require.Nil(t, selection)
+
+	_, err = SelectJSONLD([]string{"/list/5"}, doc)
+	require.ErrorContains(t, err, "invalid array index")
+}
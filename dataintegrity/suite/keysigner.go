@@ -0,0 +1,104 @@
+/*
+Copyright Gen Digital Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package suite
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/trustbloc/kms-go/doc/jose/jwk"
+	kmsapi "github.com/trustbloc/kms-go/spi/kms"
+	wrapperapi "github.com/trustbloc/kms-go/wrapper/api"
+)
+
+// ErrKeyTypeMismatch is returned when the type of a KeySigner key is not the type of the
+// public key of the verification method it should sign for.
+var ErrKeyTypeMismatch = errors.New("signing key type doesn't match the verification method key")
+
+// KeySigner is the minimal crypto of a signing backend that keeps the private keys, eg AWS KMS,
+// Google Cloud KMS or an HSM. The suites sign with it through the WithKeySigner SignerGetter of
+// their package, so that a backend is implemented without changing the suites.
+type KeySigner interface {
+	// Sign signs data with the private key keyID. The ECDSA signatures must be IEEE P1363
+	// encoded, ie the concatenation of r and s, as set by the Data Integrity suites.
+	Sign(keyID string, data []byte) ([]byte, error)
+	// KeyType returns the type of the key keyID, eg kms.ECDSAP256TypeIEEEP1363 or kms.ED25519Type.
+	KeyType(keyID string) (kmsapi.KeyType, error)
+}
+
+// FixedKeySigner signs with a single key of a KeySigner.
+type FixedKeySigner struct {
+	signer KeySigner
+	keyID  string
+}
+
+// NewFixedKeySigner returns the signer of the key of signer matching the public key pub, which is
+// identified by the key ID of pub. It fails with ErrKeyTypeMismatch if the key is not of the type of pub.
+func NewFixedKeySigner(signer KeySigner, pub *jwk.JWK) (*FixedKeySigner, error) {
+	if pub == nil || pub.KeyID == "" {
+		return nil, errors.New("key signer needs the key ID of the public key JWK")
+	}
+
+	keyType, err := signer.KeyType(pub.KeyID)
+	if err != nil {
+		return nil, fmt.Errorf("get type of key %s: %w", pub.KeyID, err)
+	}
+
+	pubKeyType, err := pub.KeyType()
+	if err != nil {
+		return nil, fmt.Errorf("get type of public key %s: %w", pub.KeyID, err)
+	}
+
+	if keyType != pubKeyType {
+		return nil, fmt.Errorf("%w: key %s is %s, public key is %s", ErrKeyTypeMismatch, pub.KeyID, keyType, pubKeyType)
+	}
+
+	return &FixedKeySigner{signer: signer, keyID: pub.KeyID}, nil
+}
+
+// Sign signs msg with the key of the FixedKeySigner.
+func (s *FixedKeySigner) Sign(msg []byte) ([]byte, error) {
+	return s.signer.Sign(s.keyID, msg)
+}
+
+// KMSKeySigner is the KeySigner of the in-process kms-go crypto, signing with the keys of its KMS,
+// eg localkms, identified by their KMS key ID. It is the default KeySigner implementation.
+type KMSKeySigner struct {
+	suite      wrapperapi.Suite
+	keyCreator wrapperapi.KeyCreator
+}
+
+// NewKMSKeySigner creates a KMSKeySigner signing with the KMS and crypto of the kms-go suite,
+// eg of localsuite.NewLocalCryptoSuite.
+func NewKMSKeySigner(kmsSuite wrapperapi.Suite) (*KMSKeySigner, error) {
+	keyCreator, err := kmsSuite.KeyCreator()
+	if err != nil {
+		return nil, fmt.Errorf("get kms key creator: %w", err)
+	}
+
+	return &KMSKeySigner{suite: kmsSuite, keyCreator: keyCreator}, nil
+}
+
+// Sign signs data with the KMS key keyID.
+func (s *KMSKeySigner) Sign(keyID string, data []byte) ([]byte, error) {
+	signer, err := s.suite.FixedKeySigner(keyID)
+	if err != nil {
+		return nil, fmt.Errorf("get signer of key %s: %w", keyID, err)
+	}
+
+	return signer.Sign(data)
+}
+
+// KeyType returns the type of the KMS key keyID.
+func (s *KMSKeySigner) KeyType(keyID string) (kmsapi.KeyType, error) {
+	_, keyType, err := s.keyCreator.ExportPubKeyBytes(keyID)
+	if err != nil {
+		return "", fmt.Errorf("export public key %s: %w", keyID, err)
+	}
+
+	return keyType, nil
+}
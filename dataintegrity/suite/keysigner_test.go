@@ -0,0 +1,109 @@
+/*
+Copyright Gen Digital Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package suite
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"github.com/trustbloc/kms-go/doc/jose/jwk"
+	kmsapi "github.com/trustbloc/kms-go/spi/kms"
+
+	"github.com/trustbloc/vc-go/internal/testutil/kmscryptoutil"
+)
+
+type mockKeySigner struct {
+	keyTypes map[string]kmsapi.KeyType
+	signed   []string
+}
+
+func (m *mockKeySigner) Sign(keyID string, data []byte) ([]byte, error) {
+	m.signed = append(m.signed, keyID)
+
+	return append([]byte("signature of "), data...), nil
+}
+
+func (m *mockKeySigner) KeyType(keyID string) (kmsapi.KeyType, error) {
+	keyType, ok := m.keyTypes[keyID]
+	if !ok {
+		return "", errors.New("key not found")
+	}
+
+	return keyType, nil
+}
+
+func TestNewFixedKeySigner(t *testing.T) {
+	kmsSuite, err := kmscryptoutil.LocalKMSCryptoSuite()
+	require.NoError(t, err)
+
+	kmsCrypto, err := kmsSuite.KMSCrypto()
+	require.NoError(t, err)
+
+	pub, err := kmsCrypto.Create(kmsapi.ECDSAP256IEEEP1363)
+	require.NoError(t, err)
+
+	t.Run("KMS key signer", func(t *testing.T) {
+		keySigner, err := NewKMSKeySigner(kmsSuite)
+		require.NoError(t, err)
+
+		keyType, err := keySigner.KeyType(pub.KeyID)
+		require.NoError(t, err)
+		require.Equal(t, kmsapi.ECDSAP256TypeIEEEP1363, keyType)
+
+		signer, err := NewFixedKeySigner(keySigner, pub)
+		require.NoError(t, err)
+
+		sig, err := signer.Sign([]byte("message"))
+		require.NoError(t, err)
+		require.NoError(t, kmsCrypto.Verify(sig, []byte("message"), pub))
+
+		_, err = keySigner.KeyType("unknown")
+		require.Error(t, err)
+
+		_, err = keySigner.Sign("unknown", []byte("message"))
+		require.Error(t, err)
+	})
+
+	t.Run("custom key signer", func(t *testing.T) {
+		keySigner := &mockKeySigner{keyTypes: map[string]kmsapi.KeyType{pub.KeyID: kmsapi.ECDSAP256TypeIEEEP1363}}
+
+		signer, err := NewFixedKeySigner(keySigner, pub)
+		require.NoError(t, err)
+
+		sig, err := signer.Sign([]byte("message"))
+		require.NoError(t, err)
+		require.Equal(t, "signature of message", string(sig))
+		require.Equal(t, []string{pub.KeyID}, keySigner.signed)
+	})
+
+	t.Run("failure", func(t *testing.T) {
+		keySigner := &mockKeySigner{keyTypes: map[string]kmsapi.KeyType{pub.KeyID: kmsapi.ECDSAP384TypeIEEEP1363}}
+
+		_, err := NewFixedKeySigner(keySigner, pub)
+		require.ErrorIs(t, err, ErrKeyTypeMismatch)
+
+		_, err = NewFixedKeySigner(&mockKeySigner{}, pub)
+		require.ErrorContains(t, err, "key not found")
+
+		withoutKeyID := *pub
+		withoutKeyID.KeyID = ""
+
+		_, err = NewFixedKeySigner(keySigner, &withoutKeyID)
+		require.ErrorContains(t, err, "key signer needs the key ID of the public key JWK")
+
+		_, err = NewFixedKeySigner(keySigner, nil)
+		require.Error(t, err)
+
+		unknownKey := &jwk.JWK{}
+		unknownKey.KeyID = "kid"
+
+		_, err = NewFixedKeySigner(&mockKeySigner{keyTypes: map[string]kmsapi.KeyType{"kid": kmsapi.ED25519Type}},
+			unknownKey)
+		require.ErrorContains(t, err, "get type of public key kid")
+	})
+}
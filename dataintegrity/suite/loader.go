@@ -0,0 +1,69 @@
+/*
+Copyright Gen Digital Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package suite
+
+import (
+	"context"
+
+	"github.com/piprate/json-gold/ld"
+)
+
+// ContextDocumentLoader is implemented by the JSON-LD document loaders that can bind the loading
+// of a document, eg its HTTP request, to a context.
+type ContextDocumentLoader interface {
+	LoadDocumentContext(ctx context.Context, u string) (*ld.RemoteDocument, error)
+}
+
+// DocumentLoaderWithContext returns a loader loading the documents with loader until ctx is done.
+// A loader implementing ContextDocumentLoader is given ctx, the loading of the other loaders is
+// abandoned when ctx is done: the call returns the error of ctx, while the loading itself goes
+// on in the background until the loader returns.
+func DocumentLoaderWithContext(ctx context.Context, loader ld.DocumentLoader) ld.DocumentLoader {
+	if loader == nil || ctx.Done() == nil {
+		return loader
+	}
+
+	return &contextDocumentLoader{ctx: ctx, loader: loader}
+}
+
+type contextDocumentLoader struct {
+	ctx    context.Context
+	loader ld.DocumentLoader
+}
+
+type loadResult struct {
+	doc *ld.RemoteDocument
+	err error
+}
+
+func (l *contextDocumentLoader) LoadDocument(u string) (*ld.RemoteDocument, error) {
+	return l.LoadDocumentContext(l.ctx, u)
+}
+
+func (l *contextDocumentLoader) LoadDocumentContext(ctx context.Context, u string) (*ld.RemoteDocument, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	if loader, ok := l.loader.(ContextDocumentLoader); ok {
+		return loader.LoadDocumentContext(ctx, u)
+	}
+
+	result := make(chan loadResult, 1)
+
+	go func() {
+		doc, err := l.loader.LoadDocument(u)
+		result <- loadResult{doc: doc, err: err}
+	}()
+
+	select {
+	case r := <-result:
+		return r.doc, r.err
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
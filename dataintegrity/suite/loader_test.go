@@ -0,0 +1,99 @@
+/*
+Copyright Gen Digital Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package suite
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/piprate/json-gold/ld"
+	"github.com/stretchr/testify/require"
+)
+
+type loaderFunc func(u string) (*ld.RemoteDocument, error)
+
+func (f loaderFunc) LoadDocument(u string) (*ld.RemoteDocument, error) {
+	return f(u)
+}
+
+type contextLoaderFunc func(ctx context.Context, u string) (*ld.RemoteDocument, error)
+
+func (f contextLoaderFunc) LoadDocument(u string) (*ld.RemoteDocument, error) {
+	return f(context.Background(), u)
+}
+
+func (f contextLoaderFunc) LoadDocumentContext(ctx context.Context, u string) (*ld.RemoteDocument, error) {
+	return f(ctx, u)
+}
+
+func TestDocumentLoaderWithContext(t *testing.T) {
+	const contextURL = "https://example.com/context"
+
+	loader := loaderFunc(func(u string) (*ld.RemoteDocument, error) {
+		return &ld.RemoteDocument{DocumentURL: u}, nil
+	})
+
+	t.Run("background context", func(t *testing.T) {
+		require.Nil(t, DocumentLoaderWithContext(context.Background(), nil))
+
+		wrapped := DocumentLoaderWithContext(context.Background(), loader)
+
+		doc, err := wrapped.LoadDocument(contextURL)
+		require.NoError(t, err)
+		require.Equal(t, contextURL, doc.DocumentURL)
+	})
+
+	t.Run("loaded until done", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+
+		wrapped := DocumentLoaderWithContext(ctx, loader)
+
+		doc, err := wrapped.LoadDocument(contextURL)
+		require.NoError(t, err)
+		require.Equal(t, contextURL, doc.DocumentURL)
+
+		cancel()
+
+		_, err = wrapped.LoadDocument(contextURL)
+		require.ErrorIs(t, err, context.Canceled)
+	})
+
+	t.Run("slow loader", func(t *testing.T) {
+		unblock := make(chan struct{})
+		defer close(unblock)
+
+		ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+		defer cancel()
+
+		_, err := DocumentLoaderWithContext(ctx, loaderFunc(func(u string) (*ld.RemoteDocument, error) {
+			<-unblock
+
+			return &ld.RemoteDocument{DocumentURL: u}, nil
+		})).LoadDocument(contextURL)
+		require.ErrorIs(t, err, context.DeadlineExceeded)
+	})
+
+	t.Run("context loader", func(t *testing.T) {
+		type ctxKey struct{}
+
+		var loadCtx context.Context
+
+		ctx, cancel := context.WithCancel(context.WithValue(context.Background(), ctxKey{}, "value"))
+		defer cancel()
+
+		loader := contextLoaderFunc(func(ctx context.Context, u string) (*ld.RemoteDocument, error) {
+			loadCtx = ctx
+
+			return &ld.RemoteDocument{DocumentURL: u}, nil
+		})
+
+		_, err := DocumentLoaderWithContext(ctx, loader).LoadDocument(contextURL)
+		require.NoError(t, err)
+		require.Equal(t, "value", loadCtx.Value(ctxKey{}))
+	})
+}
@@ -0,0 +1,113 @@
+/*
+Copyright Gen Digital Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package suite
+
+import (
+	"sync"
+
+	"golang.org/x/exp/maps"
+	"golang.org/x/exp/slices"
+)
+
+var (
+	registryMu          sync.RWMutex
+	registeredSigners   = map[string]SignerInitializer{}
+	registeredVerifiers = map[string]VerifierInitializer{}
+)
+
+// RegisterSigner registers a SignerInitializer under each of its suite types, replacing the
+// initializer registered before under the same type. The data integrity Signer looks up the
+// registered suites by type for the suite types that are not provided to it explicitly.
+//
+// The built-in suites are not registered for signing, as they sign with a key given as an option.
+// A SignerInitializer is typically registered once, at program start, eg in an init function of the
+// package implementing the suite.
+func RegisterSigner(initializer SignerInitializer) {
+	registryMu.Lock()
+	defer registryMu.Unlock()
+
+	for _, suiteType := range initializer.Type() {
+		registeredSigners[suiteType] = initializer
+	}
+}
+
+// RegisterVerifier registers a VerifierInitializer under each of its suite types, replacing the
+// initializer registered before under the same type. The data integrity Verifier looks up the
+// registered suites by type for the suite types that are not provided to it explicitly.
+//
+// The packages of the built-in suites register their verification suite, loading the JSON-LD
+// contexts from the embedded bundle of the ldcontext package, when they are imported.
+func RegisterVerifier(initializer VerifierInitializer) {
+	registryMu.Lock()
+	defer registryMu.Unlock()
+
+	for _, suiteType := range initializer.Type() {
+		registeredVerifiers[suiteType] = initializer
+	}
+}
+
+// UnregisterSigner removes the SignerInitializers registered under the given suite types.
+func UnregisterSigner(suiteTypes ...string) {
+	registryMu.Lock()
+	defer registryMu.Unlock()
+
+	for _, suiteType := range suiteTypes {
+		delete(registeredSigners, suiteType)
+	}
+}
+
+// UnregisterVerifier removes the VerifierInitializers registered under the given suite types.
+func UnregisterVerifier(suiteTypes ...string) {
+	registryMu.Lock()
+	defer registryMu.Unlock()
+
+	for _, suiteType := range suiteTypes {
+		delete(registeredVerifiers, suiteType)
+	}
+}
+
+// LookupSigner returns the SignerInitializer registered under suiteType, if any.
+func LookupSigner(suiteType string) (SignerInitializer, bool) {
+	registryMu.RLock()
+	defer registryMu.RUnlock()
+
+	initializer, ok := registeredSigners[suiteType]
+
+	return initializer, ok
+}
+
+// LookupVerifier returns the VerifierInitializer registered under suiteType, if any.
+func LookupVerifier(suiteType string) (VerifierInitializer, bool) {
+	registryMu.RLock()
+	defer registryMu.RUnlock()
+
+	initializer, ok := registeredVerifiers[suiteType]
+
+	return initializer, ok
+}
+
+// RegisteredSignerTypes returns the sorted suite types with a registered SignerInitializer.
+func RegisteredSignerTypes() []string {
+	registryMu.RLock()
+	defer registryMu.RUnlock()
+
+	suiteTypes := maps.Keys(registeredSigners)
+	slices.Sort(suiteTypes)
+
+	return suiteTypes
+}
+
+// RegisteredVerifierTypes returns the sorted suite types with a registered VerifierInitializer.
+func RegisteredVerifierTypes() []string {
+	registryMu.RLock()
+	defer registryMu.RUnlock()
+
+	suiteTypes := maps.Keys(registeredVerifiers)
+	slices.Sort(suiteTypes)
+
+	return suiteTypes
+}
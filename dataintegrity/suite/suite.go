@@ -7,8 +7,11 @@ SPDX-License-Identifier: Apache-2.0
 package suite
 
 import (
+	"context"
 	"errors"
 
+	"github.com/piprate/json-gold/ld"
+
 	"github.com/trustbloc/vc-go/dataintegrity/models"
 )
 
@@ -29,6 +32,15 @@ type Signer interface {
 	RequiresCreated
 }
 
+// ProofPreparer is implemented by cryptographic suites that can create proofs
+// from signatures made outside of the suite: PrepareProof performs the transform
+// and hash steps of the data integrity Add Proof algorithm, and returns the data
+// to sign with the verification method key, and the function generating the proof
+// from its signature.
+type ProofPreparer interface {
+	PrepareProof(doc []byte, opts *models.ProofOptions) ([]byte, func(sig []byte) (*models.Proof, error), error)
+}
+
 // Verifier is an implementation of a data integrity cryptographic suite that
 // provides the transform, hash, and proof verification steps of the data
 // integrity verify Proof algorithm.
@@ -40,6 +52,12 @@ type Verifier interface {
 	RequiresCreated
 }
 
+// ContextVerifier is implemented by the Verifier suites that load JSON-LD contexts, so that
+// the loading is canceled with ctx, or stops at its deadline, see DocumentLoaderWithContext.
+type ContextVerifier interface {
+	VerifyProofContext(ctx context.Context, doc []byte, proof *models.Proof, opts *models.ProofOptions) error
+}
+
 // Suite implements a data integrity cryptographic suite for both proof creation
 // and proof verification.
 type Suite interface {
@@ -47,6 +65,29 @@ type Suite interface {
 	Verifier
 }
 
+// Deriver is an implementation of a data integrity cryptographic suite that
+// supports selective disclosure: it derives, from a base proof, a proof that
+// reveals only a part of the signed document.
+type Deriver interface {
+	// DeriveProof returns the document reduced to the mandatory statements of the
+	// base proof and the statements selected by the given JSON pointers, together
+	// with the derived proof for the reduced document.
+	DeriveProof(doc []byte, proof *models.Proof, selectivePointers []string) ([]byte, *models.Proof, error)
+}
+
+// LDDocumentLoaderWrapper is implemented by cryptographic suites that load JSON-LD
+// contexts, so that their document loader can be wrapped, eg with a cache.
+type LDDocumentLoaderWrapper interface {
+	WrapLDDocumentLoader(wrap func(loader ld.DocumentLoader) ld.DocumentLoader)
+}
+
+// PurposeRestrictor is implemented by cryptographic suites that can only create
+// proofs for some proof purposes. Suites that don't implement it support all the
+// proof purposes known to the data integrity Signer.
+type PurposeRestrictor interface {
+	SupportedPurposes() []string
+}
+
 // Type provides a method that returns the cryptographic suite type of the
 // corresponding suite. Each suite has a type constant that's defined in its
 // associated specification.
@@ -68,6 +109,13 @@ type VerifierInitializer interface {
 	Type
 }
 
+// DeriverInitializer initializes a Deriver, using initialization options that
+// were passed into the DeriverInitializer's creation.
+type DeriverInitializer interface {
+	Deriver() (Deriver, error)
+	Type
+}
+
 var (
 	// ErrInvalidProof is returned by Verifier.VerifyProof when the given proof is
 	// invalid.
@@ -26,6 +26,11 @@ type mockSuite struct {
 	CreateProofVal *models.Proof
 	CreateProofErr error
 	VerifyProofErr error
+	PurposesVal    []string
+
+	createProofOpts  *models.ProofOptions
+	verifyProofOpts  *models.ProofOptions
+	verifyProofCalls int
 }
 
 var _ suite.Suite = &mockSuite{}
@@ -114,7 +119,9 @@ func deepEqObj(obj1, obj2 map[string]gjson.Result) bool {
 	return true
 }
 
-func (m *mockSuite) CreateProof([]byte, *models.ProofOptions) (*models.Proof, error) {
+func (m *mockSuite) CreateProof(_ []byte, opts *models.ProofOptions) (*models.Proof, error) {
+	m.createProofOpts = opts
+
 	return m.CreateProofVal, m.CreateProofErr
 }
 
@@ -122,7 +129,18 @@ func (m *mockSuite) RequiresCreated() bool {
 	return m.ReqCreatedVal
 }
 
-func (m *mockSuite) VerifyProof([]byte, *models.Proof, *models.ProofOptions) error {
+func (m *mockSuite) SupportedPurposes() []string {
+	if m.PurposesVal == nil {
+		return SupportedPurposes()
+	}
+
+	return m.PurposesVal
+}
+
+func (m *mockSuite) VerifyProof(_ []byte, _ *models.Proof, opts *models.ProofOptions) error {
+	m.verifyProofCalls++
+	m.verifyProofOpts = opts
+
 	return m.VerifyProofErr
 }
 
@@ -150,7 +168,7 @@ type mockResolver struct {
 	err error
 }
 
-var _ didResolver = mockResolver{}
+var _ DIDResolver = mockResolver{}
 
 func (m mockResolver) Resolve(id string, opts ...vdrapi.DIDMethodOption) (*did.DocResolution, error) {
 	if m.err != nil {
@@ -160,6 +178,12 @@ func (m mockResolver) Resolve(id string, opts ...vdrapi.DIDMethodOption) (*did.D
 	return makeMockDIDResolution(id, m.vm, m.vr), nil
 }
 
+type didResolverFunc func(id string) (*did.DocResolution, error)
+
+func (f didResolverFunc) Resolve(id string, opts ...vdrapi.DIDMethodOption) (*did.DocResolution, error) {
+	return f(id)
+}
+
 func makeMockDIDResolution(id string, vm *did.VerificationMethod, vr did.VerificationRelationship) *did.DocResolution {
 	ver := []did.Verification{{
 		VerificationMethod: *vm,
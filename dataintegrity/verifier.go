@@ -7,12 +7,22 @@ SPDX-License-Identifier: Apache-2.0
 package dataintegrity
 
 import (
+	"context"
+	"crypto"
+	"crypto/sha256"
 	"encoding/json"
 	"errors"
+	"fmt"
+	"strings"
 	"time"
 
+	lru "github.com/hashicorp/golang-lru/v2"
+	"github.com/piprate/json-gold/ld"
 	"github.com/tidwall/gjson"
 	"github.com/tidwall/sjson"
+	"github.com/trustbloc/did-go/doc/did"
+	"github.com/trustbloc/kms-go/doc/jose/jwk/jwksupport"
+	"golang.org/x/exp/slices"
 
 	"github.com/trustbloc/vc-go/dataintegrity/models"
 	"github.com/trustbloc/vc-go/dataintegrity/suite"
@@ -26,11 +36,20 @@ const (
 // data integrity specification, using a set of provided cryptographic suites.
 type Verifier struct {
 	suites   map[string]suite.Verifier
-	resolver didResolver
+	resolver DIDResolver
+	algo     models.CanonicalizationAlgorithm
+	verified *lru.Cache[[sha256.Size]byte, time.Time]
+	// relationshipChecks are the proof purposes with the verification relationship check.
+	relationshipChecks []string
+	// selectAlgorithm selects the signature algorithm of a proof from its suite and the key of its
+	// verification method.
+	selectAlgorithm SignatureAlgorithmSelector
 }
 
 // NewVerifier initializes a Verifier that supports using the provided
-// cryptographic suites to perform data integrity verification.
+// cryptographic suites, and the suites registered with suite.RegisterVerifier,
+// to perform data integrity verification. The built-in suites are registered by
+// their packages once imported, eg github.com/trustbloc/vc-go/dataintegrity/suite/eddsa2022.
 func NewVerifier(opts *Options, suites ...suite.VerifierInitializer) (*Verifier, error) {
 	if opts == nil {
 		opts = &Options{}
@@ -39,26 +58,120 @@ func NewVerifier(opts *Options, suites ...suite.VerifierInitializer) (*Verifier,
 	verifier := &Verifier{
 		suites:   map[string]suite.Verifier{},
 		resolver: opts.DIDResolver,
+		algo:     opts.canonicalizationAlgorithm,
+
+		relationshipChecks: opts.relationshipCheckPurposes,
+		selectAlgorithm:    opts.algorithmSelector,
+	}
+
+	if verifier.selectAlgorithm == nil {
+		verifier.selectAlgorithm = SignatureAlgorithm
+	}
+
+	if opts.verificationCacheSize > 0 {
+		cache, err := lru.New[[sha256.Size]byte, time.Time](opts.verificationCacheSize)
+		if err != nil {
+			return nil, err
+		}
+
+		verifier.verified = cache
+	}
+
+	wrapLoader, err := contextCacheWrapper(opts.contextCacheSize)
+	if err != nil {
+		return nil, err
 	}
 
 	for _, initializer := range suites {
 		for _, suiteType := range initializer.Type() {
-			if _, ok := verifier.suites[suiteType]; ok {
-				continue
+			if err = verifier.addSuite(suiteType, initializer, wrapLoader); err != nil {
+				return nil, err
 			}
+		}
+	}
 
-			verifierSuite, err := initializer.Verifier()
-			if err != nil {
+	// The suites registered with suite.RegisterVerifier are looked up for the other suite types,
+	// so that the given suites take precedence.
+	for _, suiteType := range suite.RegisteredVerifierTypes() {
+		if initializer, ok := suite.LookupVerifier(suiteType); ok {
+			if err = verifier.addSuite(suiteType, initializer, wrapLoader); err != nil {
 				return nil, err
 			}
-
-			verifier.suites[suiteType] = verifierSuite
 		}
 	}
 
 	return verifier, nil
 }
 
+// addSuite initializes the suite of suiteType with initializer, unless the Verifier has one already.
+func (v *Verifier) addSuite(
+	suiteType string,
+	initializer suite.VerifierInitializer,
+	wrapLoader func(loader ld.DocumentLoader) ld.DocumentLoader,
+) error {
+	if _, ok := v.suites[suiteType]; ok {
+		return nil
+	}
+
+	verifierSuite, err := initializer.Verifier()
+	if err != nil {
+		return err
+	}
+
+	if loaderWrapper, ok := verifierSuite.(suite.LDDocumentLoaderWrapper); ok && wrapLoader != nil {
+		loaderWrapper.WrapLDDocumentLoader(wrapLoader)
+	}
+
+	v.suites[suiteType] = verifierSuite
+
+	return nil
+}
+
+// contextCacheWrapper returns the wrapper of the document loaders of the suites that makes them
+// share a cache of up to size JSON-LD contexts, nil if size is not positive.
+func contextCacheWrapper(size int) (func(loader ld.DocumentLoader) ld.DocumentLoader, error) {
+	if size <= 0 {
+		return nil, nil
+	}
+
+	cache, err := lru.New[string, *ld.RemoteDocument](size)
+	if err != nil {
+		return nil, err
+	}
+
+	return func(loader ld.DocumentLoader) ld.DocumentLoader {
+		return &cachingDocumentLoader{loader: loader, cache: cache}
+	}, nil
+}
+
+// cachingDocumentLoader caches the documents loaded by the wrapped loader. The cache is
+// safe for concurrent use and may be shared by several loaders.
+type cachingDocumentLoader struct {
+	loader ld.DocumentLoader
+	cache  *lru.Cache[string, *ld.RemoteDocument]
+}
+
+func (l *cachingDocumentLoader) LoadDocument(u string) (*ld.RemoteDocument, error) {
+	return l.LoadDocumentContext(context.Background(), u)
+}
+
+// LoadDocumentContext returns the cached document, or loads it with the wrapped loader until ctx is done,
+// implements suite.ContextDocumentLoader.
+func (l *cachingDocumentLoader) LoadDocumentContext(ctx context.Context, u string) (*ld.RemoteDocument, error) {
+	if doc, ok := l.cache.Get(u); ok {
+		return doc, nil
+	}
+
+	doc, err := suite.DocumentLoaderWithContext(ctx, l.loader).LoadDocument(u)
+	if err != nil {
+		return nil, err
+	}
+
+	l.cache.Add(u, doc)
+
+	return doc, nil
+}
+
 var (
 	// ErrMissingProof is returned when Verifier.VerifyProof() is given a document
 	// without a data integrity proof field.
@@ -77,60 +190,296 @@ var (
 	// ErrOutOfDate is returned when Verifier.VerifyProof() is given a document with
 	// a proof that was created more than models.ProofOptions.MaxAge seconds ago.
 	ErrOutOfDate = errors.New("data integrity proof out of date")
+	// ErrExpired is returned when Verifier.VerifyProof() is given a document with
+	// a proof whose expires time is more than models.ProofOptions.ExpiresTolerance
+	// in the past. It wraps ErrOutOfDate.
+	ErrExpired = fmt.Errorf("data integrity proof expired: %w", ErrOutOfDate)
 	// ErrInvalidDomain is returned when Verifier.VerifyProof() is given a document
-	// with a proof without the expected domain.
+	// with a proof without the expected domain, or without one of the accepted domains.
+	// The returned error wraps it with the expected and the actual domain.
 	ErrInvalidDomain = errors.New("data integrity proof has invalid domain")
 	// ErrInvalidChallenge is returned when Verifier.VerifyProof() is given a
-	// document with a proof without the expected challenge.
+	// document with a proof without the expected challenge. The returned error wraps
+	// it with the expected and the actual challenge: a challenge is a nonce against
+	// replay, not a secret, so it is safe to include it in error messages and logs.
 	ErrInvalidChallenge = errors.New("data integrity proof has invalid challenge")
+	// ErrInvalidNonce is returned when Verifier.VerifyProof() is given a document with
+	// a proof without the expected nonce. The returned error wraps it with the expected
+	// and the actual nonce.
+	ErrInvalidNonce = errors.New("data integrity proof has invalid nonce")
+	// ErrCreatedInFuture is returned when Verifier.VerifyProof() is given a document
+	// with a proof that was created later than models.ProofOptions.CreatedTolerance
+	// from now, unless models.ProofOptions.AllowFutureCreated is set.
+	ErrCreatedInFuture = errors.New("data integrity proof created in the future")
 )
 
 // VerifyProof verifies the data integrity proof on the given JSON document,
 // returning an error if proof verification fails, and nil if verification
 // succeeds.
+//
+// All the proofs of a proof set must verify. A proof chained to a previous proof
+// (previousProof) is verified over the document together with the previous proof,
+// which must precede it in the proof set, else VerifyProof returns ErrInvalidProofChain.
+// With opts.ProofStructure, the proofs must moreover be a proof set of independent proofs,
+// or a proof chain with each proof chained to the proof preceding it, else VerifyProof
+// returns ErrInvalidProofChain.
+//
+// A verificationMethod given as a relative DID URL (eg "#key-1") is resolved against the
+// issuer of the document, else its holder, else its id.
+//
+// A proof of a cryptographic suite of this module whose verification method key is of another
+// key type, eg an Ed25519 key for an ecdsa-rdfc-2019 proof, is rejected with ErrIncompatibleKeyType.
 func (v *Verifier) VerifyProof(doc []byte, opts *models.ProofOptions) error {
+	return v.VerifyProofContext(context.Background(), doc, opts)
+}
+
+// VerifyProofContext verifies the data integrity proof on the given JSON document as VerifyProof,
+// until ctx is done: the DID resolutions and the loading of the JSON-LD contexts are bound to ctx,
+// so that a slow DID endpoint or context server makes the verification fail at the ctx deadline
+// with its error, eg context.DeadlineExceeded.
+//
+// The DID resolver and the document loaders of the suites get ctx when they implement
+// ContextDIDResolver and suite.ContextDocumentLoader. Else, their calls are abandoned when ctx
+// is done, but go on in the background until they return.
+func (v *Verifier) VerifyProofContext(ctx context.Context, doc []byte, opts *models.ProofOptions) error {
+	_, err := v.verifyProofSet(ctx, doc, opts, nil)
+
+	return err
+}
+
+// ProofVerificationDetail is the metadata of a proof verified by VerifyProofDetailed.
+type ProofVerificationDetail struct {
+	// SignerDID is the controller of the verification method of the proof.
+	SignerDID string
+	// VerificationMethodID is the ID of the verification method the proof is verified with,
+	// the key ID, resolved against the document if the proof has a relative DID URL.
+	VerificationMethodID string
+	// CryptoSuite is the cryptographic suite of the proof.
+	CryptoSuite string
+	// Created is the created time of the proof, zero if the proof has none.
+	Created time.Time
+	// MatchedVerificationMethodID is the ID of the assertionMethod key of the controller that verified
+	// the proof with TryAllAssertionKeys, when the verification method of the proof didn't. It is
+	// empty if the proof is verified with the key of its verification method.
+	MatchedVerificationMethodID string
+	// ProofSet holds the details of all the proofs of the document, in the order of the proof
+	// set, as all of them are verified. The fields above are those of the first proof.
+	ProofSet []*ProofVerificationDetail
+}
+
+// VerifyProofDetailed verifies the data integrity proofs on the given JSON document as VerifyProof,
+// and returns the metadata of the verified proofs on success.
+func (v *Verifier) VerifyProofDetailed(doc []byte, opts *models.ProofOptions) (*ProofVerificationDetail, error) {
+	return v.VerifyProofDetailedContext(context.Background(), doc, opts)
+}
+
+// VerifyProofDetailedContext verifies the data integrity proofs as VerifyProofDetailed, until ctx is
+// done as VerifyProofContext.
+func (v *Verifier) VerifyProofDetailedContext(
+	ctx context.Context,
+	doc []byte,
+	opts *models.ProofOptions,
+) (*ProofVerificationDetail, error) {
+	details, err := v.verifyProofSet(ctx, doc, opts, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	detail := *details[0]
+	detail.ProofSet = details
+
+	return &detail, nil
+}
+
+// VerifyProofWithKey verifies the data integrity proofs on the given JSON document,
+// created with the cryptographic suite suiteType, with the given public key (eg
+// *ecdsa.PublicKey or ed25519.PublicKey) instead of the key of their verification
+// method, which is not resolved. It is meant for callers that already know the key
+// of the issuer, eg pinned in their configuration.
+//
+// The proofs are checked as with VerifyProof, except that their purpose, domain and
+// challenge are not checked against expected values. A proof of another cryptographic
+// suite than suiteType fails with ErrUnsupportedSuite.
+func (v *Verifier) VerifyProofWithKey(doc []byte, key crypto.PublicKey, suiteType string) error {
+	return v.VerifyProofWithKeyContext(context.Background(), doc, key, suiteType)
+}
+
+// VerifyProofWithKeyContext verifies the data integrity proofs with the given public key as
+// VerifyProofWithKey, with the JSON-LD contexts loaded until ctx is done as VerifyProofContext.
+func (v *Verifier) VerifyProofWithKeyContext(
+	ctx context.Context,
+	doc []byte,
+	key crypto.PublicKey,
+	suiteType string,
+) error {
+	vm, err := keyVerificationMethod("", key)
+	if err != nil {
+		return err
+	}
+
+	_, err = v.verifyProofSet(ctx, doc, &models.ProofOptions{
+		ProofType: models.DataIntegrityProof,
+		SuiteType: suiteType,
+	}, &pinnedKeys{
+		lookup: func(string) (*models.VerificationMethod, error) {
+			return vm, nil
+		},
+	})
+
+	return err
+}
+
+// KeyLookup returns the public key (eg *ecdsa.PublicKey or ed25519.PublicKey) of the verification
+// method with the ID vmID, and whether the key is known.
+type KeyLookup func(vmID string) (crypto.PublicKey, bool)
+
+// VerifyProofSignature verifies only the signatures of the data integrity proofs on the given JSON
+// document, with the keys returned by keyLookup for their verification methods. It is meant for a
+// cheap pre-filter rejecting the documents whose proofs don't verify against locally trusted keys,
+// before their full verification.
+//
+// This is not a full verification of the proofs: the verification methods are not resolved, so that
+// neither the DID of the key nor its verification relationship for the proof purpose is checked, and
+// the purpose, domain, challenge, created and expires time of the proofs are not checked either. A
+// document must still be verified with VerifyProof to be trusted.
+//
+// A proof whose verification method key is not found by keyLookup fails with ErrVMResolution; the
+// proofs are otherwise checked as with VerifyProof, eg chained proofs.
+func (v *Verifier) VerifyProofSignature(doc []byte, keyLookup KeyLookup) error {
+	return v.VerifyProofSignatureContext(context.Background(), doc, keyLookup)
+}
+
+// VerifyProofSignatureContext verifies only the signatures of the data integrity proofs as
+// VerifyProofSignature, with the JSON-LD contexts loaded until ctx is done as VerifyProofContext.
+func (v *Verifier) VerifyProofSignatureContext(ctx context.Context, doc []byte, keyLookup KeyLookup) error {
+	_, err := v.verifyProofSet(ctx, doc, &models.ProofOptions{
+		ProofType: models.DataIntegrityProof,
+	}, &pinnedKeys{
+		lookup: func(vmID string) (*models.VerificationMethod, error) {
+			key, ok := keyLookup(vmID)
+			if !ok {
+				return nil, fmt.Errorf("%w: key of %s is not known", ErrVMResolution, vmID)
+			}
+
+			return keyVerificationMethod(vmID, key)
+		},
+		signatureOnly: true,
+	})
+
+	return err
+}
+
+// keyVerificationMethod returns a verification method with the ID vmID and the public key.
+func keyVerificationMethod(vmID string, key crypto.PublicKey) (*models.VerificationMethod, error) {
+	keyJWK, err := jwksupport.JWKFromKey(key)
+	if err != nil {
+		return nil, fmt.Errorf("convert public key to JWK: %w", err)
+	}
+
+	controller, _, _ := strings.Cut(vmID, "#")
+
+	vm, err := did.NewVerificationMethodFromJWK(vmID, "JsonWebKey2020", controller, keyJWK)
+	if err != nil {
+		return nil, fmt.Errorf("create verification method: %w", err)
+	}
+
+	return vm, nil
+}
+
+// pinnedKeys are the keys the proofs are verified with, instead of the keys of their resolved
+// verification methods.
+type pinnedKeys struct {
+	// lookup returns the verification method holding the key of the verification method vmID.
+	lookup func(vmID string) (*models.VerificationMethod, error)
+	// signatureOnly makes only the signature of the proofs checked, of any cryptographic suite,
+	// without their created and expires times.
+	signatureOnly bool
+}
+
+// verifyProofSet verifies all the proofs on doc and returns their details. With pinned keys,
+// the proofs are verified with the keys of their lookup, whatever their verification method.
+func (v *Verifier) verifyProofSet(
+	ctx context.Context,
+	doc []byte,
+	opts *models.ProofOptions,
+	pinned *pinnedKeys,
+) ([]*ProofVerificationDetail, error) {
 	proofRaw := gjson.GetBytes(doc, proofPath)
 
 	if !proofRaw.Exists() {
-		return ErrMissingProof
+		return nil, ErrMissingProof
 	}
 
 	unsecuredDoc, err := sjson.DeleteBytes(doc, proofPath)
 	if err != nil {
-		return ErrMalformedProof
+		return nil, ErrMalformedProof
 	}
 
-	for _, proof := range proofRaw.Array() {
-		if err = v.verifyProof([]byte(proof.Raw), unsecuredDoc, opts); err != nil {
-			return err
+	proofs := proofRaw.Array()
+
+	err = checkProofStructure(proofs, opts.ProofStructure)
+	if err != nil {
+		return nil, err
+	}
+
+	details := make([]*ProofVerificationDetail, 0, len(proofs))
+
+	for i, proof := range proofs {
+		proofDoc := unsecuredDoc
+
+		previousProof := proof.Get("previousProof").String()
+		if previousProof != "" {
+			proofDoc, err = previousProofDoc(unsecuredDoc, proofs[:i], previousProof)
+			if err != nil {
+				return nil, err
+			}
+		}
+
+		// Options are copied, as they are completed with the fields of each proof.
+		proofOpts := *opts
+
+		detail, err := v.verifyProof(ctx, []byte(proof.Raw), proofDoc, &proofOpts, pinned)
+		if err != nil {
+			return nil, err
 		}
+
+		details = append(details, detail)
 	}
 
-	return nil
+	return details, nil
 }
 
 func (v *Verifier) verifyProof( // nolint:funlen,gocyclo
+	ctx context.Context,
 	proofRaw, unsecuredDoc []byte,
 	opts *models.ProofOptions,
-) error {
+	pinned *pinnedKeys,
+) (*ProofVerificationDetail, error) {
 	proof := &models.Proof{}
 
 	err := json.Unmarshal(proofRaw, proof)
 	if err != nil {
-		return ErrMalformedProof
+		return nil, ErrMalformedProof
 	}
 
 	if proof.Type == "" || proof.VerificationMethod == "" || proof.ProofPurpose == "" {
-		return ErrMalformedProof
+		return nil, ErrMalformedProof
 	}
 
 	if proof.Type != models.DataIntegrityProof {
-		return ErrWrongProofType
+		return nil, ErrWrongProofType
 	}
 
 	verifierSuite, ok := v.suites[proof.CryptoSuite]
 	if !ok {
-		return ErrUnsupportedSuite
+		return nil, ErrUnsupportedSuite
+	}
+
+	pinnedKey := pinned != nil
+	signatureOnly := pinnedKey && pinned.signatureOnly
+
+	if pinnedKey && !signatureOnly && proof.CryptoSuite != opts.SuiteType {
+		return nil, fmt.Errorf("%w: expected %q, got %q", ErrUnsupportedSuite, opts.SuiteType, proof.CryptoSuite)
 	}
 
 	if opts.SuiteType == "" {
@@ -138,18 +487,24 @@ func (v *Verifier) verifyProof( // nolint:funlen,gocyclo
 	}
 
 	if verifierSuite.RequiresCreated() && proof.Created == "" {
-		return ErrMalformedProof
+		return nil, ErrMalformedProof
 	}
 
-	if opts.Created.IsZero() && proof.Created != "" {
-		var parsedCreatedTime time.Time
+	var parsedCreatedTime time.Time
 
+	if proof.Created != "" {
 		parsedCreatedTime, err = time.Parse(models.DateTimeFormat, proof.Created)
 		if err != nil {
-			return ErrMalformedProof
+			return nil, ErrMalformedProof
 		}
 
-		opts.Created = parsedCreatedTime
+		if !signatureOnly && !opts.AllowFutureCreated && parsedCreatedTime.After(time.Now().Add(opts.CreatedTolerance)) {
+			return nil, ErrCreatedInFuture
+		}
+
+		if opts.Created.IsZero() {
+			opts.Created = parsedCreatedTime
+		}
 	}
 
 	if proof.Expires != "" {
@@ -157,34 +512,357 @@ func (v *Verifier) verifyProof( // nolint:funlen,gocyclo
 
 		parsedExpiresTime, err = time.Parse(models.DateTimeFormat, proof.Expires)
 		if err != nil {
-			return ErrMalformedProof
+			return nil, ErrMalformedProof
 		}
 
-		if time.Now().After(parsedExpiresTime) {
-			return ErrOutOfDate
+		if !signatureOnly && time.Now().Add(-opts.ExpiresTolerance).After(parsedExpiresTime) {
+			return nil, ErrExpired
 		}
 
 		opts.Expires = parsedExpiresTime
 	}
 
+	vmID := absoluteVerificationMethod(proof.VerificationMethod, unsecuredDoc)
+
+	if pinnedKey {
+		// The proof is verified as it was created, only its key is not resolved.
+		opts.Purpose = proof.ProofPurpose
+		opts.VerificationMethodID = vmID
+		opts.Domain = proof.Domain
+		opts.Challenge = proof.Challenge
+
+		opts.VerificationMethod, err = pinned.lookup(vmID)
+		if err != nil {
+			return nil, err
+		}
+	}
+
 	if proof.ProofPurpose != opts.Purpose {
-		return ErrMismatchedPurpose
+		return nil, ErrMismatchedPurpose
 	}
 
-	err = resolveVM(opts, v.resolver, proof.VerificationMethod)
-	if err != nil {
-		return err
+	tryAssertionKeys := opts.TryAllAssertionKeys && !pinnedKey && opts.Purpose == AssertionMethod
+
+	var resolveErr error
+
+	if !pinnedKey {
+		resolveErr = resolveVM(ctx, opts, v.resolver, vmID, slices.Contains(v.relationshipChecks, opts.Purpose))
+		if resolveErr != nil && !tryAssertionKeys {
+			return nil, resolveErr
+		}
+	}
+
+	opts.ProofID = proof.ID
+	opts.PreviousProof = proof.PreviousProof
+	opts.CustomFields = signedCustomFields(proof.CustomFields, opts.UnsignedProofFields)
+
+	// The proof is verified with its nonce, which must match the expected one, if any.
+	expectedNonce := opts.Nonce
+
+	opts.Nonce = proof.Nonce
+	if slices.Contains(opts.UnsignedProofFields, "nonce") {
+		opts.Nonce = ""
+	}
+
+	if len(opts.AcceptedDomains) > 0 {
+		if !slices.Contains(opts.AcceptedDomains, proof.Domain) {
+			return nil, fmt.Errorf("%w: expected one of %q, got %q", ErrInvalidDomain, opts.AcceptedDomains, proof.Domain)
+		}
+
+		// The suite verifies the proof with the expected domain.
+		opts.Domain = proof.Domain
+	}
+
+	if opts.CanonicalizationAlgorithm == "" {
+		opts.CanonicalizationAlgorithm = v.algo
+	}
+
+	verifyResult := resolveErr
+	if verifyResult == nil {
+		opts.SignatureAlgorithm, verifyResult = v.selectAlgorithm(proof.CryptoSuite, opts.VerificationMethod)
+		if verifyResult != nil && !tryAssertionKeys {
+			return nil, verifyResult
+		}
+	}
+
+	if verifyResult == nil {
+		verifyResult = v.verifySuiteProof(ctx, verifierSuite, unsecuredDoc, proofRaw, proof, opts)
+	}
+
+	// A proof whose verification is interrupted is not known to be invalid.
+	if verifyResult != nil && ctx.Err() != nil {
+		return nil, ctx.Err()
 	}
 
-	verifyResult := verifierSuite.VerifyProof(unsecuredDoc, proof, opts)
+	var matchedVMID string
+
+	if verifyResult != nil && tryAssertionKeys {
+		if resolveErr != nil {
+			// The given verification method, if any, is not tried as it is not authorized.
+			opts.VerificationMethod = nil
+		}
+
+		matchedVMID, err = v.verifyWithAssertionKeys(ctx, verifierSuite, unsecuredDoc, proofRaw, proof, opts, vmID)
+
+		switch {
+		case err == nil:
+			verifyResult = nil
+		case ctx.Err() != nil:
+			return nil, ctx.Err()
+		case resolveErr != nil:
+			return nil, resolveErr
+		}
+	}
 
 	if opts.Domain != "" && opts.Domain != proof.Domain {
-		return ErrInvalidDomain
+		return nil, fmt.Errorf("%w: expected %q, got %q", ErrInvalidDomain, opts.Domain, proof.Domain)
 	}
 
 	if opts.Challenge != "" && opts.Challenge != proof.Challenge {
-		return ErrInvalidChallenge
+		return nil, fmt.Errorf("%w: expected %q, got %q", ErrInvalidChallenge, opts.Challenge, proof.Challenge)
+	}
+
+	if expectedNonce != "" && expectedNonce != proof.Nonce {
+		return nil, fmt.Errorf("%w: expected %q, got %q", ErrInvalidNonce, expectedNonce, proof.Nonce)
+	}
+
+	if verifyResult != nil {
+		// TODO update linter to use go 1.20: https://github.com/hyperledger/aries-framework-go/issues/3613
+		return nil, errors.Join(suite.ErrInvalidProof, verifyResult) // nolint:typecheck
+	}
+
+	detail := proofDetail(proof, parsedCreatedTime, vmID, opts)
+	detail.MatchedVerificationMethodID = matchedVMID
+
+	return detail, nil
+}
+
+// verifyWithAssertionKeys verifies the proof with each key listed under assertionMethod in the DID
+// document of the controller of vmID, other than the key of opts already tried, and returns the ID of
+// the first key that verifies it, which is set to opts. The proof is verified as signed with vmID,
+// only its key changes.
+func (v *Verifier) verifyWithAssertionKeys(
+	ctx context.Context,
+	verifierSuite suite.Verifier,
+	unsecuredDoc, proofRaw []byte,
+	proof *models.Proof,
+	opts *models.ProofOptions,
+	vmID string,
+) (string, error) {
+	if v.resolver == nil {
+		return "", ErrNoResolver
+	}
+
+	didDoc, err := getDIDDocFromVerificationMethod(ctx, vmID, v.resolver)
+	if err != nil {
+		// TODO update linter to use go 1.20: https://github.com/hyperledger/aries-framework-go/issues/3613
+		return "", errors.Join(ErrVMResolution, err) // nolint:typecheck
+	}
+
+	tried := opts.VerificationMethod
+
+	for _, verification := range didDoc.VerificationMethods(did.AssertionMethod)[did.AssertionMethod] {
+		vm := verification.VerificationMethod
+
+		if tried != nil && vm.ID == tried.ID {
+			continue
+		}
+
+		alg, algErr := v.selectAlgorithm(proof.CryptoSuite, &vm)
+		if algErr != nil {
+			continue
+		}
+
+		keyOpts := *opts
+		keyOpts.VerificationMethod = &vm
+		keyOpts.SignatureAlgorithm = alg
+
+		if keyOpts.VerificationMethodID == "" {
+			keyOpts.VerificationMethodID = vmID
+		}
+
+		err = v.verifySuiteProof(ctx, verifierSuite, unsecuredDoc, proofRaw, proof, &keyOpts)
+		if err == nil {
+			*opts = keyOpts
+
+			if strings.HasPrefix(vm.ID, "#") {
+				return didDoc.ID + vm.ID, nil
+			}
+
+			return vm.ID, nil
+		}
+
+		if ctx.Err() != nil {
+			return "", ctx.Err()
+		}
+	}
+
+	return "", fmt.Errorf("no assertionMethod key of %s verifies the proof", didDoc.ID)
+}
+
+// proofDetail returns the detail of the verified proof.
+func proofDetail(
+	proof *models.Proof,
+	created time.Time,
+	vmID string,
+	opts *models.ProofOptions,
+) *ProofVerificationDetail {
+	// The verification method ID given in the options is the one the proof is verified with.
+	if opts.VerificationMethodID != "" {
+		vmID = opts.VerificationMethodID
+	}
+
+	signerDID, _, _ := strings.Cut(vmID, "#")
+
+	if opts.VerificationMethod != nil && opts.VerificationMethod.Controller != "" {
+		signerDID = opts.VerificationMethod.Controller
+	}
+
+	return &ProofVerificationDetail{
+		SignerDID:            signerDID,
+		VerificationMethodID: vmID,
+		CryptoSuite:          proof.CryptoSuite,
+		Created:              created,
+	}
+}
+
+// signedCustomFields returns the custom fields of a proof without the unsigned ones.
+func signedCustomFields(customFields map[string]interface{}, unsigned []string) map[string]interface{} {
+	signed := make(map[string]interface{}, len(customFields))
+
+	for name, value := range customFields {
+		if !slices.Contains(unsigned, name) {
+			signed[name] = value
+		}
+	}
+
+	if len(signed) == 0 {
+		return nil
+	}
+
+	return signed
+}
+
+// verifySuiteProof verifies the proof with the suite, unless the verification cache has
+// a verification of the same proof, document and options. Only the successful
+// verifications are remembered, until the proof expires.
+func (v *Verifier) verifySuiteProof(
+	ctx context.Context,
+	verifierSuite suite.Verifier,
+	unsecuredDoc, proofRaw []byte,
+	proof *models.Proof,
+	opts *models.ProofOptions,
+) error {
+	if v.verified == nil {
+		return verifyWithSuite(ctx, verifierSuite, unsecuredDoc, proof, opts)
+	}
+
+	key, err := verificationCacheKey(unsecuredDoc, proofRaw, opts)
+	if err != nil {
+		return verifyWithSuite(ctx, verifierSuite, unsecuredDoc, proof, opts)
+	}
+
+	if expires, ok := v.verified.Get(key); ok {
+		if expires.IsZero() || time.Now().Before(expires) {
+			return nil
+		}
+
+		v.verified.Remove(key)
+	}
+
+	err = verifyWithSuite(ctx, verifierSuite, unsecuredDoc, proof, opts)
+	if err != nil {
+		return err
+	}
+
+	v.verified.Add(key, opts.Expires)
+
+	return nil
+}
+
+// verifyWithSuite verifies the proof with the suite. With opts.LenientProofContext, a proof that
+// doesn't verify is verified again with the Data Integrity context added to the proof configuration.
+func verifyWithSuite(ctx context.Context, verifierSuite suite.Verifier, unsecuredDoc []byte, proof *models.Proof,
+	opts *models.ProofOptions) error {
+	verify := verifierSuite.VerifyProof
+
+	if ctxVerifier, ok := verifierSuite.(suite.ContextVerifier); ok {
+		verify = func(doc []byte, proof *models.Proof, opts *models.ProofOptions) error {
+			return ctxVerifier.VerifyProofContext(ctx, doc, proof, opts)
+		}
+	}
+
+	if !opts.LenientProofContext {
+		return verify(unsecuredDoc, proof, opts)
+	}
+
+	strictOpts := *opts
+	strictOpts.LenientProofContext = false
+
+	err := verify(unsecuredDoc, proof, &strictOpts)
+	if err == nil || verify(unsecuredDoc, proof, opts) != nil {
+		return err
+	}
+
+	return nil
+}
+
+// verificationCacheKey returns the hash of everything a suite verification depends on: the
+// document, the proof, and the verification options, including the verification method key.
+func verificationCacheKey(unsecuredDoc, proofRaw []byte, opts *models.ProofOptions) ([sha256.Size]byte, error) {
+	var vmKey interface{}
+
+	if opts.VerificationMethod != nil {
+		vmKey = opts.VerificationMethod.Value
+
+		if jwk := opts.VerificationMethod.JSONWebKey(); jwk != nil {
+			vmKey = jwk
+		}
+	}
+
+	optsRaw, err := json.Marshal([]interface{}{
+		opts.SuiteType, opts.ProofType, opts.Purpose, opts.VerificationMethodID, vmKey, opts.Domain, opts.Challenge,
+		opts.Nonce, opts.Created, opts.Expires, opts.ProofID, opts.PreviousProof, opts.CanonicalizationAlgorithm,
+		opts.CustomFields, opts.LenientProofContext, opts.ProofValueEncodingHint,
+	})
+	if err != nil {
+		return [sha256.Size]byte{}, err
+	}
+
+	h := sha256.New()
+
+	for _, data := range [][]byte{unsecuredDoc, proofRaw, optsRaw} {
+		docHash := sha256.Sum256(data)
+		h.Write(docHash[:])
+	}
+
+	var key [sha256.Size]byte
+
+	copy(key[:], h.Sum(nil))
+
+	return key, nil
+}
+
+// absoluteVerificationMethod resolves a verification method given as a relative DID URL, ie a
+// fragment such as "#key-1", against the base of the document: its issuer, else its holder, else
+// its id. Other verification methods are returned as they are.
+func absoluteVerificationMethod(vmID string, doc []byte) string {
+	if !strings.HasPrefix(vmID, "#") {
+		return vmID
+	}
+
+	for _, field := range []string{"issuer", "holder", "id"} {
+		base := gjson.GetBytes(doc, field)
+		if base.IsObject() {
+			base = base.Get("id")
+		}
+
+		if base.Type == gjson.String && base.String() != "" {
+			baseURL, _, _ := strings.Cut(base.String(), "#")
+
+			return baseURL + vmID
+		}
 	}
 
-	return verifyResult
+	return vmID
 }
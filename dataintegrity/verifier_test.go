@@ -7,6 +7,7 @@ SPDX-License-Identifier: Apache-2.0
 package dataintegrity
 
 import (
+	"context"
 	"encoding/json"
 	"testing"
 	"time"
@@ -14,8 +15,10 @@ import (
 	"github.com/stretchr/testify/require"
 	"github.com/tidwall/sjson"
 	"github.com/trustbloc/did-go/doc/did"
+	vdrapi "github.com/trustbloc/did-go/vdr/api"
 
 	"github.com/trustbloc/vc-go/dataintegrity/models"
+	"github.com/trustbloc/vc-go/dataintegrity/suite"
 )
 
 const (
@@ -41,7 +44,31 @@ func TestNewVerifier(t *testing.T) {
 
 		require.NoError(t, err)
 		require.NotNil(t, v)
-		require.Len(t, v.suites, 2)
+		require.Contains(t, v.suites, mockSuiteType)
+		require.Contains(t, v.suites, mockSuiteType+"-but-different")
+		require.Len(t, v.suites, 2+len(suite.RegisteredVerifierTypes()))
+	})
+
+	t.Run("registered suites", func(t *testing.T) {
+		registered := &mockSuite{}
+		given := &mockSuite{}
+
+		suite.RegisterVerifier(&mockSuiteInitializer{mockSuite: registered, typeStr: mockSuiteType})
+		defer suite.UnregisterVerifier(mockSuiteType)
+
+		v, err := NewVerifier(nil)
+		require.NoError(t, err)
+		require.Same(t, registered, v.suites[mockSuiteType])
+
+		v, err = NewVerifier(nil, &mockSuiteInitializer{mockSuite: given, typeStr: mockSuiteType})
+		require.NoError(t, err)
+		require.Same(t, given, v.suites[mockSuiteType])
+
+		suite.UnregisterVerifier(mockSuiteType)
+
+		v, err = NewVerifier(nil)
+		require.NoError(t, err)
+		require.NotContains(t, v.suites, mockSuiteType)
 	})
 
 	t.Run("initializer error", func(t *testing.T) {
@@ -105,6 +132,47 @@ func TestVerifier_VerifyProof(t *testing.T) {
 		require.NoError(t, err)
 	})
 
+	t.Run("success with relative verification method", func(t *testing.T) {
+		var resolvedDIDs []string
+
+		v, err := NewVerifier(
+			&Options{
+				DIDResolver: didResolverFunc(func(id string) (*did.DocResolution, error) {
+					resolvedDIDs = append(resolvedDIDs, id)
+
+					return makeMockDIDResolution(id, &did.VerificationMethod{ID: mockKID}, did.AssertionMethod), nil
+				}),
+			},
+			&mockSuiteInitializer{
+				mockSuite: &mockSuite{},
+				typeStr:   mockSuiteType,
+			})
+		require.NoError(t, err)
+
+		mockProof := &models.Proof{
+			Type:               models.DataIntegrityProof,
+			CryptoSuite:        mockSuiteType,
+			VerificationMethod: mockVMID,
+			ProofPurpose:       AssertionMethod,
+		}
+
+		for _, doc := range []string{
+			`{"id":"urn:uuid:foo","issuer":"did:foo:bar"}`,
+			`{"id":"urn:uuid:foo","issuer":{"id":"did:foo:bar","name":"Foo"}}`,
+			`{"id":"did:foo:bar#doc"}`,
+		} {
+			signedDoc, err := mockAddProof([]byte(doc), mockProof)
+			require.NoError(t, err)
+
+			err = v.VerifyProof(signedDoc, &models.ProofOptions{
+				Purpose: AssertionMethod,
+			})
+			require.NoError(t, err)
+		}
+
+		require.Equal(t, []string{mockDID, mockDID, mockDID}, resolvedDIDs)
+	})
+
 	t.Run("success general purpose", func(t *testing.T) {
 		createdTime := time.Now().Format(models.DateTimeFormat)
 
@@ -475,6 +543,7 @@ func TestVerifier_VerifyProof(t *testing.T) {
 				Purpose: CapabilityInvocation,
 			})
 			require.ErrorIs(t, err, errExpected)
+			require.ErrorIs(t, err, suite.ErrInvalidProof)
 		})
 
 		t.Run("created time in wrong format", func(t *testing.T) {
@@ -578,6 +647,71 @@ func TestVerifier_VerifyProof(t *testing.T) {
 				Purpose: AssertionMethod,
 			})
 			require.ErrorIs(t, err, ErrOutOfDate)
+			require.ErrorIs(t, err, ErrExpired)
+
+			err = v.VerifyProof(signedDoc, &models.ProofOptions{
+				Purpose:          AssertionMethod,
+				ExpiresTolerance: 10 * time.Second,
+			})
+			require.ErrorIs(t, err, ErrExpired)
+
+			err = v.VerifyProof(signedDoc, &models.ProofOptions{
+				Purpose:          AssertionMethod,
+				ExpiresTolerance: 2 * time.Minute,
+			})
+			require.NoError(t, err)
+		})
+
+		t.Run("created in the future", func(t *testing.T) {
+			v, err := NewVerifier(
+				&Options{
+					DIDResolver: &mockResolver{
+						vm: &did.VerificationMethod{
+							ID: mockVMID,
+						},
+						vr: did.AssertionMethod,
+					},
+				},
+				&mockSuiteInitializer{
+					mockSuite: &mockSuite{},
+					typeStr:   mockSuiteType,
+				})
+
+			require.NoError(t, err)
+
+			mockProof := &models.Proof{
+				Type:               models.DataIntegrityProof,
+				CryptoSuite:        mockSuiteType,
+				VerificationMethod: mockKID,
+				ProofPurpose:       AssertionMethod,
+				Created:            time.Now().Add(time.Minute).Format(models.DateTimeFormat),
+			}
+
+			signedDoc, err := mockAddProof(mockDoc, mockProof)
+			require.NoError(t, err)
+
+			err = v.VerifyProof(signedDoc, &models.ProofOptions{
+				Purpose: AssertionMethod,
+			})
+			require.ErrorIs(t, err, ErrCreatedInFuture)
+
+			err = v.VerifyProof(signedDoc, &models.ProofOptions{
+				Purpose:          AssertionMethod,
+				CreatedTolerance: 10 * time.Second,
+			})
+			require.ErrorIs(t, err, ErrCreatedInFuture)
+
+			err = v.VerifyProof(signedDoc, &models.ProofOptions{
+				Purpose:          AssertionMethod,
+				CreatedTolerance: 2 * time.Minute,
+			})
+			require.NoError(t, err)
+
+			err = v.VerifyProof(signedDoc, &models.ProofOptions{
+				Purpose:            AssertionMethod,
+				AllowFutureCreated: true,
+			})
+			require.NoError(t, err)
 		})
 
 		t.Run("proof has wrong domain", func(t *testing.T) {
@@ -614,6 +748,21 @@ func TestVerifier_VerifyProof(t *testing.T) {
 				Domain:  "mock-domain",
 			})
 			require.ErrorIs(t, err, ErrInvalidDomain)
+			require.ErrorContains(t, err, `expected "mock-domain", got "wrong-domain"`)
+
+			err = v.VerifyProof(signedDoc, &models.ProofOptions{
+				Purpose:         AssertionMethod,
+				AcceptedDomains: []string{"mock-domain", "other-domain"},
+			})
+			require.ErrorIs(t, err, ErrInvalidDomain)
+			require.ErrorContains(t, err, `expected one of ["mock-domain" "other-domain"], got "wrong-domain"`)
+
+			err = v.VerifyProof(signedDoc, &models.ProofOptions{
+				Purpose:         AssertionMethod,
+				Domain:          "mock-domain",
+				AcceptedDomains: []string{"mock-domain", "wrong-domain"},
+			})
+			require.NoError(t, err)
 		})
 
 		t.Run("proof has wrong challenge", func(t *testing.T) {
@@ -650,10 +799,115 @@ func TestVerifier_VerifyProof(t *testing.T) {
 				Created:   time.Now(),
 			})
 			require.ErrorIs(t, err, ErrInvalidChallenge)
+			require.ErrorContains(t, err, `expected "mock-challenge", got "wrong-challenge"`)
 		})
 	})
 }
 
+func TestVerifier_VerificationCache(t *testing.T) {
+	mockDoc := []byte(`{"id":"foo","data":[{"id":"data-1","value":3}]}`)
+
+	m := &mockSuite{}
+
+	v, err := NewVerifier(
+		(&Options{
+			DIDResolver: &mockResolver{
+				vm: &did.VerificationMethod{ID: mockKID},
+				vr: did.AssertionMethod,
+			},
+		}).WithVerificationCache(10),
+		&mockSuiteInitializer{
+			mockSuite: m,
+			typeStr:   mockSuiteType,
+		})
+	require.NoError(t, err)
+
+	signedDoc, err := mockAddProof(mockDoc, &models.Proof{
+		Type:               models.DataIntegrityProof,
+		CryptoSuite:        mockSuiteType,
+		VerificationMethod: mockKID,
+		ProofPurpose:       AssertionMethod,
+		Challenge:          "mock-challenge",
+		ProofValue:         "z-mock-proof-value",
+	})
+	require.NoError(t, err)
+
+	verifyOpts := func() *models.ProofOptions {
+		return &models.ProofOptions{
+			Purpose:   AssertionMethod,
+			Challenge: "mock-challenge",
+		}
+	}
+
+	t.Run("same proof verified once", func(t *testing.T) {
+		require.NoError(t, v.VerifyProof(signedDoc, verifyOpts()))
+		require.NoError(t, v.VerifyProof(signedDoc, verifyOpts()))
+		require.Equal(t, 1, m.verifyProofCalls)
+	})
+
+	t.Run("document, proof or options changed", func(t *testing.T) {
+		m.verifyProofCalls = 0
+
+		tamperedDoc, err := sjson.SetBytes(signedDoc, "data.0.value", 4)
+		require.NoError(t, err)
+		require.NoError(t, v.VerifyProof(tamperedDoc, verifyOpts()))
+
+		otherProofDoc, err := sjson.SetBytes(signedDoc, "proof.proofValue", "z-other-proof-value")
+		require.NoError(t, err)
+		require.NoError(t, v.VerifyProof(otherProofDoc, verifyOpts()))
+
+		// The suite verifies the proof with the expected challenge.
+		challengeOpts := verifyOpts()
+		challengeOpts.Challenge = "other-challenge"
+		require.ErrorIs(t, v.VerifyProof(signedDoc, challengeOpts), ErrInvalidChallenge)
+
+		require.Equal(t, 3, m.verifyProofCalls)
+
+		// Accepting the domain of the proof is the same as expecting it.
+		domainOpts := verifyOpts()
+		domainOpts.AcceptedDomains = []string{"", "mock-domain"}
+		require.NoError(t, v.VerifyProof(signedDoc, domainOpts))
+
+		require.Equal(t, 3, m.verifyProofCalls)
+	})
+
+	t.Run("failed verification not remembered", func(t *testing.T) {
+		m.verifyProofCalls = 0
+		m.VerifyProofErr = errExpected
+
+		defer func() { m.VerifyProofErr = nil }()
+
+		failingDoc, err := sjson.SetBytes(signedDoc, "id", "bar")
+		require.NoError(t, err)
+
+		require.ErrorIs(t, v.VerifyProof(failingDoc, verifyOpts()), suite.ErrInvalidProof)
+		require.ErrorIs(t, v.VerifyProof(failingDoc, verifyOpts()), suite.ErrInvalidProof)
+		require.Equal(t, 2, m.verifyProofCalls)
+	})
+
+	t.Run("expired proof forgotten", func(t *testing.T) {
+		m.verifyProofCalls = 0
+
+		expiredDoc, err := sjson.SetBytes(signedDoc, "proof.expires",
+			time.Now().Add(-time.Minute).Format(models.DateTimeFormat))
+		require.NoError(t, err)
+
+		toleranceOpts := func() *models.ProofOptions {
+			opts := verifyOpts()
+			opts.ExpiresTolerance = time.Hour
+
+			return opts
+		}
+
+		require.NoError(t, v.VerifyProof(expiredDoc, toleranceOpts()))
+		require.NoError(t, v.VerifyProof(expiredDoc, toleranceOpts()))
+		require.Equal(t, 2, m.verifyProofCalls)
+
+		require.ErrorIs(t, v.VerifyProof(expiredDoc, verifyOpts()), ErrExpired)
+		require.Equal(t, 2, m.verifyProofCalls)
+	})
+}
+
 func mockAddProof(doc []byte, proof *models.Proof) ([]byte, error) {
 	proofRaw, err := json.Marshal(proof)
 	if err != nil {
@@ -667,3 +921,230 @@ func mockAddProof(doc []byte, proof *models.Proof) ([]byte, error) {
 
 	return out, nil
 }
+
+func TestVerifier_ProofRelationshipCheck(t *testing.T) {
+	mockDoc := []byte(`{"id":"foo","data":[{"id":"data-1","value":3}]}`)
+
+	signedDoc, err := mockAddProof(mockDoc, &models.Proof{
+		Type:               models.DataIntegrityProof,
+		CryptoSuite:        mockSuiteType,
+		VerificationMethod: mockKID,
+		ProofPurpose:       AssertionMethod,
+		ProofValue:         "z-mock-proof-value",
+	})
+	require.NoError(t, err)
+
+	newVerifier := func(t *testing.T, vr did.VerificationRelationship, check bool) (*Verifier, *mockSuite) {
+		t.Helper()
+
+		opts := &Options{
+			DIDResolver: &mockResolver{
+				vm: &did.VerificationMethod{ID: mockKID, Value: []byte("did-doc-key")},
+				vr: vr,
+			},
+		}
+
+		if check {
+			opts.WithProofRelationshipCheck(AssertionMethod)
+		}
+
+		m := &mockSuite{}
+
+		v, e := NewVerifier(opts, &mockSuiteInitializer{mockSuite: m, typeStr: mockSuiteType})
+		require.NoError(t, e)
+
+		return v, m
+	}
+
+	t.Run("listed under the relationship", func(t *testing.T) {
+		v, _ := newVerifier(t, did.AssertionMethod, true)
+
+		require.NoError(t, v.VerifyProof(signedDoc, &models.ProofOptions{Purpose: AssertionMethod}))
+	})
+
+	t.Run("listed without relationship", func(t *testing.T) {
+		v, _ := newVerifier(t, did.VerificationRelationshipGeneral, false)
+
+		require.NoError(t, v.VerifyProof(signedDoc, &models.ProofOptions{Purpose: AssertionMethod}))
+
+		v, _ = newVerifier(t, did.VerificationRelationshipGeneral, true)
+
+		err := v.VerifyProof(signedDoc, &models.ProofOptions{Purpose: AssertionMethod})
+		require.ErrorIs(t, err, ErrMissingVerificationRelationship)
+		require.ErrorIs(t, err, ErrVMResolution)
+		require.ErrorContains(t, err, "verification method "+mockKID+" is not listed under assertionMethod of "+mockDID)
+	})
+
+	t.Run("listed under another relationship", func(t *testing.T) {
+		// Without the relationship check, a verification method given in the options is not checked.
+		v, _ := newVerifier(t, did.Authentication, false)
+
+		require.NoError(t, v.VerifyProof(signedDoc, &models.ProofOptions{
+			Purpose:            AssertionMethod,
+			VerificationMethod: &did.VerificationMethod{ID: mockKID},
+		}))
+
+		v, _ = newVerifier(t, did.Authentication, true)
+
+		err := v.VerifyProof(signedDoc, &models.ProofOptions{
+			Purpose:            AssertionMethod,
+			VerificationMethod: &did.VerificationMethod{ID: mockKID},
+		})
+		require.ErrorIs(t, err, ErrMissingVerificationRelationship)
+	})
+
+	t.Run("verification method from the DID document", func(t *testing.T) {
+		v, m := newVerifier(t, did.AssertionMethod, true)
+
+		err := v.VerifyProof(signedDoc, &models.ProofOptions{
+			Purpose:            AssertionMethod,
+			VerificationMethod: &did.VerificationMethod{ID: mockKID, Value: []byte("given-key")},
+		})
+		require.NoError(t, err)
+		require.Equal(t, []byte("did-doc-key"), m.verifyProofOpts.VerificationMethod.Value)
+	})
+
+	t.Run("other purposes are not checked", func(t *testing.T) {
+		v, _ := newVerifier(t, did.Authentication, true)
+
+		authDoc, e := mockAddProof(mockDoc, &models.Proof{
+			Type:               models.DataIntegrityProof,
+			CryptoSuite:        mockSuiteType,
+			VerificationMethod: mockKID,
+			ProofPurpose:       Authentication,
+			ProofValue:         "z-mock-proof-value",
+		})
+		require.NoError(t, e)
+
+		require.NoError(t, v.VerifyProof(authDoc, &models.ProofOptions{
+			Purpose:            Authentication,
+			VerificationMethod: &did.VerificationMethod{ID: mockKID},
+		}))
+	})
+}
+
+type contextResolverFunc func(ctx context.Context, id string) (*did.DocResolution, error)
+
+func (f contextResolverFunc) Resolve(id string, opts ...vdrapi.DIDMethodOption) (*did.DocResolution, error) {
+	return f(context.Background(), id)
+}
+
+func (f contextResolverFunc) ResolveContext(
+	ctx context.Context,
+	id string,
+	_ ...vdrapi.DIDMethodOption,
+) (*did.DocResolution, error) {
+	return f(ctx, id)
+}
+
+type mockContextSuite struct {
+	*mockSuite
+
+	ctx context.Context
+}
+
+func (m *mockContextSuite) VerifyProofContext(
+	ctx context.Context,
+	doc []byte,
+	proof *models.Proof,
+	opts *models.ProofOptions,
+) error {
+	m.ctx = ctx
+
+	return m.VerifyProof(doc, proof, opts)
+}
+
+type mockContextSuiteInitializer struct {
+	mockSuiteInitializer
+
+	contextSuite *mockContextSuite
+}
+
+func (m *mockContextSuiteInitializer) Verifier() (suite.Verifier, error) {
+	return m.contextSuite, nil
+}
+
+func TestVerifier_VerifyProofContext(t *testing.T) {
+	type ctxKey struct{}
+
+	mockProof := &models.Proof{
+		Type:               models.DataIntegrityProof,
+		CryptoSuite:        mockSuiteType,
+		VerificationMethod: mockKID,
+		ProofPurpose:       AssertionMethod,
+	}
+
+	signedDoc, err := mockAddProof([]byte(`{"id":"foo"}`), mockProof)
+	require.NoError(t, err)
+
+	proofOpts := &models.ProofOptions{Purpose: AssertionMethod}
+
+	t.Run("context passed to resolver and suite", func(t *testing.T) {
+		var resolveCtx context.Context
+
+		contextSuite := &mockContextSuite{mockSuite: &mockSuite{}}
+
+		v, err := NewVerifier(&Options{
+			DIDResolver: contextResolverFunc(func(ctx context.Context, id string) (*did.DocResolution, error) {
+				resolveCtx = ctx
+
+				return makeMockDIDResolution(id, &did.VerificationMethod{ID: mockKID}, did.AssertionMethod), nil
+			}),
+		}, &mockContextSuiteInitializer{
+			mockSuiteInitializer: mockSuiteInitializer{typeStr: mockSuiteType},
+			contextSuite:         contextSuite,
+		})
+		require.NoError(t, err)
+
+		ctx := context.WithValue(context.Background(), ctxKey{}, "value")
+
+		require.NoError(t, v.VerifyProofContext(ctx, signedDoc, proofOpts))
+		require.Equal(t, "value", resolveCtx.Value(ctxKey{}))
+		require.Equal(t, "value", contextSuite.ctx.Value(ctxKey{}))
+
+		_, err = v.VerifyProofDetailedContext(ctx, signedDoc, proofOpts)
+		require.NoError(t, err)
+
+		// The old methods verify with the background context.
+		require.NoError(t, v.VerifyProof(signedDoc, proofOpts))
+		require.Nil(t, resolveCtx.Value(ctxKey{}))
+		require.Nil(t, contextSuite.ctx.Value(ctxKey{}))
+	})
+
+	t.Run("slow resolver", func(t *testing.T) {
+		unblock := make(chan struct{})
+		defer close(unblock)
+
+		v, err := NewVerifier(&Options{
+			DIDResolver: didResolverFunc(func(id string) (*did.DocResolution, error) {
+				<-unblock
+
+				return makeMockDIDResolution(id, &did.VerificationMethod{ID: mockKID}, did.AssertionMethod), nil
+			}),
+		}, &mockSuiteInitializer{mockSuite: &mockSuite{}, typeStr: mockSuiteType})
+		require.NoError(t, err)
+
+		ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+		defer cancel()
+
+		err = v.VerifyProofContext(ctx, signedDoc, proofOpts)
+		require.ErrorIs(t, err, context.DeadlineExceeded)
+		require.ErrorIs(t, err, ErrVMResolution)
+	})
+
+	t.Run("canceled context", func(t *testing.T) {
+		mockSuite := &mockSuite{}
+
+		v, err := NewVerifier(&Options{
+			DIDResolver: &mockResolver{vm: &did.VerificationMethod{ID: mockKID}, vr: did.AssertionMethod},
+		}, &mockSuiteInitializer{mockSuite: mockSuite, typeStr: mockSuiteType})
+		require.NoError(t, err)
+
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+
+		err = v.VerifyProofContext(ctx, signedDoc, proofOpts)
+		require.ErrorIs(t, err, context.Canceled)
+		require.Zero(t, mockSuite.verifyProofCalls)
+	})
+}
@@ -0,0 +1,167 @@
+/*
+Copyright Gen Digital Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package dataintegrity
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/trustbloc/did-go/doc/did"
+	vdrapi "github.com/trustbloc/did-go/vdr/api"
+)
+
+const (
+	webDIDPrefix = "did:web:"
+
+	// webDIDDocumentLimit is the maximum size of the fetched DID documents.
+	webDIDDocumentLimit = 1 << 20
+
+	defaultWebResolverTimeout = 30 * time.Second
+)
+
+// httpClient represents an HTTP client.
+type httpClient interface {
+	Do(req *http.Request) (*http.Response, error)
+}
+
+// WebResolver is a DIDResolver of did:web DIDs, fetching the DID documents over HTTPS as
+// specified by the did:web method: https://w3c-ccg.github.io/did-method-web/. It can be wrapped
+// with a CachingResolver, so that the DID document of an issuer is not fetched for each proof.
+type WebResolver struct {
+	httpClient httpClient
+}
+
+// WebResolverOpt is an option of NewWebResolver.
+type WebResolverOpt func(r *WebResolver)
+
+// WithWebResolverHTTPClient sets the HTTP client fetching the DID documents.
+// Default is an http.Client with a 30 seconds timeout.
+func WithWebResolverHTTPClient(client httpClient) WebResolverOpt {
+	return func(r *WebResolver) {
+		r.httpClient = client
+	}
+}
+
+// NewWebResolver creates a WebResolver.
+func NewWebResolver(opts ...WebResolverOpt) *WebResolver {
+	r := &WebResolver{
+		httpClient: &http.Client{Timeout: defaultWebResolverTimeout},
+	}
+
+	for _, opt := range opts {
+		opt(r)
+	}
+
+	return r
+}
+
+// Resolve fetches the DID document of a did:web DID, from the URL given by WebDIDDocumentURL.
+// The id of the fetched DID document must be didID. The DID method options are ignored.
+func (r *WebResolver) Resolve(didID string, opts ...vdrapi.DIDMethodOption) (*did.DocResolution, error) {
+	return r.ResolveContext(context.Background(), didID, opts...)
+}
+
+// ResolveContext fetches the DID document of a did:web DID as Resolve, with the HTTP request
+// bound to ctx, implements ContextDIDResolver.
+func (r *WebResolver) ResolveContext(
+	ctx context.Context,
+	didID string,
+	_ ...vdrapi.DIDMethodOption,
+) (*did.DocResolution, error) {
+	address, err := WebDIDDocumentURL(didID)
+	if err != nil {
+		return nil, err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, address, nil)
+	if err != nil {
+		return nil, fmt.Errorf("resolve %s: new HTTP request: %w", didID, err)
+	}
+
+	req.Header.Set("Accept", "application/did+json, application/json")
+
+	resp, err := r.httpClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("resolve %s: %w", didID, err)
+	}
+
+	defer resp.Body.Close() // nolint:errcheck
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("resolve %s: %s returned status %d", didID, address, resp.StatusCode)
+	}
+
+	docBytes, err := io.ReadAll(io.LimitReader(resp.Body, webDIDDocumentLimit+1))
+	if err != nil {
+		return nil, fmt.Errorf("resolve %s: read DID document: %w", didID, err)
+	}
+
+	if len(docBytes) > webDIDDocumentLimit {
+		return nil, fmt.Errorf("resolve %s: DID document exceeds %d bytes", didID, webDIDDocumentLimit)
+	}
+
+	doc, err := did.ParseDocument(docBytes)
+	if err != nil {
+		return nil, fmt.Errorf("resolve %s: parse DID document: %w", didID, err)
+	}
+
+	if doc.ID != didID {
+		return nil, fmt.Errorf("resolve %s: DID document has id %q", didID, doc.ID)
+	}
+
+	return &did.DocResolution{DIDDocument: doc}, nil
+}
+
+// WebDIDDocumentURL returns the HTTPS URL of the DID document of a did:web DID: the colons of
+// the method-specific id are replaced by slashes and each part is percent-decoded, so that
+// did:web:example.com%3A3000:user:alice gives https://example.com:3000/user/alice/did.json.
+// Without a path, the DID document is at /.well-known/did.json of the domain.
+func WebDIDDocumentURL(didID string) (string, error) {
+	methodID, found := strings.CutPrefix(didID, webDIDPrefix)
+	if !found {
+		return "", fmt.Errorf("%q is not a did:web DID", didID)
+	}
+
+	if strings.ContainsAny(methodID, "/?#") {
+		return "", fmt.Errorf("did:web DID %q should not have a path, query or fragment", didID)
+	}
+
+	parts := strings.Split(methodID, ":")
+
+	for i, part := range parts {
+		decoded, err := url.PathUnescape(part)
+		if err != nil {
+			return "", fmt.Errorf("did:web DID %q: %w", didID, err)
+		}
+
+		if decoded == "" {
+			return "", fmt.Errorf("did:web DID %q has an empty part", didID)
+		}
+
+		parts[i] = decoded
+	}
+
+	host := parts[0]
+
+	if hostURL, err := url.Parse("https://" + host); err != nil || hostURL.Host != host || hostURL.User != nil {
+		return "", fmt.Errorf("did:web DID %q has an invalid domain %q", didID, host)
+	}
+
+	docPath := "/.well-known/did.json"
+	if len(parts) > 1 {
+		docPath = "/" + strings.Join(parts[1:], "/") + "/did.json"
+	}
+
+	docURL := &url.URL{Scheme: "https", Host: host, Path: docPath}
+
+	return docURL.String(), nil
+}
@@ -0,0 +1,177 @@
+/*
+Copyright Gen Digital Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package dataintegrity
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"github.com/trustbloc/did-go/doc/did"
+	"github.com/trustbloc/did-go/doc/ld/documentloader"
+	kmsapi "github.com/trustbloc/kms-go/spi/kms"
+
+	"github.com/trustbloc/vc-go/dataintegrity/models"
+	"github.com/trustbloc/vc-go/dataintegrity/suite/ecdsa2019"
+	"github.com/trustbloc/vc-go/internal/testutil/kmscryptoutil"
+)
+
+func TestWebDIDDocumentURL(t *testing.T) {
+	t.Run("success", func(t *testing.T) {
+		for didID, expected := range map[string]string{
+			"did:web:w3c-ccg.github.io":                 "https://w3c-ccg.github.io/.well-known/did.json",
+			"did:web:w3c-ccg.github.io:user:alice":      "https://w3c-ccg.github.io/user/alice/did.json",
+			"did:web:example.com%3A3000":                "https://example.com:3000/.well-known/did.json",
+			"did:web:example.com%3A3000:user:alice":     "https://example.com:3000/user/alice/did.json",
+			"did:web:example.com:issuers:acme%20corp":   "https://example.com/issuers/acme%20corp/did.json",
+			"did:web:xn--bcher-kva.example:issuers:one": "https://xn--bcher-kva.example/issuers/one/did.json",
+		} {
+			address, err := WebDIDDocumentURL(didID)
+			require.NoError(t, err, didID)
+			require.Equal(t, expected, address, didID)
+		}
+	})
+
+	t.Run("failure", func(t *testing.T) {
+		for didID, errMsg := range map[string]string{
+			"did:key:z6MkhaXgBZDvotDkL5257faiztiGiC2QtKLGpbnnEGta2doK": "is not a did:web DID",
+			"did:web:":                           "has an empty part",
+			"did:web:example.com::alice":         "has an empty part",
+			"did:web:example.com%zz":             "invalid URL escape",
+			"did:web:example.com#key-1":          "should not have a path, query or fragment",
+			"did:web:example.com/path":           "should not have a path, query or fragment",
+			"did:web:user%40example.com":         "has an invalid domain",
+			"did:web:example.com%2Fpath":         "has an invalid domain",
+			"did:web:example.com%3Anot-a-number": "has an invalid domain",
+		} {
+			_, err := WebDIDDocumentURL(didID)
+			require.ErrorContains(t, err, errMsg, didID)
+		}
+	})
+}
+
+func TestWebResolver(t *testing.T) {
+	var docs map[string]string
+
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		doc, ok := docs[r.URL.Path]
+		if !ok {
+			w.WriteHeader(http.StatusNotFound)
+
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/did+json")
+		_, _ = w.Write([]byte(doc)) //nolint:errcheck
+	}))
+	defer server.Close()
+
+	serverURL, err := url.Parse(server.URL)
+	require.NoError(t, err)
+
+	webDID := "did:web:" + strings.ReplaceAll(serverURL.Host, ":", "%3A")
+	userDID := webDID + ":user:alice"
+
+	kmsCrypto := kmscryptoutil.LocalKMSCrypto(t)
+
+	key, err := kmsCrypto.Create(kmsapi.ECDSAP256IEEEP1363)
+	require.NoError(t, err)
+
+	vm, err := did.NewVerificationMethodFromJWK(userDID+"#key-1", "JsonWebKey2020", userDID, key)
+	require.NoError(t, err)
+
+	userDoc, err := (&did.Doc{
+		Context:            []string{did.ContextV1},
+		ID:                 userDID,
+		VerificationMethod: []did.VerificationMethod{*vm},
+		AssertionMethod:    []did.Verification{{VerificationMethod: *vm, Relationship: did.AssertionMethod}},
+	}).JSONBytes()
+	require.NoError(t, err)
+
+	docs = map[string]string{
+		"/.well-known/did.json": `{"@context":"https://www.w3.org/ns/did/v1","id":"` + webDID + `"}`,
+		"/user/alice/did.json":  string(userDoc),
+		"/user/bob/did.json":    `{"@context":"https://www.w3.org/ns/did/v1","id":"did:web:example.com"}`,
+		"/user/carol/did.json":  `{"@context":"https://www.w3.org/ns/did/v1","id":"` + strings.Repeat("a", 1<<20) + `"}`,
+	}
+
+	resolver := NewWebResolver(WithWebResolverHTTPClient(server.Client()))
+
+	t.Run("success", func(t *testing.T) {
+		res, err := resolver.Resolve(webDID)
+		require.NoError(t, err)
+		require.Equal(t, webDID, res.DIDDocument.ID)
+
+		res, err = resolver.Resolve(userDID)
+		require.NoError(t, err)
+		require.Equal(t, userDID, res.DIDDocument.ID)
+		require.Len(t, res.DIDDocument.AssertionMethod, 1)
+	})
+
+	t.Run("verify proof of a did:web issuer", func(t *testing.T) {
+		docLoader, err := documentloader.NewDocumentLoader(createMockProvider())
+		require.NoError(t, err)
+
+		signer, err := NewSigner(&Options{}, ecdsa2019.NewSignerInitializer(&ecdsa2019.SignerInitializerOptions{
+			LDDocumentLoader: docLoader,
+			SignerGetter:     ecdsa2019.WithKMSCryptoWrapper(kmsCrypto),
+		}))
+		require.NoError(t, err)
+
+		verifier, err := NewVerifier((&Options{}).WithDIDResolver(resolver),
+			ecdsa2019.NewVerifierInitializer(&ecdsa2019.VerifierInitializerOptions{
+				LDDocumentLoader: docLoader,
+			}))
+		require.NoError(t, err)
+
+		signedCred, err := signer.AddProof(validCredential, &models.ProofOptions{
+			VerificationMethod:   vm,
+			VerificationMethodID: vm.ID,
+			SuiteType:            ecdsa2019.SuiteType,
+			Purpose:              AssertionMethod,
+			ProofType:            models.DataIntegrityProof,
+			Created:              time.Now(),
+		})
+		require.NoError(t, err)
+
+		err = verifier.VerifyProof(signedCred, &models.ProofOptions{
+			SuiteType: ecdsa2019.SuiteType,
+			Purpose:   AssertionMethod,
+			ProofType: models.DataIntegrityProof,
+		})
+		require.NoError(t, err)
+	})
+
+	t.Run("failure", func(t *testing.T) {
+		_, err := resolver.Resolve("did:key:z6MkhaXgBZDvotDkL5257faiztiGiC2QtKLGpbnnEGta2doK")
+		require.ErrorContains(t, err, "is not a did:web DID")
+
+		_, err = resolver.Resolve(webDID + ":user:dave")
+		require.ErrorContains(t, err, "returned status 404")
+
+		_, err = resolver.Resolve(webDID + ":user:bob")
+		require.ErrorContains(t, err, `DID document has id "did:web:example.com"`)
+
+		_, err = resolver.Resolve(webDID + ":user:carol")
+		require.ErrorContains(t, err, "DID document exceeds")
+
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+
+		_, err = resolver.ResolveContext(ctx, webDID)
+		require.ErrorIs(t, err, context.Canceled)
+
+		// The certificate of the test server is not trusted by the default client.
+		_, err = NewWebResolver().Resolve(webDID)
+		require.ErrorContains(t, err, "certificate")
+	})
+}
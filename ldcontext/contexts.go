@@ -0,0 +1,46 @@
+/*
+Copyright Gen Digital Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package ldcontext
+
+import (
+	_ "embed" //nolint:gci // required for go:embed
+
+	ldcontext "github.com/trustbloc/did-go/doc/ld/context"
+	"github.com/trustbloc/did-go/doc/ld/context/embed"
+)
+
+// nolint:gochecknoglobals // required for go:embed
+var (
+	//go:embed third_party/w3id.org/data-integrity-v1.jsonld
+	dataIntegrityV1 []byte
+	//go:embed third_party/w3id.org/data-integrity-v2.jsonld
+	dataIntegrityV2 []byte
+)
+
+// dataIntegrityContexts are the Data Integrity contexts, which are not embedded by did-go.
+var dataIntegrityContexts = []ldcontext.Document{ //nolint:gochecknoglobals
+	{
+		URL:     "https://w3id.org/security/data-integrity/v1",
+		Content: dataIntegrityV1,
+	},
+	{
+		URL:     "https://w3id.org/security/data-integrity/v2",
+		Content: dataIntegrityV2,
+	},
+}
+
+// Contexts returns the JSON-LD contexts of the embedded bundle: the VC 1.1 and 2.0 contexts, the
+// contexts of the security vocabulary, the Data Integrity and legacy Linked Data proof suites, and
+// the contexts of the status lists, ie StatusList2021 and RevocationList2020, BitstringStatusList
+// being defined by the VC 2.0 context.
+func Contexts() []ldcontext.Document {
+	contexts := make([]ldcontext.Document, 0, len(embed.Contexts)+len(dataIntegrityContexts))
+
+	contexts = append(contexts, embed.Contexts...)
+
+	return append(contexts, dataIntegrityContexts...)
+}
@@ -0,0 +1,118 @@
+/*
+Copyright Gen Digital Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+// Package ldcontext provides a JSON-LD document loader serving the standard VC, Data Integrity and
+// status list contexts from a bundle embedded into the binary, for verifications which never hit the
+// network for the well-known contexts.
+package ldcontext
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"sync"
+
+	"github.com/piprate/json-gold/ld"
+	ldcontext "github.com/trustbloc/did-go/doc/ld/context"
+	"github.com/trustbloc/did-go/doc/ld/documentloader"
+
+	"github.com/trustbloc/vc-go/dataintegrity/suite"
+)
+
+// EmbeddedDocumentLoader is a JSON-LD document loader serving the contexts of the embedded bundle,
+// and the extra contexts registered at its construction, from memory.
+type EmbeddedDocumentLoader struct {
+	documents map[string]*ld.RemoteDocument
+	fallback  ld.DocumentLoader
+}
+
+type embeddedLoaderOpts struct {
+	extraContexts []ldcontext.Document
+	fallback      ld.DocumentLoader
+}
+
+// EmbeddedLoaderOpt configures an EmbeddedDocumentLoader at its construction.
+type EmbeddedLoaderOpt func(opts *embeddedLoaderOpts)
+
+// WithExtraContexts registers the contexts into the bundle of the loader. A context with the URL of
+// one of the embedded contexts replaces it.
+func WithExtraContexts(contexts ...ldcontext.Document) EmbeddedLoaderOpt {
+	return func(opts *embeddedLoaderOpts) {
+		opts.extraContexts = append(opts.extraContexts, contexts...)
+	}
+}
+
+// WithFallbackLoader sets the loader of the documents which are not in the bundle, eg a remote
+// loader fetching them from the network. By default, the loader does not fall back.
+func WithFallbackLoader(loader ld.DocumentLoader) EmbeddedLoaderOpt {
+	return func(opts *embeddedLoaderOpts) {
+		opts.fallback = loader
+	}
+}
+
+// NewEmbeddedDocumentLoader returns an EmbeddedDocumentLoader serving the contexts of Contexts and
+// the contexts given with WithExtraContexts, parsed once at its construction. The other documents
+// are loaded with the loader given with WithFallbackLoader, if any, else their loading fails with
+// documentloader.ErrContextNotFound.
+func NewEmbeddedDocumentLoader(opts ...EmbeddedLoaderOpt) (*EmbeddedDocumentLoader, error) {
+	loaderOpts := &embeddedLoaderOpts{}
+
+	for _, opt := range opts {
+		opt(loaderOpts)
+	}
+
+	loader := &EmbeddedDocumentLoader{
+		documents: map[string]*ld.RemoteDocument{},
+		fallback:  loaderOpts.fallback,
+	}
+
+	for _, c := range append(Contexts(), loaderOpts.extraContexts...) {
+		doc, err := ld.DocumentFromReader(bytes.NewReader(c.Content))
+		if err != nil {
+			return nil, fmt.Errorf("parse context %s: %w", c.URL, err)
+		}
+
+		documentURL := c.DocumentURL
+		if documentURL == "" {
+			documentURL = c.URL
+		}
+
+		loader.documents[c.URL] = &ld.RemoteDocument{DocumentURL: documentURL, Document: doc}
+	}
+
+	return loader, nil
+}
+
+// sharedDocumentLoader is the EmbeddedDocumentLoader returned by SharedDocumentLoader.
+var sharedDocumentLoader = sync.OnceValues(func() (*EmbeddedDocumentLoader, error) { //nolint:gochecknoglobals
+	return NewEmbeddedDocumentLoader()
+})
+
+// SharedDocumentLoader returns an EmbeddedDocumentLoader serving the contexts of Contexts, without
+// fallback loader, created at the first call and shared by all the callers.
+func SharedDocumentLoader() (*EmbeddedDocumentLoader, error) {
+	return sharedDocumentLoader()
+}
+
+// LoadDocument returns the document of the bundle with the URL u, else loads it with the fallback
+// loader, implements ld.DocumentLoader.
+func (l *EmbeddedDocumentLoader) LoadDocument(u string) (*ld.RemoteDocument, error) {
+	return l.LoadDocumentContext(context.Background(), u)
+}
+
+// LoadDocumentContext loads the document as LoadDocument, with the fallback loader loading until
+// ctx is done, implements suite.ContextDocumentLoader.
+func (l *EmbeddedDocumentLoader) LoadDocumentContext(ctx context.Context, u string) (*ld.RemoteDocument, error) {
+	if doc, ok := l.documents[u]; ok {
+		return doc, nil
+	}
+
+	if l.fallback == nil {
+		return nil, fmt.Errorf("%w: %s", documentloader.ErrContextNotFound, u)
+	}
+
+	return suite.DocumentLoaderWithContext(ctx, l.fallback).LoadDocument(u)
+}
@@ -0,0 +1,162 @@
+/*
+Copyright Gen Digital Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package ldcontext
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/piprate/json-gold/ld"
+	"github.com/stretchr/testify/require"
+	ldcontext "github.com/trustbloc/did-go/doc/ld/context"
+	"github.com/trustbloc/did-go/doc/ld/documentloader"
+
+	"github.com/trustbloc/vc-go/verifiable"
+)
+
+const testCredential = `{
+  "@context": [
+    "https://www.w3.org/ns/credentials/v2",
+    "https://w3id.org/security/data-integrity/v2",
+    "https://w3id.org/vc/status-list/2021/v1"
+  ],
+  "id": "https://example.com/credentials/1872",
+  "type": ["VerifiableCredential"],
+  "issuer": "did:example:76e12ec712ebc6f1c221ebfeb1f",
+  "validFrom": "2010-01-01T19:23:24Z",
+  "credentialSubject": {
+    "id": "did:example:ebfeb1f712ebc6f1c276e12ec21"
+  },
+  "credentialStatus": {
+    "id": "https://example.com/status/1#94567",
+    "type": "BitstringStatusListEntry",
+    "statusPurpose": "revocation",
+    "statusListIndex": "94567",
+    "statusListCredential": "https://example.com/status/1"
+  }
+}`
+
+type mockLoader struct {
+	calls int
+	load  func(u string) (*ld.RemoteDocument, error)
+}
+
+func (m *mockLoader) LoadDocument(u string) (*ld.RemoteDocument, error) {
+	m.calls++
+
+	return m.load(u)
+}
+
+func TestNewEmbeddedDocumentLoader(t *testing.T) {
+	t.Run("serves the bundle", func(t *testing.T) {
+		loader, err := NewEmbeddedDocumentLoader()
+		require.NoError(t, err)
+
+		for _, c := range Contexts() {
+			doc, e := loader.LoadDocument(c.URL)
+			require.NoError(t, e, c.URL)
+			require.NotNil(t, doc.Document, c.URL)
+		}
+
+		doc, err := loader.LoadDocument("https://w3id.org/security/v2")
+		require.NoError(t, err)
+		require.Equal(t, "https://w3c-ccg.github.io/security-vocab/contexts/security-v2.jsonld", doc.DocumentURL)
+
+		doc, err = loader.LoadDocument("https://w3id.org/security/data-integrity/v2")
+		require.NoError(t, err)
+		require.Equal(t, "https://w3id.org/security/data-integrity/v2", doc.DocumentURL)
+	})
+
+	t.Run("validates credential offline", func(t *testing.T) {
+		loader, err := NewEmbeddedDocumentLoader()
+		require.NoError(t, err)
+
+		vc, err := verifiable.ParseCredential([]byte(testCredential), verifiable.WithDisabledProofCheck(),
+			verifiable.WithJSONLDDocumentLoader(loader), verifiable.WithStrictValidation())
+		require.NoError(t, err)
+		require.Equal(t, "https://example.com/credentials/1872", vc.Contents().ID)
+	})
+
+	t.Run("unknown context without fallback", func(t *testing.T) {
+		loader, err := NewEmbeddedDocumentLoader()
+		require.NoError(t, err)
+
+		_, err = loader.LoadDocument("https://example.com/context/v1")
+		require.ErrorIs(t, err, documentloader.ErrContextNotFound)
+		require.ErrorContains(t, err, "https://example.com/context/v1")
+	})
+
+	t.Run("unknown context with fallback", func(t *testing.T) {
+		fallback := &mockLoader{load: func(u string) (*ld.RemoteDocument, error) {
+			return &ld.RemoteDocument{
+				DocumentURL: u,
+				Document:    map[string]interface{}{"@context": map[string]interface{}{}},
+			}, nil
+		}}
+
+		loader, err := NewEmbeddedDocumentLoader(WithFallbackLoader(fallback))
+		require.NoError(t, err)
+
+		doc, err := loader.LoadDocument("https://example.com/context/v1")
+		require.NoError(t, err)
+		require.Equal(t, "https://example.com/context/v1", doc.DocumentURL)
+
+		_, err = loader.LoadDocument("https://www.w3.org/ns/credentials/v2")
+		require.NoError(t, err)
+		require.Equal(t, 1, fallback.calls)
+	})
+
+	t.Run("fallback loading abandoned when context is done", func(t *testing.T) {
+		block := make(chan struct{})
+		defer close(block)
+
+		fallback := &mockLoader{load: func(string) (*ld.RemoteDocument, error) {
+			<-block
+
+			return nil, errors.New("unexpected")
+		}}
+
+		loader, err := NewEmbeddedDocumentLoader(WithFallbackLoader(fallback))
+		require.NoError(t, err)
+
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+
+		_, err = loader.LoadDocumentContext(ctx, "https://example.com/context/v1")
+		require.ErrorIs(t, err, context.Canceled)
+	})
+
+	t.Run("extra contexts", func(t *testing.T) {
+		loader, err := NewEmbeddedDocumentLoader(WithExtraContexts(
+			ldcontext.Document{
+				URL:         "https://example.com/context/v1",
+				DocumentURL: "https://example.com/context/v1.jsonld",
+				Content:     []byte(`{"@context": {"name": "https://schema.org/name"}}`),
+			},
+			ldcontext.Document{
+				URL:     "https://w3id.org/security/data-integrity/v2",
+				Content: []byte(`{"@context": {}}`),
+			},
+		))
+		require.NoError(t, err)
+
+		doc, err := loader.LoadDocument("https://example.com/context/v1")
+		require.NoError(t, err)
+		require.Equal(t, "https://example.com/context/v1.jsonld", doc.DocumentURL)
+
+		doc, err = loader.LoadDocument("https://w3id.org/security/data-integrity/v2")
+		require.NoError(t, err)
+		require.Equal(t, map[string]interface{}{"@context": map[string]interface{}{}}, doc.Document)
+
+		_, err = NewEmbeddedDocumentLoader(WithExtraContexts(ldcontext.Document{
+			URL:     "https://example.com/context/v1",
+			Content: []byte(`{`),
+		}))
+		require.ErrorContains(t, err, "parse context https://example.com/context/v1")
+	})
+}
@@ -537,6 +537,32 @@ func (pd *PresentationDefinition) CreateVPArray(
 	return presentations, submission, nil
 }
 
+// MatchCredentials selects the credentials that satisfy the presentation definition, as CreateVP does,
+// without creating the presentation: a wallet can then present them in a presentation of its own, eg
+// signed for an OpenID4VP response. It returns the presentation submission and the matched credentials;
+// with limit_disclosure required, these are derived from the held ones to disclose only the requested
+// SD-JWT claims or, with WithSDBBSProofCreator, BBS+ fields, and the held credentials are left unchanged.
+// The submission descriptors refer to a presentation, of the WithDefaultPresentationFormat format, with
+// the returned credentials as its verifiableCredential, in the same order.
+func (pd *PresentationDefinition) MatchCredentials(
+	credentials []*verifiable.Credential,
+	documentLoader ld.DocumentLoader,
+	opts ...MatchRequirementsOpt,
+) (*PresentationSubmission, []*verifiable.Credential, error) {
+	matchOpts := &matchRequirementsOpts{defaultVPFormat: FormatLDPVP}
+	for _, opt := range opts {
+		opt(matchOpts)
+	}
+
+	applicableCredentials, submission, err := presentationData(pd, credentials, documentLoader, false,
+		matchOpts.sdBBSProofCreator, matchOpts.defaultVPFormat, matchOpts.credOpts...)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return submission, applicableCredentials, nil
+}
+
 func presentationData(
 	pd *PresentationDefinition,
 	credentials []*verifiable.Credential,
@@ -1116,7 +1142,8 @@ func limitDisclosure(filterResults []constraintsFilterResult,
 				return nil, err
 			}
 
-			err = credential.SetSDJWTDisclosures(limitedDisclosures)
+			// The disclosures are limited on a copy, the held credential keeps all its disclosures.
+			credential, err = credential.WithSDJWTDisclosures(limitedDisclosures)
 			if err != nil {
 				return nil, err
 			}
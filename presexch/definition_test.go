@@ -3197,6 +3197,108 @@ func TestPresentationDefinition_CreateVPArray(t *testing.T) {
 	})
 }
 
+func TestPresentationDefinition_MatchCredentials(t *testing.T) {
+	lddl := createTestJSONLDDocumentLoader(t)
+
+	t.Run("Matches one of two credentials", func(t *testing.T) {
+		pd := &PresentationDefinition{
+			ID: uuid.New().String(),
+			InputDescriptors: []*InputDescriptor{{
+				ID: uuid.New().String(),
+				Schema: []*Schema{{
+					URI: "https://example.org/examples#UniversityDegreeCredential",
+				}},
+			}},
+		}
+
+		degree := createTestCredential(t, credentialProto{
+			Context: []string{verifiable.V1ContextURI, "https://www.w3.org/2018/credentials/examples/v1"},
+			Types:   []string{verifiable.VCType, "UniversityDegreeCredential"},
+			ID:      uuid.New().String(),
+		})
+
+		ps, matched, err := pd.MatchCredentials([]*verifiable.Credential{
+			createTestCredential(t, credentialProto{
+				Context: []string{verifiable.V1ContextURI, "https://trustbloc.github.io/context/vc/examples-v1.jsonld"},
+				Types:   []string{verifiable.VCType, "DocumentVerification"},
+				ID:      uuid.New().String(),
+			}),
+			degree,
+		}, lddl)
+		require.NoError(t, err)
+		require.Equal(t, []*verifiable.Credential{degree}, matched)
+
+		require.NotEmpty(t, ps.ID)
+		require.Equal(t, pd.ID, ps.DefinitionID)
+		require.Len(t, ps.DescriptorMap, 1)
+		require.Equal(t, pd.InputDescriptors[0].ID, ps.DescriptorMap[0].ID)
+		require.Equal(t, "$", ps.DescriptorMap[0].Path)
+		require.Equal(t, FormatLDPVP, ps.DescriptorMap[0].Format)
+		require.Equal(t, "$.verifiableCredential[0]", ps.DescriptorMap[0].PathNested.Path)
+		require.Equal(t, FormatLDPVC, ps.DescriptorMap[0].PathNested.Format)
+	})
+
+	t.Run("SD-JWT: Limit Disclosure", func(t *testing.T) {
+		required := Required
+
+		pd := &PresentationDefinition{
+			ID: uuid.New().String(),
+			InputDescriptors: []*InputDescriptor{{
+				ID: uuid.New().String(),
+				Schema: []*Schema{{
+					URI: fmt.Sprintf("%s#%s", verifiable.V1ContextID, verifiable.VCType),
+				}},
+				Constraints: &Constraints{
+					LimitDisclosure: &required,
+					Fields: []*Field{{
+						Path: []string{
+							"$.credentialSubject.family_name",
+							"$.credentialSubject.given_name",
+						},
+					}},
+				},
+			}},
+		}
+
+		ed25519ProofCreator, proofChecker := testsupport.NewKMSSigVerPair(t, kms.ED25519Type, testsupport.AnyPubKeyID)
+
+		sdJwtVC := newSdJwtVC(t, ed25519ProofCreator, proofChecker)
+
+		ps, matched, err := pd.MatchCredentials([]*verifiable.Credential{sdJwtVC},
+			lddl, WithSDCredentialOptions(verifiable.WithJSONLDDocumentLoader(createTestJSONLDDocumentLoader(t))))
+		require.NoError(t, err)
+		require.Len(t, matched, 1)
+		require.Len(t, ps.DescriptorMap, 1)
+
+		require.Len(t, matched[0].SDJWTDisclosures(), 2)
+		require.Len(t, sdJwtVC.SDJWTDisclosures(), 10, "held credential is not modified")
+
+		_, ok := matched[0].Contents().Subject[0].CustomFields["email"]
+		require.False(t, ok)
+	})
+
+	t.Run("No matching credentials", func(t *testing.T) {
+		pd := &PresentationDefinition{
+			ID: uuid.New().String(),
+			InputDescriptors: []*InputDescriptor{{
+				ID: uuid.New().String(),
+				Schema: []*Schema{{
+					URI: "https://example.org/examples#UniversityDegreeCredential",
+				}},
+			}},
+		}
+
+		_, _, err := pd.MatchCredentials([]*verifiable.Credential{
+			createTestCredential(t, credentialProto{
+				Context: []string{verifiable.V1ContextURI, "https://trustbloc.github.io/context/vc/examples-v1.jsonld"},
+				Types:   []string{verifiable.VCType, "DocumentVerification"},
+				ID:      uuid.New().String(),
+			}),
+		}, lddl)
+		require.ErrorIs(t, err, ErrNoCredentials)
+	})
+}
+
 func TestExtractExtraFields(t *testing.T) {
 	results := ExtractArrayValuesForSDJWTV5(map[string]interface{}{
 		"_sd": []interface{}{
@@ -0,0 +1,162 @@
+/*
+Copyright Gen Digital Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package presexch
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/piprate/json-gold/ld"
+
+	"github.com/trustbloc/vc-go/presexch/internal/requirementlogic"
+	"github.com/trustbloc/vc-go/verifiable"
+)
+
+// ErrSubmissionMismatch is returned by VerifySubmission when a presentation submission does not satisfy
+// the presentation definition it was submitted for.
+var ErrSubmissionMismatch = errors.New("presentation submission does not satisfy presentation definition")
+
+// VerifySubmission verifies that the submission of the presentation vp satisfies the presentation definition
+// defn. Each credential referenced by the descriptor_map of the submission is selected from vp with the JSONPath
+// of its mapping, and is checked against the format, the schemas and the constraints of the input descriptor
+// it is mapped to. The input descriptors of the credentials must then meet the submission requirements of defn.
+//
+// The credentials are parsed with the credential options given with WithCredentialOptions, and the schemas are
+// not validated with WithDisableSchemaValidation. A submission which does not satisfy defn fails with an error
+// wrapping ErrSubmissionMismatch.
+func VerifySubmission( //nolint:gocyclo
+	defn *PresentationDefinition,
+	submission *PresentationSubmission,
+	vp *verifiable.Presentation,
+	contextLoader ld.DocumentLoader,
+	options ...MatchOption,
+) error {
+	if defn == nil || submission == nil || vp == nil {
+		return errors.New("presentation definition, submission and presentation are required")
+	}
+
+	opts := &MatchOptions{}
+
+	for i := range options {
+		options[i](opts)
+	}
+
+	if submission.DefinitionID != defn.ID {
+		return fmt.Errorf("%w: submission definition_id [%s] does not match presentation definition id [%s]",
+			ErrSubmissionMismatch, submission.DefinitionID, defn.ID)
+	}
+
+	typelessVP, err := getTypelessVP(vp)
+	if err != nil {
+		return err
+	}
+
+	var submittedIDs []string
+
+	for _, mapping := range submission.DescriptorMap {
+		inputDescriptor := defn.inputDescriptor(mapping.ID)
+		if inputDescriptor == nil {
+			return fmt.Errorf("%w: an %s ID was found that did not match the `id` property of any input descriptor: %s",
+				ErrSubmissionMismatch, descriptorMapProperty, mapping.ID)
+		}
+
+		root := typelessVP
+		if descriptorMappingExpectsVPList(mapping) {
+			root = []interface{}{typelessVP}
+		}
+
+		vc, selectErr := selectVC(root, mapping, opts)
+		if selectErr != nil {
+			return fmt.Errorf("%w: input descriptor id [%s]: %w", ErrSubmissionMismatch, mapping.ID, selectErr)
+		}
+
+		err = verifySubmittedCredential(defn, inputDescriptor, mapping, vc, contextLoader, opts)
+		if err != nil {
+			return fmt.Errorf("%w: input descriptor id [%s]: %w", ErrSubmissionMismatch, mapping.ID, err)
+		}
+
+		submittedIDs = append(submittedIDs, mapping.ID)
+	}
+
+	req, err := makeRequirement(defn.SubmissionRequirements, defn.InputDescriptors)
+	if err != nil {
+		return err
+	}
+
+	if !req.toLogic().IsSatisfiedBy(requirementlogic.InitFromSlice(submittedIDs)) {
+		return fmt.Errorf("%w: submitted input descriptors %v do not meet submission requirements",
+			ErrSubmissionMismatch, submittedIDs)
+	}
+
+	return nil
+}
+
+func verifySubmittedCredential(
+	defn *PresentationDefinition,
+	inputDescriptor *InputDescriptor,
+	mapping *InputDescriptorMapping,
+	vc *verifiable.Credential,
+	contextLoader ld.DocumentLoader,
+	opts *MatchOptions,
+) error {
+	for mapping.PathNested != nil {
+		mapping = mapping.PathNested
+	}
+
+	if !credentialHasFormat(vc, mapping.Format) {
+		return fmt.Errorf("credential selected by path [%s] is not of format %s", mapping.Path, mapping.Format)
+	}
+
+	format := defn.Format
+	if inputDescriptor.Format.notNil() {
+		format = inputDescriptor.Format
+	}
+
+	if format.notNil() {
+		if _, matched := filterFormat(format, []*verifiable.Credential{vc}); len(matched) == 0 {
+			return errors.New("credential format is not allowed by presentation definition")
+		}
+	}
+
+	if len(inputDescriptor.Schema) > 0 && !opts.DisableSchemaValidation {
+		passed, err := filterSchema(inputDescriptor.Schema, []*verifiable.Credential{vc}, contextLoader)
+		if err != nil {
+			return err
+		}
+
+		if len(passed) == 0 {
+			vcc := vc.Contents()
+
+			return fmt.Errorf("schemas %+v do not match vc with @context [%+v] and types [%+v]",
+				inputDescriptor.Schema, vcc.Context, vcc.Types)
+		}
+	}
+
+	filtered, _, err := filterConstraints(inputDescriptor.Constraints, []*verifiable.Credential{vc})
+	if err != nil {
+		return err
+	}
+
+	if len(filtered) != 1 {
+		return errors.New("credential does not satisfy constraints")
+	}
+
+	return nil
+}
+
+// credentialHasFormat returns whether vc is of the format declared by its descriptor mapping. The formats not
+// defined by this package are not checked.
+func credentialHasFormat(vc *verifiable.Credential, format string) bool {
+	switch format {
+	case FormatJWT, FormatJWTVC:
+		return vc.IsJWT()
+	case FormatLDP, FormatLDPVC:
+		return !vc.IsJWT() && !vc.IsCWT()
+	default:
+		return true
+	}
+}
@@ -0,0 +1,265 @@
+/*
+Copyright Gen Digital Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package presexch_test
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+	utiltime "github.com/trustbloc/did-go/doc/util/time"
+	"github.com/trustbloc/kms-go/spi/kms"
+
+	. "github.com/trustbloc/vc-go/presexch"
+	"github.com/trustbloc/vc-go/proof/testsupport"
+	"github.com/trustbloc/vc-go/verifiable"
+)
+
+func TestVerifySubmission(t *testing.T) {
+	lddl := createTestJSONLDDocumentLoader(t)
+	intFilterType := "integer"
+
+	newDefinition := func() *PresentationDefinition {
+		return &PresentationDefinition{
+			ID: uuid.New().String(),
+			InputDescriptors: []*InputDescriptor{
+				{
+					ID: "degree",
+					Schema: []*Schema{{
+						URI: "https://example.org/examples#UniversityDegreeCredential",
+					}},
+				},
+				{
+					ID: "adult",
+					Schema: []*Schema{{
+						URI: fmt.Sprintf("%s#%s", verifiable.V1ContextID, verifiable.VCType),
+					}},
+					Constraints: &Constraints{
+						Fields: []*Field{{
+							Path: []string{"$.age"},
+							Filter: &Filter{
+								FilterItem: FilterItem{
+									Type:    &intFilterType,
+									Minimum: 18,
+								},
+							},
+						}},
+					},
+				},
+			},
+		}
+	}
+
+	degree := createTestCredential(t, credentialProto{
+		Context: []string{verifiable.V1ContextURI, "https://www.w3.org/2018/credentials/examples/v1"},
+		Types:   []string{verifiable.VCType, "UniversityDegreeCredential"},
+		ID:      "http://example.edu/credentials/degree",
+		Issued:  utiltime.NewTime(time.Now()),
+		Subject: []verifiable.Subject{{ID: "did:example:ebfeb1f712ebc6f1c276e12ec21"}},
+		Issuer:  &verifiable.Issuer{ID: "did:example:76e12ec712ebc6f1c221ebfeb1f"},
+		CustomFields: map[string]interface{}{
+			"age": 17,
+		},
+	})
+
+	adult := createTestCredential(t, credentialProto{
+		Context: []string{verifiable.V1ContextURI},
+		Types:   []string{verifiable.VCType},
+		ID:      "http://example.edu/credentials/adult",
+		Issued:  utiltime.NewTime(time.Now()),
+		Subject: []verifiable.Subject{{ID: "did:example:ebfeb1f712ebc6f1c276e12ec21"}},
+		Issuer:  &verifiable.Issuer{ID: "did:example:76e12ec712ebc6f1c221ebfeb1f"},
+		CustomFields: map[string]interface{}{
+			"age": 21,
+		},
+	})
+
+	matchOpts := []MatchOption{
+		WithCredentialOptions(verifiable.WithDisabledProofCheck(), verifiable.WithJSONLDDocumentLoader(lddl)),
+	}
+
+	createSubmission := func(t *testing.T, pd *PresentationDefinition) (*PresentationSubmission,
+		*verifiable.Presentation) {
+		t.Helper()
+
+		vp, err := pd.CreateVP([]*verifiable.Credential{degree, adult}, lddl)
+		require.NoError(t, err)
+
+		ps, ok := vp.CustomFields["presentation_submission"].(*PresentationSubmission)
+		require.True(t, ok)
+
+		return ps, vp
+	}
+
+	descriptorMapping := func(ps *PresentationSubmission, id string) *InputDescriptorMapping {
+		for _, mapping := range ps.DescriptorMap {
+			if mapping.ID == id {
+				return mapping
+			}
+		}
+
+		return nil
+	}
+
+	t.Run("success", func(t *testing.T) {
+		pd := newDefinition()
+		ps, vp := createSubmission(t, pd)
+
+		require.NoError(t, VerifySubmission(pd, ps, vp, lddl, matchOpts...))
+	})
+
+	t.Run("success with presentation list root", func(t *testing.T) {
+		pd := newDefinition()
+		ps, vp := createSubmission(t, pd)
+
+		for _, mapping := range ps.DescriptorMap {
+			mapping.Path = "$[0]"
+		}
+
+		require.NoError(t, VerifySubmission(pd, ps, vp, lddl, matchOpts...))
+	})
+
+	t.Run("success with submission requirements", func(t *testing.T) {
+		pd := newDefinition()
+		pd.InputDescriptors[0].Group = []string{"A"}
+		pd.InputDescriptors[1].Group = []string{"A"}
+		pd.SubmissionRequirements = []*SubmissionRequirement{{Rule: Pick, Count: 1, From: "A"}}
+
+		ps, vp := createSubmission(t, pd)
+		require.Len(t, ps.DescriptorMap, 1)
+
+		require.NoError(t, VerifySubmission(pd, ps, vp, lddl, matchOpts...))
+	})
+
+	t.Run("credential does not satisfy constraints", func(t *testing.T) {
+		pd := newDefinition()
+		ps, vp := createSubmission(t, pd)
+
+		descriptorMapping(ps, "adult").PathNested.Path = descriptorMapping(ps, "degree").PathNested.Path
+
+		err := VerifySubmission(pd, ps, vp, lddl, matchOpts...)
+		require.ErrorIs(t, err, ErrSubmissionMismatch)
+		require.ErrorContains(t, err, "input descriptor id [adult]: credential does not satisfy constraints")
+	})
+
+	t.Run("credential does not match schema", func(t *testing.T) {
+		pd := newDefinition()
+		ps, vp := createSubmission(t, pd)
+
+		descriptorMapping(ps, "degree").PathNested.Path = descriptorMapping(ps, "adult").PathNested.Path
+
+		err := VerifySubmission(pd, ps, vp, lddl, matchOpts...)
+		require.ErrorIs(t, err, ErrSubmissionMismatch)
+		require.ErrorContains(t, err, "input descriptor id [degree]: schemas")
+
+		require.NoError(t, VerifySubmission(pd, ps, vp, lddl,
+			append(matchOpts, WithDisableSchemaValidation())...))
+	})
+
+	t.Run("credential not of mapping format", func(t *testing.T) {
+		pd := newDefinition()
+		ps, vp := createSubmission(t, pd)
+
+		descriptorMapping(ps, "adult").PathNested.Format = FormatJWTVC
+
+		err := VerifySubmission(pd, ps, vp, lddl, matchOpts...)
+		require.ErrorIs(t, err, ErrSubmissionMismatch)
+		require.ErrorContains(t, err, "is not of format jwt_vc")
+	})
+
+	t.Run("credential format not allowed", func(t *testing.T) {
+		pd := newDefinition()
+		ps, vp := createSubmission(t, pd)
+
+		pd.Format = &Format{JwtVC: &JwtType{Alg: []string{"EdDSA"}}}
+
+		err := VerifySubmission(pd, ps, vp, lddl, matchOpts...)
+		require.ErrorIs(t, err, ErrSubmissionMismatch)
+		require.ErrorContains(t, err, "credential format is not allowed by presentation definition")
+	})
+
+	t.Run("JWT credential format", func(t *testing.T) {
+		proofCreator, _ := testsupport.NewKMSSigVerPair(t, kms.ED25519Type, testsupport.AnyPubKeyID)
+
+		adultJWT, err := adult.CreateSignedJWTVC(true, verifiable.EdDSA, proofCreator, "did:example:123#key-1")
+		require.NoError(t, err)
+
+		pd := newDefinition()
+		pd.InputDescriptors[0].Schema = nil
+		pd.InputDescriptors[1].Schema = nil
+		pd.InputDescriptors[1].Format = &Format{JwtVC: &JwtType{Alg: []string{"EdDSA"}}}
+
+		vp, err := pd.CreateVP([]*verifiable.Credential{degree, adultJWT}, lddl)
+		require.NoError(t, err)
+
+		ps, ok := vp.CustomFields["presentation_submission"].(*PresentationSubmission)
+		require.True(t, ok)
+		require.Equal(t, FormatJWTVC, descriptorMapping(ps, "adult").PathNested.Format)
+
+		require.NoError(t, VerifySubmission(pd, ps, vp, lddl, matchOpts...))
+
+		pd.InputDescriptors[1].Format = &Format{JwtVC: &JwtType{Alg: []string{"ES256"}}}
+
+		require.ErrorIs(t, VerifySubmission(pd, ps, vp, lddl, matchOpts...), ErrSubmissionMismatch)
+	})
+
+	t.Run("submission requirements not met", func(t *testing.T) {
+		pd := newDefinition()
+		ps, vp := createSubmission(t, pd)
+
+		ps.DescriptorMap = []*InputDescriptorMapping{descriptorMapping(ps, "degree")}
+
+		err := VerifySubmission(pd, ps, vp, lddl, matchOpts...)
+		require.ErrorIs(t, err, ErrSubmissionMismatch)
+		require.ErrorContains(t, err, "do not meet submission requirements")
+	})
+
+	t.Run("definition id mismatch", func(t *testing.T) {
+		pd := newDefinition()
+		ps, vp := createSubmission(t, pd)
+
+		ps.DefinitionID = uuid.New().String()
+
+		err := VerifySubmission(pd, ps, vp, lddl, matchOpts...)
+		require.ErrorIs(t, err, ErrSubmissionMismatch)
+		require.ErrorContains(t, err, "does not match presentation definition id")
+	})
+
+	t.Run("unknown input descriptor", func(t *testing.T) {
+		pd := newDefinition()
+		ps, vp := createSubmission(t, pd)
+
+		descriptorMapping(ps, "adult").ID = "unknown"
+
+		err := VerifySubmission(pd, ps, vp, lddl, matchOpts...)
+		require.ErrorIs(t, err, ErrSubmissionMismatch)
+		require.ErrorContains(t, err, "did not match the `id` property of any input descriptor: unknown")
+	})
+
+	t.Run("credential not found by path", func(t *testing.T) {
+		pd := newDefinition()
+		ps, vp := createSubmission(t, pd)
+
+		descriptorMapping(ps, "adult").PathNested.Path = "$.unknown"
+
+		err := VerifySubmission(pd, ps, vp, lddl, matchOpts...)
+		require.ErrorIs(t, err, ErrSubmissionMismatch)
+		require.ErrorContains(t, err, "failed to select vc from submission")
+	})
+
+	t.Run("missing arguments", func(t *testing.T) {
+		pd := newDefinition()
+		ps, vp := createSubmission(t, pd)
+
+		require.EqualError(t, VerifySubmission(nil, ps, vp, lddl),
+			"presentation definition, submission and presentation are required")
+		require.Error(t, VerifySubmission(pd, nil, vp, lddl))
+		require.Error(t, VerifySubmission(pd, ps, nil, lddl))
+	})
+}
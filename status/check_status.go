@@ -0,0 +1,190 @@
+/*
+Copyright Gen Digital Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package status
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/trustbloc/vc-go/verifiable"
+
+	"github.com/trustbloc/vc-go/status/api"
+	"github.com/trustbloc/vc-go/status/internal/bitstring"
+	"github.com/trustbloc/vc-go/status/validator/bitstringstatuslist"
+	"github.com/trustbloc/vc-go/status/validator/statuslist2021"
+)
+
+// StatusListResolver resolves the status list VC referenced by a credential status entry.
+type StatusListResolver = api.StatusListVCURIResolver
+
+// StatusResult is the result of CheckStatus.
+type StatusResult struct {
+	// Revoked is true if the status bit is set in a status list with the revocation purpose.
+	Revoked bool
+	// Suspended is true if the status bit is set in a status list with the suspension purpose.
+	Suspended bool
+}
+
+// statusListFormat describes how a status list format is validated and decoded.
+type statusListFormat struct {
+	validator   api.Validator
+	subjectType string
+	decode      func(encodedList string) ([]byte, error)
+	bitAt       func(bitString []byte, idx int) (bool, error)
+}
+
+// statusListFormats holds the status list formats supported by CheckStatus, by credential status type.
+var statusListFormats = map[string]*statusListFormat{ //nolint:gochecknoglobals
+	bitstringstatuslist.BitstringStatusListEntryType: {
+		validator:   &bitstringstatuslist.Validator{},
+		subjectType: bitstringstatuslist.BitstringStatusListSubjectType,
+		decode:      bitstring.DecodeMultibase,
+		bitAt:       bitstring.BitAtMSBFirst,
+	},
+	statuslist2021.StatusList2021Type: {
+		validator:   &statuslist2021.Validator{},
+		subjectType: statuslist2021.StatusList2021SubjectType,
+		decode:      bitstring.Decode,
+		bitAt:       bitstring.BitAt,
+	},
+}
+
+// CheckStatus checks the BitstringStatusListEntry and StatusList2021Entry statuses of the given
+// Verifiable Credential, as per spec: https://www.w3.org/TR/vc-bitstring-status-list/#validate-algorithm.
+// Statuses of other types are ignored.
+//
+// For every entry, the status list VC is fetched using the resolver and its proof is checked
+// using opts (e.g. verifiable.WithDataIntegrityVerifier and verifiable.WithJSONLDDocumentLoader).
+// A status bit which is set is reported in the result by the purpose of the entry.
+func CheckStatus(
+	vc *verifiable.Credential,
+	resolver StatusListResolver,
+	opts ...verifiable.CredentialOpt,
+) (StatusResult, error) {
+	var result StatusResult
+
+	found := false
+
+	for _, status := range vc.Contents().Status {
+		if status == nil {
+			continue
+		}
+
+		format, ok := statusListFormats[status.Type]
+		if !ok {
+			continue
+		}
+
+		found = true
+
+		purpose, bitSet, err := checkStatusListEntry(vc, status, format, resolver, opts)
+		if err != nil {
+			return StatusResult{}, err
+		}
+
+		switch purpose {
+		case StatusPurposeRevocation:
+			result.Revoked = result.Revoked || bitSet
+		case StatusPurposeSuspension:
+			result.Suspended = result.Suspended || bitSet
+		}
+	}
+
+	if !found {
+		return StatusResult{}, fmt.Errorf("vc missing %s or %s status",
+			bitstringstatuslist.BitstringStatusListEntryType, statuslist2021.StatusList2021Type)
+	}
+
+	return result, nil
+}
+
+func checkStatusListEntry( //nolint:gocyclo,funlen
+	credential *verifiable.Credential,
+	status *verifiable.TypedID,
+	format *statusListFormat,
+	resolver StatusListResolver,
+	opts []verifiable.CredentialOpt,
+) (string, bool, error) {
+	validator := format.validator
+
+	if err := validator.ValidateStatus(status); err != nil {
+		return "", false, err
+	}
+
+	purpose, err := validator.GetStatusPurpose(status)
+	if err != nil {
+		return "", false, err
+	}
+
+	if purpose != StatusPurposeRevocation && purpose != StatusPurposeSuspension {
+		return "", false, fmt.Errorf("unsupported status purpose: %s", purpose)
+	}
+
+	statusListIndex, err := validator.GetStatusListIndex(status)
+	if err != nil {
+		return "", false, err
+	}
+
+	statusVCURL, err := validator.GetStatusVCURI(status)
+	if err != nil {
+		return "", false, err
+	}
+
+	statusListVC, err := resolver.Resolve(statusVCURL)
+	if err != nil {
+		return "", false, err
+	}
+
+	if err = statusListVC.CheckProof(opts...); err != nil {
+		return "", false, fmt.Errorf("check status list vc proof: %w", err)
+	}
+
+	statusListVCC := statusListVC.Contents()
+	if statusListVCC.Issuer == nil || credential.Contents().Issuer == nil ||
+		statusListVCC.Issuer.ID != credential.Contents().Issuer.ID {
+		return "", false, errors.New("issuer of the credential does not match status list vc issuer")
+	}
+
+	if statusListVCC.Expired != nil && time.Now().After(statusListVCC.Expired.Time) {
+		return "", false, errors.New("status list vc is expired")
+	}
+
+	if len(statusListVCC.Subject) == 0 {
+		return "", false, errors.New("status list vc missing credential subject")
+	}
+
+	credSubject := statusListVCC.Subject[0]
+
+	subjectType, _ := credSubject.CustomFields["type"].(string)
+	if subjectType != format.subjectType {
+		return "", false, fmt.Errorf("status list vc subject type must be %s", format.subjectType)
+	}
+
+	listPurpose, _ := credSubject.CustomFields["statusPurpose"].(string)
+	if listPurpose != purpose {
+		return "", false, fmt.Errorf("status purpose %s does not match status list vc purpose %s",
+			purpose, listPurpose)
+	}
+
+	encodedList, ok := credSubject.CustomFields["encodedList"].(string)
+	if !ok {
+		return "", false, errors.New("encodedList must be a string")
+	}
+
+	bitString, err := format.decode(encodedList)
+	if err != nil {
+		return "", false, fmt.Errorf("failed to decode bits: %w", err)
+	}
+
+	bitSet, err := format.bitAt(bitString, statusListIndex)
+	if err != nil {
+		return "", false, err
+	}
+
+	return purpose, bitSet, nil
+}
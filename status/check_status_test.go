@@ -0,0 +1,391 @@
+/*
+Copyright Gen Digital Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package status_test
+
+import (
+	"errors"
+	"strconv"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"github.com/trustbloc/did-go/doc/did"
+	"github.com/trustbloc/did-go/doc/ld/documentloader"
+	mockldstore "github.com/trustbloc/did-go/doc/ld/mock"
+	"github.com/trustbloc/did-go/doc/ld/store"
+	vdr "github.com/trustbloc/did-go/vdr/mock"
+	kmsapi "github.com/trustbloc/kms-go/spi/kms"
+
+	"github.com/trustbloc/vc-go/dataintegrity"
+	"github.com/trustbloc/vc-go/dataintegrity/suite/ecdsa2019"
+	"github.com/trustbloc/vc-go/internal/testutil/kmscryptoutil"
+	"github.com/trustbloc/vc-go/verifiable"
+
+	"github.com/trustbloc/vc-go/status/internal/bitstring"
+	"github.com/trustbloc/vc-go/status/validator"
+	"github.com/trustbloc/vc-go/status/validator/bitstringstatuslist"
+	"github.com/trustbloc/vc-go/status/validator/statuslist2021"
+
+	. "github.com/trustbloc/vc-go/status"
+)
+
+const (
+	bitstringIssuerID   = "did:example:issuer"
+	revocationListURL   = "https://example.com/status/revocation"
+	suspensionListURL   = "https://example.com/status/suspension"
+	legacyListURL       = "https://example.com/status/2021"
+	bitstringVMFragment = "#key-1"
+)
+
+func TestCheckStatus(t *testing.T) {
+	kmsCrypto := kmscryptoutil.LocalKMSCrypto(t)
+
+	docLoader, err := documentloader.NewDocumentLoader(&ldProvider{
+		ContextStore:        mockldstore.NewMockContextStore(),
+		RemoteProviderStore: mockldstore.NewMockRemoteProviderStore(),
+	})
+	require.NoError(t, err)
+
+	key, err := kmsCrypto.Create(kmsapi.ECDSAP256IEEEP1363)
+	require.NoError(t, err)
+
+	vm, err := did.NewVerificationMethodFromJWK(bitstringIssuerID+bitstringVMFragment, "JsonWebKey2020",
+		bitstringIssuerID, key)
+	require.NoError(t, err)
+
+	didResolver := &vdr.VDRegistry{ResolveValue: &did.Doc{
+		ID:                 bitstringIssuerID,
+		VerificationMethod: []did.VerificationMethod{*vm},
+		AssertionMethod:    []did.Verification{{VerificationMethod: *vm, Relationship: did.AssertionMethod}},
+	}}
+
+	signer, err := dataintegrity.NewSigner(&dataintegrity.Options{
+		DIDResolver: didResolver,
+	}, ecdsa2019.NewSignerInitializer(&ecdsa2019.SignerInitializerOptions{
+		SignerGetter:     ecdsa2019.WithKMSCryptoWrapper(kmsCrypto),
+		LDDocumentLoader: docLoader,
+	}))
+	require.NoError(t, err)
+
+	verifier, err := dataintegrity.NewVerifier(&dataintegrity.Options{
+		DIDResolver: didResolver,
+	}, ecdsa2019.NewVerifierInitializer(&ecdsa2019.VerifierInitializerOptions{
+		LDDocumentLoader: docLoader,
+	}))
+	require.NoError(t, err)
+
+	verifyOpts := []verifiable.CredentialOpt{
+		verifiable.WithDataIntegrityVerifier(verifier),
+		verifiable.WithJSONLDDocumentLoader(docLoader),
+	}
+
+	signStatusList := func(t *testing.T, statusListVC *verifiable.Credential) *verifiable.Credential {
+		t.Helper()
+
+		require.NoError(t, statusListVC.AddDataIntegrityProof(&verifiable.DataIntegrityProofContext{
+			SigningKeyID: bitstringIssuerID + bitstringVMFragment,
+			CryptoSuite:  ecdsa2019.SuiteType,
+		}, signer))
+
+		return statusListVC
+	}
+
+	// Bits 3 of the revocation list and 5 of the suspension list are set.
+	lists := mapResolver{
+		revocationListURL: signStatusList(t,
+			mockBitstringStatusListVC(t, revocationListURL, StatusPurposeRevocation, 3)),
+		suspensionListURL: signStatusList(t,
+			mockBitstringStatusListVC(t, suspensionListURL, StatusPurposeSuspension, 5)),
+		legacyListURL: signStatusList(t, mockStatusList2021VC(t, legacyListURL, StatusPurposeRevocation, 6)),
+	}
+
+	t.Run("success", func(t *testing.T) {
+		result, err := CheckStatus(mockBitstringCredential(t,
+			bitstringStatusEntry(revocationListURL, StatusPurposeRevocation, "3"),
+			bitstringStatusEntry(suspensionListURL, StatusPurposeSuspension, "3"),
+		), lists, verifyOpts...)
+		require.NoError(t, err)
+		require.Equal(t, StatusResult{Revoked: true}, result)
+
+		result, err = CheckStatus(mockBitstringCredential(t,
+			bitstringStatusEntry(revocationListURL, StatusPurposeRevocation, "5"),
+			bitstringStatusEntry(suspensionListURL, StatusPurposeSuspension, "5"),
+		), lists, verifyOpts...)
+		require.NoError(t, err)
+		require.Equal(t, StatusResult{Suspended: true}, result)
+
+		result, err = CheckStatus(mockBitstringCredential(t,
+			bitstringStatusEntry(revocationListURL, StatusPurposeRevocation, "0"),
+		), lists, verifyOpts...)
+		require.NoError(t, err)
+		require.Equal(t, StatusResult{}, result)
+	})
+
+	t.Run("issued status list", func(t *testing.T) {
+		const grownIndex = 16*1024*8 + 10
+
+		statusListVC, err := NewStatusListCredential(revocationListURL, bitstringIssuerID,
+			StatusPurposeRevocation, []int{1, grownIndex})
+		require.NoError(t, err)
+
+		issuedLists := mapResolver{revocationListURL: signStatusList(t, statusListVC)}
+
+		checkIndex := func(t *testing.T, index string) StatusResult {
+			t.Helper()
+
+			result, err := CheckStatus(mockBitstringCredential(t,
+				bitstringStatusEntry(revocationListURL, StatusPurposeRevocation, index),
+			), issuedLists, verifyOpts...)
+			require.NoError(t, err)
+
+			return result
+		}
+
+		require.Equal(t, StatusResult{Revoked: true}, checkIndex(t, "1"))
+		require.Equal(t, StatusResult{Revoked: true}, checkIndex(t, strconv.Itoa(grownIndex)))
+		require.Equal(t, StatusResult{}, checkIndex(t, "2"))
+
+		statusListVC, err = UpdateStatus(issuedLists[revocationListURL], 1, false)
+		require.NoError(t, err)
+		require.Empty(t, statusListVC.Proofs())
+
+		statusListVC, err = UpdateStatus(statusListVC, 2, true)
+		require.NoError(t, err)
+
+		issuedLists[revocationListURL] = signStatusList(t, statusListVC)
+
+		require.Equal(t, StatusResult{}, checkIndex(t, "1"))
+		require.Equal(t, StatusResult{Revoked: true}, checkIndex(t, "2"))
+	})
+
+	t.Run("same status with Client.VerifyStatus", func(t *testing.T) {
+		statusListVC, err := NewStatusListCredential(revocationListURL, bitstringIssuerID,
+			StatusPurposeRevocation, []int{1, 8})
+		require.NoError(t, err)
+
+		issuedLists := mapResolver{revocationListURL: signStatusList(t, statusListVC)}
+
+		client := Client{
+			ValidatorGetter: validator.GetValidator,
+			Resolver:        issuedLists,
+		}
+
+		for index, revoked := range map[int]bool{0: false, 1: true, 7: false, 8: true, 9: false} {
+			vc := mockBitstringCredential(t,
+				bitstringStatusEntry(revocationListURL, StatusPurposeRevocation, strconv.Itoa(index)))
+
+			result, e := CheckStatus(vc, issuedLists, verifyOpts...)
+			require.NoError(t, e)
+			require.Equal(t, StatusResult{Revoked: revoked}, result, index)
+
+			e = client.VerifyStatus(vc)
+			if revoked {
+				require.ErrorIs(t, e, ErrRevoked, index)
+			} else {
+				require.NoError(t, e, index)
+			}
+		}
+	})
+
+	t.Run("StatusList2021", func(t *testing.T) {
+		legacyEntry := func(index string) *verifiable.TypedID {
+			return &verifiable.TypedID{
+				ID:   legacyListURL + "#" + index,
+				Type: statuslist2021.StatusList2021Type,
+				CustomFields: map[string]interface{}{
+					statuslist2021.StatusPurpose:        StatusPurposeRevocation,
+					statuslist2021.StatusListCredential: legacyListURL,
+					statuslist2021.StatusListIndex:      index,
+				},
+			}
+		}
+
+		result, err := CheckStatus(mockBitstringCredential(t, legacyEntry("6")), lists, verifyOpts...)
+		require.NoError(t, err)
+		require.Equal(t, StatusResult{Revoked: true}, result)
+
+		result, err = CheckStatus(mockBitstringCredential(t,
+			legacyEntry("1"),
+			bitstringStatusEntry(suspensionListURL, StatusPurposeSuspension, "5"),
+		), lists, verifyOpts...)
+		require.NoError(t, err)
+		require.Equal(t, StatusResult{Suspended: true}, result)
+
+		_, err = CheckStatus(mockBitstringCredential(t,
+			bitstringStatusEntry(legacyListURL, StatusPurposeRevocation, "6"),
+		), lists, verifyOpts...)
+		require.ErrorContains(t, err, "status list vc subject type must be BitstringStatusList")
+	})
+
+	t.Run("failure", func(t *testing.T) {
+		t.Run("no bitstring status entry", func(t *testing.T) {
+			_, err := CheckStatus(mockBitstringCredential(t), lists, verifyOpts...)
+			require.ErrorContains(t, err, "vc missing BitstringStatusListEntry or StatusList2021Entry status")
+		})
+
+		t.Run("invalid status entry", func(t *testing.T) {
+			entry := bitstringStatusEntry(revocationListURL, StatusPurposeRevocation, "3")
+			entry.CustomFields[bitstringstatuslist.StatusSize] = float64(2)
+
+			_, err := CheckStatus(mockBitstringCredential(t, entry), lists, verifyOpts...)
+			require.ErrorContains(t, err, "statusSize 2 not supported")
+
+			_, err = CheckStatus(mockBitstringCredential(t,
+				bitstringStatusEntry(revocationListURL, "refresh", "3")), lists, verifyOpts...)
+			require.ErrorContains(t, err, "unsupported status purpose: refresh")
+		})
+
+		t.Run("resolve status list", func(t *testing.T) {
+			_, err := CheckStatus(mockBitstringCredential(t,
+				bitstringStatusEntry("https://example.com/status/unknown", StatusPurposeRevocation, "3"),
+			), lists, verifyOpts...)
+			require.ErrorContains(t, err, "status list not found")
+		})
+
+		t.Run("status list proof", func(t *testing.T) {
+			unsigned := mapResolver{
+				revocationListURL: mockBitstringStatusListVC(t, revocationListURL, StatusPurposeRevocation, 3),
+			}
+
+			_, err := CheckStatus(mockBitstringCredential(t,
+				bitstringStatusEntry(revocationListURL, StatusPurposeRevocation, "3"),
+			), unsigned, verifyOpts...)
+			require.ErrorContains(t, err, "check status list vc proof")
+
+			tampered := signStatusList(t,
+				mockBitstringStatusListVC(t, revocationListURL, StatusPurposeRevocation, 3))
+			tamperedJSON := tampered.ToRawJSON()
+			tamperedJSON["credentialSubject"].(map[string]interface{})["encodedList"] = encodeBits(t, 4)
+
+			tampered, err = verifiable.ParseCredentialJSON(tamperedJSON, verifiable.WithDisabledProofCheck(),
+				verifiable.WithJSONLDDocumentLoader(docLoader))
+			require.NoError(t, err)
+
+			_, err = CheckStatus(mockBitstringCredential(t,
+				bitstringStatusEntry(revocationListURL, StatusPurposeRevocation, "4"),
+			), mapResolver{revocationListURL: tampered}, verifyOpts...)
+			require.ErrorContains(t, err, "check status list vc proof")
+		})
+
+		t.Run("status purpose mismatch", func(t *testing.T) {
+			_, err := CheckStatus(mockBitstringCredential(t,
+				bitstringStatusEntry(suspensionListURL, StatusPurposeRevocation, "5"),
+			), lists, verifyOpts...)
+			require.ErrorContains(t, err, "status purpose revocation does not match status list vc purpose suspension")
+		})
+
+		t.Run("index out of range", func(t *testing.T) {
+			_, err := CheckStatus(mockBitstringCredential(t,
+				bitstringStatusEntry(revocationListURL, StatusPurposeRevocation, "1000000"),
+			), lists, verifyOpts...)
+			require.ErrorContains(t, err, "position is invalid")
+		})
+	})
+}
+
+type mapResolver map[string]*verifiable.Credential
+
+func (m mapResolver) Resolve(statusListVCURL string) (*verifiable.Credential, error) {
+	vc, ok := m[statusListVCURL]
+	if !ok {
+		return nil, errors.New("status list not found")
+	}
+
+	return vc, nil
+}
+
+func bitstringStatusEntry(statusListURL, purpose, index string) *verifiable.TypedID {
+	return &verifiable.TypedID{
+		ID:   statusListURL + "#" + index,
+		Type: bitstringstatuslist.BitstringStatusListEntryType,
+		CustomFields: map[string]interface{}{
+			bitstringstatuslist.StatusPurpose:        purpose,
+			bitstringstatuslist.StatusListCredential: statusListURL,
+			bitstringstatuslist.StatusListIndex:      index,
+		},
+	}
+}
+
+func mockBitstringCredential(t *testing.T, status ...*verifiable.TypedID) *verifiable.Credential {
+	t.Helper()
+
+	return createTestCredential(t, verifiable.CredentialContents{
+		Context: []string{verifiable.V2ContextURI},
+		Types:   []string{verifiable.VCType},
+		Issuer:  &verifiable.Issuer{ID: bitstringIssuerID},
+		Status:  status,
+	})
+}
+
+func mockBitstringStatusListVC(t *testing.T, id, purpose string, setBit int) *verifiable.Credential {
+	t.Helper()
+
+	return createTestCredential(t, verifiable.CredentialContents{
+		Context: []string{verifiable.V2ContextURI},
+		ID:      id,
+		Types:   []string{verifiable.VCType, "BitstringStatusListCredential"},
+		Issuer:  &verifiable.Issuer{ID: bitstringIssuerID},
+		Subject: []verifiable.Subject{{
+			ID: id + "#list",
+			CustomFields: map[string]interface{}{
+				"type":          bitstringstatuslist.BitstringStatusListSubjectType,
+				"statusPurpose": purpose,
+				"encodedList":   encodeBits(t, setBit),
+			},
+		}},
+	})
+}
+
+func mockStatusList2021VC(t *testing.T, id, purpose string, setBit int) *verifiable.Credential {
+	t.Helper()
+
+	bits := make([]bool, 16*1024*8)
+	bits[setBit] = true
+
+	encodedList, err := bitstring.Encode(bool2bits(bits))
+	require.NoError(t, err)
+
+	return createTestCredential(t, verifiable.CredentialContents{
+		Context: []string{verifiable.V2ContextURI},
+		ID:      id,
+		Types:   []string{verifiable.VCType, "StatusList2021Credential"},
+		Issuer:  &verifiable.Issuer{ID: bitstringIssuerID},
+		Subject: []verifiable.Subject{{
+			ID: id + "#list",
+			CustomFields: map[string]interface{}{
+				"type":          statuslist2021.StatusList2021SubjectType,
+				"statusPurpose": purpose,
+				"encodedList":   encodedList,
+			},
+		}},
+	})
+}
+
+// encodeBits encodes a 16KB bitstring with the given bit set, index 0 being the left-most bit.
+func encodeBits(t *testing.T, setBit int) string {
+	t.Helper()
+
+	bits := make([]byte, 16*1024)
+	bits[setBit/8] |= 0x80 >> (setBit % 8)
+
+	encoded, err := bitstring.EncodeMultibase(bits)
+	require.NoError(t, err)
+
+	return encoded
+}
+
+type ldProvider struct {
+	ContextStore        store.ContextStore
+	RemoteProviderStore store.RemoteProviderStore
+}
+
+func (p *ldProvider) JSONLDContextStore() store.ContextStore {
+	return p.ContextStore
+}
+
+func (p *ldProvider) JSONLDRemoteProviderStore() store.RemoteProviderStore {
+	return p.RemoteProviderStore
+}
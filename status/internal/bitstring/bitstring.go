@@ -5,7 +5,7 @@ SPDX-License-Identifier: Apache-2.0
 */
 
 // Package bitstring provides functions for operating on byte slices as if they are 0-indexed arrays of bits,
-// packed 8 bits to a byte, LSB-first.
+// packed 8 bits to a byte, LSB-first (StatusList2021) or MSB-first (Bitstring Status List).
 package bitstring
 
 import (
@@ -13,11 +13,15 @@ import (
 	"compress/gzip"
 	"encoding/base64"
 	"errors"
+	"fmt"
+
+	"github.com/multiformats/go-multibase"
 )
 
 const (
 	bitsPerByte = 8
 	one         = 0x1
+	msb         = 0x80
 )
 
 // Decode decodes a compressed bitstring from a base64URL-encoded string.
@@ -27,6 +31,25 @@ func Decode(src string) ([]byte, error) {
 		return nil, err
 	}
 
+	return decompress(decodedBits)
+}
+
+// DecodeMultibase decodes a compressed bitstring from a multibase base64URL-encoded string,
+// as per spec: https://www.w3.org/TR/vc-bitstring-status-list/#bitstring-expansion-algorithm
+func DecodeMultibase(src string) ([]byte, error) {
+	encoding, decodedBits, err := multibase.Decode(src)
+	if err != nil {
+		return nil, err
+	}
+
+	if encoding != multibase.Base64url {
+		return nil, fmt.Errorf("unsupported multibase encoding %q, expected base64url", string(rune(encoding)))
+	}
+
+	return decompress(decodedBits)
+}
+
+func decompress(decodedBits []byte) ([]byte, error) {
 	b := bytes.NewReader(decodedBits)
 
 	zipReader, err := gzip.NewReader(b)
@@ -56,6 +79,45 @@ func BitAt(bitString []byte, idx int) (bool, error) {
 	return bitValue, nil
 }
 
+// BitAtMSBFirst returns the bit in the idx'th position (zero-indexed) in the given bitstring,
+// where index 0 is the left-most (most significant) bit of the first byte.
+func BitAtMSBFirst(bitString []byte, idx int) (bool, error) {
+	nByte := idx / bitsPerByte
+	nBit := idx % bitsPerByte
+
+	if idx < 0 || nByte >= len(bitString) {
+		return false, errors.New("position is invalid")
+	}
+
+	bitValue := (bitString[nByte] & (msb >> nBit)) != 0
+
+	return bitValue, nil
+}
+
+// SetBitMSBFirst sets or clears the bit in the idx'th position (zero-indexed) in the given bitstring,
+// where index 0 is the left-most (most significant) bit of the first byte. If idx is beyond the end of
+// the bitstring, the bitstring is grown with zero bytes to hold it. It returns the updated bitstring.
+func SetBitMSBFirst(bitString []byte, idx int, bitSet bool) ([]byte, error) {
+	if idx < 0 {
+		return nil, errors.New("position is invalid")
+	}
+
+	nByte := idx / bitsPerByte
+	nBit := idx % bitsPerByte
+
+	if nByte >= len(bitString) {
+		bitString = append(bitString, make([]byte, nByte+1-len(bitString))...)
+	}
+
+	if bitSet {
+		bitString[nByte] |= msb >> nBit
+	} else {
+		bitString[nByte] &^= msb >> nBit
+	}
+
+	return bitString, nil
+}
+
 // Encode gzips a bitstring and encodes it as a raw urlsafe base-64 string.
 func Encode(bitString []byte) (string, error) {
 	var buf bytes.Buffer
@@ -71,3 +133,13 @@ func Encode(bitString []byte) (string, error) {
 
 	return base64.RawURLEncoding.EncodeToString(buf.Bytes()), nil
 }
+
+// EncodeMultibase gzips a bitstring and encodes it as a multibase base64URL string.
+func EncodeMultibase(bitString []byte) (string, error) {
+	encoded, err := Encode(bitString)
+	if err != nil {
+		return "", err
+	}
+
+	return string(rune(multibase.Base64url)) + encoded, nil
+}
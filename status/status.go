@@ -98,12 +98,20 @@ func (c *Client) verifyStatus( //nolint:gocyclo,funlen
 		return errors.New("encodedList must be a string")
 	}
 
-	bitString, err := bitstring.Decode(encodedList)
+	decode, bitAt := bitstring.Decode, bitstring.BitAt
+
+	// The status lists of the formats checked by CheckStatus are decoded the same way, eg the multibase
+	// encoded and MSB-first lists of BitstringStatusListEntry.
+	if format, ok := statusListFormats[status.Type]; ok {
+		decode, bitAt = format.decode, format.bitAt
+	}
+
+	bitString, err := decode(encodedList)
 	if err != nil {
 		return fmt.Errorf("failed to decode bits: %w", err)
 	}
 
-	bitSet, err := bitstring.BitAt(bitString, statusListIndex)
+	bitSet, err := bitAt(bitString, statusListIndex)
 	if err != nil {
 		return err
 	}
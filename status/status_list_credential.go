@@ -0,0 +1,113 @@
+/*
+Copyright Gen Digital Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package status
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/trustbloc/vc-go/verifiable"
+
+	"github.com/trustbloc/vc-go/status/internal/bitstring"
+	"github.com/trustbloc/vc-go/status/validator/bitstringstatuslist"
+)
+
+// minStatusListSize is the minimum size in bytes (16KB) of a status list bitstring, as per spec:
+// https://www.w3.org/TR/vc-bitstring-status-list/#bitstring-generation-algorithm
+const minStatusListSize = 16 * 1024
+
+// NewStatusListCredential creates a BitstringStatusListCredential with the given id, issuer and
+// status purpose, where the bits at setIndices are set. The list grows beyond its minimum size of
+// 16KB if an index requires it. The returned credential has no proof: sign it, for instance
+// with Credential.AddDataIntegrityProof.
+func NewStatusListCredential(
+	id, issuer, purpose string,
+	setIndices []int,
+) (*verifiable.Credential, error) {
+	if purpose == "" {
+		return nil, errors.New("status purpose is required")
+	}
+
+	bitString := make([]byte, minStatusListSize)
+
+	for _, idx := range setIndices {
+		var err error
+
+		bitString, err = bitstring.SetBitMSBFirst(bitString, idx, true)
+		if err != nil {
+			return nil, fmt.Errorf("set status list index %d: %w", idx, err)
+		}
+	}
+
+	encodedList, err := bitstring.EncodeMultibase(bitString)
+	if err != nil {
+		return nil, fmt.Errorf("encode status list: %w", err)
+	}
+
+	return verifiable.CreateCredential(verifiable.CredentialContents{
+		Context: []string{verifiable.V2ContextURI},
+		ID:      id,
+		Types:   []string{verifiable.VCType, bitstringstatuslist.BitstringStatusListCredentialType},
+		Issuer:  &verifiable.Issuer{ID: issuer},
+		Subject: []verifiable.Subject{{
+			ID: id + "#list",
+			CustomFields: map[string]interface{}{
+				"type":          bitstringstatuslist.BitstringStatusListSubjectType,
+				"statusPurpose": purpose,
+				"encodedList":   encodedList,
+			},
+		}},
+	}, nil)
+}
+
+// UpdateStatus returns a copy of the given BitstringStatusListCredential, where the bit at index is
+// set or cleared. The list grows if index is beyond its end. As the status list changes, the returned
+// credential has no proof and must be signed again.
+func UpdateStatus(credential *verifiable.Credential, index int, set bool) (*verifiable.Credential, error) {
+	subjects := credential.Contents().Subject
+	if len(subjects) == 0 {
+		return nil, errors.New("status list vc missing credential subject")
+	}
+
+	subjectType, _ := subjects[0].CustomFields["type"].(string)
+	if subjectType != bitstringstatuslist.BitstringStatusListSubjectType {
+		return nil, fmt.Errorf("status list vc subject type must be %s",
+			bitstringstatuslist.BitstringStatusListSubjectType)
+	}
+
+	encodedList, ok := subjects[0].CustomFields["encodedList"].(string)
+	if !ok {
+		return nil, errors.New("encodedList must be a string")
+	}
+
+	bitString, err := bitstring.DecodeMultibase(encodedList)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode bits: %w", err)
+	}
+
+	bitString, err = bitstring.SetBitMSBFirst(bitString, index, set)
+	if err != nil {
+		return nil, fmt.Errorf("set status list index %d: %w", index, err)
+	}
+
+	encodedList, err = bitstring.EncodeMultibase(bitString)
+	if err != nil {
+		return nil, fmt.Errorf("encode status list: %w", err)
+	}
+
+	customFields := make(map[string]interface{}, len(subjects[0].CustomFields))
+	for k, v := range subjects[0].CustomFields {
+		customFields[k] = v
+	}
+
+	customFields["encodedList"] = encodedList
+
+	updatedSubjects := append([]verifiable.Subject{}, subjects...)
+	updatedSubjects[0].CustomFields = customFields
+
+	return credential.WithModifiedSubject(updatedSubjects), nil
+}
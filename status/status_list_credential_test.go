@@ -0,0 +1,91 @@
+/*
+Copyright Gen Digital Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package status_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/trustbloc/vc-go/verifiable"
+
+	"github.com/trustbloc/vc-go/status/internal/bitstring"
+	"github.com/trustbloc/vc-go/status/validator/bitstringstatuslist"
+
+	. "github.com/trustbloc/vc-go/status"
+)
+
+func TestNewStatusListCredential(t *testing.T) {
+	t.Run("success", func(t *testing.T) {
+		vc, err := NewStatusListCredential(revocationListURL, bitstringIssuerID, StatusPurposeRevocation, []int{0, 9})
+		require.NoError(t, err)
+
+		vcc := vc.Contents()
+		require.Equal(t, revocationListURL, vcc.ID)
+		require.Equal(t, bitstringIssuerID, vcc.Issuer.ID)
+		require.Equal(t, []string{verifiable.VCType, bitstringstatuslist.BitstringStatusListCredentialType}, vcc.Types)
+		require.Len(t, vcc.Subject, 1)
+		require.Equal(t, bitstringstatuslist.BitstringStatusListSubjectType, vcc.Subject[0].CustomFields["type"])
+		require.Equal(t, StatusPurposeRevocation, vcc.Subject[0].CustomFields["statusPurpose"])
+
+		bits, err := bitstring.DecodeMultibase(vcc.Subject[0].CustomFields["encodedList"].(string))
+		require.NoError(t, err)
+		require.Len(t, bits, 16*1024)
+		require.Equal(t, []byte{0x80, 0x40}, bits[:2])
+	})
+
+	t.Run("missing purpose", func(t *testing.T) {
+		_, err := NewStatusListCredential(revocationListURL, bitstringIssuerID, "", nil)
+		require.ErrorContains(t, err, "status purpose is required")
+	})
+
+	t.Run("negative index", func(t *testing.T) {
+		_, err := NewStatusListCredential(revocationListURL, bitstringIssuerID, StatusPurposeRevocation, []int{-1})
+		require.ErrorContains(t, err, "position is invalid")
+	})
+}
+
+func TestUpdateStatus(t *testing.T) {
+	vc, err := NewStatusListCredential(revocationListURL, bitstringIssuerID, StatusPurposeRevocation, []int{3})
+	require.NoError(t, err)
+
+	t.Run("grow list", func(t *testing.T) {
+		updated, err := UpdateStatus(vc, 16*1024*8, true)
+		require.NoError(t, err)
+
+		bits, err := bitstring.DecodeMultibase(updated.Contents().Subject[0].CustomFields["encodedList"].(string))
+		require.NoError(t, err)
+		require.Len(t, bits, 16*1024+1)
+		require.Equal(t, byte(0x10), bits[0])
+		require.Equal(t, byte(0x80), bits[16*1024])
+
+		original, err := bitstring.DecodeMultibase(vc.Contents().Subject[0].CustomFields["encodedList"].(string))
+		require.NoError(t, err)
+		require.Len(t, original, 16*1024)
+	})
+
+	t.Run("not a bitstring status list", func(t *testing.T) {
+		_, err := UpdateStatus(mockStatusList2021VC(t, legacyListURL, StatusPurposeRevocation, 1), 1, true)
+		require.ErrorContains(t, err, "subject type must be BitstringStatusList")
+	})
+
+	t.Run("missing subject", func(t *testing.T) {
+		_, err := UpdateStatus(vc.WithModifiedSubject(nil), 1, true)
+		require.ErrorContains(t, err, "missing credential subject")
+	})
+
+	t.Run("invalid encoded list", func(t *testing.T) {
+		subject := vc.Contents().Subject
+		subject[0].CustomFields = map[string]interface{}{
+			"type":        bitstringstatuslist.BitstringStatusListSubjectType,
+			"encodedList": "not encoded",
+		}
+
+		_, err := UpdateStatus(vc.WithModifiedSubject(subject), 1, true)
+		require.ErrorContains(t, err, "failed to decode bits")
+	})
+}
@@ -0,0 +1,114 @@
+/*
+Copyright Gen Digital Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+// Package bitstringstatuslist handles client-side validation and parsing for
+// Credential Status fields of type BitstringStatusListEntryType, as per spec:
+// https://www.w3.org/TR/vc-bitstring-status-list/
+package bitstringstatuslist
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+
+	"github.com/trustbloc/vc-go/verifiable"
+)
+
+const (
+	// BitstringStatusListEntryType represents the implementation of Bitstring Status List.
+	//  VC.Status.Type
+	// 	Doc: https://www.w3.org/TR/vc-bitstring-status-list/#bitstringstatuslistentry
+	BitstringStatusListEntryType = "BitstringStatusListEntry"
+
+	// BitstringStatusListCredentialType is the type of the status list VC.
+	BitstringStatusListCredentialType = "BitstringStatusListCredential"
+
+	// BitstringStatusListSubjectType is the type of the credential subject of the status list VC.
+	BitstringStatusListSubjectType = "BitstringStatusList"
+
+	// StatusListCredential stores the link to the status list VC.
+	//  VC.Status.CustomFields key.
+	StatusListCredential = "statusListCredential"
+
+	// StatusListIndex identifies the bit position of the status value of the VC.
+	//  VC.Status.CustomFields key.
+	StatusListIndex = "statusListIndex"
+
+	// StatusPurpose for Bitstring Status List.
+	//  VC.Status.CustomFields key. For example, "revocation", "suspension".
+	StatusPurpose = "statusPurpose"
+
+	// StatusSize is the size of the status entry in bits.
+	//  VC.Status.CustomFields key. Only the default size of 1 is supported.
+	StatusSize = "statusSize"
+)
+
+// Validator validates a Verifiable Credential's Status field against the Bitstring Status List specification, and
+// returns fields for status verification.
+//
+// Implements spec: https://www.w3.org/TR/vc-bitstring-status-list/#bitstringstatuslistentry
+type Validator struct{}
+
+// ValidateStatus validates that a Verifiable Credential's Status field matches the Bitstring Status List
+// specification.
+func (v *Validator) ValidateStatus(vcStatus *verifiable.TypedID) error {
+	if vcStatus == nil {
+		return errors.New("vc status does not exist")
+	}
+
+	if vcStatus.Type != BitstringStatusListEntryType {
+		return fmt.Errorf("vc status %s not supported", vcStatus.Type)
+	}
+
+	for _, field := range []string{StatusListCredential, StatusListIndex, StatusPurpose} {
+		if vcStatus.CustomFields[field] == nil {
+			return fmt.Errorf("%s field does not exist in vc status", field)
+		}
+	}
+
+	if size, ok := vcStatus.CustomFields[StatusSize]; ok {
+		if n, isNum := size.(float64); !isNum || n != 1 {
+			return fmt.Errorf("%s %v not supported", StatusSize, size)
+		}
+	}
+
+	return nil
+}
+
+// GetStatusVCURI returns the ID (URL) of status VC.
+func (v *Validator) GetStatusVCURI(vcStatus *verifiable.TypedID) (string, error) {
+	statusListVC, ok := vcStatus.CustomFields[StatusListCredential].(string)
+	if !ok {
+		return "", errors.New("failed to cast URI of statusListCredential")
+	}
+
+	return statusListVC, nil
+}
+
+// GetStatusListIndex returns the bit position of the status value of the VC.
+func (v *Validator) GetStatusListIndex(vcStatus *verifiable.TypedID) (int, error) {
+	statusListIndex, ok := vcStatus.CustomFields[StatusListIndex].(string)
+	if !ok {
+		return -1, fmt.Errorf("%s must be a string", StatusListIndex)
+	}
+
+	idx, err := strconv.Atoi(statusListIndex)
+	if err != nil {
+		return -1, fmt.Errorf("unable to get statusListIndex: %w", err)
+	}
+
+	return idx, nil
+}
+
+// GetStatusPurpose returns the purpose of the status list. For example, "revocation", "suspension".
+func (v *Validator) GetStatusPurpose(vcStatus *verifiable.TypedID) (string, error) {
+	statusPurpose, ok := vcStatus.CustomFields[StatusPurpose].(string)
+	if !ok {
+		return "", fmt.Errorf("%s must be a string", StatusPurpose)
+	}
+
+	return statusPurpose, nil
+}
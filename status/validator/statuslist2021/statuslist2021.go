@@ -22,6 +22,9 @@ const (
 	// 	Doc: https://w3c-ccg.github.io/vc-status-list-2021/
 	StatusList2021Type = "StatusList2021Entry"
 
+	// StatusList2021SubjectType is the type of the credential subject of the status list VC.
+	StatusList2021SubjectType = "StatusList2021"
+
 	// StatusListCredential stores the link to the status list VC.
 	//  VC.Status.CustomFields key.
 	StatusListCredential = "statusListCredential"
@@ -12,6 +12,7 @@ import (
 	"fmt"
 
 	"github.com/trustbloc/vc-go/status/api"
+	"github.com/trustbloc/vc-go/status/validator/bitstringstatuslist"
 	"github.com/trustbloc/vc-go/status/validator/statuslist2021"
 )
 
@@ -20,6 +21,8 @@ func GetValidator(statusType string) (api.Validator, error) { //nolint:ireturn
 	switch statusType {
 	case statuslist2021.StatusList2021Type:
 		return &statuslist2021.Validator{}, nil
+	case bitstringstatuslist.BitstringStatusListEntryType:
+		return &bitstringstatuslist.Validator{}, nil
 	default:
 		return nil, fmt.Errorf("unsupported VCStatusListType %s", statusType)
 	}
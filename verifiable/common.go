@@ -15,6 +15,8 @@ SPDX-License-Identifier: Apache-2.0
 package verifiable
 
 import (
+	"bytes"
+	"encoding/json"
 	"errors"
 	"fmt"
 	"strings"
@@ -296,11 +298,38 @@ func decodeContext(c interface{}) ([]string, []interface{}, error) {
 		return s, nil, nil
 	case []string:
 		return rContext, nil, nil
+	case map[string]interface{}:
+		// a single inline context object
+		return nil, []interface{}{rContext}, nil
 	default:
 		return nil, nil, errors.New("credential context of unknown type")
 	}
 }
 
+// appendExternalContexts returns the @context rawContext with the external contexts appended. The inline
+// context objects of rawContext are kept, including an inline context object given as the whole @context.
+func appendExternalContexts(rawContext interface{}, externalContexts ...string) []interface{} {
+	var contexts []interface{}
+
+	switch c := rawContext.(type) {
+	case nil:
+	case []interface{}:
+		contexts = append(contexts, c...)
+	case []string:
+		for _, ctx := range c {
+			contexts = append(contexts, ctx)
+		}
+	default:
+		contexts = append(contexts, c)
+	}
+
+	for _, ctx := range externalContexts {
+		contexts = append(contexts, ctx)
+	}
+
+	return contexts
+}
+
 func safeStringValue(v interface{}) string {
 	if v == nil {
 		return ""
@@ -314,6 +343,8 @@ func safeStringValue(v interface{}) string {
 	return value
 }
 
+// proofsToRaw returns the proofs as they are, including the fields that are not known to this package
+// (eg previousProof or vendor extensions), so that the proofs that are passed through are not mutated.
 func proofsToRaw(proofs []Proof) interface{} {
 	switch len(proofs) {
 	case 0:
@@ -327,6 +358,34 @@ func proofsToRaw(proofs []Proof) interface{} {
 	}
 }
 
+// decodeRawProof decodes the proof field of the JSON document keeping numbers as json.Number,
+// so that a number in a proof field is re-serialized exactly as it was.
+func decodeRawProof(docJSON []byte, fldName string) (interface{}, error) {
+	var fields map[string]json.RawMessage
+
+	err := json.Unmarshal(docJSON, &fields)
+	if err != nil {
+		return nil, err
+	}
+
+	proofRaw, ok := fields[fldName]
+	if !ok {
+		return nil, nil
+	}
+
+	decoder := json.NewDecoder(bytes.NewReader(proofRaw))
+	decoder.UseNumber()
+
+	var proof interface{}
+
+	err = decoder.Decode(&proof)
+	if err != nil {
+		return nil, err
+	}
+
+	return proof, nil
+}
+
 func parseLDProof(proofJSON interface{}) ([]Proof, error) {
 	if proofJSON == nil {
 		return nil, nil
@@ -174,6 +174,16 @@ func TestDecodeContext(t *testing.T) {
 		require.Equal(t, []interface{}{customContext}, extraContexts)
 	})
 
+	t.Run("Decode inline context object", func(t *testing.T) {
+		customContext := map[string]interface{}{
+			"image": map[string]interface{}{"@id": "schema:image", "@type": "@id"},
+		}
+		contexts, extraContexts, err := decodeContext(customContext)
+		require.NoError(t, err)
+		require.Empty(t, contexts)
+		require.Equal(t, []interface{}{customContext}, extraContexts)
+	})
+
 	t.Run("Decode context of invalid type", func(t *testing.T) {
 		contexts, extraContexts, err := decodeContext(55)
 		require.Error(t, err)
@@ -182,6 +192,22 @@ func TestDecodeContext(t *testing.T) {
 	})
 }
 
+func TestAppendExternalContexts(t *testing.T) {
+	const externalContext = "https://www.w3.org/2018/credentials/examples/v1"
+
+	customContext := map[string]interface{}{"favoriteColor": "https://example.com/vocab#favoriteColor"}
+
+	require.Equal(t, []interface{}{V1ContextURI, customContext, externalContext},
+		appendExternalContexts([]interface{}{V1ContextURI, customContext}, externalContext))
+	require.Equal(t, []interface{}{customContext, externalContext},
+		appendExternalContexts(customContext, externalContext))
+	require.Equal(t, []interface{}{V1ContextURI, externalContext},
+		appendExternalContexts(V1ContextURI, externalContext))
+	require.Equal(t, []interface{}{V1ContextURI, externalContext},
+		appendExternalContexts([]string{V1ContextURI}, externalContext))
+	require.Equal(t, []interface{}{externalContext}, appendExternalContexts(nil, externalContext))
+}
+
 func Test_safeStringValue(t *testing.T) {
 	var i interface{} = "str"
 
@@ -226,6 +252,17 @@ func Test_parseLDProof(t *testing.T) {
 		require.Len(t, proofs, 1)
 	})
 
+	t.Run("Success with single proof object", func(t *testing.T) {
+		singleProof := map[string]interface{}{
+			"proofValue": "eyJhbGciOiJFZERTQSIsImI2NCI6ZmFsc2UsImNyaXQiOlsiYjY0Il19..67TTULBvibJaJ2oZf3tGYhxZqxYS89qGQykL5hfCoh-MF0vrwQqzciZhjNrAGTAgHtDZsnSQVwJ8bO_7Sc0ECw", //nolint:lll
+		}
+
+		proofs, err := parseLDProof(singleProof)
+		require.NoError(t, err)
+		require.Equal(t, []Proof{singleProof}, proofs)
+		require.Equal(t, singleProof, proofsToRaw(proofs))
+	})
+
 	t.Run("unsupported proof value", func(t *testing.T) {
 		singleProof := []interface{}{
 			"eyJhbGciOiJFZERTQSIsImI2NCI6ZmFsc2UsImNyaXQiOlsiYjY0Il19", //nolint:lll
@@ -7,7 +7,9 @@ package verifiable
 
 import (
 	"bytes"
+	"context"
 	"crypto"
+	"encoding/base64"
 	"encoding/binary"
 	"encoding/hex"
 	"encoding/json"
@@ -25,6 +27,7 @@ import (
 	"github.com/trustbloc/did-go/doc/ld/processor"
 	"github.com/trustbloc/did-go/doc/ld/proof"
 	docjsonld "github.com/trustbloc/did-go/doc/ld/validator"
+	"github.com/trustbloc/did-go/pkg/canonicalizer"
 	"github.com/trustbloc/kms-go/doc/jose"
 	"github.com/veraison/go-cose"
 	"github.com/xeipuuv/gojsonschema"
@@ -33,11 +36,13 @@ import (
 
 	"github.com/trustbloc/vc-go/cwt"
 	"github.com/trustbloc/vc-go/dataintegrity"
+	"github.com/trustbloc/vc-go/dataintegrity/models"
 	"github.com/trustbloc/vc-go/jwt"
 	"github.com/trustbloc/vc-go/sdjwt/common"
 	jsonutil "github.com/trustbloc/vc-go/util/json"
 	cwt2 "github.com/trustbloc/vc-go/verifiable/cwt"
 	"github.com/trustbloc/vc-go/verifiable/lddocument"
+	"github.com/trustbloc/vc-go/vermethod"
 )
 
 var errLogger = log.New(os.Stderr, " [vc-go/verifiable] ", log.Ldate|log.Ltime|log.LUTC)
@@ -580,6 +585,8 @@ const (
 
 	// https://www.w3.org/TR/vc-json-schema/#jsonschema
 	jsonSchemaType = "JsonSchema"
+	// https://w3c.github.io/vc-json-schema/#jsonschema2023 (earlier drafts of JsonSchema)
+	jsonSchema2023Type = "JsonSchema2023"
 	// https://www.w3.org/TR/vc-json-schema/#jsonschemacredential
 	jsonSchemaCredentialType = "JsonSchemaCredential"
 )
@@ -613,6 +620,18 @@ const (
 	VCMediaTypeCOSE MediaType = "application/vc-ld+cose"
 )
 
+// vcMediaTypeAliases maps the media types of the VC-JOSE-COSE recommendation, which the wallets emit
+// in the data URL of their enveloped credentials, to the media types above.
+// See https://www.w3.org/TR/vc-jose-cose/#media-types.
+var vcMediaTypeAliases = map[MediaType]MediaType{
+	"application/vc+jwt":    VCMediaTypeJWT,
+	"application/vc+sd-jwt": VCMediaTypeSDJWT,
+	"application/vc+cose":   VCMediaTypeCOSE,
+}
+
+// base64Encoding is the encoding of the base64 data URLs.
+const base64Encoding Encoding = "base64"
+
 // vcModelValidationMode defines constraint put on context and type of VC.
 type vcModelValidationMode int
 
@@ -876,6 +895,8 @@ type Credential struct {
 	credentialJSON     JSONObject
 	credentialContents CredentialContents
 	ldProofs           []Proof
+	// envelopeMediaType is the media type of the data URL the credential was enveloped in, if any.
+	envelopeMediaType MediaType
 	//TODO: make this private. Currently used in tests to create invalid jwt vc's.
 	JWTEnvelope *JWTEnvelope
 	CWTEnvelope *CWTEnvelope
@@ -962,11 +983,17 @@ func (vc *Credential) ToJWTString() (string, error) {
 }
 
 // ToUniversalForm returns vc in its natural form. For jwt-vc it is a jwt string. For json-ld vc it is a json object.
+// A VC DM 2.0 jwt-vc or cwt-vc is enveloped, with the media type of the data URL it was parsed from, if any.
 func (vc *Credential) ToUniversalForm() (interface{}, error) {
 	switch {
 	case vc.IsCWT():
+		mediaType := VCMediaTypeCOSE
+		if vc.envelopeMediaType != "" {
+			mediaType = vc.envelopeMediaType
+		}
+
 		return vc.toEnvelopedForm(
-			VCMediaTypeCOSE,
+			mediaType,
 			func(vc *Credential) (string, error) {
 				return hex.EncodeToString(vc.CWTEnvelope.Sign1MessageRaw), nil
 			},
@@ -974,9 +1001,12 @@ func (vc *Credential) ToUniversalForm() (interface{}, error) {
 	case vc.IsJWT():
 		var mediaType MediaType
 
-		if len(vc.SDJWTDisclosures()) > 0 {
+		switch {
+		case vc.envelopeMediaType != "":
+			mediaType = vc.envelopeMediaType
+		case len(vc.SDJWTDisclosures()) > 0:
 			mediaType = VCMediaTypeSDJWT
-		} else {
+		default:
 			mediaType = VCMediaTypeJWT
 		}
 
@@ -1018,6 +1048,11 @@ func (vc *Credential) Proofs() []Proof {
 	return vc.ldProofs
 }
 
+// Subjects returns the subjects of the credential, one for each entry of a credentialSubject array.
+func (vc *Credential) Subjects() []Subject {
+	return vc.credentialContents.Subject
+}
+
 // IsJWT returns is vc envelop into jwt.
 func (vc *Credential) IsJWT() bool {
 	return vc.JWTEnvelope != nil
@@ -1057,6 +1092,22 @@ func (vc *Credential) SetSDJWTDisclosures(disclosures []*common.DisclosureClaim)
 	return nil
 }
 
+// WithSDJWTDisclosures returns a copy of the SD-JWT credential with the given disclosures, eg the subset
+// of its disclosures to present, leaving the credential unchanged.
+func (vc *Credential) WithSDJWTDisclosures(disclosures []*common.DisclosureClaim) (*Credential, error) {
+	if vc.JWTEnvelope == nil {
+		return nil, errors.New("non jws credentials not support sd jwt disclosure")
+	}
+
+	envelope := *vc.JWTEnvelope
+	envelope.SDJWTDisclosures = disclosures
+
+	newVC := *vc
+	newVC.JWTEnvelope = &envelope
+
+	return &newVC, nil
+}
+
 // CustomField returns custom field by name.
 func (vc *Credential) CustomField(name string) interface{} {
 	return vc.credentialJSON[name]
@@ -1115,15 +1166,27 @@ type credentialOpts struct {
 	allowedContexts      map[string]bool
 	allowedCustomTypes   map[string]bool
 	disabledProofCheck   bool
+	requireProof         bool
+	issuerKeyBinding     bool
+	issuerDIDResolver    didResolver
 	strictValidation     bool
 	defaultSchema        string
 	defaultSchemaLoader  func(vcc *CredentialContents) string
+	subjectSchemaLoader  SchemaLoader
 	disableValidation    bool
 	verifyDataIntegrity  *verifyDataIntegrityOpts
 
 	jsonldCredentialOpts
 	disableRelatedResourceCheck bool
 	enableJsonLDTypesCheck      bool
+	verificationConcurrency     int
+	termsOfUseEvaluator         TermsOfUseEvaluator
+	maxDocumentSize             int
+	subjectDIDResolution        bool
+	subjectDIDResolver          didResolver
+	normalizeDates              bool
+	trustedIssuers              map[string]bool
+	trustedIssuerMatcher        func(issuerID string) bool
 }
 
 // CredentialOpt is the Verifiable Credential decoding option.
@@ -1136,6 +1199,14 @@ func WithDisabledProofCheck() CredentialOpt {
 	}
 }
 
+// WithRequireProof option makes parsing fail if the credential has no embedded proof
+// and is not enveloped into a JWT or CWT.
+func WithRequireProof() CredentialOpt {
+	return func(opts *credentialOpts) {
+		opts.requireProof = true
+	}
+}
+
 // WithEnabledJSONLDTypesCheck option for enabling check of JSON-LD types.
 func WithEnabledJSONLDTypesCheck() CredentialOpt {
 	return func(opts *credentialOpts) {
@@ -1151,6 +1222,9 @@ func WithDisabledRelatedResourceCheck() CredentialOpt {
 }
 
 // WithCredDisableValidation options for disabling of JSON-LD and json-schema validation.
+// Together with WithDisabledProofCheck, it parses a credential without loading its JSON-LD contexts,
+// e.g. to inspect a malformed credential. The credential can be validated and its proof checked
+// later with Credential.ValidateCredential and Credential.CheckProof.
 func WithCredDisableValidation() CredentialOpt {
 	return func(opts *credentialOpts) {
 		opts.disableValidation = true
@@ -1242,6 +1316,14 @@ func WithDataIntegrityVerifier(v *dataintegrity.Verifier) CredentialOpt {
 	}
 }
 
+// WithVerificationContext sets the context bounding the verification of the Data Integrity
+// proofs, so that a slow DID or JSON-LD context endpoint is given up on its cancellation or deadline.
+func WithVerificationContext(ctx context.Context) CredentialOpt {
+	return func(opts *credentialOpts) {
+		opts.verifyDataIntegrity.Context = ctx
+	}
+}
+
 // WithExpectedDataIntegrityFields validates that a Data Integrity proof has the
 // given purpose, domain, and challenge. Empty purpose means the default,
 // assertionMethod, will be expected. Empty domain and challenge will mean they
@@ -1254,6 +1336,153 @@ func WithExpectedDataIntegrityFields(purpose, domain, challenge string) Credenti
 	}
 }
 
+// WithExpectedDataIntegrityNonce validates that a Data Integrity proof has the given nonce.
+// Empty nonce means it is not checked.
+func WithExpectedDataIntegrityNonce(nonce string) CredentialOpt {
+	return func(opts *credentialOpts) {
+		opts.verifyDataIntegrity.Nonce = nonce
+	}
+}
+
+// WithAcceptedDomains validates that a Data Integrity proof has one of the given domains,
+// e.g. the hostnames a verifier is served on. It takes precedence over the domain
+// of WithExpectedDataIntegrityFields. Empty domains mean the domain is not checked.
+func WithAcceptedDomains(domains ...string) CredentialOpt {
+	return func(opts *credentialOpts) {
+		opts.verifyDataIntegrity.AcceptedDomains = domains
+	}
+}
+
+// WithDataIntegrityProofMatching sets the policy applied when the credential
+// has several Data Integrity proofs. By default, all proofs must verify.
+func WithDataIntegrityProofMatching(policy DataIntegrityProofMatching) CredentialOpt {
+	return func(opts *credentialOpts) {
+		opts.verifyDataIntegrity.ProofMatching = policy
+	}
+}
+
+// WithProofCreatedTolerance allows the created time of a Data Integrity proof to be
+// up to d in the future relative to the verifier's clock. Default is zero tolerance.
+func WithProofCreatedTolerance(d time.Duration) CredentialOpt {
+	return func(opts *credentialOpts) {
+		opts.verifyDataIntegrity.CreatedTolerance = d
+	}
+}
+
+// WithAllowFutureCreated sets whether a Data Integrity proof may be created at any time in the
+// future relative to the verifier's clock. By default, a proof created later than the tolerance of
+// WithProofCreatedTolerance is rejected with ErrCodeCreatedInFuture.
+//
+// The proof times and the credential validity period are checked independently: the proof created
+// time is checked against this option and WithProofCreatedTolerance, and the proof expires time
+// against WithProofExpiryTolerance, when the proof is verified. The validFrom and validUntil of the
+// credential (issuanceDate and expirationDate for VC Data Model 1.1) are not checked by the proof
+// verification, but by Credential.CheckValidity. A pre-dated credential, issued now and valid from
+// a future time, thus has a proof created now and verifies without this option.
+func WithAllowFutureCreated(allow bool) CredentialOpt {
+	return func(opts *credentialOpts) {
+		opts.verifyDataIntegrity.AllowFutureCreated = allow
+	}
+}
+
+// WithLenientProofContext accepts the Data Integrity proofs of the credentials whose @context
+// doesn't define the proof terms, eg DataIntegrityProof and proofValue, when the proof verifies with
+// the Data Integrity v2 context added to the proof configuration. This is for the issuers that sign
+// as if the credential had that context. By default, the proof terms are expanded with the context
+// of the credential only.
+func WithLenientProofContext() CredentialOpt {
+	return func(opts *credentialOpts) {
+		opts.verifyDataIntegrity.LenientProofContext = true
+	}
+}
+
+// WithProofValueEncodingHint accepts the Data Integrity proofs whose proofValue is not multibase
+// encoded, by decoding it with enc, eg models.ProofValueBase64, without multibase prefix. This is
+// for the issuers that omit the prefix. By default, the proofValue must be multibase encoded.
+// The selective disclosure suites ecdsa-sd-2023 and bbs-2023 ignore it.
+func WithProofValueEncodingHint(enc models.ProofValueEncoding) CredentialOpt {
+	return func(opts *credentialOpts) {
+		opts.verifyDataIntegrity.ProofValueEncodingHint = enc
+	}
+}
+
+// WithProofExpiryTolerance allows the expires time of a Data Integrity proof to be
+// up to d in the past relative to the verifier's clock. Default is zero tolerance.
+// A proof without an expires time never expires.
+func WithProofExpiryTolerance(d time.Duration) CredentialOpt {
+	return func(opts *credentialOpts) {
+		opts.verifyDataIntegrity.ExpiresTolerance = d
+	}
+}
+
+// WithDataIntegrityResolvedVerificationMethod sets the already resolved verification method
+// used to check Data Integrity proofs, which skips DID resolution. The ID of the verification
+// method must match the verificationMethod of the proofs.
+func WithDataIntegrityResolvedVerificationMethod(vm *vermethod.VerificationMethod) CredentialOpt {
+	return func(opts *credentialOpts) {
+		opts.verifyDataIntegrity.ResolvedVerificationMethod = vm
+	}
+}
+
+// WithAllowEmbeddedVerificationMethod makes the Data Integrity proofs checked with the verification
+// method embedded in the proof by DataIntegrityProofContext.EmbedVerificationMethod, instead of the one
+// resolved from the DID of the proof verificationMethod, eg for air-gapped verification. Its ID must
+// match the verificationMethod of the proofs. The proofs without embedded key are resolved.
+//
+// The embedded key is not signed over, and anyone can embed a key under the ID of another DID. So the
+// embedded key of a did:key or did:jwk verification method must be the key that its DID encodes, and
+// the embedded verification methods of the other DIDs must be trusted by trust, eg because their key
+// is pinned for their ID; with a nil trust, only the did:key and did:jwk ones are used. The checks of
+// the issuer, such as WithIssuerKeyBinding and WithTrustedIssuers, rely on this binding of the key to
+// the DID of its ID.
+func WithAllowEmbeddedVerificationMethod(trust EmbeddedVerificationMethodTrust) CredentialOpt {
+	return func(opts *credentialOpts) {
+		opts.verifyDataIntegrity.AllowEmbeddedVerificationMethod = true
+		opts.verifyDataIntegrity.TrustEmbeddedVerificationMethod = trust
+	}
+}
+
+// WithDataIntegrityProofStructure sets the expected structure of the Data Integrity proofs of the
+// credential. With models.ProofSet, the proofs must be independent: a proof chained to another one
+// fails with ErrCodeInvalidProofChain. With models.ProofChain, each proof after the first one must be
+// chained through previousProof to the proof preceding it, else the check fails with
+// ErrCodeInvalidProofChain, while a proof of the chain that doesn't verify fails with
+// ErrCodeSignatureInvalid; all the proofs of the chain must verify, whatever the policy of
+// WithDataIntegrityProofMatching. By default, the proofs are verified independently, and the chained
+// ones together with the proofs they are chained to, see DetectProofStructure.
+func WithDataIntegrityProofStructure(structure models.ProofStructure) CredentialOpt {
+	return func(opts *credentialOpts) {
+		opts.verifyDataIntegrity.ProofStructure = structure
+	}
+}
+
+// WithSignatureOnly makes only the signatures of the Data Integrity proofs of the credential checked,
+// with the public keys that keyLookup returns for the IDs of their verification methods, eg from a map of
+// locally trusted keys, for a cheap pre-filter rejecting the credentials whose proofs don't verify before
+// their full verification. A proof whose key isn't returned fails with ErrCodeVerificationMethod.
+//
+// This is not a full verification of the credential proofs: their verification methods are not resolved,
+// so that the verification relationship of the keys, eg assertionMethod, is not checked, nor are the
+// purpose, domain, challenge, capability, created and expires time of the proofs. A credential that passes
+// this check must still be parsed without this option to be trusted. See
+// dataintegrity.Verifier.VerifyProofSignature.
+func WithSignatureOnly(keyLookup func(vmID string) (crypto.PublicKey, bool)) CredentialOpt {
+	return func(opts *credentialOpts) {
+		opts.verifyDataIntegrity.SignatureOnly = keyLookup
+	}
+}
+
+// WithTryAllAssertionKeys makes the Data Integrity proofs with the assertionMethod purpose checked
+// with each key listed under assertionMethod in the DID document of the issuer when their verification
+// method can't be resolved or doesn't verify them, eg during a key rotation when the key of a method ID
+// is replaced. A proof verifies if one of the keys does; dataintegrity.Verifier.VerifyProofDetailed
+// reports which key it is.
+func WithTryAllAssertionKeys() CredentialOpt {
+	return func(opts *credentialOpts) {
+		opts.verifyDataIntegrity.TryAllAssertionKeys = true
+	}
+}
+
 // WithBaseContextExtendedValidation validates that fields that are specified in base context are as specified.
 // Additional fields are allowed.
 func WithBaseContextExtendedValidation(baseContext string, customContexts, customTypes []string) CredentialOpt {
@@ -1455,6 +1684,10 @@ func ParseCredentialJSON(vcJSON JSONObject, opts ...CredentialOpt) (*Credential,
 		return vc, nil
 	}
 
+	if vcOpts.normalizeDates && vcJSON[jsonFldLDProof] == nil {
+		vcJSON = normalizeDates(vcJSON)
+	}
+
 	ldProofs, err := parseLDProof(vcJSON[jsonFldLDProof])
 	if err != nil {
 		return nil, fmt.Errorf("fill credential proof from raw: %w", err)
@@ -1491,6 +1724,10 @@ func ParseCredential(vcData []byte, opts ...CredentialOpt) (*Credential, error)
 
 	vcOpts := getCredentialOpts(opts)
 
+	if err := checkDocumentSize(vcData, vcOpts.maxDocumentSize); err != nil {
+		return nil, err
+	}
+
 	var finalErr error
 
 	for _, parser := range parsers {
@@ -1510,32 +1747,49 @@ func ParseCredential(vcData []byte, opts ...CredentialOpt) (*Credential, error)
 }
 
 // parseCredentialDataURL parses a Verifiable Credential from a data URL.
-// The data URL must be in the format "data:<media type>;base64,<data>".
-// Supported media types are application/vc-ld+jwt, application/vc-ld+sd-jwt, and application/vc-ld+cose.
+// The data URL must be in the format "data:<media type>[;base64],<data>".
+// Supported media types are application/vc-ld+jwt, application/vc-ld+sd-jwt, and application/vc-ld+cose,
+// and their application/vc+jwt, application/vc+sd-jwt and application/vc+cose forms of the recommendation.
+// The media type is kept, so that the credential is enveloped in the same form when marshalled.
 func parseCredentialDataURL(dataURL string, opts *credentialOpts) (*Credential, error) {
-	mediaType, _, data, err := ParseDataURL(dataURL)
+	urlMediaType, encoding, data, err := ParseDataURL(dataURL)
 	if err != nil {
 		return nil, err
 	}
 
-	switch mediaType {
-	case VCMediaTypeJWT, VCMediaTypeSDJWT:
-		vc, e := parseCredential([]byte(data), &CredentialJSONParser{}, opts)
-		if e != nil {
-			return nil, fmt.Errorf("parse credential from data URL of type %q: %w", mediaType, e)
-		}
+	vcData := []byte(data)
 
-		return vc, nil
-	case VCMediaTypeCOSE:
-		vc, e := parseCredential([]byte(data), &CredentialCBORParser{}, opts)
-		if e != nil {
-			return nil, fmt.Errorf("parse credential from data URL of type %q: %w", mediaType, e)
+	if encoding == base64Encoding {
+		vcData, err = base64.StdEncoding.DecodeString(data)
+		if err != nil {
+			return nil, fmt.Errorf("decode base64 data URL: %w", err)
 		}
+	}
 
-		return vc, nil
+	mediaType := urlMediaType
+	if alias, ok := vcMediaTypeAliases[mediaType]; ok {
+		mediaType = alias
+	}
+
+	var parser CredentialParser
+
+	switch mediaType {
+	case VCMediaTypeJWT, VCMediaTypeSDJWT:
+		parser = &CredentialJSONParser{}
+	case VCMediaTypeCOSE:
+		parser = &CredentialCBORParser{}
 	default:
 		return nil, fmt.Errorf("unsupported data URL media type: %s", mediaType)
 	}
+
+	vc, err := parseCredential(vcData, parser, opts)
+	if err != nil {
+		return nil, fmt.Errorf("parse credential from data URL of type %q: %w", urlMediaType, err)
+	}
+
+	vc.envelopeMediaType = urlMediaType
+
+	return vc, nil
 }
 
 func parseCredential(vcData []byte, parser CredentialParser, opts *credentialOpts) (*Credential, error) {
@@ -1544,6 +1798,17 @@ func parseCredential(vcData []byte, parser CredentialParser, opts *credentialOpt
 		return nil, err
 	}
 
+	if opts.subjectSchemaLoader != nil {
+		err = validateSubjectUsingCredentialSchemas(&vc.credentialContents, opts.subjectSchemaLoader)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if opts.requireProof && len(vc.ldProofs) == 0 && vc.JWTEnvelope == nil && vc.CWTEnvelope == nil {
+		return nil, errors.New("credential has no verifiable proof")
+	}
+
 	if !opts.disabledProofCheck {
 		err = vc.checkProof(opts)
 		if err != nil {
@@ -1551,12 +1816,28 @@ func parseCredential(vcData []byte, parser CredentialParser, opts *credentialOpt
 		}
 	}
 
+	if err = checkTrustedIssuer(vc, opts); err != nil {
+		return nil, err
+	}
+
 	if !opts.disableRelatedResourceCheck {
 		if err = DefaultRelatedResourceValidator.Validate([]*Credential{vc}); err != nil {
 			return nil, err
 		}
 	}
 
+	if opts.termsOfUseEvaluator != nil {
+		if err = evaluateTermsOfUse(vc, opts.termsOfUseEvaluator); err != nil {
+			return nil, err
+		}
+	}
+
+	if opts.subjectDIDResolution {
+		if err = resolveSubjectDIDs(vc, opts.subjectDIDResolver); err != nil {
+			return nil, err
+		}
+	}
+
 	return vc, nil
 }
 
@@ -1601,6 +1882,13 @@ func parseCredentialJSON(vcJSON []byte) (JSONObject, error) {
 		return nil, fmt.Errorf("unmarshal new credential: %w", err)
 	}
 
+	if raw[jsonFldLDProof] != nil {
+		raw[jsonFldLDProof], err = decodeRawProof(vcJSON, jsonFldLDProof)
+		if err != nil {
+			return nil, fmt.Errorf("unmarshal credential proof: %w", err)
+		}
+	}
+
 	return raw, nil
 }
 
@@ -2083,7 +2371,16 @@ func (vc *Credential) checkProof(vcOpts *credentialOpts) error {
 		return nil
 	}
 
-	return checkEmbeddedProof(vc.credentialJSON, &issuerID, getEmbeddedProofCheckOpts(vcOpts))
+	err := checkEmbeddedProof(vc.credentialJSON, &issuerID, getEmbeddedProofCheckOpts(vcOpts))
+	if err != nil {
+		return err
+	}
+
+	if vcOpts.issuerKeyBinding {
+		return checkIssuerKeyBinding(vc.ldProofs, issuerID, vcOpts.issuerDIDResolver)
+	}
+
+	return nil
 }
 
 func decodeJWTVC(vcStr string) (jose.Headers, []byte, error) {
@@ -2300,11 +2597,15 @@ func schemaLoaderV2() gojsonschema.JSONLoader {
 }
 
 func getJSONSchema(url string, opts *credentialOpts) ([]byte, error) {
-	loader := opts.schemaLoader
-	cache := loader.cache
+	return opts.schemaLoader.LoadSchema(url)
+}
+
+// LoadSchema downloads the JSON schema at url, or gets it from the cache. It implements SchemaLoader.
+func (l *CredentialSchemaLoader) LoadSchema(url string) ([]byte, error) {
+	cache := l.cache
 
 	if cache == nil {
-		return loadJSONSchema(url, loader.schemaDownloadClient)
+		return loadJSONSchema(url, l.schemaDownloadClient)
 	}
 
 	// Check the cache first.
@@ -2312,7 +2613,7 @@ func getJSONSchema(url string, opts *credentialOpts) ([]byte, error) {
 		return cachedBytes, nil
 	}
 
-	schemaBytes, err := loadJSONSchema(url, loader.schemaDownloadClient)
+	schemaBytes, err := loadJSONSchema(url, l.schemaDownloadClient)
 	if err != nil {
 		return nil, err
 	}
@@ -2386,6 +2687,30 @@ func (vc *Credential) CreateSignedJWTVC(
 	}, nil
 }
 
+// AddJWTProof secures vc as a JWT-VC (compact JWS) signed by signer with the given key and
+// algorithm, and returns the JWT. The registered claims are mapped from the credential fields:
+// iss from the issuer, sub from the subject ID, nbf from the issuance date (validFrom),
+// exp from the expiration date (validUntil) and jti from the credential ID.
+// The full credential is kept in the "vc" claim, and vc is enveloped into the JWT.
+func (vc *Credential) AddJWTProof(signer jwt.ProofCreator, keyID string, alg JWSAlgorithm) (string, error) {
+	jwtClaims, err := vc.JWTClaims(false)
+	if err != nil {
+		return "", fmt.Errorf("add JWT proof: %w", err)
+	}
+
+	jwsString, joseHeaders, err := jwtClaims.MarshalJWS(alg, signer, keyID)
+	if err != nil {
+		return "", fmt.Errorf("add JWT proof: %w", err)
+	}
+
+	vc.JWTEnvelope = &JWTEnvelope{
+		JWT:        jwsString,
+		JWTHeaders: joseHeaders,
+	}
+
+	return jwsString, nil
+}
+
 // CreateSignedCOSEVC envelops current vc into signed COSE.
 func (vc *Credential) CreateSignedCOSEVC(
 	signatureAlg cose.Algorithm,
@@ -2435,6 +2760,24 @@ func (vc *Credential) CreateUnsecuredJWTVC(minimizeVC bool) (*Credential, error)
 	}, nil
 }
 
+// ToLDCredential returns a JSON-LD copy of vc, without its JWT or COSE envelope nor its proofs,
+// so that it can be secured again, eg with AddDataIntegrityProof. The registered claims of a
+// JWT-VC are mapped back to the credential fields when it is parsed: jti to id, iss to issuer,
+// nbf to issuanceDate (validFrom for a v2 credential) and exp to expirationDate (validUntil),
+// with the dates in RFC3339 format in UTC. All the disclosures of an SD-JWT credential are disclosed.
+//
+// The reverse conversion is AddJWTProof, or CreateSignedJWTVC.
+func (vc *Credential) ToLDCredential() (*Credential, error) {
+	if vc.credentialContents.SDJWTHashAlg != nil {
+		return vc.CreateDisplayCredential(DisplayAllDisclosures())
+	}
+
+	return &Credential{
+		credentialJSON:     copyCredentialJSONWithoutProofs(vc.credentialJSON),
+		credentialContents: vc.Contents(),
+	}, nil
+}
+
 // SubjectID gets ID of single subject if present or
 // returns error if there are several subjects or one without ID defined.
 func SubjectID(subject []Subject) (string, error) { //nolint:funlen
@@ -2606,6 +2949,19 @@ func (vc *Credential) MarshalAsJSONLD() ([]byte, error) {
 	return byteCred, nil
 }
 
+// CanonicalJSON returns the RFC 8785 (JCS) canonical JSON of the credential in the JSON-LD form of
+// MarshalAsJSONLD, with its proofs. Unlike the canonicalization of the Data Integrity suites, which
+// excludes the proof value, it covers the whole credential, so that it can be hashed for deduplication
+// or caching. For a credential in JWT or CWT form, it covers the claims, not the envelope.
+func (vc *Credential) CanonicalJSON() ([]byte, error) {
+	canonical, err := canonicalizer.MarshalCanonical(vc.ToRawClaimsMap())
+	if err != nil {
+		return nil, fmt.Errorf("JCS canonicalization of verifiable credential: %w", err)
+	}
+
+	return canonical, nil
+}
+
 // ToRawClaimsMap returns raw map[string]interface{} of VC claims.
 func (vc *Credential) ToRawClaimsMap() JSONObject {
 	return vc.ToRawJSON()
@@ -0,0 +1,67 @@
+/*
+Copyright Gen Digital Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package verifiable
+
+import (
+	"time"
+
+	jsonutil "github.com/trustbloc/vc-go/util/json"
+)
+
+// legacyDateLayouts are the layouts of the date strings normalized by WithNormalizeDates, in addition
+// to RFC 3339. The dates without time zone are taken as UTC. Fractional seconds are accepted after the
+// seconds of each layout.
+var legacyDateLayouts = []string{
+	time.RFC3339,
+	"2006-01-02T15:04:05",
+	"2006-01-02 15:04:05Z07:00",
+	"2006-01-02 15:04:05",
+	time.DateOnly,
+}
+
+// WithNormalizeDates option makes ParseCredential and ParseCredentialJSON rewrite the issuanceDate,
+// expirationDate, validFrom and validUntil of the credential into RFC 3339 UTC, eg
+// "2010-01-01T19:23:24Z" for "2010-01-01T19:23:24" or "2010-01-01T21:23:24+02:00", so that the
+// credential is canonicalized consistently when it is signed or its dates are compared. The date
+// strings that are not recognized are left unchanged.
+//
+// As the normalization changes the bytes of the credential, it only applies to the credentials without
+// proof: the credentials with an embedded proof, or enveloped into a JWT or CWT, are kept as issued,
+// since their proofs were computed over the original dates and would no longer verify.
+func WithNormalizeDates() CredentialOpt {
+	return func(opts *credentialOpts) {
+		opts.normalizeDates = true
+	}
+}
+
+// normalizeDates returns vcJSON with its recognized date fields rewritten into RFC 3339 UTC.
+func normalizeDates(vcJSON JSONObject) JSONObject {
+	normalized := jsonutil.ShallowCopyObj(vcJSON)
+
+	for _, field := range []string{jsonFldIssued, jsonFldExpired, jsonFldValidFrom, jsonFldValidUntil} {
+		date, ok := normalized[field].(string)
+		if !ok {
+			continue
+		}
+
+		if t, ok := parseLegacyDate(date); ok {
+			normalized[field] = t.UTC().Format(time.RFC3339Nano)
+		}
+	}
+
+	return normalized
+}
+
+func parseLegacyDate(date string) (time.Time, bool) {
+	for _, layout := range legacyDateLayouts {
+		if t, err := time.Parse(layout, date); err == nil {
+			return t, true
+		}
+	}
+
+	return time.Time{}, false
+}
@@ -0,0 +1,119 @@
+/*
+Copyright Gen Digital Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package verifiable
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"github.com/tidwall/gjson"
+	"github.com/tidwall/sjson"
+)
+
+func TestWithNormalizeDates(t *testing.T) {
+	legacyCredential := func(t *testing.T, dates map[string]string) []byte {
+		t.Helper()
+
+		vcJSON := []byte(v1ValidCredential)
+
+		for field, date := range dates {
+			var err error
+
+			vcJSON, err = sjson.SetBytes(vcJSON, field, date)
+			require.NoError(t, err)
+		}
+
+		return vcJSON
+	}
+
+	vcJSON := legacyCredential(t, map[string]string{
+		"issuanceDate":   "2010-01-01T19:23:24",
+		"expirationDate": "2020-01-01T21:23:24.5+02:00",
+	})
+
+	t.Run("normalized", func(t *testing.T) {
+		vc, err := parseTestCredential(t, vcJSON, WithDisabledProofCheck(), WithNormalizeDates())
+		require.NoError(t, err)
+		require.Equal(t, "2010-01-01T19:23:24Z", vc.Contents().Issued.FormatToString())
+		require.Equal(t, "2020-01-01T19:23:24.5Z", vc.Contents().Expired.FormatToString())
+
+		vcBytes, err := vc.MarshalJSON()
+		require.NoError(t, err)
+		require.Equal(t, "2010-01-01T19:23:24Z", gjson.GetBytes(vcBytes, "issuanceDate").String())
+		require.Equal(t, "2020-01-01T19:23:24.5Z", gjson.GetBytes(vcBytes, "expirationDate").String())
+
+		// The dates are kept as they are without the option, and don't pass the validation.
+		_, err = parseTestCredential(t, vcJSON, WithDisabledProofCheck())
+		require.ErrorContains(t, err, "issuanceDate: Does not match format 'date-time'")
+
+		vc, err = parseTestCredential(t, vcJSON, WithDisabledProofCheck(), WithCredDisableValidation())
+		require.NoError(t, err)
+		require.Equal(t, "2010-01-01T19:23:24", vc.Contents().Issued.FormatToString())
+		require.Equal(t, "2020-01-01T21:23:24.5+02:00", vc.Contents().Expired.FormatToString())
+	})
+
+	t.Run("legacy layouts", func(t *testing.T) {
+		for date, expected := range map[string]string{
+			"2010-01-01T19:23:24Z":      "2010-01-01T19:23:24Z",
+			"2010-01-01T19:23:24-05:00": "2010-01-02T00:23:24Z",
+			"2010-01-01 19:23:24":       "2010-01-01T19:23:24Z",
+			"2010-01-01 19:23:24+01:00": "2010-01-01T18:23:24Z",
+			"2010-01-01T19:23:24.120":   "2010-01-01T19:23:24.12Z",
+			"2010-01-01":                "2010-01-01T00:00:00Z",
+		} {
+			vc, err := parseTestCredential(t, legacyCredential(t, map[string]string{"issuanceDate": date}),
+				WithDisabledProofCheck(), WithNormalizeDates())
+			require.NoError(t, err, date)
+			require.Equal(t, expected, vc.Contents().Issued.FormatToString(), date)
+		}
+
+		normalized := normalizeDates(JSONObject{
+			"validFrom":  "2010-01-01T19:23:24",
+			"validUntil": "not a date",
+			"name":       "2010-01-01T19:23:24",
+		})
+		require.Equal(t, JSONObject{
+			"validFrom":  "2010-01-01T19:23:24Z",
+			"validUntil": "not a date",
+			"name":       "2010-01-01T19:23:24",
+		}, normalized)
+	})
+
+	t.Run("credential JSON", func(t *testing.T) {
+		var raw JSONObject
+		require.NoError(t, json.Unmarshal(vcJSON, &raw))
+
+		vc, err := ParseCredentialJSON(raw, WithDisabledProofCheck(), WithNormalizeDates(),
+			WithJSONLDDocumentLoader(createTestDocumentLoader(t)))
+		require.NoError(t, err)
+		require.Equal(t, "2010-01-01T19:23:24Z", vc.Contents().Issued.FormatToString())
+
+		// The given JSON object is not modified.
+		require.Equal(t, "2010-01-01T19:23:24", raw["issuanceDate"])
+	})
+
+	t.Run("not normalized with proof", func(t *testing.T) {
+		withProof, err := sjson.SetRawBytes(vcJSON, "proof", []byte(`{
+			"type": "Ed25519Signature2020",
+			"created": "2010-01-01T19:23:24Z",
+			"proofPurpose": "assertionMethod",
+			"verificationMethod": "did:example:76e12ec712ebc6f1c221ebfeb1f#key-1",
+			"proofValue": "z3FXQjecWufY46yg5abdVZsXqLhxhueuSoZgNSARiKBk"
+		}`))
+		require.NoError(t, err)
+
+		_, err = parseTestCredential(t, withProof, WithDisabledProofCheck(), WithNormalizeDates())
+		require.ErrorContains(t, err, "issuanceDate: Does not match format 'date-time'")
+
+		vc, err := parseTestCredential(t, withProof, WithDisabledProofCheck(), WithNormalizeDates(),
+			WithCredDisableValidation())
+		require.NoError(t, err)
+		require.Equal(t, "2010-01-01T19:23:24", vc.Contents().Issued.FormatToString())
+		require.Equal(t, "2020-01-01T21:23:24.5+02:00", vc.Contents().Expired.FormatToString())
+	})
+}
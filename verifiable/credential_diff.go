@@ -0,0 +1,178 @@
+/*
+Copyright Gen Digital Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package verifiable
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"slices"
+	"time"
+
+	"github.com/samber/lo"
+)
+
+// FieldDiff is a field that differs between two credentials, see Credential.Diff.
+type FieldDiff struct {
+	// Field is the name of a top-level field of the credentials, eg "issuer", or of a field of their
+	// subject, eg "credentialSubject.name", with the index of the subject if there are several of them,
+	// eg "credentialSubject[1].name".
+	Field string
+	// Value is the value of the field in the credential, nil if it has no such field.
+	Value interface{}
+	// Other is the value of the field in the other credential, nil if it has no such field.
+	Other interface{}
+}
+
+// Equal returns true if vc and other have the same contents, compared in their JSON form: key order,
+// number formatting and the formatting of dates, as recognized by WithNormalizeDates, don't matter, eg
+// 2023-01-01T00:00:00Z and 2023-01-01T00:00:00.000Z are equal dates. The proofs and the securing
+// envelopes, eg JWT, are not compared, so that a credential is equal to its unsigned version.
+func (vc *Credential) Equal(other *Credential) bool {
+	if vc == nil || other == nil {
+		return vc == other
+	}
+
+	return reflect.DeepEqual(comparableCredentialJSON(vc), comparableCredentialJSON(other))
+}
+
+// Diff returns the top-level fields and the subject fields which differ between vc and other, compared
+// as by Equal, sorted by field name. When the credentials have a different number of subjects, the
+// credentialSubject field differs as a whole.
+func (vc *Credential) Diff(other *Credential) []FieldDiff {
+	var vcJSON, otherJSON map[string]interface{}
+
+	if vc != nil {
+		vcJSON = comparableCredentialJSON(vc)
+	}
+
+	if other != nil {
+		otherJSON = comparableCredentialJSON(other)
+	}
+
+	return diffFields("", vcJSON, otherJSON, func(name string, value, otherValue interface{}) []FieldDiff {
+		if name != jsonFldSubject {
+			return nil
+		}
+
+		return diffSubjects(value, otherValue)
+	})
+}
+
+func diffSubjects(subject, other interface{}) []FieldDiff {
+	subjects, otherSubjects := subjectObjects(subject), subjectObjects(other)
+	if subjects == nil || otherSubjects == nil || len(subjects) != len(otherSubjects) {
+		return nil
+	}
+
+	var diffs []FieldDiff
+
+	for i := range subjects {
+		prefix := jsonFldSubject + "."
+		if len(subjects) > 1 {
+			prefix = fmt.Sprintf("%s[%d].", jsonFldSubject, i)
+		}
+
+		diffs = append(diffs, diffFields(prefix, subjects[i], otherSubjects[i], nil)...)
+	}
+
+	return diffs
+}
+
+// diffFields returns the fields of obj and other which differ. A field is first given to descend, which
+// returns the differences within the field, if it can compare its values.
+func diffFields(
+	prefix string,
+	obj, other map[string]interface{},
+	descend func(name string, value, otherValue interface{}) []FieldDiff,
+) []FieldDiff {
+	names := lo.Uniq(append(lo.Keys(obj), lo.Keys(other)...))
+	slices.Sort(names)
+
+	var diffs []FieldDiff
+
+	for _, name := range names {
+		value, otherValue := obj[name], other[name]
+		if reflect.DeepEqual(value, otherValue) {
+			continue
+		}
+
+		if descend != nil {
+			if fieldDiffs := descend(name, value, otherValue); len(fieldDiffs) > 0 {
+				diffs = append(diffs, fieldDiffs...)
+
+				continue
+			}
+		}
+
+		diffs = append(diffs, FieldDiff{Field: prefix + name, Value: value, Other: otherValue})
+	}
+
+	return diffs
+}
+
+// subjectObjects returns the subjects of a credentialSubject field, or nil if it has other values.
+func subjectObjects(subject interface{}) []map[string]interface{} {
+	switch s := subject.(type) {
+	case map[string]interface{}:
+		return []map[string]interface{}{s}
+	case []interface{}:
+		objects := make([]map[string]interface{}, 0, len(s))
+
+		for _, item := range s {
+			obj, ok := item.(map[string]interface{})
+			if !ok {
+				return nil
+			}
+
+			objects = append(objects, obj)
+		}
+
+		return objects
+	}
+
+	return nil
+}
+
+// comparableCredentialJSON returns the JSON of vc without proofs, as unmarshalled from its serialization,
+// with the dates in a single format.
+func comparableCredentialJSON(vc *Credential) map[string]interface{} {
+	raw, err := json.Marshal(copyCredentialJSONWithoutProofs(vc.credentialJSON))
+	if err != nil {
+		return nil
+	}
+
+	var obj map[string]interface{}
+
+	if err = json.Unmarshal(raw, &obj); err != nil {
+		return nil
+	}
+
+	normalized, _ := normalizeDateValues(obj).(map[string]interface{})
+
+	return normalized
+}
+
+// normalizeDateValues rewrites the date strings of value, as recognized by WithNormalizeDates, into RFC 3339 UTC.
+func normalizeDateValues(value interface{}) interface{} {
+	switch v := value.(type) {
+	case string:
+		if t, ok := parseLegacyDate(v); ok {
+			return t.UTC().Format(time.RFC3339Nano)
+		}
+	case map[string]interface{}:
+		for name, item := range v {
+			v[name] = normalizeDateValues(item)
+		}
+	case []interface{}:
+		for i, item := range v {
+			v[i] = normalizeDateValues(item)
+		}
+	}
+
+	return value
+}
@@ -0,0 +1,112 @@
+/*
+Copyright Gen Digital Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package verifiable
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"github.com/tidwall/sjson"
+)
+
+func TestCredential_Equal(t *testing.T) {
+	vc, err := parseTestCredential(t, []byte(v1ValidCredential), WithDisabledProofCheck())
+	require.NoError(t, err)
+
+	parse := func(t *testing.T, vcJSON string) *Credential {
+		t.Helper()
+
+		parsed, e := parseTestCredential(t, []byte(vcJSON), WithDisabledProofCheck())
+		require.NoError(t, e)
+
+		return parsed
+	}
+
+	t.Run("equal", func(t *testing.T) {
+		require.True(t, vc.Equal(parse(t, v1ValidCredential)))
+		require.Empty(t, vc.Diff(parse(t, v1ValidCredential)))
+
+		var nilVC *Credential
+
+		require.True(t, nilVC.Equal(nil))
+		require.False(t, vc.Equal(nil))
+		require.False(t, nilVC.Equal(vc))
+	})
+
+	t.Run("dates of other formats", func(t *testing.T) {
+		issued := vc.Contents().Issued.Time.UTC()
+
+		vcJSON, e := sjson.Set(v1ValidCredential, "issuanceDate", issued.Format("2006-01-02T15:04:05.000Z"))
+		require.NoError(t, e)
+
+		other := parse(t, vcJSON)
+		require.True(t, vc.Equal(other))
+		require.Empty(t, vc.Diff(other))
+	})
+
+	t.Run("proofs are not compared", func(t *testing.T) {
+		vcJSON, e := sjson.Set(v1ValidCredential, "proof", map[string]interface{}{
+			"type":               "Ed25519Signature2018",
+			"created":            "2023-01-01T00:00:00Z",
+			"proofPurpose":       "assertionMethod",
+			"verificationMethod": "did:example:76e12ec712ebc6f1c221ebfeb1f#key-1",
+			"jws":                "eyJhbGciOiJFZERTQSJ9..mock",
+		})
+		require.NoError(t, e)
+
+		signed := parse(t, vcJSON)
+		require.Len(t, signed.Proofs(), 1)
+		require.True(t, vc.Equal(signed))
+		require.Empty(t, vc.Diff(signed))
+	})
+
+	t.Run("top-level field differs", func(t *testing.T) {
+		vcJSON, e := sjson.Set(v1ValidCredential, "id", "http://example.edu/credentials/other")
+		require.NoError(t, e)
+
+		vcJSON, e = sjson.Delete(vcJSON, "expirationDate")
+		require.NoError(t, e)
+
+		other := parse(t, vcJSON)
+		require.False(t, vc.Equal(other))
+		require.Equal(t, []FieldDiff{
+			{Field: "expirationDate", Value: vc.ToRawJSON()["expirationDate"]},
+			{Field: "id", Value: vc.Contents().ID, Other: "http://example.edu/credentials/other"},
+		}, vc.Diff(other))
+	})
+
+	t.Run("subject field differs", func(t *testing.T) {
+		vcJSON, e := sjson.Set(v1ValidCredential, "credentialSubject.name", "Other Name")
+		require.NoError(t, e)
+
+		other := parse(t, vcJSON)
+		require.False(t, vc.Equal(other))
+
+		diffs := vc.Diff(other)
+		require.Len(t, diffs, 1)
+		require.Equal(t, "credentialSubject.name", diffs[0].Field)
+		require.Equal(t, "Other Name", diffs[0].Other)
+	})
+
+	t.Run("several subjects", func(t *testing.T) {
+		subjects := []Subject{
+			{ID: "did:example:1", CustomFields: CustomFields{"name": "Jayden Doe"}},
+			{ID: "did:example:2", CustomFields: CustomFields{"name": "Morgan Doe"}},
+		}
+
+		withSubjects := vc.WithModifiedSubject(subjects)
+
+		subjects[1] = Subject{ID: "did:example:2", CustomFields: CustomFields{"name": "Other Name"}}
+
+		require.Equal(t, []FieldDiff{{Field: "credentialSubject[1].name", Value: "Morgan Doe", Other: "Other Name"}},
+			withSubjects.Diff(withSubjects.WithModifiedSubject(subjects)))
+
+		diffs := vc.Diff(withSubjects)
+		require.Len(t, diffs, 1)
+		require.Equal(t, "credentialSubject", diffs[0].Field)
+	})
+}
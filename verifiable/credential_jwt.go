@@ -203,7 +203,8 @@ func (jcc *JWTCredClaims) refineFromJWTClaims() error {
 		vcMap[vcIDField] = jti
 	}
 
-	if iat := claims.IssuedAt; iat != nil {
+	// nbf is the issuance date of the credential, iat is only used when nbf is absent.
+	if iat := claims.IssuedAt; iat != nil && claims.NotBefore == nil {
 		iatTime := iat.Time().UTC()
 		if HasBaseContext(vcMap, V2ContextURI) {
 			vcMap[vcValidFrom] = iatTime.Format(time.RFC3339)
@@ -12,12 +12,14 @@ import (
 	"encoding/base64"
 	"encoding/json"
 	"net/http"
+	"strings"
 	"testing"
 
 	"github.com/btcsuite/btcutil/base58"
 	"github.com/google/uuid"
 	jsonld "github.com/piprate/json-gold/ld"
 	"github.com/stretchr/testify/require"
+	"github.com/tidwall/sjson"
 	"github.com/trustbloc/did-go/method/key"
 	"github.com/trustbloc/did-go/method/web"
 	vdrpkg "github.com/trustbloc/did-go/vdr"
@@ -88,6 +90,56 @@ func TestParseCredentialFromLinkedDataProof_Ed25519Signature2018(t *testing.T) {
 		r.NoError(err)
 		r.Equal(vc.ToRawJSON(), vcWithLdp.ToRawJSON())
 	})
+
+	t.Run("inline context", func(t *testing.T) {
+		vcJSON, err := sjson.Set(v1ValidCredential, "@context.-1", map[string]interface{}{
+			"favoriteColor": "https://example.com/vocab#favoriteColor",
+		})
+		r.NoError(err)
+
+		vcJSON, err = sjson.Set(vcJSON, "credentialSubject.favoriteColor", "blue")
+		r.NoError(err)
+
+		vc, err := parseTestCredential(t, []byte(vcJSON), WithDisabledProofCheck(), WithStrictValidation())
+		r.NoError(err)
+
+		err = vc.AddLinkedDataProof(ldpContext, jsonldsig.WithDocumentLoader(createTestDocumentLoader(t)))
+		r.NoError(err)
+
+		vcBytes, err := json.Marshal(vc)
+		r.NoError(err)
+
+		_, err = parseTestCredential(t, vcBytes, WithProofChecker(proofChecker), WithStrictValidation())
+		r.NoError(err)
+
+		tampered, err := sjson.SetBytes(vcBytes, "credentialSubject.favoriteColor", "red")
+		r.NoError(err)
+
+		_, err = parseTestCredential(t, tampered, WithProofChecker(proofChecker))
+		r.Error(err)
+	})
+
+	t.Run("single proof in an array", func(t *testing.T) {
+		vc, err := parseTestCredential(t, []byte(v2ValidCredential), WithDisabledProofCheck())
+		r.NoError(err)
+
+		err = vc.AddLinkedDataProof(ldpContext, jsonldsig.WithDocumentLoader(createTestDocumentLoader(t)))
+		r.NoError(err)
+
+		vcBytes, err := json.Marshal(vc)
+		r.NoError(err)
+
+		arrayBytes, err := sjson.SetBytes(vcBytes, "proof", []interface{}{vc.Proofs()[0]})
+		r.NoError(err)
+
+		vcWithLdp, err := parseTestCredential(t, arrayBytes,
+			WithProofChecker(proofChecker))
+		r.NoError(err)
+		r.Len(vcWithLdp.Proofs(), 1)
+
+		// A single proof is serialized as an object, whatever its shape in the parsed credential.
+		r.Equal(vc.ToRawJSON(), vcWithLdp.ToRawJSON())
+	})
 }
 
 func TestParseCredentialFromLinkedDataProof_Ed25519Signature2020(t *testing.T) {
@@ -110,6 +162,14 @@ func TestParseCredentialFromLinkedDataProof_Ed25519Signature2020(t *testing.T) {
 		err = vc.AddLinkedDataProof(ldpContext, jsonldsig.WithDocumentLoader(createTestDocumentLoader(t)))
 		r.NoError(err)
 
+		// The legacy shape, as expected by the verifiers not supporting Data Integrity proofs.
+		r.Len(vc.Proofs(), 1)
+		r.Equal("Ed25519Signature2020", vc.Proofs()[0]["type"])
+		r.NotContains(vc.Proofs()[0], "cryptosuite")
+		r.NotContains(vc.Proofs()[0], "jws")
+		r.IsType("", vc.Proofs()[0]["proofValue"])
+		r.Equal("z", vc.Proofs()[0]["proofValue"].(string)[:1])
+
 		vcBytes, err := json.Marshal(vc)
 		r.NoError(err)
 
@@ -385,6 +445,7 @@ func TestWithStrictValidationOfJsonWebSignature2020(t *testing.T) {
 
 	require.NoError(t, err)
 	require.NotNil(t, vcWithLdp)
+	require.NotContains(t, vcWithLdp.ToRawJSON()["@context"], "https://w3id.org/security/jws/v1")
 }
 
 func TestExtraContextWithLDP(t *testing.T) {
@@ -689,6 +750,53 @@ func TestParseCredentialFromLinkedDataProof_JsonWebSignature2020_ecdsaP256(t *te
 	r.Equal(vc.ToRawJSON(), vcWithLdp.ToRawJSON())
 }
 
+func TestParseCredentialFromLinkedDataProof_JsonWebSignature2020_ecdsaP384(t *testing.T) {
+	r := require.New(t)
+
+	proofCreator, proofChecker := testsupport.NewKMSSigVerPair(t, kms.ECDSAP384TypeIEEEP1363, "did:example:76e12ec712ebc6f1c221ebfeb1f#key1")
+
+	ldpContext := &LinkedDataProofContext{
+		SignatureType:           "JsonWebSignature2020",
+		KeyType:                 kms.ECDSAP384TypeIEEEP1363,
+		SignatureRepresentation: SignatureJWS,
+		ProofCreator:            proofCreator,
+		VerificationMethod:      "did:example:76e12ec712ebc6f1c221ebfeb1f#key1",
+	}
+
+	vc, err := parseTestCredential(t, []byte(v1ValidCredential), WithDisabledProofCheck())
+	r.NoError(err)
+
+	err = vc.AddLinkedDataProof(ldpContext, jsonldsig.WithDocumentLoader(createTestDocumentLoader(t)))
+	r.NoError(err)
+
+	// Detached JWS with unencoded payload (RFC 7797) and no proofValue.
+	r.Len(vc.Proofs(), 1)
+	r.NotContains(vc.Proofs()[0], "proofValue")
+
+	jws, ok := vc.Proofs()[0]["jws"].(string)
+	r.True(ok)
+
+	jwsParts := strings.Split(jws, ".")
+	r.Len(jwsParts, 3)
+	r.Empty(jwsParts[1])
+
+	headerBytes, err := base64.RawURLEncoding.DecodeString(jwsParts[0])
+	r.NoError(err)
+
+	var header map[string]interface{}
+
+	r.NoError(json.Unmarshal(headerBytes, &header))
+	r.Equal(false, header["b64"])
+	r.Equal([]interface{}{"b64"}, header["crit"])
+
+	vcBytes, err := json.Marshal(vc)
+	r.NoError(err)
+
+	vcWithLdp, err := parseTestCredential(t, vcBytes, WithProofChecker(proofChecker))
+	r.NoError(err)
+	r.Equal(vc.ToRawJSON(), vcWithLdp.ToRawJSON())
+}
+
 func TestParseCredentialFromLinkedDataProof_EcdsaSecp256k1Signature2019(t *testing.T) {
 	r := require.New(t)
 
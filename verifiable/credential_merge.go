@@ -0,0 +1,142 @@
+/*
+Copyright Gen Digital Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package verifiable
+
+import (
+	"errors"
+	"fmt"
+	"maps"
+	"reflect"
+	"slices"
+
+	"github.com/samber/lo"
+)
+
+// ErrSubjectConflict is returned when credentials merged with SubjectMergeErrorOnConflict give different values
+// to the same claim of a subject.
+var ErrSubjectConflict = errors.New("conflicting credential subject claims")
+
+// SubjectMergePolicy defines how MergeCredentialSubjectsWithPolicy resolves a claim given different values
+// by the merged credentials.
+type SubjectMergePolicy int
+
+const (
+	// SubjectMergeLastWins keeps the value of the last credential giving the claim. This is the default.
+	SubjectMergeLastWins SubjectMergePolicy = iota
+	// SubjectMergeErrorOnConflict fails the merge with ErrSubjectConflict.
+	SubjectMergeErrorOnConflict
+)
+
+// MergeCredentialSubjects creates a new unsigned credential combining the subjects of base and overlays, resolving
+// conflicting claims with SubjectMergeLastWins. See MergeCredentialSubjectsWithPolicy.
+func MergeCredentialSubjects(base *Credential, overlays ...*Credential) (*Credential, error) {
+	return MergeCredentialSubjectsWithPolicy(SubjectMergeLastWins, base, overlays...)
+}
+
+// MergeCredentialSubjectsWithPolicy creates a new unsigned credential combining the subjects of base and
+// overlays, in order. The claims of an overlay subject are added to the subject of the same id, or the subject
+// is appended if there is none. Claims are merged at the top level of the subject only; a claim given different
+// values is resolved with policy.
+//
+// The @context of the credentials are combined without duplicates, and every other field is taken from base.
+// The proofs of base and the JWT or COSE envelopes of all credentials are not kept, as they become invalid.
+// Credentials with selectively disclosable claims are not supported.
+func MergeCredentialSubjectsWithPolicy(
+	policy SubjectMergePolicy,
+	base *Credential,
+	overlays ...*Credential,
+) (*Credential, error) {
+	if base == nil {
+		return nil, errors.New("base credential is required")
+	}
+
+	if base.credentialContents.SDJWTHashAlg != nil {
+		return nil, errors.New("merge subjects of selectively disclosable credential is not supported")
+	}
+
+	newContents := base.Contents()
+	newContents.Context = slices.Clone(newContents.Context)
+	newContents.CustomContext = slices.Clone(newContents.CustomContext)
+	newContents.Subject = lo.Map(newContents.Subject, func(subject Subject, _ int) Subject {
+		return Subject{ID: subject.ID, CustomFields: maps.Clone(subject.CustomFields)}
+	})
+
+	for i, overlay := range overlays {
+		if overlay == nil {
+			return nil, fmt.Errorf("overlay credential [%d] is nil", i)
+		}
+
+		if overlay.credentialContents.SDJWTHashAlg != nil {
+			return nil, errors.New("merge subjects of selectively disclosable credential is not supported")
+		}
+
+		for _, ctx := range overlay.credentialContents.Context {
+			if !slices.Contains(newContents.Context, ctx) {
+				newContents.Context = append(newContents.Context, ctx)
+			}
+		}
+
+		for _, ctx := range overlay.credentialContents.CustomContext {
+			if !slices.ContainsFunc(newContents.CustomContext, func(c interface{}) bool {
+				return reflect.DeepEqual(c, ctx)
+			}) {
+				newContents.CustomContext = append(newContents.CustomContext, ctx)
+			}
+		}
+
+		var err error
+
+		newContents.Subject, err = mergeSubjects(newContents.Subject, overlay.credentialContents.Subject, policy)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	newCredJSON := copyCredentialJSONWithoutProofs(base.credentialJSON)
+
+	if rawContext := contextToRaw(newContents.Context, newContents.CustomContext); len(rawContext) > 0 {
+		newCredJSON[jsonFldContext] = rawContext
+	}
+
+	if len(newContents.Subject) > 0 {
+		newCredJSON[jsonFldSubject] = SerializeSubject(newContents.Subject)
+	}
+
+	return &Credential{
+		credentialJSON:     newCredJSON,
+		credentialContents: newContents,
+	}, nil
+}
+
+func mergeSubjects(subjects, overlay []Subject, policy SubjectMergePolicy) ([]Subject, error) {
+	for _, overlaySubject := range overlay {
+		i := slices.IndexFunc(subjects, func(s Subject) bool { return s.ID == overlaySubject.ID })
+		if i < 0 {
+			subjects = append(subjects, Subject{
+				ID:           overlaySubject.ID,
+				CustomFields: maps.Clone(overlaySubject.CustomFields),
+			})
+
+			continue
+		}
+
+		if subjects[i].CustomFields == nil {
+			subjects[i].CustomFields = CustomFields{}
+		}
+
+		for name, value := range overlaySubject.CustomFields {
+			current, ok := subjects[i].CustomFields[name]
+			if ok && policy == SubjectMergeErrorOnConflict && !reflect.DeepEqual(current, value) {
+				return nil, fmt.Errorf("%w: claim %q of subject %q", ErrSubjectConflict, name, overlaySubject.ID)
+			}
+
+			subjects[i].CustomFields[name] = value
+		}
+	}
+
+	return subjects, nil
+}
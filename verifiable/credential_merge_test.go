@@ -0,0 +1,103 @@
+/*
+Copyright Gen Digital Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package verifiable
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	afgotime "github.com/trustbloc/did-go/doc/util/time"
+)
+
+func TestMergeCredentialSubjects(t *testing.T) {
+	const subjectID = "did:example:ebfeb1f712ebc6f1c276e12ec21"
+
+	newCredential := func(t *testing.T, context []string, subjects ...Subject) *Credential {
+		t.Helper()
+
+		vc, err := CreateCredential(CredentialContents{
+			Context: context,
+			ID:      "http://example.edu/credentials/1872",
+			Types:   []string{VCType},
+			Issuer:  &Issuer{ID: "did:example:76e12ec712ebc6f1c221ebfeb1f"},
+			Issued:  afgotime.NewTime(time.Now()),
+			Subject: subjects,
+		}, nil)
+		require.NoError(t, err)
+
+		return vc
+	}
+
+	degree := newCredential(t, []string{V1ContextURI, "https://www.w3.org/2018/credentials/examples/v1"},
+		Subject{ID: subjectID, CustomFields: CustomFields{"name": "Jayden Doe", "degree": "BachelorDegree"}})
+	age := newCredential(t, []string{V1ContextURI, "https://w3id.org/citizenship/v1"},
+		Subject{ID: subjectID, CustomFields: CustomFields{"name": "J. Doe", "age": 21}})
+
+	t.Run("merge subjects of same id", func(t *testing.T) {
+		merged, err := MergeCredentialSubjects(degree, age)
+		require.NoError(t, err)
+
+		vcc := merged.Contents()
+		require.Equal(t, []string{V1ContextURI, "https://www.w3.org/2018/credentials/examples/v1",
+			"https://w3id.org/citizenship/v1"}, vcc.Context)
+		require.Equal(t, degree.Contents().ID, vcc.ID)
+		require.Equal(t, []Subject{{ID: subjectID, CustomFields: CustomFields{
+			"name": "J. Doe", "degree": "BachelorDegree", "age": 21,
+		}}}, vcc.Subject)
+
+		vcJSON := merged.ToRawJSON()
+		require.Len(t, vcJSON[jsonFldContext], 3)
+		require.Equal(t, "J. Doe", vcJSON[jsonFldSubject].(map[string]interface{})["name"])
+		require.Equal(t, "Jayden Doe", degree.Contents().Subject[0].CustomFields["name"])
+	})
+
+	t.Run("append subjects of other id", func(t *testing.T) {
+		other := newCredential(t, []string{V1ContextURI},
+			Subject{ID: "did:example:c276e12ec21ebfeb1f712ebc6f1", CustomFields: CustomFields{"age": 30}})
+
+		merged, err := MergeCredentialSubjects(degree, other)
+		require.NoError(t, err)
+		require.Len(t, merged.Contents().Subject, 2)
+		require.Equal(t, other.Contents().Subject[0], merged.Contents().Subject[1])
+		require.Len(t, degree.Contents().Subject, 1)
+	})
+
+	t.Run("error on conflict", func(t *testing.T) {
+		_, err := MergeCredentialSubjectsWithPolicy(SubjectMergeErrorOnConflict, degree, age)
+		require.ErrorIs(t, err, ErrSubjectConflict)
+		require.ErrorContains(t, err, `claim "name" of subject "`+subjectID+`"`)
+
+		same := newCredential(t, []string{V1ContextURI},
+			Subject{ID: subjectID, CustomFields: CustomFields{"name": "Jayden Doe", "age": 21}})
+
+		merged, err := MergeCredentialSubjectsWithPolicy(SubjectMergeErrorOnConflict, degree, same)
+		require.NoError(t, err)
+		require.Equal(t, 21, merged.Contents().Subject[0].CustomFields["age"])
+	})
+
+	t.Run("proofs are stripped", func(t *testing.T) {
+		signed := degree.WithModifiedID(degree.Contents().ID)
+		signed.ldProofs = []Proof{{"type": "Ed25519Signature2018"}}
+		signed.credentialJSON[jsonFldLDProof] = signed.ldProofs[0]
+
+		merged, err := MergeCredentialSubjects(signed, age)
+		require.NoError(t, err)
+		require.Empty(t, merged.Proofs())
+
+		vcJSON := merged.ToRawJSON()
+		require.NotContains(t, vcJSON, jsonFldLDProof)
+	})
+
+	t.Run("invalid arguments", func(t *testing.T) {
+		_, err := MergeCredentialSubjects(nil, age)
+		require.EqualError(t, err, "base credential is required")
+
+		_, err = MergeCredentialSubjects(degree, age, nil)
+		require.EqualError(t, err, "overlay credential [1] is nil")
+	})
+}
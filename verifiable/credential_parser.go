@@ -68,6 +68,10 @@ func (p *CredentialJSONParser) Parse( //nolint:funlen,gocyclo // Old function
 		return nil, errors.Join(errUnsupportedCredentialFormat, err)
 	}
 
+	if vcOpts.normalizeDates && externalJWT == "" && vcJSON[jsonFldLDProof] == nil {
+		vcJSON = normalizeDates(vcJSON)
+	}
+
 	contents, err := parseCredentialContents(vcJSON, jwtParseRes.isSDJWT)
 	if err != nil {
 		return nil, err
@@ -0,0 +1,168 @@
+/*
+Copyright Gen Digital Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package verifiable
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+)
+
+const (
+	// RefreshService2021 is the type of the refresh services that Credential.Refresh supports.
+	RefreshService2021 = "VerifiableCredentialRefreshService2021"
+
+	jsonFldRefreshServiceURL = "url"
+)
+
+// ErrNoRefreshService is returned by Credential.Refresh when the credential has no
+// refresh service of a supported type.
+var ErrNoRefreshService = errors.New("credential has no supported refresh service")
+
+// RefreshService is a service from which a fresh version of a credential can be
+// obtained, eg when it is about to expire.
+type RefreshService struct {
+	TypedID
+}
+
+// URL returns the endpoint of the refresh service: its url field, as with
+// VerifiableCredentialRefreshService2021, else its id.
+func (s RefreshService) URL() string {
+	if url, ok := s.CustomFields[jsonFldRefreshServiceURL].(string); ok && url != "" {
+		return url
+	}
+
+	return s.ID
+}
+
+// RefreshServices returns the refresh services of the credential, in the order they
+// are listed in its refreshService property.
+func (vc *Credential) RefreshServices() []RefreshService {
+	// The credential was parsed, so the refresh services are well-formed.
+	typedIDs, err := parseTypedID(vc.credentialJSON[jsonFldRefreshService])
+	if err != nil || len(typedIDs) == 0 {
+		return nil
+	}
+
+	services := make([]RefreshService, len(typedIDs))
+
+	for i, typedID := range typedIDs {
+		services[i] = RefreshService{TypedID: typedID}
+	}
+
+	return services
+}
+
+// Refresh gets a fresh version of the credential from its first VerifiableCredentialRefreshService2021
+// refresh service. The credential is POSTed to the URL of the service in an unsigned presentation, and
+// the service responds with the refreshed credential, or with a presentation of it. The refreshed
+// credential is parsed with the given options.
+//
+// Refresh services that require the holder to authenticate with a signed presentation are not supported.
+func (vc *Credential) Refresh(ctx context.Context, client httpClient, opts ...CredentialOpt) (*Credential, error) {
+	var serviceURL string
+
+	for _, service := range vc.RefreshServices() {
+		if service.Type == RefreshService2021 && service.URL() != "" {
+			serviceURL = service.URL()
+
+			break
+		}
+	}
+
+	if serviceURL == "" {
+		return nil, ErrNoRefreshService
+	}
+
+	vp, err := NewPresentation(WithCredentials(vc))
+	if err != nil {
+		return nil, fmt.Errorf("create refresh presentation: %w", err)
+	}
+
+	vpBytes, err := vp.MarshalJSON()
+	if err != nil {
+		return nil, fmt.Errorf("marshal refresh presentation: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, serviceURL, bytes.NewReader(vpBytes))
+	if err != nil {
+		return nil, fmt.Errorf("create refresh request: %w", err)
+	}
+
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("refresh credential at %s: %w", serviceURL, err)
+	}
+
+	defer func() {
+		_ = resp.Body.Close() // nolint:errcheck
+	}()
+
+	respBytes, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("read refresh response: %w", err)
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("refresh credential at %s: status code %d", serviceURL, resp.StatusCode)
+	}
+
+	vcBytes, err := refreshedCredential(respBytes)
+	if err != nil {
+		return nil, err
+	}
+
+	refreshed, err := ParseCredential(vcBytes, opts...)
+	if err != nil {
+		return nil, fmt.Errorf("parse refreshed credential: %w", err)
+	}
+
+	return refreshed, nil
+}
+
+// refreshedCredential returns the credential of a refresh response, which is either the
+// credential or a presentation of it.
+func refreshedCredential(respBytes []byte) ([]byte, error) {
+	var presentation struct {
+		VerifiableCredential json.RawMessage `json:"verifiableCredential"`
+	}
+
+	if err := json.Unmarshal(respBytes, &presentation); err != nil || len(presentation.VerifiableCredential) == 0 {
+		// Not a JSON object with a verifiableCredential, eg a JWT credential.
+		return respBytes, nil
+	}
+
+	var credentials []json.RawMessage
+
+	if err := json.Unmarshal(presentation.VerifiableCredential, &credentials); err != nil {
+		return unquoteCredential(presentation.VerifiableCredential), nil
+	}
+
+	if len(credentials) == 0 {
+		return nil, errors.New("refresh response has an empty presentation")
+	}
+
+	return unquoteCredential(credentials[0]), nil
+}
+
+// unquoteCredential returns the JWT of a credential given as a JSON string, or else the
+// credential as it is.
+func unquoteCredential(vcBytes []byte) []byte {
+	var jwt string
+
+	if err := json.Unmarshal(vcBytes, &jwt); err == nil {
+		return []byte(jwt)
+	}
+
+	return vcBytes
+}
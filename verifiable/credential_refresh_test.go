@@ -0,0 +1,144 @@
+/*
+Copyright Gen Digital Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package verifiable
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"github.com/tidwall/sjson"
+)
+
+func TestCredential_RefreshServices(t *testing.T) {
+	t.Run("one refresh service", func(t *testing.T) {
+		vc, err := parseTestCredential(t, []byte(v1ValidCredential), WithDisabledProofCheck())
+		require.NoError(t, err)
+
+		services := vc.RefreshServices()
+		require.Len(t, services, 1)
+		require.Equal(t, vc.Contents().RefreshService.ID, services[0].ID)
+		require.Equal(t, vc.Contents().RefreshService.Type, services[0].Type)
+		require.Equal(t, services[0].ID, services[0].URL())
+	})
+
+	t.Run("several refresh services", func(t *testing.T) {
+		vc := newRefreshableCredential(t, "https://university.example/refresh")
+
+		services := vc.RefreshServices()
+		require.Len(t, services, 2)
+		require.Equal(t, "ManualRefreshService2018", services[0].Type)
+		require.Equal(t, "https://example.edu/refresh/3732", services[0].URL())
+		require.Equal(t, RefreshService2021, services[1].Type)
+		require.Equal(t, "https://university.example/refresh", services[1].URL())
+	})
+
+	t.Run("no refresh service", func(t *testing.T) {
+		vc, err := parseTestCredential(t, []byte(v2ValidCredential), WithDisabledProofCheck())
+		require.NoError(t, err)
+
+		require.Empty(t, vc.RefreshServices())
+	})
+}
+
+func TestCredential_Refresh(t *testing.T) {
+	refreshedJSON, err := sjson.Set(v2ValidCredential, "id", "http://example.edu/credentials/refreshed")
+	require.NoError(t, err)
+
+	parseOpts := []CredentialOpt{WithJSONLDDocumentLoader(createTestDocumentLoader(t)), WithDisabledProofCheck()}
+
+	t.Run("credential response", func(t *testing.T) {
+		var requested map[string]interface{}
+
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			require.Equal(t, http.MethodPost, r.Method)
+			require.Equal(t, "application/json", r.Header.Get("Content-Type"))
+
+			body, e := io.ReadAll(r.Body)
+			require.NoError(t, e)
+			require.NoError(t, json.Unmarshal(body, &requested))
+
+			_, _ = w.Write([]byte(refreshedJSON)) // nolint:errcheck
+		}))
+		defer server.Close()
+
+		vc := newRefreshableCredential(t, server.URL)
+
+		refreshed, err := vc.Refresh(context.Background(), server.Client(), parseOpts...)
+		require.NoError(t, err)
+		require.Equal(t, "http://example.edu/credentials/refreshed", refreshed.Contents().ID)
+
+		require.Contains(t, requested["type"], "VerifiablePresentation")
+		require.Len(t, requested["verifiableCredential"], 1)
+	})
+
+	t.Run("presentation response", func(t *testing.T) {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			_, _ = w.Write([]byte(`{"type":"VerifiablePresentation","verifiableCredential":[` + // nolint:errcheck
+				refreshedJSON + `]}`))
+		}))
+		defer server.Close()
+
+		refreshed, err := newRefreshableCredential(t, server.URL).Refresh(context.Background(), server.Client(),
+			parseOpts...)
+		require.NoError(t, err)
+		require.Equal(t, "http://example.edu/credentials/refreshed", refreshed.Contents().ID)
+	})
+
+	t.Run("error status", func(t *testing.T) {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusForbidden)
+		}))
+		defer server.Close()
+
+		_, err := newRefreshableCredential(t, server.URL).Refresh(context.Background(), server.Client(),
+			parseOpts...)
+		require.ErrorContains(t, err, "status code 403")
+	})
+
+	t.Run("empty presentation response", func(t *testing.T) {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			_, _ = w.Write([]byte(`{"verifiableCredential":[]}`)) // nolint:errcheck
+		}))
+		defer server.Close()
+
+		_, err := newRefreshableCredential(t, server.URL).Refresh(context.Background(), server.Client(),
+			parseOpts...)
+		require.ErrorContains(t, err, "empty presentation")
+	})
+
+	t.Run("no supported refresh service", func(t *testing.T) {
+		vc, err := parseTestCredential(t, []byte(v1ValidCredential), WithDisabledProofCheck())
+		require.NoError(t, err)
+
+		_, err = vc.Refresh(context.Background(), http.DefaultClient)
+		require.ErrorIs(t, err, ErrNoRefreshService)
+	})
+}
+
+func newRefreshableCredential(t *testing.T, serviceURL string) *Credential {
+	t.Helper()
+
+	vcJSON, err := sjson.SetRaw(v2ValidCredential, "refreshService", `[{
+		"id": "https://example.edu/refresh/3732",
+		"type": "ManualRefreshService2018"
+	}, {
+		"id": "urn:uuid:8a6ea4ba-2a92-4cb1-8d4a-3dd16a0d8a7b",
+		"type": "VerifiableCredentialRefreshService2021",
+		"url": "`+serviceURL+`"
+	}]`)
+	require.NoError(t, err)
+
+	vc, err := parseTestCredential(t, []byte(vcJSON), WithDisabledProofCheck())
+	require.NoError(t, err)
+
+	return vc
+}
@@ -0,0 +1,110 @@
+/*
+Copyright Gen Digital Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package verifiable
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/xeipuuv/gojsonschema"
+)
+
+// SchemaLoader loads the JSON schema documents referenced by the credentialSchema of credentials.
+// CredentialSchemaLoader is a SchemaLoader downloading the schemas over HTTP.
+type SchemaLoader interface {
+	LoadSchema(id string) ([]byte, error)
+}
+
+// WithCredentialSchemaValidation option makes parsing validate the credential subjects against the
+// JSON schemas referenced in credentialSchema with JsonSchema or JsonSchema2023 type, which are loaded
+// with loader. It fails with a *CredentialSchemaError if a subject doesn't conform to one of them.
+// The credentials without such credentialSchema are not checked.
+func WithCredentialSchemaValidation(loader SchemaLoader) CredentialOpt {
+	return func(opts *credentialOpts) {
+		opts.subjectSchemaLoader = loader
+	}
+}
+
+// CredentialSchemaError is returned when a credential subject does not conform to a credentialSchema
+// of the credential, see WithCredentialSchemaValidation.
+type CredentialSchemaError struct {
+	// SchemaID is the id of the credentialSchema.
+	SchemaID string
+	// Failures lists the validation failures, in the order of the JSON schema validator.
+	Failures []CredentialSchemaFailure
+}
+
+// CredentialSchemaFailure describes a JSON schema validation failure of a credential subject.
+type CredentialSchemaFailure struct {
+	// Path of the failing field in the credential, e.g. "credentialSubject.degree.type".
+	Path string
+	// Description of the failure.
+	Description string
+}
+
+func (e *CredentialSchemaError) Error() string {
+	var sb strings.Builder
+
+	fmt.Fprintf(&sb, "credential subject does not conform to credential schema %s:", e.SchemaID)
+
+	for _, failure := range e.Failures {
+		fmt.Fprintf(&sb, "\n- %s: %s", failure.Path, failure.Description)
+	}
+
+	return sb.String()
+}
+
+func validateSubjectUsingCredentialSchemas(vcc *CredentialContents, loader SchemaLoader) error {
+	for _, credentialSchema := range vcc.Schemas {
+		if credentialSchema.Type != jsonSchemaType && credentialSchema.Type != jsonSchema2023Type {
+			continue
+		}
+
+		schemaData, err := loader.LoadSchema(credentialSchema.ID)
+		if err != nil {
+			return fmt.Errorf("load credential schema %s: %w", credentialSchema.ID, err)
+		}
+
+		schema, err := gojsonschema.NewSchema(gojsonschema.NewBytesLoader(schemaData))
+		if err != nil {
+			return fmt.Errorf("compile credential schema %s: %w", credentialSchema.ID, err)
+		}
+
+		schemaErr := &CredentialSchemaError{SchemaID: credentialSchema.ID}
+
+		for i, subject := range vcc.Subject {
+			subjectPath := jsonFldSubject
+			if len(vcc.Subject) > 1 {
+				subjectPath = fmt.Sprintf("%s[%d]", jsonFldSubject, i)
+			}
+
+			result, err := schema.Validate(gojsonschema.NewGoLoader(SubjectToJSON(subject)))
+			if err != nil {
+				return fmt.Errorf("validate %s against credential schema %s: %w",
+					subjectPath, credentialSchema.ID, err)
+			}
+
+			for _, desc := range result.Errors() {
+				path := subjectPath
+				if desc.Field() != gojsonschema.STRING_CONTEXT_ROOT {
+					path += "." + desc.Field()
+				}
+
+				schemaErr.Failures = append(schemaErr.Failures, CredentialSchemaFailure{
+					Path:        path,
+					Description: desc.Description(),
+				})
+			}
+		}
+
+		if len(schemaErr.Failures) > 0 {
+			return schemaErr
+		}
+	}
+
+	return nil
+}
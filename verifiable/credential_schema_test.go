@@ -0,0 +1,137 @@
+/*
+Copyright Gen Digital Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package verifiable
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+const degreeSchema = `{
+  "$schema": "http://json-schema.org/draft-07/schema#",
+  "type": "object",
+  "required": ["id", "name", "degree"],
+  "properties": {
+    "name": {"type": "string"},
+    "degree": {
+      "type": "object",
+      "required": ["type"],
+      "properties": {"type": {"enum": ["BachelorDegree", "MasterDegree"]}}
+    }
+  }
+}`
+
+type schemaLoaderFunc func(id string) ([]byte, error)
+
+func (f schemaLoaderFunc) LoadSchema(id string) ([]byte, error) {
+	return f(id)
+}
+
+func TestWithCredentialSchemaValidation(t *testing.T) {
+	const schemaID = "https://example.edu/schemas/degree.json"
+
+	var loaded []string
+
+	loader := schemaLoaderFunc(func(id string) ([]byte, error) {
+		loaded = append(loaded, id)
+
+		switch id {
+		case schemaID:
+			return []byte(degreeSchema), nil
+		case "https://example.edu/schemas/invalid.json":
+			return []byte(`{"type": 5}`), nil
+		}
+
+		return nil, errors.New("schema not found")
+	})
+
+	parse := func(t *testing.T, subject, schema string) (*Credential, error) {
+		t.Helper()
+
+		loaded = nil
+
+		vc := `{
+			"@context": ["https://www.w3.org/2018/credentials/v1", "https://www.w3.org/2018/credentials/examples/v1"],
+			"type": ["VerifiableCredential", "UniversityDegreeCredential"],
+			"issuer": "did:example:76e12ec712ebc6f1c221ebfeb1f",
+			"issuanceDate": "2010-01-01T19:23:24Z",
+			"credentialSubject": ` + subject
+
+		if schema != "" {
+			vc += `, "credentialSchema": ` + schema
+		}
+
+		return parseTestCredential(t, []byte(vc+"}"), WithDisabledProofCheck(), WithNoCustomSchemaCheck(),
+			WithCredentialSchemaValidation(loader))
+	}
+
+	validSubject := `{
+		"id": "did:example:ebfeb1f712ebc6f1c276e12ec21",
+		"name": "Jayden Doe",
+		"degree": {"type": "BachelorDegree"}
+	}`
+	invalidSubject := `{
+		"id": "did:example:ebfeb1f712ebc6f1c276e12ec21",
+		"degree": {"type": "HighSchoolDiploma"}
+	}`
+
+	t.Run("valid subject", func(t *testing.T) {
+		for _, schemaType := range []string{"JsonSchema", "JsonSchema2023"} {
+			_, err := parse(t, validSubject, `{"id": "`+schemaID+`", "type": "`+schemaType+`"}`)
+			require.NoError(t, err)
+			require.Equal(t, []string{schemaID}, loaded)
+		}
+	})
+
+	t.Run("invalid subject", func(t *testing.T) {
+		_, err := parse(t, invalidSubject, `{"id": "`+schemaID+`", "type": "JsonSchema"}`)
+		require.Error(t, err)
+
+		var schemaErr *CredentialSchemaError
+
+		require.ErrorAs(t, err, &schemaErr)
+		require.Equal(t, schemaID, schemaErr.SchemaID)
+		require.Len(t, schemaErr.Failures, 2)
+		require.Equal(t, "credentialSubject", schemaErr.Failures[0].Path)
+		require.Equal(t, "name is required", schemaErr.Failures[0].Description)
+		require.Equal(t, "credentialSubject.degree.type", schemaErr.Failures[1].Path)
+		require.EqualError(t, err, "credential subject does not conform to credential schema "+schemaID+":\n"+
+			"- credentialSubject: name is required\n"+
+			"- credentialSubject.degree.type: "+schemaErr.Failures[1].Description)
+	})
+
+	t.Run("invalid subject of several", func(t *testing.T) {
+		_, err := parse(t, "["+validSubject+","+invalidSubject+"]", `{"id": "`+schemaID+`", "type": "JsonSchema"}`)
+
+		var schemaErr *CredentialSchemaError
+
+		require.ErrorAs(t, err, &schemaErr)
+		require.Len(t, schemaErr.Failures, 2)
+		require.Equal(t, "credentialSubject[1]", schemaErr.Failures[0].Path)
+		require.Equal(t, "credentialSubject[1].degree.type", schemaErr.Failures[1].Path)
+	})
+
+	t.Run("no JSON schema", func(t *testing.T) {
+		_, err := parse(t, invalidSubject, "")
+		require.NoError(t, err)
+
+		_, err = parse(t, invalidSubject, `{"id": "`+schemaID+`", "type": "JsonSchemaCredential"}`)
+		require.NoError(t, err)
+		require.Empty(t, loaded)
+	})
+
+	t.Run("failure", func(t *testing.T) {
+		_, err := parse(t, validSubject, `{"id": "https://example.edu/schemas/missing.json", "type": "JsonSchema"}`)
+		require.ErrorContains(t, err, "load credential schema https://example.edu/schemas/missing.json: "+
+			"schema not found")
+
+		_, err = parse(t, validSubject, `{"id": "https://example.edu/schemas/invalid.json", "type": "JsonSchema"}`)
+		require.ErrorContains(t, err, "compile credential schema https://example.edu/schemas/invalid.json")
+	})
+}
@@ -484,23 +484,9 @@ func (vc *Credential) CreateDisplayCredential( // nolint:funlen,gocyclo
 		return vc, nil
 	}
 
-	credClaims := &JWTCredClaims{}
-
-	_, err := unmarshalJWT(vc.JWTEnvelope.JWT, credClaims)
-	if err != nil {
-		return nil, fmt.Errorf("unmarshal VC JWT claims: %w", err)
-	}
-
-	err = credClaims.refineFromJWTClaims()
+	newVCObj, err := vc.disclosedVCObject(filterDisclosureList(vc.JWTEnvelope.SDJWTDisclosures, options))
 	if err != nil {
-		return nil, fmt.Errorf("refineFromJWTClaims claims: %w", err)
-	}
-
-	useDisclosures := filterDisclosureList(vc.JWTEnvelope.SDJWTDisclosures, options)
-
-	newVCObj, err := common.GetDisclosedClaims(useDisclosures, credClaims.VC)
-	if err != nil {
-		return nil, fmt.Errorf("assembling disclosed claims into vc: %w", err)
+		return nil, err
 	}
 
 	if subj, ok := newVCObj["credentialSubject"].(map[string]interface{}); ok {
@@ -546,6 +532,34 @@ func (vc *Credential) CreateDisplayCredentialMap( // nolint:funlen,gocyclo
 		return vc.credentialJSON, nil
 	}
 
+	newVCObj, err := vc.disclosedVCObject(filterDisclosureList(vc.JWTEnvelope.SDJWTDisclosures, options))
+	if err != nil {
+		return nil, err
+	}
+
+	if subj, ok := newVCObj["credentialSubject"].(map[string]interface{}); ok {
+		clearEmpty(subj)
+	}
+
+	return newVCObj, nil
+}
+
+// DisclosedClaims returns, for SD-JWT credentials, the claims of the credential with the presented
+// disclosures applied: the _sd digests of the objects, and the array element digests, are replaced
+// with the disclosed claims and array elements, recursively for the disclosures within disclosures.
+// The digests without a presented disclosure are removed, as is _sd_alg.
+//
+// If the calling Credential is not an SD-JWT credential, this method returns its JSON, as ToRawJSON.
+func (vc *Credential) DisclosedClaims() (map[string]interface{}, error) {
+	if vc.credentialContents.SDJWTHashAlg == nil || vc.JWTEnvelope == nil {
+		return vc.ToRawJSON(), nil
+	}
+
+	return vc.disclosedVCObject(vc.JWTEnvelope.SDJWTDisclosures)
+}
+
+// disclosedVCObject returns the vc claim of the SD-JWT of the credential with the disclosures applied.
+func (vc *Credential) disclosedVCObject(disclosures []*common.DisclosureClaim) (map[string]interface{}, error) {
 	credClaims := &JWTCredClaims{}
 
 	_, err := unmarshalJWT(vc.JWTEnvelope.JWT, credClaims)
@@ -558,17 +572,11 @@ func (vc *Credential) CreateDisplayCredentialMap( // nolint:funlen,gocyclo
 		return nil, fmt.Errorf("refineFromJWTClaims claims: %w", err)
 	}
 
-	useDisclosures := filterDisclosureList(vc.JWTEnvelope.SDJWTDisclosures, options)
-
-	newVCObj, err := common.GetDisclosedClaims(useDisclosures, credClaims.VC)
+	newVCObj, err := common.GetDisclosedClaims(disclosures, credClaims.VC)
 	if err != nil {
 		return nil, fmt.Errorf("assembling disclosed claims into vc: %w", err)
 	}
 
-	if subj, ok := newVCObj["credentialSubject"].(map[string]interface{}); ok {
-		clearEmpty(subj)
-	}
-
 	return newVCObj, nil
 }
 
@@ -17,6 +17,7 @@ import (
 	"github.com/go-jose/go-jose/v3/jwt"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
+	"github.com/tidwall/sjson"
 
 	"github.com/trustbloc/vc-go/crypto-ext/testutil"
 	afgjwt "github.com/trustbloc/vc-go/jwt"
@@ -598,6 +599,99 @@ func TestCreateDisplayCredential(t *testing.T) {
 	})
 }
 
+func TestDisclosedClaims(t *testing.T) {
+	pubKey, privKey, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+
+	proofCreator, proofChecker := testsupport.NewEd25519Pair(pubKey, privKey, signingKeyID)
+
+	joseSigner, err := afgjwt.NewJOSESigner(afgjwt.SignParameters{
+		KeyID:  signingKeyID,
+		JWTAlg: "EdDSA",
+	}, proofCreator)
+	require.NoError(t, err)
+
+	credJSON, err := sjson.Set(jwtTestCredential, "credentialSubject.languages", []string{"en", "fr"})
+	require.NoError(t, err)
+
+	srcVC, err := parseTestCredential(t, []byte(credJSON), WithDisabledProofCheck())
+	require.NoError(t, err)
+
+	expectedSubject := map[string]interface{}{
+		"id": "did:example:ebfeb1f712ebc6f1c276e12ec21",
+		"degree": map[string]interface{}{
+			"type":       "BachelorDegree",
+			"university": "MIT",
+		},
+		"languages": []interface{}{"en", "fr"},
+	}
+
+	t.Run("success", func(t *testing.T) {
+		for name, opts := range map[string][]MakeSDJWTOption{
+			"default version":    nil,
+			"nested disclosures": {MakeSDJWTWithRecursiveClaimsObjects([]string{"degree"})},
+			"array element, v5":  {MakeSDJWTWithVersion(common.SDJWTVersionV5)},
+			"nested disclosures, v5": {
+				MakeSDJWTWithVersion(common.SDJWTVersionV5),
+				MakeSDJWTWithRecursiveClaimsObjects([]string{"degree"}),
+			},
+		} {
+			t.Run(name, func(t *testing.T) {
+				sdJWT, err := srcVC.MakeSDJWT(joseSigner, signingKeyID, opts...)
+				require.NoError(t, err)
+
+				vc, err := ParseCredential([]byte(sdJWT), WithProofChecker(proofChecker))
+				require.NoError(t, err)
+
+				claims, err := vc.DisclosedClaims()
+				require.NoError(t, err)
+				require.Equal(t, expectedSubject, claims["credentialSubject"])
+				require.Equal(t, "did:example:76e12ec712ebc6f1c221ebfeb1f",
+					claims["issuer"].(map[string]interface{})["id"])
+				require.NotContains(t, claims, common.SDAlgorithmKey)
+			})
+		}
+	})
+
+	t.Run("undisclosed claims are removed", func(t *testing.T) {
+		sdJWT, err := srcVC.MakeSDJWT(joseSigner, signingKeyID)
+		require.NoError(t, err)
+
+		vc, err := ParseCredential([]byte(sdJWT), WithDisabledProofCheck())
+		require.NoError(t, err)
+
+		presented, err := vc.MarshalWithDisclosure(DiscloseGivenRequired([]string{"type"}))
+		require.NoError(t, err)
+
+		vc, err = ParseCredential([]byte(presented), WithDisabledProofCheck())
+		require.NoError(t, err)
+
+		claims, err := vc.DisclosedClaims()
+		require.NoError(t, err)
+		require.Equal(t, map[string]interface{}{
+			"id":     "did:example:ebfeb1f712ebc6f1c276e12ec21",
+			"degree": map[string]interface{}{"type": "BachelorDegree"},
+		}, claims["credentialSubject"])
+	})
+
+	t.Run("not a SD-JWT credential", func(t *testing.T) {
+		claims, err := srcVC.DisclosedClaims()
+		require.NoError(t, err)
+		require.Equal(t, map[string]interface{}(srcVC.ToRawJSON()), claims)
+	})
+
+	t.Run("malformed JWT", func(t *testing.T) {
+		badAlg := crypto.Hash(0)
+		badVC := &Credential{
+			JWTEnvelope:        &JWTEnvelope{JWT: "blah blah blahblah blah"},
+			credentialContents: CredentialContents{SDJWTHashAlg: &badAlg},
+		}
+
+		_, err := badVC.DisclosedClaims()
+		require.ErrorContains(t, err, "unmarshal VC JWT claims")
+	})
+}
+
 type mockSigner struct {
 	signErr error
 }
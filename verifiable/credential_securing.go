@@ -0,0 +1,79 @@
+/*
+Copyright Gen Digital Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package verifiable
+
+import (
+	"github.com/trustbloc/vc-go/dataintegrity/models"
+)
+
+// SecuringKind is a mechanism securing a Credential.
+type SecuringKind int
+
+const (
+	// SecuringKindUnsecured is used for a credential without proof or envelope.
+	SecuringKindUnsecured SecuringKind = iota
+	// SecuringKindDataIntegrity is used for a credential with an embedded Data Integrity proof.
+	SecuringKindDataIntegrity
+	// SecuringKindLinkedDataProof is used for a credential with an embedded proof of another
+	// type, eg Ed25519Signature2020.
+	SecuringKindLinkedDataProof
+	// SecuringKindJWT is used for a credential enveloped into a JWT.
+	SecuringKindJWT
+	// SecuringKindSDJWT is used for a credential enveloped into a selective disclosure JWT.
+	SecuringKindSDJWT
+	// SecuringKindCWT is used for a credential enveloped into a CWT.
+	SecuringKindCWT
+)
+
+// SecuringMechanism returns the outermost mechanism securing the credential, eg to route it
+// to the matching verification: its envelope if it has one, else its embedded proofs, the Data
+// Integrity ones first. See SecuringMechanisms for the credentials secured in several ways.
+func (vc *Credential) SecuringMechanism() SecuringKind {
+	kinds := vc.SecuringMechanisms()
+	if len(kinds) == 0 {
+		return SecuringKindUnsecured
+	}
+
+	return kinds[0]
+}
+
+// SecuringMechanisms returns all the mechanisms securing the credential, outermost first: the
+// envelope, then the kinds of its embedded proofs, eg [SecuringKindJWT, SecuringKindDataIntegrity]
+// for a JWT enveloping a credential with a Data Integrity proof. It returns nil for an unsecured
+// credential.
+func (vc *Credential) SecuringMechanisms() []SecuringKind {
+	var kinds []SecuringKind
+
+	switch {
+	case vc.IsCWT():
+		kinds = append(kinds, SecuringKindCWT)
+	case vc.IsJWT() && (len(vc.SDJWTDisclosures()) > 0 || vc.credentialContents.SDJWTHashAlg != nil):
+		kinds = append(kinds, SecuringKindSDJWT)
+	case vc.IsJWT():
+		kinds = append(kinds, SecuringKindJWT)
+	}
+
+	var hasDataIntegrity, hasLinkedData bool
+
+	for _, proof := range vc.ldProofs {
+		if proof["type"] == models.DataIntegrityProof {
+			hasDataIntegrity = true
+		} else {
+			hasLinkedData = true
+		}
+	}
+
+	if hasDataIntegrity {
+		kinds = append(kinds, SecuringKindDataIntegrity)
+	}
+
+	if hasLinkedData {
+		kinds = append(kinds, SecuringKindLinkedDataProof)
+	}
+
+	return kinds
+}
@@ -0,0 +1,102 @@
+/*
+Copyright Gen Digital Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package verifiable
+
+import (
+	"crypto"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/trustbloc/vc-go/dataintegrity/models"
+	"github.com/trustbloc/vc-go/sdjwt/common"
+)
+
+func TestCredential_SecuringMechanism(t *testing.T) {
+	sha256Alg := crypto.SHA256
+
+	tests := []struct {
+		name   string
+		vc     *Credential
+		expect []SecuringKind
+	}{
+		{
+			name: "unsecured",
+			vc:   &Credential{},
+		},
+		{
+			name:   "data integrity",
+			vc:     &Credential{ldProofs: []Proof{{"type": models.DataIntegrityProof}}},
+			expect: []SecuringKind{SecuringKindDataIntegrity},
+		},
+		{
+			name: "linked data proofs",
+			vc: &Credential{ldProofs: []Proof{
+				{"type": "Ed25519Signature2018"},
+				{"type": models.DataIntegrityProof},
+				{"type": "Ed25519Signature2020"},
+			}},
+			expect: []SecuringKind{SecuringKindDataIntegrity, SecuringKindLinkedDataProof},
+		},
+		{
+			name:   "JWT",
+			vc:     &Credential{JWTEnvelope: &JWTEnvelope{JWT: "a.b.c"}},
+			expect: []SecuringKind{SecuringKindJWT},
+		},
+		{
+			name: "JWT with data integrity proof",
+			vc: &Credential{
+				JWTEnvelope: &JWTEnvelope{JWT: "a.b.c"},
+				ldProofs:    []Proof{{"type": models.DataIntegrityProof}},
+			},
+			expect: []SecuringKind{SecuringKindJWT, SecuringKindDataIntegrity},
+		},
+		{
+			name: "SD-JWT",
+			vc: &Credential{JWTEnvelope: &JWTEnvelope{
+				JWT:              "a.b.c",
+				SDJWTDisclosures: []*common.DisclosureClaim{{Name: "name"}},
+			}},
+			expect: []SecuringKind{SecuringKindSDJWT},
+		},
+		{
+			name: "SD-JWT with all disclosures omitted",
+			vc: &Credential{
+				JWTEnvelope:        &JWTEnvelope{JWT: "a.b.c"},
+				credentialContents: CredentialContents{SDJWTHashAlg: &sha256Alg},
+			},
+			expect: []SecuringKind{SecuringKindSDJWT},
+		},
+		{
+			name:   "CWT",
+			vc:     &Credential{CWTEnvelope: &CWTEnvelope{Sign1MessageRaw: []byte{1}}},
+			expect: []SecuringKind{SecuringKindCWT},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			require.Equal(t, tt.expect, tt.vc.SecuringMechanisms())
+
+			if len(tt.expect) == 0 {
+				require.Equal(t, SecuringKindUnsecured, tt.vc.SecuringMechanism())
+			} else {
+				require.Equal(t, tt.expect[0], tt.vc.SecuringMechanism())
+			}
+		})
+	}
+
+	t.Run("enveloped linked data proof", func(t *testing.T) {
+		vc, _ := createVCWithLinkedDataProof(t)
+		require.Equal(t, SecuringKindLinkedDataProof, vc.SecuringMechanism())
+
+		jwtVC, err := vc.CreateUnsecuredJWTVC(false)
+		require.NoError(t, err)
+		require.Equal(t, SecuringKindJWT, jwtVC.SecuringMechanism())
+		require.Equal(t, []SecuringKind{SecuringKindJWT, SecuringKindLinkedDataProof}, jwtVC.SecuringMechanisms())
+	})
+}
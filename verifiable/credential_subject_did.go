@@ -0,0 +1,88 @@
+/*
+Copyright Gen Digital Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package verifiable
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/trustbloc/did-go/doc/did"
+)
+
+// ErrSubjectDIDResolution is returned by ParseCredential, with WithSubjectDIDResolution, when a
+// subject DID of the credential can't be resolved. It wraps the error of the resolver.
+var ErrSubjectDIDResolution = errors.New("credential subject DID can't be resolved")
+
+// WithSubjectDIDResolution option makes ParseCredential resolve the DIDs of the credential subjects,
+// see Credential.SubjectDIDs, with the resolver set with WithSubjectDIDResolver. The credential is
+// rejected with ErrSubjectDIDResolution if one of them doesn't resolve, eg for a holder binding
+// check where the presenter must control the subject DID. The subjects without DID are not checked.
+func WithSubjectDIDResolution() CredentialOpt {
+	return func(opts *credentialOpts) {
+		opts.subjectDIDResolution = true
+	}
+}
+
+// WithSubjectDIDResolver sets the resolver of the subject DIDs used by WithSubjectDIDResolution.
+func WithSubjectDIDResolver(resolver didResolver) CredentialOpt {
+	return func(opts *credentialOpts) {
+		opts.subjectDIDResolver = resolver
+	}
+}
+
+// SubjectDID returns the id of the first credential subject whose id is a DID, see SubjectDIDs
+// for the credentials with several subjects.
+func (vc *Credential) SubjectDID() (string, bool) {
+	dids := vc.SubjectDIDs()
+	if len(dids) == 0 {
+		return "", false
+	}
+
+	return dids[0], true
+}
+
+// SubjectDIDs returns the ids of the credential subjects which are DIDs, in the order of the
+// subjects. The subjects without id, or with an id which is not a DID, eg a URL, are skipped.
+func (vc *Credential) SubjectDIDs() []string {
+	var dids []string
+
+	for _, subject := range vc.credentialContents.Subject {
+		if subject.ID == "" {
+			continue
+		}
+
+		if _, err := did.Parse(subject.ID); err == nil {
+			dids = append(dids, subject.ID)
+		}
+	}
+
+	return dids
+}
+
+func resolveSubjectDIDs(vc *Credential, resolver didResolver) error {
+	dids := vc.SubjectDIDs()
+	if len(dids) == 0 {
+		return nil
+	}
+
+	if resolver == nil {
+		return fmt.Errorf("%w: no subject DID resolver", ErrSubjectDIDResolution)
+	}
+
+	for _, didID := range dids {
+		docResolution, err := resolver.Resolve(didID)
+		if err != nil {
+			return fmt.Errorf("%w: resolve %q: %w", ErrSubjectDIDResolution, didID, err)
+		}
+
+		if docResolution == nil || docResolution.DIDDocument == nil {
+			return fmt.Errorf("%w: resolve %q: no DID document", ErrSubjectDIDResolution, didID)
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,110 @@
+/*
+Copyright Gen Digital Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package verifiable
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"github.com/tidwall/sjson"
+	"github.com/trustbloc/did-go/doc/did"
+)
+
+func TestCredential_SubjectDIDs(t *testing.T) {
+	t.Run("single subject", func(t *testing.T) {
+		vc, err := parseTestCredential(t, []byte(jwtTestCredential), WithDisabledProofCheck())
+		require.NoError(t, err)
+
+		subjectDID, ok := vc.SubjectDID()
+		require.True(t, ok)
+		require.Equal(t, "did:example:ebfeb1f712ebc6f1c276e12ec21", subjectDID)
+		require.Equal(t, []string{"did:example:ebfeb1f712ebc6f1c276e12ec21"}, vc.SubjectDIDs())
+	})
+
+	t.Run("multiple subjects", func(t *testing.T) {
+		credJSON, err := sjson.SetRaw(jwtTestCredential, "credentialSubject",
+			`[{"id":"https://example.com/subjects/1"},{"id":"did:example:alice"},{"degree":{"type":"BachelorDegree"}},`+
+				`{"id":"did:example:bob"}]`)
+		require.NoError(t, err)
+
+		vc, err := parseTestCredential(t, []byte(credJSON), WithDisabledProofCheck())
+		require.NoError(t, err)
+
+		subjectDID, ok := vc.SubjectDID()
+		require.True(t, ok)
+		require.Equal(t, "did:example:alice", subjectDID)
+		require.Equal(t, []string{"did:example:alice", "did:example:bob"}, vc.SubjectDIDs())
+	})
+
+	t.Run("no subject DID", func(t *testing.T) {
+		credJSON, err := sjson.Set(jwtTestCredential, "credentialSubject.id", "https://example.com/subjects/1")
+		require.NoError(t, err)
+
+		vc, err := parseTestCredential(t, []byte(credJSON), WithDisabledProofCheck())
+		require.NoError(t, err)
+
+		_, ok := vc.SubjectDID()
+		require.False(t, ok)
+		require.Empty(t, vc.SubjectDIDs())
+	})
+}
+
+func TestWithSubjectDIDResolution(t *testing.T) {
+	credJSON, err := sjson.SetRaw(jwtTestCredential, "credentialSubject",
+		`[{"id":"did:example:alice"},{"id":"did:example:bob"}]`)
+	require.NoError(t, err)
+
+	var resolved []string
+
+	resolver := resolveFunc(func(id string) (*did.DocResolution, error) {
+		resolved = append(resolved, id)
+
+		if id == "did:example:bob" {
+			return nil, errors.New("DID not found")
+		}
+
+		return &did.DocResolution{DIDDocument: &did.Doc{ID: id}}, nil
+	})
+
+	t.Run("success", func(t *testing.T) {
+		resolved = nil
+
+		_, err := parseTestCredential(t, []byte(jwtTestCredential), WithDisabledProofCheck(),
+			WithSubjectDIDResolution(), WithSubjectDIDResolver(resolver))
+		require.NoError(t, err)
+		require.Equal(t, []string{"did:example:ebfeb1f712ebc6f1c276e12ec21"}, resolved)
+
+		// The subject DIDs are not resolved without the option.
+		resolved = nil
+
+		_, err = parseTestCredential(t, []byte(credJSON), WithDisabledProofCheck(), WithSubjectDIDResolver(resolver))
+		require.NoError(t, err)
+		require.Empty(t, resolved)
+	})
+
+	t.Run("failure", func(t *testing.T) {
+		resolved = nil
+
+		_, err := parseTestCredential(t, []byte(credJSON), WithDisabledProofCheck(),
+			WithSubjectDIDResolution(), WithSubjectDIDResolver(resolver))
+		require.ErrorIs(t, err, ErrSubjectDIDResolution)
+		require.ErrorContains(t, err, `resolve "did:example:bob": DID not found`)
+		require.Equal(t, []string{"did:example:alice", "did:example:bob"}, resolved)
+
+		_, err = parseTestCredential(t, []byte(credJSON), WithDisabledProofCheck(), WithSubjectDIDResolution())
+		require.ErrorIs(t, err, ErrSubjectDIDResolution)
+		require.ErrorContains(t, err, "no subject DID resolver")
+
+		_, err = parseTestCredential(t, []byte(credJSON), WithDisabledProofCheck(), WithSubjectDIDResolution(),
+			WithSubjectDIDResolver(resolveFunc(func(string) (*did.DocResolution, error) {
+				return &did.DocResolution{}, nil
+			})))
+		require.ErrorIs(t, err, ErrSubjectDIDResolution)
+		require.ErrorContains(t, err, "no DID document")
+	})
+}
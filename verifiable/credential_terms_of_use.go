@@ -0,0 +1,69 @@
+/*
+Copyright Gen Digital Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package verifiable
+
+import (
+	"errors"
+	"fmt"
+)
+
+// ErrTermsOfUseViolation is returned by ParseCredential when the TermsOfUseEvaluator given
+// with WithTermsOfUseEvaluator rejects the terms of use of the credential. It wraps the error
+// of the evaluator.
+var ErrTermsOfUseViolation = errors.New("credential terms of use violated")
+
+// TermsOfUse is a policy under which the credential was issued, eg an IssuerPolicy
+// prohibiting the holder to share the credential further.
+type TermsOfUse struct {
+	TypedID
+}
+
+// TermsOfUseEvaluator evaluates the terms of use of a credential against the policies of
+// the relying party.
+type TermsOfUseEvaluator interface {
+	// EvaluateTermsOfUse returns an error if the relying party can't use the credential
+	// under the given terms of use.
+	EvaluateTermsOfUse(vc *Credential, terms []TermsOfUse) error
+}
+
+// WithTermsOfUseEvaluator makes ParseCredential evaluate the terms of use of credentials that
+// have some, once their proof is checked. The credential is rejected with ErrTermsOfUseViolation
+// if the evaluator returns an error.
+func WithTermsOfUseEvaluator(evaluator TermsOfUseEvaluator) CredentialOpt {
+	return func(opts *credentialOpts) {
+		opts.termsOfUseEvaluator = evaluator
+	}
+}
+
+// TermsOfUse returns the terms of use of the credential, in the order they are listed in
+// its termsOfUse property.
+func (vc *Credential) TermsOfUse() []TermsOfUse {
+	if len(vc.credentialContents.TermsOfUse) == 0 {
+		return nil
+	}
+
+	terms := make([]TermsOfUse, len(vc.credentialContents.TermsOfUse))
+
+	for i, typedID := range vc.credentialContents.TermsOfUse {
+		terms[i] = TermsOfUse{TypedID: typedID}
+	}
+
+	return terms
+}
+
+func evaluateTermsOfUse(vc *Credential, evaluator TermsOfUseEvaluator) error {
+	terms := vc.TermsOfUse()
+	if len(terms) == 0 {
+		return nil
+	}
+
+	if err := evaluator.EvaluateTermsOfUse(vc, terms); err != nil {
+		return fmt.Errorf("%w: %w", ErrTermsOfUseViolation, err)
+	}
+
+	return nil
+}
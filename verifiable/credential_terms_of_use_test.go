@@ -0,0 +1,99 @@
+/*
+Copyright Gen Digital Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package verifiable
+
+import (
+	"encoding/json"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"github.com/tidwall/sjson"
+)
+
+type termsOfUseEvaluatorFunc func(vc *Credential, terms []TermsOfUse) error
+
+func (f termsOfUseEvaluatorFunc) EvaluateTermsOfUse(vc *Credential, terms []TermsOfUse) error {
+	return f(vc, terms)
+}
+
+func TestCredential_TermsOfUse(t *testing.T) {
+	t.Run("parse and serialize", func(t *testing.T) {
+		vc, err := parseTestCredential(t, []byte(v1ValidCredential), WithDisabledProofCheck())
+		require.NoError(t, err)
+
+		terms := vc.TermsOfUse()
+		require.Len(t, terms, 1)
+		require.Equal(t, "IssuerPolicy", terms[0].Type)
+		require.Equal(t, "http://example.com/policies/credential/4", terms[0].ID)
+		require.Len(t, terms[0].CustomFields["prohibition"], 1)
+
+		vcBytes, err := vc.MarshalJSON()
+		require.NoError(t, err)
+
+		var raw map[string]interface{}
+		require.NoError(t, json.Unmarshal(vcBytes, &raw))
+		require.Len(t, raw["termsOfUse"], 1)
+
+		roundTripped, err := parseTestCredential(t, vcBytes, WithDisabledProofCheck())
+		require.NoError(t, err)
+		require.Equal(t, terms, roundTripped.TermsOfUse())
+	})
+
+	t.Run("no terms of use", func(t *testing.T) {
+		vcJSON, err := sjson.Delete(v1ValidCredential, "termsOfUse")
+		require.NoError(t, err)
+
+		vc, err := parseTestCredential(t, []byte(vcJSON), WithDisabledProofCheck())
+		require.NoError(t, err)
+		require.Empty(t, vc.TermsOfUse())
+	})
+}
+
+func TestWithTermsOfUseEvaluator(t *testing.T) {
+	errArchival := errors.New("archival prohibited")
+
+	prohibitArchival := termsOfUseEvaluatorFunc(func(vc *Credential, terms []TermsOfUse) error {
+		for _, term := range terms {
+			prohibitions, _ := term.CustomFields["prohibition"].([]interface{})
+			if term.Type == "IssuerPolicy" && len(prohibitions) > 0 {
+				return errArchival
+			}
+		}
+
+		return nil
+	})
+
+	t.Run("violation", func(t *testing.T) {
+		_, err := parseTestCredential(t, []byte(v1ValidCredential), WithDisabledProofCheck(),
+			WithTermsOfUseEvaluator(prohibitArchival))
+		require.ErrorIs(t, err, ErrTermsOfUseViolation)
+		require.ErrorIs(t, err, errArchival)
+	})
+
+	t.Run("accepted", func(t *testing.T) {
+		var evaluated []TermsOfUse
+
+		vc, err := parseTestCredential(t, []byte(v1ValidCredential), WithDisabledProofCheck(),
+			WithTermsOfUseEvaluator(termsOfUseEvaluatorFunc(func(vc *Credential, terms []TermsOfUse) error {
+				evaluated = terms
+
+				return nil
+			})))
+		require.NoError(t, err)
+		require.Equal(t, vc.TermsOfUse(), evaluated)
+	})
+
+	t.Run("not evaluated without terms of use", func(t *testing.T) {
+		vcJSON, err := sjson.Delete(v1ValidCredential, "termsOfUse")
+		require.NoError(t, err)
+
+		_, err = parseTestCredential(t, []byte(vcJSON), WithDisabledProofCheck(),
+			WithTermsOfUseEvaluator(prohibitArchival))
+		require.NoError(t, err)
+	})
+}
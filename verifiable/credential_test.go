@@ -9,6 +9,7 @@ import (
 	"crypto"
 	"crypto/ed25519"
 	"crypto/rand"
+	"encoding/base64"
 	"encoding/hex"
 	"encoding/json"
 	"fmt"
@@ -18,6 +19,7 @@ import (
 	"testing"
 	"time"
 
+	josejwt "github.com/go-jose/go-jose/v3/jwt"
 	"github.com/piprate/json-gold/ld"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -1042,6 +1044,178 @@ func TestCredential_CreateSignedJWTVC(t *testing.T) {
 	})
 }
 
+func TestCredential_AddJWTProof(t *testing.T) {
+	issuerKeyID := "did:example:76e12ec712ebc6f1c221ebfeb1f#key-1"
+	proofCreator, proofChecker := testsupport.NewKMSSigVerPair(t, kms.ED25519Type, issuerKeyID)
+
+	t.Run("Success", func(t *testing.T) {
+		vcJSON := map[string]interface{}{}
+		require.NoError(t, json.Unmarshal([]byte(jwtTestCredential), &vcJSON))
+
+		vcJSON["id"] = "http://example.edu/credentials/1872"
+
+		vcBytes, err := json.Marshal(vcJSON)
+		require.NoError(t, err)
+
+		vc, err := parseTestCredential(t, vcBytes, WithDisabledProofCheck())
+		require.NoError(t, err)
+
+		jwtVC, err := vc.AddJWTProof(proofCreator, issuerKeyID, EdDSA)
+		require.NoError(t, err)
+		require.True(t, vc.IsJWT())
+		require.Equal(t, jwtVC, vc.JWTEnvelope.JWT)
+
+		parts := strings.Split(jwtVC, ".")
+		require.Len(t, parts, 3)
+
+		payload, err := base64.RawURLEncoding.DecodeString(parts[1])
+		require.NoError(t, err)
+
+		var claims map[string]interface{}
+
+		require.NoError(t, json.Unmarshal(payload, &claims))
+		require.Equal(t, "did:example:76e12ec712ebc6f1c221ebfeb1f", claims["iss"])
+		require.Equal(t, "did:example:ebfeb1f712ebc6f1c276e12ec21", claims["sub"])
+		require.Equal(t, "http://example.edu/credentials/1872", claims["jti"])
+		require.EqualValues(t, vc.Contents().Issued.Unix(), claims["nbf"])
+		require.EqualValues(t, vc.Contents().Expired.Unix(), claims["exp"])
+
+		vcFromJWT, err := parseTestCredential(t, []byte(jwtVC), WithProofChecker(proofChecker))
+		require.NoError(t, err)
+		require.True(t, vcFromJWT.IsJWT())
+		require.Equal(t, vc.Contents(), vcFromJWT.Contents())
+	})
+
+	t.Run("Invalid subject", func(t *testing.T) {
+		vcc := vccProto
+		vcc.Subject = nil
+
+		vc, err := CreateCredential(vcc, nil)
+		require.NoError(t, err)
+
+		_, err = vc.AddJWTProof(proofCreator, issuerKeyID, EdDSA)
+		require.ErrorContains(t, err, "no subject is defined")
+		require.False(t, vc.IsJWT())
+	})
+}
+
+func TestCredential_ToLDCredential(t *testing.T) {
+	issuerKeyID := "did:example:76e12ec712ebc6f1c221ebfeb1f#key-1"
+	proofCreator, proofChecker := testsupport.NewKMSSigVerPair(t, kms.ED25519Type, issuerKeyID)
+
+	vcJSON := map[string]interface{}{}
+	require.NoError(t, json.Unmarshal([]byte(jwtTestCredential), &vcJSON))
+
+	vcJSON["id"] = "http://example.edu/credentials/1872"
+
+	vcBytes, err := json.Marshal(vcJSON)
+	require.NoError(t, err)
+
+	ldVC, err := parseTestCredential(t, vcBytes, WithDisabledProofCheck())
+	require.NoError(t, err)
+
+	t.Run("JWT to LD and back", func(t *testing.T) {
+		jwtVC, err := ldVC.CreateSignedJWTVC(true, EdDSA, proofCreator, issuerKeyID)
+		require.NoError(t, err)
+
+		jwtString, err := jwtVC.ToJWTString()
+		require.NoError(t, err)
+
+		vcFromJWT, err := parseTestCredential(t, []byte(jwtString), WithProofChecker(proofChecker))
+		require.NoError(t, err)
+
+		converted, err := vcFromJWT.ToLDCredential()
+		require.NoError(t, err)
+		require.False(t, converted.IsJWT())
+		require.Empty(t, converted.Proofs())
+		require.Equal(t, ldVC.Contents(), converted.Contents())
+
+		// The minimized "vc" claim is completed from jti, iss, nbf and exp.
+		raw := converted.ToRawJSON()
+		require.Equal(t, "http://example.edu/credentials/1872", raw["id"])
+		require.Equal(t, "2010-01-01T19:23:24Z", raw["issuanceDate"])
+		require.Equal(t, "2020-01-01T19:23:24Z", raw["expirationDate"])
+		require.Equal(t, "did:example:76e12ec712ebc6f1c221ebfeb1f", raw["issuer"].(map[string]interface{})["id"])
+
+		_, err = converted.AddJWTProof(proofCreator, issuerKeyID, EdDSA)
+		require.NoError(t, err)
+
+		jwtString, err = converted.ToJWTString()
+		require.NoError(t, err)
+
+		vcFromJWT, err = parseTestCredential(t, []byte(jwtString), WithProofChecker(proofChecker))
+		require.NoError(t, err)
+		require.Equal(t, ldVC.Contents(), vcFromJWT.Contents())
+	})
+
+	t.Run("nbf is the issuance date", func(t *testing.T) {
+		claims, err := ldVC.JWTClaims(true)
+		require.NoError(t, err)
+
+		claims.IssuedAt = josejwt.NewNumericDate(ldVC.Contents().Issued.Add(time.Hour))
+
+		jws, _, err := claims.MarshalJWS(EdDSA, proofCreator, issuerKeyID)
+		require.NoError(t, err)
+
+		vcFromJWT, err := parseTestCredential(t, []byte(jws), WithProofChecker(proofChecker))
+		require.NoError(t, err)
+
+		converted, err := vcFromJWT.ToLDCredential()
+		require.NoError(t, err)
+		require.Equal(t, ldVC.Contents().Issued.Time, converted.Contents().Issued.Time)
+	})
+
+	t.Run("LD credential", func(t *testing.T) {
+		converted, err := ldVC.ToLDCredential()
+		require.NoError(t, err)
+		require.Equal(t, ldVC.Contents(), converted.Contents())
+	})
+}
+
+func TestWithRequireProof(t *testing.T) {
+	issuerKeyID := "did:example:76e12ec712ebc6f1c221ebfeb1f#key-1"
+	proofCreator, proofChecker := testsupport.NewKMSSigVerPair(t, kms.ED25519Type, issuerKeyID)
+
+	t.Run("unsigned credential", func(t *testing.T) {
+		_, err := parseTestCredential(t, []byte(jwtTestCredential), WithDisabledProofCheck(), WithRequireProof())
+		require.EqualError(t, err, "credential has no verifiable proof")
+
+		_, err = parseTestCredential(t, []byte(jwtTestCredential), WithDisabledProofCheck())
+		require.NoError(t, err)
+	})
+
+	t.Run("credential with embedded proof", func(t *testing.T) {
+		vcJSON := map[string]interface{}{}
+		require.NoError(t, json.Unmarshal([]byte(jwtTestCredential), &vcJSON))
+
+		vcJSON["proof"] = map[string]interface{}{
+			"type":               "DataIntegrityProof",
+			"cryptosuite":        "ecdsa-2019",
+			"verificationMethod": issuerKeyID,
+			"proofPurpose":       "assertionMethod",
+			"proofValue":         "z58DAdFfa9SkqZMVPxAQpic7ndSayn1PzZs6ZjWp1CktyGesjuTSwRdoWhAfGFCF5bppETSTojQCrfFPP2oumHKtz",
+		}
+
+		vcBytes, err := json.Marshal(vcJSON)
+		require.NoError(t, err)
+
+		vc, err := parseTestCredential(t, vcBytes, WithDisabledProofCheck(), WithRequireProof())
+		require.NoError(t, err)
+		require.Len(t, vc.Proofs(), 1)
+	})
+
+	t.Run("JWT credential", func(t *testing.T) {
+		vc, err := parseTestCredential(t, []byte(jwtTestCredential), WithDisabledProofCheck())
+		require.NoError(t, err)
+
+		jwtVC, err := vc.AddJWTProof(proofCreator, issuerKeyID, EdDSA)
+		require.NoError(t, err)
+
+		_, err = parseTestCredential(t, []byte(jwtVC), WithProofChecker(proofChecker), WithRequireProof())
+		require.NoError(t, err)
+	})
+}
+
 func TestCreateCredential(t *testing.T) {
 	t.Run("Success", func(t *testing.T) {
 		vcc := vccProto
@@ -1106,6 +1280,103 @@ func TestCreateCredential(t *testing.T) {
 	})
 }
 
+func TestCredential_Subjects(t *testing.T) {
+	secondSubject := Subject{
+		ID:           "did:example:c276e12ec21ebfeb1f712ebc6f1",
+		CustomFields: CustomFields{"name": "Morgan Doe"},
+	}
+
+	subjectsJSON := func(t *testing.T, vc *Credential) interface{} {
+		t.Helper()
+
+		vcBytes, err := vc.MarshalJSON()
+		require.NoError(t, err)
+
+		var raw JSONObject
+
+		require.NoError(t, json.Unmarshal(vcBytes, &raw))
+
+		return raw["credentialSubject"]
+	}
+
+	t.Run("several subjects as an array", func(t *testing.T) {
+		vcc := vccProto
+		vcc.Subject = []Subject{subjectProto, secondSubject}
+
+		vc, err := CreateCredential(vcc, nil)
+		require.NoError(t, err)
+		require.Equal(t, vcc.Subject, vc.Subjects())
+		require.Len(t, subjectsJSON(t, vc), 2)
+
+		vcBytes, err := vc.MarshalJSON()
+		require.NoError(t, err)
+
+		parsed, err := parseTestCredential(t, vcBytes, WithDisabledProofCheck())
+		require.NoError(t, err)
+		require.Len(t, parsed.Subjects(), 2)
+		require.Equal(t, subjectProto.ID, parsed.Subjects()[0].ID)
+		require.Equal(t, secondSubject, parsed.Subjects()[1])
+		require.Equal(t, subjectsJSON(t, vc), subjectsJSON(t, parsed))
+	})
+
+	t.Run("single subject as an object", func(t *testing.T) {
+		vc, err := CreateCredential(vccProto, nil)
+		require.NoError(t, err)
+		require.Len(t, vc.Subjects(), 1)
+		require.IsType(t, map[string]interface{}{}, subjectsJSON(t, vc))
+	})
+
+	t.Run("single subject array is kept on parsing", func(t *testing.T) {
+		vc, err := CreateCredential(vccProto, nil)
+		require.NoError(t, err)
+
+		raw := vc.ToRawJSON()
+		raw["credentialSubject"] = []interface{}{raw["credentialSubject"]}
+
+		vcBytes, err := json.Marshal(raw)
+		require.NoError(t, err)
+
+		parsed, err := parseTestCredential(t, vcBytes, WithDisabledProofCheck())
+		require.NoError(t, err)
+		require.Len(t, parsed.Subjects(), 1)
+		require.Len(t, subjectsJSON(t, parsed), 1)
+	})
+}
+
+func TestCredential_ExtensionPropertiesRoundTrip(t *testing.T) {
+	// The members of JSON objects are marshalled in key order, so are they here to compare the bytes.
+	const (
+		renderMethod = `[{"css3MediaQuery":"@media (orientation: portrait)",` +
+			`"id":"https://example.edu/templates/degree.svg","name":"Portrait","type":"SvgRenderingTemplate"}]`
+		confidenceMethod = `{"id":"did:example:ebfeb1f712ebc6f1c276e12ec21","type":"ExampleConfidenceMethod"}`
+	)
+
+	vcJSON := `{
+		"@context": ["https://www.w3.org/ns/credentials/v2", "https://www.w3.org/ns/credentials/examples/v2"],
+		"id": "http://example.edu/credentials/1872",
+		"type": ["VerifiableCredential", "ExampleDegreeCredential"],
+		"issuer": "did:example:76e12ec712ebc6f1c221ebfeb1f",
+		"validFrom": "2010-01-01T19:23:24Z",
+		"credentialSubject": {"id": "did:example:ebfeb1f712ebc6f1c276e12ec21"},
+		"renderMethod": ` + renderMethod + `,
+		"confidenceMethod": ` + confidenceMethod + `,
+		"exampleUnknownProperty": {"value": 1}
+	}`
+
+	vc, err := parseTestCredential(t, []byte(vcJSON), WithDisabledProofCheck(), WithStrictValidation())
+	require.NoError(t, err)
+
+	vcBytes, err := vc.MarshalJSON()
+	require.NoError(t, err)
+	require.JSONEq(t, vcJSON, string(vcBytes))
+
+	var marshalled map[string]json.RawMessage
+
+	require.NoError(t, json.Unmarshal(vcBytes, &marshalled))
+	require.Equal(t, renderMethod, string(marshalled["renderMethod"]))
+	require.Equal(t, confidenceMethod, string(marshalled["confidenceMethod"]))
+}
+
 func TestCredential_MarshalAndParseJSON(t *testing.T) {
 	const pubKeyID = "did:123#issuer-key"
 
@@ -2642,3 +2913,52 @@ func validateEnvelopedVC(t *testing.T, vcBytes []byte, expectedMediaType MediaTy
 	require.Equal(t, expectedMediaType, mediaType)
 	require.NotEmpty(t, data)
 }
+
+func TestCredential_CanonicalJSON(t *testing.T) {
+	credJSON := `{"@context":["https://www.w3.org/2018/credentials/v1"],"type":"VerifiableCredential",` +
+		`"issuer":"did:example:76e12ec712ebc6f1c221ebfeb1f","issuanceDate":"2010-01-01T19:23:24Z",` +
+		`"credentialSubject":{"id":"did:example:ebfeb1f712ebc6f1c276e12ec21","name":"Jayden Doe","age":1.0},` +
+		`"proof":{"type":"DataIntegrityProof","cryptosuite":"eddsa-rdfc-2022","proofPurpose":"assertionMethod",` +
+		`"verificationMethod":"did:example:76e12ec712ebc6f1c221ebfeb1f#key-1","created":"2023-02-24T23:36:38Z",` +
+		`"proofValue":"z58DAdFfa9SkqZMVPxAQpic7ndSayn1PzZs6ZjWp1CktyGesjuTSwRdoWhAfGFCF5bppETSTojQCrfFPP2oumHKtz"}}`
+
+	reordered := `{"proof":{"proofValue":"z58DAdFfa9SkqZMVPxAQpic7ndSayn1PzZs6ZjWp1CktyGesjuTSwRdoWhAf` +
+		`GFCF5bppETSTojQCrfFPP2oumHKtz","created":"2023-02-24T23:36:38Z",` +
+		`"verificationMethod":"did:example:76e12ec712ebc6f1c221ebfeb1f#key-1","proofPurpose":"assertionMethod",` +
+		`"cryptosuite":"eddsa-rdfc-2022","type":"DataIntegrityProof"},` +
+		`"credentialSubject":{"age":1,"name":"Jayden Doe","id":"did:example:ebfeb1f712ebc6f1c276e12ec21"},` +
+		`"issuanceDate":"2010-01-01T19:23:24Z","issuer":"did:example:76e12ec712ebc6f1c221ebfeb1f",` +
+		`"type":"VerifiableCredential","@context":["https://www.w3.org/2018/credentials/v1"]}`
+
+	vc, err := parseTestCredential(t, []byte(credJSON), WithDisabledProofCheck())
+	require.NoError(t, err)
+
+	canonical, err := vc.CanonicalJSON()
+	require.NoError(t, err)
+
+	require.Equal(t, `{"@context":["https://www.w3.org/2018/credentials/v1"],"credentialSubject":{"age":1,`+
+		`"id":"did:example:ebfeb1f712ebc6f1c276e12ec21","name":"Jayden Doe"},"issuanceDate":"2010-01-01T19:23:24Z",`+
+		`"issuer":"did:example:76e12ec712ebc6f1c221ebfeb1f","proof":{"created":"2023-02-24T23:36:38Z",`+
+		`"cryptosuite":"eddsa-rdfc-2022","proofPurpose":"assertionMethod","proofValue":"z58DAdFfa9SkqZMVPxAQpic7ndS`+
+		`ayn1PzZs6ZjWp1CktyGesjuTSwRdoWhAfGFCF5bppETSTojQCrfFPP2oumHKtz","type":"DataIntegrityProof",`+
+		`"verificationMethod":"did:example:76e12ec712ebc6f1c221ebfeb1f#key-1"},"type":"VerifiableCredential"}`,
+		string(canonical))
+
+	for range 10 {
+		reorderedVC, err := parseTestCredential(t, []byte(reordered), WithDisabledProofCheck())
+		require.NoError(t, err)
+
+		reorderedCanonical, err := reorderedVC.CanonicalJSON()
+		require.NoError(t, err)
+		require.Equal(t, string(canonical), string(reorderedCanonical))
+	}
+
+	// The proof is covered, unlike with the canonicalization of the Data Integrity suites.
+	otherProofVC, err := parseTestCredential(t, []byte(strings.Replace(credJSON, "z58D", "z59D", 1)),
+		WithDisabledProofCheck())
+	require.NoError(t, err)
+
+	otherProofCanonical, err := otherProofVC.CanonicalJSON()
+	require.NoError(t, err)
+	require.NotEqual(t, canonical, otherProofCanonical)
+}
@@ -0,0 +1,60 @@
+/*
+Copyright Gen Digital Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package verifiable
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	util "github.com/trustbloc/did-go/doc/util/time"
+)
+
+var (
+	// ErrCredentialNotYetValid is returned by Credential.CheckValidity when the credential
+	// validity period starts after the checked time.
+	ErrCredentialNotYetValid = errors.New("credential is not yet valid")
+	// ErrCredentialExpired is returned by Credential.CheckValidity when the credential
+	// validity period ends before the checked time.
+	ErrCredentialExpired = errors.New("credential is expired")
+)
+
+// CheckValidity checks that the credential is valid at the given time. The validity period is
+// defined by validFrom and validUntil (VC Data Model 2.0), and by issuanceDate and expirationDate
+// (VC Data Model 1.1) for the bounds without their 2.0 field. A credential without bounds is valid.
+// It returns ErrCredentialNotYetValid or ErrCredentialExpired, wrapped with the bound, if it isn't.
+func (vc *Credential) CheckValidity(at time.Time) error {
+	validFrom, err := validityBound(vc.credentialJSON, jsonFldValidFrom, jsonFldIssued)
+	if err != nil {
+		return err
+	}
+
+	validUntil, err := validityBound(vc.credentialJSON, jsonFldValidUntil, jsonFldExpired)
+	if err != nil {
+		return err
+	}
+
+	if validFrom != nil && at.Before(validFrom.Time) {
+		return fmt.Errorf("%w: valid from %s", ErrCredentialNotYetValid, validFrom.FormatToString())
+	}
+
+	if validUntil != nil && at.After(validUntil.Time) {
+		return fmt.Errorf("%w: valid until %s", ErrCredentialExpired, validUntil.FormatToString())
+	}
+
+	return nil
+}
+
+// validityBound returns the time of field, else of the fallback field.
+func validityBound(raw JSONObject, field, fallback string) (*util.TimeWrapper, error) {
+	bound, err := parseTimeFld(raw, field)
+	if err != nil || bound != nil {
+		return bound, err
+	}
+
+	return parseTimeFld(raw, fallback)
+}
@@ -0,0 +1,67 @@
+/*
+Copyright Gen Digital Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package verifiable
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestCredential_CheckValidity(t *testing.T) {
+	parse := func(t *testing.T, validity string) *Credential {
+		t.Helper()
+
+		vc, err := parseTestCredential(t, []byte(`{
+			"@context": ["https://www.w3.org/ns/credentials/v2"],
+			"type": "VerifiableCredential",
+			"issuer": "did:example:76e12ec712ebc6f1c221ebfeb1f",
+			"credentialSubject": {"id": "did:example:ebfeb1f712ebc6f1c276e12ec21"}`+validity+`
+		}`), WithDisabledProofCheck(), WithCredDisableValidation())
+		require.NoError(t, err)
+
+		return vc
+	}
+
+	before := time.Date(2009, 1, 1, 0, 0, 0, 0, time.UTC)
+	during := time.Date(2015, 1, 1, 0, 0, 0, 0, time.UTC)
+	after := time.Date(2021, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	for name, validity := range map[string]string{
+		"VC 2.0": `, "validFrom": "2010-01-01T19:23:24Z", "validUntil": "2020-01-01T19:23:24Z"`,
+		"VC 1.1": `, "issuanceDate": "2010-01-01T19:23:24Z", "expirationDate": "2020-01-01T19:23:24Z"`,
+		"VC 2.0 fields preferred": `, "validFrom": "2010-01-01T19:23:24Z", "validUntil": "2020-01-01T19:23:24Z",
+			"issuanceDate": "2000-01-01T19:23:24Z", "expirationDate": "2030-01-01T19:23:24Z"`,
+		"VC 1.1 fallback": `, "validFrom": "2010-01-01T19:23:24Z", "expirationDate": "2020-01-01T19:23:24Z"`,
+	} {
+		t.Run(name, func(t *testing.T) {
+			vc := parse(t, validity)
+
+			require.NoError(t, vc.CheckValidity(during))
+
+			err := vc.CheckValidity(before)
+			require.ErrorIs(t, err, ErrCredentialNotYetValid)
+			require.ErrorContains(t, err, "valid from 2010-01-01T19:23:24Z")
+
+			err = vc.CheckValidity(after)
+			require.ErrorIs(t, err, ErrCredentialExpired)
+			require.ErrorContains(t, err, "valid until 2020-01-01T19:23:24Z")
+		})
+	}
+
+	t.Run("no validity period", func(t *testing.T) {
+		require.NoError(t, parse(t, "").CheckValidity(after))
+	})
+
+	t.Run("invalid time", func(t *testing.T) {
+		vc := parse(t, `, "expirationDate": "2020-01-01T19:23:24Z", "validUntil": "tomorrow"`)
+
+		require.ErrorContains(t, vc.CheckValidity(during),
+			`field "validUntil" contains invalid time value`)
+	})
+}
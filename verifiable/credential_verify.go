@@ -0,0 +1,72 @@
+/*
+Copyright Gen Digital Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package verifiable
+
+import (
+	"errors"
+	"runtime"
+	"sync"
+)
+
+// WithVerificationConcurrency sets the maximum number of goroutines VerifyCredentials uses
+// to check credential proofs. It defaults to the number of CPUs.
+func WithVerificationConcurrency(n int) CredentialOpt {
+	return func(opts *credentialOpts) {
+		opts.verificationConcurrency = n
+	}
+}
+
+// VerifyCredentials checks the proofs of the credentials, like Credential.CheckProof, using
+// a pool of goroutines (see WithVerificationConcurrency). It returns the proof check errors
+// aligned by index with vcs: the error for vcs[i] is at index i and is nil if the check passed.
+//
+// The proof checkers, the JSON-LD document loader, the DID resolver and the Data Integrity
+// verifier passed in opts are shared by the goroutines, so they must be safe for concurrent use.
+func VerifyCredentials(vcs []*Credential, opts ...CredentialOpt) []error {
+	errs := make([]error, len(vcs))
+
+	workers := getCredentialOpts(opts).verificationConcurrency
+	if workers <= 0 {
+		workers = runtime.NumCPU()
+	}
+
+	if workers > len(vcs) {
+		workers = len(vcs)
+	}
+
+	indexes := make(chan int)
+
+	var wg sync.WaitGroup
+
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+
+		go func() {
+			defer wg.Done()
+
+			for i := range indexes {
+				if vcs[i] == nil {
+					errs[i] = errors.New("credential is not defined")
+
+					continue
+				}
+
+				// Options are created per check, because proof checks can set defaults on them.
+				errs[i] = vcs[i].checkProof(getCredentialOpts(opts))
+			}
+		}()
+	}
+
+	for i := range vcs {
+		indexes <- i
+	}
+
+	close(indexes)
+	wg.Wait()
+
+	return errs
+}
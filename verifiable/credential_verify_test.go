@@ -0,0 +1,118 @@
+/*
+Copyright Gen Digital Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package verifiable
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"github.com/trustbloc/did-go/doc/did"
+	kmsapi "github.com/trustbloc/kms-go/spi/kms"
+
+	"github.com/trustbloc/vc-go/dataintegrity"
+	"github.com/trustbloc/vc-go/dataintegrity/suite/ecdsa2019"
+	"github.com/trustbloc/vc-go/internal/testutil/kmscryptoutil"
+)
+
+func TestVerifyCredentials(t *testing.T) {
+	kmsCrypto := kmscryptoutil.LocalKMSCrypto(t)
+
+	docLoader := createTestDocumentLoader(t)
+
+	key, err := kmsCrypto.Create(kmsapi.ECDSAP256IEEEP1363)
+	require.NoError(t, err)
+
+	const signingDID = "did:foo:bar"
+
+	const vmID = "#key-1"
+
+	vm, err := did.NewVerificationMethodFromJWK(signingDID+vmID, "JsonWebKey2020", signingDID, key)
+	require.NoError(t, err)
+
+	resolver := resolveFunc(func(id string) (*did.DocResolution, error) {
+		return makeMockDIDResolution(signingDID, vm, did.AssertionMethod), nil
+	})
+
+	signer, err := dataintegrity.NewSigner(&dataintegrity.Options{
+		DIDResolver: resolver,
+	}, ecdsa2019.NewSignerInitializer(&ecdsa2019.SignerInitializerOptions{
+		SignerGetter:     ecdsa2019.WithKMSCryptoWrapper(kmsCrypto),
+		LDDocumentLoader: docLoader,
+	}))
+	require.NoError(t, err)
+
+	verifier, err := dataintegrity.NewVerifier(&dataintegrity.Options{
+		DIDResolver: resolver,
+	}, ecdsa2019.NewVerifierInitializer(&ecdsa2019.VerifierInitializerOptions{
+		LDDocumentLoader: docLoader,
+	}))
+	require.NoError(t, err)
+
+	const numCredentials = 10
+
+	vcs := make([]*Credential, numCredentials)
+
+	for i := range vcs {
+		vc, e := parseTestCredential(t, []byte(dataIntegrityTestCredential), WithDisabledProofCheck())
+		require.NoError(t, e)
+
+		vc.credentialJSON["id"] = fmt.Sprintf("https://example.com/credentials/%d", i)
+
+		e = vc.AddDataIntegrityProof(&DataIntegrityProofContext{
+			SigningKeyID: signingDID + vmID,
+			CryptoSuite:  ecdsa2019.SuiteType,
+		}, signer)
+		require.NoError(t, e)
+
+		vcs[i] = vc
+	}
+
+	t.Run("success", func(t *testing.T) {
+		for _, concurrency := range []int{0, 1, 3, 2 * numCredentials} {
+			errs := VerifyCredentials(vcs, WithDataIntegrityVerifier(verifier),
+				WithJSONLDDocumentLoader(docLoader), WithVerificationConcurrency(concurrency))
+			require.Len(t, errs, numCredentials)
+
+			for _, e := range errs {
+				require.NoError(t, e)
+			}
+		}
+
+		require.Empty(t, VerifyCredentials(nil))
+	})
+
+	t.Run("errors aligned by index", func(t *testing.T) {
+		tamperedVC, e := parseTestCredential(t, []byte(dataIntegrityTestCredential), WithDisabledProofCheck())
+		require.NoError(t, e)
+
+		tamperedVC.credentialJSON = vcs[3].ToRawJSON()
+		tamperedVC.credentialJSON["id"] = "https://example.com/credentials/tampered"
+
+		batch := append([]*Credential{}, vcs...)
+		batch[3] = tamperedVC
+		batch[7] = nil
+
+		errs := VerifyCredentials(batch, WithDataIntegrityVerifier(verifier),
+			WithJSONLDDocumentLoader(docLoader), WithVerificationConcurrency(4))
+		require.Len(t, errs, numCredentials)
+
+		for i, e := range errs {
+			switch i {
+			case 3:
+				var diErr *DataIntegrityError
+
+				require.ErrorAs(t, e, &diErr)
+				require.Equal(t, ErrCodeSignatureInvalid, diErr.Code)
+			case 7:
+				require.ErrorContains(t, e, "credential is not defined")
+			default:
+				require.NoError(t, e)
+			}
+		}
+	})
+}
@@ -0,0 +1,126 @@
+/*
+Copyright Gen Digital Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package verifiable
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/trustbloc/vc-go/dataintegrity"
+)
+
+type signCredentialsOpts struct {
+	concurrency int
+	createdStep time.Duration
+}
+
+// SignCredentialsOpt is an option of SignCredentials.
+type SignCredentialsOpt func(opts *signCredentialsOpts)
+
+// WithSigningConcurrency makes SignCredentials sign up to n credentials at the same time, 1 by default.
+// The document loaders and key signers of the signer suites must then be safe for concurrent use.
+func WithSigningConcurrency(n int) SignCredentialsOpt {
+	return func(opts *signCredentialsOpts) {
+		opts.concurrency = n
+	}
+}
+
+// WithStaggeredCreated makes SignCredentials set the created time of the proof of the i-th credential
+// to the created time of the batch plus i times step, instead of the same created time for all proofs.
+func WithStaggeredCreated(step time.Duration) SignCredentialsOpt {
+	return func(opts *signCredentialsOpts) {
+		opts.createdStep = step
+	}
+}
+
+// SignCredentials adds a Data Integrity proof to each of the credentials, as AddDataIntegrityProof does
+// with the given context, and returns the error of each credential, nil if it is signed. The credentials
+// must be distinct, and context is not modified.
+//
+// The verification method is resolved once for the batch, and the proofs share the created time,
+// context.Created or else the current time of the signer clock (see dataintegrity.Options.WithClock),
+// unless WithStaggeredCreated is used. A nil credential fails with an error. For the JSON-LD
+// contexts of the credentials to be loaded once, create the signer with the context cache of
+// dataintegrity.Options.WithContextCache. The first credential is signed alone, so that the cache is
+// warmed before signing the others concurrently with WithSigningConcurrency.
+func SignCredentials(
+	vcs []*Credential,
+	context *DataIntegrityProofContext,
+	signer *dataintegrity.Signer,
+	opts ...SignCredentialsOpt,
+) []error {
+	batchOpts := &signCredentialsOpts{concurrency: 1}
+
+	for _, opt := range opts {
+		opt(batchOpts)
+	}
+
+	errs := make([]error, len(vcs))
+	if len(vcs) == 0 {
+		return errs
+	}
+
+	batchContext := *context
+	if batchContext.ProofPurpose == "" {
+		batchContext.ProofPurpose = assertionMethod
+	}
+
+	vm, err := signer.ResolveVerificationMethod(batchContext.SigningKeyID, batchContext.ProofPurpose)
+	if err != nil {
+		for i := range errs {
+			errs[i] = fmt.Errorf("add data integrity proof to VC: %w", err)
+		}
+
+		return errs
+	}
+
+	created := signer.Now()
+	if batchContext.Created != nil {
+		created = *batchContext.Created
+	}
+
+	sign := func(i int) {
+		if vcs[i] == nil {
+			errs[i] = errors.New("credential is not defined")
+
+			return
+		}
+
+		vcContext := batchContext
+		vcCreated := created.Add(time.Duration(i) * batchOpts.createdStep)
+		vcContext.Created = &vcCreated
+
+		_, errs[i] = vcs[i].addDataIntegrityProof(&vcContext, signer, vm)
+	}
+
+	sign(0)
+
+	var wg sync.WaitGroup
+
+	sem := make(chan struct{}, max(batchOpts.concurrency, 1))
+
+	for i := 1; i < len(vcs); i++ {
+		sem <- struct{}{}
+
+		wg.Add(1)
+
+		go func() {
+			defer func() {
+				<-sem
+				wg.Done()
+			}()
+
+			sign(i)
+		}()
+	}
+
+	wg.Wait()
+
+	return errs
+}
@@ -0,0 +1,171 @@
+/*
+Copyright Gen Digital Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package verifiable
+
+import (
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"github.com/trustbloc/did-go/doc/did"
+	kmsapi "github.com/trustbloc/kms-go/spi/kms"
+
+	"github.com/trustbloc/vc-go/dataintegrity"
+	"github.com/trustbloc/vc-go/dataintegrity/suite/ecdsa2019"
+	"github.com/trustbloc/vc-go/internal/testutil/kmscryptoutil"
+)
+
+func TestSignCredentials(t *testing.T) {
+	kmsCrypto := kmscryptoutil.LocalKMSCrypto(t)
+	docLoader := createTestDocumentLoader(t)
+
+	key, err := kmsCrypto.Create(kmsapi.ECDSAP256IEEEP1363)
+	require.NoError(t, err)
+
+	const signingDID = "did:foo:bar"
+
+	vm, err := did.NewVerificationMethodFromJWK(signingDID+"#key-1", "JsonWebKey2020", signingDID, key)
+	require.NoError(t, err)
+
+	var resolutions atomic.Int32
+
+	resolver := resolveFunc(func(id string) (*did.DocResolution, error) {
+		resolutions.Add(1)
+
+		return makeMockDIDResolution(signingDID, vm, did.AssertionMethod), nil
+	})
+
+	signer, err := dataintegrity.NewSigner((&dataintegrity.Options{DIDResolver: resolver}).WithContextCache(10),
+		ecdsa2019.NewSignerInitializer(&ecdsa2019.SignerInitializerOptions{
+			SignerGetter:     ecdsa2019.WithKMSCryptoWrapper(kmsCrypto),
+			LDDocumentLoader: docLoader,
+		}))
+	require.NoError(t, err)
+
+	verifier, err := dataintegrity.NewVerifier(&dataintegrity.Options{DIDResolver: resolver},
+		ecdsa2019.NewVerifierInitializer(&ecdsa2019.VerifierInitializerOptions{
+			LDDocumentLoader: docLoader,
+		}))
+	require.NoError(t, err)
+
+	newVCs := func(t *testing.T, n int) []*Credential {
+		t.Helper()
+
+		vcs := make([]*Credential, n)
+
+		for i := range vcs {
+			vc, e := parseTestCredential(t, []byte(dataIntegrityTestCredential), WithDisabledProofCheck())
+			require.NoError(t, e)
+
+			vcs[i] = vc
+		}
+
+		return vcs
+	}
+
+	created := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+
+	t.Run("shared created time", func(t *testing.T) {
+		resolutions.Store(0)
+
+		vcs := newVCs(t, 10)
+		context := &DataIntegrityProofContext{
+			SigningKeyID: signingDID + "#key-1",
+			CryptoSuite:  ecdsa2019.SuiteType,
+			Created:      &created,
+		}
+
+		errs := SignCredentials(vcs, context, signer, WithSigningConcurrency(4))
+		require.Len(t, errs, len(vcs))
+		require.EqualValues(t, 1, resolutions.Load())
+		require.Empty(t, context.ProofPurpose)
+
+		for i, vc := range vcs {
+			require.NoError(t, errs[i])
+			require.Len(t, vc.Proofs(), 1)
+			require.Equal(t, "2024-01-02T03:04:05Z", vc.Proofs()[0]["created"])
+
+			vcBytes, e := vc.MarshalJSON()
+			require.NoError(t, e)
+
+			_, e = parseTestCredential(t, vcBytes, WithDataIntegrityVerifier(verifier))
+			require.NoError(t, e)
+		}
+	})
+
+	t.Run("staggered created time", func(t *testing.T) {
+		vcs := newVCs(t, 3)
+
+		errs := SignCredentials(vcs, &DataIntegrityProofContext{
+			SigningKeyID: signingDID + "#key-1",
+			CryptoSuite:  ecdsa2019.SuiteType,
+			Created:      &created,
+		}, signer, WithStaggeredCreated(time.Second))
+
+		for i, expected := range []string{"2024-01-02T03:04:05Z", "2024-01-02T03:04:06Z", "2024-01-02T03:04:07Z"} {
+			require.NoError(t, errs[i])
+			require.Equal(t, expected, vcs[i].Proofs()[0]["created"])
+		}
+	})
+
+	t.Run("created time of the signer clock", func(t *testing.T) {
+		clockSigner, e := dataintegrity.NewSigner((&dataintegrity.Options{DIDResolver: resolver}).
+			WithClock(func() time.Time { return created }),
+			ecdsa2019.NewSignerInitializer(&ecdsa2019.SignerInitializerOptions{
+				SignerGetter:     ecdsa2019.WithKMSCryptoWrapper(kmsCrypto),
+				LDDocumentLoader: docLoader,
+			}))
+		require.NoError(t, e)
+		require.Equal(t, created, clockSigner.Now())
+
+		vcs := newVCs(t, 2)
+
+		errs := SignCredentials(vcs, &DataIntegrityProofContext{
+			SigningKeyID: signingDID + "#key-1",
+			CryptoSuite:  ecdsa2019.SuiteType,
+		}, clockSigner, WithStaggeredCreated(time.Minute))
+
+		for i, expected := range []string{"2024-01-02T03:04:05Z", "2024-01-02T03:05:05Z"} {
+			require.NoError(t, errs[i])
+			require.Equal(t, expected, vcs[i].Proofs()[0]["created"])
+		}
+	})
+
+	t.Run("nil credential", func(t *testing.T) {
+		vcs := newVCs(t, 3)
+		vcs[1] = nil
+
+		errs := SignCredentials(vcs, &DataIntegrityProofContext{
+			SigningKeyID: signingDID + "#key-1",
+			CryptoSuite:  ecdsa2019.SuiteType,
+		}, signer)
+
+		require.NoError(t, errs[0])
+		require.EqualError(t, errs[1], "credential is not defined")
+		require.NoError(t, errs[2])
+		require.Len(t, vcs[2].Proofs(), 1)
+	})
+
+	t.Run("verification method resolution failure", func(t *testing.T) {
+		vcs := newVCs(t, 2)
+
+		errs := SignCredentials(vcs, &DataIntegrityProofContext{
+			SigningKeyID: signingDID + "#missing",
+			CryptoSuite:  ecdsa2019.SuiteType,
+		}, signer)
+
+		for i, vc := range vcs {
+			require.ErrorIs(t, errs[i], dataintegrity.ErrVMResolution)
+			require.Empty(t, vc.Proofs())
+		}
+	})
+
+	t.Run("no credentials", func(t *testing.T) {
+		require.Empty(t, SignCredentials(nil, &DataIntegrityProofContext{}, signer))
+	})
+}
@@ -0,0 +1,179 @@
+/*
+Copyright Gen Digital Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package verifiable
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/trustbloc/vc-go/dataintegrity"
+)
+
+// ErrInvalidCapability is returned when the capability or capabilityAction of a Data Integrity proof
+// is not allowed for its proof purpose, is not signed over or doesn't match the expected one.
+var ErrInvalidCapability = errors.New("invalid proof capability")
+
+const (
+	jsonFldCapability       = "capability"
+	jsonFldCapabilityAction = "capabilityAction"
+
+	zcapContext = "https://w3id.org/zcap/v1"
+)
+
+// WithExpectedCapability validates that the Data Integrity proofs of the credential with the
+// capabilityInvocation or capabilityDelegation purpose have the given capability and capabilityAction.
+// Empty capability or action will mean they are not checked. The purpose itself is set with
+// WithExpectedDataIntegrityFields.
+func WithExpectedCapability(capability, action string) CredentialOpt {
+	return func(opts *credentialOpts) {
+		opts.verifyDataIntegrity.Capability = capability
+		opts.verifyDataIntegrity.CapabilityAction = action
+	}
+}
+
+// WithPresExpectedCapability validates that the Data Integrity proofs of the presentation with the
+// capabilityInvocation or capabilityDelegation purpose have the given capability and capabilityAction,
+// as with WithExpectedCapability. The proofs of the embedded credentials are not checked against them.
+func WithPresExpectedCapability(capability, action string) PresentationOpt {
+	return func(opts *presentationOpts) {
+		opts.verifyDataIntegrity.Capability = capability
+		opts.verifyDataIntegrity.CapabilityAction = action
+	}
+}
+
+func isCapabilityPurpose(purpose string) bool {
+	return purpose == dataintegrity.CapabilityInvocation || purpose == dataintegrity.CapabilityDelegation
+}
+
+// capabilityProofFields returns the additional proof fields of the context together with its
+// capability and capabilityAction, checking that they are allowed for the proof purpose and
+// that the JSON-LD context of the document defines them, so that they are signed over.
+func capabilityProofFields(context *DataIntegrityProofContext, jsonldDoc JSONObject) (map[string]interface{}, error) {
+	if context.Capability == "" && context.CapabilityAction == "" {
+		return context.AdditionalProofFields, nil
+	}
+
+	err := checkCapabilityFields(jsonldDoc, context.ProofPurpose, context.Capability, context.CapabilityAction)
+	if err != nil {
+		return nil, err
+	}
+
+	fields := make(map[string]interface{}, len(context.AdditionalProofFields)+2)
+
+	for name, value := range context.AdditionalProofFields {
+		fields[name] = value
+	}
+
+	for name, value := range map[string]string{
+		jsonFldCapability:       context.Capability,
+		jsonFldCapabilityAction: context.CapabilityAction,
+	} {
+		if value == "" {
+			continue
+		}
+
+		if _, ok := fields[name]; ok {
+			return nil, fmt.Errorf("additional proof field %q conflicts with the proof capability", name)
+		}
+
+		fields[name] = value
+	}
+
+	return fields, nil
+}
+
+// checkProofCapability checks the capability and capabilityAction of a Data Integrity proof,
+// and that they match the expected ones of opts for a capabilityInvocation or capabilityDelegation proof.
+// They are not checked when only the signature of the proof is, see WithSignatureOnly.
+func checkProofCapability(jsonldDoc, proof map[string]interface{}, opts *verifyDataIntegrityOpts) error {
+	if opts.SignatureOnly != nil {
+		return nil
+	}
+
+	purpose := safeStringValue(proof["proofPurpose"])
+
+	_, hasCapability := proof[jsonFldCapability]
+	_, hasAction := proof[jsonFldCapabilityAction]
+
+	if !hasCapability && !hasAction && !isCapabilityPurpose(purpose) {
+		return nil
+	}
+
+	capability := safeStringValue(proof[jsonFldCapability])
+	action := safeStringValue(proof[jsonFldCapabilityAction])
+
+	if (hasCapability && capability == "") || (hasAction && action == "") {
+		return fmt.Errorf("%w: capability and capabilityAction should be strings", ErrInvalidCapability)
+	}
+
+	err := checkCapabilityFields(jsonldDoc, purpose, capability, action)
+	if err != nil {
+		return err
+	}
+
+	if opts.Capability != "" && capability != opts.Capability {
+		return fmt.Errorf("%w: expected capability %q, got %q", ErrInvalidCapability, opts.Capability, capability)
+	}
+
+	if opts.CapabilityAction != "" && action != opts.CapabilityAction {
+		return fmt.Errorf("%w: expected capability action %q, got %q",
+			ErrInvalidCapability, opts.CapabilityAction, action)
+	}
+
+	return nil
+}
+
+func checkCapabilityFields(jsonldDoc map[string]interface{}, purpose, capability, action string) error {
+	if !isCapabilityPurpose(purpose) {
+		if capability != "" || action != "" {
+			return fmt.Errorf("%w: capability is only allowed with the %s and %s proof purposes, got %q",
+				ErrInvalidCapability, dataintegrity.CapabilityInvocation, dataintegrity.CapabilityDelegation, purpose)
+		}
+
+		return nil
+	}
+
+	if purpose == dataintegrity.CapabilityInvocation && capability == "" {
+		return fmt.Errorf("%w: %s proof purpose requires a capability", ErrInvalidCapability, purpose)
+	}
+
+	if (capability != "" || action != "") && !definesCapabilityTerms(jsonldDoc[jsonFldContext]) {
+		// The RDF canonicalization drops the proof members that the context doesn't define,
+		// so that the capability would not be signed over.
+		return fmt.Errorf("%w: the @context of the document should define capability and capabilityAction,"+
+			" eg with %s", ErrInvalidCapability, zcapContext)
+	}
+
+	return nil
+}
+
+// definesCapabilityTerms tells whether the @context includes the ZCAP or security v2 context,
+// or an embedded context that defines the capability term.
+func definesCapabilityTerms(context interface{}) bool {
+	switch ctx := context.(type) {
+	case string:
+		return ctx == zcapContext || ctx == securityContext
+	case map[string]interface{}:
+		_, ok := ctx[jsonFldCapability]
+
+		return ok
+	case []string:
+		for _, c := range ctx {
+			if definesCapabilityTerms(c) {
+				return true
+			}
+		}
+	case []interface{}:
+		for _, c := range ctx {
+			if definesCapabilityTerms(c) {
+				return true
+			}
+		}
+	}
+
+	return false
+}
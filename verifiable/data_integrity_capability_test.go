@@ -0,0 +1,216 @@
+/*
+Copyright Gen Digital Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package verifiable
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"github.com/tidwall/sjson"
+	"github.com/trustbloc/did-go/doc/did"
+	kmsapi "github.com/trustbloc/kms-go/spi/kms"
+
+	"github.com/trustbloc/vc-go/dataintegrity"
+	"github.com/trustbloc/vc-go/dataintegrity/suite/ecdsa2019"
+	"github.com/trustbloc/vc-go/internal/testutil/kmscryptoutil"
+)
+
+func Test_DataIntegrity_Capability(t *testing.T) {
+	kmsCrypto := kmscryptoutil.LocalKMSCrypto(t)
+
+	docLoader := createTestDocumentLoader(t)
+
+	key, err := kmsCrypto.Create(kmsapi.ECDSAP256IEEEP1363)
+	require.NoError(t, err)
+
+	const (
+		signingDID = "did:foo:bar"
+		vmID       = "#key-1"
+		capability = "urn:zcap:root:https%3A%2F%2Fexample.com%2Fdocuments"
+		action     = "read"
+	)
+
+	vm, err := did.NewVerificationMethodFromJWK(signingDID+vmID, "JsonWebKey2020", signingDID, key)
+	require.NoError(t, err)
+
+	relationship := did.CapabilityInvocation
+
+	resolver := resolveFunc(func(id string) (*did.DocResolution, error) {
+		return makeMockDIDResolution(signingDID, vm, relationship), nil
+	})
+
+	signer, err := dataintegrity.NewSigner(&dataintegrity.Options{DIDResolver: resolver},
+		ecdsa2019.NewSignerInitializer(&ecdsa2019.SignerInitializerOptions{
+			SignerGetter:     ecdsa2019.WithKMSCryptoWrapper(kmsCrypto),
+			LDDocumentLoader: docLoader,
+		}))
+	require.NoError(t, err)
+
+	verifier, err := dataintegrity.NewVerifier(&dataintegrity.Options{DIDResolver: resolver},
+		ecdsa2019.NewVerifierInitializer(&ecdsa2019.VerifierInitializerOptions{
+			LDDocumentLoader: docLoader,
+		}))
+	require.NoError(t, err)
+
+	// The RDF canonicalization signs over the capability members only if the context defines them.
+	credJSON, err := sjson.SetRaw(dataIntegrityTestCredential, "@context.-1",
+		`{"capability":{"@id":"https://w3id.org/security#capability","@type":"@id"},`+
+			`"capabilityAction":"https://w3id.org/security#capabilityAction"}`)
+	require.NoError(t, err)
+
+	signCredential := func(t *testing.T, purpose string) []byte {
+		t.Helper()
+
+		vc, e := parseTestCredential(t, []byte(credJSON), WithDisabledProofCheck())
+		require.NoError(t, e)
+
+		e = vc.AddDataIntegrityProof(&DataIntegrityProofContext{
+			SigningKeyID:     signingDID + vmID,
+			ProofPurpose:     purpose,
+			CryptoSuite:      ecdsa2019.SuiteType,
+			Capability:       capability,
+			CapabilityAction: action,
+		}, signer)
+		require.NoError(t, e)
+
+		proofs := vc.DataIntegrityProofs()
+		require.Len(t, proofs, 1)
+		require.Equal(t, purpose, proofs[0].ProofPurpose)
+		require.Equal(t, capability, proofs[0].Capability)
+		require.Equal(t, action, proofs[0].CapabilityAction)
+		require.Empty(t, proofs[0].AdditionalProofFields)
+
+		vcBytes, e := vc.MarshalJSON()
+		require.NoError(t, e)
+
+		return vcBytes
+	}
+
+	t.Run("capability invocation", func(t *testing.T) {
+		relationship = did.CapabilityInvocation
+
+		vcBytes := signCredential(t, dataintegrity.CapabilityInvocation)
+
+		parseOpts := []CredentialOpt{
+			WithDataIntegrityVerifier(verifier),
+			WithExpectedDataIntegrityFields(dataintegrity.CapabilityInvocation, "", ""),
+		}
+
+		_, err := parseTestCredential(t, vcBytes, append(parseOpts, WithExpectedCapability(capability, action))...)
+		require.NoError(t, err)
+
+		var diErr *DataIntegrityError
+
+		_, err = parseTestCredential(t, vcBytes, append(parseOpts, WithExpectedCapability("urn:zcap:other", ""))...)
+		require.ErrorIs(t, err, ErrInvalidCapability)
+		require.ErrorAs(t, err, &diErr)
+		require.Equal(t, ErrCodeInvalidCapability, diErr.Code)
+
+		_, err = parseTestCredential(t, vcBytes, append(parseOpts, WithExpectedCapability("", "write"))...)
+		require.ErrorIs(t, err, ErrInvalidCapability)
+		require.ErrorContains(t, err, `expected capability action "write", got "read"`)
+
+		// The capability members are signed over.
+		for field, value := range map[string]string{"proof.capability": "urn:zcap:other", "proof.capabilityAction": "write"} {
+			tampered, e := sjson.SetBytes(vcBytes, field, value)
+			require.NoError(t, e)
+
+			_, e = parseTestCredential(t, tampered, parseOpts...)
+			require.ErrorAs(t, e, &diErr, field)
+			require.Equal(t, ErrCodeSignatureInvalid, diErr.Code, field)
+		}
+
+		// The verification method must be authorized for capability invocation.
+		relationship = did.AssertionMethod
+
+		_, err = parseTestCredential(t, vcBytes, parseOpts...)
+		require.ErrorAs(t, err, &diErr)
+		require.Equal(t, ErrCodeMissingVerificationRelationship, diErr.Code)
+	})
+
+	t.Run("capability delegation", func(t *testing.T) {
+		relationship = did.CapabilityDelegation
+
+		vcBytes := signCredential(t, dataintegrity.CapabilityDelegation)
+
+		_, err := parseTestCredential(t, vcBytes, WithDataIntegrityVerifier(verifier),
+			WithExpectedDataIntegrityFields(dataintegrity.CapabilityDelegation, "", ""),
+			WithExpectedCapability(capability, action))
+		require.NoError(t, err)
+
+		_, err = parseTestCredential(t, vcBytes, WithDataIntegrityVerifier(verifier),
+			WithExpectedDataIntegrityFields(dataintegrity.CapabilityInvocation, "", ""))
+		require.Error(t, err)
+	})
+
+	t.Run("failure", func(t *testing.T) {
+		relationship = did.CapabilityInvocation
+
+		vc, err := parseTestCredential(t, []byte(credJSON), WithDisabledProofCheck())
+		require.NoError(t, err)
+
+		err = vc.AddDataIntegrityProof(&DataIntegrityProofContext{
+			SigningKeyID: signingDID + vmID,
+			CryptoSuite:  ecdsa2019.SuiteType,
+			Capability:   capability,
+		}, signer)
+		require.ErrorIs(t, err, ErrInvalidCapability)
+		require.ErrorContains(t, err, `capability is only allowed with the capabilityInvocation and `+
+			`capabilityDelegation proof purposes, got "assertionMethod"`)
+
+		err = vc.AddDataIntegrityProof(&DataIntegrityProofContext{
+			SigningKeyID:     signingDID + vmID,
+			ProofPurpose:     dataintegrity.CapabilityInvocation,
+			CryptoSuite:      ecdsa2019.SuiteType,
+			CapabilityAction: action,
+		}, signer)
+		require.ErrorIs(t, err, ErrInvalidCapability)
+		require.ErrorContains(t, err, "capabilityInvocation proof purpose requires a capability")
+
+		err = vc.AddDataIntegrityProof(&DataIntegrityProofContext{
+			SigningKeyID:          signingDID + vmID,
+			ProofPurpose:          dataintegrity.CapabilityInvocation,
+			CryptoSuite:           ecdsa2019.SuiteType,
+			Capability:            capability,
+			AdditionalProofFields: map[string]interface{}{"capability": "urn:zcap:other"},
+		}, signer)
+		require.ErrorContains(t, err, `additional proof field "capability" conflicts with the proof capability`)
+
+		vcWithoutContext, err := parseTestCredential(t, []byte(dataIntegrityTestCredential), WithDisabledProofCheck())
+		require.NoError(t, err)
+
+		err = vcWithoutContext.AddDataIntegrityProof(&DataIntegrityProofContext{
+			SigningKeyID: signingDID + vmID,
+			ProofPurpose: dataintegrity.CapabilityInvocation,
+			CryptoSuite:  ecdsa2019.SuiteType,
+			Capability:   capability,
+		}, signer)
+		require.ErrorIs(t, err, ErrInvalidCapability)
+		require.ErrorContains(t, err, "the @context of the document should define capability and capabilityAction")
+
+		// A capabilityInvocation proof without capability is rejected on verification.
+		vcBytes := signCredential(t, dataintegrity.CapabilityInvocation)
+
+		withoutCapability, err := sjson.DeleteBytes(vcBytes, "proof.capability")
+		require.NoError(t, err)
+
+		_, err = parseTestCredential(t, withoutCapability, WithDataIntegrityVerifier(verifier),
+			WithExpectedDataIntegrityFields(dataintegrity.CapabilityInvocation, "", ""))
+		require.ErrorIs(t, err, ErrInvalidCapability)
+		require.ErrorContains(t, err, "data integrity proof [0]")
+	})
+}
+
+func TestDefinesCapabilityTerms(t *testing.T) {
+	require.True(t, definesCapabilityTerms(zcapContext))
+	require.True(t, definesCapabilityTerms([]interface{}{V1ContextURI, securityContext}))
+	require.True(t, definesCapabilityTerms([]string{V1ContextURI, zcapContext}))
+	require.True(t, definesCapabilityTerms([]interface{}{V1ContextURI, map[string]interface{}{"capability": "sec:capability"}}))
+	require.False(t, definesCapabilityTerms([]interface{}{V1ContextURI, map[string]interface{}{"name": "schema:name"}}))
+	require.False(t, definesCapabilityTerms(V1ContextURI))
+	require.False(t, definesCapabilityTerms(nil))
+}
@@ -0,0 +1,112 @@
+/*
+Copyright Gen Digital Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package verifiable
+
+import (
+	"context"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"github.com/trustbloc/did-go/doc/did"
+	kmsapi "github.com/trustbloc/kms-go/spi/kms"
+
+	"github.com/trustbloc/vc-go/dataintegrity"
+	"github.com/trustbloc/vc-go/dataintegrity/suite/ecdsa2019"
+	"github.com/trustbloc/vc-go/internal/testutil/kmscryptoutil"
+)
+
+func Test_DataIntegrity_VerificationContext(t *testing.T) {
+	kmsCrypto := kmscryptoutil.LocalKMSCrypto(t)
+
+	docLoader := createTestDocumentLoader(t)
+
+	key, err := kmsCrypto.Create(kmsapi.ECDSAP256IEEEP1363)
+	require.NoError(t, err)
+
+	const (
+		signingDID = "did:foo:bar"
+		vmID       = "#key-1"
+	)
+
+	vm, err := did.NewVerificationMethodFromJWK(signingDID+vmID, "JsonWebKey2020", signingDID, key)
+	require.NoError(t, err)
+
+	unblock := make(chan struct{})
+	defer close(unblock)
+
+	var slow atomic.Bool
+
+	resolver := resolveFunc(func(id string) (*did.DocResolution, error) {
+		if slow.Load() {
+			<-unblock
+		}
+
+		return makeMockDIDResolution(signingDID, vm, did.AssertionMethod), nil
+	})
+
+	signer, err := dataintegrity.NewSigner(&dataintegrity.Options{DIDResolver: resolver},
+		ecdsa2019.NewSignerInitializer(&ecdsa2019.SignerInitializerOptions{
+			SignerGetter:     ecdsa2019.WithKMSCryptoWrapper(kmsCrypto),
+			LDDocumentLoader: docLoader,
+		}))
+	require.NoError(t, err)
+
+	verifier, err := dataintegrity.NewVerifier(&dataintegrity.Options{DIDResolver: resolver},
+		ecdsa2019.NewVerifierInitializer(&ecdsa2019.VerifierInitializerOptions{
+			LDDocumentLoader: docLoader,
+		}))
+	require.NoError(t, err)
+
+	vc, err := parseTestCredential(t, []byte(dataIntegrityTestCredential), WithDisabledProofCheck())
+	require.NoError(t, err)
+
+	err = vc.AddDataIntegrityProof(&DataIntegrityProofContext{
+		SigningKeyID: signingDID + vmID,
+		CryptoSuite:  ecdsa2019.SuiteType,
+	}, signer)
+	require.NoError(t, err)
+
+	vcBytes, err := vc.MarshalJSON()
+	require.NoError(t, err)
+
+	t.Run("success", func(t *testing.T) {
+		ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+		defer cancel()
+
+		_, err := parseTestCredential(t, vcBytes, WithDataIntegrityVerifier(verifier), WithVerificationContext(ctx))
+		require.NoError(t, err)
+	})
+
+	t.Run("slow DID resolution", func(t *testing.T) {
+		slow.Store(true)
+		defer slow.Store(false)
+
+		ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+		defer cancel()
+
+		_, err := parseTestCredential(t, vcBytes, WithDataIntegrityVerifier(verifier), WithVerificationContext(ctx))
+		require.ErrorIs(t, err, context.DeadlineExceeded)
+
+		var diErr *DataIntegrityError
+
+		require.ErrorAs(t, err, &diErr)
+		require.Equal(t, ErrCodeVerificationMethod, diErr.Code)
+	})
+
+	t.Run("presentation option", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+
+		vpOpts := &presentationOpts{verifyDataIntegrity: &verifyDataIntegrityOpts{}}
+		WithPresVerificationContext(ctx)(vpOpts)
+
+		// The embedded credentials are verified with the context of the presentation.
+		require.Equal(t, ctx, embeddedCredentialCheckOpts(vpOpts).verifyDataIntegrity.Context)
+	})
+}
@@ -7,78 +7,374 @@ SPDX-License-Identifier: Apache-2.0
 package verifiable
 
 import (
+	"context"
+	"crypto/rand"
+	"encoding/base64"
 	"encoding/json"
 	"errors"
 	"fmt"
+	"io"
+	"strings"
 	"time"
 
+	"github.com/trustbloc/did-go/doc/did"
+	"golang.org/x/exp/slices"
+
 	"github.com/trustbloc/vc-go/dataintegrity"
 	"github.com/trustbloc/vc-go/dataintegrity/models"
+	"github.com/trustbloc/vc-go/dataintegrity/suite"
+	jsonutil "github.com/trustbloc/vc-go/util/json"
+	"github.com/trustbloc/vc-go/vermethod"
 )
 
 // DataIntegrityProofContext holds parameters for creating or validating a Data Integrity Proof.
 type DataIntegrityProofContext struct {
-	SigningKeyID string     // eg did:foo:bar#key-1
-	ProofPurpose string     // assertionMethod
-	CryptoSuite  string     // ecdsa-2019
-	Created      *time.Time //
-	Expires      *time.Time //
-	Domain       string     //
-	Challenge    string     //
+	SigningKeyID string // eg did:foo:bar#key-1
+	// PublicVerificationMethodID is the verificationMethod of the proof, eg a stable public alias
+	// of the signing key, if it differs from SigningKeyID. SigningKeyID then only selects the
+	// signing key, resolved with the signer DID resolver, and is not published in the proof.
+	PublicVerificationMethodID string
+	ProofPurpose               string     // assertionMethod, or authentication for a Presentation (default)
+	CryptoSuite                string     // ecdsa-2019
+	Created                    *time.Time //
+	Expires                    *time.Time //
+	Domain                     string     //
+	Challenge                  string     //
+	Nonce                      string     //
+	// MandatoryPointers are the JSON pointers to the always disclosed statements
+	// of a selective disclosure suite, eg ["/issuer"] for ecdsa-sd-2023.
+	MandatoryPointers []string
+	// ProofID is the id of the proof, eg "urn:uuid:...", so that a later proof can be chained to it.
+	ProofID string
+	// PreviousProof is the ProofID of the proof that the proof is chained to, for sequential signing.
+	// The new proof signs over the document together with that proof (a proof chain), while other
+	// proofs of the proof set are not signed over, as they are independent signatures of the document.
+	PreviousProof string
+	// ProofValueEncoding is the multibase encoding of the proofValue, base58-btc by default, eg
+	// models.ProofValueBase64URL. The suites with a fixed encoding, like ecdsa-sd-2023, ignore it.
+	// The encoding is detected from the multibase prefix on verification.
+	ProofValueEncoding models.ProofValueEncoding
+	// EmbedVerificationMethod embeds the public key of the signing verification method in the proof,
+	// see WithAllowEmbeddedVerificationMethod.
+	EmbedVerificationMethod bool
+	// AdditionalProofFields are extra members of the proof, eg of a profile, that are signed over with
+	// the proof options. The standard proof members and embeddedVerificationMethod can't be set.
+	// With the RDF canonicalization suites, only the members defined by the JSON-LD context of
+	// the document are signed over, other members are dropped by the canonicalization.
+	AdditionalProofFields map[string]interface{}
+	// Capability is the authorization capability, eg a ZCAP id, that a proof with the
+	// capabilityInvocation purpose invokes, or that a capabilityDelegation proof delegates.
+	// It is signed over as the "capability" member of the proof, and the @context of the document
+	// must define it, with the https://w3id.org/zcap/v1 context or an embedded context defining the
+	// capability and capabilityAction terms.
+	Capability string
+	// CapabilityAction is the action of the invoked capability, signed over as "capabilityAction".
+	CapabilityAction string
+}
+
+// Validate checks that the context can be used to create a Data Integrity Proof with signer:
+// SigningKeyID must be a DID URL with a fragment (or PublicVerificationMethodID, if it is set,
+// and SigningKeyID only needs a fragment), CryptoSuite must be a suite of signer, ProofPurpose
+// (if set) must be a known purpose, Expires (if set) must be after Created and ProofValueEncoding
+// (if set) must be a supported encoding.
+func (context *DataIntegrityProofContext) Validate(signer *dataintegrity.Signer) error {
+	if signer == nil {
+		return errors.New("data integrity signer is required")
+	}
+
+	if context.PublicVerificationMethodID != "" {
+		if !isDIDURLWithFragment(context.PublicVerificationMethodID) {
+			return fmt.Errorf("public verification method ID %q should be a DID URL with a fragment",
+				context.PublicVerificationMethodID)
+		}
+
+		if _, fragment, found := strings.Cut(context.SigningKeyID, "#"); !found || fragment == "" {
+			return fmt.Errorf("signing key ID %q should have a fragment", context.SigningKeyID)
+		}
+	} else if !isDIDURLWithFragment(context.SigningKeyID) {
+		return fmt.Errorf("signing key ID %q should be a DID URL with a fragment", context.SigningKeyID)
+	}
+
+	if !signer.SupportsSuite(context.CryptoSuite) {
+		return fmt.Errorf("crypto suite %q is not supported by the signer, supported suites: [%s]",
+			context.CryptoSuite, strings.Join(signer.Suites(), ", "))
+	}
+
+	if context.ProofPurpose != "" && !slices.Contains(dataintegrity.SupportedPurposes(), context.ProofPurpose) {
+		return fmt.Errorf("proof purpose %q is not supported, supported purposes: [%s]",
+			context.ProofPurpose, strings.Join(dataintegrity.SupportedPurposes(), ", "))
+	}
+
+	if context.Expires != nil {
+		created := time.Now()
+		if context.Created != nil {
+			created = *context.Created
+		}
+
+		if !context.Expires.After(created) {
+			return errors.New("proof expires time should be after created time")
+		}
+	}
+
+	if _, err := context.ProofValueEncoding.Encoding(); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func isDIDURLWithFragment(id string) bool {
+	didID, fragment, found := strings.Cut(id, "#")
+
+	return found && strings.HasPrefix(didID, "did:") && fragment != ""
 }
 
 // AddDataIntegrityProof adds a Data Integrity Proof to the Credential.
+// It fails with dataintegrity.ErrUnsupportedPurpose, before signing, if context.ProofPurpose
+// is set to a purpose that the crypto suite does not support.
 func (vc *Credential) AddDataIntegrityProof(context *DataIntegrityProofContext, signer *dataintegrity.Signer) error {
-	vcBytes, err := vc.MarshalJSON()
-	if err != nil {
-		return fmt.Errorf("add data integrity proof to VC: %w", err)
-	}
+	_, err := vc.AddDataIntegrityProofReturning(context, signer)
+
+	return err
+}
 
-	// TODO: rewrite to use json object instead bytes presentation
-	proofs, err := addDataIntegrityProof(context, vcBytes, signer)
+// AddDataIntegrityProofReturning adds a Data Integrity Proof to the Credential, like AddDataIntegrityProof,
+// and returns a copy of the added proof, with the proofValue and the created time set by the signer.
+func (vc *Credential) AddDataIntegrityProofReturning(
+	context *DataIntegrityProofContext,
+	signer *dataintegrity.Signer,
+) (Proof, error) {
+	return vc.addDataIntegrityProof(context, signer, nil)
+}
+
+func (vc *Credential) addDataIntegrityProof(
+	context *DataIntegrityProofContext,
+	signer *dataintegrity.Signer,
+	resolvedVM *models.VerificationMethod,
+) (Proof, error) {
+	proofs, err := addDataIntegrityProof(context, vc.credentialJSON, signer, assertionMethod, resolvedVM)
 	if err != nil {
-		return err
+		return nil, fmt.Errorf("add data integrity proof to VC: %w", err)
 	}
 
-	vc.ldProofs = proofs
+	vc.ldProofs = append(vc.ldProofs, proofs...)
 
 	if len(vc.ldProofs) > 0 {
 		vc.credentialJSON[jsonFldLDProof] = proofsToRaw(vc.ldProofs)
 	}
 
-	return nil
+	return jsonutil.ShallowCopyObj(proofs[0]), nil
+}
+
+// RemoveDataIntegrityProof removes the Data Integrity proofs of the Credential created with the
+// given verification method, e.g. to re-sign the Credential with AddDataIntegrityProof after
+// the issuer rotated its keys. It returns whether any proof was removed.
+func (vc *Credential) RemoveDataIntegrityProof(verificationMethodID string) bool {
+	proofs := make([]Proof, 0, len(vc.ldProofs))
+
+	for _, p := range vc.ldProofs {
+		if p["type"] == models.DataIntegrityProof && p["verificationMethod"] == verificationMethodID {
+			continue
+		}
+
+		proofs = append(proofs, p)
+	}
+
+	if len(proofs) == len(vc.ldProofs) {
+		return false
+	}
+
+	if len(proofs) == 0 {
+		vc.ldProofs = nil
+
+		delete(vc.credentialJSON, jsonFldLDProof)
+
+		return true
+	}
+
+	vc.ldProofs = proofs
+	vc.credentialJSON[jsonFldLDProof] = proofsToRaw(proofs)
+
+	return true
+}
+
+// challengeSize is the size in bytes of the challenges generated with WithAutoChallenge.
+const challengeSize = 16
+
+type presDataIntegrityProofOpts struct {
+	autoChallenge   bool
+	challenge       *string
+	challengeReader io.Reader
+}
+
+// PresDataIntegrityProofOpt is an option of Presentation.AddDataIntegrityProof.
+type PresDataIntegrityProofOpt func(opts *presDataIntegrityProofOpts)
+
+// WithAutoChallenge makes Presentation.AddDataIntegrityProof generate a random 128-bit challenge,
+// encoded as base64url, if context.Challenge is empty. The challenge of the proof, generated or not,
+// is set to challenge, if not nil, so that the caller can correlate the presentation. The context is
+// not modified: a new challenge is generated each time it is used.
+func WithAutoChallenge(challenge *string) PresDataIntegrityProofOpt {
+	return func(opts *presDataIntegrityProofOpts) {
+		opts.autoChallenge = true
+		opts.challenge = challenge
+	}
+}
+
+// WithChallengeReader sets the source of randomness of WithAutoChallenge. Default is crypto/rand.Reader.
+func WithChallengeReader(r io.Reader) PresDataIntegrityProofOpt {
+	return func(opts *presDataIntegrityProofOpts) {
+		opts.challengeReader = r
+	}
 }
 
 // AddDataIntegrityProof adds a Data Integrity Proof to the Presentation.
-func (vp *Presentation) AddDataIntegrityProof(context *DataIntegrityProofContext, signer *dataintegrity.Signer) error {
-	vpBytes, err := vp.MarshalJSON()
+// The proof purpose defaults to authentication, which requires context.Challenge and context.Domain
+// to be set, as the proof answers a verifier's challenge (eg in OIDC4VP or DIDComm presentation flows).
+// With WithAutoChallenge, an empty context.Challenge is generated.
+func (vp *Presentation) AddDataIntegrityProof(
+	context *DataIntegrityProofContext,
+	signer *dataintegrity.Signer,
+	opts ...PresDataIntegrityProofOpt,
+) error {
+	proofOpts := &presDataIntegrityProofOpts{challengeReader: rand.Reader}
+
+	for _, opt := range opts {
+		opt(proofOpts)
+	}
+
+	if proofOpts.autoChallenge {
+		// The challenge is generated on a copy, so that a context used again gets a new one.
+		proofContext := *context
+		context = &proofContext
+
+		if context.Challenge == "" {
+			challenge := make([]byte, challengeSize)
+
+			if _, err := io.ReadFull(proofOpts.challengeReader, challenge); err != nil {
+				return fmt.Errorf("add data integrity proof to VP: generate challenge: %w", err)
+			}
+
+			context.Challenge = base64.RawURLEncoding.EncodeToString(challenge)
+		}
+
+		if proofOpts.challenge != nil {
+			*proofOpts.challenge = context.Challenge
+		}
+	}
+
+	raw, err := vp.raw()
 	if err != nil {
 		return fmt.Errorf("add data integrity proof to VP: %w", err)
 	}
 
-	proofs, err := addDataIntegrityProof(context, vpBytes, signer)
+	proofs, err := addDataIntegrityProof(context, raw, signer, authentication, nil)
 	if err != nil {
-		return err
+		return fmt.Errorf("add data integrity proof to VP: %w", err)
 	}
 
-	vp.Proofs = proofs
+	vp.Proofs = append(vp.Proofs, proofs...)
 
 	return nil
 }
 
+// DataIntegrityProofs returns the parameters of the Data Integrity proofs of the Credential.
+func (vc *Credential) DataIntegrityProofs() []DataIntegrityProofContext {
+	return dataIntegrityProofContexts(vc.ldProofs)
+}
+
+// DataIntegrityProofs returns the parameters of the Data Integrity proofs of the Presentation.
+func (vp *Presentation) DataIntegrityProofs() []DataIntegrityProofContext {
+	return dataIntegrityProofContexts(vp.Proofs)
+}
+
+// dataIntegrityProofContexts reconstructs the contexts of the Data Integrity proofs, other proof
+// types are skipped. Created and Expires keep the time zone offset of the proof timestamps.
+func dataIntegrityProofContexts(proofs []Proof) []DataIntegrityProofContext {
+	var contexts []DataIntegrityProofContext
+
+	for _, p := range proofs {
+		if p["type"] != models.DataIntegrityProof {
+			continue
+		}
+
+		contexts = append(contexts, DataIntegrityProofContext{
+			SigningKeyID:  safeStringValue(p["verificationMethod"]),
+			ProofPurpose:  safeStringValue(p["proofPurpose"]),
+			CryptoSuite:   safeStringValue(p["cryptosuite"]),
+			Created:       proofTime(p["created"]),
+			Expires:       proofTime(p["expires"]),
+			Domain:        safeStringValue(p["domain"]),
+			Challenge:     safeStringValue(p["challenge"]),
+			Nonce:         safeStringValue(p["nonce"]),
+			ProofID:       safeStringValue(p["id"]),
+			PreviousProof: safeStringValue(p["previousProof"]),
+
+			Capability:       safeStringValue(p[jsonFldCapability]),
+			CapabilityAction: safeStringValue(p[jsonFldCapabilityAction]),
+
+			AdditionalProofFields: additionalProofFields(p),
+		})
+	}
+
+	return contexts
+}
+
+// additionalProofFields returns the members of the proof that are not standard proof members,
+// nor the capability members.
+func additionalProofFields(proof Proof) map[string]interface{} {
+	var fields map[string]interface{}
+
+	for name, value := range proof {
+		if models.IsReservedProofField(name) || name == jsonFldEmbeddedVerificationMethod ||
+			name == jsonFldCapability || name == jsonFldCapabilityAction {
+			continue
+		}
+
+		if fields == nil {
+			fields = make(map[string]interface{})
+		}
+
+		fields[name] = value
+	}
+
+	return fields
+}
+
+func proofTime(value interface{}) *time.Time {
+	str, ok := value.(string)
+	if !ok || str == "" {
+		return nil
+	}
+
+	t, err := time.Parse(time.RFC3339Nano, str)
+	if err != nil {
+		return nil
+	}
+
+	return &t
+}
+
 const (
 	assertionMethod = "assertionMethod"
+	authentication  = "authentication"
 )
 
+// addDataIntegrityProof creates a new Data Integrity proof for the JSON-LD document (VC, VP or DID doc).
+// Proofs already present in the document are not signed over, so the new proof extends the proof set,
+// except for the proof that the new proof is chained to with context.PreviousProof.
+// It returns only the newly created proof. The context.ProofPurpose is set to defaultPurpose if empty.
+// The verification method is resolved with the signer, unless resolvedVM is given.
 func addDataIntegrityProof(
 	context *DataIntegrityProofContext,
-	ldBytes []byte,
+	jsonldDoc JSONObject,
 	signer *dataintegrity.Signer,
+	defaultPurpose string,
+	resolvedVM *models.VerificationMethod,
 ) ([]Proof, error) {
+	// The signer sets the created time from its clock if it is not set.
 	var createdTime, expiresTime time.Time
-	if context.Created == nil {
-		createdTime = time.Now()
-	} else {
+	if context.Created != nil {
 		createdTime = *context.Created
 	}
 
@@ -87,60 +383,596 @@ func addDataIntegrityProof(
 	}
 
 	if context.ProofPurpose == "" {
-		context.ProofPurpose = assertionMethod
+		context.ProofPurpose = defaultPurpose
+	}
+
+	if context.ProofPurpose == authentication && (context.Challenge == "" || context.Domain == "") {
+		return nil, errors.New("authentication proof purpose requires challenge and domain")
+	}
+
+	if _, ok := context.AdditionalProofFields[jsonFldEmbeddedVerificationMethod]; ok {
+		return nil, fmt.Errorf("additional proof field %q is reserved", jsonFldEmbeddedVerificationMethod)
 	}
 
-	signed, err := signer.AddProof(ldBytes, &models.ProofOptions{
+	customFields, err := capabilityProofFields(context, jsonldDoc)
+	if err != nil {
+		return nil, err
+	}
+
+	unsecuredDoc := jsonutil.CopyExcept(jsonldDoc, jsonFldLDProof)
+	if context.PreviousProof != "" {
+		// The signer picks the previous proof out of the proof set.
+		unsecuredDoc = jsonldDoc
+	}
+
+	ldBytes, err := json.Marshal(unsecuredDoc)
+	if err != nil {
+		return nil, err
+	}
+
+	// The verification method is resolved once, to sign with the key that is embedded.
+	vm := resolvedVM
+
+	if (context.EmbedVerificationMethod || context.PublicVerificationMethodID != "") && vm == nil {
+		vm, err = signer.ResolveVerificationMethod(context.SigningKeyID, context.ProofPurpose)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	vmID := context.SigningKeyID
+
+	if context.PublicVerificationMethodID != "" {
+		vmID = context.PublicVerificationMethodID
+		vm = publicVerificationMethod(vm, vmID)
+	}
+
+	diProof, err := signer.CreateProof(ldBytes, &models.ProofOptions{
 		Purpose:              context.ProofPurpose,
-		VerificationMethodID: context.SigningKeyID,
+		VerificationMethodID: vmID,
+		VerificationMethod:   vm,
 		ProofType:            models.DataIntegrityProof,
 		SuiteType:            context.CryptoSuite,
 		Domain:               context.Domain,
 		Challenge:            context.Challenge,
+		Nonce:                context.Nonce,
 		Created:              createdTime,
 		Expires:              expiresTime,
+		MandatoryPointers:    context.MandatoryPointers,
+		ProofID:              context.ProofID,
+		PreviousProof:        context.PreviousProof,
+		ProofValueEncoding:   context.ProofValueEncoding,
+		CustomFields:         customFields,
 	})
 	if err != nil {
 		return nil, err
 	}
 
-	// Get a proof from json-ld document.
-	var rProof rawProof
+	proof, err := proofFromModel(diProof)
+	if err != nil {
+		return nil, err
+	}
+
+	if context.EmbedVerificationMethod {
+		proof[jsonFldEmbeddedVerificationMethod] = embeddedVerificationMethodToRaw(vm)
+	}
+
+	return []Proof{proof}, nil
+}
+
+// publicVerificationMethod returns a copy of the signing verification method published with the
+// given id, and the DID of the id as controller, so that the internal key id is not disclosed.
+func publicVerificationMethod(vm *models.VerificationMethod, id string) *models.VerificationMethod {
+	public := *vm
+	public.ID = id
+	public.Controller, _, _ = strings.Cut(id, "#")
+
+	return &public
+}
 
-	err = json.Unmarshal(signed, &rProof)
+// DeriveDataIntegrityProof derives a selective disclosure Data Integrity proof from the base proof
+// of the Credential. The returned Credential contains only the statements selected by the mandatory
+// pointers of the base proof and by revealedPaths, which are JSON pointers (eg "/credentialSubject/name").
+func (vc *Credential) DeriveDataIntegrityProof(revealedPaths []string, deriverInitializer suite.DeriverInitializer,
+	opts ...CredentialOpt) (*Credential, error) {
+	if deriverInitializer == nil {
+		return nil, errors.New("data integrity proof deriver not defined")
+	}
+
+	baseProof, err := findDataIntegrityProof(vc.ldProofs, deriverInitializer.Type())
+	if err != nil {
+		return nil, err
+	}
+
+	deriver, err := deriverInitializer.Deriver()
 	if err != nil {
 		return nil, err
 	}
 
-	proofs, err := parseLDProof(rProof.Proof)
+	vcBytes, err := json.Marshal(jsonutil.CopyExcept(vc.credentialJSON, jsonFldLDProof))
 	if err != nil {
 		return nil, err
 	}
 
-	return proofs, nil
+	revealedBytes, derivedProof, err := deriver.DeriveProof(vcBytes, baseProof, revealedPaths)
+	if err != nil {
+		return nil, fmt.Errorf("derive data integrity proof: %w", err)
+	}
+
+	revealedDoc, err := jsonutil.ToMap(revealedBytes)
+	if err != nil {
+		return nil, err
+	}
+
+	proof, err := proofFromModel(derivedProof)
+	if err != nil {
+		return nil, err
+	}
+
+	revealedDoc[jsonFldLDProof] = proof
+
+	revealedVCBytes, err := json.Marshal(revealedDoc)
+	if err != nil {
+		return nil, err
+	}
+
+	opts = append(opts, WithDisabledProofCheck())
+
+	return ParseCredential(revealedVCBytes, opts...)
+}
+
+// findDataIntegrityProof returns the first Data Integrity proof created with one of the suite types.
+func findDataIntegrityProof(proofs []Proof, suiteTypes []string) (*models.Proof, error) {
+	for _, p := range proofs {
+		if p["type"] != models.DataIntegrityProof {
+			continue
+		}
+
+		cryptoSuite, ok := p["cryptosuite"].(string)
+		if !ok || !slices.Contains(suiteTypes, cryptoSuite) {
+			continue
+		}
+
+		proofBytes, err := json.Marshal(p)
+		if err != nil {
+			return nil, err
+		}
+
+		diProof := &models.Proof{}
+
+		err = json.Unmarshal(proofBytes, diProof)
+		if err != nil {
+			return nil, err
+		}
+
+		return diProof, nil
+	}
+
+	return nil, fmt.Errorf("no data integrity proof with crypto suite [%s]", strings.Join(suiteTypes, ", "))
+}
+
+func proofFromModel(diProof *models.Proof) (Proof, error) {
+	proofBytes, err := json.Marshal(diProof)
+	if err != nil {
+		return nil, err
+	}
+
+	var proof Proof
+
+	err = json.Unmarshal(proofBytes, &proof)
+	if err != nil {
+		return nil, err
+	}
+
+	return proof, nil
 }
 
+// DataIntegrityErrorCode classifies the reason a Data Integrity proof check failed.
+type DataIntegrityErrorCode int
+
+const (
+	// ErrCodeUnknown is used when the failure doesn't fall into any known class.
+	ErrCodeUnknown DataIntegrityErrorCode = iota
+	// ErrCodeMissingVerifier is used when no data integrity verifier was provided.
+	ErrCodeMissingVerifier
+	// ErrCodeMissingProof is used when the document has no proof.
+	ErrCodeMissingProof
+	// ErrCodeMalformedProof is used when the proof is malformed or isn't a Data Integrity proof.
+	ErrCodeMalformedProof
+	// ErrCodeUnknownSuite is used when the proof requires an unsupported cryptographic suite.
+	ErrCodeUnknownSuite
+	// ErrCodePurposeMismatch is used when the proof purpose doesn't match the expected one.
+	ErrCodePurposeMismatch
+	// ErrCodeExpired is used when the proof is expired.
+	ErrCodeExpired
+	// ErrCodeDomainMismatch is used when the proof domain doesn't match the expected one.
+	ErrCodeDomainMismatch
+	// ErrCodeChallengeMismatch is used when the proof challenge doesn't match the expected one.
+	ErrCodeChallengeMismatch
+	// ErrCodeVerificationMethod is used when the verification method can't be resolved.
+	ErrCodeVerificationMethod
+	// ErrCodeSignatureInvalid is used when the proof value doesn't verify.
+	ErrCodeSignatureInvalid
+	// ErrCodeCreatedInFuture is used when the proof created time is in the future.
+	ErrCodeCreatedInFuture
+	// ErrCodeInvalidProofChain is used when the previous proof of a chained proof is not found, or when
+	// the proofs are not of the expected proof structure.
+	ErrCodeInvalidProofChain
+	// ErrCodeMissingVerificationRelationship is used when the verification method is not authorized
+	// for the proof purpose by the DID document of its controller.
+	ErrCodeMissingVerificationRelationship
+	// ErrCodeInvalidCapability is used when the capability of the proof is not valid or doesn't
+	// match the expected one.
+	ErrCodeInvalidCapability
+	// ErrCodeIncompatibleKeyType is used when the verification method key is not of a key type of
+	// the cryptographic suite of the proof.
+	ErrCodeIncompatibleKeyType
+	// ErrCodeNonceMismatch is used when the proof nonce doesn't match the expected one.
+	ErrCodeNonceMismatch
+	// ErrCodeAlgorithmMismatch is used when the alg declared by the verification method JWK is not
+	// the signature algorithm of the cryptographic suite of the proof with the key.
+	ErrCodeAlgorithmMismatch
+)
+
+// DataIntegrityError is returned when a Data Integrity proof check fails.
+type DataIntegrityError struct {
+	Code DataIntegrityErrorCode
+	Err  error
+}
+
+// Error returns the message of the underlying error.
+func (e *DataIntegrityError) Error() string {
+	return e.Err.Error()
+}
+
+// Unwrap returns the underlying error.
+func (e *DataIntegrityError) Unwrap() error {
+	return e.Err
+}
+
+func errMissingDataIntegrityVerifier() error {
+	return &DataIntegrityError{
+		Code: ErrCodeMissingVerifier,
+		Err:  errors.New("data integrity proof needs data integrity verifier"),
+	}
+}
+
+func newDataIntegrityError(err error) *DataIntegrityError {
+	return &DataIntegrityError{
+		Code: dataIntegrityErrorCode(err),
+		Err:  err,
+	}
+}
+
+func dataIntegrityErrorCode(err error) DataIntegrityErrorCode {
+	switch {
+	case errors.Is(err, dataintegrity.ErrMissingProof):
+		return ErrCodeMissingProof
+	case errors.Is(err, dataintegrity.ErrMalformedProof), errors.Is(err, dataintegrity.ErrWrongProofType):
+		return ErrCodeMalformedProof
+	case errors.Is(err, dataintegrity.ErrUnsupportedSuite):
+		return ErrCodeUnknownSuite
+	case errors.Is(err, dataintegrity.ErrMismatchedPurpose):
+		return ErrCodePurposeMismatch
+	case errors.Is(err, dataintegrity.ErrOutOfDate):
+		return ErrCodeExpired
+	case errors.Is(err, dataintegrity.ErrCreatedInFuture):
+		return ErrCodeCreatedInFuture
+	case errors.Is(err, dataintegrity.ErrInvalidProofChain):
+		return ErrCodeInvalidProofChain
+	case errors.Is(err, dataintegrity.ErrInvalidDomain):
+		return ErrCodeDomainMismatch
+	case errors.Is(err, dataintegrity.ErrInvalidChallenge):
+		return ErrCodeChallengeMismatch
+	case errors.Is(err, dataintegrity.ErrInvalidNonce):
+		return ErrCodeNonceMismatch
+	case errors.Is(err, dataintegrity.ErrMissingVerificationRelationship):
+		return ErrCodeMissingVerificationRelationship
+	case errors.Is(err, dataintegrity.ErrIncompatibleKeyType):
+		return ErrCodeIncompatibleKeyType
+	case errors.Is(err, dataintegrity.ErrAlgorithmMismatch):
+		return ErrCodeAlgorithmMismatch
+	case errors.Is(err, dataintegrity.ErrNoResolver), errors.Is(err, dataintegrity.ErrVMResolution):
+		return ErrCodeVerificationMethod
+	case errors.Is(err, suite.ErrInvalidProof):
+		return ErrCodeSignatureInvalid
+	case errors.Is(err, ErrInvalidCapability):
+		return ErrCodeInvalidCapability
+	}
+
+	return ErrCodeUnknown
+}
+
+// DataIntegrityProofMatching defines which of the Data Integrity proofs of a document must verify.
+type DataIntegrityProofMatching int
+
+const (
+	// AllProofsMustVerify requires every Data Integrity proof to verify. This is the default.
+	AllProofsMustVerify DataIntegrityProofMatching = iota
+	// AtLeastOneProofMustVerify requires at least one Data Integrity proof to verify.
+	AtLeastOneProofMustVerify
+)
+
 type verifyDataIntegrityOpts struct {
-	Verifier  *dataintegrity.Verifier
-	Purpose   string
-	Domain    string
-	Challenge string
+	Verifier      *dataintegrity.Verifier
+	Purpose       string
+	Domain        string
+	Challenge     string
+	Nonce         string
+	ProofMatching DataIntegrityProofMatching
+	// AcceptedDomains are the domains accepted for the proof, instead of Domain.
+	AcceptedDomains []string
+	// CreatedTolerance is the allowed clock skew for the proof created time.
+	CreatedTolerance time.Duration
+	// AllowFutureCreated accepts the proofs created at any time in the future.
+	AllowFutureCreated bool
+	// ExpiresTolerance is the allowed clock skew for the proof expires time.
+	ExpiresTolerance time.Duration
+	// ResolvedVerificationMethod is used instead of resolving the verification method of the proof.
+	ResolvedVerificationMethod *vermethod.VerificationMethod
+	// AllowEmbeddedVerificationMethod makes the verification method embedded in the proof used
+	// instead of resolving it, if it is bound to its DID or trusted by TrustEmbeddedVerificationMethod.
+	AllowEmbeddedVerificationMethod bool
+	// TrustEmbeddedVerificationMethod trusts the embedded verification methods not bound to their DID.
+	TrustEmbeddedVerificationMethod EmbeddedVerificationMethodTrust
+	// UnsignedProofFields are the proof members that are not signed over, in addition
+	// to the embedded verification method.
+	UnsignedProofFields []string
+	// LenientProofContext accepts the proofs signed as if the document had the Data Integrity context.
+	LenientProofContext bool
+	// ProofValueEncodingHint is the encoding of the proof values that are not multibase encoded.
+	ProofValueEncodingHint models.ProofValueEncoding
+	// Capability and CapabilityAction are the expected capability members of the proofs
+	// with the capabilityInvocation or capabilityDelegation purpose.
+	Capability       string
+	CapabilityAction string
+	// TryAllAssertionKeys makes the assertionMethod proofs checked with each assertionMethod key
+	// of the controller when their verification method fails.
+	TryAllAssertionKeys bool
+	// ProofStructure is the expected structure of the proofs, detected from them if not set.
+	ProofStructure models.ProofStructure
+	// SignatureOnly, if set, makes only the signatures of the proofs checked, with the keys it returns.
+	SignatureOnly dataintegrity.KeyLookup
+	// Context bounds the DID resolution and JSON-LD context loading of the verification.
+	Context context.Context
 }
 
+// checkDataIntegrityProofs verifies each of the given proofs against the document
+// and applies the proof matching policy to the results. Errors of failed proofs
+// are reported together with the index of the proof.
+func checkDataIntegrityProofs(
+	jsonldDoc map[string]interface{},
+	proofs []map[string]interface{},
+	opts *verifyDataIntegrityOpts,
+) error {
+	if opts == nil || opts.Verifier == nil {
+		return errMissingDataIntegrityVerifier()
+	}
+
+	singleProofDoc := jsonutil.ShallowCopyObj(jsonldDoc)
+
+	if opts.ProofStructure == models.ProofChain {
+		return checkDataIntegrityProofChain(singleProofDoc, proofs, opts)
+	}
+
+	var proofErrs []error
+
+	for i := range proofs {
+		err := checkProofCapability(jsonldDoc, proofs[i], opts)
+		if err != nil {
+			proofErrs = append(proofErrs, fmt.Errorf("data integrity proof [%d]: %w", i, newDataIntegrityError(err)))
+
+			continue
+		}
+
+		singleProofDoc[jsonFldLDProof] = proofChain(proofs, i)
+
+		docBytes, err := json.Marshal(singleProofDoc)
+		if err != nil {
+			return err
+		}
+
+		err = checkDataIntegrityProof(docBytes, opts)
+		if err != nil {
+			proofErrs = append(proofErrs, fmt.Errorf("data integrity proof [%d]: %w", i, err))
+
+			continue
+		}
+
+		if opts.ProofMatching == AtLeastOneProofMustVerify {
+			return nil
+		}
+	}
+
+	return errors.Join(proofErrs...)
+}
+
+// checkDataIntegrityProofChain verifies the proofs as a proof chain: the chain is verified as a
+// whole, each of its proofs must verify whatever the proof matching policy.
+func checkDataIntegrityProofChain(
+	doc map[string]interface{},
+	proofs []map[string]interface{},
+	opts *verifyDataIntegrityOpts,
+) error {
+	chain := make([]interface{}, len(proofs))
+
+	for i := range proofs {
+		err := checkProofCapability(doc, proofs[i], opts)
+		if err != nil {
+			return fmt.Errorf("data integrity proof [%d]: %w", i, newDataIntegrityError(err))
+		}
+
+		chain[i] = proofs[i]
+	}
+
+	doc[jsonFldLDProof] = chain
+
+	docBytes, err := json.Marshal(doc)
+	if err != nil {
+		return err
+	}
+
+	return checkDataIntegrityProof(docBytes, opts)
+}
+
+// DetectProofStructure returns the structure of the given proofs: models.ProofChain if one of them
+// is chained to a previous proof through previousProof, else models.ProofSet.
+func DetectProofStructure(proofs []Proof) models.ProofStructure {
+	for _, proof := range proofs {
+		if _, ok := proof["previousProof"]; ok {
+			return models.ProofChain
+		}
+	}
+
+	return models.ProofSet
+}
+
+// proofChain returns the proof at index i if it is not chained, else the proof together with
+// the proofs it is chained to (through previousProof), in the proof set order, so that the
+// proof, and the proofs it is chained to, are verified together.
+func proofChain(proofs []map[string]interface{}, i int) interface{} {
+	previousProof := safeStringValue(proofs[i]["previousProof"])
+	if previousProof == "" {
+		return proofs[i]
+	}
+
+	chain := []interface{}{proofs[i]}
+
+	for j := i - 1; j >= 0 && previousProof != ""; j-- {
+		if safeStringValue(proofs[j]["id"]) != previousProof {
+			continue
+		}
+
+		chain = append([]interface{}{proofs[j]}, chain...)
+		previousProof = safeStringValue(proofs[j]["previousProof"])
+	}
+
+	return chain
+}
+
+// checkDataIntegrityProof returns a *DataIntegrityError in case of failure.
+//
 // TODO: refactor to directly use map[string]inteface{} instead []byte.
 func checkDataIntegrityProof(ldBytes []byte, opts *verifyDataIntegrityOpts) error {
 	if opts == nil || opts.Verifier == nil {
-		return errors.New("data integrity proof needs data integrity verifier")
+		return errMissingDataIntegrityVerifier()
+	}
+
+	ctx := opts.Context
+	if ctx == nil {
+		ctx = context.Background()
+	}
+
+	if opts.SignatureOnly != nil {
+		err := opts.Verifier.VerifyProofSignatureContext(ctx, ldBytes, opts.SignatureOnly)
+		if err != nil {
+			return newDataIntegrityError(err)
+		}
+
+		return nil
 	}
 
 	if opts.Purpose == "" {
 		opts.Purpose = assertionMethod
 	}
 
-	return opts.Verifier.VerifyProof(ldBytes, &models.ProofOptions{
-		Purpose:   opts.Purpose,
-		ProofType: models.DataIntegrityProof,
-		Domain:    opts.Domain,
-		Challenge: opts.Challenge,
-	})
+	proofOpts := &models.ProofOptions{
+		Purpose:            opts.Purpose,
+		ProofType:          models.DataIntegrityProof,
+		Domain:             opts.Domain,
+		AcceptedDomains:    opts.AcceptedDomains,
+		Challenge:          opts.Challenge,
+		Nonce:              opts.Nonce,
+		CreatedTolerance:   opts.CreatedTolerance,
+		AllowFutureCreated: opts.AllowFutureCreated,
+		ExpiresTolerance:   opts.ExpiresTolerance,
+		// The embedded verification method is added to the proof once it is created.
+		UnsignedProofFields:    append([]string{jsonFldEmbeddedVerificationMethod}, opts.UnsignedProofFields...),
+		LenientProofContext:    opts.LenientProofContext,
+		ProofValueEncodingHint: opts.ProofValueEncodingHint,
+		TryAllAssertionKeys:    opts.TryAllAssertionKeys,
+		ProofStructure:         opts.ProofStructure,
+	}
+
+	resolvedVM := opts.ResolvedVerificationMethod
+
+	if resolvedVM == nil && opts.AllowEmbeddedVerificationMethod {
+		var err error
+
+		resolvedVM, err = embeddedVerificationMethod(ldBytes)
+		if err != nil {
+			return newDataIntegrityError(err)
+		}
+
+		if resolvedVM != nil {
+			err = checkEmbeddedVerificationMethod(resolvedVM, opts.TrustEmbeddedVerificationMethod)
+			if err != nil {
+				return newDataIntegrityError(err)
+			}
+		}
+	}
+
+	if resolvedVM != nil {
+		vm, err := toModelsVerificationMethod(ldBytes, resolvedVM)
+		if err != nil {
+			return newDataIntegrityError(err)
+		}
+
+		proofOpts.VerificationMethodID = vm.ID
+		proofOpts.VerificationMethod = vm
+	}
+
+	err := opts.Verifier.VerifyProofContext(ctx, ldBytes, proofOpts)
+	if err != nil {
+		return newDataIntegrityError(err)
+	}
+
+	return nil
+}
+
+// toModelsVerificationMethod converts the pre-resolved verification method, checking that
+// it is the one referenced by the proof of ldBytes.
+func toModelsVerificationMethod(
+	ldBytes []byte,
+	vm *vermethod.VerificationMethod,
+) (*models.VerificationMethod, error) {
+	if vm.ID == "" {
+		// TODO update linter to use go 1.20: https://github.com/hyperledger/aries-framework-go/issues/3613
+		return nil, errors.Join(dataintegrity.ErrVMResolution, // nolint:typecheck
+			errors.New("resolved verification method needs ID"))
+	}
+
+	var doc map[string]interface{}
+
+	err := json.Unmarshal(ldBytes, &doc)
+	if err != nil {
+		// TODO update linter to use go 1.20: https://github.com/hyperledger/aries-framework-go/issues/3613
+		return nil, errors.Join(dataintegrity.ErrMalformedProof, err) // nolint:typecheck
+	}
+
+	proofs, err := parseLDProof(doc[jsonFldLDProof])
+	if err != nil {
+		// TODO update linter to use go 1.20: https://github.com/hyperledger/aries-framework-go/issues/3613
+		return nil, errors.Join(dataintegrity.ErrMalformedProof, err) // nolint:typecheck
+	}
+
+	// All the proofs of a proof chain are checked with the same verification method.
+	for _, proof := range proofs {
+		proofVM := safeStringValue(proof["verificationMethod"])
+
+		if proofVM != vm.ID {
+			// TODO update linter to use go 1.20: https://github.com/hyperledger/aries-framework-go/issues/3613
+			return nil, errors.Join(dataintegrity.ErrVMResolution, // nolint:typecheck
+				fmt.Errorf("proof verification method %q does not match resolved verification method %q",
+					proofVM, vm.ID))
+		}
+	}
+
+	controller, _, _ := strings.Cut(vm.ID, "#")
+
+	if vm.JWK != nil {
+		return did.NewVerificationMethodFromJWK(vm.ID, vm.Type, controller, vm.JWK)
+	}
+
+	return did.NewVerificationMethodFromBytes(vm.ID, vm.Type, controller, vm.Value), nil
 }
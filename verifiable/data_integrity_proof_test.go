@@ -7,15 +7,24 @@ SPDX-License-Identifier: Apache-2.0
 package verifiable
 
 import (
+	"bytes"
+	"crypto"
 	_ "embed"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
 	"net/http"
+	"strings"
 	"testing"
 	"time"
 
+	"github.com/multiformats/go-multibase"
 	"github.com/piprate/json-gold/ld"
 	"github.com/samber/lo"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
+	"github.com/tidwall/gjson"
+	"github.com/tidwall/sjson"
 	"github.com/trustbloc/did-go/doc/did"
 	ldcontext "github.com/trustbloc/did-go/doc/ld/context"
 	"github.com/trustbloc/did-go/doc/ld/testutil"
@@ -23,19 +32,21 @@ import (
 	"github.com/trustbloc/did-go/method/key"
 	vdrpkg "github.com/trustbloc/did-go/vdr"
 	vdrapi "github.com/trustbloc/did-go/vdr/api"
+	kmsjwk "github.com/trustbloc/kms-go/doc/jose/jwk"
 	kmsapi "github.com/trustbloc/kms-go/spi/kms"
 
 	"github.com/trustbloc/vc-go/dataintegrity"
 	"github.com/trustbloc/vc-go/dataintegrity/models"
+	"github.com/trustbloc/vc-go/dataintegrity/suite/bbs2023"
 	"github.com/trustbloc/vc-go/dataintegrity/suite/ecdsa2019"
+	"github.com/trustbloc/vc-go/dataintegrity/suite/ecdsasd2023"
 	"github.com/trustbloc/vc-go/dataintegrity/suite/eddsa2022"
 	"github.com/trustbloc/vc-go/internal/testutil/kmscryptoutil"
 	"github.com/trustbloc/vc-go/proof/defaults"
 	"github.com/trustbloc/vc-go/vermethod"
 )
 
-func Test_DataIntegrity_SignVerify(t *testing.T) {
-	vcJSON := `
+const dataIntegrityTestCredential = `
 {
   "@context": [
     "https://www.w3.org/2018/credentials/v1",
@@ -60,6 +71,7 @@ func Test_DataIntegrity_SignVerify(t *testing.T) {
 }
 `
 
+func Test_DataIntegrity_SignVerify(t *testing.T) {
 	kmsCrypto := kmscryptoutil.LocalKMSCrypto(t)
 
 	docLoader := createTestDocumentLoader(t)
@@ -78,6 +90,11 @@ func Test_DataIntegrity_SignVerify(t *testing.T) {
 		return makeMockDIDResolution(signingDID, vm, did.AssertionMethod), nil
 	})
 
+	// The signing key is pinned for its ID, for the verification with the embedded verification method.
+	pinnedSigningKey := func(embedded *vermethod.VerificationMethod) bool {
+		return embedded.ID == signingDID+vmID && sameKey(embedded.JWK, embedded.Type, embedded.Value, key, "", nil)
+	}
+
 	signerSuite := ecdsa2019.NewSignerInitializer(&ecdsa2019.SignerInitializerOptions{
 		SignerGetter:     ecdsa2019.WithKMSCryptoWrapper(kmsCrypto),
 		LDDocumentLoader: docLoader,
@@ -107,8 +124,78 @@ func Test_DataIntegrity_SignVerify(t *testing.T) {
 	}, verifySuite)
 	require.NoError(t, err)
 
+	t.Run("credential, returning proof", func(t *testing.T) {
+		now := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+
+		clockSigner, e := dataintegrity.NewSigner((&dataintegrity.Options{
+			DIDResolver: resolver,
+		}).WithClock(func() time.Time { return now }), signerSuite)
+		require.NoError(t, e)
+
+		vc, e := parseTestCredential(t, []byte(dataIntegrityTestCredential), WithDisabledProofCheck())
+		require.NoError(t, e)
+
+		proof, e := vc.AddDataIntegrityProofReturning(&DataIntegrityProofContext{
+			SigningKeyID: signingDID + vmID,
+			CryptoSuite:  ecdsa2019.SuiteType,
+		}, clockSigner)
+		require.NoError(t, e)
+		require.Equal(t, vc.Proofs()[0], proof)
+		require.Equal(t, "2024-01-02T03:04:05Z", proof["created"])
+		require.NotEmpty(t, proof["proofValue"])
+
+		proof["proofValue"] = "changed"
+		require.NotEqual(t, "changed", vc.Proofs()[0]["proofValue"])
+	})
+
+	t.Run("credential, base64url proof value", func(t *testing.T) {
+		vc, e := parseTestCredential(t, []byte(dataIntegrityTestCredential), WithDisabledProofCheck())
+		require.NoError(t, e)
+
+		proof, e := vc.AddDataIntegrityProofReturning(&DataIntegrityProofContext{
+			SigningKeyID:       signingDID + vmID,
+			CryptoSuite:        ecdsa2019.SuiteType,
+			ProofValueEncoding: models.ProofValueBase64URL,
+		}, signer)
+		require.NoError(t, e)
+		require.True(t, strings.HasPrefix(proof["proofValue"].(string), "u"))
+
+		vcBytes, e := vc.MarshalJSON()
+		require.NoError(t, e)
+
+		_, e = parseTestCredential(t, vcBytes, WithDataIntegrityVerifier(verifier))
+		require.NoError(t, e)
+	})
+
+	t.Run("credential, proof value without multibase prefix", func(t *testing.T) {
+		vc, e := parseTestCredential(t, []byte(dataIntegrityTestCredential), WithDisabledProofCheck())
+		require.NoError(t, e)
+
+		proof, e := vc.AddDataIntegrityProofReturning(&DataIntegrityProofContext{
+			SigningKeyID: signingDID + vmID,
+			CryptoSuite:  ecdsa2019.SuiteType,
+		}, signer)
+		require.NoError(t, e)
+
+		_, signature, e := multibase.Decode(proof["proofValue"].(string))
+		require.NoError(t, e)
+
+		vcBytes, e := vc.MarshalJSON()
+		require.NoError(t, e)
+
+		vcBytes, e = sjson.SetBytes(vcBytes, "proof.proofValue", base64.StdEncoding.EncodeToString(signature))
+		require.NoError(t, e)
+
+		_, e = parseTestCredential(t, vcBytes, WithDataIntegrityVerifier(verifier))
+		require.Error(t, e)
+
+		_, e = parseTestCredential(t, vcBytes, WithDataIntegrityVerifier(verifier),
+			WithProofValueEncodingHint(models.ProofValueBase64))
+		require.NoError(t, e)
+	})
+
 	t.Run("credential", func(t *testing.T) {
-		vc, e := parseTestCredential(t, []byte(vcJSON), WithDisabledProofCheck(), WithStrictValidation())
+		vc, e := parseTestCredential(t, []byte(dataIntegrityTestCredential), WithDisabledProofCheck(), WithStrictValidation())
 		require.NoError(t, e)
 
 		e = vc.AddDataIntegrityProof(signContext, signer)
@@ -125,120 +212,1807 @@ func Test_DataIntegrity_SignVerify(t *testing.T) {
 			_, e = parseTestCredential(t, vcBytes, WithDataIntegrityVerifier(nil))
 			require.Error(t, e)
 			require.Contains(t, e.Error(), "needs data integrity verifier")
+
+			var diErr *DataIntegrityError
+
+			require.ErrorAs(t, e, &diErr)
+			require.Equal(t, ErrCodeMissingVerifier, diErr.Code)
+		})
+
+		t.Run("fail with purpose mismatch", func(t *testing.T) {
+			_, e = parseTestCredential(t, vcBytes, WithDataIntegrityVerifier(verifier),
+				WithExpectedDataIntegrityFields("authentication", "", ""))
+
+			var diErr *DataIntegrityError
+
+			require.ErrorAs(t, e, &diErr)
+			require.Equal(t, ErrCodePurposeMismatch, diErr.Code)
+			require.ErrorIs(t, e, dataintegrity.ErrMismatchedPurpose)
+		})
+
+		t.Run("resolved verification method", func(t *testing.T) {
+			noResolverVerifier, err := dataintegrity.NewVerifier(nil, verifySuite)
+			require.NoError(t, err)
+
+			_, e = parseTestCredential(t, vcBytes, WithDataIntegrityVerifier(noResolverVerifier),
+				WithExpectedDataIntegrityFields(assertionMethod, "mock-domain", "mock-challenge"),
+				WithDataIntegrityResolvedVerificationMethod(&vermethod.VerificationMethod{
+					ID:   signingDID + vmID,
+					Type: "JsonWebKey2020",
+					JWK:  key,
+				}))
+			require.NoError(t, e)
+
+			_, e = parseTestCredential(t, vcBytes, WithDataIntegrityVerifier(noResolverVerifier),
+				WithExpectedDataIntegrityFields(assertionMethod, "mock-domain", "mock-challenge"),
+				WithDataIntegrityResolvedVerificationMethod(&vermethod.VerificationMethod{
+					ID:   signingDID + "#key-2",
+					Type: "JsonWebKey2020",
+					JWK:  key,
+				}))
+
+			var diErr *DataIntegrityError
+
+			require.ErrorAs(t, e, &diErr)
+			require.Equal(t, ErrCodeVerificationMethod, diErr.Code)
+			require.ErrorContains(t, e, "does not match resolved verification method")
+
+			_, e = parseTestCredential(t, vcBytes, WithDataIntegrityVerifier(noResolverVerifier),
+				WithExpectedDataIntegrityFields(assertionMethod, "mock-domain", "mock-challenge"),
+				WithDataIntegrityResolvedVerificationMethod(&vermethod.VerificationMethod{
+					Type: "JsonWebKey2020",
+					JWK:  key,
+				}))
+			require.ErrorContains(t, e, "resolved verification method needs ID")
+		})
+
+		t.Run("signature only", func(t *testing.T) {
+			noResolverVerifier, err := dataintegrity.NewVerifier(nil, verifySuite)
+			require.NoError(t, err)
+
+			trustedKeys := func(id string) (crypto.PublicKey, bool) {
+				if id != signingDID+vmID {
+					return nil, false
+				}
+
+				return key.Key, true
+			}
+
+			// Neither the domain and challenge, nor the verification method, are checked.
+			_, e = parseTestCredential(t, vcBytes, WithDataIntegrityVerifier(noResolverVerifier),
+				WithExpectedDataIntegrityFields("authentication", "other-domain", "other-challenge"),
+				WithSignatureOnly(trustedKeys))
+			require.NoError(t, e)
+
+			_, e = parseTestCredential(t, vcBytes, WithDataIntegrityVerifier(noResolverVerifier),
+				WithSignatureOnly(func(string) (crypto.PublicKey, bool) { return nil, false }))
+
+			var diErr *DataIntegrityError
+
+			require.ErrorAs(t, e, &diErr)
+			require.Equal(t, ErrCodeVerificationMethod, diErr.Code)
+
+			tamperedBytes, err := sjson.SetBytes(vcBytes, "issuer", "did:example:other")
+			require.NoError(t, err)
+
+			_, e = parseTestCredential(t, tamperedBytes, WithDataIntegrityVerifier(noResolverVerifier),
+				WithSignatureOnly(trustedKeys))
+			require.ErrorAs(t, e, &diErr)
+			require.Equal(t, ErrCodeSignatureInvalid, diErr.Code)
+		})
+
+		t.Run("fail with missing verification relationship", func(t *testing.T) {
+			authResolver := resolveFunc(func(id string) (*did.DocResolution, error) {
+				return makeMockDIDResolution(signingDID, vm, did.Authentication), nil
+			})
+
+			checkingVerifier, err := dataintegrity.NewVerifier((&dataintegrity.Options{
+				DIDResolver: authResolver,
+			}).WithProofRelationshipCheck(assertionMethod), verifySuite)
+			require.NoError(t, err)
+
+			_, e = parseTestCredential(t, vcBytes, WithDataIntegrityVerifier(checkingVerifier),
+				WithExpectedDataIntegrityFields(assertionMethod, "mock-domain", "mock-challenge"),
+				WithDataIntegrityResolvedVerificationMethod(&vermethod.VerificationMethod{
+					ID:   signingDID + vmID,
+					Type: "JsonWebKey2020",
+					JWK:  key,
+				}))
+
+			var diErr *DataIntegrityError
+
+			require.ErrorAs(t, e, &diErr)
+			require.Equal(t, ErrCodeMissingVerificationRelationship, diErr.Code)
+			require.ErrorIs(t, e, dataintegrity.ErrMissingVerificationRelationship)
+		})
+
+		t.Run("fail with incompatible key type", func(t *testing.T) {
+			noResolverVerifier, err := dataintegrity.NewVerifier(nil, verifySuite)
+			require.NoError(t, err)
+
+			_, e = parseTestCredential(t, vcBytes, WithDataIntegrityVerifier(noResolverVerifier),
+				WithExpectedDataIntegrityFields(assertionMethod, "mock-domain", "mock-challenge"),
+				WithDataIntegrityResolvedVerificationMethod(&vermethod.VerificationMethod{
+					ID:    signingDID + vmID,
+					Type:  "Ed25519VerificationKey2020",
+					Value: make([]byte, 32),
+				}))
+
+			var diErr *DataIntegrityError
+
+			require.ErrorAs(t, e, &diErr)
+			require.Equal(t, ErrCodeIncompatibleKeyType, diErr.Code)
+			require.ErrorContains(t, e, "incompatible with key type Ed25519VerificationKey2020")
+		})
+
+		t.Run("fail with invalid signature", func(t *testing.T) {
+			tamperedVC, err := parseTestCredential(t, vcBytes, WithDisabledProofCheck())
+			require.NoError(t, err)
+
+			tamperedVC.credentialJSON["id"] = "https://example.com/credentials/1873"
+
+			tamperedBytes, err := tamperedVC.MarshalJSON()
+			require.NoError(t, err)
+
+			_, e = parseTestCredential(t, tamperedBytes, WithDataIntegrityVerifier(verifier))
+
+			var diErr *DataIntegrityError
+
+			require.ErrorAs(t, e, &diErr)
+			require.Equal(t, ErrCodeSignatureInvalid, diErr.Code)
 		})
 	})
 
-	t.Run("presentation", func(t *testing.T) {
-		vp, e := newTestPresentation(t, []byte(validPresentation), WithPresDisabledProofCheck())
+	t.Run("credential, embedded verification method", func(t *testing.T) {
+		vc, e := parseTestCredential(t, []byte(dataIntegrityTestCredential), WithDisabledProofCheck())
 		require.NoError(t, e)
 
-		e = vp.AddDataIntegrityProof(signContext, signer)
+		proof, e := vc.AddDataIntegrityProofReturning(&DataIntegrityProofContext{
+			SigningKeyID:            signingDID + vmID,
+			CryptoSuite:             ecdsa2019.SuiteType,
+			Domain:                  "mock-domain",
+			Challenge:               "mock-challenge",
+			EmbedVerificationMethod: true,
+		}, signer)
 		require.NoError(t, e)
+		require.Contains(t, proof, jsonFldEmbeddedVerificationMethod)
 
-		vpBytes, e := vp.MarshalJSON()
+		vcBytes, e := vc.MarshalJSON()
 		require.NoError(t, e)
 
-		_, e = newTestPresentation(t, vpBytes,
-			WithPresDataIntegrityVerifier(verifier),
-			WithPresExpectedDataIntegrityFields(assertionMethod, "mock-domain", "mock-challenge"),
-		)
+		// The verifier can't resolve DIDs.
+		noResolverVerifier, e := dataintegrity.NewVerifier(nil, verifySuite)
 		require.NoError(t, e)
 
-		t.Run("fail if not provided verifier", func(t *testing.T) {
-			_, e = newTestPresentation(t, vpBytes)
-			require.Error(t, e)
-			require.Contains(t, e.Error(), "needs data integrity verifier")
-		})
+		parseOpts := []CredentialOpt{
+			WithDataIntegrityVerifier(noResolverVerifier),
+			WithExpectedDataIntegrityFields(assertionMethod, "mock-domain", "mock-challenge"),
+		}
+
+		trustAll := func(*vermethod.VerificationMethod) bool { return true }
+
+		_, e = parseTestCredential(t, vcBytes, append(parseOpts, WithAllowEmbeddedVerificationMethod(pinnedSigningKey))...)
+		require.NoError(t, e)
+
+		var diErr *DataIntegrityError
+
+		_, e = parseTestCredential(t, vcBytes, parseOpts...)
+		require.ErrorAs(t, e, &diErr)
+		require.Equal(t, ErrCodeVerificationMethod, diErr.Code)
+
+		// Without trust, the embedded key of a DID other than did:key and did:jwk is not used.
+		_, e = parseTestCredential(t, vcBytes, append(parseOpts, WithAllowEmbeddedVerificationMethod(nil))...)
+		require.ErrorAs(t, e, &diErr)
+		require.Equal(t, ErrCodeVerificationMethod, diErr.Code)
+		require.ErrorContains(t, e, `embedded verification method "did:foo:bar#key-1" is not trusted`)
+
+		otherIDBytes, e := sjson.SetBytes(vcBytes, "proof.embeddedVerificationMethod.id", signingDID+"#key-2")
+		require.NoError(t, e)
+
+		_, e = parseTestCredential(t, otherIDBytes, append(parseOpts, WithAllowEmbeddedVerificationMethod(trustAll))...)
+		require.ErrorAs(t, e, &diErr)
+		require.Equal(t, ErrCodeVerificationMethod, diErr.Code)
+		require.ErrorContains(t, e, "does not match resolved verification method")
+
+		otherKey, e := kmsCrypto.Create(kmsapi.ECDSAP256IEEEP1363)
+		require.NoError(t, e)
+
+		otherKeyBytes, e := sjson.SetBytes(vcBytes, "proof.embeddedVerificationMethod.publicKeyJwk", otherKey)
+		require.NoError(t, e)
+
+		// A key embedded under the ID of the signer is not the pinned key.
+		_, e = parseTestCredential(t, otherKeyBytes, append(parseOpts, WithAllowEmbeddedVerificationMethod(pinnedSigningKey))...)
+		require.ErrorAs(t, e, &diErr)
+		require.Equal(t, ErrCodeVerificationMethod, diErr.Code)
+
+		_, e = parseTestCredential(t, otherKeyBytes,
+			append(parseOpts, WithAllowEmbeddedVerificationMethod(trustAll))...)
+		require.ErrorAs(t, e, &diErr)
+		require.Equal(t, ErrCodeSignatureInvalid, diErr.Code)
+
+		noKeyBytes, e := sjson.DeleteBytes(vcBytes, "proof.embeddedVerificationMethod.publicKeyJwk")
+		require.NoError(t, e)
+
+		_, e = parseTestCredential(t, noKeyBytes, append(parseOpts, WithAllowEmbeddedVerificationMethod(pinnedSigningKey))...)
+		require.ErrorIs(t, e, dataintegrity.ErrMalformedProof)
+		require.ErrorContains(t, e, "needs publicKeyJwk or publicKeyMultibase")
 	})
 
-	t.Run("did document", func(t *testing.T) {
-		didDoc, e := did.ParseDocument(validDoc)
+	t.Run("credential, embedded verification method of did:jwk", func(t *testing.T) {
+		jwkBytes, e := json.Marshal(key)
 		require.NoError(t, e)
 
-		signedDoc, e := AddDIDDataIntegrityProof(didDoc, signContext, signer)
+		jwkVMID := "did:jwk:" + base64.RawURLEncoding.EncodeToString(jwkBytes) + "#0"
+
+		jwkSigner, e := dataintegrity.NewSigner(&dataintegrity.Options{
+			DIDResolver: dataintegrity.NewJWKResolver(),
+		}, signerSuite)
 		require.NoError(t, e)
 
-		require.Len(t, signedDoc.Proof, 1)
-		require.Equal(t, models.DataIntegrityProof, signedDoc.Proof[0].Type)
-		require.Equal(t, ecdsa2019.SuiteType, signedDoc.Proof[0].CryptoSuite)
-		require.Empty(t, signedDoc.Proof[0].JWS)
-		require.NotEmpty(t, signedDoc.Proof[0].ProofValue)
+		vc, e := parseTestCredential(t, []byte(dataIntegrityTestCredential), WithDisabledProofCheck())
+		require.NoError(t, e)
 
-		e = VerifyDIDProof(signedDoc,
-			WithDIDDataIntegrityVerifier(verifier),
-			WithDIDExpectedDataIntegrityFields(assertionMethod, "mock-domain", "mock-challenge"),
-		)
+		e = vc.AddDataIntegrityProof(&DataIntegrityProofContext{
+			SigningKeyID:            jwkVMID,
+			CryptoSuite:             ecdsa2019.SuiteType,
+			EmbedVerificationMethod: true,
+		}, jwkSigner)
+		require.NoError(t, e)
+
+		vcBytes, e := vc.MarshalJSON()
+		require.NoError(t, e)
+
+		noResolverVerifier, e := dataintegrity.NewVerifier(nil, verifySuite)
 		require.NoError(t, e)
+
+		parseOpts := []CredentialOpt{
+			WithDataIntegrityVerifier(noResolverVerifier),
+			WithAllowEmbeddedVerificationMethod(nil),
+		}
+
+		// The embedded key is the key that the DID encodes, no trust is needed.
+		_, e = parseTestCredential(t, vcBytes, parseOpts...)
+		require.NoError(t, e)
+
+		otherKey, e := kmsCrypto.Create(kmsapi.ECDSAP256IEEEP1363)
+		require.NoError(t, e)
+
+		otherKeyBytes, e := sjson.SetBytes(vcBytes, "proof.embeddedVerificationMethod.publicKeyJwk", otherKey)
+		require.NoError(t, e)
+
+		var diErr *DataIntegrityError
+
+		_, e = parseTestCredential(t, otherKeyBytes, parseOpts...)
+		require.ErrorAs(t, e, &diErr)
+		require.Equal(t, ErrCodeVerificationMethod, diErr.Code)
+		require.ErrorContains(t, e, "is not the key of its DID")
 	})
 
-	t.Run("failure", func(t *testing.T) {
-		t.Run("marshal json", func(t *testing.T) {
-			vc, err := CreateCredential(CredentialContents{
-				CustomContext: []interface{}{make(chan int)},
-			}, nil)
-			require.NoError(t, err)
+	t.Run("credential, public verification method ID", func(t *testing.T) {
+		const publicVMID = "did:foo:alias#key-1"
 
-			err = vc.AddDataIntegrityProof(&DataIntegrityProofContext{}, &dataintegrity.Signer{})
-			require.Error(t, err)
-			require.Contains(t, err.Error(), "add data integrity proof to VC")
+		vc, e := parseTestCredential(t, []byte(dataIntegrityTestCredential), WithDisabledProofCheck())
+		require.NoError(t, e)
 
-			vp := &Presentation{
-				Proofs: []Proof{
-					{
-						"foo": make(chan int),
-					},
-				},
-			}
+		proof, e := vc.AddDataIntegrityProofReturning(&DataIntegrityProofContext{
+			SigningKeyID:               signingDID + vmID,
+			PublicVerificationMethodID: publicVMID,
+			CryptoSuite:                ecdsa2019.SuiteType,
+			EmbedVerificationMethod:    true,
+		}, signer)
+		require.NoError(t, e)
+		require.Equal(t, publicVMID, proof["verificationMethod"])
 
-			err = vp.AddDataIntegrityProof(&DataIntegrityProofContext{}, &dataintegrity.Signer{})
-			require.Error(t, err)
-			require.Contains(t, err.Error(), "add data integrity proof to VP")
-		})
+		embeddedVM, ok := proof[jsonFldEmbeddedVerificationMethod].(map[string]interface{})
+		require.True(t, ok)
+		require.Equal(t, publicVMID, embeddedVM["id"])
+		require.Equal(t, "did:foo:alias", embeddedVM["controller"])
 
-		t.Run("add data integrity proof", func(t *testing.T) {
-			vc := &Credential{}
+		vcBytes, e := vc.MarshalJSON()
+		require.NoError(t, e)
+		require.NotContains(t, string(vcBytes), signingDID+vmID)
 
-			err := vc.AddDataIntegrityProof(&DataIntegrityProofContext{}, &dataintegrity.Signer{})
-			require.Error(t, err)
-			require.Contains(t, err.Error(), "unsupported cryptographic suite")
+		noResolverVerifier, e := dataintegrity.NewVerifier(nil, verifySuite)
+		require.NoError(t, e)
 
-			vp := &Presentation{}
+		// The signing key is pinned for its public ID.
+		pinnedPublicKey := func(embedded *vermethod.VerificationMethod) bool {
+			return embedded.ID == publicVMID && sameKey(embedded.JWK, embedded.Type, embedded.Value, key, "", nil)
+		}
 
-			err = vp.AddDataIntegrityProof(&DataIntegrityProofContext{
-				Created: &time.Time{},
-			}, &dataintegrity.Signer{})
-			require.Error(t, err)
-			require.Contains(t, err.Error(), "unsupported cryptographic suite")
-		})
+		_, e = parseTestCredential(t, vcBytes, WithDataIntegrityVerifier(noResolverVerifier),
+			WithAllowEmbeddedVerificationMethod(pinnedPublicKey))
+		require.NoError(t, e)
+
+		_, e = parseTestCredential(t, vcBytes, WithDataIntegrityVerifier(noResolverVerifier),
+			WithAllowEmbeddedVerificationMethod(pinnedSigningKey))
+		require.ErrorContains(t, e, "is not trusted")
 	})
-}
 
-//go:embed testdata/example_presentation_1_ed25519.jsonld
-var examplePresentation1Ed25519 []byte
+	t.Run("credential with several subjects", func(t *testing.T) {
+		credBytes, e := sjson.SetRawBytes([]byte(dataIntegrityTestCredential), "credentialSubject", []byte(`[
+			{"id": "did:example:ebfeb1f712ebc6f1c276e12ec21", "name": "Jayden Doe"},
+			{"id": "did:example:c276e12ec21ebfeb1f712ebc6f1", "name": "Morgan Doe"}
+		]`))
+		require.NoError(t, e)
 
-//go:embed testdata/example_presentation_2_ed25519.json
-var examplePresentation2Ed25519 []byte
+		vc, e := parseTestCredential(t, credBytes, WithDisabledProofCheck())
+		require.NoError(t, e)
+		require.Len(t, vc.Subjects(), 2)
 
-//go:embed testdata/example_presentation_3_ed25519.json
-var examplePresentation3Ed25519 []byte
+		e = vc.AddDataIntegrityProof(signContext, signer)
+		require.NoError(t, e)
 
-//go:embed testdata/example_presentation_4_p256.json
-var examplePresentation4P256 []byte
+		vcBytes, e := vc.MarshalJSON()
+		require.NoError(t, e)
+		require.True(t, gjson.GetBytes(vcBytes, "credentialSubject").IsArray())
 
-//go:embed testdata/example_presentation_4_p384.json
-var examplePresentation4P384 []byte
+		parseOpts := []CredentialOpt{
+			WithDataIntegrityVerifier(verifier),
+			WithExpectedDataIntegrityFields(assertionMethod, "mock-domain", "mock-challenge"),
+		}
 
-//go:embed testdata/context/citizenship_v2.jsonld
-var citizenshipV2Context []byte
+		verified, e := parseTestCredential(t, vcBytes, parseOpts...)
+		require.NoError(t, e)
+		require.Equal(t, vc.Subjects(), verified.Subjects())
 
-//go:embed testdata/context/citizenship_v4rc1.jsonld
-var citizenshipV4rc1Context []byte
+		tampered, e := sjson.SetBytes(vcBytes, "credentialSubject.1.name", "Alex Doe")
+		require.NoError(t, e)
 
-//go:embed testdata/context/imsglobal_context.jsonld
-var imsglobalContext []byte
+		var diErr *DataIntegrityError
 
-//go:embed testdata/context/lds_jws2020_v1.jsonld
-var ldsJWS2020V1Context []byte
+		_, e = parseTestCredential(t, tampered, parseOpts...)
+		require.ErrorAs(t, e, &diErr)
+		require.Equal(t, ErrCodeSignatureInvalid, diErr.Code)
+	})
+
+	t.Run("credential without the data integrity context", func(t *testing.T) {
+		// The verification cache must not accept a strict verification after a lenient one.
+		cachingVerifier, e := dataintegrity.NewVerifier(
+			(&dataintegrity.Options{DIDResolver: resolver}).WithVerificationCache(10), verifySuite)
+		require.NoError(t, e)
+
+		vc, e := parseTestCredential(t, []byte(dataIntegrityTestCredential), WithDisabledProofCheck())
+		require.NoError(t, e)
+
+		e = vc.AddDataIntegrityProof(signContext, signer)
+		require.NoError(t, e)
+
+		signedBytes, e := vc.MarshalJSON()
+		require.NoError(t, e)
+
+		// The issuer signed with the Data Integrity context, but didn't include it in the credential.
+		vcBytes, e := sjson.DeleteBytes(signedBytes, "@context.2")
+		require.NoError(t, e)
+		require.NotContains(t, gjson.GetBytes(vcBytes, "@context").Raw, "data-integrity")
+
+		parseOpts := []CredentialOpt{
+			WithDataIntegrityVerifier(cachingVerifier),
+			WithExpectedDataIntegrityFields(assertionMethod, "mock-domain", "mock-challenge"),
+		}
+
+		var diErr *DataIntegrityError
+
+		_, e = parseTestCredential(t, vcBytes, parseOpts...)
+		require.ErrorAs(t, e, &diErr)
+		require.Equal(t, ErrCodeSignatureInvalid, diErr.Code)
+
+		_, e = parseTestCredential(t, vcBytes, append(parseOpts, WithLenientProofContext())...)
+		require.NoError(t, e)
+
+		_, e = parseTestCredential(t, vcBytes, parseOpts...)
+		require.ErrorAs(t, e, &diErr)
+
+		// The proofs that verify with the context of the credential are still accepted.
+		_, e = parseTestCredential(t, signedBytes, append(parseOpts, WithLenientProofContext())...)
+		require.NoError(t, e)
+
+		unsignedBytes, e := sjson.DeleteBytes([]byte(dataIntegrityTestCredential), "@context.2")
+		require.NoError(t, e)
+
+		strictVC, e := parseTestCredential(t, unsignedBytes, WithDisabledProofCheck())
+		require.NoError(t, e)
+
+		e = strictVC.AddDataIntegrityProof(signContext, signer)
+		require.NoError(t, e)
+
+		strictBytes, e := strictVC.MarshalJSON()
+		require.NoError(t, e)
+
+		_, e = parseTestCredential(t, strictBytes, append(parseOpts, WithLenientProofContext())...)
+		require.NoError(t, e)
+	})
+
+	t.Run("credential, additional proof fields", func(t *testing.T) {
+		// A full IRI, as the RDF canonicalization drops the terms the context doesn't define.
+		const nonceField = "https://example.com/vocab/nonce"
+
+		vc, e := parseTestCredential(t, []byte(dataIntegrityTestCredential), WithDisabledProofCheck())
+		require.NoError(t, e)
+
+		e = vc.AddDataIntegrityProof(&DataIntegrityProofContext{
+			SigningKeyID:            signingDID + vmID,
+			CryptoSuite:             ecdsa2019.SuiteType,
+			EmbedVerificationMethod: true,
+			AdditionalProofFields:   map[string]interface{}{nonceField: "mock-nonce"},
+		}, signer)
+		require.NoError(t, e)
+
+		proofs := vc.DataIntegrityProofs()
+		require.Len(t, proofs, 1)
+		require.Equal(t, map[string]interface{}{nonceField: "mock-nonce"}, proofs[0].AdditionalProofFields)
+
+		vcBytes, e := vc.MarshalJSON()
+		require.NoError(t, e)
+
+		parseOpts := []CredentialOpt{
+			WithDataIntegrityVerifier(verifier),
+			WithAllowEmbeddedVerificationMethod(pinnedSigningKey),
+		}
+
+		_, e = parseTestCredential(t, vcBytes, parseOpts...)
+		require.NoError(t, e)
+
+		tampered, e := sjson.SetBytes(vcBytes, "proof.https://example\\.com/vocab/nonce", "other-nonce")
+		require.NoError(t, e)
+
+		var diErr *DataIntegrityError
+
+		_, e = parseTestCredential(t, tampered, parseOpts...)
+		require.ErrorAs(t, e, &diErr)
+		require.Equal(t, ErrCodeSignatureInvalid, diErr.Code)
+
+		e = vc.AddDataIntegrityProof(&DataIntegrityProofContext{
+			SigningKeyID:          signingDID + vmID,
+			CryptoSuite:           ecdsa2019.SuiteType,
+			AdditionalProofFields: map[string]interface{}{"created": "2024-01-01T00:00:00Z"},
+		}, signer)
+		require.ErrorIs(t, e, dataintegrity.ErrProofGeneration)
+
+		e = vc.AddDataIntegrityProof(&DataIntegrityProofContext{
+			SigningKeyID:          signingDID + vmID,
+			CryptoSuite:           ecdsa2019.SuiteType,
+			AdditionalProofFields: map[string]interface{}{jsonFldEmbeddedVerificationMethod: "mock"},
+		}, signer)
+		require.ErrorContains(t, e, `additional proof field "embeddedVerificationMethod" is reserved`)
+	})
+
+	t.Run("credential, nonce", func(t *testing.T) {
+		parseOpts := []CredentialOpt{
+			WithDataIntegrityVerifier(verifier),
+			WithAllowEmbeddedVerificationMethod(pinnedSigningKey),
+		}
+
+		signWithNonce := func(t *testing.T, nonce string) []byte {
+			t.Helper()
+
+			vc, e := parseTestCredential(t, []byte(dataIntegrityTestCredential), WithDisabledProofCheck())
+			require.NoError(t, e)
+
+			e = vc.AddDataIntegrityProof(&DataIntegrityProofContext{
+				SigningKeyID:            signingDID + vmID,
+				CryptoSuite:             ecdsa2019.SuiteType,
+				EmbedVerificationMethod: true,
+				Nonce:                   nonce,
+			}, signer)
+			require.NoError(t, e)
+
+			proofs := vc.DataIntegrityProofs()
+			require.Len(t, proofs, 1)
+			require.Equal(t, nonce, proofs[0].Nonce)
+
+			vcBytes, e := vc.MarshalJSON()
+			require.NoError(t, e)
+
+			return vcBytes
+		}
+
+		t.Run("with nonce", func(t *testing.T) {
+			vcBytes := signWithNonce(t, "mock-nonce")
+			require.Equal(t, "mock-nonce", gjson.GetBytes(vcBytes, "proof.nonce").String())
+
+			_, e := parseTestCredential(t, vcBytes, append(parseOpts, WithExpectedDataIntegrityNonce("mock-nonce"))...)
+			require.NoError(t, e)
+
+			_, e = parseTestCredential(t, vcBytes, parseOpts...)
+			require.NoError(t, e)
+
+			var diErr *DataIntegrityError
+
+			_, e = parseTestCredential(t, vcBytes, append(parseOpts, WithExpectedDataIntegrityNonce("other-nonce"))...)
+			require.ErrorAs(t, e, &diErr)
+			require.Equal(t, ErrCodeNonceMismatch, diErr.Code)
+			require.ErrorIs(t, e, dataintegrity.ErrInvalidNonce)
+
+			tampered, e := sjson.SetBytes(vcBytes, "proof.nonce", "other-nonce")
+			require.NoError(t, e)
+
+			_, e = parseTestCredential(t, tampered, append(parseOpts, WithExpectedDataIntegrityNonce("other-nonce"))...)
+			require.ErrorAs(t, e, &diErr)
+			require.Equal(t, ErrCodeSignatureInvalid, diErr.Code)
+		})
+
+		t.Run("without nonce", func(t *testing.T) {
+			vcBytes := signWithNonce(t, "")
+			require.False(t, gjson.GetBytes(vcBytes, "proof.nonce").Exists())
+
+			_, e := parseTestCredential(t, vcBytes, parseOpts...)
+			require.NoError(t, e)
+
+			var diErr *DataIntegrityError
+
+			_, e = parseTestCredential(t, vcBytes, append(parseOpts, WithExpectedDataIntegrityNonce("mock-nonce"))...)
+			require.ErrorAs(t, e, &diErr)
+			require.Equal(t, ErrCodeNonceMismatch, diErr.Code)
+		})
+	})
+
+	t.Run("credential with inline context", func(t *testing.T) {
+		vcJSON := `{
+  "@context": [
+    "https://www.w3.org/2018/credentials/v1",
+    "https://w3id.org/security/data-integrity/v2",
+    {
+      "@version": 1.1,
+      "ex": "https://example.com/vocab#",
+      "favoriteColor": "ex:favoriteColor",
+      "Preferences": {
+        "@id": "ex:Preferences",
+        "@context": {
+          "theme": "ex:theme"
+        }
+      }
+    }
+  ],
+  "id": "https://example.com/credentials/1872",
+  "type": ["VerifiableCredential"],
+  "issuer": "did:foo:bar",
+  "issuanceDate": "2010-01-01T19:23:24Z",
+  "credentialSubject": {
+    "id": "did:example:ebfeb1f712ebc6f1c276e12ec21",
+    "type": "Preferences",
+    "favoriteColor": "blue",
+    "theme": "dark"
+  }
+}`
+
+		vc, e := parseTestCredential(t, []byte(vcJSON), WithDisabledProofCheck(), WithStrictValidation())
+		require.NoError(t, e)
+
+		e = vc.AddDataIntegrityProof(&DataIntegrityProofContext{
+			SigningKeyID: signingDID + vmID,
+			CryptoSuite:  ecdsa2019.SuiteType,
+		}, signer)
+		require.NoError(t, e)
+
+		vcBytes, e := vc.MarshalJSON()
+		require.NoError(t, e)
+
+		_, e = parseTestCredential(t, vcBytes, WithDataIntegrityVerifier(verifier), WithStrictValidation())
+		require.NoError(t, e)
+
+		for path, value := range map[string]string{
+			"credentialSubject.favoriteColor": "red",
+			"credentialSubject.theme":         "light",
+		} {
+			tampered, te := sjson.SetBytes(vcBytes, path, value)
+			require.NoError(t, te)
+
+			var diErr *DataIntegrityError
+
+			_, e = parseTestCredential(t, tampered, WithDataIntegrityVerifier(verifier))
+			require.ErrorAs(t, e, &diErr, path)
+			require.Equal(t, ErrCodeSignatureInvalid, diErr.Code, path)
+		}
+	})
+
+	t.Run("embedded verification method with public key value", func(t *testing.T) {
+		const multikeyID = signingDID + "#multikey-1"
+
+		multikeyVM := did.NewVerificationMethodFromBytes(multikeyID, "Multikey", signingDID,
+			[]byte{0xed, 0x01, 1, 2, 3})
+
+		raw := embeddedVerificationMethodToRaw(multikeyVM)
+		require.Equal(t, "zTjsU3jY", raw["publicKeyMultibase"])
+
+		ldBytes, e := json.Marshal(JSONObject{
+			jsonFldLDProof: JSONObject{
+				"verificationMethod":              multikeyID,
+				jsonFldEmbeddedVerificationMethod: raw,
+			},
+		})
+		require.NoError(t, e)
+
+		embedded, e := embeddedVerificationMethod(ldBytes)
+		require.NoError(t, e)
+		require.Equal(t, &vermethod.VerificationMethod{
+			ID:    multikeyID,
+			Type:  "Multikey",
+			Value: multikeyVM.Value,
+		}, embedded)
+	})
+
+	t.Run("credential created in the future", func(t *testing.T) {
+		vc, e := parseTestCredential(t, []byte(dataIntegrityTestCredential), WithDisabledProofCheck())
+		require.NoError(t, e)
+
+		e = vc.AddDataIntegrityProof(&DataIntegrityProofContext{
+			SigningKeyID: signingDID + vmID,
+			CryptoSuite:  ecdsa2019.SuiteType,
+			Created:      lo.ToPtr(time.Now().Add(time.Minute)),
+		}, signer)
+		require.NoError(t, e)
+
+		vcBytes, e := vc.MarshalJSON()
+		require.NoError(t, e)
+
+		_, e = parseTestCredential(t, vcBytes, WithDataIntegrityVerifier(verifier))
+
+		var diErr *DataIntegrityError
+
+		require.ErrorAs(t, e, &diErr)
+		require.Equal(t, ErrCodeCreatedInFuture, diErr.Code)
+
+		_, e = parseTestCredential(t, vcBytes, WithDataIntegrityVerifier(verifier),
+			WithProofCreatedTolerance(2*time.Minute))
+		require.NoError(t, e)
+
+		_, e = parseTestCredential(t, vcBytes, WithDataIntegrityVerifier(verifier), WithAllowFutureCreated(true))
+		require.NoError(t, e)
+
+		_, e = parseTestCredential(t, vcBytes, WithDataIntegrityVerifier(verifier), WithAllowFutureCreated(false))
+		require.ErrorAs(t, e, &diErr)
+		require.Equal(t, ErrCodeCreatedInFuture, diErr.Code)
+	})
+
+	t.Run("pre-dated credential created now", func(t *testing.T) {
+		validFrom := time.Now().Add(24 * time.Hour).UTC().Format(time.RFC3339)
+
+		credJSON, e := sjson.Set(dataIntegrityTestCredential, "issuanceDate", validFrom)
+		require.NoError(t, e)
+
+		vc, e := parseTestCredential(t, []byte(credJSON), WithDisabledProofCheck())
+		require.NoError(t, e)
+
+		e = vc.AddDataIntegrityProof(&DataIntegrityProofContext{
+			SigningKeyID: signingDID + vmID,
+			CryptoSuite:  ecdsa2019.SuiteType,
+		}, signer)
+		require.NoError(t, e)
+
+		vcBytes, e := vc.MarshalJSON()
+		require.NoError(t, e)
+
+		// The proof verifies, the credential validity period is checked separately.
+		parsed, e := parseTestCredential(t, vcBytes, WithDataIntegrityVerifier(verifier))
+		require.NoError(t, e)
+		require.ErrorIs(t, parsed.CheckValidity(time.Now()), ErrCredentialNotYetValid)
+	})
+
+	t.Run("credential proof expiry", func(t *testing.T) {
+		signWithExpires := func(t *testing.T, expires *time.Time) []byte {
+			t.Helper()
+
+			vc, e := parseTestCredential(t, []byte(dataIntegrityTestCredential), WithDisabledProofCheck())
+			require.NoError(t, e)
+
+			e = vc.AddDataIntegrityProof(&DataIntegrityProofContext{
+				SigningKeyID: signingDID + vmID,
+				CryptoSuite:  ecdsa2019.SuiteType,
+				Created:      lo.ToPtr(time.Now().Add(-time.Hour)),
+				Expires:      expires,
+			}, signer)
+			require.NoError(t, e)
+
+			vcBytes, e := vc.MarshalJSON()
+			require.NoError(t, e)
+
+			return vcBytes
+		}
+
+		t.Run("expired", func(t *testing.T) {
+			vcBytes := signWithExpires(t, lo.ToPtr(time.Now().Add(-time.Minute)))
+
+			_, e := parseTestCredential(t, vcBytes, WithDataIntegrityVerifier(verifier))
+
+			var diErr *DataIntegrityError
+
+			require.ErrorAs(t, e, &diErr)
+			require.Equal(t, ErrCodeExpired, diErr.Code)
+			require.ErrorIs(t, e, dataintegrity.ErrExpired)
+
+			_, e = parseTestCredential(t, vcBytes, WithDataIntegrityVerifier(verifier),
+				WithProofExpiryTolerance(10*time.Second))
+			require.ErrorIs(t, e, dataintegrity.ErrExpired)
+
+			_, e = parseTestCredential(t, vcBytes, WithDataIntegrityVerifier(verifier),
+				WithProofExpiryTolerance(2*time.Minute))
+			require.NoError(t, e)
+		})
+
+		t.Run("not yet expired", func(t *testing.T) {
+			vcBytes := signWithExpires(t, lo.ToPtr(time.Now().Add(time.Hour)))
+
+			_, e := parseTestCredential(t, vcBytes, WithDataIntegrityVerifier(verifier))
+			require.NoError(t, e)
+		})
+
+		t.Run("without expiry", func(t *testing.T) {
+			vcBytes := signWithExpires(t, nil)
+			require.NotContains(t, string(vcBytes), `"expires"`)
+
+			_, e := parseTestCredential(t, vcBytes, WithDataIntegrityVerifier(verifier))
+			require.NoError(t, e)
+		})
+	})
+
+	t.Run("presentation", func(t *testing.T) {
+		vp, e := newTestPresentation(t, []byte(validPresentation), WithPresDisabledProofCheck())
+		require.NoError(t, e)
+
+		e = vp.AddDataIntegrityProof(signContext, signer)
+		require.NoError(t, e)
+
+		vpBytes, e := vp.MarshalJSON()
+		require.NoError(t, e)
+
+		_, e = newTestPresentation(t, vpBytes,
+			WithPresDataIntegrityVerifier(verifier),
+			WithPresExpectedDataIntegrityFields(assertionMethod, "mock-domain", "mock-challenge"),
+		)
+		require.NoError(t, e)
+
+		t.Run("fail if not provided verifier", func(t *testing.T) {
+			_, e = newTestPresentation(t, vpBytes)
+			require.Error(t, e)
+			require.Contains(t, e.Error(), "needs data integrity verifier")
+		})
+	})
+
+	t.Run("presentation with authentication purpose", func(t *testing.T) {
+		authResolver := resolveFunc(func(id string) (*did.DocResolution, error) {
+			return makeMockDIDResolution(signingDID, vm, did.Authentication), nil
+		})
+
+		authSigner, e := dataintegrity.NewSigner(&dataintegrity.Options{
+			DIDResolver: authResolver,
+		}, signerSuite)
+		require.NoError(t, e)
+
+		authVerifier, e := dataintegrity.NewVerifier(&dataintegrity.Options{
+			DIDResolver: authResolver,
+		}, verifySuite)
+		require.NoError(t, e)
+
+		vp, e := newTestPresentation(t, []byte(validPresentation), WithPresDisabledProofCheck())
+		require.NoError(t, e)
+
+		authContext := &DataIntegrityProofContext{
+			SigningKeyID: signingDID + vmID,
+			CryptoSuite:  ecdsa2019.SuiteType,
+			Domain:       "mock-domain",
+			Challenge:    "mock-challenge",
+		}
+
+		e = vp.AddDataIntegrityProof(authContext, authSigner)
+		require.NoError(t, e)
+		require.Equal(t, authentication, authContext.ProofPurpose)
+
+		vpBytes, e := vp.MarshalJSON()
+		require.NoError(t, e)
+
+		// The expected purpose of presentation proofs defaults to authentication.
+		_, e = newTestPresentation(t, vpBytes, WithPresDataIntegrityVerifier(authVerifier))
+		require.NoError(t, e)
+
+		_, e = newTestPresentation(t, vpBytes,
+			WithPresDataIntegrityVerifier(authVerifier),
+			WithPresExpectedDataIntegrityFields(authentication, "mock-domain", "mock-challenge"),
+		)
+		require.NoError(t, e)
+
+		_, e = newTestPresentation(t, vpBytes,
+			WithPresDataIntegrityVerifier(authVerifier),
+			WithPresExpectedDataIntegrityFields(authentication, "", "mock-challenge"),
+			WithPresAcceptedDomains("blue.example.com", "mock-domain"),
+		)
+		require.NoError(t, e)
+
+		_, e = newTestPresentation(t, vpBytes,
+			WithPresDataIntegrityVerifier(authVerifier),
+			WithPresExpectedDataIntegrityFields(authentication, "mock-domain", "mock-challenge"),
+			WithPresAcceptedDomains("blue.example.com", "green.example.com"),
+		)
+
+		var diErr *DataIntegrityError
+
+		require.ErrorAs(t, e, &diErr)
+		require.Equal(t, ErrCodeDomainMismatch, diErr.Code)
+		require.ErrorContains(t, e, `expected one of ["blue.example.com" "green.example.com"], got "mock-domain"`)
+
+		_, e = newTestPresentation(t, vpBytes,
+			WithPresDataIntegrityVerifier(authVerifier),
+			WithPresExpectedDataIntegrityFields(authentication, "mock-domain", "other-challenge"),
+		)
+
+		require.ErrorAs(t, e, &diErr)
+		require.Equal(t, ErrCodeChallengeMismatch, diErr.Code)
+		require.ErrorContains(t, e, `expected "other-challenge", got "mock-challenge"`)
+
+		for _, context := range []*DataIntegrityProofContext{
+			{SigningKeyID: signingDID + vmID, CryptoSuite: ecdsa2019.SuiteType, Domain: "mock-domain"},
+			{SigningKeyID: signingDID + vmID, CryptoSuite: ecdsa2019.SuiteType, Challenge: "mock-challenge"},
+			{
+				SigningKeyID: signingDID + vmID,
+				CryptoSuite:  ecdsa2019.SuiteType,
+				ProofPurpose: authentication,
+			},
+		} {
+			e = vp.AddDataIntegrityProof(context, authSigner)
+			require.ErrorContains(t, e, "authentication proof purpose requires challenge and domain")
+		}
+
+		vc, e := parseTestCredential(t, []byte(dataIntegrityTestCredential), WithDisabledProofCheck())
+		require.NoError(t, e)
+
+		e = vc.AddDataIntegrityProof(&DataIntegrityProofContext{
+			SigningKeyID: signingDID + vmID,
+			CryptoSuite:  ecdsa2019.SuiteType,
+			ProofPurpose: authentication,
+		}, authSigner)
+		require.ErrorContains(t, e, "authentication proof purpose requires challenge and domain")
+
+		t.Run("auto challenge", func(t *testing.T) {
+			autoVP, e := newTestPresentation(t, []byte(validPresentation), WithPresDisabledProofCheck())
+			require.NoError(t, e)
+
+			autoContext := &DataIntegrityProofContext{
+				SigningKeyID: signingDID + vmID,
+				CryptoSuite:  ecdsa2019.SuiteType,
+				Domain:       "mock-domain",
+			}
+
+			var challenge string
+
+			e = autoVP.AddDataIntegrityProof(autoContext, authSigner, WithAutoChallenge(&challenge),
+				WithChallengeReader(bytes.NewReader(bytes.Repeat([]byte{0xfb}, 16))))
+			require.NoError(t, e)
+			require.Equal(t, "-_v7-_v7-_v7-_v7-_v7-w", challenge)
+			require.Equal(t, challenge, autoVP.DataIntegrityProofs()[0].Challenge)
+			require.Empty(t, autoContext.Challenge)
+
+			vpBytes, e := autoVP.MarshalJSON()
+			require.NoError(t, e)
+
+			_, e = newTestPresentation(t, vpBytes,
+				WithPresDataIntegrityVerifier(authVerifier),
+				WithPresExpectedDataIntegrityFields(authentication, "mock-domain", challenge),
+			)
+			require.NoError(t, e)
+
+			// A reused context gets a new challenge.
+			var reusedChallenge string
+
+			e = autoVP.AddDataIntegrityProof(autoContext, authSigner, WithAutoChallenge(&reusedChallenge))
+			require.NoError(t, e)
+			require.Len(t, reusedChallenge, 22)
+			require.NotEqual(t, challenge, reusedChallenge)
+			require.Equal(t, reusedChallenge, autoVP.DataIntegrityProofs()[1].Challenge)
+			require.Empty(t, autoContext.Challenge)
+
+			// A given challenge is kept.
+			givenContext := &DataIntegrityProofContext{
+				SigningKeyID: signingDID + vmID,
+				CryptoSuite:  ecdsa2019.SuiteType,
+				Domain:       "mock-domain",
+				Challenge:    "mock-challenge",
+			}
+
+			var givenChallenge string
+
+			e = autoVP.AddDataIntegrityProof(givenContext, authSigner, WithAutoChallenge(&givenChallenge))
+			require.NoError(t, e)
+			require.Equal(t, "mock-challenge", givenChallenge)
+			require.Equal(t, "mock-challenge", autoVP.DataIntegrityProofs()[2].Challenge)
+
+			e = autoVP.AddDataIntegrityProof(&DataIntegrityProofContext{
+				SigningKeyID: signingDID + vmID,
+				CryptoSuite:  ecdsa2019.SuiteType,
+				Domain:       "mock-domain",
+			}, authSigner, WithAutoChallenge(nil))
+			require.NoError(t, e)
+			require.Len(t, autoVP.DataIntegrityProofs()[3].Challenge, 22)
+
+			e = autoVP.AddDataIntegrityProof(&DataIntegrityProofContext{
+				SigningKeyID: signingDID + vmID,
+				CryptoSuite:  ecdsa2019.SuiteType,
+				Domain:       "mock-domain",
+			}, authSigner, WithAutoChallenge(nil), WithChallengeReader(bytes.NewReader(nil)))
+			require.ErrorContains(t, e, "generate challenge")
+		})
+	})
+
+	t.Run("did document", func(t *testing.T) {
+		didDoc, e := did.ParseDocument(validDoc)
+		require.NoError(t, e)
+
+		signedDoc, e := AddDIDDataIntegrityProof(didDoc, signContext, signer)
+		require.NoError(t, e)
+
+		require.Len(t, signedDoc.Proof, 1)
+		require.Equal(t, models.DataIntegrityProof, signedDoc.Proof[0].Type)
+		require.Equal(t, ecdsa2019.SuiteType, signedDoc.Proof[0].CryptoSuite)
+		require.Empty(t, signedDoc.Proof[0].JWS)
+		require.NotEmpty(t, signedDoc.Proof[0].ProofValue)
+
+		e = VerifyDIDProof(signedDoc,
+			WithDIDDataIntegrityVerifier(verifier),
+			WithDIDExpectedDataIntegrityFields(assertionMethod, "mock-domain", "mock-challenge"),
+		)
+		require.NoError(t, e)
+	})
+
+	t.Run("single proof as object or array", func(t *testing.T) {
+		// proofAsArray returns the document with its single proof object wrapped into an array.
+		proofAsArray := func(t *testing.T, docBytes []byte) []byte {
+			t.Helper()
+
+			var doc map[string]json.RawMessage
+			require.NoError(t, json.Unmarshal(docBytes, &doc))
+			require.True(t, bytes.HasPrefix(doc["proof"], []byte("{")), "single proof is serialized as an object")
+
+			arrayBytes, e := sjson.SetRawBytes(docBytes, "proof", append(append([]byte("["), doc["proof"]...), ']'))
+			require.NoError(t, e)
+
+			return arrayBytes
+		}
+
+		t.Run("credential", func(t *testing.T) {
+			vc, e := parseTestCredential(t, []byte(dataIntegrityTestCredential), WithDisabledProofCheck())
+			require.NoError(t, e)
+
+			require.NoError(t, vc.AddDataIntegrityProof(signContext, signer))
+
+			objectBytes, e := vc.MarshalJSON()
+			require.NoError(t, e)
+
+			for _, vcBytes := range [][]byte{objectBytes, proofAsArray(t, objectBytes)} {
+				parsedVC, e := parseTestCredential(t, vcBytes,
+					WithDataIntegrityVerifier(verifier),
+					WithExpectedDataIntegrityFields(assertionMethod, "mock-domain", "mock-challenge"),
+				)
+				require.NoError(t, e)
+				require.Len(t, parsedVC.Proofs(), 1)
+
+				reserialized, e := parsedVC.MarshalJSON()
+				require.NoError(t, e)
+				require.JSONEq(t, string(objectBytes), string(reserialized))
+			}
+		})
+
+		t.Run("presentation", func(t *testing.T) {
+			vp, e := newTestPresentation(t, []byte(validPresentation), WithPresDisabledProofCheck())
+			require.NoError(t, e)
+
+			require.NoError(t, vp.AddDataIntegrityProof(signContext, signer))
+
+			objectBytes, e := vp.MarshalJSON()
+			require.NoError(t, e)
+
+			for _, vpBytes := range [][]byte{objectBytes, proofAsArray(t, objectBytes)} {
+				parsedVP, e := newTestPresentation(t, vpBytes,
+					WithPresDataIntegrityVerifier(verifier),
+					WithPresExpectedDataIntegrityFields(assertionMethod, "mock-domain", "mock-challenge"),
+				)
+				require.NoError(t, e)
+				require.Len(t, parsedVP.Proofs, 1)
+
+				reserialized, e := parsedVP.MarshalJSON()
+				require.NoError(t, e)
+				require.JSONEq(t, string(objectBytes), string(reserialized))
+			}
+		})
+	})
+
+	t.Run("proof accessors", func(t *testing.T) {
+		zone := time.FixedZone("IST", 5*60*60+30*60)
+		created := time.Now().In(zone).Truncate(time.Second)
+		expires := created.Add(time.Hour).UTC()
+
+		accessorContext := &DataIntegrityProofContext{
+			SigningKeyID: signingDID + vmID,
+			CryptoSuite:  ecdsa2019.SuiteType,
+			Created:      &created,
+			Expires:      &expires,
+			Domain:       "mock-domain",
+			Challenge:    "mock-challenge",
+		}
+
+		givenTimesSigner, e := dataintegrity.NewSigner((&dataintegrity.Options{
+			DIDResolver: resolver,
+		}).WithGivenProofTimes(), signerSuite)
+		require.NoError(t, e)
+
+		checkContexts := func(t *testing.T, contexts []DataIntegrityProofContext) {
+			t.Helper()
+
+			require.Len(t, contexts, 1)
+			require.Equal(t, signingDID+vmID, contexts[0].SigningKeyID)
+			require.Equal(t, assertionMethod, contexts[0].ProofPurpose)
+			require.Equal(t, ecdsa2019.SuiteType, contexts[0].CryptoSuite)
+			require.Equal(t, "mock-domain", contexts[0].Domain)
+			require.Equal(t, "mock-challenge", contexts[0].Challenge)
+			require.True(t, created.Equal(*contexts[0].Created))
+			require.Equal(t, created.Format(time.RFC3339), contexts[0].Created.Format(time.RFC3339))
+			require.True(t, expires.Equal(*contexts[0].Expires))
+			require.Equal(t, expires.Format(time.RFC3339), contexts[0].Expires.Format(time.RFC3339))
+
+			_, offset := contexts[0].Created.Zone()
+			require.Equal(t, 5*60*60+30*60, offset)
+		}
+
+		vc, e := parseTestCredential(t, []byte(dataIntegrityTestCredential), WithDisabledProofCheck())
+		require.NoError(t, e)
+		require.Empty(t, vc.DataIntegrityProofs())
+
+		e = vc.AddDataIntegrityProof(accessorContext, givenTimesSigner)
+		require.NoError(t, e)
+
+		checkContexts(t, vc.DataIntegrityProofs())
+
+		vcBytes, e := vc.MarshalJSON()
+		require.NoError(t, e)
+
+		parsedVC, e := parseTestCredential(t, vcBytes, WithDisabledProofCheck())
+		require.NoError(t, e)
+
+		checkContexts(t, parsedVC.DataIntegrityProofs())
+
+		vp, e := newTestPresentation(t, []byte(validPresentation), WithPresDisabledProofCheck())
+		require.NoError(t, e)
+
+		e = vp.AddDataIntegrityProof(accessorContext, givenTimesSigner)
+		require.NoError(t, e)
+
+		vp.Proofs = append(vp.Proofs, Proof{"type": "Ed25519Signature2018"})
+
+		checkContexts(t, vp.DataIntegrityProofs())
+	})
+
+	t.Run("failure", func(t *testing.T) {
+		t.Run("marshal json", func(t *testing.T) {
+			vc, err := CreateCredential(CredentialContents{
+				CustomContext: []interface{}{make(chan int)},
+			}, nil)
+			require.NoError(t, err)
+
+			err = vc.AddDataIntegrityProof(&DataIntegrityProofContext{}, &dataintegrity.Signer{})
+			require.Error(t, err)
+			require.Contains(t, err.Error(), "add data integrity proof to VC")
+
+			vp := &Presentation{
+				Proofs: []Proof{
+					{
+						"foo": make(chan int),
+					},
+				},
+			}
+
+			err = vp.AddDataIntegrityProof(&DataIntegrityProofContext{}, &dataintegrity.Signer{})
+			require.Error(t, err)
+			require.Contains(t, err.Error(), "add data integrity proof to VP")
+		})
+
+		t.Run("add data integrity proof", func(t *testing.T) {
+			vc := &Credential{}
+
+			err := vc.AddDataIntegrityProof(&DataIntegrityProofContext{}, &dataintegrity.Signer{})
+			require.Error(t, err)
+			require.Contains(t, err.Error(), "unsupported cryptographic suite")
+
+			vp := &Presentation{}
+
+			err = vp.AddDataIntegrityProof(&DataIntegrityProofContext{
+				Created:   &time.Time{},
+				Domain:    "mock-domain",
+				Challenge: "mock-challenge",
+			}, &dataintegrity.Signer{})
+			require.Error(t, err)
+			require.Contains(t, err.Error(), "unsupported cryptographic suite")
+		})
+
+		t.Run("unsupported proof purpose", func(t *testing.T) {
+			vc, err := parseTestCredential(t, []byte(dataIntegrityTestCredential), WithDisabledProofCheck())
+			require.NoError(t, err)
+
+			err = vc.AddDataIntegrityProof(&DataIntegrityProofContext{
+				SigningKeyID: signingDID + vmID,
+				ProofPurpose: "keyAgreement",
+				CryptoSuite:  ecdsa2019.SuiteType,
+			}, signer)
+			require.ErrorIs(t, err, dataintegrity.ErrUnsupportedPurpose)
+			require.Empty(t, vc.Proofs())
+		})
+	})
+}
+
+func Test_DataIntegrity_MultipleProofs(t *testing.T) {
+	kmsCrypto := kmscryptoutil.LocalKMSCrypto(t)
+
+	docLoader := createTestDocumentLoader(t)
+
+	const signingDID = "did:foo:bar"
+
+	var verifications []did.Verification
+
+	for _, vmID := range []string{"#key-1", "#key-2"} {
+		key, err := kmsCrypto.Create(kmsapi.ECDSAP256IEEEP1363)
+		require.NoError(t, err)
+
+		vm, err := did.NewVerificationMethodFromJWK(signingDID+vmID, "JsonWebKey2020", signingDID, key)
+		require.NoError(t, err)
+
+		verifications = append(verifications, did.Verification{
+			VerificationMethod: *vm,
+			Relationship:       did.AssertionMethod,
+		})
+	}
+
+	resolver := resolveFunc(func(id string) (*did.DocResolution, error) {
+		return &did.DocResolution{
+			DIDDocument: &did.Doc{
+				ID:              signingDID,
+				AssertionMethod: verifications,
+			},
+		}, nil
+	})
+
+	signer, err := dataintegrity.NewSigner(&dataintegrity.Options{
+		DIDResolver: resolver,
+	}, ecdsa2019.NewSignerInitializer(&ecdsa2019.SignerInitializerOptions{
+		SignerGetter:     ecdsa2019.WithKMSCryptoWrapper(kmsCrypto),
+		LDDocumentLoader: docLoader,
+	}))
+	require.NoError(t, err)
+
+	verifier, err := dataintegrity.NewVerifier(&dataintegrity.Options{
+		DIDResolver: resolver,
+	}, ecdsa2019.NewVerifierInitializer(&ecdsa2019.VerifierInitializerOptions{
+		LDDocumentLoader: docLoader,
+	}))
+	require.NoError(t, err)
+
+	vc, err := parseTestCredential(t, []byte(dataIntegrityTestCredential), WithDisabledProofCheck())
+	require.NoError(t, err)
+
+	for _, vmID := range []string{"#key-1", "#key-2"} {
+		err = vc.AddDataIntegrityProof(&DataIntegrityProofContext{
+			SigningKeyID: signingDID + vmID,
+			CryptoSuite:  ecdsa2019.SuiteType,
+		}, signer)
+		require.NoError(t, err)
+	}
+
+	require.Len(t, vc.Proofs(), 2)
+
+	vcBytes, err := vc.MarshalJSON()
+	require.NoError(t, err)
+
+	t.Run("all proofs verify", func(t *testing.T) {
+		_, e := parseTestCredential(t, vcBytes, WithDataIntegrityVerifier(verifier))
+		require.NoError(t, e)
+
+		_, e = parseTestCredential(t, vcBytes, WithDataIntegrityVerifier(verifier),
+			WithDataIntegrityProofMatching(AtLeastOneProofMustVerify))
+		require.NoError(t, e)
+	})
+
+	t.Run("one proof fails", func(t *testing.T) {
+		tamperedProof := Proof{}
+
+		for k, v := range vc.Proofs()[1] {
+			tamperedProof[k] = v
+		}
+
+		tamperedProof["proofValue"] = vc.Proofs()[0]["proofValue"]
+
+		raw := vc.ToRawJSON()
+		raw[jsonFldLDProof] = proofsToRaw([]Proof{vc.Proofs()[0], tamperedProof})
+
+		tamperedBytes, e := json.Marshal(raw)
+		require.NoError(t, e)
+
+		_, e = parseTestCredential(t, tamperedBytes, WithDataIntegrityVerifier(verifier))
+		require.ErrorContains(t, e, "data integrity proof [1]")
+		require.NotContains(t, e.Error(), "data integrity proof [0]")
+
+		var diErr *DataIntegrityError
+
+		require.ErrorAs(t, e, &diErr)
+		require.Equal(t, ErrCodeSignatureInvalid, diErr.Code)
+
+		_, e = parseTestCredential(t, tamperedBytes, WithDataIntegrityVerifier(verifier),
+			WithDataIntegrityProofMatching(AtLeastOneProofMustVerify))
+		require.NoError(t, e)
+	})
+
+	t.Run("proof chain", func(t *testing.T) {
+		chainVC, e := parseTestCredential(t, []byte(dataIntegrityTestCredential), WithDisabledProofCheck())
+		require.NoError(t, e)
+
+		e = chainVC.AddDataIntegrityProof(&DataIntegrityProofContext{
+			SigningKeyID: signingDID + "#key-1",
+			CryptoSuite:  ecdsa2019.SuiteType,
+			ProofID:      "urn:uuid:proof-1",
+		}, signer)
+		require.NoError(t, e)
+
+		e = chainVC.AddDataIntegrityProof(&DataIntegrityProofContext{
+			SigningKeyID:  signingDID + "#key-2",
+			CryptoSuite:   ecdsa2019.SuiteType,
+			ProofID:       "urn:uuid:proof-2",
+			PreviousProof: "urn:uuid:proof-1",
+		}, signer)
+		require.NoError(t, e)
+
+		contexts := chainVC.DataIntegrityProofs()
+		require.Len(t, contexts, 2)
+		require.Equal(t, "urn:uuid:proof-1", contexts[0].ProofID)
+		require.Empty(t, contexts[0].PreviousProof)
+		require.Equal(t, "urn:uuid:proof-2", contexts[1].ProofID)
+		require.Equal(t, "urn:uuid:proof-1", contexts[1].PreviousProof)
+
+		chainBytes, e := chainVC.MarshalJSON()
+		require.NoError(t, e)
+
+		_, e = parseTestCredential(t, chainBytes, WithDataIntegrityVerifier(verifier))
+		require.NoError(t, e)
+
+		t.Run("previous proof modified", func(t *testing.T) {
+			modifiedProof := Proof{}
+
+			for k, v := range chainVC.Proofs()[0] {
+				modifiedProof[k] = v
+			}
+
+			modifiedProof["created"] = "2020-01-01T00:00:00Z"
+
+			raw := chainVC.ToRawJSON()
+			raw[jsonFldLDProof] = proofsToRaw([]Proof{modifiedProof, chainVC.Proofs()[1]})
+
+			modifiedBytes, err := json.Marshal(raw)
+			require.NoError(t, err)
+
+			// The chained proof signs over the previous proof, so neither verifies.
+			_, err = parseTestCredential(t, modifiedBytes, WithDataIntegrityVerifier(verifier),
+				WithDataIntegrityProofMatching(AtLeastOneProofMustVerify))
+			require.ErrorContains(t, err, "data integrity proof [0]")
+			require.ErrorContains(t, err, "data integrity proof [1]")
+		})
+
+		t.Run("previous proof missing", func(t *testing.T) {
+			raw := chainVC.ToRawJSON()
+			raw[jsonFldLDProof] = proofsToRaw(chainVC.Proofs()[1:])
+
+			missingBytes, err := json.Marshal(raw)
+			require.NoError(t, err)
+
+			_, err = parseTestCredential(t, missingBytes, WithDataIntegrityVerifier(verifier))
+
+			var diErr *DataIntegrityError
+
+			require.ErrorAs(t, err, &diErr)
+			require.Equal(t, ErrCodeInvalidProofChain, diErr.Code)
+		})
+
+		t.Run("proof structure", func(t *testing.T) {
+			setVC, err := parseTestCredential(t, vcBytes, WithDisabledProofCheck())
+			require.NoError(t, err)
+
+			require.Equal(t, models.ProofChain, DetectProofStructure(chainVC.Proofs()))
+			require.Equal(t, models.ProofSet, DetectProofStructure(setVC.Proofs()))
+
+			_, err = parseTestCredential(t, chainBytes, WithDataIntegrityVerifier(verifier),
+				WithDataIntegrityProofStructure(models.ProofChain))
+			require.NoError(t, err)
+
+			_, err = parseTestCredential(t, vcBytes, WithDataIntegrityVerifier(verifier),
+				WithDataIntegrityProofStructure(models.ProofSet))
+			require.NoError(t, err)
+
+			var diErr *DataIntegrityError
+
+			_, err = parseTestCredential(t, chainBytes, WithDataIntegrityVerifier(verifier),
+				WithDataIntegrityProofStructure(models.ProofSet))
+			require.ErrorAs(t, err, &diErr)
+			require.Equal(t, ErrCodeInvalidProofChain, diErr.Code)
+			require.ErrorContains(t, err, "of proof set is chained to previous proof")
+
+			_, err = parseTestCredential(t, vcBytes, WithDataIntegrityVerifier(verifier),
+				WithDataIntegrityProofStructure(models.ProofChain),
+				WithDataIntegrityProofMatching(AtLeastOneProofMustVerify))
+			require.ErrorAs(t, err, &diErr)
+			require.Equal(t, ErrCodeInvalidProofChain, diErr.Code)
+
+			// The chain is well-formed, but its chained proof doesn't verify.
+			tamperedProof := Proof{}
+
+			for k, v := range chainVC.Proofs()[1] {
+				tamperedProof[k] = v
+			}
+
+			tamperedProof["created"] = "2020-01-01T00:00:00Z"
+
+			raw := chainVC.ToRawJSON()
+			raw[jsonFldLDProof] = proofsToRaw([]Proof{chainVC.Proofs()[0], tamperedProof})
+
+			tamperedBytes, err := json.Marshal(raw)
+			require.NoError(t, err)
+
+			_, err = parseTestCredential(t, tamperedBytes, WithDataIntegrityVerifier(verifier),
+				WithDataIntegrityProofStructure(models.ProofChain))
+			require.ErrorAs(t, err, &diErr)
+			require.Equal(t, ErrCodeSignatureInvalid, diErr.Code)
+		})
+
+		t.Run("sign with unknown previous proof", func(t *testing.T) {
+			err := chainVC.AddDataIntegrityProof(&DataIntegrityProofContext{
+				SigningKeyID:  signingDID + "#key-2",
+				CryptoSuite:   ecdsa2019.SuiteType,
+				PreviousProof: "urn:uuid:proof-3",
+			}, signer)
+			require.ErrorIs(t, err, dataintegrity.ErrInvalidProofChain)
+		})
+	})
+
+	t.Run("remove proof and re-sign", func(t *testing.T) {
+		rotatedVC, e := parseTestCredential(t, vcBytes, WithDataIntegrityVerifier(verifier))
+		require.NoError(t, e)
+
+		require.True(t, rotatedVC.RemoveDataIntegrityProof(signingDID+"#key-1"))
+		require.False(t, rotatedVC.RemoveDataIntegrityProof(signingDID+"#key-1"))
+		require.Len(t, rotatedVC.Proofs(), 1)
+		require.Equal(t, signingDID+"#key-2", rotatedVC.Proofs()[0]["verificationMethod"])
+
+		rotatedBytes, e := rotatedVC.MarshalJSON()
+		require.NoError(t, e)
+
+		_, e = parseTestCredential(t, rotatedBytes, WithDataIntegrityVerifier(verifier))
+		require.NoError(t, e)
+
+		require.True(t, rotatedVC.RemoveDataIntegrityProof(signingDID+"#key-2"))
+		require.Empty(t, rotatedVC.Proofs())
+		require.NotContains(t, rotatedVC.ToRawJSON(), jsonFldLDProof)
+
+		e = rotatedVC.AddDataIntegrityProof(&DataIntegrityProofContext{
+			SigningKeyID: signingDID + "#key-1",
+			CryptoSuite:  ecdsa2019.SuiteType,
+		}, signer)
+		require.NoError(t, e)
+		require.Len(t, rotatedVC.Proofs(), 1)
+
+		rotatedBytes, e = rotatedVC.MarshalJSON()
+		require.NoError(t, e)
+
+		_, e = parseTestCredential(t, rotatedBytes, WithDataIntegrityVerifier(verifier))
+		require.NoError(t, e)
+	})
+}
+
+func Test_DataIntegrity_UnknownProofFields(t *testing.T) {
+	const extensionField = `{"amount":12345678901234567890,"id":"urn:uuid:4f5e","rate":1.50,"tags":["a",null,true]}`
+
+	proof := `{
+		"type": "DataIntegrityProof",
+		"cryptosuite": "ecdsa-2019",
+		"proofPurpose": "assertionMethod",
+		"verificationMethod": "did:foo:bar#key-1",
+		"proofValue": "z58DAdFfa9SkqZMVPxAQp",
+		"previousProof": "urn:uuid:26329423-bec9-4b2e-88cb-a7c7d9dc4544",
+		"x-vendor": ` + extensionField + `
+	}`
+
+	var proofFields map[string]json.RawMessage
+
+	require.NoError(t, json.Unmarshal([]byte(proof), &proofFields))
+
+	checkProof := func(t *testing.T, docBytes []byte) {
+		t.Helper()
+
+		var doc struct {
+			Proof map[string]json.RawMessage `json:"proof"`
+		}
+
+		require.NoError(t, json.Unmarshal(docBytes, &doc))
+		require.Len(t, doc.Proof, len(proofFields))
+		require.Equal(t, `"urn:uuid:26329423-bec9-4b2e-88cb-a7c7d9dc4544"`, string(doc.Proof["previousProof"]))
+		require.Equal(t, extensionField, string(doc.Proof["x-vendor"]))
+	}
+
+	withProof := func(t *testing.T, doc string) []byte {
+		t.Helper()
+
+		var docJSON map[string]json.RawMessage
+
+		require.NoError(t, json.Unmarshal([]byte(doc), &docJSON))
+
+		docJSON["proof"] = json.RawMessage(proof)
+
+		docBytes, err := json.Marshal(docJSON)
+		require.NoError(t, err)
+
+		return docBytes
+	}
+
+	t.Run("credential", func(t *testing.T) {
+		vc, err := parseTestCredential(t, withProof(t, dataIntegrityTestCredential), WithDisabledProofCheck())
+		require.NoError(t, err)
+
+		vcBytes, err := vc.MarshalJSON()
+		require.NoError(t, err)
+
+		checkProof(t, vcBytes)
+	})
+
+	t.Run("presentation", func(t *testing.T) {
+		vp, err := newTestPresentation(t, withProof(t, validPresentation), WithPresDisabledProofCheck())
+		require.NoError(t, err)
+
+		vpBytes, err := vp.MarshalJSON()
+		require.NoError(t, err)
+
+		checkProof(t, vpBytes)
+	})
+}
+
+func Test_DataIntegrity_SelectiveDisclosure(t *testing.T) {
+	kmsCrypto := kmscryptoutil.LocalKMSCrypto(t)
+
+	docLoader := createTestDocumentLoader(t)
+
+	const signingDID = "did:foo:bar"
+
+	key, err := kmsCrypto.Create(kmsapi.ECDSAP256IEEEP1363)
+	require.NoError(t, err)
+
+	vm, err := did.NewVerificationMethodFromJWK(signingDID+"#key-1", "JsonWebKey2020", signingDID, key)
+	require.NoError(t, err)
+
+	resolver := resolveFunc(func(id string) (*did.DocResolution, error) {
+		return makeMockDIDResolution(signingDID, vm, did.AssertionMethod), nil
+	})
+
+	signer, err := dataintegrity.NewSigner(&dataintegrity.Options{
+		DIDResolver: resolver,
+	}, ecdsasd2023.NewSignerInitializer(&ecdsasd2023.SignerInitializerOptions{
+		SignerGetter:     ecdsasd2023.WithKMSCryptoWrapper(kmsCrypto),
+		LDDocumentLoader: docLoader,
+	}))
+	require.NoError(t, err)
+
+	verifier, err := dataintegrity.NewVerifier(&dataintegrity.Options{
+		DIDResolver: resolver,
+	}, ecdsasd2023.NewVerifierInitializer(&ecdsasd2023.VerifierInitializerOptions{
+		LDDocumentLoader: docLoader,
+	}))
+	require.NoError(t, err)
+
+	deriver := ecdsasd2023.NewDeriverInitializer(&ecdsasd2023.DeriverInitializerOptions{
+		LDDocumentLoader: docLoader,
+	})
+
+	vc, err := parseTestCredential(t, []byte(dataIntegrityTestCredential), WithDisabledProofCheck())
+	require.NoError(t, err)
+
+	err = vc.AddDataIntegrityProof(&DataIntegrityProofContext{
+		SigningKeyID:      signingDID + "#key-1",
+		CryptoSuite:       ecdsasd2023.SuiteType,
+		MandatoryPointers: []string{"/issuer", "/issuanceDate"},
+	}, signer)
+	require.NoError(t, err)
+
+	t.Run("success", func(t *testing.T) {
+		derived, e := vc.DeriveDataIntegrityProof([]string{"/credentialSubject/degree"}, deriver,
+			WithJSONLDDocumentLoader(docLoader))
+		require.NoError(t, e)
+		require.Len(t, derived.Proofs(), 1)
+		require.NotEqual(t, vc.Proofs()[0]["proofValue"], derived.Proofs()[0]["proofValue"])
+
+		subject := derived.ToRawJSON()["credentialSubject"]
+		require.Equal(t, map[string]interface{}{
+			"id":     "did:example:ebfeb1f712ebc6f1c276e12ec21",
+			"degree": map[string]interface{}{"type": "BachelorDegree"},
+		}, subject)
+
+		derivedBytes, e := derived.MarshalJSON()
+		require.NoError(t, e)
+
+		_, e = parseTestCredential(t, derivedBytes, WithDataIntegrityVerifier(verifier))
+		require.NoError(t, e)
+	})
+
+	t.Run("success with disclosure frame", func(t *testing.T) {
+		frame, e := NewDisclosureFrame(vc, []string{"$.credentialSubject.degree.type", "$.credentialSubject['name']"},
+			FrameMandatoryPointers("/issuer", "/issuanceDate"))
+		require.NoError(t, e)
+
+		derived, e := vc.DeriveDataIntegrityProof(frame.Pointers(), deriver, WithJSONLDDocumentLoader(docLoader))
+		require.NoError(t, e)
+
+		subject := derived.ToRawJSON()["credentialSubject"]
+		require.Equal(t, map[string]interface{}{
+			"id":     "did:example:ebfeb1f712ebc6f1c276e12ec21",
+			"degree": map[string]interface{}{"type": "BachelorDegree"},
+			"name":   "Jayden Doe",
+		}, subject)
+
+		derivedBytes, e := derived.MarshalJSON()
+		require.NoError(t, e)
+
+		_, e = parseTestCredential(t, derivedBytes, WithDataIntegrityVerifier(verifier))
+		require.NoError(t, e)
+	})
+
+	t.Run("inline context", func(t *testing.T) {
+		vcJSON, e := sjson.Set(dataIntegrityTestCredential, "@context.-1", map[string]interface{}{
+			"favoriteColor": "https://example.com/vocab#favoriteColor",
+		})
+		require.NoError(t, e)
+
+		vcJSON, e = sjson.Set(vcJSON, "credentialSubject.favoriteColor", "blue")
+		require.NoError(t, e)
+
+		inlineVC, e := parseTestCredential(t, []byte(vcJSON), WithDisabledProofCheck())
+		require.NoError(t, e)
+
+		e = inlineVC.AddDataIntegrityProof(&DataIntegrityProofContext{
+			SigningKeyID:      signingDID + "#key-1",
+			CryptoSuite:       ecdsasd2023.SuiteType,
+			MandatoryPointers: []string{"/issuer", "/issuanceDate"},
+		}, signer)
+		require.NoError(t, e)
+
+		derived, e := inlineVC.DeriveDataIntegrityProof([]string{"/credentialSubject/favoriteColor"}, deriver,
+			WithJSONLDDocumentLoader(docLoader))
+		require.NoError(t, e)
+		require.Equal(t, "blue", derived.ToRawJSON()["credentialSubject"].(map[string]interface{})["favoriteColor"])
+
+		derivedBytes, e := derived.MarshalJSON()
+		require.NoError(t, e)
+
+		_, e = parseTestCredential(t, derivedBytes, WithDataIntegrityVerifier(verifier))
+		require.NoError(t, e)
+	})
+
+	t.Run("failure", func(t *testing.T) {
+		_, e := vc.DeriveDataIntegrityProof([]string{"/credentialSubject/degree"}, nil)
+		require.ErrorContains(t, e, "deriver not defined")
+
+		_, e = vc.DeriveDataIntegrityProof([]string{"/credentialSubject/unknown"}, deriver)
+		require.ErrorContains(t, e, "derive data integrity proof")
+
+		plainVC, e := parseTestCredential(t, []byte(dataIntegrityTestCredential), WithDisabledProofCheck())
+		require.NoError(t, e)
+
+		_, e = plainVC.DeriveDataIntegrityProof([]string{"/credentialSubject/degree"}, deriver)
+		require.ErrorContains(t, e, "no data integrity proof with crypto suite [ecdsa-sd-2023]")
+	})
+}
+
+func TestDataIntegrityProofContext_Validate(t *testing.T) {
+	signer, err := dataintegrity.NewSigner(nil, ecdsa2019.NewSignerInitializer(&ecdsa2019.SignerInitializerOptions{}))
+	require.NoError(t, err)
+
+	now := time.Now()
+
+	validContext := func() *DataIntegrityProofContext {
+		return &DataIntegrityProofContext{
+			SigningKeyID: "did:foo:bar#key-1",
+			CryptoSuite:  ecdsa2019.SuiteType,
+			Created:      lo.ToPtr(now),
+			Expires:      lo.ToPtr(now.Add(time.Hour)),
+		}
+	}
+
+	t.Run("success", func(t *testing.T) {
+		require.NoError(t, validContext().Validate(signer))
+
+		context := validContext()
+		context.ProofPurpose = dataintegrity.Authentication
+		context.Created = nil
+
+		require.NoError(t, context.Validate(signer))
+
+		context = validContext()
+		context.SigningKeyID = "urn:hsm:keys#42"
+		context.PublicVerificationMethodID = "did:foo:bar#key-1"
+
+		require.NoError(t, context.Validate(signer))
+	})
+
+	tests := []struct {
+		name   string
+		modify func(context *DataIntegrityProofContext)
+		errStr string
+	}{
+		{
+			name:   "empty signing key ID",
+			modify: func(context *DataIntegrityProofContext) { context.SigningKeyID = "" },
+			errStr: "should be a DID URL with a fragment",
+		},
+		{
+			name:   "signing key ID without fragment",
+			modify: func(context *DataIntegrityProofContext) { context.SigningKeyID = "did:foo:bar" },
+			errStr: "should be a DID URL with a fragment",
+		},
+		{
+			name:   "signing key ID is not a DID",
+			modify: func(context *DataIntegrityProofContext) { context.SigningKeyID = "https://foo.bar#key-1" },
+			errStr: "should be a DID URL with a fragment",
+		},
+		{
+			name: "public verification method ID is not a DID",
+			modify: func(context *DataIntegrityProofContext) {
+				context.PublicVerificationMethodID = "https://foo.bar#key-1"
+			},
+			errStr: `public verification method ID "https://foo.bar#key-1" should be a DID URL with a fragment`,
+		},
+		{
+			name: "signing key ID without fragment, with public verification method ID",
+			modify: func(context *DataIntegrityProofContext) {
+				context.SigningKeyID = "urn:hsm:keys"
+				context.PublicVerificationMethodID = "did:foo:bar#key-1"
+			},
+			errStr: `signing key ID "urn:hsm:keys" should have a fragment`,
+		},
+		{
+			name:   "unknown crypto suite",
+			modify: func(context *DataIntegrityProofContext) { context.CryptoSuite = "foo-2024" },
+			errStr: `crypto suite "foo-2024" is not supported by the signer, ` +
+				`supported suites: [ecdsa-2019, ecdsa-jcs-2019, ecdsa-rdfc-2019]`,
+		},
+		{
+			name:   "unknown proof purpose",
+			modify: func(context *DataIntegrityProofContext) { context.ProofPurpose = "foo" },
+			errStr: `proof purpose "foo" is not supported, supported purposes: [assertionMethod, authentication,`,
+		},
+		{
+			name:   "expires before created",
+			modify: func(context *DataIntegrityProofContext) { context.Expires = lo.ToPtr(now.Add(-time.Hour)) },
+			errStr: "expires time should be after created time",
+		},
+		{
+			name:   "unsupported proof value encoding",
+			modify: func(context *DataIntegrityProofContext) { context.ProofValueEncoding = "base32" },
+			errStr: `unsupported proof value encoding "base32"`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			context := validContext()
+			tt.modify(context)
+
+			require.ErrorContains(t, context.Validate(signer), tt.errStr)
+		})
+	}
+
+	t.Run("suite of a verifier only", func(t *testing.T) {
+		_, e := dataintegrity.NewVerifier(nil, bbs2023.NewVerifierInitializer(&bbs2023.VerifierInitializerOptions{}))
+		require.NoError(t, e)
+
+		context := validContext()
+		context.CryptoSuite = bbs2023.SuiteType
+
+		require.ErrorContains(t, context.Validate(signer), `crypto suite "bbs-2023" is not supported by the signer`)
+	})
+
+	t.Run("no signer", func(t *testing.T) {
+		require.EqualError(t, validContext().Validate(nil), "data integrity signer is required")
+	})
+}
+
+//go:embed testdata/example_presentation_1_ed25519.jsonld
+var examplePresentation1Ed25519 []byte
+
+//go:embed testdata/example_presentation_2_ed25519.json
+var examplePresentation2Ed25519 []byte
+
+//go:embed testdata/example_presentation_3_ed25519.json
+var examplePresentation3Ed25519 []byte
+
+//go:embed testdata/example_presentation_4_p256.json
+var examplePresentation4P256 []byte
+
+//go:embed testdata/example_presentation_4_p384.json
+var examplePresentation4P384 []byte
+
+//go:embed testdata/context/citizenship_v2.jsonld
+var citizenshipV2Context []byte
+
+//go:embed testdata/context/citizenship_v4rc1.jsonld
+var citizenshipV4rc1Context []byte
+
+//go:embed testdata/context/imsglobal_context.jsonld
+var imsglobalContext []byte
+
+//go:embed testdata/context/lds_jws2020_v1.jsonld
+var ldsJWS2020V1Context []byte
+
+func TestWithTryAllAssertionKeys(t *testing.T) {
+	kmsCrypto := kmscryptoutil.LocalKMSCrypto(t)
+	docLoader := createTestDocumentLoader(t)
+
+	const issuerDID = "did:foo:issuer"
+
+	newKey := func() *kmsjwk.JWK {
+		key, err := kmsCrypto.Create(kmsapi.ECDSAP256IEEEP1363)
+		require.NoError(t, err)
+
+		return key
+	}
+
+	// newResolver resolves the issuer DID with the given keys listed under assertionMethod as #key-1, #key-2...
+	newResolver := func(keys ...*kmsjwk.JWK) resolveFunc {
+		return func(id string) (*did.DocResolution, error) {
+			doc := &did.Doc{ID: id}
+
+			for i, key := range keys {
+				vm, err := did.NewVerificationMethodFromJWK(fmt.Sprintf("%s#key-%d", id, i+1), "JsonWebKey2020", id, key)
+				require.NoError(t, err)
+
+				doc.AssertionMethod = append(doc.AssertionMethod, did.Verification{VerificationMethod: *vm})
+			}
+
+			return &did.DocResolution{DIDDocument: doc}, nil
+		}
+	}
+
+	previousKey := newKey()
+
+	signer, err := dataintegrity.NewSigner(&dataintegrity.Options{DIDResolver: newResolver(previousKey)},
+		ecdsa2019.NewSignerInitializer(&ecdsa2019.SignerInitializerOptions{
+			SignerGetter:     ecdsa2019.WithKMSCryptoWrapper(kmsCrypto),
+			LDDocumentLoader: docLoader,
+		}))
+	require.NoError(t, err)
+
+	vcBytes, err := sjson.SetBytes([]byte(dataIntegrityTestCredential), "issuer", issuerDID)
+	require.NoError(t, err)
+
+	vc, err := parseTestCredential(t, vcBytes, WithDisabledProofCheck())
+	require.NoError(t, err)
+
+	err = vc.AddDataIntegrityProof(&DataIntegrityProofContext{
+		SigningKeyID: issuerDID + "#key-1",
+		CryptoSuite:  ecdsa2019.SuiteType,
+	}, signer)
+	require.NoError(t, err)
+
+	vcBytes, err = vc.MarshalJSON()
+	require.NoError(t, err)
+
+	// The key of #key-1 is rotated, the previous key is listed as #key-2.
+	verifier, err := dataintegrity.NewVerifier(&dataintegrity.Options{DIDResolver: newResolver(newKey(), previousKey)},
+		ecdsa2019.NewVerifierInitializer(&ecdsa2019.VerifierInitializerOptions{
+			LDDocumentLoader: docLoader,
+		}))
+	require.NoError(t, err)
+
+	_, err = parseTestCredential(t, vcBytes, WithDataIntegrityVerifier(verifier))
+
+	var diErr *DataIntegrityError
+
+	require.ErrorAs(t, err, &diErr)
+	require.Equal(t, ErrCodeSignatureInvalid, diErr.Code)
+
+	_, err = parseTestCredential(t, vcBytes, WithDataIntegrityVerifier(verifier), WithTryAllAssertionKeys())
+	require.NoError(t, err)
+
+	_, err = parseTestCredential(t, vcBytes, WithDataIntegrityVerifier(verifier), WithTryAllAssertionKeys(),
+		WithExpectedDataIntegrityFields(authentication, "", ""))
+	require.Error(t, err)
+}
 
 func TestCanParseRDFC2022Presentation(t *testing.T) {
 	vdr := vdrpkg.New(vdrpkg.WithVDR(jwk.New()), vdrpkg.WithVDR(key.New()))
@@ -370,6 +2144,10 @@ func makeMockDIDResolution(id string, vm *did.VerificationMethod, vr did.Verific
 		doc.Authentication = ver
 	case did.AssertionMethod:
 		doc.AssertionMethod = ver
+	case did.CapabilityInvocation:
+		doc.CapabilityInvocation = ver
+	case did.CapabilityDelegation:
+		doc.CapabilityDelegation = ver
 	}
 
 	return &did.DocResolution{
@@ -7,6 +7,7 @@ package verifiable
 
 import (
 	"encoding/json"
+	"errors"
 	"fmt"
 
 	"github.com/trustbloc/did-go/doc/did"
@@ -20,25 +21,26 @@ func AddDIDDataIntegrityProof(
 	context *DataIntegrityProofContext,
 	diSigner *dataintegrity.Signer,
 ) (*did.Doc, error) {
-	didBytes, err := didDoc.JSONBytes()
-	if err != nil {
-		return nil, fmt.Errorf("decode did doc: %w", err)
+	if len(context.AdditionalProofFields) > 0 {
+		return nil, errors.New("create data integrity proof: additional proof fields are not supported by did.Doc")
 	}
 
-	var jsonLdObject map[string]interface{}
-
-	err = json.Unmarshal(didBytes, &jsonLdObject)
+	jsonLdObject, err := didToMap(didDoc)
 	if err != nil {
-		return nil, fmt.Errorf("unmarshal did doc: %w", err)
+		return nil, err
 	}
 
-	// TODO: rewrite to use json object instead bytes presentation
-	diProof, err := addDataIntegrityProof(context, didBytes, diSigner)
+	diProof, err := addDataIntegrityProof(context, jsonLdObject, diSigner, assertionMethod, nil)
 	if err != nil {
 		return nil, fmt.Errorf("create data integrity proof: %w", err)
 	}
 
-	jsonLdObject[jsonFldLDProof] = proofsToRaw(diProof)
+	existingProofs, err := parseLDProof(jsonLdObject[jsonFldLDProof])
+	if err != nil {
+		return nil, fmt.Errorf("parse did doc proofs: %w", err)
+	}
+
+	jsonLdObject[jsonFldLDProof] = proofsToRaw(append(existingProofs, diProof...))
 
 	signedDoc, err := json.Marshal(jsonLdObject)
 	if err != nil {
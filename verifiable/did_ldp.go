@@ -8,6 +8,7 @@ package verifiable
 import (
 	"encoding/json"
 	"fmt"
+	"time"
 
 	jsonld "github.com/piprate/json-gold/ld"
 	"github.com/trustbloc/did-go/doc/did"
@@ -15,6 +16,7 @@ import (
 
 	"github.com/trustbloc/vc-go/dataintegrity"
 	"github.com/trustbloc/vc-go/verifiable/lddocument"
+	"github.com/trustbloc/vc-go/vermethod"
 )
 
 type verifyDIDOpts struct {
@@ -54,6 +56,49 @@ func WithDIDExpectedDataIntegrityFields(purpose, domain, challenge string) Verif
 	}
 }
 
+// WithDIDAcceptedDomains validates that a Data Integrity proof has one of the given domains,
+// e.g. the hostnames a verifier is served on. It takes precedence over the domain
+// of WithDIDExpectedDataIntegrityFields. Empty domains mean the domain is not checked.
+func WithDIDAcceptedDomains(domains ...string) VerifyDIDOpt {
+	return func(opts *verifyDIDOpts) {
+		opts.verifyDataIntegrity.AcceptedDomains = domains
+	}
+}
+
+// WithDIDDataIntegrityProofMatching sets the policy applied when the did.Doc
+// has several Data Integrity proofs. By default, all proofs must verify.
+func WithDIDDataIntegrityProofMatching(policy DataIntegrityProofMatching) VerifyDIDOpt {
+	return func(opts *verifyDIDOpts) {
+		opts.verifyDataIntegrity.ProofMatching = policy
+	}
+}
+
+// WithDIDProofCreatedTolerance allows the created time of a Data Integrity proof to be
+// up to d in the future relative to the verifier's clock. Default is zero tolerance.
+func WithDIDProofCreatedTolerance(d time.Duration) VerifyDIDOpt {
+	return func(opts *verifyDIDOpts) {
+		opts.verifyDataIntegrity.CreatedTolerance = d
+	}
+}
+
+// WithDIDProofExpiryTolerance allows the expires time of a Data Integrity proof to be
+// up to d in the past relative to the verifier's clock. Default is zero tolerance.
+// A proof without an expires time never expires.
+func WithDIDProofExpiryTolerance(d time.Duration) VerifyDIDOpt {
+	return func(opts *verifyDIDOpts) {
+		opts.verifyDataIntegrity.ExpiresTolerance = d
+	}
+}
+
+// WithDIDDataIntegrityResolvedVerificationMethod sets the already resolved verification method
+// used to check Data Integrity proofs, which skips DID resolution. The ID of the verification
+// method must match the verificationMethod of the proofs.
+func WithDIDDataIntegrityResolvedVerificationMethod(vm *vermethod.VerificationMethod) VerifyDIDOpt {
+	return func(opts *verifyDIDOpts) {
+		opts.verifyDataIntegrity.ResolvedVerificationMethod = vm
+	}
+}
+
 // WithDIDJSONLDDocumentLoader defines a JSON-LD document loader.
 func WithDIDJSONLDDocumentLoader(documentLoader jsonld.DocumentLoader) VerifyDIDOpt {
 	return func(opts *verifyDIDOpts) {
@@ -111,7 +156,9 @@ func AddDIDLinkedDataProof(
 // VerifyDIDProof verifies proof to the did.Doc.
 func VerifyDIDProof(didDoc *did.Doc, opts ...VerifyDIDOpt) error {
 	didOpts := &verifyDIDOpts{
-		verifyDataIntegrity: &verifyDataIntegrityOpts{},
+		verifyDataIntegrity: &verifyDataIntegrityOpts{
+			UnsignedProofFields: didProofFields,
+		},
 	}
 
 	for _, opt := range opts {
@@ -134,6 +181,10 @@ func VerifyDIDProof(didDoc *did.Doc, opts ...VerifyDIDOpt) error {
 	return checkEmbeddedProof(jsonldDoc, expectedProofIssuer, embeddedProofCheckOptions)
 }
 
+// didProofFields are the members that did.Doc adds to every proof it serializes. Data Integrity
+// proofs of a did.Doc are created without them, and with no additional proof fields.
+var didProofFields = []string{"creator", "nonce"} //nolint:gochecknoglobals
+
 func didToMap(didDoc *did.Doc) (map[string]interface{}, error) {
 	didBytes, err := didDoc.JSONBytes()
 	if err != nil {
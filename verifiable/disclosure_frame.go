@@ -0,0 +1,257 @@
+/*
+Copyright Gen Digital Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package verifiable
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/samber/lo"
+
+	jsonutil "github.com/trustbloc/vc-go/util/json"
+)
+
+// ErrInvalidDisclosurePath is returned by NewDisclosureFrame for a JSONPath which is not supported,
+// doesn't resolve in the credential or targets a field which is always disclosed.
+var ErrInvalidDisclosurePath = errors.New("invalid disclosure path")
+
+// DisclosureFrame is the selection of the fields of a credential to disclose with selective
+// disclosure, given as JSONPaths and validated against the credential by NewDisclosureFrame.
+type DisclosureFrame struct {
+	pointers        []string
+	disclosureNames []string
+}
+
+type disclosureFrameOpts struct {
+	mandatoryPointers []string
+}
+
+// DisclosureFrameOption provides an option for NewDisclosureFrame.
+type DisclosureFrameOption func(opts *disclosureFrameOpts)
+
+// FrameMandatoryPointers sets the JSON pointers of the fields that are always disclosed, ie the
+// DataIntegrityProofContext.MandatoryPointers of the base proof of a Data Integrity credential.
+// NewDisclosureFrame returns an error for a path to one of them or within one of them.
+func FrameMandatoryPointers(pointers ...string) DisclosureFrameOption {
+	return func(opts *disclosureFrameOpts) {
+		opts.mandatoryPointers = append(opts.mandatoryPointers, pointers...)
+	}
+}
+
+// NewDisclosureFrame creates the DisclosureFrame of vc revealing the fields of the given JSONPaths,
+// eg "$.credentialSubject.degree.type" or "$.credentialSubject['alumniOf'][1]". Only the child and
+// index selectors are supported, not the wildcards, descendants and filters.
+//
+// The paths must resolve in the credential. For an SD-JWT credential, they are resolved with all
+// the disclosures applied, and their last name must be the claim name of a disclosure, as the other
+// claims are always disclosed. For other credentials, they must not target the mandatory fields
+// given with FrameMandatoryPointers.
+func NewDisclosureFrame(vc *Credential, paths []string, opts ...DisclosureFrameOption) (*DisclosureFrame, error) {
+	if vc == nil {
+		return nil, errors.New("credential is nil")
+	}
+
+	frameOpts := &disclosureFrameOpts{}
+
+	for _, opt := range opts {
+		opt(frameOpts)
+	}
+
+	claims, err := vc.DisclosedClaims()
+	if err != nil {
+		return nil, err
+	}
+
+	claims = jsonutil.CopyExcept(claims, jsonFldLDProof)
+
+	var sdNames map[string]bool
+
+	if vc.credentialContents.SDJWTHashAlg != nil && vc.JWTEnvelope != nil {
+		sdNames = map[string]bool{}
+
+		for _, disclosure := range vc.JWTEnvelope.SDJWTDisclosures {
+			if disclosure.Name != "" {
+				sdNames[disclosure.Name] = true
+			}
+		}
+	}
+
+	frame := &DisclosureFrame{}
+
+	for _, path := range paths {
+		segments, errPath := parseDisclosurePath(path)
+		if errPath != nil {
+			return nil, errPath
+		}
+
+		if errPath = resolveDisclosurePath(claims, segments); errPath != nil {
+			return nil, fmt.Errorf("%w: %s: %w", ErrInvalidDisclosurePath, path, errPath)
+		}
+
+		pointer := toJSONPointer(segments)
+
+		if sdNames != nil {
+			names, errNames := sdDisclosureNames(segments, sdNames)
+			if errNames != nil {
+				return nil, fmt.Errorf("%w: %s: %w", ErrInvalidDisclosurePath, path, errNames)
+			}
+
+			frame.disclosureNames = append(frame.disclosureNames, names...)
+		} else if mandatory, ok := lo.Find(frameOpts.mandatoryPointers, func(m string) bool {
+			return pointer == m || strings.HasPrefix(pointer, m+"/")
+		}); ok {
+			return nil, fmt.Errorf("%w: %s: field is always disclosed by mandatory pointer %s",
+				ErrInvalidDisclosurePath, path, mandatory)
+		}
+
+		frame.pointers = append(frame.pointers, pointer)
+	}
+
+	frame.pointers = lo.Uniq(frame.pointers)
+	frame.disclosureNames = lo.Uniq(frame.disclosureNames)
+
+	return frame, nil
+}
+
+// Pointers returns the JSON pointers of the frame, to be revealed with
+// Credential.DeriveDataIntegrityProof.
+func (f *DisclosureFrame) Pointers() []string {
+	return append([]string{}, f.pointers...)
+}
+
+// DisclosureNames returns, for an SD-JWT credential, the claim names of the disclosures of the frame,
+// including the names of the disclosed objects that contain the revealed fields, to be disclosed
+// with Credential.MarshalWithDisclosure and DiscloseGivenRequired.
+func (f *DisclosureFrame) DisclosureNames() []string {
+	return append([]string{}, f.disclosureNames...)
+}
+
+// parseDisclosurePath parses a JSONPath made of child and index selectors into its segments,
+// the names as strings and the indexes as ints.
+func parseDisclosurePath(path string) ([]interface{}, error) { // nolint:gocyclo
+	if !strings.HasPrefix(path, "$") {
+		return nil, fmt.Errorf("%w: %s: should start with $", ErrInvalidDisclosurePath, path)
+	}
+
+	var segments []interface{}
+
+	for rest := path[1:]; rest != ""; {
+		switch rest[0] {
+		case '.':
+			end := strings.IndexAny(rest[1:], ".[")
+			if end < 0 {
+				end = len(rest) - 1
+			}
+
+			name := rest[1 : end+1]
+			if name == "" || name == "*" {
+				return nil, fmt.Errorf("%w: %s: wildcard and descendant selectors are not supported",
+					ErrInvalidDisclosurePath, path)
+			}
+
+			segments = append(segments, name)
+			rest = rest[end+1:]
+		case '[':
+			end := strings.Index(rest, "]")
+			if end < 0 {
+				return nil, fmt.Errorf("%w: %s: unclosed bracket", ErrInvalidDisclosurePath, path)
+			}
+
+			selector := rest[1:end]
+
+			if len(selector) >= 2 && (selector[0] == '\'' || selector[0] == '"') &&
+				selector[len(selector)-1] == selector[0] {
+				segments = append(segments, selector[1:len(selector)-1])
+			} else {
+				index, err := strconv.Atoi(selector)
+				if err != nil || index < 0 {
+					return nil, fmt.Errorf("%w: %s: unsupported selector [%s]", ErrInvalidDisclosurePath, path, selector)
+				}
+
+				segments = append(segments, index)
+			}
+
+			rest = rest[end+1:]
+		default:
+			return nil, fmt.Errorf("%w: %s: unexpected %q", ErrInvalidDisclosurePath, path, rest[0])
+		}
+	}
+
+	if len(segments) == 0 {
+		return nil, fmt.Errorf("%w: %s: selects the whole credential", ErrInvalidDisclosurePath, path)
+	}
+
+	return segments, nil
+}
+
+func resolveDisclosurePath(doc interface{}, segments []interface{}) error {
+	for i, segment := range segments {
+		switch s := segment.(type) {
+		case string:
+			obj, ok := doc.(map[string]interface{})
+			if !ok {
+				return fmt.Errorf("%s is not an object", toJSONPointer(segments[:i]))
+			}
+
+			if doc, ok = obj[s]; !ok {
+				return fmt.Errorf("%s is not found", toJSONPointer(segments[:i+1]))
+			}
+		case int:
+			arr, ok := doc.([]interface{})
+			if !ok {
+				return fmt.Errorf("%s is not an array", toJSONPointer(segments[:i]))
+			}
+
+			if s >= len(arr) {
+				return fmt.Errorf("%s is out of range", toJSONPointer(segments[:i+1]))
+			}
+
+			doc = arr[s]
+		}
+	}
+
+	return nil
+}
+
+// sdDisclosureNames returns the names of the path which are the claim names of disclosures,
+// the last one being required, as a claim without disclosure is always disclosed.
+func sdDisclosureNames(segments []interface{}, sdNames map[string]bool) ([]string, error) {
+	var names []string
+
+	for _, segment := range segments {
+		if name, ok := segment.(string); ok && sdNames[name] {
+			names = append(names, name)
+		}
+	}
+
+	last, ok := segments[len(segments)-1].(string)
+	if !ok || !sdNames[last] {
+		return nil, errors.New("claim is not selectively disclosable")
+	}
+
+	return names, nil
+}
+
+// toJSONPointer returns the JSON pointer of the path segments, escaped as in RFC 6901.
+func toJSONPointer(segments []interface{}) string {
+	var sb strings.Builder
+
+	for _, segment := range segments {
+		sb.WriteString("/")
+
+		switch s := segment.(type) {
+		case string:
+			sb.WriteString(strings.ReplaceAll(strings.ReplaceAll(s, "~", "~0"), "/", "~1"))
+		case int:
+			sb.WriteString(strconv.Itoa(s))
+		}
+	}
+
+	return sb.String()
+}
@@ -0,0 +1,109 @@
+/*
+Copyright Gen Digital Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package verifiable
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/trustbloc/vc-go/sdjwt/common"
+)
+
+func TestNewDisclosureFrame(t *testing.T) {
+	vc, err := parseTestCredential(t, []byte(dataIntegrityTestCredential), WithDisabledProofCheck())
+	require.NoError(t, err)
+
+	t.Run("success", func(t *testing.T) {
+		frame, e := NewDisclosureFrame(vc, []string{
+			"$.credentialSubject.degree.type",
+			"$['credentialSubject'][\"name\"]",
+			"$.type[1]",
+			"$.credentialSubject.degree.type",
+		}, FrameMandatoryPointers("/issuer", "/issuanceDate"))
+		require.NoError(t, e)
+		require.Equal(t, []string{"/credentialSubject/degree/type", "/credentialSubject/name", "/type/1"},
+			frame.Pointers())
+		require.Empty(t, frame.DisclosureNames())
+	})
+
+	t.Run("escapes pointer", func(t *testing.T) {
+		escapedVC, e := parseTestCredential(t, []byte(dataIntegrityTestCredential), WithDisabledProofCheck())
+		require.NoError(t, e)
+
+		escapedVC.SetCustomField("a/b~c", "value")
+
+		frame, e := NewDisclosureFrame(escapedVC, []string{"$['a/b~c']"})
+		require.NoError(t, e)
+		require.Equal(t, []string{"/a~1b~0c"}, frame.Pointers())
+	})
+
+	t.Run("invalid path", func(t *testing.T) {
+		for _, path := range []string{
+			"credentialSubject.name",
+			"$",
+			"$.credentialSubject.*",
+			"$..name",
+			"$.type[-1]",
+			"$.type[?(@ == 'x')]",
+			"$.type[0",
+			"$.credentialSubject.unknown",
+			"$.type[5]",
+			"$.issuer.name",
+			"$.credentialSubject[0]",
+		} {
+			_, e := NewDisclosureFrame(vc, []string{path})
+			require.ErrorIs(t, e, ErrInvalidDisclosurePath, path)
+		}
+	})
+
+	t.Run("mandatory field", func(t *testing.T) {
+		_, e := NewDisclosureFrame(vc, []string{"$.issuanceDate"}, FrameMandatoryPointers("/issuer", "/issuanceDate"))
+		require.ErrorIs(t, e, ErrInvalidDisclosurePath)
+		require.ErrorContains(t, e, "always disclosed by mandatory pointer /issuanceDate")
+
+		_, e = NewDisclosureFrame(vc, []string{"$.credentialSubject.degree.type"},
+			FrameMandatoryPointers("/credentialSubject/degree"))
+		require.ErrorIs(t, e, ErrInvalidDisclosurePath)
+	})
+
+	t.Run("nil credential", func(t *testing.T) {
+		_, e := NewDisclosureFrame(nil, []string{"$.type"})
+		require.ErrorContains(t, e, "credential is nil")
+	})
+}
+
+func TestNewDisclosureFrame_SDJWT(t *testing.T) {
+	sourceCred, _ := createTestSDJWTCred(t, MakeSDJWTWithVersion(common.SDJWTVersionV5),
+		MakeSDJWTWithRecursiveClaimsObjects([]string{"degree"}))
+
+	vc, err := ParseCredential([]byte(sourceCred), WithDisabledProofCheck())
+	require.NoError(t, err)
+
+	t.Run("success", func(t *testing.T) {
+		frame, e := NewDisclosureFrame(vc, []string{"$.credentialSubject.degree.type"})
+		require.NoError(t, e)
+		require.Equal(t, []string{"degree", "type"}, frame.DisclosureNames())
+
+		resultCred, e := vc.MarshalWithDisclosure(DiscloseGivenRequired(frame.DisclosureNames()))
+		require.NoError(t, e)
+
+		res := common.ParseCombinedFormatForPresentation(resultCred)
+		require.Len(t, res.Disclosures, 2)
+	})
+
+	t.Run("always disclosed claim", func(t *testing.T) {
+		_, e := NewDisclosureFrame(vc, []string{"$.issuer.name"})
+		require.ErrorIs(t, e, ErrInvalidDisclosurePath)
+		require.ErrorContains(t, e, "claim is not selectively disclosable")
+	})
+
+	t.Run("unknown claim", func(t *testing.T) {
+		_, e := NewDisclosureFrame(vc, []string{"$.credentialSubject.degree.unknown"})
+		require.ErrorIs(t, e, ErrInvalidDisclosurePath)
+	})
+}
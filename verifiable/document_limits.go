@@ -0,0 +1,89 @@
+/*
+Copyright Gen Digital Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package verifiable
+
+import (
+	"errors"
+	"fmt"
+	"io"
+)
+
+// ErrDocumentTooLarge is returned by ParseCredential, ParsePresentation and StreamPresentation when
+// the document exceeds the limits set with WithMaxDocumentSize, WithPresMaxDocumentSize or
+// WithMaxEmbeddedCredentials. The returned error wraps it with the exceeded limit.
+var ErrDocumentTooLarge = errors.New("document too large")
+
+// WithMaxDocumentSize makes ParseCredential reject credentials larger than n bytes, before they
+// are decoded. A limit of zero or less means no limit, which is the default.
+func WithMaxDocumentSize(n int) CredentialOpt {
+	return func(opts *credentialOpts) {
+		opts.maxDocumentSize = n
+	}
+}
+
+// WithPresMaxDocumentSize makes ParsePresentation reject presentations larger than n bytes, before
+// they are decoded, embedded credentials included. StreamPresentation stops reading the presentation
+// once n bytes are read. A limit of zero or less means no limit, which is the default.
+func WithPresMaxDocumentSize(n int) PresentationOpt {
+	return func(opts *presentationOpts) {
+		opts.maxDocumentSize = n
+	}
+}
+
+// WithMaxEmbeddedCredentials makes ParsePresentation and StreamPresentation reject presentations with
+// more than n embedded credentials, before the credentials are decoded. A limit of zero or less means
+// no limit, which is the default.
+func WithMaxEmbeddedCredentials(n int) PresentationOpt {
+	return func(opts *presentationOpts) {
+		opts.maxEmbeddedCredentials = n
+	}
+}
+
+func checkDocumentSize(data []byte, maxSize int) error {
+	if maxSize > 0 && len(data) > maxSize {
+		return fmt.Errorf("%w: %d bytes exceed the limit of %d bytes", ErrDocumentTooLarge, len(data), maxSize)
+	}
+
+	return nil
+}
+
+func checkEmbeddedCredentialsCount(count, maxCount int) error {
+	if maxCount > 0 && count > maxCount {
+		return fmt.Errorf("%w: more than %d embedded credentials", ErrDocumentTooLarge, maxCount)
+	}
+
+	return nil
+}
+
+// sizeLimitedReader reads from r until more than limit bytes are read, it then fails
+// with ErrDocumentTooLarge.
+type sizeLimitedReader struct {
+	r     io.Reader
+	limit int
+	read  int
+}
+
+func newSizeLimitedReader(r io.Reader, limit int) io.Reader {
+	if limit <= 0 {
+		return r
+	}
+
+	// One more byte than the limit is read, to tell a document of the limit size from a larger one.
+	return &sizeLimitedReader{r: io.LimitReader(r, int64(limit)+1), limit: limit}
+}
+
+func (l *sizeLimitedReader) Read(p []byte) (int, error) {
+	n, err := l.r.Read(p)
+
+	l.read += n
+
+	if l.read > l.limit {
+		return 0, fmt.Errorf("%w: exceeds the limit of %d bytes", ErrDocumentTooLarge, l.limit)
+	}
+
+	return n, err
+}
@@ -0,0 +1,115 @@
+/*
+Copyright Gen Digital Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package verifiable
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestWithMaxDocumentSize(t *testing.T) {
+	credJSON := []byte(jwtTestCredential)
+
+	_, err := parseTestCredential(t, credJSON, WithDisabledProofCheck(), WithMaxDocumentSize(len(credJSON)))
+	require.NoError(t, err)
+
+	_, err = parseTestCredential(t, credJSON, WithDisabledProofCheck(), WithMaxDocumentSize(len(credJSON)-1))
+	require.ErrorIs(t, err, ErrDocumentTooLarge)
+	require.ErrorContains(t, err, "exceed the limit of")
+}
+
+func TestWithPresMaxDocumentSize(t *testing.T) {
+	vpJSON := []byte(streamedPresentation)
+
+	t.Run("parse presentation", func(t *testing.T) {
+		_, err := newTestPresentation(t, vpJSON, WithPresDisabledProofCheck(),
+			WithPresMaxDocumentSize(len(vpJSON)))
+		require.NoError(t, err)
+
+		_, err = newTestPresentation(t, vpJSON, WithPresDisabledProofCheck(),
+			WithPresMaxDocumentSize(len(vpJSON)-1))
+		require.ErrorIs(t, err, ErrDocumentTooLarge)
+	})
+
+	t.Run("stream presentation", func(t *testing.T) {
+		stream, err := StreamPresentation(strings.NewReader(streamedPresentation), WithPresDisabledProofCheck(),
+			WithPresMaxDocumentSize(len(vpJSON)))
+		require.NoError(t, err)
+
+		count := 0
+
+		for _, err := range stream.Credentials() {
+			require.NoError(t, err)
+
+			count++
+		}
+
+		require.Equal(t, 2, count)
+
+		// The limit is reached in the second credential.
+		limit := strings.Index(streamedPresentation, "credentials/2")
+
+		stream, err = StreamPresentation(strings.NewReader(streamedPresentation), WithPresDisabledProofCheck(),
+			WithPresMaxDocumentSize(limit))
+		require.NoError(t, err)
+
+		var errs []error
+
+		for _, err := range stream.Credentials() {
+			errs = append(errs, err)
+		}
+
+		require.NotEmpty(t, errs)
+		require.ErrorIs(t, errs[len(errs)-1], ErrDocumentTooLarge)
+
+		_, err = StreamPresentation(strings.NewReader(streamedPresentation), WithPresMaxDocumentSize(10))
+		require.ErrorIs(t, err, ErrDocumentTooLarge)
+	})
+}
+
+func TestWithMaxEmbeddedCredentials(t *testing.T) {
+	vpJSON := []byte(streamedPresentation)
+
+	t.Run("parse presentation", func(t *testing.T) {
+		vp, err := newTestPresentation(t, vpJSON, WithPresDisabledProofCheck(), WithMaxEmbeddedCredentials(2))
+		require.NoError(t, err)
+		require.Len(t, vp.Credentials(), 2)
+
+		_, err = newTestPresentation(t, vpJSON, WithPresDisabledProofCheck(), WithMaxEmbeddedCredentials(1))
+		require.ErrorIs(t, err, ErrDocumentTooLarge)
+		require.ErrorContains(t, err, "more than 1 embedded credentials")
+	})
+
+	t.Run("stream presentation", func(t *testing.T) {
+		credentials, err := StreamCredentials(strings.NewReader(streamedPresentation), WithPresDisabledProofCheck(),
+			WithMaxEmbeddedCredentials(1))
+		require.NoError(t, err)
+
+		var (
+			count int
+			errs  []error
+		)
+
+		for vc, err := range credentials {
+			if err != nil {
+				errs = append(errs, err)
+
+				continue
+			}
+
+			require.NotNil(t, vc)
+
+			count++
+		}
+
+		require.Equal(t, 1, count)
+		require.Len(t, errs, 1)
+		require.ErrorIs(t, errs[0], ErrDocumentTooLarge)
+	})
+}
@@ -10,9 +10,8 @@ import (
 	"errors"
 	"fmt"
 
-	jsonld "github.com/trustbloc/did-go/doc/ld/processor"
-
 	"github.com/trustbloc/vc-go/dataintegrity/models"
+	jsonutil "github.com/trustbloc/vc-go/util/json"
 	"github.com/trustbloc/vc-go/verifiable/lddocument"
 )
 
@@ -56,22 +55,29 @@ func checkEmbeddedProof(jsonldDoc map[string]interface{}, expectedProofIssuer *s
 	}
 
 	if len(opts.externalContext) > 0 {
-		// Use external contexts for check of the linked data proofs to enrich JSON-LD context vocabulary.
-		jsonldDoc["@context"] = jsonld.AppendExternalContexts(jsonldDoc["@context"], opts.externalContext...)
+		// Use external contexts for check of the linked data proofs to enrich JSON-LD context vocabulary,
+		// on a copy, as the document may be the JSON of a credential embedded in a presentation.
+		jsonldDoc = jsonutil.ShallowCopyObj(jsonldDoc)
+		jsonldDoc["@context"] = appendExternalContexts(jsonldDoc["@context"], opts.externalContext...)
 	}
 
-	if len(proofs) > 0 {
-		typeStr, ok := proofs[0]["type"]
-		if ok && typeStr == models.DataIntegrityProof {
-			var docBytes []byte
+	dataIntegrityProofs := 0
 
-			docBytes, err = json.Marshal(jsonldDoc)
-			if err != nil {
-				return err
-			}
+	for _, proof := range proofs {
+		if proof["type"] == models.DataIntegrityProof {
+			dataIntegrityProofs++
+		}
+	}
 
-			return checkDataIntegrityProof(docBytes, opts.dataIntegrityOpts)
+	if dataIntegrityProofs > 0 {
+		if dataIntegrityProofs != len(proofs) {
+			// The Data Integrity and the Linked Data proofs are checked by different verifiers, each of which
+			// checks all the proofs of the document, so a proof set mixing both is not supported.
+			return fmt.Errorf("check embedded proof: mixed proof set of %s and other proof types is not supported",
+				models.DataIntegrityProof)
 		}
+
+		return checkDataIntegrityProofs(jsonldDoc, proofs, opts.dataIntegrityOpts)
 	}
 
 	if opts.proofChecker == nil {
@@ -119,6 +119,34 @@ func Test_checkEmbeddedProofBytes(t *testing.T) {
 		r.EqualError(err, "check embedded proof: invalid proof type")
 	})
 
+	t.Run("error on mixed proof set", func(t *testing.T) {
+		docWithMixedProofs := `
+{
+  "@context": "https://www.w3.org/2018/credentials/v1",
+  "proof": [
+    {
+      "created": "2020-04-17T16:54:24+03:00",
+      "proofPurpose": "assertionMethod",
+      "proofValue": "Lxx69YOV08JglTEmAmdVZgsJdBnCw7oWvfGNaTEKdg-_8qMVAKy1u0oTvWZuhAjTbowjuf1oRtu_1N--PA4TBg",
+      "type": "Ed25519Signature2018",
+      "verificationMethod": "did:example:123456#key1"
+    },
+    {
+      "created": "2020-04-17T16:54:24+03:00",
+      "cryptosuite": "eddsa-rdfc-2022",
+      "proofPurpose": "assertionMethod",
+      "proofValue": "z2YwC8z3ap7yx1nZYCg4L3j3ApHsF8kgPdSb5xoS1VR7vPG3F561B52hYnQF9iseabecm3ijx4K1FBTQsCZahKZme",
+      "type": "DataIntegrityProof",
+      "verificationMethod": "did:example:123456#key2"
+    }
+  ]
+}
+`
+		err := checkEmbeddedProofBytes([]byte(docWithMixedProofs), nil, defaultOpts)
+		r.EqualError(err,
+			"check embedded proof: mixed proof set of DataIntegrityProof and other proof types is not supported")
+	})
+
 	t.Run("error on not supported type of embedded proof", func(t *testing.T) {
 		docWithNotSupportedProof := `{
   "@context": "https://www.w3.org/2018/credentials/v1",
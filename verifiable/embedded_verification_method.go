@@ -0,0 +1,221 @@
+/*
+Copyright Gen Digital Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package verifiable
+
+import (
+	"bytes"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/multiformats/go-multibase"
+	"github.com/trustbloc/did-go/doc/did"
+	vdrkey "github.com/trustbloc/did-go/method/key"
+	"github.com/trustbloc/kms-go/doc/jose/jwk"
+
+	"github.com/trustbloc/vc-go/dataintegrity"
+	"github.com/trustbloc/vc-go/dataintegrity/models"
+	"github.com/trustbloc/vc-go/vermethod"
+)
+
+// jsonFldEmbeddedVerificationMethod is the proof member holding the embedded verification method, a
+// verification method object as in a DID document. It is not a term of the Data Integrity context,
+// so it is not signed over.
+const jsonFldEmbeddedVerificationMethod = "embeddedVerificationMethod"
+
+type embeddedVerificationMethodRaw struct {
+	ID                 string   `json:"id"`
+	Type               string   `json:"type"`
+	Controller         string   `json:"controller,omitempty"`
+	PublicKeyJwk       *jwk.JWK `json:"publicKeyJwk,omitempty"`
+	PublicKeyMultibase string   `json:"publicKeyMultibase,omitempty"`
+}
+
+func embeddedVerificationMethodToRaw(vm *models.VerificationMethod) JSONObject {
+	raw := JSONObject{
+		"id":   vm.ID,
+		"type": vm.Type,
+	}
+
+	if vm.Controller != "" {
+		raw["controller"] = vm.Controller
+	}
+
+	if key := vm.JSONWebKey(); key != nil {
+		raw["publicKeyJwk"] = key
+	} else {
+		// The value is kept as is, eg with the multicodec header of a Multikey.
+		raw["publicKeyMultibase"], _ = multibase.Encode(multibase.Base58BTC, vm.Value)
+	}
+
+	return raw
+}
+
+// embeddedVerificationMethod returns the verification method embedded in the last proof of ldBytes,
+// the one that is verified after the proofs it is chained to, or nil if it has none.
+func embeddedVerificationMethod(ldBytes []byte) (*vermethod.VerificationMethod, error) {
+	var doc map[string]interface{}
+
+	err := json.Unmarshal(ldBytes, &doc)
+	if err != nil {
+		// TODO update linter to use go 1.20: https://github.com/hyperledger/aries-framework-go/issues/3613
+		return nil, errors.Join(dataintegrity.ErrMalformedProof, err) // nolint:typecheck
+	}
+
+	proofs, err := parseLDProof(doc[jsonFldLDProof])
+	if err != nil || len(proofs) == 0 {
+		// TODO update linter to use go 1.20: https://github.com/hyperledger/aries-framework-go/issues/3613
+		return nil, errors.Join(dataintegrity.ErrMalformedProof, err) // nolint:typecheck
+	}
+
+	embedded, ok := proofs[len(proofs)-1][jsonFldEmbeddedVerificationMethod]
+	if !ok {
+		return nil, nil
+	}
+
+	embeddedBytes, err := json.Marshal(embedded)
+	if err != nil {
+		return nil, err
+	}
+
+	raw := &embeddedVerificationMethodRaw{}
+
+	err = json.Unmarshal(embeddedBytes, raw)
+	if err != nil {
+		// TODO update linter to use go 1.20: https://github.com/hyperledger/aries-framework-go/issues/3613
+		return nil, errors.Join(dataintegrity.ErrMalformedProof, // nolint:typecheck
+			fmt.Errorf("embedded verification method: %w", err))
+	}
+
+	vm := &vermethod.VerificationMethod{
+		ID:   raw.ID,
+		Type: raw.Type,
+		JWK:  raw.PublicKeyJwk,
+	}
+
+	if vm.JWK == nil {
+		if raw.PublicKeyMultibase == "" {
+			// TODO update linter to use go 1.20: https://github.com/hyperledger/aries-framework-go/issues/3613
+			return nil, errors.Join(dataintegrity.ErrMalformedProof, // nolint:typecheck
+				errors.New("embedded verification method needs publicKeyJwk or publicKeyMultibase"))
+		}
+
+		_, vm.Value, err = multibase.Decode(raw.PublicKeyMultibase)
+		if err != nil {
+			// TODO update linter to use go 1.20: https://github.com/hyperledger/aries-framework-go/issues/3613
+			return nil, errors.Join(dataintegrity.ErrMalformedProof, // nolint:typecheck
+				fmt.Errorf("embedded verification method publicKeyMultibase: %w", err))
+		}
+	}
+
+	return vm, nil
+}
+
+// EmbeddedVerificationMethodTrust reports whether the verification method embedded in a Data Integrity
+// proof is trusted to be the verification method of its ID, eg because its key is pinned for this ID.
+type EmbeddedVerificationMethodTrust func(vm *vermethod.VerificationMethod) bool
+
+// multikeyPublicKeyHeaders are the multicodec headers of the Multikey public keys.
+// ref https://www.w3.org/TR/controller-document/#Multikey
+var multikeyPublicKeyHeaders = [][]byte{ //nolint:gochecknoglobals
+	{0xed, 0x01}, // ed25519-pub
+	{0x80, 0x24}, // p256-pub
+	{0x81, 0x24}, // p384-pub
+	{0x82, 0x24}, // p521-pub
+	{0xe7, 0x01}, // secp256k1-pub
+	{0xeb, 0x01}, // bls12_381-g2-pub
+}
+
+// checkEmbeddedVerificationMethod checks that the embedded verification method vm can be used to verify
+// the proof. As anyone can embed a key, the key of a did:key or did:jwk verification method must be the
+// key that its DID encodes, and the other verification methods must be trusted by trust.
+func checkEmbeddedVerificationMethod(vm *vermethod.VerificationMethod, trust EmbeddedVerificationMethodTrust) error {
+	controller, _, _ := strings.Cut(vm.ID, "#")
+
+	var (
+		resolution *did.DocResolution
+		err        error
+	)
+
+	switch {
+	case strings.HasPrefix(controller, "did:key:"):
+		resolution, err = vdrkey.New().Read(controller)
+	case strings.HasPrefix(controller, "did:jwk:"):
+		resolution, err = dataintegrity.NewJWKResolver().Resolve(controller)
+	default:
+		if trust == nil || !trust(vm) {
+			// TODO update linter to use go 1.20: https://github.com/hyperledger/aries-framework-go/issues/3613
+			return errors.Join(dataintegrity.ErrVMResolution, // nolint:typecheck
+				fmt.Errorf("embedded verification method %q is not trusted", vm.ID))
+		}
+
+		return nil
+	}
+
+	if err != nil {
+		// TODO update linter to use go 1.20: https://github.com/hyperledger/aries-framework-go/issues/3613
+		return errors.Join(dataintegrity.ErrVMResolution, err) // nolint:typecheck
+	}
+
+	for i := range resolution.DIDDocument.VerificationMethod {
+		didVM := &resolution.DIDDocument.VerificationMethod[i]
+		if didVM.ID != vm.ID {
+			continue
+		}
+
+		if sameKey(didVM.JSONWebKey(), didVM.Type, didVM.Value, vm.JWK, vm.Type, vm.Value) {
+			return nil
+		}
+
+		break
+	}
+
+	// TODO update linter to use go 1.20: https://github.com/hyperledger/aries-framework-go/issues/3613
+	return errors.Join(dataintegrity.ErrVMResolution, // nolint:typecheck
+		fmt.Errorf("embedded verification method %q is not the key of its DID", vm.ID))
+}
+
+// sameKey reports whether two verification method keys, each given as a JWK or as a public key value,
+// are the same public key.
+func sameKey(jwk1 *jwk.JWK, type1 string, value1 []byte, jwk2 *jwk.JWK, type2 string, value2 []byte) bool {
+	key1, err := comparablePublicKey(jwk1, type1, value1)
+	if err != nil {
+		return false
+	}
+
+	key2, err := comparablePublicKey(jwk2, type2, value2)
+	if err != nil {
+		return false
+	}
+
+	return len(key1) > 0 && bytes.Equal(key1, key2)
+}
+
+// comparablePublicKey returns the public key bytes of a verification method key, EC keys compressed
+// and without the multicodec header of a Multikey.
+func comparablePublicKey(key *jwk.JWK, vmType string, value []byte) ([]byte, error) {
+	if key != nil {
+		if ecKey, ok := key.Key.(*ecdsa.PublicKey); ok {
+			return elliptic.MarshalCompressed(ecKey.Curve, ecKey.X, ecKey.Y), nil
+		}
+
+		return key.PublicKeyBytes()
+	}
+
+	if vmType == "Multikey" || vmType == "Ed25519VerificationKey2020" {
+		for _, header := range multikeyPublicKeyHeaders {
+			if bytes.HasPrefix(value, header) {
+				return value[len(header):], nil
+			}
+		}
+	}
+
+	return value, nil
+}
@@ -0,0 +1,98 @@
+/*
+Copyright Gen Digital Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package verifiable
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/trustbloc/did-go/doc/did"
+	vdrapi "github.com/trustbloc/did-go/vdr/api"
+)
+
+// ErrIssuerKeyBinding is returned when the verification method of a credential proof
+// belongs to a DID other than the credential issuer and is not authorized by the issuer.
+var ErrIssuerKeyBinding = errors.New("proof verification method is not bound to the credential issuer")
+
+type didResolver interface {
+	Resolve(did string, opts ...vdrapi.DIDMethodOption) (*did.DocResolution, error)
+}
+
+// WithIssuerKeyBinding option makes the proof check fail with ErrIssuerKeyBinding if the DID of the
+// verificationMethod of an embedded proof is not the credential issuer DID, unless the verification
+// method is in the assertionMethod of the issuer DID document resolved with WithIssuerDIDResolver.
+func WithIssuerKeyBinding() CredentialOpt {
+	return func(opts *credentialOpts) {
+		opts.issuerKeyBinding = true
+	}
+}
+
+// WithIssuerDIDResolver sets the resolver of the issuer DID document used by WithIssuerKeyBinding.
+func WithIssuerDIDResolver(resolver didResolver) CredentialOpt {
+	return func(opts *credentialOpts) {
+		opts.issuerDIDResolver = resolver
+	}
+}
+
+func checkIssuerKeyBinding(proofs []Proof, issuerID string, resolver didResolver) error {
+	var issuerDoc *did.Doc
+
+	for i, proof := range proofs {
+		vmID := safeStringValue(proof["verificationMethod"])
+		if vmID == "" {
+			vmID = safeStringValue(proof["creator"])
+		}
+
+		if didFromVerificationMethod(vmID) == issuerID {
+			continue
+		}
+
+		if resolver == nil {
+			return fmt.Errorf("proof [%d]: %w: %q is not a key of %q", i, ErrIssuerKeyBinding, vmID, issuerID)
+		}
+
+		if issuerDoc == nil {
+			docResolution, err := resolver.Resolve(issuerID)
+			if err != nil {
+				return fmt.Errorf("resolve issuer DID %q: %w", issuerID, err)
+			}
+
+			issuerDoc = docResolution.DIDDocument
+		}
+
+		if !isAssertionMethod(issuerDoc, vmID) {
+			return fmt.Errorf("proof [%d]: %w: %q is not an assertion method of %q",
+				i, ErrIssuerKeyBinding, vmID, issuerID)
+		}
+	}
+
+	return nil
+}
+
+func didFromVerificationMethod(vmID string) string {
+	didPart, _, _ := strings.Cut(vmID, "#")
+
+	return didPart
+}
+
+func isAssertionMethod(doc *did.Doc, vmID string) bool {
+	for _, verifications := range doc.VerificationMethods(did.AssertionMethod) {
+		for _, verification := range verifications {
+			id := verification.VerificationMethod.ID
+			if strings.HasPrefix(id, "#") {
+				id = doc.ID + id
+			}
+
+			if id == vmID {
+				return true
+			}
+		}
+	}
+
+	return false
+}
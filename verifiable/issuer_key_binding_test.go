@@ -0,0 +1,122 @@
+/*
+Copyright Gen Digital Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package verifiable
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"github.com/trustbloc/did-go/doc/did"
+	kmsapi "github.com/trustbloc/kms-go/spi/kms"
+
+	"github.com/trustbloc/vc-go/dataintegrity"
+	"github.com/trustbloc/vc-go/dataintegrity/suite/ecdsa2019"
+	"github.com/trustbloc/vc-go/internal/testutil/kmscryptoutil"
+)
+
+func TestWithIssuerKeyBinding(t *testing.T) {
+	kmsCrypto := kmscryptoutil.LocalKMSCrypto(t)
+
+	docLoader := createTestDocumentLoader(t)
+
+	key, err := kmsCrypto.Create(kmsapi.ECDSAP256IEEEP1363)
+	require.NoError(t, err)
+
+	const (
+		signingDID = "did:foo:bar"
+		otherDID   = "did:key:z6Mkj7of2aaooXhTJvJ5oCL9ZVcAS472ZBuSjYyXDa4bWT32"
+		vmID       = "#key-1"
+	)
+
+	vm, err := did.NewVerificationMethodFromJWK(signingDID+vmID, "JsonWebKey2020", signingDID, key)
+	require.NoError(t, err)
+
+	resolver := resolveFunc(func(id string) (*did.DocResolution, error) {
+		return makeMockDIDResolution(signingDID, vm, did.AssertionMethod), nil
+	})
+
+	signer, err := dataintegrity.NewSigner(&dataintegrity.Options{
+		DIDResolver: resolver,
+	}, ecdsa2019.NewSignerInitializer(&ecdsa2019.SignerInitializerOptions{
+		SignerGetter:     ecdsa2019.WithKMSCryptoWrapper(kmsCrypto),
+		LDDocumentLoader: docLoader,
+	}))
+	require.NoError(t, err)
+
+	verifier, err := dataintegrity.NewVerifier(&dataintegrity.Options{
+		DIDResolver: resolver,
+	}, ecdsa2019.NewVerifierInitializer(&ecdsa2019.VerifierInitializerOptions{
+		LDDocumentLoader: docLoader,
+	}))
+	require.NoError(t, err)
+
+	signedVC := func(t *testing.T, issuer string) *Credential {
+		t.Helper()
+
+		vc, e := parseTestCredential(t,
+			[]byte(strings.ReplaceAll(dataIntegrityTestCredential, otherDID, issuer)), WithDisabledProofCheck())
+		require.NoError(t, e)
+
+		require.NoError(t, vc.AddDataIntegrityProof(&DataIntegrityProofContext{
+			SigningKeyID: signingDID + vmID,
+			CryptoSuite:  ecdsa2019.SuiteType,
+		}, signer))
+
+		return vc
+	}
+
+	verifyOpts := []CredentialOpt{
+		WithDataIntegrityVerifier(verifier),
+		WithJSONLDDocumentLoader(docLoader),
+		WithIssuerKeyBinding(),
+	}
+
+	t.Run("signed by issuer", func(t *testing.T) {
+		require.NoError(t, signedVC(t, signingDID).CheckProof(verifyOpts...))
+	})
+
+	t.Run("signed by other DID", func(t *testing.T) {
+		vc := signedVC(t, otherDID)
+
+		require.NoError(t, vc.CheckProof(WithDataIntegrityVerifier(verifier), WithJSONLDDocumentLoader(docLoader)))
+		require.ErrorIs(t, vc.CheckProof(verifyOpts...), ErrIssuerKeyBinding)
+	})
+
+	t.Run("authorized by issuer assertion method", func(t *testing.T) {
+		issuerResolver := resolveFunc(func(id string) (*did.DocResolution, error) {
+			require.Equal(t, otherDID, id)
+
+			return &did.DocResolution{DIDDocument: &did.Doc{
+				ID:              otherDID,
+				AssertionMethod: []did.Verification{{VerificationMethod: *vm, Relationship: did.AssertionMethod}},
+			}}, nil
+		})
+
+		err := signedVC(t, otherDID).CheckProof(append(verifyOpts, WithIssuerDIDResolver(issuerResolver))...)
+		require.NoError(t, err)
+	})
+
+	t.Run("not authorized by issuer DID document", func(t *testing.T) {
+		issuerResolver := resolveFunc(func(id string) (*did.DocResolution, error) {
+			return makeMockDIDResolution(otherDID, vm, did.Authentication), nil
+		})
+
+		err := signedVC(t, otherDID).CheckProof(append(verifyOpts, WithIssuerDIDResolver(issuerResolver))...)
+		require.ErrorIs(t, err, ErrIssuerKeyBinding)
+	})
+
+	t.Run("issuer DID resolution error", func(t *testing.T) {
+		issuerResolver := resolveFunc(func(id string) (*did.DocResolution, error) {
+			return nil, errors.New("resolve error")
+		})
+
+		err := signedVC(t, otherDID).CheckProof(append(verifyOpts, WithIssuerDIDResolver(issuerResolver))...)
+		require.ErrorContains(t, err, "resolve error")
+	})
+}
@@ -73,10 +73,6 @@ func mapJSONLDProcessorOpts(jsonldOpts *jsonldCredentialOpts) []ldprocessor.Opts
 	return processorOpts
 }
 
-type rawProof struct {
-	Proof JSONObject `json:"proof,omitempty"`
-}
-
 // addLinkedDataProof adds a new proof to the JSON-LD document (VC or VP). It returns a slice
 // of the proofs which were already present appended with a newly created proof.
 func addLinkedDataProof(context *LinkedDataProofContext, jsonld JSONObject,
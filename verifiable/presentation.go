@@ -6,11 +6,14 @@ SPDX-License-Identifier: Apache-2.0
 package verifiable
 
 import (
+	"context"
+	"crypto"
 	"encoding/hex"
 	"encoding/json"
 	"errors"
 	"fmt"
 	"strings"
+	"time"
 
 	"github.com/fxamacker/cbor/v2"
 	jsonld "github.com/piprate/json-gold/ld"
@@ -18,7 +21,9 @@ import (
 	"github.com/xeipuuv/gojsonschema"
 
 	"github.com/trustbloc/vc-go/dataintegrity"
+	"github.com/trustbloc/vc-go/dataintegrity/models"
 	jsonutil "github.com/trustbloc/vc-go/util/json"
+	"github.com/trustbloc/vc-go/vermethod"
 )
 
 const (
@@ -389,6 +394,8 @@ func WithBaseContext(ctx string) CreatePresentationOpt {
 }
 
 // MarshalJSON converts Verifiable Presentation to JSON bytes.
+// The proof field is emitted only if the presentation has proofs, so an unsigned presentation
+// is marshalled without proof, neither null nor an empty array.
 func (vp *Presentation) MarshalJSON() ([]byte, error) {
 	if IsBaseContext(vp.Context, V2ContextURI) {
 		if vp.IsJWT() {
@@ -531,6 +538,11 @@ func (vp *Presentation) raw() (rawPresentation, error) {
 	}
 
 	for cfKey, cfValue := range vp.CustomFields {
+		// The proofs are set with Proofs only.
+		if cfKey == vpFldProof {
+			continue
+		}
+
 		if _, exists := rp[cfKey]; !exists {
 			rp[cfKey] = cfValue
 		}
@@ -580,6 +592,11 @@ type presentationOpts struct {
 	jsonldCredentialOpts
 	checkHolder          bool
 	checkRelatedResource bool
+
+	skipEmbeddedCredentialVerification bool
+	collectAllErrors                   bool
+	maxDocumentSize                    int
+	maxEmbeddedCredentials             int
 }
 
 // PresentationOpt is the Verifiable Presentation decoding option.
@@ -646,9 +663,17 @@ func WithPresDataIntegrityVerifier(v *dataintegrity.Verifier) PresentationOpt {
 	}
 }
 
+// WithPresVerificationContext sets the context bounding the verification of the Data Integrity
+// proofs of the presentation and of its embedded credentials, as with WithVerificationContext.
+func WithPresVerificationContext(ctx context.Context) PresentationOpt {
+	return func(opts *presentationOpts) {
+		opts.verifyDataIntegrity.Context = ctx
+	}
+}
+
 // WithPresExpectedDataIntegrityFields validates that a Data Integrity proof has the
 // given purpose, domain, and challenge. Empty purpose means the default,
-// assertionMethod, will be expected. Empty domain and challenge will mean they
+// authentication, will be expected. Empty domain and challenge will mean they
 // are not checked.
 func WithPresExpectedDataIntegrityFields(purpose, domain, challenge string) PresentationOpt {
 	return func(opts *presentationOpts) {
@@ -658,11 +683,127 @@ func WithPresExpectedDataIntegrityFields(purpose, domain, challenge string) Pres
 	}
 }
 
+// WithPresExpectedDataIntegrityNonce validates that a Data Integrity proof has the given nonce.
+// Empty nonce means it is not checked.
+func WithPresExpectedDataIntegrityNonce(nonce string) PresentationOpt {
+	return func(opts *presentationOpts) {
+		opts.verifyDataIntegrity.Nonce = nonce
+	}
+}
+
+// WithPresAcceptedDomains validates that a Data Integrity proof has one of the given domains,
+// e.g. the hostnames a verifier is served on. It takes precedence over the domain
+// of WithPresExpectedDataIntegrityFields. Empty domains mean the domain is not checked.
+func WithPresAcceptedDomains(domains ...string) PresentationOpt {
+	return func(opts *presentationOpts) {
+		opts.verifyDataIntegrity.AcceptedDomains = domains
+	}
+}
+
+// WithPresDataIntegrityProofMatching sets the policy applied when the presentation
+// has several Data Integrity proofs. By default, all proofs must verify.
+func WithPresDataIntegrityProofMatching(policy DataIntegrityProofMatching) PresentationOpt {
+	return func(opts *presentationOpts) {
+		opts.verifyDataIntegrity.ProofMatching = policy
+	}
+}
+
+// WithPresDataIntegrityProofStructure sets the expected structure of the Data Integrity proofs of the
+// presentation, as WithDataIntegrityProofStructure does for credentials.
+func WithPresDataIntegrityProofStructure(structure models.ProofStructure) PresentationOpt {
+	return func(opts *presentationOpts) {
+		opts.verifyDataIntegrity.ProofStructure = structure
+	}
+}
+
+// WithPresSignatureOnly makes only the signatures of the Data Integrity proofs of the presentation
+// checked, with the public keys that keyLookup returns, as WithSignatureOnly does for credentials. This
+// is not a full verification of the presentation proofs.
+func WithPresSignatureOnly(keyLookup func(vmID string) (crypto.PublicKey, bool)) PresentationOpt {
+	return func(opts *presentationOpts) {
+		opts.verifyDataIntegrity.SignatureOnly = keyLookup
+	}
+}
+
+// WithPresProofCreatedTolerance allows the created time of a Data Integrity proof to be
+// up to d in the future relative to the verifier's clock. Default is zero tolerance.
+func WithPresProofCreatedTolerance(d time.Duration) PresentationOpt {
+	return func(opts *presentationOpts) {
+		opts.verifyDataIntegrity.CreatedTolerance = d
+	}
+}
+
+// WithPresAllowFutureCreated sets whether the Data Integrity proofs of the presentations, and of
+// their credentials, may be created at any time in the future, as with WithAllowFutureCreated.
+func WithPresAllowFutureCreated(allow bool) PresentationOpt {
+	return func(opts *presentationOpts) {
+		opts.verifyDataIntegrity.AllowFutureCreated = allow
+	}
+}
+
+// WithPresLenientProofContext accepts the Data Integrity proofs of the presentations, and of their
+// credentials, whose @context doesn't define the proof terms, as with WithLenientProofContext.
+func WithPresLenientProofContext() PresentationOpt {
+	return func(opts *presentationOpts) {
+		opts.verifyDataIntegrity.LenientProofContext = true
+	}
+}
+
+// WithPresProofValueEncodingHint accepts the Data Integrity proofs of the presentations, and of their
+// credentials, whose proofValue is not multibase encoded, as with WithProofValueEncodingHint.
+func WithPresProofValueEncodingHint(enc models.ProofValueEncoding) PresentationOpt {
+	return func(opts *presentationOpts) {
+		opts.verifyDataIntegrity.ProofValueEncodingHint = enc
+	}
+}
+
+// WithPresProofExpiryTolerance allows the expires time of a Data Integrity proof to be
+// up to d in the past relative to the verifier's clock. Default is zero tolerance.
+// A proof without an expires time never expires.
+func WithPresProofExpiryTolerance(d time.Duration) PresentationOpt {
+	return func(opts *presentationOpts) {
+		opts.verifyDataIntegrity.ExpiresTolerance = d
+	}
+}
+
+// WithPresDataIntegrityResolvedVerificationMethod sets the already resolved verification method
+// used to check Data Integrity proofs, which skips DID resolution. The ID of the verification
+// method must match the verificationMethod of the proofs.
+func WithPresDataIntegrityResolvedVerificationMethod(vm *vermethod.VerificationMethod) PresentationOpt {
+	return func(opts *presentationOpts) {
+		opts.verifyDataIntegrity.ResolvedVerificationMethod = vm
+	}
+}
+
+// WithPresAllowEmbeddedVerificationMethod makes the Data Integrity proofs checked with their embedded
+// verification method, if bound to its DID or trusted by trust, see WithAllowEmbeddedVerificationMethod.
+func WithPresAllowEmbeddedVerificationMethod(trust EmbeddedVerificationMethodTrust) PresentationOpt {
+	return func(opts *presentationOpts) {
+		opts.verifyDataIntegrity.AllowEmbeddedVerificationMethod = true
+		opts.verifyDataIntegrity.TrustEmbeddedVerificationMethod = trust
+	}
+}
+
+// WithCollectAllErrors makes ParsePresentation report all the failures of the presentation checks,
+// joined with errors.Join, instead of returning on the first one: the presentation proof, the
+// validation of the presentation, each embedded credential and the holder and related resource checks.
+// Each error tells its source, eg "credential 1: ..." for the second embedded credential.
+// VerifyPresentation always reports all the failures.
+func WithCollectAllErrors() PresentationOpt {
+	return func(opts *presentationOpts) {
+		opts.collectAllErrors = true
+	}
+}
+
 // ParsePresentation creates an instance of Verifiable Presentation by reading a JSON document from bytes.
 // It also applies miscellaneous options like custom decoders or settings of schema validation.
 func ParsePresentation(vpData []byte, opts ...PresentationOpt) (*Presentation, error) {
 	vpOpts := getPresentationOpts(opts)
 
+	if err := checkDocumentSize(vpData, vpOpts.maxDocumentSize); err != nil {
+		return nil, err
+	}
+
 	parsers := []PresentationParser{
 		&presentationEnvelopedParser{},
 		&PresentationJSONParser{},
@@ -696,14 +837,33 @@ func ParsePresentation(vpData []byte, opts ...PresentationOpt) (*Presentation, e
 		return nil, errors.New("unable to parse presentation")
 	}
 
+	// The failures collected with WithCollectAllErrors.
+	var errs []error
+
+	if parsed.ProofErr != nil {
+		errs = append(errs, fmt.Errorf("presentation proof: %w", parsed.ProofErr))
+	}
+
 	err = validateVP(parsed.VPRaw, vpOpts)
 	if err != nil {
-		return nil, err
+		if !vpOpts.collectAllErrors {
+			return nil, err
+		}
+
+		errs = append(errs, fmt.Errorf("presentation validation: %w", err))
 	}
 
 	p, err := newPresentation(parsed.VPRaw, vpOpts)
 	if err != nil {
-		return nil, err
+		if !vpOpts.collectAllErrors {
+			return nil, err
+		}
+
+		errs = append(errs, err)
+	}
+
+	if len(errs) > 0 {
+		return nil, errors.Join(errs...)
 	}
 
 	if vpOpts.requireVC && len(p.credentials) == 0 {
@@ -811,19 +971,29 @@ func executeChecks(
 	creds []*Credential,
 	holder string,
 ) error {
+	var errs []error
+
 	if vpOpts.checkHolder {
 		if err := validateHolder(proofs, creds, holder); err != nil {
-			return err
+			if !vpOpts.collectAllErrors {
+				return err
+			}
+
+			errs = append(errs, fmt.Errorf("holder binding: %w", err))
 		}
 	}
 
 	if vpOpts.checkRelatedResource {
 		if err := DefaultRelatedResourceValidator.Validate(creds); err != nil {
-			return err
+			if !vpOpts.collectAllErrors {
+				return err
+			}
+
+			errs = append(errs, fmt.Errorf("related resource: %w", err))
 		}
 	}
 
-	return nil
+	return errors.Join(errs...)
 }
 
 func newPresentation(vpRaw rawPresentation, vpOpts *presentationOpts) (*Presentation, error) {
@@ -837,9 +1007,11 @@ func newPresentation(vpRaw rawPresentation, vpOpts *presentationOpts) (*Presenta
 		return nil, fmt.Errorf("fill presentation contexts from raw: %w", err)
 	}
 
-	creds, err := decodeCredentials(vpRaw[vpFldCredential], vpOpts)
-	if err != nil {
-		return nil, fmt.Errorf("decode credentials of presentation: %w", err)
+	// With WithCollectAllErrors, the failures of the credentials and of the checks are
+	// returned together, after the checks of the decoded credentials.
+	creds, credsErr := decodeCredentials(vpRaw[vpFldCredential], vpOpts)
+	if credsErr != nil && !vpOpts.collectAllErrors {
+		return nil, fmt.Errorf("decode credentials of presentation: %w", credsErr)
 	}
 
 	proofs, err := parseLDProof(vpRaw[vpFldProof])
@@ -857,7 +1029,12 @@ func newPresentation(vpRaw rawPresentation, vpOpts *presentationOpts) (*Presenta
 		return nil, fmt.Errorf("fill presentation holder from raw: %w", err)
 	}
 
-	if err = executeChecks(vpOpts, proofs, creds, holder); err != nil {
+	err = executeChecks(vpOpts, proofs, creds, holder)
+	if credsErr != nil {
+		return nil, errors.Join(credsErr, err)
+	}
+
+	if err != nil {
 		return nil, err
 	}
 
@@ -886,37 +1063,12 @@ func newPresentation(vpRaw rawPresentation, vpOpts *presentationOpts) (*Presenta
 // 2) the same as 1) but as array - e.g. zero ore more JWS
 // 3) struct (should be map[string]interface{}) representing credential data model
 // 4) the same as 3) but as array - i.e. zero or more credentials structs.
-func decodeCredentials(rawCred interface{}, opts *presentationOpts) ([]*Credential, error) { //nolint:funlen
+func decodeCredentials(rawCred interface{}, opts *presentationOpts) ([]*Credential, error) {
 	// Accept the case when VP does not have any VCs.
 	if rawCred == nil {
 		return nil, nil
 	}
 
-	unmarshalSingleCredFn := func(cred interface{}) (*Credential, error) {
-		credOpts := []CredentialOpt{
-			WithProofChecker(opts.proofChecker),
-			WithJSONLDDocumentLoader(opts.jsonldCredentialOpts.jsonldDocumentLoader),
-		}
-
-		if opts.disabledProofCheck {
-			credOpts = append(credOpts, WithDisabledProofCheck())
-		}
-
-		// Check the case when VC is defined in string format (e.g. JWT).
-		// Decode credential and keep result of decoding.
-		if sCred, ok := cred.(string); ok {
-			return ParseCredential([]byte(sCred), credOpts...)
-		}
-
-		if jsonCred, ok := cred.(JSONObject); ok {
-			//TODO: Previous implementation do not validate credentials, should we enable it?
-			return ParseCredentialJSON(jsonCred, append(credOpts, WithCredDisableValidation())...)
-		}
-
-		return nil,
-			fmt.Errorf("invalid credential type should be string or map[string]interface{}, got: %T", cred)
-	}
-
 	switch cred := rawCred.(type) {
 	case []interface{}:
 		// Accept the case when VP does not have any VCs.
@@ -924,23 +1076,41 @@ func decodeCredentials(rawCred interface{}, opts *presentationOpts) ([]*Credenti
 			return nil, nil
 		}
 
+		if err := checkEmbeddedCredentialsCount(len(cred), opts.maxEmbeddedCredentials); err != nil {
+			return nil, err
+		}
+
 		// 1 or more credentials
-		creds := make([]*Credential, len(cred))
+		creds := make([]*Credential, 0, len(cred))
+
+		var errs []error
 
 		for i := range cred {
-			c, err := unmarshalSingleCredFn(cred[i])
+			c, err := decodeCredential(cred[i], opts)
 			if err != nil {
-				return nil, err
+				if !opts.collectAllErrors {
+					return nil, err
+				}
+
+				errs = append(errs, fmt.Errorf("credential %d: %w", i, err))
+
+				continue
 			}
 
-			creds[i] = c
+			creds = append(creds, c)
 		}
 
-		return creds, nil
+		// With WithCollectAllErrors, the decoded credentials are returned together with
+		// the failures of the others.
+		return creds, errors.Join(errs...)
 	default:
 		// single credential
-		c, err := unmarshalSingleCredFn(cred)
+		c, err := decodeCredential(cred, opts)
 		if err != nil {
+			if opts.collectAllErrors {
+				return nil, fmt.Errorf("credential 0: %w", err)
+			}
+
 			return nil, err
 		}
 
@@ -948,6 +1118,32 @@ func decodeCredentials(rawCred interface{}, opts *presentationOpts) ([]*Credenti
 	}
 }
 
+// decodeCredential decodes a single credential embedded into presentation.
+func decodeCredential(cred interface{}, opts *presentationOpts) (*Credential, error) {
+	credOpts := []CredentialOpt{
+		WithProofChecker(opts.proofChecker),
+		WithJSONLDDocumentLoader(opts.jsonldCredentialOpts.jsonldDocumentLoader),
+	}
+
+	if opts.disabledProofCheck {
+		credOpts = append(credOpts, WithDisabledProofCheck())
+	}
+
+	// Check the case when VC is defined in string format (e.g. JWT).
+	// Decode credential and keep result of decoding.
+	if sCred, ok := cred.(string); ok {
+		return ParseCredential([]byte(sCred), credOpts...)
+	}
+
+	if jsonCred, ok := cred.(JSONObject); ok {
+		//TODO: Previous implementation do not validate credentials, should we enable it?
+		return ParseCredentialJSON(jsonCred, append(credOpts, WithCredDisableValidation())...)
+	}
+
+	return nil,
+		fmt.Errorf("invalid credential type should be string or map[string]interface{}, got: %T", cred)
+}
+
 func validateVP(data rawPresentation, opts *presentationOpts) error {
 	err := validateVPJSONSchema(data)
 	if err != nil {
@@ -1010,6 +1206,13 @@ func decodeVPFromJSON(vpData []byte) (rawPresentation, error) {
 		return nil, fmt.Errorf("JSON unmarshalling of verifiable presentation: %w", err)
 	}
 
+	if raw[vpFldProof] != nil {
+		raw[vpFldProof], err = decodeRawProof(vpData, vpFldProof)
+		if err != nil {
+			return nil, fmt.Errorf("JSON unmarshalling of verifiable presentation proof: %w", err)
+		}
+	}
+
 	return raw, nil
 }
 
@@ -0,0 +1,155 @@
+/*
+Copyright Gen Digital Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package verifiable
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/trustbloc/vc-go/dataintegrity"
+	"github.com/trustbloc/vc-go/sdjwt/common"
+	"github.com/trustbloc/vc-go/sdjwt/holder"
+)
+
+type builderCredential struct {
+	vc              *Credential
+	disclosedClaims []string
+}
+
+// PresentationBuilder builds a Presentation from credentials of different formats: SD-JWT credentials
+// are presented with the selected disclosures and a key binding JWT, other credentials as they are.
+// The Presentation can then be secured with a Data Integrity proof.
+type PresentationBuilder struct {
+	presentationOpts []CreatePresentationOpt
+	credentials      []builderCredential
+	holderBinding    *holder.BindingInfo
+
+	proofContext *DataIntegrityProofContext
+	proofSigner  *dataintegrity.Signer
+	proofOpts    []PresDataIntegrityProofOpt
+}
+
+// NewPresentationBuilder creates a PresentationBuilder. The opts are applied to the built Presentation,
+// as with NewPresentation.
+func NewPresentationBuilder(opts ...CreatePresentationOpt) *PresentationBuilder {
+	return &PresentationBuilder{presentationOpts: opts}
+}
+
+// AddCredential adds a credential to the Presentation. For an SD-JWT credential, only the disclosures of
+// the given claim names are presented, and each name must match a disclosure of the credential. Other
+// credentials are presented as they are, so no claim names can be given for them.
+func (b *PresentationBuilder) AddCredential(vc *Credential, disclosedClaims ...string) *PresentationBuilder {
+	b.credentials = append(b.credentials, builderCredential{vc: vc, disclosedClaims: disclosedClaims})
+
+	return b
+}
+
+// WithSDJWTHolderBinding sets the holder binding used to create the key binding JWT of
+// the SD-JWT credentials of the Presentation.
+func (b *PresentationBuilder) WithSDJWTHolderBinding(binding *holder.BindingInfo) *PresentationBuilder {
+	b.holderBinding = binding
+
+	return b
+}
+
+// WithDataIntegrityProof makes Build add a Data Integrity proof to the Presentation,
+// as with Presentation.AddDataIntegrityProof.
+func (b *PresentationBuilder) WithDataIntegrityProof(
+	context *DataIntegrityProofContext,
+	signer *dataintegrity.Signer,
+	opts ...PresDataIntegrityProofOpt,
+) *PresentationBuilder {
+	b.proofContext = context
+	b.proofSigner = signer
+	b.proofOpts = opts
+
+	return b
+}
+
+// Build creates the Presentation.
+func (b *PresentationBuilder) Build() (*Presentation, error) {
+	credentials := make([]*Credential, 0, len(b.credentials))
+
+	for i, c := range b.credentials {
+		if c.vc == nil {
+			return nil, fmt.Errorf("credential [%d] is not defined", i)
+		}
+
+		if c.vc.JWTEnvelope == nil || c.vc.credentialContents.SDJWTHashAlg == nil {
+			if len(c.disclosedClaims) > 0 {
+				return nil, fmt.Errorf("credential [%d] is not an SD-JWT credential, claims can't be selected", i)
+			}
+
+			credentials = append(credentials, c.vc)
+
+			continue
+		}
+
+		presented, err := b.presentSDJWT(c)
+		if err != nil {
+			return nil, fmt.Errorf("credential [%d]: %w", i, err)
+		}
+
+		credentials = append(credentials, presented)
+	}
+
+	vp, err := NewPresentation(append(b.presentationOpts, WithCredentials(credentials...))...)
+	if err != nil {
+		return nil, err
+	}
+
+	if b.proofContext != nil {
+		if b.proofSigner == nil {
+			return nil, errors.New("data integrity proof signer is not defined")
+		}
+
+		err = vp.AddDataIntegrityProof(b.proofContext, b.proofSigner, b.proofOpts...)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return vp, nil
+}
+
+// presentSDJWT returns a copy of the SD-JWT credential with only the selected disclosures,
+// and the key binding JWT if the holder binding is set.
+func (b *PresentationBuilder) presentSDJWT(c builderCredential) (*Credential, error) {
+	available := make(map[string]*common.DisclosureClaim, len(c.vc.JWTEnvelope.SDJWTDisclosures))
+
+	for _, disclosure := range c.vc.JWTEnvelope.SDJWTDisclosures {
+		available[disclosure.Name] = disclosure
+	}
+
+	disclosures := make([]*common.DisclosureClaim, 0, len(c.disclosedClaims))
+
+	for _, name := range c.disclosedClaims {
+		disclosure, ok := available[name]
+		if !ok {
+			return nil, fmt.Errorf("disclosure %q not found in SD-JWT", name)
+		}
+
+		disclosures = append(disclosures, disclosure)
+	}
+
+	envelope := *c.vc.JWTEnvelope
+	envelope.SDJWTDisclosures = disclosures
+
+	if b.holderBinding != nil {
+		kbJWT, err := holder.CreateHolderVerification(b.holderBinding)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create holder binding: %w", err)
+		}
+
+		envelope.SDHolderBinding = kbJWT
+	}
+
+	presented := *c.vc
+	presented.JWTEnvelope = &envelope
+
+	return &presented, nil
+}
@@ -0,0 +1,163 @@
+/*
+Copyright Gen Digital Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package verifiable
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"github.com/trustbloc/did-go/doc/did"
+	kmsapi "github.com/trustbloc/kms-go/spi/kms"
+
+	"github.com/trustbloc/vc-go/crypto-ext/testutil"
+	"github.com/trustbloc/vc-go/dataintegrity"
+	"github.com/trustbloc/vc-go/dataintegrity/suite/ecdsa2019"
+	"github.com/trustbloc/vc-go/internal/testutil/kmscryptoutil"
+	"github.com/trustbloc/vc-go/sdjwt/common"
+	"github.com/trustbloc/vc-go/sdjwt/holder"
+)
+
+func TestPresentationBuilder(t *testing.T) {
+	kmsCrypto := kmscryptoutil.LocalKMSCrypto(t)
+
+	docLoader := createTestDocumentLoader(t)
+
+	key, err := kmsCrypto.Create(kmsapi.ECDSAP256IEEEP1363)
+	require.NoError(t, err)
+
+	const (
+		holderDID = "did:foo:holder"
+		vmID      = "#key-1"
+		domain    = "https://verifier.example.com"
+		challenge = "mock-challenge"
+	)
+
+	vm, err := did.NewVerificationMethodFromJWK(holderDID+vmID, "JsonWebKey2020", holderDID, key)
+	require.NoError(t, err)
+
+	resolver := resolveFunc(func(id string) (*did.DocResolution, error) {
+		return makeMockDIDResolution(holderDID, vm, did.Authentication), nil
+	})
+
+	signer, err := dataintegrity.NewSigner(&dataintegrity.Options{
+		DIDResolver: resolver,
+	}, ecdsa2019.NewSignerInitializer(&ecdsa2019.SignerInitializerOptions{
+		SignerGetter:     ecdsa2019.WithKMSCryptoWrapper(kmsCrypto),
+		LDDocumentLoader: docLoader,
+	}))
+	require.NoError(t, err)
+
+	verifier, err := dataintegrity.NewVerifier(&dataintegrity.Options{
+		DIDResolver: resolver,
+	}, ecdsa2019.NewVerifierInitializer(&ecdsa2019.VerifierInitializerOptions{
+		LDDocumentLoader: docLoader,
+	}))
+	require.NoError(t, err)
+
+	sdJWTString, sdJWTProofChecker := createTestSDJWTCred(t)
+
+	sdJWTVC, err := ParseCredential([]byte(sdJWTString), WithDisabledProofCheck())
+	require.NoError(t, err)
+	require.NotEmpty(t, sdJWTVC.SDJWTDisclosures())
+
+	ldVC, err := parseTestCredential(t, []byte(dataIntegrityTestCredential), WithDisabledProofCheck())
+	require.NoError(t, err)
+
+	_, holderKey, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+
+	disclosedClaim := sdJWTVC.SDJWTDisclosures()[0].Name
+
+	t.Run("success", func(t *testing.T) {
+		vp, err := NewPresentationBuilder().
+			AddCredential(ldVC).
+			AddCredential(sdJWTVC, disclosedClaim).
+			WithSDJWTHolderBinding(&holder.BindingInfo{
+				Payload: holder.BindingPayload{Nonce: challenge, Audience: domain},
+				Signer:  testutil.NewEd25519Signer(holderKey),
+			}).
+			WithDataIntegrityProof(&DataIntegrityProofContext{
+				SigningKeyID: holderDID + vmID,
+				CryptoSuite:  ecdsa2019.SuiteType,
+				Domain:       domain,
+				Challenge:    challenge,
+			}, signer).
+			Build()
+		require.NoError(t, err)
+		require.Len(t, vp.Credentials(), 2)
+		require.Same(t, ldVC, vp.Credentials()[0])
+		require.Len(t, vp.Proofs, 1)
+
+		presentedSDJWT, err := vp.Credentials()[1].ToJWTString()
+		require.NoError(t, err)
+
+		cf := common.ParseCombinedFormatForPresentation(presentedSDJWT)
+		require.Len(t, cf.Disclosures, 1)
+		require.Equal(t, sdJWTVC.SDJWTDisclosures()[0].Disclosure, cf.Disclosures[0])
+		require.NotEmpty(t, cf.HolderVerification)
+
+		// The holder's credential is unchanged.
+		require.Len(t, sdJWTVC.SDJWTDisclosures(), len(common.ParseCombinedFormatForIssuance(sdJWTString).Disclosures))
+
+		vpBytes, err := vp.MarshalJSON()
+		require.NoError(t, err)
+
+		_, err = ParsePresentation(vpBytes,
+			WithPresProofChecker(sdJWTProofChecker),
+			WithPresDataIntegrityVerifier(verifier),
+			WithPresExpectedDataIntegrityFields(authentication, domain, challenge),
+			WithPresJSONLDDocumentLoader(docLoader))
+		require.NoError(t, err)
+	})
+
+	t.Run("no proof", func(t *testing.T) {
+		vp, err := NewPresentationBuilder(WithBaseContext(V1ContextURI)).AddCredential(sdJWTVC).Build()
+		require.NoError(t, err)
+		require.Empty(t, vp.Proofs)
+
+		presentedSDJWT, err := vp.Credentials()[0].ToJWTString()
+		require.NoError(t, err)
+
+		cf := common.ParseCombinedFormatForPresentation(presentedSDJWT)
+		require.Empty(t, cf.Disclosures)
+		require.Empty(t, cf.HolderVerification)
+	})
+
+	t.Run("failure", func(t *testing.T) {
+		t.Run("unknown disclosure", func(t *testing.T) {
+			_, err := NewPresentationBuilder().AddCredential(sdJWTVC, "unknown").Build()
+			require.ErrorContains(t, err, `credential [0]: disclosure "unknown" not found in SD-JWT`)
+		})
+
+		t.Run("claims of a non SD-JWT credential", func(t *testing.T) {
+			_, err := NewPresentationBuilder().AddCredential(ldVC, "name").Build()
+			require.ErrorContains(t, err, "credential [0] is not an SD-JWT credential")
+		})
+
+		t.Run("nil credential", func(t *testing.T) {
+			_, err := NewPresentationBuilder().AddCredential(nil).Build()
+			require.ErrorContains(t, err, "credential [0] is not defined")
+		})
+
+		t.Run("missing signer", func(t *testing.T) {
+			_, err := NewPresentationBuilder().AddCredential(ldVC).
+				WithDataIntegrityProof(&DataIntegrityProofContext{}, nil).Build()
+			require.ErrorContains(t, err, "data integrity proof signer is not defined")
+		})
+
+		t.Run("proof error", func(t *testing.T) {
+			_, err := NewPresentationBuilder().AddCredential(ldVC).
+				WithDataIntegrityProof(&DataIntegrityProofContext{
+					SigningKeyID: holderDID + vmID,
+					CryptoSuite:  ecdsa2019.SuiteType,
+				}, signer).Build()
+			require.ErrorContains(t, err, "authentication proof purpose requires challenge and domain")
+		})
+	})
+}
@@ -24,6 +24,8 @@ type parsePresentationResponse struct {
 	VPRaw         rawPresentation
 	VPJwt         string
 	VPCwt         *VpCWT
+	// ProofErr is the failure of the presentation proof check, kept with WithCollectAllErrors.
+	ProofErr error
 }
 
 // PresentationParser is an interface for parsing presentations.
@@ -61,8 +63,14 @@ func (p *PresentationJSONParser) parse(vpData []byte, vpOpts *presentationOpts)
 		}, nil
 	}
 
+	// Presentation proofs are created for authentication by default.
+	dataIntegrityOpts := *vpOpts.verifyDataIntegrity
+	if dataIntegrityOpts.Purpose == "" {
+		dataIntegrityOpts.Purpose = authentication
+	}
+
 	embeddedProofCheckOpts := &embeddedProofCheckOpts{
-		dataIntegrityOpts:    vpOpts.verifyDataIntegrity,
+		dataIntegrityOpts:    &dataIntegrityOpts,
 		proofChecker:         vpOpts.proofChecker,
 		disabledProofCheck:   vpOpts.disabledProofCheck,
 		jsonldCredentialOpts: vpOpts.jsonldCredentialOpts,
@@ -74,14 +82,16 @@ func (p *PresentationJSONParser) parse(vpData []byte, vpOpts *presentationOpts)
 			return nil, fmt.Errorf("decoding of Verifiable Presentation from unsecured JWT: %w", err)
 		}
 
-		if err := checkEmbeddedProofBytes(rawBytes, nil, embeddedProofCheckOpts); err != nil {
-			return nil, err
+		proofErr := checkEmbeddedProofBytes(rawBytes, nil, embeddedProofCheckOpts)
+		if proofErr != nil && !vpOpts.collectAllErrors {
+			return nil, proofErr
 		}
 
 		return &parsePresentationResponse{
 			VPDataDecoded: rawBytes,
 			VPRaw:         rawPres,
 			VPJwt:         "",
+			ProofErr:      proofErr,
 		}, nil
 	}
 
@@ -90,9 +100,9 @@ func (p *PresentationJSONParser) parse(vpData []byte, vpOpts *presentationOpts)
 		return nil, err
 	}
 
-	err = checkEmbeddedProofBytes(vpData, nil, embeddedProofCheckOpts)
-	if err != nil {
-		return nil, err
+	proofErr := checkEmbeddedProofBytes(vpData, nil, embeddedProofCheckOpts)
+	if proofErr != nil && !vpOpts.collectAllErrors {
+		return nil, proofErr
 	}
 
 	// check that embedded proof is present, if not, it's not a verifiable presentation
@@ -104,6 +114,7 @@ func (p *PresentationJSONParser) parse(vpData []byte, vpOpts *presentationOpts)
 		VPDataDecoded: vpData,
 		VPRaw:         vpRaw,
 		VPJwt:         "",
+		ProofErr:      proofErr,
 	}, nil
 }
 
@@ -0,0 +1,254 @@
+/*
+Copyright Gen Digital Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package verifiable
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"iter"
+)
+
+// errStreamStopped is returned internally when the consumer of the credentials stops the iteration.
+var errStreamStopped = errors.New("credential stream stopped")
+
+// PresentationStream is a JSON-LD Verifiable Presentation read from an io.Reader, whose embedded
+// credentials are decoded lazily, one at a time, as they are consumed from Credentials. This keeps
+// only one credential in memory at once, for presentations with many large credentials.
+//
+// The envelope fields which precede verifiableCredential in the JSON document are set by
+// StreamPresentation, the ones which follow it once all the credentials are consumed.
+//
+// The proof of the presentation is not checked, as it covers the whole presentation, credentials
+// included: a presentation whose proof must be verified is parsed with ParsePresentation.
+type PresentationStream struct {
+	Context       []string
+	CustomContext []interface{}
+	ID            string
+	Type          []string
+	Holder        string
+
+	dec      *json.Decoder
+	opts     *presentationOpts
+	raw      rawPresentation
+	hasCreds bool
+	consumed bool
+}
+
+// StreamPresentation reads the envelope of a JSON-LD Verifiable Presentation from r, up to its embedded
+// credentials, which are then decoded from r by PresentationStream.Credentials. The opts apply to the
+// decoding of the credentials, as with ParsePresentation.
+func StreamPresentation(r io.Reader, opts ...PresentationOpt) (*PresentationStream, error) {
+	vpOpts := getPresentationOpts(opts)
+
+	s := &PresentationStream{
+		dec:  json.NewDecoder(newSizeLimitedReader(r, vpOpts.maxDocumentSize)),
+		opts: vpOpts,
+		raw:  rawPresentation{},
+	}
+
+	err := s.expectDelim(json.Delim('{'))
+	if err != nil {
+		return nil, fmt.Errorf("JSON decoding of verifiable presentation: %w", err)
+	}
+
+	s.hasCreds, err = s.readFields(s.raw, true)
+	if err != nil {
+		return nil, fmt.Errorf("JSON decoding of verifiable presentation: %w", err)
+	}
+
+	err = s.fillEnvelope()
+	if err != nil {
+		return nil, err
+	}
+
+	return s, nil
+}
+
+// StreamCredentials reads a JSON-LD Verifiable Presentation from r and returns an iterator over its
+// embedded credentials, decoded lazily. See StreamPresentation for the presentation envelope.
+func StreamCredentials(r io.Reader, opts ...PresentationOpt) (iter.Seq2[*Credential, error], error) {
+	s, err := StreamPresentation(r, opts...)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.Credentials(), nil
+}
+
+// Credentials returns an iterator over the credentials embedded into the presentation, each decoded
+// when it is reached. The iteration ends at the first error. The credentials are read from the
+// underlying reader, so they can be iterated only once.
+func (s *PresentationStream) Credentials() iter.Seq2[*Credential, error] {
+	return func(yield func(*Credential, error) bool) {
+		if s.consumed {
+			yield(nil, errors.New("credentials of presentation stream are already consumed"))
+
+			return
+		}
+
+		s.consumed = true
+
+		if !s.hasCreds {
+			return
+		}
+
+		err := s.streamCredentials(yield)
+		if err != nil && !errors.Is(err, errStreamStopped) {
+			yield(nil, err)
+		}
+	}
+}
+
+func (s *PresentationStream) streamCredentials(yield func(*Credential, error) bool) error {
+	tok, err := s.dec.Token()
+	if err != nil {
+		return fmt.Errorf("JSON decoding of verifiable presentation: %w", err)
+	}
+
+	switch tok {
+	case json.Delim('['):
+		for i := 0; s.dec.More(); i++ {
+			err = checkEmbeddedCredentialsCount(i+1, s.opts.maxEmbeddedCredentials)
+			if err != nil {
+				return err
+			}
+
+			var rawCred interface{}
+
+			err = s.dec.Decode(&rawCred)
+			if err != nil {
+				return fmt.Errorf("JSON decoding of credential [%d] of presentation: %w", i, err)
+			}
+
+			err = s.yieldCredential(rawCred, yield)
+			if err != nil {
+				return fmt.Errorf("decode credential [%d] of presentation: %w", i, err)
+			}
+		}
+
+		err = s.expectDelim(json.Delim(']'))
+		if err != nil {
+			return fmt.Errorf("JSON decoding of verifiable presentation: %w", err)
+		}
+	case json.Delim('{'):
+		rawCred := JSONObject{}
+
+		if _, err = s.readFields(rawCred, false); err != nil {
+			return fmt.Errorf("JSON decoding of credential of presentation: %w", err)
+		}
+
+		err = s.yieldCredential(rawCred, yield)
+		if err != nil {
+			return fmt.Errorf("decode credential of presentation: %w", err)
+		}
+	case nil:
+		// Accept the case when VP does not have any VCs.
+	default:
+		err = s.yieldCredential(tok, yield)
+		if err != nil {
+			return fmt.Errorf("decode credential of presentation: %w", err)
+		}
+	}
+
+	if _, err = s.readFields(s.raw, false); err != nil {
+		return fmt.Errorf("JSON decoding of verifiable presentation: %w", err)
+	}
+
+	return s.fillEnvelope()
+}
+
+func (s *PresentationStream) yieldCredential(rawCred interface{}, yield func(*Credential, error) bool) error {
+	vc, err := decodeCredential(rawCred, s.opts)
+	if err != nil {
+		return err
+	}
+
+	if !yield(vc, nil) {
+		return errStreamStopped
+	}
+
+	return nil
+}
+
+// readFields decodes the fields of the current JSON object into dst, up to the end of the object,
+// or up to the verifiableCredential field if stopAtCredentials is set, in which case it returns true.
+func (s *PresentationStream) readFields(dst map[string]interface{}, stopAtCredentials bool) (bool, error) {
+	for {
+		tok, err := s.dec.Token()
+		if err != nil {
+			return false, err
+		}
+
+		if tok == json.Delim('}') {
+			return false, nil
+		}
+
+		key, ok := tok.(string)
+		if !ok {
+			return false, fmt.Errorf("unexpected token %v", tok)
+		}
+
+		if stopAtCredentials && key == vpFldCredential {
+			return true, nil
+		}
+
+		var value interface{}
+
+		err = s.dec.Decode(&value)
+		if err != nil {
+			return false, err
+		}
+
+		dst[key] = value
+	}
+}
+
+func (s *PresentationStream) expectDelim(delim json.Delim) error {
+	tok, err := s.dec.Token()
+	if err != nil {
+		return err
+	}
+
+	if tok != delim {
+		return fmt.Errorf("expected %v, got %v", delim, tok)
+	}
+
+	return nil
+}
+
+// fillEnvelope sets the envelope fields decoded so far, the ones not decoded yet being left empty.
+func (s *PresentationStream) fillEnvelope() error {
+	var err error
+
+	if rawType, ok := s.raw[vpFldType]; ok {
+		s.Type, err = decodeType(rawType)
+		if err != nil {
+			return fmt.Errorf("fill presentation types from raw: %w", err)
+		}
+	}
+
+	if rawContext, ok := s.raw[vpFldContext]; ok {
+		s.Context, s.CustomContext, err = decodeContext(rawContext)
+		if err != nil {
+			return fmt.Errorf("fill presentation contexts from raw: %w", err)
+		}
+	}
+
+	s.ID, err = parseStringFld(s.raw, vpFldID)
+	if err != nil {
+		return fmt.Errorf("fill presentation id from raw: %w", err)
+	}
+
+	s.Holder, err = decodeHolder(s.raw[vpFldHolder])
+	if err != nil {
+		return fmt.Errorf("fill presentation holder from raw: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,135 @@
+/*
+Copyright Gen Digital Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package verifiable
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+const streamedPresentation = `{
+  "@context": ["https://www.w3.org/2018/credentials/v1"],
+  "id": "urn:uuid:3978344f-8596-4c3a-a978-8fcaba3903c5",
+  "type": "VerifiablePresentation",
+  "verifiableCredential": [
+    {
+      "@context": ["https://www.w3.org/2018/credentials/v1"],
+      "id": "http://example.edu/credentials/1",
+      "type": "VerifiableCredential",
+      "issuer": "https://example.edu/issuers/14",
+      "issuanceDate": "2010-01-01T19:23:24Z",
+      "credentialSubject": {"id": "did:example:ebfeb1f712ebc6f1c276e12ec21"}
+    },
+    {
+      "@context": ["https://www.w3.org/2018/credentials/v1"],
+      "id": "http://example.edu/credentials/2",
+      "type": "VerifiableCredential",
+      "issuer": "https://example.edu/issuers/14",
+      "issuanceDate": "2010-01-01T19:23:24Z",
+      "credentialSubject": {"id": "did:example:ebfeb1f712ebc6f1c276e12ec21"}
+    }
+  ],
+  "holder": "did:example:ebfeb1f712ebc6f1c276e12ec21"
+}`
+
+func TestStreamPresentation(t *testing.T) {
+	t.Run("credentials array", func(t *testing.T) {
+		stream, err := StreamPresentation(strings.NewReader(streamedPresentation), WithPresDisabledProofCheck())
+		require.NoError(t, err)
+
+		require.Equal(t, []string{V1ContextURI}, stream.Context)
+		require.Equal(t, "urn:uuid:3978344f-8596-4c3a-a978-8fcaba3903c5", stream.ID)
+		require.Equal(t, []string{VPType}, stream.Type)
+		// The holder follows the credentials.
+		require.Empty(t, stream.Holder)
+
+		var ids []string
+
+		for vc, err := range stream.Credentials() {
+			require.NoError(t, err)
+
+			ids = append(ids, vc.Contents().ID)
+		}
+
+		require.Equal(t, []string{"http://example.edu/credentials/1", "http://example.edu/credentials/2"}, ids)
+		require.Equal(t, "did:example:ebfeb1f712ebc6f1c276e12ec21", stream.Holder)
+
+		for _, err := range stream.Credentials() {
+			require.ErrorContains(t, err, "already consumed")
+		}
+	})
+
+	t.Run("stop iteration", func(t *testing.T) {
+		credentials, err := StreamCredentials(strings.NewReader(streamedPresentation), WithPresDisabledProofCheck())
+		require.NoError(t, err)
+
+		count := 0
+
+		for _, err := range credentials {
+			require.NoError(t, err)
+
+			count++
+
+			break
+		}
+
+		require.Equal(t, 1, count)
+	})
+
+	t.Run("single credential", func(t *testing.T) {
+		credentials, err := StreamCredentials(strings.NewReader(validPresentation), WithPresDisabledProofCheck())
+		require.NoError(t, err)
+
+		count := 0
+
+		for vc, err := range credentials {
+			require.NoError(t, err)
+			require.Equal(t, "http://example.edu/credentials/58473", vc.Contents().ID)
+
+			count++
+		}
+
+		require.Equal(t, 1, count)
+	})
+
+	t.Run("no credentials", func(t *testing.T) {
+		stream, err := StreamPresentation(strings.NewReader(presentationWithoutCredentials))
+		require.NoError(t, err)
+		require.Equal(t, []string{VPType}, stream.Type)
+
+		for range stream.Credentials() {
+			require.Fail(t, "no credential expected")
+		}
+	})
+
+	t.Run("failure", func(t *testing.T) {
+		_, err := StreamPresentation(strings.NewReader(`[]`))
+		require.ErrorContains(t, err, "JSON decoding of verifiable presentation")
+
+		_, err = StreamPresentation(strings.NewReader(`{"type": 1, "verifiableCredential": []}`))
+		require.ErrorContains(t, err, "fill presentation types from raw")
+
+		credentials, err := StreamCredentials(strings.NewReader(
+			`{"type": "VerifiablePresentation", "verifiableCredential": [{"id": 5}]}`))
+		require.NoError(t, err)
+
+		for vc, err := range credentials {
+			require.Nil(t, vc)
+			require.ErrorContains(t, err, "decode credential [0] of presentation")
+		}
+
+		credentials, err = StreamCredentials(strings.NewReader(
+			`{"type": "VerifiablePresentation", "verifiableCredential": [{"id": `))
+		require.NoError(t, err)
+
+		for _, err := range credentials {
+			require.ErrorContains(t, err, "JSON decoding of credential [0] of presentation")
+		}
+	})
+}
@@ -7,17 +7,20 @@ package verifiable
 
 import (
 	_ "embed"
+	"encoding/base64"
 	"encoding/json"
 	"testing"
 
 	jsonld "github.com/piprate/json-gold/ld"
 	"github.com/stretchr/testify/require"
+	"github.com/tidwall/sjson"
 	ldcontext "github.com/trustbloc/did-go/doc/ld/context"
 	ldprocessor "github.com/trustbloc/did-go/doc/ld/processor"
 	ldtestutil "github.com/trustbloc/did-go/doc/ld/testutil"
 	"github.com/trustbloc/kms-go/spi/kms"
 	"github.com/veraison/go-cose"
 
+	afgjwt "github.com/trustbloc/vc-go/jwt"
 	"github.com/trustbloc/vc-go/proof/testsupport"
 )
 
@@ -614,6 +617,129 @@ func TestV2ParsePresentation(t *testing.T) {
 	})
 }
 
+func TestParsePresentation_EnvelopedCredentials(t *testing.T) {
+	const keyID = "did:123#issuer-key"
+
+	vcc := vccProtoV2
+	vcc.Issuer = &Issuer{ID: "did:123"}
+
+	vc, err := CreateCredential(vcc, nil)
+	require.NoError(t, err)
+
+	issuerSigner, _ := testsupport.NewKMSSigVerPair(t, kms.ED25519Type, keyID)
+
+	jwtVC, err := vc.CreateSignedJWTVC(false, EdDSA, issuerSigner, keyID)
+	require.NoError(t, err)
+
+	joseSigner, err := afgjwt.NewJOSESigner(afgjwt.SignParameters{
+		KeyID:  keyID,
+		JWTAlg: "EdDSA",
+	}, issuerSigner)
+	require.NoError(t, err)
+
+	sdJWT, err := vc.MakeSDJWT(joseSigner, keyID)
+	require.NoError(t, err)
+
+	jwtString, err := jwtVC.ToJWTString()
+	require.NoError(t, err)
+
+	// The wallets envelop the credentials with the media types of the VC-JOSE-COSE recommendation.
+	vpJSON, err := json.Marshal(map[string]interface{}{
+		"@context": []string{V2ContextURI},
+		"type":     VPType,
+		"verifiableCredential": []interface{}{
+			vc.ToRawJSON(),
+			map[string]interface{}{
+				"@context": V2ContextURI,
+				"type":     VCEnvelopedType,
+				"id":       NewDataURL("application/vc+jwt", "", jwtString),
+			},
+			map[string]interface{}{
+				"@context": V2ContextURI,
+				"type":     VCEnvelopedType,
+				"id": NewDataURL("application/vc+sd-jwt", "base64",
+					base64.StdEncoding.EncodeToString([]byte(sdJWT))),
+			},
+			map[string]interface{}{
+				"@context": V2ContextURI,
+				"type":     VCEnvelopedType,
+				"id":       NewDataURL(VCMediaTypeJWT, "", jwtString),
+			},
+		},
+	})
+	require.NoError(t, err)
+
+	vp, err := ParsePresentation(vpJSON,
+		WithPresDisabledProofCheck(),
+		WithPresJSONLDDocumentLoader(createTestDocumentLoader(t)),
+	)
+	require.NoError(t, err)
+
+	creds := vp.Credentials()
+	require.Len(t, creds, 4)
+
+	for _, cred := range creds {
+		require.Equal(t, vcc.ID, cred.Contents().ID)
+		require.Equal(t, "did:123", cred.Contents().Issuer.ID)
+	}
+
+	require.False(t, creds[0].IsJWT())
+	require.True(t, creds[1].IsJWT())
+	require.True(t, creds[2].IsJWT())
+	require.NotEmpty(t, creds[2].SDJWTDisclosures())
+	require.True(t, creds[3].IsJWT())
+
+	// The credentials are enveloped again with their media type.
+	vpBytes, err := vp.MarshalJSON()
+	require.NoError(t, err)
+
+	var raw JSONObject
+	require.NoError(t, json.Unmarshal(vpBytes, &raw))
+
+	rawCreds, ok := raw[vpFldCredential].([]interface{})
+	require.True(t, ok)
+	require.Len(t, rawCreds, 4)
+
+	for i, expected := range map[int]MediaType{
+		1: "application/vc+jwt",
+		2: "application/vc+sd-jwt",
+		3: VCMediaTypeJWT,
+	} {
+		envelope, ok := rawCreds[i].(map[string]interface{})
+		require.True(t, ok)
+
+		id, ok := envelope[jsonFldID].(string)
+		require.True(t, ok)
+
+		mediaType, _, _, err := ParseDataURL(id)
+		require.NoError(t, err)
+		require.Equal(t, expected, mediaType)
+	}
+
+	_, err = ParsePresentation(vpBytes,
+		WithPresDisabledProofCheck(),
+		WithPresJSONLDDocumentLoader(createTestDocumentLoader(t)),
+	)
+	require.NoError(t, err)
+
+	t.Run("failure", func(t *testing.T) {
+		for id, errMsg := range map[string]string{
+			NewDataURL("application/vc+jwt", "", "invalid"):      `data URL of type "application/vc+jwt"`,
+			NewDataURL("application/vc+sd-jwt", "base64", "%%%"): "decode base64 data URL",
+			NewDataURL("application/json", "", jwtString):        "unsupported data URL media type",
+		} {
+			invalidVP, err := sjson.SetBytes(vpJSON, "verifiableCredential.1.id", id)
+			require.NoError(t, err)
+
+			_, err = ParsePresentation(invalidVP,
+				WithPresDisabledProofCheck(),
+				WithPresJSONLDDocumentLoader(createTestDocumentLoader(t)),
+			)
+			require.ErrorContains(t, err, errMsg, id)
+		}
+	})
+}
+
 func TestValidateVP_Context(t *testing.T) {
 	t.Run("rejects verifiable presentation with empty context", func(t *testing.T) {
 		var raw rawPresentation
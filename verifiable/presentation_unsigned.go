@@ -0,0 +1,44 @@
+/*
+Copyright Gen Digital Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package verifiable
+
+import (
+	"errors"
+	"fmt"
+)
+
+// ErrPresentationSigned is returned by ValidateUnsigned for a presentation which has a proof or is a JWT or CWT.
+var ErrPresentationSigned = errors.New("presentation is signed")
+
+// IsSigned tells whether the presentation is secured, ie whether it has an embedded proof
+// or is a JWT or CWT presentation.
+func (vp *Presentation) IsSigned() bool {
+	return len(vp.Proofs) > 0 || vp.IsJWT() || vp.IsCWT()
+}
+
+// ValidateUnsigned checks that vp is a well-formed presentation without proof, for the flows where
+// the presentation is left unsigned, eg when the transport authenticates the holder. It returns
+// ErrPresentationSigned for a signed vp, else validates the presentation against the JSON schema
+// of its base context and, unless WithDisabledJSONLDChecks is given, its JSON-LD, using the JSON-LD
+// options given as PresentationOpt.
+func (vp *Presentation) ValidateUnsigned(opts ...PresentationOpt) error {
+	if vp.IsSigned() {
+		return ErrPresentationSigned
+	}
+
+	raw, err := vp.raw()
+	if err != nil {
+		return fmt.Errorf("validate unsigned presentation: %w", err)
+	}
+
+	err = validateVP(raw, getPresentationOpts(opts))
+	if err != nil {
+		return fmt.Errorf("validate unsigned presentation: %w", err)
+	}
+
+	return nil
+}
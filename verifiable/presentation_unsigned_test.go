@@ -0,0 +1,84 @@
+/*
+Copyright Gen Digital Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package verifiable
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestPresentation_ValidateUnsigned(t *testing.T) {
+	docLoader := createTestDocumentLoader(t)
+
+	vc, err := parseTestCredential(t, []byte(dataIntegrityTestCredential), WithDisabledProofCheck())
+	require.NoError(t, err)
+
+	newVP := func(t *testing.T) *Presentation {
+		t.Helper()
+
+		vp, e := NewPresentation(WithCredentials(vc))
+		require.NoError(t, e)
+
+		vp.Holder = "did:example:holder"
+
+		return vp
+	}
+
+	t.Run("unsigned", func(t *testing.T) {
+		vp := newVP(t)
+		vp.Proofs = []Proof{}
+		vp.CustomFields = CustomFields{"proof": []interface{}{}}
+
+		require.False(t, vp.IsSigned())
+		require.NoError(t, vp.ValidateUnsigned(WithPresJSONLDDocumentLoader(docLoader)))
+
+		vpBytes, e := vp.MarshalJSON()
+		require.NoError(t, e)
+
+		var raw map[string]interface{}
+
+		require.NoError(t, json.Unmarshal(vpBytes, &raw))
+		require.NotContains(t, raw, "proof")
+
+		parsed, e := newTestPresentation(t, vpBytes, WithPresDisabledProofCheck())
+		require.NoError(t, e)
+		require.False(t, parsed.IsSigned())
+		require.NoError(t, parsed.ValidateUnsigned(WithPresJSONLDDocumentLoader(docLoader)))
+	})
+
+	t.Run("signed", func(t *testing.T) {
+		vp := newVP(t)
+		vp.Proofs = []Proof{{"type": "Ed25519Signature2018"}}
+
+		require.True(t, vp.IsSigned())
+		require.ErrorIs(t, vp.ValidateUnsigned(), ErrPresentationSigned)
+
+		vp = newVP(t)
+		vp.JWT = "eyJhbGciOiJFUzI1NiJ9.e30.c2ln"
+
+		require.True(t, vp.IsSigned())
+		require.ErrorIs(t, vp.ValidateUnsigned(), ErrPresentationSigned)
+	})
+
+	t.Run("malformed", func(t *testing.T) {
+		vp := newVP(t)
+		vp.Type = []string{"OtherPresentation"}
+
+		err := vp.ValidateUnsigned(WithDisabledJSONLDChecks())
+		require.ErrorContains(t, err, "validate unsigned presentation:")
+		require.ErrorContains(t, err, "verifiable presentation")
+
+		vp = newVP(t)
+		vp.CustomFields = CustomFields{"undefinedTerm": "value"}
+
+		require.NoError(t, vp.ValidateUnsigned(WithDisabledJSONLDChecks()))
+		require.ErrorContains(t, vp.ValidateUnsigned(WithPresJSONLDDocumentLoader(docLoader),
+			WithPresStrictValidation()), "validate unsigned presentation:")
+	})
+}
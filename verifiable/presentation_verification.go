@@ -0,0 +1,159 @@
+/*
+Copyright Gen Digital Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package verifiable
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/trustbloc/vc-go/jwt"
+)
+
+// PresentationVerificationResult is the result of VerifyPresentation.
+type PresentationVerificationResult struct {
+	// ProofErr is the failure of the presentation proof check, nil if the proof is verified.
+	ProofErr error
+	// Credentials holds the statuses of the embedded credentials, in the order of the presentation.
+	// It is empty if the verification of the embedded credentials is skipped.
+	Credentials []CredentialVerificationStatus
+	// HolderBindingErr is the failure of the holder check of WithPresHolderCheck, which is always
+	// made by VerifyPresentation. It is nil if the holder is bound to the presentation.
+	HolderBindingErr error
+}
+
+// CredentialVerificationStatus is the verification status of a credential embedded into a presentation.
+type CredentialVerificationStatus struct {
+	Credential *Credential
+	// Err is the failure of the credential proof check, nil if the proof is verified.
+	Err error
+}
+
+// Err returns the failures of the result joined together, nil if the presentation, its credentials
+// and its holder binding are verified.
+func (r *PresentationVerificationResult) Err() error {
+	var errs []error
+
+	if r.ProofErr != nil {
+		errs = append(errs, fmt.Errorf("presentation proof: %w", r.ProofErr))
+	}
+
+	for i, status := range r.Credentials {
+		if status.Err != nil {
+			errs = append(errs, fmt.Errorf("credential %d proof: %w", i, status.Err))
+		}
+	}
+
+	if r.HolderBindingErr != nil {
+		errs = append(errs, fmt.Errorf("holder binding: %w", r.HolderBindingErr))
+	}
+
+	return errors.Join(errs...)
+}
+
+// WithSkipEmbeddedCredentialVerification makes VerifyPresentation verify only the presentation
+// proof and the holder binding, eg when the embedded credentials are verified separately.
+func WithSkipEmbeddedCredentialVerification() PresentationOpt {
+	return func(opts *presentationOpts) {
+		opts.skipEmbeddedCredentialVerification = true
+	}
+}
+
+// VerifyPresentation verifies the proof of the already parsed presentation vp, the proofs of its embedded
+// credentials and its holder binding. It uses the proof checker, the Data Integrity verifier and the JSON-LD
+// options given as PresentationOpt.
+//
+// A Data Integrity proof of the presentation is expected to have the authentication purpose, unless
+// another is given with WithPresExpectedDataIntegrityFields, which also sets the expected domain and
+// challenge. The credentials are checked with the same verifier, for the assertionMethod purpose and
+// without domain or challenge. Each credential is verified as its own document, from its own JSON, so
+// that it is canonicalized in isolation rather than as a node of the presentation graph.
+//
+// The returned result is nil only for a nil vp. The returned error is the result Err: the result
+// tells which of the checks failed.
+func VerifyPresentation(vp *Presentation, opts ...PresentationOpt) (*PresentationVerificationResult, error) {
+	if vp == nil {
+		return nil, errors.New("presentation is nil")
+	}
+
+	vpOpts := getPresentationOpts(opts)
+
+	result := &PresentationVerificationResult{
+		ProofErr:         checkPresentationProof(vp, vpOpts),
+		HolderBindingErr: validateHolder(vp.Proofs, vp.credentials, vp.Holder),
+	}
+
+	if !vpOpts.skipEmbeddedCredentialVerification {
+		credOpts := embeddedCredentialCheckOpts(vpOpts)
+
+		for _, vc := range vp.credentials {
+			result.Credentials = append(result.Credentials, CredentialVerificationStatus{
+				Credential: vc,
+				Err:        vc.checkProof(credOpts),
+			})
+		}
+	}
+
+	return result, result.Err()
+}
+
+func checkPresentationProof(vp *Presentation, vpOpts *presentationOpts) error {
+	if vp.CWT != nil {
+		return errors.New("check of CWT presentation proof is not supported")
+	}
+
+	if vp.JWT != "" {
+		if vpOpts.proofChecker == nil {
+			return errors.New("proof checker is not defined")
+		}
+
+		err := jwt.CheckProof(vp.JWT, vpOpts.proofChecker, nil, nil)
+		if err != nil {
+			return fmt.Errorf("jwt proof check: %w", err)
+		}
+
+		return nil
+	}
+
+	raw, err := vp.raw()
+	if err != nil {
+		return err
+	}
+
+	dataIntegrityOpts := *vpOpts.verifyDataIntegrity
+	if dataIntegrityOpts.Purpose == "" {
+		dataIntegrityOpts.Purpose = authentication
+	}
+
+	return checkEmbeddedProof(raw, nil, &embeddedProofCheckOpts{
+		proofChecker:         vpOpts.proofChecker,
+		dataIntegrityOpts:    &dataIntegrityOpts,
+		jsonldCredentialOpts: vpOpts.jsonldCredentialOpts,
+	})
+}
+
+// embeddedCredentialCheckOpts returns the options to check the credential proofs of a presentation:
+// the presentation specific Data Integrity options, as the purpose, domain, challenge and verification
+// method, are not used.
+func embeddedCredentialCheckOpts(vpOpts *presentationOpts) *credentialOpts {
+	credOpts := getCredentialOpts([]CredentialOpt{WithProofChecker(vpOpts.proofChecker)})
+	credOpts.jsonldCredentialOpts = vpOpts.jsonldCredentialOpts
+
+	credOpts.verifyDataIntegrity = &verifyDataIntegrityOpts{
+		Verifier:                        vpOpts.verifyDataIntegrity.Verifier,
+		ProofMatching:                   vpOpts.verifyDataIntegrity.ProofMatching,
+		CreatedTolerance:                vpOpts.verifyDataIntegrity.CreatedTolerance,
+		AllowFutureCreated:              vpOpts.verifyDataIntegrity.AllowFutureCreated,
+		ExpiresTolerance:                vpOpts.verifyDataIntegrity.ExpiresTolerance,
+		AllowEmbeddedVerificationMethod: vpOpts.verifyDataIntegrity.AllowEmbeddedVerificationMethod,
+		TrustEmbeddedVerificationMethod: vpOpts.verifyDataIntegrity.TrustEmbeddedVerificationMethod,
+		LenientProofContext:             vpOpts.verifyDataIntegrity.LenientProofContext,
+		ProofValueEncodingHint:          vpOpts.verifyDataIntegrity.ProofValueEncodingHint,
+		Context:                         vpOpts.verifyDataIntegrity.Context,
+	}
+
+	return credOpts
+}
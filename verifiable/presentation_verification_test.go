@@ -0,0 +1,245 @@
+/*
+Copyright Gen Digital Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package verifiable
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"github.com/tidwall/sjson"
+	"github.com/trustbloc/did-go/doc/did"
+	kmsapi "github.com/trustbloc/kms-go/spi/kms"
+
+	"github.com/trustbloc/vc-go/dataintegrity"
+	"github.com/trustbloc/vc-go/dataintegrity/suite/ecdsa2019"
+	"github.com/trustbloc/vc-go/internal/testutil/kmscryptoutil"
+)
+
+func TestVerifyPresentation(t *testing.T) {
+	kmsCrypto := kmscryptoutil.LocalKMSCrypto(t)
+	docLoader := createTestDocumentLoader(t)
+
+	newVM := func(didID string) *did.VerificationMethod {
+		key, err := kmsCrypto.Create(kmsapi.ECDSAP256IEEEP1363)
+		require.NoError(t, err)
+
+		vm, err := did.NewVerificationMethodFromJWK(didID+"#key-1", "JsonWebKey2020", didID, key)
+		require.NoError(t, err)
+
+		return vm
+	}
+
+	const (
+		issuerDID = "did:foo:issuer"
+		holderDID = "did:foo:holder"
+	)
+
+	vms := map[string]*did.VerificationMethod{
+		issuerDID: newVM(issuerDID),
+		holderDID: newVM(holderDID),
+	}
+
+	resolver := resolveFunc(func(id string) (*did.DocResolution, error) {
+		vm := vms[id]
+		ver := []did.Verification{{VerificationMethod: *vm}}
+
+		return &did.DocResolution{DIDDocument: &did.Doc{
+			ID:              id,
+			AssertionMethod: ver,
+			Authentication:  ver,
+		}}, nil
+	})
+
+	signer, err := dataintegrity.NewSigner(&dataintegrity.Options{DIDResolver: resolver},
+		ecdsa2019.NewSignerInitializer(&ecdsa2019.SignerInitializerOptions{
+			SignerGetter:     ecdsa2019.WithKMSCryptoWrapper(kmsCrypto),
+			LDDocumentLoader: docLoader,
+		}))
+	require.NoError(t, err)
+
+	verifier, err := dataintegrity.NewVerifier(&dataintegrity.Options{DIDResolver: resolver},
+		ecdsa2019.NewVerifierInitializer(&ecdsa2019.VerifierInitializerOptions{
+			LDDocumentLoader: docLoader,
+		}))
+	require.NoError(t, err)
+
+	signedVC, err := parseTestCredential(t, []byte(dataIntegrityTestCredential), WithDisabledProofCheck())
+	require.NoError(t, err)
+
+	err = signedVC.AddDataIntegrityProof(&DataIntegrityProofContext{
+		SigningKeyID: issuerDID + "#key-1",
+		CryptoSuite:  ecdsa2019.SuiteType,
+	}, signer)
+	require.NoError(t, err)
+
+	unsignedVC, err := parseTestCredential(t, []byte(dataIntegrityTestCredential), WithDisabledProofCheck())
+	require.NoError(t, err)
+
+	newVP := func(t *testing.T, signingDID, holder string, vcs ...*Credential) *Presentation {
+		t.Helper()
+
+		vp, e := NewPresentation(WithCredentials(vcs...))
+		require.NoError(t, e)
+
+		vp.Holder = holder
+
+		e = vp.AddDataIntegrityProof(&DataIntegrityProofContext{
+			SigningKeyID: signingDID + "#key-1",
+			CryptoSuite:  ecdsa2019.SuiteType,
+			Domain:       "mock-domain",
+			Challenge:    "mock-challenge",
+		}, signer)
+		require.NoError(t, e)
+
+		vpBytes, e := vp.MarshalJSON()
+		require.NoError(t, e)
+
+		parsed, e := newTestPresentation(t, vpBytes, WithPresDisabledProofCheck())
+		require.NoError(t, e)
+
+		return parsed
+	}
+
+	verifyOpts := []PresentationOpt{
+		WithPresJSONLDDocumentLoader(docLoader),
+		WithPresDataIntegrityVerifier(verifier),
+		WithPresExpectedDataIntegrityFields("", "mock-domain", "mock-challenge"),
+	}
+
+	t.Run("success", func(t *testing.T) {
+		vp := newVP(t, holderDID, holderDID, signedVC)
+
+		result, e := VerifyPresentation(vp, verifyOpts...)
+		require.NoError(t, e)
+		require.NoError(t, result.ProofErr)
+		require.NoError(t, result.HolderBindingErr)
+		require.Len(t, result.Credentials, 1)
+		require.Equal(t, vp.Credentials()[0], result.Credentials[0].Credential)
+		require.NoError(t, result.Credentials[0].Err)
+	})
+
+	t.Run("success with blank node credentials", func(t *testing.T) {
+		// The credential subjects without id, and their degrees, are blank nodes, whose canonical labels
+		// differ when the credential is canonicalized as a node of the presentation graph.
+		newBlankNodeVC := func(name string) *Credential {
+			vcBytes, e := sjson.DeleteBytes([]byte(dataIntegrityTestCredential), "credentialSubject.id")
+			require.NoError(t, e)
+
+			vcBytes, e = sjson.SetBytes(vcBytes, "credentialSubject.name", name)
+			require.NoError(t, e)
+
+			vc, e := parseTestCredential(t, vcBytes, WithDisabledProofCheck())
+			require.NoError(t, e)
+
+			e = vc.AddDataIntegrityProof(&DataIntegrityProofContext{
+				SigningKeyID: issuerDID + "#key-1",
+				CryptoSuite:  ecdsa2019.SuiteType,
+			}, signer)
+			require.NoError(t, e)
+
+			return vc
+		}
+
+		vp := newVP(t, holderDID, holderDID, newBlankNodeVC("Jayden Doe"), newBlankNodeVC("Morgan Doe"))
+
+		for i := 0; i < 2; i++ {
+			result, e := VerifyPresentation(vp, verifyOpts...)
+			require.NoError(t, e)
+			require.Len(t, result.Credentials, 2)
+		}
+
+		for _, vc := range vp.Credentials() {
+			vcBytes, e := vc.MarshalJSON()
+			require.NoError(t, e)
+
+			_, e = parseTestCredential(t, vcBytes, WithDataIntegrityVerifier(verifier))
+			require.NoError(t, e)
+		}
+	})
+
+	t.Run("presentation proof failure", func(t *testing.T) {
+		vp := newVP(t, holderDID, holderDID, signedVC)
+
+		result, e := VerifyPresentation(vp, append(verifyOpts,
+			WithPresExpectedDataIntegrityFields("", "mock-domain", "other-challenge"))...)
+		require.ErrorContains(t, e, "presentation proof:")
+
+		var diErr *DataIntegrityError
+
+		require.ErrorAs(t, result.ProofErr, &diErr)
+		require.Equal(t, ErrCodeChallengeMismatch, diErr.Code)
+		require.NoError(t, result.Credentials[0].Err)
+
+		result, e = VerifyPresentation(newVP(t, issuerDID, holderDID, signedVC), append(verifyOpts,
+			WithPresExpectedDataIntegrityFields(assertionMethod, "mock-domain", "mock-challenge"))...)
+		require.Error(t, e)
+		require.ErrorAs(t, result.ProofErr, &diErr)
+	})
+
+	t.Run("credential proof failure", func(t *testing.T) {
+		vp := newVP(t, holderDID, holderDID, signedVC, unsignedVC)
+
+		result, e := VerifyPresentation(vp, verifyOpts...)
+		require.ErrorContains(t, e, "credential 1 proof: proof not found")
+		require.NoError(t, result.ProofErr)
+		require.Len(t, result.Credentials, 2)
+		require.NoError(t, result.Credentials[0].Err)
+		require.EqualError(t, result.Credentials[1].Err, "proof not found")
+	})
+
+	t.Run("skip embedded credential verification", func(t *testing.T) {
+		vp := newVP(t, holderDID, holderDID, unsignedVC)
+
+		result, e := VerifyPresentation(vp, append(verifyOpts, WithSkipEmbeddedCredentialVerification())...)
+		require.NoError(t, e)
+		require.NoError(t, result.ProofErr)
+		require.Empty(t, result.Credentials)
+	})
+
+	t.Run("holder binding failure", func(t *testing.T) {
+		// Self-asserted credential, secured with the same key as the presentation, without holder.
+		vp := newVP(t, issuerDID, "", signedVC)
+
+		result, e := VerifyPresentation(vp, verifyOpts...)
+		require.ErrorContains(t, e, "holder binding:")
+		require.NoError(t, result.ProofErr)
+		require.NoError(t, result.Credentials[0].Err)
+		require.ErrorContains(t, result.HolderBindingErr, "MUST include a holder property")
+	})
+
+	t.Run("parse presentation, collect all errors", func(t *testing.T) {
+		vpBytes, e := newVP(t, issuerDID, "", signedVC).MarshalJSON()
+		require.NoError(t, e)
+
+		vpBytes, e = sjson.SetBytes(vpBytes, "verifiableCredential.-1", "not a credential")
+		require.NoError(t, e)
+
+		parseOpts := append(verifyOpts,
+			WithPresExpectedDataIntegrityFields(authentication, "mock-domain", "other-challenge"),
+			WithPresHolderCheck(true))
+
+		_, e = newTestPresentation(t, vpBytes, parseOpts...)
+		require.ErrorContains(t, e, "challenge")
+		require.NotContains(t, e.Error(), "credential 1:")
+
+		_, e = newTestPresentation(t, vpBytes, append(parseOpts, WithCollectAllErrors())...)
+		require.ErrorContains(t, e, "presentation proof:")
+		require.ErrorContains(t, e, "credential 1: ")
+		require.ErrorContains(t, e, "holder binding: a verifiable presentation that includes a self-asserted")
+
+		var diErr *DataIntegrityError
+
+		require.ErrorAs(t, e, &diErr)
+		require.Equal(t, ErrCodeChallengeMismatch, diErr.Code)
+	})
+
+	t.Run("nil presentation", func(t *testing.T) {
+		result, e := VerifyPresentation(nil)
+		require.EqualError(t, e, "presentation is nil")
+		require.Nil(t, result)
+	})
+}
@@ -0,0 +1,80 @@
+/*
+Copyright Gen Digital Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package verifiable
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// ErrUntrustedIssuer is returned by ParseCredential when the credential issuer is not trusted by the
+// options given with WithTrustedIssuers or WithTrustedIssuerMatcher.
+var ErrUntrustedIssuer = errors.New("untrusted issuer")
+
+const didWebPrefix = "did:web:"
+
+// WithTrustedIssuers option makes ParseCredential fail with ErrUntrustedIssuer if the credential
+// issuer ID is not one of issuers, once the proof is checked. It can be combined with
+// WithTrustedIssuerMatcher, the issuer is then trusted if either of them accepts it.
+func WithTrustedIssuers(issuers []string) CredentialOpt {
+	return func(opts *credentialOpts) {
+		if opts.trustedIssuers == nil {
+			opts.trustedIssuers = make(map[string]bool, len(issuers))
+		}
+
+		for _, issuer := range issuers {
+			opts.trustedIssuers[issuer] = true
+		}
+	}
+}
+
+// WithTrustedIssuerMatcher option makes ParseCredential fail with ErrUntrustedIssuer if matcher returns
+// false for the credential issuer ID and the issuer is not one of those given with WithTrustedIssuers.
+// See DIDWebDomainMatcher for a matcher of did:web issuers.
+func WithTrustedIssuerMatcher(matcher func(issuerID string) bool) CredentialOpt {
+	return func(opts *credentialOpts) {
+		opts.trustedIssuerMatcher = matcher
+	}
+}
+
+// DIDWebDomainMatcher returns an issuer matcher for WithTrustedIssuerMatcher which accepts the did:web
+// DIDs hosted on domain or any of its subdomains, eg "did:web:issuer.example.com:path" for "example.com".
+// The port of the host, if any, is ignored.
+func DIDWebDomainMatcher(domain string) func(issuerID string) bool {
+	domain = strings.ToLower(domain)
+
+	return func(issuerID string) bool {
+		if !strings.HasPrefix(issuerID, didWebPrefix) {
+			return false
+		}
+
+		host, _, _ := strings.Cut(strings.TrimPrefix(issuerID, didWebPrefix), ":")
+		host, _, _ = strings.Cut(strings.ToLower(host), "%3a")
+
+		return host == domain || strings.HasSuffix(host, "."+domain)
+	}
+}
+
+func checkTrustedIssuer(vc *Credential, opts *credentialOpts) error {
+	if opts.trustedIssuers == nil && opts.trustedIssuerMatcher == nil {
+		return nil
+	}
+
+	var issuerID string
+
+	if vc.credentialContents.Issuer != nil {
+		issuerID = vc.credentialContents.Issuer.ID
+	}
+
+	if issuerID != "" &&
+		(opts.trustedIssuers[issuerID] || opts.trustedIssuerMatcher != nil && opts.trustedIssuerMatcher(issuerID)) {
+		return nil
+	}
+
+	return fmt.Errorf("%w: %q", ErrUntrustedIssuer, issuerID)
+}
@@ -0,0 +1,69 @@
+/*
+Copyright Gen Digital Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package verifiable
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"github.com/tidwall/sjson"
+)
+
+func TestWithTrustedIssuers(t *testing.T) {
+	const issuerID = "did:example:76e12ec712ebc6f1c221ebfeb1f"
+
+	t.Run("trusted issuer", func(t *testing.T) {
+		vc, err := parseTestCredential(t, []byte(v1ValidCredential), WithDisabledProofCheck(),
+			WithTrustedIssuers([]string{"did:example:other", issuerID}))
+		require.NoError(t, err)
+		require.Equal(t, issuerID, vc.Contents().Issuer.ID)
+
+		_, err = parseTestCredential(t, []byte(v1ValidCredential), WithDisabledProofCheck(),
+			WithTrustedIssuers([]string{"did:example:other"}),
+			WithTrustedIssuerMatcher(func(id string) bool { return id == issuerID }))
+		require.NoError(t, err)
+	})
+
+	t.Run("untrusted issuer", func(t *testing.T) {
+		_, err := parseTestCredential(t, []byte(v1ValidCredential), WithDisabledProofCheck(),
+			WithTrustedIssuers([]string{"did:example:other"}))
+		require.ErrorIs(t, err, ErrUntrustedIssuer)
+		require.ErrorContains(t, err, issuerID)
+
+		_, err = parseTestCredential(t, []byte(v1ValidCredential), WithDisabledProofCheck(),
+			WithTrustedIssuers(nil))
+		require.ErrorIs(t, err, ErrUntrustedIssuer)
+
+		_, err = parseTestCredential(t, []byte(v1ValidCredential), WithDisabledProofCheck(),
+			WithTrustedIssuerMatcher(DIDWebDomainMatcher("example.com")))
+		require.ErrorIs(t, err, ErrUntrustedIssuer)
+	})
+
+	t.Run("did:web domain", func(t *testing.T) {
+		vcJSON, err := sjson.Set(v1ValidCredential, "issuer.id", "did:web:issuer.example.com:tenants:1")
+		require.NoError(t, err)
+
+		_, err = parseTestCredential(t, []byte(vcJSON), WithDisabledProofCheck(),
+			WithTrustedIssuerMatcher(DIDWebDomainMatcher("example.com")))
+		require.NoError(t, err)
+	})
+}
+
+func TestDIDWebDomainMatcher(t *testing.T) {
+	match := DIDWebDomainMatcher("Example.com")
+
+	require.True(t, match("did:web:example.com"))
+	require.True(t, match("did:web:issuer.example.com"))
+	require.True(t, match("did:web:a.b.example.com:user:alice"))
+	require.True(t, match("did:web:example.com%3A3000"))
+
+	require.False(t, match("did:web:badexample.com"))
+	require.False(t, match("did:web:example.com.evil.org"))
+	require.False(t, match("did:web:evil.org:example.com"))
+	require.False(t, match("did:key:example.com"))
+	require.False(t, match(""))
+}
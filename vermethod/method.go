@@ -4,6 +4,7 @@ import "github.com/trustbloc/kms-go/doc/jose/jwk"
 
 // VerificationMethod is defined either as raw public key bytes (Value field) or as JSON Web Key.
 type VerificationMethod struct {
+	ID    string // optional, eg did:foo:bar#key-1
 	Type  string
 	Value []byte
 	JWK   *jwk.JWK
@@ -44,6 +44,7 @@ func (r *VDRResolver) ResolveVerificationMethod(
 			if verification.VerificationMethod.ID == verificationMethod &&
 				verification.Relationship != did.KeyAgreement {
 				return &VerificationMethod{
+					ID:    verification.VerificationMethod.ID,
 					Type:  verification.VerificationMethod.Type,
 					Value: verification.VerificationMethod.Value,
 					JWK:   verification.VerificationMethod.JSONWebKey(),